@@ -0,0 +1,238 @@
+// Package annotations keeps personal notes, labels and bookmarks on jobs.
+// Annotations are stored locally, keyed by cluster and job ID, so they
+// outlive the jobs leaving the queue.
+package annotations
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jontk/s9s/internal/fileperms"
+)
+
+// labelPattern restricts labels to what can be typed in a filter
+var labelPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Annotation is what the user noted about a job
+type Annotation struct {
+	Cluster    string    `json:"cluster"`
+	JobID      string    `json:"job_id"`
+	JobName    string    `json:"job_name,omitempty"` // Name of the job when it was annotated
+	Note       string    `json:"note,omitempty"`
+	Labels     []string  `json:"labels,omitempty"`
+	Bookmarked bool      `json:"bookmarked,omitempty"`
+	Updated    time.Time `json:"updated"`
+}
+
+// Empty reports whether the annotation holds nothing worth keeping
+func (a Annotation) Empty() bool {
+	return strings.TrimSpace(a.Note) == "" && len(a.Labels) == 0 && !a.Bookmarked
+}
+
+// ParseLabels parses labels separated by commas or spaces, dropping
+// duplicates
+func ParseLabels(s string) ([]string, error) {
+	var labels []string
+	for _, label := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' }) {
+		if !labelPattern.MatchString(label) {
+			return nil, fmt.Errorf("invalid label %q: use letters, digits, '.', '-' and '_'", label)
+		}
+		if !slices.Contains(labels, label) {
+			labels = append(labels, label)
+		}
+	}
+	return labels, nil
+}
+
+// key identifies the job an annotation belongs to
+type key struct {
+	cluster string
+	jobID   string
+}
+
+// Store keeps annotations in a JSON file, by default ~/.s9s/annotations.json
+type Store struct {
+	mu          sync.RWMutex
+	path        string
+	annotations map[key]*Annotation
+}
+
+// DefaultPath returns the file annotations are stored in
+func DefaultPath() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".s9s", "annotations.json")
+}
+
+// NewStore creates a store for the file at path. Annotations are not read
+// until Load is called.
+func NewStore(path string) *Store {
+	return &Store{path: path, annotations: make(map[key]*Annotation)}
+}
+
+// Load (re)reads the annotations. A missing file is an empty store.
+func (s *Store) Load() error {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	var list []*Annotation
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("%s: %w", s.path, err)
+	}
+
+	annotations := make(map[key]*Annotation, len(list))
+	for _, a := range list {
+		if a.JobID != "" {
+			annotations[key{a.Cluster, a.JobID}] = a
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.annotations = annotations
+	return nil
+}
+
+// Get returns the annotation of a job
+func (s *Store) Get(cluster, jobID string) (Annotation, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	a, ok := s.annotations[key{cluster, jobID}]
+	if !ok {
+		return Annotation{Cluster: cluster, JobID: jobID}, false
+	}
+	return clone(a), true
+}
+
+// Put stores the annotation of a job, replacing the one it had. An empty
+// annotation removes it.
+func (s *Store) Put(a Annotation) error {
+	for _, label := range a.Labels {
+		if !labelPattern.MatchString(label) {
+			return fmt.Errorf("invalid label %q: use letters, digits, '.', '-' and '_'", label)
+		}
+	}
+	a.Note = strings.TrimSpace(a.Note)
+	a.Updated = time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := key{a.Cluster, a.JobID}
+	previous, existed := s.annotations[k]
+	if a.Empty() {
+		delete(s.annotations, k)
+	} else {
+		stored := clone(&a)
+		s.annotations[k] = &stored
+	}
+	if err := s.write(); err != nil {
+		if existed {
+			s.annotations[k] = previous
+		} else {
+			delete(s.annotations, k)
+		}
+		return err
+	}
+	return nil
+}
+
+// ToggleBookmark bookmarks a job, or removes its bookmark, and returns
+// whether it is bookmarked now
+func (s *Store) ToggleBookmark(cluster, jobID, jobName string) (bool, error) {
+	a, _ := s.Get(cluster, jobID)
+	a.Bookmarked = !a.Bookmarked
+	if jobName != "" {
+		a.JobName = jobName
+	}
+	if err := s.Put(a); err != nil {
+		return !a.Bookmarked, err
+	}
+	return a.Bookmarked, nil
+}
+
+// List returns the annotations of a cluster: bookmarks first, then the
+// most recently updated
+func (s *Store) List(cluster string) []Annotation {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var list []Annotation
+	for k, a := range s.annotations {
+		if k.cluster == cluster {
+			list = append(list, clone(a))
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Bookmarked != list[j].Bookmarked {
+			return list[i].Bookmarked
+		}
+		if !list[i].Updated.Equal(list[j].Updated) {
+			return list[i].Updated.After(list[j].Updated)
+		}
+		return list[i].JobID < list[j].JobID
+	})
+	return list
+}
+
+// Labels returns the labels used on a cluster, sorted
+func (s *Store) Labels(cluster string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var labels []string
+	for k, a := range s.annotations {
+		if k.cluster != cluster {
+			continue
+		}
+		for _, label := range a.Labels {
+			if !slices.Contains(labels, label) {
+				labels = append(labels, label)
+			}
+		}
+	}
+	sort.Strings(labels)
+	return labels
+}
+
+// clone returns a copy of a that shares no slice with it
+func clone(a *Annotation) Annotation {
+	c := *a
+	c.Labels = slices.Clone(a.Labels)
+	return c
+}
+
+// write writes the annotations to disk; callers hold s.mu
+func (s *Store) write() error {
+	list := make([]*Annotation, 0, len(s.annotations))
+	for _, a := range s.annotations {
+		list = append(list, a)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Cluster != list[j].Cluster {
+			return list[i].Cluster < list[j].Cluster
+		}
+		return list[i].JobID < list[j].JobID
+	})
+
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), fileperms.ConfigDir); err != nil {
+		return fmt.Errorf("failed to create annotations directory: %w", err)
+	}
+	return os.WriteFile(s.path, data, fileperms.ConfigFile)
+}
@@ -0,0 +1,86 @@
+package annotations
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLabels(t *testing.T) {
+	labels, err := ParseLabels(" baseline, lr-1e-3 baseline,,v2.1 ")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"baseline", "lr-1e-3", "v2.1"}, labels)
+
+	labels, err = ParseLabels("")
+	require.NoError(t, err)
+	assert.Empty(t, labels)
+
+	_, err = ParseLabels("ok,-bad")
+	assert.EqualError(t, err, `invalid label "-bad": use letters, digits, '.', '-' and '_'`)
+}
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "s9s", "annotations.json")
+	store := NewStore(path)
+	require.NoError(t, store.Load(), "a missing file is an empty store")
+	assert.Empty(t, store.List("prod"))
+
+	require.NoError(t, store.Put(Annotation{Cluster: "prod", JobID: "101", JobName: "train", Note: "  lr too high ", Labels: []string{"baseline"}}))
+	require.NoError(t, store.Put(Annotation{Cluster: "prod", JobID: "102", Labels: []string{"ablation", "baseline"}}))
+	require.NoError(t, store.Put(Annotation{Cluster: "dev", JobID: "101", Labels: []string{"scratch"}}))
+	bookmarked, err := store.ToggleBookmark("prod", "103", "eval")
+	require.NoError(t, err)
+	assert.True(t, bookmarked)
+
+	assert.ErrorContains(t, store.Put(Annotation{Cluster: "prod", JobID: "104", Labels: []string{"two words"}}), "invalid label")
+
+	// Read back from disk
+	store = NewStore(path)
+	require.NoError(t, store.Load())
+
+	a, ok := store.Get("prod", "101")
+	require.True(t, ok)
+	assert.Equal(t, "lr too high", a.Note)
+	assert.Equal(t, "train", a.JobName)
+	assert.False(t, a.Updated.IsZero())
+
+	_, ok = store.Get("prod", "999")
+	assert.False(t, ok)
+	a, ok = store.Get("dev", "101")
+	require.True(t, ok, "the same job ID on another cluster is another job")
+	assert.Equal(t, []string{"scratch"}, a.Labels)
+
+	list := store.List("prod")
+	require.Len(t, list, 3)
+	assert.Equal(t, "103", list[0].JobID, "bookmarks first")
+	assert.Equal(t, []string{"ablation", "baseline"}, store.Labels("prod"))
+
+	list[1].Labels[0] = "changed"
+	assert.Equal(t, []string{"ablation", "baseline"}, store.Labels("prod"), "List returns copies")
+
+	// Removing the bookmark of an otherwise empty annotation removes it
+	bookmarked, err = store.ToggleBookmark("prod", "103", "")
+	require.NoError(t, err)
+	assert.False(t, bookmarked)
+	_, ok = store.Get("prod", "103")
+	assert.False(t, ok)
+
+	a, _ = store.Get("prod", "101")
+	a.Note, a.Labels = "", nil
+	require.NoError(t, store.Put(a))
+	assert.Len(t, store.List("prod"), 1)
+}
+
+func TestStoreWriteFailureRollsBack(t *testing.T) {
+	dir := t.TempDir()
+	blocker := filepath.Join(dir, "file")
+	require.NoError(t, os.WriteFile(blocker, nil, 0o600))
+
+	store := NewStore(filepath.Join(blocker, "annotations.json"))
+	assert.Error(t, store.Put(Annotation{Cluster: "prod", JobID: "1", Note: "x"}))
+	_, ok := store.Get("prod", "1")
+	assert.False(t, ok)
+}
@@ -3,6 +3,7 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -10,16 +11,24 @@ import (
 	"time"
 
 	"github.com/gdamore/tcell/v2"
+	"github.com/jontk/s9s/internal/annotations"
+	"github.com/jontk/s9s/internal/audit"
 	"github.com/jontk/s9s/internal/config"
 	"github.com/jontk/s9s/internal/dao"
 	"github.com/jontk/s9s/internal/errs"
 	"github.com/jontk/s9s/internal/layouts"
 	"github.com/jontk/s9s/internal/logging"
+	"github.com/jontk/s9s/internal/monitoring"
 	"github.com/jontk/s9s/internal/notifications"
+	"github.com/jontk/s9s/internal/pipeline"
 	"github.com/jontk/s9s/internal/plugins"
 	"github.com/jontk/s9s/internal/preferences"
+	"github.com/jontk/s9s/internal/safety"
 	"github.com/jontk/s9s/internal/streaming"
+	"github.com/jontk/s9s/internal/sweep"
+	"github.com/jontk/s9s/internal/timeseries"
 	"github.com/jontk/s9s/internal/ui/components"
+	"github.com/jontk/s9s/internal/ui/filters"
 	"github.com/jontk/s9s/internal/views"
 	"github.com/jontk/s9s/pkg/slurm"
 	"github.com/rivo/tview"
@@ -43,6 +52,36 @@ type S9s struct {
 	// SLURM client
 	client dao.SlurmClient
 
+	// policy guards every mutation made through client
+	policy *safety.Policy
+
+	// configWatcher reloads the config file on change; fileConfig is the
+	// file as last loaded, without runtime overrides
+	configWatcher *config.Watcher
+	fileConfig    *config.Config
+
+	// shortcuts are the custom key bindings of the config
+	shortcuts map[shortcutKey]string
+
+	// history records cluster series from every refresh for trend display
+	history *timeseries.Store
+
+	// health evaluates the health rules once for both the health view and
+	// the dashboard alerts
+	health *monitoring.HealthMonitor
+
+	// savedViews holds the named views recalled with :view
+	savedViews *filters.SavedViewStore
+
+	// jobGroups holds the job groups, such as parameter sweeps, shown with :group
+	jobGroups *sweep.GroupStore
+
+	// pipelineRuns holds the submitted pipelines shown with :pipeline
+	pipelineRuns *pipeline.RunStore
+
+	// annotations holds the notes, labels and bookmarks of jobs
+	annotations *annotations.Store
+
 	// Plugin system
 	pluginManager plugins.PluginManager
 
@@ -101,6 +140,22 @@ func NewWithScreen(ctx context.Context, cfg *config.Config, screen tcell.Screen)
 	if err != nil {
 		return nil, err
 	}
+	raw := client
+	client, policy, auditLog, err := wrapClient(client, cfg, audit.SourceTUI)
+	if err != nil {
+		_ = raw.Close()
+		cancel()
+		return nil, err
+	}
+	history := newHistoryStore(cfg)
+	client = timeseries.NewRecordingClient(client, history)
+
+	rules, err := monitoring.NewRuleEngineFromConfig(cfg.Health.Rules)
+	if err != nil {
+		logging.GetLogger().Warn().Err(err).Msg("Skipping invalid health rules")
+	}
+	health := monitoring.NewHealthMonitor(client, 30*time.Second)
+	health.SetRuleEngine(rules)
 
 	// Create tview application and set screen if provided
 	app := tview.NewApplication()
@@ -115,12 +170,21 @@ func NewWithScreen(ctx context.Context, cfg *config.Config, screen tcell.Screen)
 		config:        cfg,
 		logger:        logging.GetLogger(),
 		client:        client,
+		policy:        policy,
+		history:       history,
+		health:        health,
 		app:           app,
 		pages:         tview.NewPages(),
 		contentPages:  tview.NewPages(),
 		pluginManager: plugins.NewManager(appCtx, client),
 	}
 	s9s.autoRefresh.Store(true)
+	policy.SetConfirmer(s9s)
+	auditLog.SetReporter(s9s)
+	s9s.savedViews = s9s.newSavedViewStore()
+	s9s.jobGroups = s9s.newJobGroupStore()
+	s9s.pipelineRuns = s9s.newPipelineRunStore()
+	s9s.annotations = s9s.newAnnotationStore()
 
 	// Load user preferences
 	if err := s9s.loadUserPreferences(); err != nil {
@@ -154,6 +218,9 @@ func NewWithScreen(ctx context.Context, cfg *config.Config, screen tcell.Screen)
 
 	// Setup keyboard shortcuts
 	s9s.setupKeyboardShortcuts()
+	if err := s9s.applyShortcuts(); err != nil {
+		s9s.logger.Warn().Err(err).Msg("Skipping invalid shortcuts")
+	}
 
 	// Load plugins (non-fatal if they fail)
 	s9s.loadAndRegisterPlugins()
@@ -161,6 +228,54 @@ func NewWithScreen(ctx context.Context, cfg *config.Config, screen tcell.Screen)
 	return s9s, nil
 }
 
+// NewSlurmClient creates the SLURM client for the configured cluster, or the
+// mock client when mock mode is enabled. It is used by headless commands.
+func NewSlurmClient(ctx context.Context, cfg *config.Config) (dao.SlurmClient, error) {
+	client, err := createSlurmClient(ctx, cfg, func() {})
+	if err != nil {
+		return nil, err
+	}
+	wrapped, _, auditLog, err := wrapClient(client, cfg, audit.SourceCLI)
+	if err != nil {
+		_ = client.Close()
+		return nil, err
+	}
+	auditLog.SetReporter(stderrAuditReporter{})
+	return wrapped, nil
+}
+
+// stderrAuditReporter warns on stderr about actions of headless commands
+// that were not recorded
+type stderrAuditReporter struct{}
+
+func (stderrAuditReporter) AuditFailed(action string, err error) {
+	fmt.Fprintf(os.Stderr, "Warning: %s was not recorded in the audit log: %v\n", action, err)
+}
+
+// wrapClient enforces the safety policy on the mutations made through
+// client and records them, including refused ones, in the audit log. When
+// auditing is enabled but the log cannot be opened, mutations are refused
+// until it is fixed or auditing is disabled; the returned logger is nil
+// then.
+func wrapClient(client dao.SlurmClient, cfg *config.Config, source string) (dao.SlurmClient, *safety.Policy, *audit.Logger, error) {
+	policy, err := safety.NewPolicy(cfg)
+	if err != nil {
+		return nil, nil, nil, errs.Wrap(err, errs.ErrorTypeConfiguration, "invalid safety policy")
+	}
+	client = safety.NewClient(client, policy)
+
+	logger, err := audit.NewLogger(&cfg.Audit)
+	if err != nil {
+		logging.GetLogger().Warn().Err(err).Msg("Audit log unavailable, refusing changes")
+		return audit.NewUnavailableClient(client, err), policy, nil, nil
+	}
+	return audit.NewClient(client, logger, audit.Identity{
+		SlurmUser: cfg.ResolveSlurmUser(),
+		Cluster:   cfg.DefaultCluster,
+		Source:    source,
+	}), policy, logger, nil
+}
+
 func createSlurmClient(appCtx context.Context, cfg *config.Config, cancel context.CancelFunc) (dao.SlurmClient, error) {
 	if cfg.UseMockClient {
 		return slurm.NewMockClient(), nil
@@ -174,7 +289,7 @@ func createSlurmClient(appCtx context.Context, cfg *config.Config, cancel contex
 	}
 
 	// Create real SLURM adapter
-	adapter, err := dao.NewSlurmAdapter(appCtx, clusterConfig)
+	adapter, err := dao.NewSlurmAdapter(appCtx, cfg.DefaultCluster, clusterConfig)
 	if err != nil {
 		cancel()
 		return nil, errs.DAOError("create", "SLURM adapter", err)
@@ -250,6 +365,9 @@ func (s *S9s) Run() error {
 		go s.checkForUpdates()
 	}
 
+	// Apply changes to the config file while running
+	s.startConfigWatcher()
+
 	// Set the root and run the application
 	s.app.SetRoot(s.pages, true)
 
@@ -272,8 +390,9 @@ func (s *S9s) HideModal(name string) {
 func (s *S9s) Stop() error {
 	s.isRunning.Store(false)
 
-	// Stop refresh timer
+	// Stop refresh timer and config watcher
 	s.stopRefreshTimer()
+	s.stopConfigWatcher()
 
 	// Stop header
 	s.header.Stop()
@@ -286,6 +405,8 @@ func (s *S9s) Stop() error {
 		_ = s.streamManager.Close()
 	}
 
+	s.saveHistory()
+
 	// Stop the tview application
 	s.app.Stop()
 
@@ -337,13 +458,16 @@ func (s *S9s) stopRefreshTimer() {
 	}
 }
 
-// ApplyConfig swaps in an updated configuration and re-applies any
-// settings that affect running components (currently: refresh cadence).
-// Called by the config modal when the user applies or saves changes.
-func (s *S9s) ApplyConfig(newCfg *config.Config) {
+// ApplyConfig swaps in an updated configuration and re-applies the
+// settings that affect running components: refresh cadence, view
+// settings, shortcuts, the cluster connection with its safety policy and
+// audit log, and plugins. Called on the UI goroutine by the config modal
+// and when the config file is reloaded.
+func (s *S9s) ApplyConfig(newCfg *config.Config) error {
 	if newCfg == nil {
-		return
+		return nil
 	}
+	old := s.config
 	s.config = newCfg
 
 	// Re-arm the global refresh ticker with the new cadence.
@@ -353,6 +477,29 @@ func (s *S9s) ApplyConfig(newCfg *config.Config) {
 			s.startRefreshTimer(duration)
 		}
 	}
+
+	s.applyViewsConfig(old)
+
+	var errs []error
+	if err := s.applyShortcuts(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := s.applyClusterConfig(old); err != nil {
+		errs = append(errs, err)
+	}
+	loaded, err := s.applyPluginConfig()
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for _, p := range loaded {
+		for _, pluginView := range p.GetViews() {
+			s.registerPluginView(pluginView)
+		}
+	}
+	if len(loaded) > 0 {
+		s.header.SetViews(s.viewMgr.GetViewNames())
+	}
+	return errors.Join(errs...)
 }
 
 // GetCurrentViewName returns the name of the current view
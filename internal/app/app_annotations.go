@@ -0,0 +1,148 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/jontk/s9s/internal/annotations"
+	"github.com/jontk/s9s/internal/views"
+	"github.com/rivo/tview"
+)
+
+// newAnnotationStore loads the notes, labels and bookmarks of jobs
+func (s *S9s) newAnnotationStore() *annotations.Store {
+	store := annotations.NewStore(annotations.DefaultPath())
+	if err := store.Load(); err != nil {
+		s.logger.Warn().Err(err).Msg("Skipping unreadable job annotations")
+	}
+	return store
+}
+
+// cmdNotes handles :notes, which lists the annotated jobs, and :notes LABEL,
+// which shows the jobs with a label
+func (s *S9s) cmdNotes(args []string) CommandResult {
+	if s.annotations == nil {
+		return CommandResult{Success: false, Message: "Job notes are not available"}
+	}
+	if len(args) == 0 {
+		return s.showAnnotatedJobs()
+	}
+
+	jobsView, result := s.focusJobsView()
+	if jobsView == nil {
+		return result
+	}
+	if err := jobsView.ShowLabel(args[0]); err != nil {
+		return CommandResult{Success: false, Message: err.Error(), Error: err}
+	}
+	return CommandResult{Success: true, Message: fmt.Sprintf("Showing the jobs labelled %s", args[0])}
+}
+
+// focusJobsView switches to the jobs view and returns it, or nil and the
+// failure when it is not available
+func (s *S9s) focusJobsView() (*views.JobsView, CommandResult) {
+	view, err := s.viewMgr.GetView("jobs")
+	if err != nil {
+		return nil, CommandResult{Success: false, Message: "The jobs view is not available", Error: err}
+	}
+	jobsView, ok := view.(*views.JobsView)
+	if !ok {
+		return nil, CommandResult{Success: false, Message: "The jobs view is not available"}
+	}
+	s.switchToView("jobs")
+	return jobsView, CommandResult{Success: true}
+}
+
+// showAnnotatedJobs displays the annotated jobs of the cluster, bookmarks
+// first, including jobs that left the queue; selecting one shows it in the
+// jobs view
+func (s *S9s) showAnnotatedJobs() CommandResult {
+	list := s.annotations.List(clusterName(s.config))
+	if len(list) == 0 {
+		return CommandResult{Success: true, Message: "No job notes. Press n on a job to write one, or m to bookmark it"}
+	}
+
+	modal := tview.NewList()
+	modal.SetBorder(true).
+		SetTitle(" Job Notes ").
+		SetTitleAlign(tview.AlignCenter)
+
+	for _, a := range list {
+		jobID := a.JobID
+		modal.AddItem(annotationTitle(a), annotationSummary(a), 0, func() {
+			s.pages.RemovePage("job-notes")
+			jobsView, result := s.focusJobsView()
+			if jobsView == nil {
+				s.statusBar.Error(result.Message)
+				return
+			}
+			if err := jobsView.ShowJob(jobID); err != nil {
+				s.statusBar.Error(err.Error())
+				return
+			}
+			if slices.Contains(jobsView.GetJobIDs(), jobID) {
+				s.statusBar.Success(fmt.Sprintf("Showing job %s", jobID))
+			} else {
+				s.statusBar.Warning(fmt.Sprintf("Job %s is no longer listed by the cluster; its note is kept", jobID))
+			}
+		})
+	}
+
+	modal.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEsc {
+			s.pages.RemovePage("job-notes")
+			if currentView, err := s.viewMgr.GetCurrentView(); err == nil {
+				s.app.SetFocus(currentView.Render())
+			}
+			return nil
+		}
+		return event
+	})
+
+	centeredModal := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(modal, 0, 3, true).
+			AddItem(nil, 0, 1, false), 0, 3, true).
+		AddItem(nil, 0, 1, false)
+
+	s.pages.AddPage("job-notes", centeredModal, true, true)
+	s.app.SetFocus(modal)
+	return CommandResult{Success: true, Message: fmt.Sprintf("%d annotated job(s)", len(list))}
+}
+
+// annotationTitle names an annotated job in one line
+func annotationTitle(a annotations.Annotation) string {
+	title := "  " + a.JobID
+	if a.Bookmarked {
+		title = "[yellow]★[white] " + a.JobID
+	}
+	if a.JobName != "" {
+		title += " " + tview.Escape(a.JobName)
+	}
+	if len(a.Labels) > 0 {
+		title += " [gray](" + strings.Join(a.Labels, ", ") + ")[white]"
+	}
+	return title
+}
+
+// annotationSummary describes an annotation in one line
+func annotationSummary(a annotations.Annotation) string {
+	note, _, _ := strings.Cut(a.Note, "\n")
+	if note == "" {
+		return "updated " + a.Updated.Format("2006-01-02 15:04")
+	}
+	return tview.Escape(note)
+}
+
+// getNoteCompletions completes :notes with the labels of the cluster
+func (s *S9s) getNoteCompletions(text string) []string {
+	var labels []string
+	if s.annotations != nil {
+		labels = s.annotations.Labels(clusterName(s.config))
+	}
+	return completeNamedArgs(text, nil, labels)
+}
@@ -155,6 +155,19 @@ func (s *S9s) commandRegistry() map[string]*CommandDef {
 			MaxArgs: 0,
 			Handler: s.cmdPerformance,
 		},
+		"topology": {
+			Name:    "topology",
+			Aliases: []string{"topo"},
+			Usage:   ":topology",
+			MaxArgs: 0,
+			Handler: s.cmdTopology,
+		},
+		"audit": {
+			Name:    "audit",
+			Usage:   ":audit",
+			MaxArgs: 0,
+			Handler: s.cmdAudit,
+		},
 		"refresh": {
 			Name:    "refresh",
 			Aliases: []string{"r"},
@@ -176,6 +189,34 @@ func (s *S9s) commandRegistry() map[string]*CommandDef {
 			MaxArgs: 0,
 			Handler: s.cmdLayout,
 		},
+		"view": {
+			Name:    "view",
+			Aliases: []string{"views"},
+			Usage:   ":view [NAME | save NAME [DESCRIPTION] | delete NAME | list]",
+			MaxArgs: -1, // Unlimited for description
+			Handler: s.cmdView,
+		},
+		"group": {
+			Name:    "group",
+			Aliases: []string{"groups"},
+			Usage:   ":group [NAME | delete NAME | list]",
+			MaxArgs: 2,
+			Handler: s.cmdGroup,
+		},
+		"pipeline": {
+			Name:    "pipeline",
+			Aliases: []string{"pipelines"},
+			Usage:   ":pipeline [RUN | submit FILE | delete RUN | list]",
+			MaxArgs: 2,
+			Handler: s.cmdPipeline,
+		},
+		"notes": {
+			Name:    "notes",
+			Aliases: []string{"bookmarks"},
+			Usage:   ":notes [LABEL]",
+			MaxArgs: 1,
+			Handler: s.cmdNotes,
+		},
 		"config": {
 			Name:    "config",
 			Aliases: []string{"configuration", "settings"},
@@ -229,6 +270,18 @@ func (s *S9s) commandRegistry() map[string]*CommandDef {
 			MaxArgs: 1,
 			Handler: s.cmdResumeNode,
 		},
+		"admin": {
+			Name:    "admin",
+			Usage:   ":admin [on|off]",
+			MaxArgs: 1,
+			Handler: s.cmdAdmin,
+		},
+		"gpus": {
+			Name:    "gpus",
+			Usage:   ":gpus [COUNT] [TYPE]",
+			MaxArgs: 2,
+			Handler: s.cmdGPUs,
+		},
 	}
 }
 
@@ -309,6 +362,16 @@ func (s *S9s) cmdPerformance(args []string) CommandResult {
 	return CommandResult{Success: true, Message: "Switched to performance view"}
 }
 
+func (s *S9s) cmdTopology(args []string) CommandResult {
+	s.switchToView("topology")
+	return CommandResult{Success: true, Message: "Switched to topology view"}
+}
+
+func (s *S9s) cmdAudit(args []string) CommandResult {
+	s.switchToView("audit")
+	return CommandResult{Success: true, Message: "Switched to audit view"}
+}
+
 func (s *S9s) cmdHelp(args []string) CommandResult {
 	s.showHelp()
 	return CommandResult{Success: true, Message: "Showing help"}
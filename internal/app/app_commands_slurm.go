@@ -2,76 +2,55 @@ package app
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/jontk/s9s/internal/views"
 )
 
 // cmdCancelJob cancels a SLURM job
 func (s *S9s) cmdCancelJob(args []string) CommandResult {
 	jobID := args[0]
-	if err := s.client.Jobs().Cancel(jobID); err != nil {
-		return CommandResult{
-			Success: false,
-			Message: fmt.Sprintf("Failed to cancel job %s: %v", jobID, err),
-			Error:   err,
+	return s.runSlurmCommand(fmt.Sprintf("Canceling job %s...", jobID), func() (string, error) {
+		if err := s.client.Jobs().Cancel(jobID); err != nil {
+			return fmt.Sprintf("Failed to cancel job %s", jobID), err
 		}
-	}
-	s.refreshCurrentViewAsync()
-	return CommandResult{
-		Success: true,
-		Message: fmt.Sprintf("Job %s canceled", jobID),
-	}
+		return fmt.Sprintf("Job %s canceled", jobID), nil
+	})
 }
 
 // cmdHoldJob holds a SLURM job
 func (s *S9s) cmdHoldJob(args []string) CommandResult {
 	jobID := args[0]
-	if err := s.client.Jobs().Hold(jobID); err != nil {
-		return CommandResult{
-			Success: false,
-			Message: fmt.Sprintf("Failed to hold job %s: %v", jobID, err),
-			Error:   err,
+	return s.runSlurmCommand(fmt.Sprintf("Holding job %s...", jobID), func() (string, error) {
+		if err := s.client.Jobs().Hold(jobID); err != nil {
+			return fmt.Sprintf("Failed to hold job %s", jobID), err
 		}
-	}
-	s.refreshCurrentViewAsync()
-	return CommandResult{
-		Success: true,
-		Message: fmt.Sprintf("Job %s held", jobID),
-	}
+		return fmt.Sprintf("Job %s held", jobID), nil
+	})
 }
 
 // cmdReleaseJob releases a held SLURM job
 func (s *S9s) cmdReleaseJob(args []string) CommandResult {
 	jobID := args[0]
-	if err := s.client.Jobs().Release(jobID); err != nil {
-		return CommandResult{
-			Success: false,
-			Message: fmt.Sprintf("Failed to release job %s: %v", jobID, err),
-			Error:   err,
+	return s.runSlurmCommand(fmt.Sprintf("Releasing job %s...", jobID), func() (string, error) {
+		if err := s.client.Jobs().Release(jobID); err != nil {
+			return fmt.Sprintf("Failed to release job %s", jobID), err
 		}
-	}
-	s.refreshCurrentViewAsync()
-	return CommandResult{
-		Success: true,
-		Message: fmt.Sprintf("Job %s released", jobID),
-	}
+		return fmt.Sprintf("Job %s released", jobID), nil
+	})
 }
 
 // cmdRequeueJob requeues a SLURM job
 func (s *S9s) cmdRequeueJob(args []string) CommandResult {
 	jobID := args[0]
-	if _, err := s.client.Jobs().Requeue(jobID); err != nil {
-		return CommandResult{
-			Success: false,
-			Message: fmt.Sprintf("Failed to requeue job %s: %v", jobID, err),
-			Error:   err,
+	return s.runSlurmCommand(fmt.Sprintf("Requeuing job %s...", jobID), func() (string, error) {
+		if _, err := s.client.Jobs().Requeue(jobID); err != nil {
+			return fmt.Sprintf("Failed to requeue job %s", jobID), err
 		}
-	}
-	s.refreshCurrentViewAsync()
-	return CommandResult{
-		Success: true,
-		Message: fmt.Sprintf("Job %s requeued", jobID),
-	}
+		return fmt.Sprintf("Job %s requeued", jobID), nil
+	})
 }
 
 // cmdDrainNode drains a SLURM node
@@ -81,35 +60,70 @@ func (s *S9s) cmdDrainNode(args []string) CommandResult {
 	if len(args) > 1 {
 		reason = strings.Join(args[1:], " ")
 	}
-	if err := s.client.Nodes().Drain(nodeName, reason); err != nil {
-		return CommandResult{
-			Success: false,
-			Message: fmt.Sprintf("Failed to drain node %s: %v", nodeName, err),
-			Error:   err,
+	return s.runSlurmCommand(fmt.Sprintf("Draining node %s...", nodeName), func() (string, error) {
+		if err := s.client.Nodes().Drain(nodeName, reason); err != nil {
+			return fmt.Sprintf("Failed to drain node %s", nodeName), err
 		}
-	}
-	s.refreshCurrentViewAsync()
-	return CommandResult{
-		Success: true,
-		Message: fmt.Sprintf("Node %s drained: %s", nodeName, reason),
-	}
+		return fmt.Sprintf("Node %s drained: %s", nodeName, reason), nil
+	})
 }
 
 // cmdResumeNode resumes a drained SLURM node
 func (s *S9s) cmdResumeNode(args []string) CommandResult {
 	nodeName := args[0]
-	if err := s.client.Nodes().Resume(nodeName); err != nil {
-		return CommandResult{
-			Success: false,
-			Message: fmt.Sprintf("Failed to resume node %s: %v", nodeName, err),
-			Error:   err,
+	return s.runSlurmCommand(fmt.Sprintf("Resuming node %s...", nodeName), func() (string, error) {
+		if err := s.client.Nodes().Resume(nodeName); err != nil {
+			return fmt.Sprintf("Failed to resume node %s", nodeName), err
+		}
+		return fmt.Sprintf("Node %s resumed", nodeName), nil
+	})
+}
+
+// runSlurmCommand runs action off the UI goroutine, since the safety
+// policy may ask for a typed confirmation, and reports its outcome in the
+// status bar. action returns the success or failure message.
+func (s *S9s) runSlurmCommand(pending string, action func() (string, error)) CommandResult {
+	go func() {
+		message, err := action()
+		s.app.QueueUpdateDraw(func() {
+			if err != nil {
+				s.statusBar.Error(fmt.Sprintf("%s: %v", message, err))
+				return
+			}
+			s.statusBar.Success(message)
+		})
+		if err == nil {
+			s.refreshCurrentViewAsync()
+		}
+	}()
+	return CommandResult{Success: true, Message: pending}
+}
+
+// cmdGPUs shows where COUNT GPUs of TYPE can start right now, e.g. ":gpus 4 a100"
+func (s *S9s) cmdGPUs(args []string) CommandResult {
+	count, gpuType := 0, ""
+	for _, arg := range args {
+		if n, err := strconv.Atoi(arg); err == nil && n > 0 && count == 0 {
+			count = n
+		} else if gpuType == "" {
+			gpuType = arg
+		} else {
+			return CommandResult{Success: false, Message: "Usage: :gpus [COUNT] [TYPE]"}
 		}
 	}
-	s.refreshCurrentViewAsync()
-	return CommandResult{
-		Success: true,
-		Message: fmt.Sprintf("Node %s resumed", nodeName),
+
+	view, err := s.viewMgr.GetView("nodes")
+	if err != nil {
+		return CommandResult{Success: false, Message: "Nodes view is not available", Error: err}
 	}
+	nodesView, ok := view.(*views.NodesView)
+	if !ok {
+		return CommandResult{Success: false, Message: "Nodes view is not available"}
+	}
+
+	s.switchToView("nodes")
+	nodesView.ShowGPUAvailability(count, gpuType)
+	return CommandResult{Success: true, Message: "Showing GPU availability"}
 }
 
 // refreshCurrentViewAsync refreshes the current view after a delay
@@ -14,6 +14,11 @@ const (
 	ArgTypeNone ArgType = iota
 	ArgTypeJobID
 	ArgTypeNodeName
+	ArgTypeGPUType
+	ArgTypeSavedView
+	ArgTypeJobGroup
+	ArgTypePipeline
+	ArgTypeLabel
 )
 
 // getArgType returns the expected argument type for a command
@@ -23,6 +28,16 @@ func getArgType(cmdName string) ArgType {
 		return ArgTypeJobID
 	case "drain", "resume":
 		return ArgTypeNodeName
+	case "gpus":
+		return ArgTypeGPUType
+	case "view", "views":
+		return ArgTypeSavedView
+	case "group", "groups":
+		return ArgTypeJobGroup
+	case "pipeline", "pipelines":
+		return ArgTypePipeline
+	case "notes", "bookmarks":
+		return ArgTypeLabel
 	default:
 		return ArgTypeNone
 	}
@@ -79,6 +94,16 @@ func (s *S9s) getArgumentCompletions(text string) []string {
 
 	cmdName := strings.ToLower(parts[0])
 	argType := getArgType(cmdName)
+	switch argType {
+	case ArgTypeSavedView:
+		return s.getSavedViewCompletions(text)
+	case ArgTypeJobGroup:
+		return s.getJobGroupCompletions(text)
+	case ArgTypePipeline:
+		return s.getPipelineCompletions(text)
+	case ArgTypeLabel:
+		return s.getNoteCompletions(text)
+	}
 
 	// Get the partial argument being typed (if any)
 	var argPrefix string
@@ -96,6 +121,8 @@ func (s *S9s) getArgumentCompletions(text string) []string {
 		candidates = s.getJobIDCandidates()
 	case ArgTypeNodeName:
 		candidates = s.getNodeNameCandidates()
+	case ArgTypeGPUType:
+		candidates = s.getGPUTypeCandidates()
 	default:
 		return nil
 	}
@@ -136,3 +163,52 @@ func (s *S9s) getNodeNameCandidates() []string {
 	}
 	return nil
 }
+
+// getGPUTypeCandidates returns GPU models from cached view data
+func (s *S9s) getGPUTypeCandidates() []string {
+	view, err := s.viewMgr.GetView("nodes")
+	if err != nil {
+		return nil
+	}
+	if nodesView, ok := view.(*views.NodesView); ok {
+		return nodesView.GetGPUTypes()
+	}
+	return nil
+}
+
+// completeNamedArgs completes the arguments of commands managing named
+// items, such as :view and :group: subcommands and names for the first
+// argument, names after delete
+func completeNamedArgs(text string, subcommands, names []string) []string {
+	fields := strings.Fields(text)
+	if strings.HasSuffix(text, " ") {
+		fields = append(fields, "")
+	}
+	if len(fields) < 2 {
+		return nil
+	}
+	args := fields[1:]
+	typing := args[len(args)-1]
+
+	var prefix string
+	var candidates []string
+	switch {
+	case len(args) == 1:
+		prefix = fields[0] + " "
+		candidates = append(append(candidates, subcommands...), names...)
+	case len(args) == 2 && strings.EqualFold(args[0], "delete"):
+		prefix = fields[0] + " " + args[0] + " "
+		candidates = names
+	default:
+		return nil
+	}
+
+	var completions []string
+	for _, c := range candidates {
+		if strings.HasPrefix(c, typing) {
+			completions = append(completions, prefix+c)
+		}
+	}
+	sort.Strings(completions)
+	return completions
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/jontk/s9s/internal/dao"
+	"github.com/jontk/s9s/internal/ui/filters"
 	"github.com/jontk/s9s/internal/views"
 	"github.com/rivo/tview"
 )
@@ -43,6 +44,11 @@ func TestGetArgType(t *testing.T) {
 		{"requeue command", "requeue", ArgTypeJobID},
 		{"drain command", "drain", ArgTypeNodeName},
 		{"resume command", "resume", ArgTypeNodeName},
+		{"gpus command", "gpus", ArgTypeGPUType},
+		{"view command", "view", ArgTypeSavedView},
+		{"group command", "group", ArgTypeJobGroup},
+		{"pipeline command", "pipeline", ArgTypePipeline},
+		{"notes command", "notes", ArgTypeLabel},
 		{"quit command", "quit", ArgTypeNone},
 		{"unknown command", "unknown", ArgTypeNone},
 	}
@@ -71,7 +77,7 @@ func TestGetCommandCompletions(t *testing.T) {
 		{
 			name:     "empty prefix",
 			prefix:   "",
-			expected: []string{"accounts", "cancel", "config", "configuration", "dashboard", "drain", "h", "health", "help", "hold", "j", "jobs", "layout", "layouts", "n", "nodes", "p", "partitions", "performance", "q", "qos", "quit", "r", "refresh", "release", "requeue", "reservations", "resume", "settings", "users"},
+			expected: []string{"accounts", "admin", "audit", "bookmarks", "cancel", "config", "configuration", "dashboard", "drain", "gpus", "group", "groups", "h", "health", "help", "hold", "j", "jobs", "layout", "layouts", "n", "nodes", "notes", "p", "partitions", "performance", "pipeline", "pipelines", "q", "qos", "quit", "r", "refresh", "release", "requeue", "reservations", "resume", "settings", "topo", "topology", "users", "view", "views"},
 		},
 		{
 			name:     "prefix 'q'",
@@ -208,6 +214,41 @@ func TestGetCompletions_Arguments(t *testing.T) {
 	}
 }
 
+func TestGetCompletions_SavedViews(t *testing.T) {
+	store := filters.NewSavedViewStore(t.TempDir(), "")
+	for _, name := range []string{"my-gpu-pending", "my-failed", "down-nodes"} {
+		if err := store.Save(filters.SavedView{Name: name, ViewType: "jobs"}); err != nil {
+			t.Fatalf("Save(%s) failed: %v", name, err)
+		}
+	}
+
+	s := &S9s{
+		app:        tview.NewApplication(),
+		savedViews: store,
+	}
+
+	tests := []struct {
+		text     string
+		expected []string
+	}{
+		{"view my", []string{"view my-failed", "view my-gpu-pending"}},
+		{"view ", []string{"view delete", "view down-nodes", "view list", "view my-failed", "view my-gpu-pending", "view save"}},
+		{"view de", []string{"view delete"}},
+		{"view delete ", []string{"view delete down-nodes", "view delete my-failed", "view delete my-gpu-pending"}},
+		{"views delete d", []string{"views delete down-nodes"}},
+		{"view save new ", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			result := s.getCompletions(tt.text)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("getCompletions(%q) = %v, want %v", tt.text, result, tt.expected)
+			}
+		})
+	}
+}
+
 func TestGetJobIDCandidates_NoView(t *testing.T) {
 	app := tview.NewApplication()
 	viewMgr := views.NewViewManager(app)
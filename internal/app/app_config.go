@@ -0,0 +1,287 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/jontk/s9s/internal/audit"
+	"github.com/jontk/s9s/internal/config"
+	"github.com/jontk/s9s/internal/dao"
+	"github.com/jontk/s9s/internal/plugins"
+	"github.com/jontk/s9s/internal/timeseries"
+	"github.com/jontk/s9s/internal/views"
+)
+
+// startConfigWatcher reloads the configuration whenever one of its config
+// files changes
+func (s *S9s) startConfigWatcher() {
+	files := s.config.Files()
+	if len(files) == 0 {
+		return
+	}
+	path := s.config.ConfigPath
+
+	// The files' own settings tell their changes apart from the cluster
+	// and mock mode s9s was started or switched with
+	fileConfig, err := config.LoadWithPath(path)
+	if err != nil {
+		s.logger.Warn().Err(err).Msg("Not watching the config files")
+		return
+	}
+	s.fileConfig = fileConfig
+
+	watcher, err := config.NewWatcher(files, func() { s.reloadConfig(path) })
+	if err != nil {
+		s.logger.Warn().Err(err).Msg("Not watching the config files")
+		return
+	}
+	s.configWatcher = watcher
+}
+
+// stopConfigWatcher stops reloading the config file
+func (s *S9s) stopConfigWatcher() {
+	if s.configWatcher != nil {
+		_ = s.configWatcher.Close()
+		s.configWatcher = nil
+	}
+}
+
+// reloadConfig loads the changed config files and applies them on the UI
+// goroutine. It is called by the config watcher.
+func (s *S9s) reloadConfig(path string) {
+	loaded, err := config.LoadWithPath(path)
+	s.app.QueueUpdateDraw(func() {
+		if err != nil {
+			s.statusBar.Error(fmt.Sprintf("Config reload failed, keeping the previous configuration: %v", err))
+			return
+		}
+		s.applyReloadedConfig(loaded)
+	})
+}
+
+// applyReloadedConfig validates a reloaded config file and applies it, or
+// keeps the running configuration if the file introduces errors
+func (s *S9s) applyReloadedConfig(loaded *config.Config) {
+	newCfg := s.withRuntimeSettings(loaded)
+	if err := newCfg.SetCurrentCluster(); err != nil {
+		s.statusBar.Error(fmt.Sprintf("Config reload failed, keeping the previous configuration: %v", err))
+		return
+	}
+
+	// Errors the running configuration already has do not block a reload
+	before := config.ValidateAndFix(s.config, false)
+	if introduced := config.ValidateAndFix(newCfg, false).NewErrors(before); len(introduced) > 0 {
+		s.statusBar.Error(fmt.Sprintf("Invalid config, keeping the previous configuration: [%s] %s%s",
+			introduced[0].Field, introduced[0].Message, moreErrors(len(introduced)-1)))
+		return
+	}
+	s.fileConfig = loaded
+	if reflect.DeepEqual(newCfg, s.config) {
+		return
+	}
+
+	pending := restartRequired(s.config, newCfg)
+	if err := s.ApplyConfig(newCfg); err != nil {
+		s.statusBar.Error(fmt.Sprintf("Configuration reloaded with errors: %v", err))
+		return
+	}
+	if len(pending) > 0 {
+		s.statusBar.Warning(fmt.Sprintf("Configuration reloaded; %s take effect after a restart", strings.Join(pending, ", ")))
+		return
+	}
+	s.statusBar.Success("Configuration reloaded")
+}
+
+// withRuntimeSettings returns a reloaded config with the settings s9s was
+// started or switched with: mock mode, discovery flags, the active cluster
+// unless the file now names another, and discovered clusters if the file
+// has none
+func (s *S9s) withRuntimeSettings(loaded *config.Config) *config.Config {
+	cfg := *loaded
+	cfg.UseMockClient = s.config.UseMockClient
+	cfg.Discovery = s.config.Discovery
+	if len(cfg.Clusters) == 0 {
+		cfg.Clusters = s.config.Clusters
+		cfg.Cluster = s.config.Cluster
+	}
+	if s.fileConfig == nil || cfg.DefaultCluster == s.fileConfig.DefaultCluster {
+		cfg.DefaultCluster = s.config.DefaultCluster
+	}
+	return &cfg
+}
+
+// restartRequired returns the changed config sections that are only read
+// at startup
+func restartRequired(old, cfg *config.Config) []string {
+	var sections []string
+	add := func(name string, before, after any) {
+		if !reflect.DeepEqual(before, after) {
+			sections = append(sections, name)
+		}
+	}
+	add("features", old.Features, cfg.Features)
+	add("history", old.History, cfg.History)
+	add("health", old.Health, cfg.Health)
+	add("update", old.Update, cfg.Update)
+	add("pluginSettings", old.PluginSettings, cfg.PluginSettings)
+	return sections
+}
+
+func moreErrors(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (and %d more)", n)
+}
+
+// applyViewsConfig hands the views section of the config to the views
+// whose settings changed
+func (s *S9s) applyViewsConfig(old *config.Config) {
+	if !reflect.DeepEqual(old.Views.Jobs, s.config.Views.Jobs) {
+		if view, err := s.viewMgr.GetView("jobs"); err == nil {
+			if jobs, ok := view.(*views.JobsView); ok {
+				jobs.SetSubmissionConfig(&s.config.Views.Jobs.Submission)
+				jobs.SetViewConfig(&s.config.Views.Jobs)
+			}
+		}
+	}
+	if old.Views.Nodes.GroupBy != s.config.Views.Nodes.GroupBy && s.config.Views.Nodes.GroupBy != "" {
+		if view, err := s.viewMgr.GetView("nodes"); err == nil {
+			if nodes, ok := view.(*views.NodesView); ok {
+				nodes.SetInitialGroupBy(s.config.Views.Nodes.GroupBy)
+			}
+		}
+	}
+	if !reflect.DeepEqual(old.Views.Topology, s.config.Views.Topology) {
+		if view, err := s.viewMgr.GetView("topology"); err == nil {
+			if topology, ok := view.(*views.TopologyView); ok {
+				topology.SetTopologyConfig(s.config.Views.Topology)
+			}
+		}
+	}
+	if old.Views.SharedDir != s.config.Views.SharedDir {
+		s.savedViews = s.newSavedViewStore()
+	}
+}
+
+// applyClusterConfig reconnects when the active cluster or its connection
+// changed, and re-applies the safety policy and audit log when only they
+// changed
+func (s *S9s) applyClusterConfig(old *config.Config) error {
+	oldEntry, _ := old.GetCluster(old.DefaultCluster)
+	newEntry, _ := s.config.GetCluster(s.config.DefaultCluster)
+	connectionChanged := old.DefaultCluster != s.config.DefaultCluster ||
+		!reflect.DeepEqual(old.Cluster, s.config.Cluster) ||
+		!reflect.DeepEqual(oldEntry, newEntry)
+	policyChanged := !reflect.DeepEqual(old.Safety, s.config.Safety) ||
+		!reflect.DeepEqual(old.Audit, s.config.Audit)
+
+	// Admin mode toggled with :admin survives unless the config changes it
+	admin := s.policy != nil && s.policy.AdminMode() && old.Safety.AdminMode == s.config.Safety.AdminMode
+	defer func() {
+		if admin && s.policy != nil {
+			s.policy.SetAdminMode(true)
+		}
+	}()
+
+	if connectionChanged && !s.config.UseMockClient {
+		if old.DefaultCluster != s.config.DefaultCluster {
+			s.saveHistory()
+			s.history = newHistoryStore(s.config)
+		}
+		raw, err := createSlurmClient(s.ctx, s.config, s.cancel)
+		if err != nil {
+			return fmt.Errorf("connecting to %s: %w", s.config.DefaultCluster, err)
+		}
+		if err := s.connect(raw); err != nil {
+			return err
+		}
+		s.header.SetClusterName(s.config.DefaultCluster)
+		return s.viewMgr.RefreshCurrentView()
+	}
+	if connectionChanged || policyChanged {
+		return s.connect(baseClient(s.client))
+	}
+	return nil
+}
+
+// connect wraps raw with the safety policy and audit log of the config and
+// the metrics history, and hands it to every view
+func (s *S9s) connect(raw dao.SlurmClient) error {
+	client, policy, auditLog, err := wrapClient(raw, s.config, audit.SourceTUI)
+	if err != nil {
+		return err
+	}
+	policy.SetConfirmer(s)
+	auditLog.SetReporter(s)
+	s.policy = policy
+	client = timeseries.NewRecordingClient(client, s.history)
+	s.client = client
+
+	for _, view := range s.viewMgr.GetViews() {
+		if setter, ok := view.(views.ClientSetter); ok {
+			setter.SetClient(client)
+		}
+		if setter, ok := view.(views.HistorySetter); ok {
+			setter.SetHistory(s.history)
+		}
+	}
+	return nil
+}
+
+// baseClient returns the client at the bottom of a wrapper chain
+func baseClient(client dao.SlurmClient) dao.SlurmClient {
+	for {
+		wrapper, ok := client.(interface{ Unwrap() dao.SlurmClient })
+		if !ok {
+			return client
+		}
+		client = wrapper.Unwrap()
+	}
+}
+
+// applyPluginConfig unloads the plugins the config disables and loads the
+// enabled plugins with a path that are not loaded yet. It returns the
+// newly loaded plugins.
+func (s *S9s) applyPluginConfig() ([]plugins.Plugin, error) {
+	var loaded []plugins.Plugin
+	var errs []error
+	for _, pc := range s.config.Plugins {
+		existing := s.pluginManager.GetPlugin(pc.Name)
+		switch {
+		case !pc.Enabled && existing != nil:
+			s.removePluginViews(existing)
+			if err := s.pluginManager.UnloadPlugin(pc.Name); err != nil {
+				errs = append(errs, err)
+			}
+		case pc.Enabled && existing == nil && pc.Path != "":
+			if err := s.pluginManager.LoadPlugin(os.ExpandEnv(pc.Path)); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if p := s.pluginManager.GetPlugin(pc.Name); p != nil {
+				loaded = append(loaded, p)
+			}
+		}
+	}
+	return loaded, errors.Join(errs...)
+}
+
+// removePluginViews removes the views of a plugin that is being unloaded
+func (s *S9s) removePluginViews(p plugins.Plugin) {
+	for _, pluginView := range p.GetViews() {
+		name := pluginView.GetName()
+		if _, err := s.viewMgr.GetView(name); err != nil {
+			continue // Not registered yet
+		}
+		if err := s.viewMgr.RemoveView(name); err != nil {
+			s.logger.Warn().Err(err).Str("view", name).Msg("Failed to remove plugin view")
+			continue
+		}
+		s.contentPages.RemovePage(name)
+	}
+	s.header.SetViews(s.viewMgr.GetViewNames())
+}
@@ -0,0 +1,111 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/jontk/s9s/internal/config"
+	"github.com/jontk/s9s/internal/ui/components"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseShortcutKey(t *testing.T) {
+	tests := []struct {
+		spec string
+		want shortcutKey
+	}{
+		{"x", shortcutKey{key: tcell.KeyRune, ch: 'x'}},
+		{"X", shortcutKey{key: tcell.KeyRune, ch: 'X'}},
+		{"F5", shortcutKey{key: tcell.KeyF5}},
+		{"ctrl+e", shortcutKey{key: tcell.KeyCtrlE}},
+		{"PgDn", shortcutKey{key: tcell.KeyPgDn}},
+		{"alt+j", shortcutKey{key: tcell.KeyRune, ch: 'j', alt: true}},
+		{"Alt+J", shortcutKey{key: tcell.KeyRune, ch: 'J', alt: true}},
+	}
+	for _, tt := range tests {
+		got, err := parseShortcutKey(tt.spec)
+		require.NoError(t, err, tt.spec)
+		assert.Equal(t, tt.want, got, tt.spec)
+	}
+
+	for _, spec := range []string{"", "ctrl+", "alt+jk", "hyper+x"} {
+		_, err := parseShortcutKey(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestApplyShortcuts(t *testing.T) {
+	s := &S9s{config: &config.Config{Shortcuts: []config.ShortcutConfig{
+		{Key: "ctrl+e", Action: "view running"},
+		{Key: "nope+x", Action: "jobs"},
+	}}}
+
+	err := s.applyShortcuts()
+	assert.ErrorContains(t, err, `unknown shortcut key "nope+x"`)
+	assert.Equal(t, map[shortcutKey]string{{key: tcell.KeyCtrlE}: "view running"}, s.shortcuts)
+
+	event := tcell.NewEventKey(tcell.KeyCtrlE, 0, tcell.ModCtrl)
+	assert.Equal(t, shortcutKey{key: tcell.KeyCtrlE}, eventShortcutKey(event))
+}
+
+func newReloadTestApp(t *testing.T, cfg *config.Config) *S9s {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	s := &S9s{ctx: ctx, cancel: cancel, config: cfg, statusBar: components.NewStatusBar()}
+	t.Cleanup(s.stopRefreshTimer)
+	return s
+}
+
+func reloadTestConfig() *config.Config {
+	cfg := config.DefaultConfig()
+	cfg.DefaultCluster = "prod"
+	cfg.Clusters = []config.ClusterContext{{
+		Name:    "prod",
+		Cluster: config.ClusterConfig{Endpoint: "https://prod:6820", APIVersion: "v0.0.43"},
+	}}
+	return cfg
+}
+
+func TestApplyReloadedConfig(t *testing.T) {
+	cfg := reloadTestConfig()
+	require.NoError(t, cfg.SetCurrentCluster())
+	s := newReloadTestApp(t, cfg)
+
+	loaded := reloadTestConfig()
+	loaded.RefreshRate = "30s"
+	s.applyReloadedConfig(loaded)
+	assert.Equal(t, "30s", s.config.RefreshRate)
+	assert.NotNil(t, s.refreshTicker)
+	assert.Same(t, loaded, s.fileConfig)
+
+	// A file that fails validation leaves the running configuration alone
+	running := s.config
+	invalid := reloadTestConfig()
+	invalid.Audit.MaxSizeMB = -1
+	s.applyReloadedConfig(invalid)
+	assert.Same(t, running, s.config)
+	assert.Same(t, loaded, s.fileConfig)
+}
+
+func TestWithRuntimeSettingsKeepsActiveCluster(t *testing.T) {
+	cfg := reloadTestConfig()
+	cfg.Clusters = append(cfg.Clusters, config.ClusterContext{Name: "dev"})
+	cfg.DefaultCluster = "dev" // switched with :ctx
+	cfg.UseMockClient = true
+	s := newReloadTestApp(t, cfg)
+	s.fileConfig = reloadTestConfig()
+
+	loaded := reloadTestConfig()
+	loaded.RefreshRate = "30s"
+	got := s.withRuntimeSettings(loaded)
+	assert.Equal(t, "dev", got.DefaultCluster)
+	assert.True(t, got.UseMockClient)
+	assert.Equal(t, "30s", got.RefreshRate)
+
+	// Naming another cluster in the file switches to it
+	loaded.DefaultCluster = "staging"
+	assert.Equal(t, "staging", s.withRuntimeSettings(loaded).DefaultCluster)
+}
@@ -0,0 +1,53 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jontk/s9s/internal/config"
+	"github.com/jontk/s9s/internal/logging"
+	"github.com/jontk/s9s/internal/timeseries"
+)
+
+// newHistoryStore creates the metrics history for the current cluster,
+// restoring the saved history when persistence is enabled
+func newHistoryStore(cfg *config.Config) *timeseries.Store {
+	retention, err := time.ParseDuration(cfg.History.Retention)
+	if err != nil {
+		retention = timeseries.DefaultRetention
+	}
+	store := timeseries.NewStore(timeseries.DefaultResolution, retention)
+
+	if cfg.History.Persist {
+		if err := store.Load(historyPath(cfg)); err != nil {
+			logging.GetLogger().Warn().Err(err).Msg("Failed to load metrics history")
+		}
+	}
+	return store
+}
+
+// clusterName returns the name of the current cluster
+func clusterName(cfg *config.Config) string {
+	if cfg.DefaultCluster == "" {
+		return "default"
+	}
+	return cfg.DefaultCluster
+}
+
+// historyPath returns the history file of the current cluster
+func historyPath(cfg *config.Config) string {
+	cluster := strings.NewReplacer("/", "_", "\\", "_", "..", "_").Replace(clusterName(cfg))
+	return filepath.Join(os.Getenv("HOME"), ".s9s", "history", cluster+".json")
+}
+
+// saveHistory writes the metrics history when persistence is enabled
+func (s *S9s) saveHistory() {
+	if s.history == nil || !s.config.History.Persist {
+		return
+	}
+	if err := s.history.Save(historyPath(s.config)); err != nil {
+		s.logger.Warn().Err(err).Msg("Failed to save metrics history")
+	}
+}
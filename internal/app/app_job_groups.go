@@ -0,0 +1,135 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/jontk/s9s/internal/sweep"
+	"github.com/rivo/tview"
+)
+
+// groupSubcommands are the :group arguments that are not group names
+var groupSubcommands = []string{"delete", "list"}
+
+// newJobGroupStore loads the job groups
+func (s *S9s) newJobGroupStore() *sweep.GroupStore {
+	store := sweep.NewGroupStore(sweep.DefaultGroupsPath())
+	if err := store.Load(); err != nil {
+		s.logger.Warn().Err(err).Msg("Skipping unreadable job groups")
+	}
+	return store
+}
+
+// cmdGroup handles :group NAME, :group delete NAME and :group list
+func (s *S9s) cmdGroup(args []string) CommandResult {
+	if s.jobGroups == nil {
+		return CommandResult{Success: false, Message: "Job groups are not available"}
+	}
+	if len(args) == 0 {
+		return s.showJobGroups()
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "list":
+		return s.showJobGroups()
+	case "delete":
+		if len(args) != 2 {
+			return CommandResult{Success: false, Message: "Usage: :group delete NAME"}
+		}
+		if err := s.jobGroups.Delete(args[1]); err != nil {
+			return CommandResult{Success: false, Message: err.Error(), Error: err}
+		}
+		return CommandResult{Success: true, Message: fmt.Sprintf("Deleted group %s; its jobs are left alone", args[1])}
+	}
+
+	if len(args) > 1 {
+		return CommandResult{Success: false, Message: "Usage: :group NAME"}
+	}
+	return s.showJobGroup(args[0])
+}
+
+// showJobGroup switches to the jobs view filtered to the jobs of a group
+func (s *S9s) showJobGroup(name string) CommandResult {
+	jobsView, result := s.focusJobsView()
+	if jobsView == nil {
+		return result
+	}
+	if err := jobsView.ShowGroup(name); err != nil {
+		return CommandResult{Success: false, Message: err.Error(), Error: err}
+	}
+	group, _ := s.jobGroups.Get(name)
+	return CommandResult{Success: true, Message: fmt.Sprintf("Showing the %d job(s) of group %s", len(group.JobIDs), name)}
+}
+
+// showJobGroups displays the job groups; selecting one shows its jobs
+func (s *S9s) showJobGroups() CommandResult {
+	groups := s.jobGroups.List()
+	if len(groups) == 0 {
+		return CommandResult{Success: true, Message: "No job groups. Submit a parameter sweep from the job submission form to create one"}
+	}
+
+	list := tview.NewList()
+	list.SetBorder(true).
+		SetTitle(" Job Groups ").
+		SetTitleAlign(tview.AlignCenter)
+
+	for _, group := range groups {
+		name := group.Name
+		title := fmt.Sprintf("%s [gray](%d jobs)[white]", name, len(group.JobIDs))
+		list.AddItem(title, jobGroupSummary(group), 0, func() {
+			s.pages.RemovePage("job-groups")
+			result := s.showJobGroup(name)
+			if result.Success {
+				s.statusBar.Success(result.Message)
+			} else {
+				s.statusBar.Error(result.Message)
+			}
+		})
+	}
+
+	list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEsc {
+			s.pages.RemovePage("job-groups")
+			if currentView, err := s.viewMgr.GetCurrentView(); err == nil {
+				s.app.SetFocus(currentView.Render())
+			}
+			return nil
+		}
+		return event
+	})
+
+	centeredModal := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(list, 0, 3, true).
+			AddItem(nil, 0, 1, false), 0, 3, true).
+		AddItem(nil, 0, 1, false)
+
+	s.pages.AddPage("job-groups", centeredModal, true, true)
+	s.app.SetFocus(list)
+	return CommandResult{Success: true, Message: fmt.Sprintf("%d job group(s)", len(groups))}
+}
+
+// jobGroupSummary describes a job group in one line
+func jobGroupSummary(group sweep.Group) string {
+	parts := []string{"created " + group.Created.Format("2006-01-02 15:04")}
+	if group.Mode == sweep.ModeArray {
+		parts = append(parts, "job array")
+	}
+	if len(group.Variables) > 0 {
+		parts = append(parts, "sweeps "+strings.Join(group.Variables, ", "))
+	}
+	return strings.Join(parts, " | ")
+}
+
+// getJobGroupCompletions completes :group arguments: subcommands and group
+// names for the first argument, group names after delete
+func (s *S9s) getJobGroupCompletions(text string) []string {
+	var names []string
+	if s.jobGroups != nil {
+		names = s.jobGroups.Names()
+	}
+	return completeNamedArgs(text, groupSubcommands, names)
+}
@@ -30,6 +30,11 @@ func (s *S9s) setupKeyboardShortcuts() {
 			return event
 		}
 
+		// Custom shortcuts from the config take precedence over built-in keys
+		if s.handleShortcut(event) {
+			return nil
+		}
+
 		// Try to handle by key type - rune keys go to global rune handlers first
 		if event.Key() == tcell.KeyRune {
 			result := s.handleRuneKey(event, isModalOpen)
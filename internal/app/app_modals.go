@@ -39,6 +39,10 @@ func (s *S9s) showHelp() {
   [yellow]:qos[white]           QoS view        [yellow]:performance[white]   Performance view
   [yellow]:refresh, :r[white]   Refresh         [yellow]:layout[white]        Layout switcher
   [yellow]:quit, :q[white]      Quit            [yellow]:help, :h[white]      Help
+  [yellow]:view NAME[white]     Saved view      [yellow]:view save NAME[white] Save current view
+  [yellow]:group NAME[white]    Job group       [yellow]:group list[white]    Job groups
+  [yellow]:pipeline RUN[white]  Pipeline run    [yellow]:pipeline submit FILE[white] Submit pipeline
+  [yellow]:notes[white]         Job notes       [yellow]:notes LABEL[white]   Jobs with label
 
 [teal]Common View Keys:[white] [gray](available in all data views)[white]
   [yellow]/[white] Filter    [yellow]f[white] Adv Filter    [yellow]Ctrl+F[white] Search    [yellow]S[white] Sort    [yellow]R[white] Refresh    [yellow]e[white] Export
@@ -143,11 +147,12 @@ func (s *S9s) showConfiguration() {
 		if newConfig == nil {
 			return
 		}
-		// Apply new configuration to running components (currently:
-		// swap in the new config and re-arm the refresh ticker with
-		// the new cadence). Other UI settings are picked up on the
-		// next redraw or view switch.
-		s.ApplyConfig(newConfig)
+		// Apply new configuration to running components. Other UI
+		// settings are picked up on the next redraw or view switch.
+		if err := s.ApplyConfig(newConfig); err != nil {
+			s.statusBar.Error(fmt.Sprintf("Configuration applied with errors: %v", err))
+			return
+		}
 		s.statusBar.Success("Configuration applied")
 	})
 
@@ -209,8 +214,12 @@ func (s *S9s) switchCluster(clusterName string) {
 
 	s.statusBar.Info(fmt.Sprintf("Switching to cluster %s...", clusterName))
 
+	// History is kept per cluster
+	s.saveHistory()
+
 	// Update config
 	s.config.DefaultCluster = clusterName
+	_ = s.config.SetCurrentCluster()
 
 	// Create new client
 	newClient, err := createSlurmClient(s.ctx, s.config, s.cancel)
@@ -219,14 +228,11 @@ func (s *S9s) switchCluster(clusterName string) {
 		return
 	}
 
-	// Update app client
-	s.client = newClient
-
-	// Update all views
-	for _, view := range s.viewMgr.GetViews() {
-		if setter, ok := view.(views.ClientSetter); ok {
-			setter.SetClient(newClient)
-		}
+	// Update app client and all views
+	s.history = newHistoryStore(s.config)
+	if err := s.connect(newClient); err != nil {
+		s.statusBar.Error(fmt.Sprintf("Failed to connect to %s: %v", clusterName, err))
+		return
 	}
 
 	// Update header
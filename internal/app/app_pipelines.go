@@ -0,0 +1,182 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/jontk/s9s/internal/pipeline"
+	"github.com/jontk/s9s/internal/views"
+	"github.com/rivo/tview"
+)
+
+// pipelineSubcommands are the :pipeline arguments that are not run IDs
+var pipelineSubcommands = []string{"delete", "list", "submit"}
+
+// newPipelineRunStore loads the submitted pipeline runs
+func (s *S9s) newPipelineRunStore() *pipeline.RunStore {
+	store := pipeline.NewRunStore(pipeline.DefaultRunsPath())
+	if err := store.Load(); err != nil {
+		s.logger.Warn().Err(err).Msg("Skipping unreadable pipeline runs")
+	}
+	return store
+}
+
+// cmdPipeline handles :pipeline RUN, :pipeline submit FILE,
+// :pipeline delete RUN and :pipeline list
+func (s *S9s) cmdPipeline(args []string) CommandResult {
+	if s.pipelineRuns == nil {
+		return CommandResult{Success: false, Message: "Pipelines are not available"}
+	}
+	if len(args) == 0 {
+		return s.showPipelineRuns()
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "list":
+		return s.showPipelineRuns()
+	case "submit":
+		if len(args) != 2 {
+			return CommandResult{Success: false, Message: "Usage: :pipeline submit FILE"}
+		}
+		return s.submitPipeline(args[1])
+	case "delete":
+		if len(args) != 2 {
+			return CommandResult{Success: false, Message: "Usage: :pipeline delete RUN"}
+		}
+		if err := s.pipelineRuns.Delete(args[1]); err != nil {
+			return CommandResult{Success: false, Message: err.Error(), Error: err}
+		}
+		return CommandResult{Success: true, Message: fmt.Sprintf("Deleted pipeline run %s; its jobs are left alone", args[1])}
+	}
+
+	if len(args) > 1 {
+		return CommandResult{Success: false, Message: "Usage: :pipeline RUN"}
+	}
+	run, ok := s.pipelineRuns.Get(args[0])
+	if !ok {
+		return CommandResult{Success: false, Message: fmt.Sprintf("pipeline run %s not found", args[0])}
+	}
+	s.showPipelineRun(run)
+	return CommandResult{Success: true, Message: fmt.Sprintf("Pipeline %s", run.ID)}
+}
+
+// submitPipeline submits the pipeline spec at path in the background and
+// shows the run once its stages are submitted. Stages without a working
+// directory run in the directory of the spec.
+func (s *S9s) submitPipeline(path string) CommandResult {
+	spec, err := pipeline.Load(path)
+	if err != nil {
+		return CommandResult{Success: false, Message: err.Error(), Error: err}
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return CommandResult{Success: false, Message: err.Error(), Error: err}
+	}
+
+	run := pipeline.NewRun(spec, time.Now())
+	run.SpecFile = absPath
+	run.WorkingDir = filepath.Dir(absPath)
+	resolve := views.PipelineResolver(&s.config.Views.Jobs.Submission, spec, run.WorkingDir)
+
+	go func() {
+		err := run.Submit(s.client.Jobs(), resolve, func(stage, jobID string) {
+			s.app.QueueUpdateDraw(func() {
+				s.statusBar.Info(fmt.Sprintf("Pipeline %s: submitted %s as job %s", spec.Name, stage, jobID))
+			})
+		})
+		var saveErr error
+		if len(run.Jobs) > 0 {
+			saveErr = s.pipelineRuns.Add(run)
+		}
+		s.app.QueueUpdateDraw(func() {
+			switch {
+			case err != nil && len(run.Jobs) == 0:
+				s.statusBar.Error(fmt.Sprintf("Pipeline %s: %v", spec.Name, err))
+				return
+			case err != nil:
+				s.statusBar.Error(fmt.Sprintf("Pipeline %s: submitted %d of %d stages, then failed: %v", spec.Name, len(run.Jobs), len(spec.Stages), err))
+			case saveErr != nil:
+				s.statusBar.Error(fmt.Sprintf("Pipeline %s was submitted but could not be saved: %v", spec.Name, saveErr))
+			default:
+				s.statusBar.Success(fmt.Sprintf("Submitted pipeline %s (%d stages)", run.ID, len(run.Jobs)))
+			}
+			s.showPipelineRun(run)
+		})
+	}()
+	return CommandResult{Success: true, Message: fmt.Sprintf("Submitting pipeline %s...", spec.Name)}
+}
+
+// showPipelineRun shows a pipeline run with its live stage states
+func (s *S9s) showPipelineRun(run *pipeline.Run) {
+	view := views.NewPipelineView(s.client, s.app, s.pages, s.pipelineRuns, &s.config.Views.Jobs.Submission)
+	view.Show(run)
+}
+
+// showPipelineRuns displays the pipeline runs; selecting one shows it
+func (s *S9s) showPipelineRuns() CommandResult {
+	runs := s.pipelineRuns.List()
+	if len(runs) == 0 {
+		return CommandResult{Success: true, Message: "No pipeline runs. Submit one with :pipeline submit FILE"}
+	}
+
+	list := tview.NewList()
+	list.SetBorder(true).
+		SetTitle(" Pipelines ").
+		SetTitleAlign(tview.AlignCenter)
+
+	for _, run := range runs {
+		title := fmt.Sprintf("%s [gray](%d stages)[white]", run.ID, len(run.Spec.Stages))
+		list.AddItem(title, pipelineRunSummary(run), 0, func() {
+			s.pages.RemovePage("pipeline-runs")
+			s.showPipelineRun(run)
+		})
+	}
+
+	list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEsc {
+			s.pages.RemovePage("pipeline-runs")
+			if currentView, err := s.viewMgr.GetCurrentView(); err == nil {
+				s.app.SetFocus(currentView.Render())
+			}
+			return nil
+		}
+		return event
+	})
+
+	centeredModal := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(list, 0, 3, true).
+			AddItem(nil, 0, 1, false), 0, 3, true).
+		AddItem(nil, 0, 1, false)
+
+	s.pages.AddPage("pipeline-runs", centeredModal, true, true)
+	s.app.SetFocus(list)
+	return CommandResult{Success: true, Message: fmt.Sprintf("%d pipeline run(s)", len(runs))}
+}
+
+// pipelineRunSummary describes a pipeline run in one line
+func pipelineRunSummary(run *pipeline.Run) string {
+	parts := []string{"submitted " + run.Created.Format("2006-01-02 15:04")}
+	if run.SpecFile != "" {
+		parts = append(parts, run.SpecFile)
+	}
+	if run.Spec.Description != "" {
+		parts = append(parts, run.Spec.Description)
+	}
+	return strings.Join(parts, " | ")
+}
+
+// getPipelineCompletions completes :pipeline arguments: subcommands and run
+// IDs for the first argument, run IDs after delete
+func (s *S9s) getPipelineCompletions(text string) []string {
+	var ids []string
+	if s.pipelineRuns != nil {
+		ids = s.pipelineRuns.IDs()
+	}
+	return completeNamedArgs(text, pipelineSubcommands, ids)
+}
@@ -31,6 +31,11 @@ func (s *S9s) loadPlugins() error {
 	localPluginDir := filepath.Join(".", "plugins")
 	_ = s.pluginManager.LoadPluginsFromDirectory(localPluginDir)
 
+	// Load and unload plugins as enabled in the config
+	if _, err := s.applyPluginConfig(); err != nil {
+		s.logger.Warn().Err(err).Msg("Failed to apply plugin configuration")
+	}
+
 	return nil
 }
 
@@ -45,38 +50,44 @@ func (s *S9s) registerPluginViews() error {
 	s.logger.Info().Int("count", len(pluginViews)).Msg("Registering plugin views")
 
 	for _, pluginView := range pluginViews {
-		s.logger.Info().Str("view", pluginView.GetName()).Msg("Registering plugin view")
+		s.registerPluginView(pluginView)
+	}
 
-		// Create context with tview application for plugin initialization
-		ctx := context.WithValue(s.ctx, appContextKey, s.app)
+	// Update header with new view names (including plugin views)
+	s.header.SetViews(s.viewMgr.GetViewNames())
 
-		// Initialize the plugin view
-		if err := pluginView.Init(ctx); err != nil {
-			s.logger.Warn().Err(err).Str("view", pluginView.GetName()).Msg("Failed to initialize plugin view")
-			continue
-		}
+	return nil
+}
 
-		// Create adapter to bridge plugin view to s9s view interface
-		viewAdapter := &PluginViewAdapter{
-			pluginView: pluginView,
-		}
+// registerPluginView initializes a plugin view and adds it to the view
+// manager and content pages
+func (s *S9s) registerPluginView(pluginView plugins.View) {
+	s.logger.Info().Str("view", pluginView.GetName()).Msg("Registering plugin view")
 
-		// Add to view manager
-		if err := s.viewMgr.AddView(viewAdapter); err != nil {
-			s.logger.Warn().Err(err).Str("view", pluginView.GetName()).Msg("Failed to add plugin view")
-			continue
-		}
+	// Create context with tview application for plugin initialization
+	ctx := context.WithValue(s.ctx, appContextKey, s.app)
 
-		// Add to content pages
-		s.contentPages.AddPage(pluginView.GetName(), pluginView.Render(), true, false)
+	// Initialize the plugin view
+	if err := pluginView.Init(ctx); err != nil {
+		s.logger.Warn().Err(err).Str("view", pluginView.GetName()).Msg("Failed to initialize plugin view")
+		return
+	}
 
-		s.logger.Info().Str("view", pluginView.GetName()).Msg("Successfully registered plugin view")
+	// Create adapter to bridge plugin view to s9s view interface
+	viewAdapter := &PluginViewAdapter{
+		pluginView: pluginView,
 	}
 
-	// Update header with new view names (including plugin views)
-	s.header.SetViews(s.viewMgr.GetViewNames())
+	// Add to view manager
+	if err := s.viewMgr.AddView(viewAdapter); err != nil {
+		s.logger.Warn().Err(err).Str("view", pluginView.GetName()).Msg("Failed to add plugin view")
+		return
+	}
 
-	return nil
+	// Add to content pages
+	s.contentPages.AddPage(pluginView.GetName(), pluginView.Render(), true, false)
+
+	s.logger.Info().Str("view", pluginView.GetName()).Msg("Successfully registered plugin view")
 }
 
 // PluginViewAdapter adapts a plugin view to the s9s view interface.
@@ -0,0 +1,117 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/jontk/s9s/internal/ui/styles"
+	"github.com/rivo/tview"
+)
+
+// typedConfirmationPage is the page name of the typed confirmation prompt
+const typedConfirmationPage = "typed-confirmation"
+
+// ConfirmTyped asks the user to type expected before action runs on a
+// target protected by the safety policy. It blocks until the prompt is
+// answered, so it must not be called from the UI goroutine; every
+// mutation in the TUI is sent from a background goroutine.
+func (s *S9s) ConfirmTyped(action, target, expected string) bool {
+	answer := make(chan bool, 1)
+	s.app.QueueUpdateDraw(func() {
+		s.showTypedConfirmation(action, target, expected, answer)
+	})
+	select {
+	case confirmed := <-answer:
+		return confirmed
+	case <-s.ctx.Done():
+		return false
+	}
+}
+
+// AuditFailed shows that an action ran but could not be recorded in the
+// audit log
+func (s *S9s) AuditFailed(action string, err error) {
+	if !s.isRunning.Load() {
+		return
+	}
+	s.app.QueueUpdateDraw(func() {
+		if s.statusBar != nil {
+			s.statusBar.Error(fmt.Sprintf("%s was not recorded in the audit log: %v", action, err))
+		}
+	})
+}
+
+// showTypedConfirmation shows the prompt of ConfirmTyped and sends the
+// outcome to answer
+func (s *S9s) showTypedConfirmation(action, target, expected string, answer chan<- bool) {
+	previous := s.app.GetFocus()
+
+	input := styles.NewStyledInputField().
+		SetLabel(fmt.Sprintf("Type %s to confirm: ", expected)).
+		SetFieldWidth(30)
+	input.SetDoneFunc(func(key tcell.Key) {
+		if key != tcell.KeyEnter && key != tcell.KeyEscape {
+			return
+		}
+		confirmed := key == tcell.KeyEnter && strings.TrimSpace(input.GetText()) == expected
+		if key == tcell.KeyEnter && !confirmed {
+			s.statusBar.Error(fmt.Sprintf("Typed name does not match %s", expected))
+		}
+		s.pages.RemovePage(typedConfirmationPage)
+		if previous != nil {
+			s.app.SetFocus(previous)
+		}
+		answer <- confirmed
+	})
+
+	text := tview.NewTextView().
+		SetDynamicColors(true).
+		SetText(fmt.Sprintf("[yellow]%s[white] is protected by the safety policy.\nConfirm [red]%s[white] by typing its name, or press Esc.",
+			tview.Escape(target), tview.Escape(action)))
+
+	form := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(text, 2, 0, false).
+		AddItem(input, 1, 0, true)
+	form.SetBorder(true).
+		SetTitle(" Protected Resource ").
+		SetTitleAlign(tview.AlignCenter)
+
+	modal := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(form, 5, 0, true).
+			AddItem(nil, 0, 1, false), 70, 0, true).
+		AddItem(nil, 0, 1, false)
+
+	s.pages.AddPage(typedConfirmationPage, modal, true, true)
+	s.app.SetFocus(input)
+}
+
+// cmdAdmin turns admin mode, which allows acting on other users' jobs, on
+// or off
+func (s *S9s) cmdAdmin(args []string) CommandResult {
+	if s.policy == nil {
+		return CommandResult{Success: false, Message: "No safety policy is active"}
+	}
+
+	on := !s.policy.AdminMode()
+	if len(args) == 1 {
+		switch strings.ToLower(args[0]) {
+		case "on":
+			on = true
+		case "off":
+			on = false
+		default:
+			return CommandResult{Success: false, Message: "Usage: :admin [on|off]"}
+		}
+	}
+
+	s.policy.SetAdminMode(on)
+	if on {
+		return CommandResult{Success: true, Message: "Admin mode on: other users' jobs can be cancelled and requeued"}
+	}
+	return CommandResult{Success: true, Message: "Admin mode off"}
+}
@@ -0,0 +1,197 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/jontk/s9s/internal/ui/filters"
+	"github.com/jontk/s9s/internal/views"
+	"github.com/rivo/tview"
+)
+
+// viewSubcommands are the :view arguments that are not view names
+var viewSubcommands = []string{"delete", "list", "save"}
+
+// newSavedViewStore loads the personal and shared saved views
+func (s *S9s) newSavedViewStore() *filters.SavedViewStore {
+	store := filters.NewSavedViewStore(filters.DefaultSavedViewsDir(), s.config.Views.SharedDir)
+	if err := store.Load(); err != nil {
+		s.logger.Warn().Err(err).Msg("Skipping invalid saved views")
+	}
+	return store
+}
+
+// cmdView handles :view NAME, :view save NAME [DESCRIPTION], :view delete NAME
+// and :view list
+func (s *S9s) cmdView(args []string) CommandResult {
+	if s.savedViews == nil {
+		return CommandResult{Success: false, Message: "Saved views are not available"}
+	}
+	if len(args) == 0 {
+		return s.showSavedViews()
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "list":
+		return s.showSavedViews()
+	case "save":
+		if len(args) < 2 {
+			return CommandResult{Success: false, Message: "Usage: :view save NAME [DESCRIPTION]"}
+		}
+		return s.saveCurrentView(args[1], strings.Join(args[2:], " "))
+	case "delete":
+		if len(args) != 2 {
+			return CommandResult{Success: false, Message: "Usage: :view delete NAME"}
+		}
+		if err := s.savedViews.Delete(args[1]); err != nil {
+			return CommandResult{Success: false, Message: err.Error(), Error: err}
+		}
+		return CommandResult{Success: true, Message: fmt.Sprintf("Deleted view %s", args[1])}
+	}
+
+	if len(args) > 1 {
+		return CommandResult{Success: false, Message: "Usage: :view NAME"}
+	}
+	return s.applySavedView(args[0])
+}
+
+// saveCurrentView saves the filter, sort and columns of the current view
+func (s *S9s) saveCurrentView(name, description string) CommandResult {
+	for _, sub := range viewSubcommands {
+		if strings.EqualFold(name, sub) {
+			return CommandResult{Success: false, Message: fmt.Sprintf("%q is reserved and cannot name a view", name)}
+		}
+	}
+
+	current, err := s.viewMgr.GetCurrentView()
+	if err != nil {
+		return CommandResult{Success: false, Message: "No current view", Error: err}
+	}
+	target, ok := current.(views.SavedViewTarget)
+	if !ok {
+		return CommandResult{Success: false, Message: fmt.Sprintf("The %s view cannot be saved", current.Name())}
+	}
+
+	saved := target.CaptureSavedView()
+	saved.Name = name
+	saved.Description = description
+	if err := s.savedViews.Save(saved); err != nil {
+		return CommandResult{Success: false, Message: fmt.Sprintf("Failed to save view: %v", err), Error: err}
+	}
+	return CommandResult{Success: true, Message: fmt.Sprintf("Saved %s view as %s", saved.ViewType, name)}
+}
+
+// applySavedView switches to the view a saved view belongs to and restores it
+func (s *S9s) applySavedView(name string) CommandResult {
+	saved, ok := s.savedViews.Get(name)
+	if !ok {
+		return CommandResult{Success: false, Message: fmt.Sprintf("Unknown view: %s", name)}
+	}
+
+	view, err := s.viewMgr.GetView(saved.ViewType)
+	if err != nil {
+		return CommandResult{Success: false, Message: fmt.Sprintf("View %s needs the %s view, which is not available", name, saved.ViewType), Error: err}
+	}
+	target, ok := view.(views.SavedViewTarget)
+	if !ok {
+		return CommandResult{Success: false, Message: fmt.Sprintf("The %s view cannot restore saved views", saved.ViewType)}
+	}
+
+	s.switchToView(saved.ViewType)
+	if err := target.ApplySavedView(saved); err != nil {
+		return CommandResult{Success: false, Message: err.Error(), Error: err}
+	}
+	return CommandResult{Success: true, Message: fmt.Sprintf("Applied view %s", name)}
+}
+
+// showSavedViews displays the saved views; selecting one applies it
+func (s *S9s) showSavedViews() CommandResult {
+	saved := s.savedViews.List()
+	if len(saved) == 0 {
+		return CommandResult{Success: true, Message: "No saved views. Save the current view with :view save NAME"}
+	}
+
+	list := tview.NewList()
+	list.SetBorder(true).
+		SetTitle(" Saved Views ").
+		SetTitleAlign(tview.AlignCenter)
+
+	for _, view := range saved {
+		name := view.Name
+		title := fmt.Sprintf("%s [gray](%s)[white]", name, view.ViewType)
+		if view.Shared {
+			title += " [cyan]shared[white]"
+		}
+		list.AddItem(title, savedViewSummary(view), 0, func() {
+			s.pages.RemovePage("saved-views")
+			result := s.applySavedView(name)
+			if result.Success {
+				s.statusBar.Success(result.Message)
+			} else {
+				s.statusBar.Error(result.Message)
+			}
+		})
+	}
+
+	list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEsc {
+			s.pages.RemovePage("saved-views")
+			if currentView, err := s.viewMgr.GetCurrentView(); err == nil {
+				s.app.SetFocus(currentView.Render())
+			}
+			return nil
+		}
+		return event
+	})
+
+	centeredModal := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(list, 0, 3, true).
+			AddItem(nil, 0, 1, false), 0, 3, true).
+		AddItem(nil, 0, 1, false)
+
+	s.pages.AddPage("saved-views", centeredModal, true, true)
+	s.app.SetFocus(list)
+	return CommandResult{Success: true, Message: fmt.Sprintf("%d saved view(s)", len(saved))}
+}
+
+// savedViewSummary describes a saved view in one line
+func savedViewSummary(view filters.SavedView) string {
+	var parts []string
+	if view.Description != "" {
+		parts = append(parts, view.Description)
+	}
+	if view.Filter != "" {
+		parts = append(parts, "filter: "+view.Filter)
+	}
+	if view.SortColumn != "" {
+		direction := "asc"
+		if view.SortDescending {
+			direction = "desc"
+		}
+		parts = append(parts, fmt.Sprintf("sort: %s %s", view.SortColumn, direction))
+	}
+	if len(view.Columns) > 0 {
+		parts = append(parts, "columns: "+strings.Join(view.Columns, ","))
+	}
+	if view.GroupBy != "" {
+		parts = append(parts, "group: "+view.GroupBy)
+	}
+	if len(parts) == 0 {
+		return "all rows, default layout"
+	}
+	return strings.Join(parts, " | ")
+}
+
+// getSavedViewCompletions completes :view arguments: subcommands and view
+// names for the first argument, view names after delete
+func (s *S9s) getSavedViewCompletions(text string) []string {
+	var names []string
+	if s.savedViews != nil {
+		names = s.savedViews.Names()
+	}
+	return completeNamedArgs(text, viewSubcommands, names)
+}
@@ -0,0 +1,96 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// shortcutKey identifies a key press bound by a custom shortcut
+type shortcutKey struct {
+	key tcell.Key
+	ch  rune
+	alt bool
+}
+
+// namedKeys maps lower-cased key names such as "f5", "ctrl+e" or "pgdn"
+// to their keys
+var namedKeys = func() map[string]tcell.Key {
+	keys := make(map[string]tcell.Key, len(tcell.KeyNames))
+	for key, name := range tcell.KeyNames {
+		keys[strings.ToLower(strings.ReplaceAll(name, "-", "+"))] = key
+	}
+	return keys
+}()
+
+// parseShortcutKey parses the key of a custom shortcut: a single
+// character such as "x", a named key such as "F5" or "ctrl+e", or "alt+"
+// followed by a character
+func parseShortcutKey(spec string) (shortcutKey, error) {
+	spec = strings.TrimSpace(spec)
+	if len([]rune(spec)) == 1 {
+		return shortcutKey{key: tcell.KeyRune, ch: []rune(spec)[0]}, nil
+	}
+
+	lower := strings.ToLower(spec)
+	if strings.HasPrefix(lower, "alt+") {
+		// The character keeps its case: alt+x and alt+X differ
+		runes := []rune(spec[len("alt+"):])
+		if len(runes) != 1 {
+			return shortcutKey{}, fmt.Errorf("alt shortcut %q must be alt+ and one character", spec)
+		}
+		return shortcutKey{key: tcell.KeyRune, ch: runes[0], alt: true}, nil
+	}
+	if key, ok := namedKeys[lower]; ok {
+		return shortcutKey{key: key}, nil
+	}
+	return shortcutKey{}, fmt.Errorf("unknown shortcut key %q", spec)
+}
+
+// eventShortcutKey returns the shortcut key of a key press
+func eventShortcutKey(event *tcell.EventKey) shortcutKey {
+	k := shortcutKey{key: event.Key()}
+	if k.key == tcell.KeyRune {
+		k.ch = event.Rune()
+		k.alt = event.Modifiers()&tcell.ModAlt != 0
+	}
+	return k
+}
+
+// applyShortcuts binds the custom shortcuts of the config, replacing any
+// bound before. Shortcuts with an invalid key are skipped and reported.
+func (s *S9s) applyShortcuts() error {
+	shortcuts := make(map[shortcutKey]string, len(s.config.Shortcuts))
+	var errs []error
+	for _, shortcut := range s.config.Shortcuts {
+		key, err := parseShortcutKey(shortcut.Key)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if strings.TrimSpace(shortcut.Action) == "" {
+			continue
+		}
+		shortcuts[key] = shortcut.Action
+	}
+	s.shortcuts = shortcuts
+	return errors.Join(errs...)
+}
+
+// handleShortcut runs the command bound to a key press by a custom
+// shortcut and reports whether there was one. Ctrl+C is never rebound, and
+// plain characters are left to a focused input field.
+func (s *S9s) handleShortcut(event *tcell.EventKey) bool {
+	key := eventShortcutKey(event)
+	action, ok := s.shortcuts[key]
+	if !ok || key.key == tcell.KeyCtrlC {
+		return false
+	}
+	if key.key == tcell.KeyRune && !key.alt && s.hasInputFieldFocus() {
+		return false
+	}
+	s.executeCommand(action)
+	return true
+}
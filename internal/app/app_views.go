@@ -3,6 +3,7 @@ package app
 import (
 	"fmt"
 
+	"github.com/jontk/s9s/internal/audit"
 	"github.com/jontk/s9s/internal/errs"
 	"github.com/jontk/s9s/internal/views"
 )
@@ -23,6 +24,8 @@ func (s *S9s) initViews() error {
 		{"dashboard", s.registerDashboardView},
 		{"health", s.registerHealthView},
 		{"performance", s.registerPerformanceView},
+		{"topology", s.registerTopologyView},
+		{"audit", s.registerAuditView},
 	}
 
 	for _, v := range viewRegistry {
@@ -51,6 +54,8 @@ func (s *S9s) registerJobsView() error {
 	view.SetSubmissionConfig(&s.config.Views.Jobs.Submission)
 	view.SetViewConfig(&s.config.Views.Jobs)
 	view.SetSlurmUser(s.config.ResolveSlurmUser())
+	view.SetGroupStore(s.jobGroups)
+	view.SetAnnotationStore(s.annotations, clusterName(s.config))
 	if s.streamManager != nil {
 		view.SetStreamManager(s.streamManager)
 	}
@@ -72,7 +77,9 @@ func (s *S9s) registerNodesView() error {
 func (s *S9s) registerPartitionsView() error {
 	view := views.NewPartitionsView(s.client)
 	view.SetApp(s.app)
+	view.SetStatusBar(s.statusBar)
 	view.SetPages(s.pages)
+	view.SetHistory(s.history)
 	return s.addViewToApp("partitions", view)
 }
 
@@ -114,6 +121,8 @@ func (s *S9s) registerDashboardView() error {
 	view := views.NewDashboardView(s.client)
 	view.SetApp(s.app)
 	view.SetPages(s.pages)
+	view.SetHistory(s.history)
+	view.SetHealthMonitor(s.health)
 	return s.addViewToApp("dashboard", view)
 }
 
@@ -122,6 +131,8 @@ func (s *S9s) registerHealthView() error {
 	view := views.NewHealthView(s.client)
 	view.SetApp(s.app)
 	view.SetPages(s.pages)
+	view.SetHistory(s.history)
+	view.SetHealthMonitor(s.health)
 	return s.addViewToApp("health", view)
 }
 
@@ -133,6 +144,25 @@ func (s *S9s) registerPerformanceView() error {
 	return s.addViewToApp("performance", view)
 }
 
+// registerTopologyView registers the topology view
+func (s *S9s) registerTopologyView() error {
+	view := views.NewTopologyView(s.client)
+	view.SetApp(s.app)
+	view.SetStatusBar(s.statusBar)
+	view.SetPages(s.pages)
+	view.SetTopologyConfig(s.config.Views.Topology)
+	return s.addViewToApp("topology", view)
+}
+
+// registerAuditView registers the audit log view
+func (s *S9s) registerAuditView() error {
+	view := views.NewAuditView(audit.PathFor(&s.config.Audit), s.config.Audit.Enabled)
+	view.SetApp(s.app)
+	view.SetStatusBar(s.statusBar)
+	view.SetPages(s.pages)
+	return s.addViewToApp("audit", view)
+}
+
 // registerAppDiagnosticsView registers the app diagnostics view (debug only)
 func (s *S9s) registerAppDiagnosticsView() error {
 	view := views.NewAppDiagnosticsView(s.client)
@@ -0,0 +1,248 @@
+// Package audit records every mutating action performed through s9s in an
+// append-only JSON lines log, optionally forwarded in syslog or journald
+// format, so that cancels, drains and submissions can be traced back to the
+// person who made them.
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jontk/s9s/internal/config"
+)
+
+// Sources of audited actions
+const (
+	SourceTUI = "tui"
+	SourceCLI = "cli"
+)
+
+// Results of audited actions
+const (
+	ResultSuccess = "success"
+	ResultError   = "error"
+)
+
+// Entry is one audited action
+type Entry struct {
+	Timestamp time.Time         `json:"timestamp"`
+	OSUser    string            `json:"os_user"`
+	SlurmUser string            `json:"slurm_user,omitempty"`
+	Cluster   string            `json:"cluster,omitempty"`
+	Source    string            `json:"source"`
+	Action    string            `json:"action"`
+	Targets   []string          `json:"targets,omitempty"`
+	Params    map[string]string `json:"params,omitempty"`
+	Result    string            `json:"result"`
+	Error     string            `json:"error,omitempty"`
+}
+
+// Failed reports whether the action returned an error
+func (e *Entry) Failed() bool {
+	return e.Result == ResultError
+}
+
+// DefaultPath returns the default audit log location, ~/.s9s/audit.log
+func DefaultPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = os.TempDir()
+	}
+	return filepath.Join(home, ".s9s", "audit.log")
+}
+
+// PathFor returns the audit log path configured in cfg
+func PathFor(cfg *config.AuditConfig) string {
+	if cfg != nil && cfg.File != "" {
+		return cfg.File
+	}
+	return DefaultPath()
+}
+
+// Reporter is told about actions that ran but could not be recorded
+type Reporter interface {
+	AuditFailed(action string, err error)
+}
+
+// Logger appends entries to the audit log. Files are opened for every
+// entry so that several s9s processes can share one log.
+type Logger struct {
+	path      string
+	maxBytes  int64
+	maxFiles  int
+	forward   formatter
+	forwardTo string
+	reporter  Reporter
+	mu        sync.Mutex
+}
+
+// NewLogger returns a logger for cfg, or nil if auditing is disabled. It
+// fails if the log cannot be opened for writing.
+func NewLogger(cfg *config.AuditConfig) (*Logger, error) {
+	if cfg == nil || !cfg.Enabled {
+		return nil, nil
+	}
+
+	l := &Logger{
+		path:     PathFor(cfg),
+		maxBytes: int64(cfg.MaxSizeMB) * 1024 * 1024,
+		maxFiles: cfg.MaxFiles,
+	}
+	if cfg.Forward.Format != "" {
+		forward, err := newFormatter(cfg.Forward.Format)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.Forward.File == "" {
+			return nil, fmt.Errorf("audit forward format %s has no file", cfg.Forward.Format)
+		}
+		l.forward = forward
+		l.forwardTo = cfg.Forward.File
+	}
+
+	// Open the files once so an unwritable log is found before any action
+	if err := l.append(l.path, nil); err != nil {
+		return nil, err
+	}
+	if l.forward != nil {
+		if err := l.append(l.forwardTo, nil); err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+// SetReporter sets who is told when an entry cannot be written. Without
+// one the failure is only logged.
+func (l *Logger) SetReporter(reporter Reporter) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.reporter = reporter
+}
+
+// reportFailure tells the reporter that action could not be recorded
+func (l *Logger) reportFailure(action string, err error) {
+	l.mu.Lock()
+	reporter := l.reporter
+	l.mu.Unlock()
+	if reporter != nil {
+		reporter.AuditFailed(action, err)
+	}
+}
+
+// Path returns the path of the audit log
+func (l *Logger) Path() string {
+	return l.path
+}
+
+// Record appends an entry to the log and to the forward file, if any
+func (l *Logger) Record(entry *Entry) error {
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := l.append(l.path, append(line, '\n')); err != nil {
+		return err
+	}
+	if l.forward != nil {
+		if err := l.append(l.forwardTo, l.forward(entry)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// append writes data to path, rotating the file first if it has reached
+// the size limit
+func (l *Logger) append(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+	if err := l.rotateIfNeeded(path); err != nil {
+		return fmt.Errorf("failed to rotate audit log: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return f.Close()
+}
+
+// rotateIfNeeded shifts path to path.1, path.1 to path.2 and so on when
+// path has reached the size limit, dropping the oldest file
+func (l *Logger) rotateIfNeeded(path string) error {
+	if l.maxBytes <= 0 {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.Size() < l.maxBytes {
+		return nil
+	}
+
+	if l.maxFiles <= 0 {
+		return os.Remove(path)
+	}
+	_ = os.Remove(rotatedPath(path, l.maxFiles))
+	for i := l.maxFiles - 1; i >= 1; i-- {
+		_ = os.Rename(rotatedPath(path, i), rotatedPath(path, i+1))
+	}
+	return os.Rename(path, rotatedPath(path, 1))
+}
+
+// rotatedPath returns the name of the n-th rotated copy of path
+func rotatedPath(path string, n int) string {
+	return fmt.Sprintf("%s.%d", path, n)
+}
+
+// ReadRecent returns up to limit entries from the log at path and its
+// rotated copies, newest first. Lines that are not valid entries are
+// skipped. A missing log is not an error.
+func ReadRecent(path string, limit int) ([]Entry, error) {
+	var entries []Entry
+	for n := 0; limit <= 0 || len(entries) < limit; n++ {
+		file := path
+		if n > 0 {
+			file = rotatedPath(path, n)
+		}
+		data, err := os.ReadFile(file)
+		if os.IsNotExist(err) {
+			break
+		}
+		if err != nil {
+			return entries, fmt.Errorf("failed to read audit log: %w", err)
+		}
+
+		lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+		for i := len(lines) - 1; i >= 0; i-- {
+			var entry Entry
+			if json.Unmarshal([]byte(lines[i]), &entry) != nil || entry.Action == "" {
+				continue
+			}
+			entries = append(entries, entry)
+			if limit > 0 && len(entries) == limit {
+				break
+			}
+		}
+	}
+	return entries, nil
+}
@@ -0,0 +1,148 @@
+package audit
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jontk/s9s/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testConfig(t *testing.T) *config.AuditConfig {
+	t.Helper()
+	return &config.AuditConfig{
+		Enabled:  true,
+		File:     filepath.Join(t.TempDir(), "audit", "audit.log"),
+		MaxFiles: 2,
+	}
+}
+
+func TestNewLoggerDisabled(t *testing.T) {
+	logger, err := NewLogger(&config.AuditConfig{Enabled: false})
+	require.NoError(t, err)
+	assert.Nil(t, logger)
+}
+
+func TestNewLoggerRejectsUnknownForwardFormat(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.Forward = config.AuditForwardConfig{Format: "gelf", File: filepath.Join(t.TempDir(), "out")}
+	_, err := NewLogger(cfg)
+	assert.Error(t, err)
+}
+
+func TestNewLoggerRejectsUnwritableLog(t *testing.T) {
+	blocker := filepath.Join(t.TempDir(), "blocker")
+	require.NoError(t, os.WriteFile(blocker, nil, 0o600))
+
+	_, err := NewLogger(&config.AuditConfig{Enabled: true, File: filepath.Join(blocker, "audit.log")})
+	assert.Error(t, err)
+}
+
+func TestRecordAndReadRecent(t *testing.T) {
+	cfg := testConfig(t)
+	logger, err := NewLogger(cfg)
+	require.NoError(t, err)
+
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i, action := range []string{"job.cancel", "job.hold", "node.drain"} {
+		require.NoError(t, logger.Record(&Entry{
+			Timestamp: base.Add(time.Duration(i) * time.Minute),
+			OSUser:    "alice",
+			Source:    SourceTUI,
+			Action:    action,
+			Targets:   []string{"1"},
+			Result:    ResultSuccess,
+		}))
+	}
+
+	info, err := os.Stat(cfg.File)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	entries, err := ReadRecent(cfg.File, 2)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "node.drain", entries[0].Action)
+	assert.Equal(t, "job.hold", entries[1].Action)
+}
+
+func TestReadRecentMissingLog(t *testing.T) {
+	entries, err := ReadRecent(filepath.Join(t.TempDir(), "audit.log"), 10)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestRotation(t *testing.T) {
+	cfg := testConfig(t)
+	logger, err := NewLogger(cfg)
+	require.NoError(t, err)
+	// Rotate before every entry
+	logger.maxBytes = 1
+
+	for _, action := range []string{"job.cancel", "job.hold", "job.release", "job.requeue"} {
+		require.NoError(t, logger.Record(&Entry{Action: action, Result: ResultSuccess}))
+	}
+
+	_, err = os.Stat(cfg.File + ".2")
+	require.NoError(t, err)
+	_, err = os.Stat(cfg.File + ".3")
+	assert.True(t, os.IsNotExist(err), "only maxFiles rotated logs are kept")
+
+	entries, err := ReadRecent(cfg.File, 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, "job.requeue", entries[0].Action)
+	assert.Equal(t, "job.hold", entries[2].Action)
+}
+
+func TestForwardSyslog(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.Forward = config.AuditForwardConfig{Format: "syslog", File: filepath.Join(t.TempDir(), "syslog")}
+	logger, err := NewLogger(cfg)
+	require.NoError(t, err)
+
+	require.NoError(t, logger.Record(&Entry{Action: "node.drain", Targets: []string{"c1"}, Result: ResultSuccess}))
+	require.NoError(t, logger.Record(&Entry{Action: "job.cancel", Result: ResultError, Error: "denied"}))
+
+	data, err := os.ReadFile(cfg.Forward.File)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+	assert.True(t, strings.HasPrefix(lines[0], "<85>1 "), lines[0])
+	assert.True(t, strings.HasPrefix(lines[1], "<84>1 "), lines[1])
+	assert.Contains(t, lines[0], " s9s ")
+
+	var entry Entry
+	require.NoError(t, json.Unmarshal([]byte(lines[0][strings.Index(lines[0], "{"):]), &entry))
+	assert.Equal(t, "node.drain", entry.Action)
+}
+
+func TestForwardJournal(t *testing.T) {
+	entry := &Entry{
+		Timestamp: time.Unix(1700000000, 0),
+		OSUser:    "alice",
+		Action:    "job.notify",
+		Targets:   []string{"42"},
+		Params:    map[string]string{"message": "line one\nline two"},
+		Result:    ResultSuccess,
+	}
+	out := string(formatJournal(entry))
+
+	assert.Contains(t, out, "__REALTIME_TIMESTAMP=1700000000000000\n")
+	assert.Contains(t, out, "MESSAGE=alice job.notify 42\n")
+	assert.Contains(t, out, "PRIORITY=5\n")
+	assert.Contains(t, out, "S9S_ACTION=job.notify\n")
+	assert.Contains(t, out, "S9S_PARAMS=")
+	assert.True(t, strings.HasSuffix(out, "\n\n"), "entries end with a blank line")
+
+	entry.Error = "first\nsecond"
+	entry.Result = ResultError
+	out = string(formatJournal(entry))
+	assert.Contains(t, out, "S9S_ERROR\n", "multi-line values use the binary form")
+	assert.Contains(t, out, "PRIORITY=4\n")
+}
@@ -0,0 +1,289 @@
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	osuser "os/user"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jontk/s9s/internal/dao"
+	"github.com/jontk/s9s/internal/debug"
+)
+
+// Identity describes who performs the actions recorded by a client
+type Identity struct {
+	OSUser    string
+	SlurmUser string
+	Cluster   string
+	Source    string
+}
+
+// CurrentOSUser returns the name of the user running s9s
+func CurrentOSUser() string {
+	if u, err := osuser.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+// auditingClient wraps a SlurmClient and records every job, node and
+// partition mutation made through it
+type auditingClient struct {
+	dao.SlurmClient
+	rec *recorder
+}
+
+// NewClient returns a client that records mutations made through client
+// to logger. With a nil logger client is returned unchanged.
+func NewClient(client dao.SlurmClient, logger *Logger, id Identity) dao.SlurmClient {
+	if logger == nil {
+		return client
+	}
+	if id.OSUser == "" {
+		id.OSUser = CurrentOSUser()
+	}
+	return &auditingClient{SlurmClient: client, rec: &recorder{logger: logger, id: id}}
+}
+
+// NewUnavailableClient returns a client that refuses every job, node and
+// partition mutation because the audit log could not be opened. Reads are
+// passed through.
+func NewUnavailableClient(client dao.SlurmClient, err error) dao.SlurmClient {
+	return &auditingClient{SlurmClient: client, rec: &recorder{unavailable: err}}
+}
+
+// UnavailableError refuses an action that cannot be recorded because the
+// audit log could not be opened
+type UnavailableError struct {
+	Action string
+	Err    error
+}
+
+func (e *UnavailableError) Error() string {
+	return fmt.Sprintf("%s refused: the audit log is unavailable (%v); fix it or set audit.enabled: false to run without auditing",
+		e.Action, e.Err)
+}
+
+func (e *UnavailableError) Unwrap() error {
+	return e.Err
+}
+
+// Unwrap returns the wrapped client
+func (c *auditingClient) Unwrap() dao.SlurmClient {
+	return c.SlurmClient
+}
+
+func (c *auditingClient) Jobs() dao.JobManager {
+	return &auditingJobManager{JobManager: c.SlurmClient.Jobs(), rec: c.rec}
+}
+
+func (c *auditingClient) Nodes() dao.NodeManager {
+	return &auditingNodeManager{NodeManager: c.SlurmClient.Nodes(), rec: c.rec}
+}
+
+func (c *auditingClient) Partitions() dao.PartitionManager {
+	return &auditingPartitionManager{PartitionManager: c.SlurmClient.Partitions(), rec: c.rec}
+}
+
+// recorder writes entries for one identity
+type recorder struct {
+	logger      *Logger
+	id          Identity
+	unavailable error // Why the log could not be opened; all actions are refused
+}
+
+// allow refuses action when the audit log is unavailable
+func (r *recorder) allow(action string) error {
+	if r.unavailable != nil {
+		return &UnavailableError{Action: action, Err: r.unavailable}
+	}
+	return nil
+}
+
+// record logs the outcome of an action. A failure to write the audit log
+// does not fail the action, which has already happened; it is passed to
+// the reporter of the logger instead.
+func (r *recorder) record(action string, targets []string, params map[string]string, err error) {
+	entry := &Entry{
+		Timestamp: time.Now(),
+		OSUser:    r.id.OSUser,
+		SlurmUser: r.id.SlurmUser,
+		Cluster:   r.id.Cluster,
+		Source:    r.id.Source,
+		Action:    action,
+		Targets:   targets,
+		Params:    params,
+		Result:    ResultSuccess,
+	}
+	if err != nil {
+		entry.Result = ResultError
+		entry.Error = err.Error()
+	}
+	if writeErr := r.logger.Record(entry); writeErr != nil {
+		debug.Logger.Printf("Failed to record %s in the audit log: %v", action, writeErr)
+		r.logger.reportFailure(action, writeErr)
+	}
+}
+
+// auditingJobManager records job mutations
+type auditingJobManager struct {
+	dao.JobManager
+	rec *recorder
+}
+
+func (m *auditingJobManager) Submit(job *dao.JobSubmission) (string, error) {
+	if err := m.rec.allow(dao.ActionJobSubmit); err != nil {
+		return "", err
+	}
+	id, err := m.JobManager.Submit(job)
+	var targets []string
+	if id != "" {
+		targets = []string{id}
+	}
+	m.rec.record(dao.ActionJobSubmit, targets, submissionParams(job), err)
+	return id, err
+}
+
+func (m *auditingJobManager) Cancel(id string) error {
+	if err := m.rec.allow(dao.ActionJobCancel); err != nil {
+		return err
+	}
+	err := m.JobManager.Cancel(id)
+	m.rec.record(dao.ActionJobCancel, []string{id}, nil, err)
+	return err
+}
+
+func (m *auditingJobManager) Hold(id string) error {
+	if err := m.rec.allow(dao.ActionJobHold); err != nil {
+		return err
+	}
+	err := m.JobManager.Hold(id)
+	m.rec.record(dao.ActionJobHold, []string{id}, nil, err)
+	return err
+}
+
+func (m *auditingJobManager) Release(id string) error {
+	if err := m.rec.allow(dao.ActionJobRelease); err != nil {
+		return err
+	}
+	err := m.JobManager.Release(id)
+	m.rec.record(dao.ActionJobRelease, []string{id}, nil, err)
+	return err
+}
+
+func (m *auditingJobManager) Requeue(id string) (*dao.Job, error) {
+	if err := m.rec.allow(dao.ActionJobRequeue); err != nil {
+		return nil, err
+	}
+	job, err := m.JobManager.Requeue(id)
+	m.rec.record(dao.ActionJobRequeue, []string{id}, nil, err)
+	return job, err
+}
+
+func (m *auditingJobManager) Notify(id string, message string) error {
+	if err := m.rec.allow(dao.ActionJobNotify); err != nil {
+		return err
+	}
+	err := m.JobManager.Notify(id, message)
+	m.rec.record(dao.ActionJobNotify, []string{id}, map[string]string{"message": message}, err)
+	return err
+}
+
+// submissionParams returns the audited fields of a submission. The script
+// itself is recorded as a hash, since it may embed credentials.
+func submissionParams(job *dao.JobSubmission) map[string]string {
+	if job == nil {
+		return nil
+	}
+	params := make(map[string]string)
+	set := func(key, value string) {
+		if value != "" {
+			params[key] = value
+		}
+	}
+	setInt := func(key string, value int) {
+		if value != 0 {
+			params[key] = strconv.Itoa(value)
+		}
+	}
+
+	set("name", job.Name)
+	set("partition", job.Partition)
+	set("account", job.Account)
+	set("qos", job.QoS)
+	setInt("nodes", job.Nodes)
+	setInt("cpus", job.CPUs)
+	set("memory", job.Memory)
+	setInt("gpus", job.GPUs)
+	set("time_limit", job.TimeLimit)
+	set("array", job.ArraySpec)
+	set("dependencies", strings.Join(job.Dependencies, ","))
+	set("working_directory", job.WorkingDir)
+	set("command", job.Command)
+	if job.Script != "" {
+		sum := sha256.Sum256([]byte(job.Script))
+		params["script_sha256"] = hex.EncodeToString(sum[:])
+	}
+	return params
+}
+
+// auditingNodeManager records node mutations
+type auditingNodeManager struct {
+	dao.NodeManager
+	rec *recorder
+}
+
+func (m *auditingNodeManager) Drain(name string, reason string) error {
+	if err := m.rec.allow(dao.ActionNodeDrain); err != nil {
+		return err
+	}
+	err := m.NodeManager.Drain(name, reason)
+	m.rec.record(dao.ActionNodeDrain, []string{name}, map[string]string{"reason": reason}, err)
+	return err
+}
+
+func (m *auditingNodeManager) Resume(name string) error {
+	if err := m.rec.allow(dao.ActionNodeResume); err != nil {
+		return err
+	}
+	err := m.NodeManager.Resume(name)
+	m.rec.record(dao.ActionNodeResume, []string{name}, nil, err)
+	return err
+}
+
+func (m *auditingNodeManager) SetState(name string, state string) error {
+	if err := m.rec.allow(dao.ActionNodeSetState); err != nil {
+		return err
+	}
+	err := m.NodeManager.SetState(name, state)
+	m.rec.record(dao.ActionNodeSetState, []string{name}, map[string]string{"state": state}, err)
+	return err
+}
+
+// auditingPartitionManager records partition mutations
+type auditingPartitionManager struct {
+	dao.PartitionManager
+	rec *recorder
+}
+
+func (m *auditingPartitionManager) SetState(name string, state string) error {
+	if err := m.rec.allow(dao.ActionPartitionSetState); err != nil {
+		return err
+	}
+	err := m.PartitionManager.SetState(name, state)
+	m.rec.record(dao.ActionPartitionSetState, []string{name}, map[string]string{"state": state}, err)
+	return err
+}
+
+func (m *auditingPartitionManager) SetMaxTime(name string, limit string) error {
+	if err := m.rec.allow(dao.ActionPartitionSetMaxTime); err != nil {
+		return err
+	}
+	err := m.PartitionManager.SetMaxTime(name, limit)
+	m.rec.record(dao.ActionPartitionSetMaxTime, []string{name}, map[string]string{"max_time": limit}, err)
+	return err
+}
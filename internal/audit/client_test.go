@@ -0,0 +1,121 @@
+package audit
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jontk/s9s/internal/dao"
+	"github.com/jontk/s9s/pkg/slurm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewClientWithoutLogger(t *testing.T) {
+	mock := slurm.NewMockClient()
+	assert.Same(t, mock, NewClient(mock, nil, Identity{}))
+}
+
+func TestAuditingClient(t *testing.T) {
+	mock := slurm.NewMockClient()
+	mock.SetDelay(0)
+	cfg := testConfig(t)
+	logger, err := NewLogger(cfg)
+	require.NoError(t, err)
+	client := NewClient(mock, logger, Identity{OSUser: "alice", SlurmUser: "svc", Cluster: "prod", Source: SourceCLI})
+
+	jobs, err := client.Jobs().List(nil)
+	require.NoError(t, err)
+	require.NotEmpty(t, jobs.Jobs)
+	nodes, err := client.Nodes().List(nil)
+	require.NoError(t, err)
+	require.NotEmpty(t, nodes.Nodes)
+
+	require.NoError(t, client.Nodes().Drain(nodes.Nodes[0].Name, "maintenance"))
+	require.Error(t, client.Jobs().Cancel("no-such-job"))
+	id, err := client.Jobs().Submit(&dao.JobSubmission{Name: "test", Script: "#!/bin/bash\necho secret", Partition: "compute"})
+	require.NoError(t, err)
+
+	entries, err := ReadRecent(cfg.File, 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 3, "only mutations are recorded")
+
+	submit := entries[0]
+	assert.Equal(t, "job.submit", submit.Action)
+	assert.Equal(t, []string{id}, submit.Targets)
+	assert.Equal(t, "compute", submit.Params["partition"])
+	assert.Len(t, submit.Params["script_sha256"], 64)
+	assert.NotContains(t, submit.Params, "script")
+
+	cancel := entries[1]
+	assert.Equal(t, "job.cancel", cancel.Action)
+	assert.Equal(t, ResultError, cancel.Result)
+	assert.Contains(t, cancel.Error, "not found")
+
+	drain := entries[2]
+	assert.Equal(t, "node.drain", drain.Action)
+	assert.Equal(t, ResultSuccess, drain.Result)
+	assert.Equal(t, "maintenance", drain.Params["reason"])
+	assert.Equal(t, "alice", drain.OSUser)
+	assert.Equal(t, "svc", drain.SlurmUser)
+	assert.Equal(t, "prod", drain.Cluster)
+	assert.Equal(t, SourceCLI, drain.Source)
+
+	unwrapper, ok := client.(interface{ Unwrap() dao.SlurmClient })
+	require.True(t, ok)
+	assert.Same(t, mock, unwrapper.Unwrap())
+}
+
+// fakeReporter remembers the actions that were not recorded
+type fakeReporter struct {
+	actions []string
+}
+
+func (r *fakeReporter) AuditFailed(action string, _ error) {
+	r.actions = append(r.actions, action)
+}
+
+func TestAuditingClientReportsWriteFailures(t *testing.T) {
+	mock := slurm.NewMockClient()
+	mock.SetDelay(0)
+	cfg := testConfig(t)
+	logger, err := NewLogger(cfg)
+	require.NoError(t, err)
+	reporter := &fakeReporter{}
+	logger.SetReporter(reporter)
+	client := NewClient(mock, logger, Identity{OSUser: "alice"})
+
+	// The log directory turns into a file after startup
+	dir := filepath.Dir(cfg.File)
+	require.NoError(t, os.RemoveAll(dir))
+	require.NoError(t, os.WriteFile(dir, nil, 0o600))
+
+	nodes, err := client.Nodes().List(nil)
+	require.NoError(t, err)
+	require.NoError(t, client.Nodes().Drain(nodes.Nodes[0].Name, "maintenance"), "the action itself succeeded")
+	assert.Equal(t, []string{dao.ActionNodeDrain}, reporter.actions)
+}
+
+func TestUnavailableClientRefusesMutations(t *testing.T) {
+	mock := slurm.NewMockClient()
+	mock.SetDelay(0)
+	client := NewUnavailableClient(mock, errors.New("permission denied"))
+
+	nodes, err := client.Nodes().List(nil)
+	require.NoError(t, err, "reads are not audited")
+	name := nodes.Nodes[0].Name
+
+	err = client.Nodes().Drain(name, "maintenance")
+	var unavailable *UnavailableError
+	require.ErrorAs(t, err, &unavailable)
+	assert.Equal(t, dao.ActionNodeDrain, unavailable.Action)
+	assert.Contains(t, err.Error(), "audit.enabled: false")
+
+	_, err = client.Jobs().Submit(&dao.JobSubmission{Name: "test", Script: "#!/bin/bash\ntrue", Partition: "compute"})
+	assert.ErrorAs(t, err, &unavailable)
+
+	node, err := mock.Nodes().Get(name)
+	require.NoError(t, err)
+	assert.False(t, node.ParsedState().Has(dao.NodeFlagDrain), "the refused drain never reached the cluster")
+}
@@ -0,0 +1,106 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// formatter renders an entry for the forward file
+type formatter func(entry *Entry) []byte
+
+// Syslog facility and severities used for forwarded entries
+const (
+	facilityAuthPriv = 10
+	severityWarning  = 4
+	severityNotice   = 5
+)
+
+func newFormatter(format string) (formatter, error) {
+	switch strings.ToLower(format) {
+	case "syslog":
+		return formatSyslog, nil
+	case "journald":
+		return formatJournal, nil
+	default:
+		return nil, fmt.Errorf("unknown audit forward format %q (use syslog or journald)", format)
+	}
+}
+
+// severity returns the syslog severity of an entry
+func severity(entry *Entry) int {
+	if entry.Failed() {
+		return severityWarning
+	}
+	return severityNotice
+}
+
+// summary returns a one-line description of an entry
+func summary(entry *Entry) string {
+	msg := fmt.Sprintf("%s %s %s", entry.OSUser, entry.Action, strings.Join(entry.Targets, ","))
+	if entry.Cluster != "" {
+		msg += " on " + entry.Cluster
+	}
+	if entry.Failed() {
+		msg += ": " + entry.Error
+	}
+	return msg
+}
+
+// formatSyslog renders an entry as an RFC 5424 line with the JSON entry as
+// its message
+func formatSyslog(entry *Entry) []byte {
+	host, _ := os.Hostname()
+	if host == "" {
+		host = "-"
+	}
+	body, _ := json.Marshal(entry)
+	return []byte(fmt.Sprintf("<%d>1 %s %s s9s %d audit - %s\n",
+		facilityAuthPriv*8+severity(entry),
+		entry.Timestamp.UTC().Format(time.RFC3339Nano),
+		host, os.Getpid(), body))
+}
+
+// formatJournal renders an entry in the journal export format, which
+// systemd-journal-remote and journalctl --file can import
+func formatJournal(entry *Entry) []byte {
+	var b bytes.Buffer
+	field := func(name, value string) {
+		if strings.Contains(value, "\n") {
+			// Values with newlines use the binary form
+			b.WriteString(name)
+			b.WriteByte('\n')
+			_ = binary.Write(&b, binary.LittleEndian, uint64(len(value)))
+			b.WriteString(value)
+			b.WriteByte('\n')
+			return
+		}
+		b.WriteString(name + "=" + value + "\n")
+	}
+
+	field("__REALTIME_TIMESTAMP", fmt.Sprintf("%d", entry.Timestamp.UnixMicro()))
+	field("MESSAGE", summary(entry))
+	field("PRIORITY", fmt.Sprintf("%d", severity(entry)))
+	field("SYSLOG_FACILITY", fmt.Sprintf("%d", facilityAuthPriv))
+	field("SYSLOG_IDENTIFIER", "s9s")
+	field("S9S_OS_USER", entry.OSUser)
+	field("S9S_SLURM_USER", entry.SlurmUser)
+	field("S9S_CLUSTER", entry.Cluster)
+	field("S9S_SOURCE", entry.Source)
+	field("S9S_ACTION", entry.Action)
+	field("S9S_TARGETS", strings.Join(entry.Targets, ","))
+	if len(entry.Params) > 0 {
+		params, _ := json.Marshal(entry.Params)
+		field("S9S_PARAMS", string(params))
+	}
+	field("S9S_RESULT", entry.Result)
+	if entry.Error != "" {
+		field("S9S_ERROR", entry.Error)
+	}
+	b.WriteByte('\n')
+	return b.Bytes()
+}
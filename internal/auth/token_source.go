@@ -0,0 +1,389 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jontk/s9s/internal/config"
+	"github.com/jontk/s9s/internal/debug"
+	"github.com/zalando/go-keyring"
+)
+
+const (
+	// DefaultRefreshBefore is how long before its expiry a token is refreshed
+	DefaultRefreshBefore = 5 * time.Minute
+
+	// tokenCommandTimeout bounds how long a token command may run
+	tokenCommandTimeout = 30 * time.Second
+)
+
+// TokenSource supplies the current token of a cluster. Each call may
+// fetch a new token; RefreshingTokenSource caches it until it nears expiry.
+type TokenSource interface {
+	Token(ctx context.Context) (*Token, error)
+}
+
+// NewTokenSource creates the token source configured for a cluster,
+// wrapped so the token is refreshed before it expires. clusterID is the
+// name of the cluster context; with the client ID it keys the cached
+// OAuth2 login.
+func NewTokenSource(cfg *config.TokenSourceConfig, clusterID string) (*RefreshingTokenSource, error) {
+	kinds := cfg.Kinds()
+	if len(kinds) != 1 {
+		return nil, fmt.Errorf("tokenFrom must set exactly one of command, file, keyring, env or oauth2")
+	}
+
+	refreshBefore := DefaultRefreshBefore
+	if cfg.RefreshBefore != "" {
+		d, err := time.ParseDuration(cfg.RefreshBefore)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("invalid tokenFrom.refreshBefore %q", cfg.RefreshBefore)
+		}
+		refreshBefore = d
+	}
+
+	var source TokenSource
+	switch kinds[0] {
+	case "command":
+		if strings.TrimSpace(cfg.Command) == "" {
+			return nil, fmt.Errorf("tokenFrom.command is empty")
+		}
+		source = &CommandTokenSource{Command: cfg.Command, ClusterID: clusterID}
+	case "file":
+		source = &FileTokenSource{Path: cfg.File, ClusterID: clusterID}
+	case "keyring":
+		source = &KeyringTokenSource{Key: cfg.Keyring, ClusterID: clusterID}
+	case "env":
+		source = &EnvTokenSource{Name: cfg.Env, ClusterID: clusterID}
+	case "oauth2":
+		source = NewOAuth2TokenSource(cfg.OAuth2, clusterID, refreshBefore)
+	}
+	return NewRefreshingTokenSource(source, kinds[0], refreshBefore), nil
+}
+
+// NewBearerToken wraps a raw token string, taking its expiry from the
+// "exp" claim when it is a JWT. The signature is not verified; slurmrestd
+// does that. Tokens without an expiry have a zero ExpiresAt.
+func NewBearerToken(raw, clusterID string) *Token {
+	token := &Token{AccessToken: raw, TokenType: "Bearer", ClusterID: clusterID}
+	if expiresAt, ok := JWTExpiry(raw); ok {
+		token.ExpiresAt = expiresAt
+	}
+	return token
+}
+
+// JWTExpiry returns the expiry claim of a JWT without verifying its
+// signature
+func JWTExpiry(raw string) (time.Time, bool) {
+	claims := &jwt.RegisteredClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(raw, claims); err != nil || claims.ExpiresAt == nil {
+		return time.Time{}, false
+	}
+	return claims.ExpiresAt.Time, true
+}
+
+// parseTokenText extracts the token from command output or a token file.
+// It accepts a bare token as well as the "SLURM_JWT=..." line printed by
+// scontrol token, optionally preceded by "export".
+func parseTokenText(text string) (string, error) {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+		if _, value, ok := strings.Cut(line, "SLURM_JWT="); ok {
+			line = value
+		}
+		if token := strings.Trim(line, `"'`); token != "" {
+			return token, nil
+		}
+	}
+	return "", fmt.Errorf("no token found")
+}
+
+// CommandTokenSource runs a command and reads the token from its output.
+// The command is run by sh, so it may quote arguments and use pipes.
+type CommandTokenSource struct {
+	Command   string
+	ClusterID string
+}
+
+// Token runs the command and returns the token it printed
+func (c *CommandTokenSource) Token(ctx context.Context) (*Token, error) {
+	ctx, cancel := context.WithTimeout(ctx, tokenCommandTimeout)
+	defer cancel()
+
+	//nolint:gosec // G204: the command comes from the user's own config, like a credential helper
+	output, err := exec.CommandContext(ctx, "sh", "-c", c.Command).Output()
+	if err != nil {
+		return nil, fmt.Errorf("token command %q failed: %w", c.Command, err)
+	}
+	raw, err := parseTokenText(string(output))
+	if err != nil {
+		return nil, fmt.Errorf("token command %q: %w", c.Command, err)
+	}
+	debug.Logger.Printf("Fetched token for %s from command %q", c.ClusterID, c.Command)
+	return NewBearerToken(raw, c.ClusterID), nil
+}
+
+// FileTokenSource reads the token from a file, so tokens rotated by
+// another process are picked up on refresh
+type FileTokenSource struct {
+	Path      string
+	ClusterID string
+}
+
+// Token reads the token file
+func (f *FileTokenSource) Token(_ context.Context) (*Token, error) {
+	path := f.Path
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, rest)
+		}
+	}
+
+	//nolint:gosec // G304: the token file path comes from the user's own config
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token file: %w", err)
+	}
+	raw, err := parseTokenText(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("token file %s: %w", f.Path, err)
+	}
+	return NewBearerToken(raw, f.ClusterID), nil
+}
+
+// KeyringTokenSource reads the token from the system keyring, where
+// `s9s auth store` puts it
+type KeyringTokenSource struct {
+	Key       string
+	ClusterID string
+}
+
+// Token reads the token from the keyring
+func (k *KeyringTokenSource) Token(_ context.Context) (*Token, error) {
+	raw, err := keyring.Get(KeyringService, k.Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token %q from keyring: %w", k.Key, err)
+	}
+	return NewBearerToken(strings.TrimSpace(raw), k.ClusterID), nil
+}
+
+// StoreKeyringToken saves a token in the system keyring for a
+// KeyringTokenSource
+func StoreKeyringToken(key, raw string) error {
+	return keyring.Set(KeyringService, key, raw)
+}
+
+// EnvTokenSource reads the token from an environment variable
+type EnvTokenSource struct {
+	Name      string
+	ClusterID string
+}
+
+// Token reads the environment variable
+func (e *EnvTokenSource) Token(_ context.Context) (*Token, error) {
+	raw := strings.TrimSpace(os.Getenv(e.Name))
+	if raw == "" {
+		return nil, fmt.Errorf("environment variable %s is not set", e.Name)
+	}
+	return NewBearerToken(raw, e.ClusterID), nil
+}
+
+// invalidator is implemented by sources that keep their own token and must
+// be told when slurmrestd rejects it
+type invalidator interface {
+	Invalidate(rejected string)
+}
+
+// OAuth2TokenSource logs in through an OAuth2/OIDC provider and refreshes
+// with the refresh token. Tokens are cached by a TokenManager so a login
+// survives restarts.
+type OAuth2TokenSource struct {
+	cfg           Config
+	clusterID     string
+	cacheKey      string // Cluster and client ID, so each login is cached apart
+	tokens        *TokenManager
+	refreshBefore time.Duration
+
+	mu            sync.Mutex
+	authenticator Authenticator
+	last          *Token
+	rejected      bool // The last token was rejected and must be refreshed
+}
+
+// NewOAuth2TokenSource creates an OAuth2 token source for a cluster
+// context. The login is refreshed once it is within refreshBefore of its
+// expiry.
+func NewOAuth2TokenSource(cfg *config.OAuth2TokenConfig, clusterID string, refreshBefore time.Duration) *OAuth2TokenSource {
+	authCfg := Config{
+		"provider":               cfg.Provider,
+		"client_id":              cfg.ClientID,
+		"client_secret":          cfg.ClientSecret,
+		"discovery_url":          cfg.DiscoveryURL,
+		"authorization_endpoint": cfg.AuthorizationEndpoint,
+		"token_endpoint":         cfg.TokenEndpoint,
+		"redirect_uri":           cfg.RedirectURI,
+		"scopes":                 cfg.Scopes,
+	}
+	cacheDir := ""
+	if home, err := os.UserHomeDir(); err == nil {
+		cacheDir = filepath.Join(home, ".s9s")
+	}
+	return &OAuth2TokenSource{
+		cfg:           authCfg,
+		clusterID:     clusterID,
+		cacheKey:      clusterID + "/" + cfg.ClientID,
+		tokens:        NewTokenManager(cacheDir, true),
+		refreshBefore: refreshBefore,
+	}
+}
+
+// Token refreshes the last token, or logs in when there is none
+func (o *OAuth2TokenSource) Token(ctx context.Context) (*Token, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.authenticator == nil {
+		authenticator := NewOAuth2Authenticator()
+		if err := authenticator.Initialize(ctx, o.cfg); err != nil {
+			return nil, fmt.Errorf("oauth2: %w", err)
+		}
+		o.authenticator = authenticator
+	}
+	if o.last == nil {
+		if cached, err := o.tokens.GetToken(o.cacheKey); err == nil {
+			o.last = cached
+		}
+	}
+
+	var token *Token
+	var err error
+	switch {
+	case o.last == nil:
+		token, err = o.authenticator.Authenticate(ctx, o.cfg)
+	case !o.rejected && time.Until(o.last.ExpiresAt) > o.refreshBefore:
+		// A cached login that is still fresh
+		token = o.last
+	default:
+		token, err = o.authenticator.RefreshToken(ctx, o.last)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("oauth2: %w", err)
+	}
+
+	token.ClusterID = o.clusterID
+	if token != o.last {
+		if err := o.tokens.SetToken(o.cacheKey, token); err != nil {
+			debug.Logger.Printf("Failed to cache OAuth2 token for %s: %v", o.clusterID, err)
+		}
+	}
+	o.last = token
+	o.rejected = false
+	return token, nil
+}
+
+// Invalidate marks the last token as rejected by slurmrestd, so the next
+// call to Token refreshes it even though it has not expired
+func (o *OAuth2TokenSource) Invalidate(rejected string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.last != nil && o.last.AccessToken == rejected {
+		o.rejected = true
+	}
+}
+
+// RefreshingTokenSource caches the token of a source and fetches a new one
+// when the cached token is within refreshBefore of its expiry or has been
+// rejected by slurmrestd. It is safe for concurrent use.
+type RefreshingTokenSource struct {
+	source        TokenSource
+	kind          string
+	refreshBefore time.Duration
+
+	mu    sync.Mutex
+	token *Token
+}
+
+// NewRefreshingTokenSource wraps source; kind names it in status output
+func NewRefreshingTokenSource(source TokenSource, kind string, refreshBefore time.Duration) *RefreshingTokenSource {
+	return &RefreshingTokenSource{
+		source:        source,
+		kind:          kind,
+		refreshBefore: refreshBefore,
+	}
+}
+
+// Kind returns the kind of the wrapped source, e.g. "command"
+func (r *RefreshingTokenSource) Kind() string {
+	return r.kind
+}
+
+// Token returns the cached token, fetching a new one when it is missing
+// or about to expire. When a refresh fails the cached token is kept for as
+// long as it is still valid.
+func (r *RefreshingTokenSource) Token(ctx context.Context) (*Token, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if r.token != nil && !r.dueLocked(now) {
+		return r.token, nil
+	}
+
+	token, err := r.source.Token(ctx)
+	if err != nil {
+		if r.token != nil && (r.token.ExpiresAt.IsZero() || now.Before(r.token.ExpiresAt)) {
+			debug.Logger.Printf("Token refresh from %s failed, keeping current token: %v", r.kind, err)
+			return r.token, nil
+		}
+		return nil, err
+	}
+	if !token.ExpiresAt.IsZero() {
+		debug.Logger.Printf("Token from %s expires at %s", r.kind, token.ExpiresAt.Format(time.RFC3339))
+	}
+	r.token = token
+	return token, nil
+}
+
+// dueLocked reports whether the cached token should be refreshed. Tokens
+// without an expiry are kept until they are rejected.
+func (r *RefreshingTokenSource) dueLocked(now time.Time) bool {
+	if r.token.ExpiresAt.IsZero() {
+		return false
+	}
+	return !now.Add(r.refreshBefore).Before(r.token.ExpiresAt)
+}
+
+// Invalidate drops the cached token if it is the rejected one, so the next
+// call to Token fetches a new token. Sources that keep their own token are
+// told as well. It returns false when the cache already holds a different
+// token, e.g. one refreshed by a concurrent request.
+func (r *RefreshingTokenSource) Invalidate(rejected string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.token == nil || r.token.AccessToken != rejected {
+		return false
+	}
+	r.token = nil
+	if source, ok := r.source.(invalidator); ok {
+		source.Invalidate(rejected)
+	}
+	return true
+}
+
+// Current returns the cached token without fetching one, or nil
+func (r *RefreshingTokenSource) Current() *Token {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.token
+}
@@ -0,0 +1,222 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jontk/s9s/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testJWT(t *testing.T, expiresAt time.Time) string {
+	t.Helper()
+	raw, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+	}).SignedString([]byte("test"))
+	require.NoError(t, err)
+	return raw
+}
+
+// countingSource hands out numbered tokens that expire after ttl
+type countingSource struct {
+	calls int
+	ttl   time.Duration
+	err   error
+}
+
+func (c *countingSource) Token(_ context.Context) (*Token, error) {
+	c.calls++
+	if c.err != nil {
+		return nil, c.err
+	}
+	token := &Token{AccessToken: string(rune('a' + c.calls))}
+	if c.ttl != 0 {
+		token.ExpiresAt = time.Now().Add(c.ttl)
+	}
+	return token, nil
+}
+
+func TestParseTokenText(t *testing.T) {
+	for input, want := range map[string]string{
+		"eyJabc\n":                               "eyJabc",
+		"SLURM_JWT=eyJabc\n":                     "eyJabc",
+		"# token\nexport SLURM_JWT=\"eyJabc\"\n": "eyJabc",
+	} {
+		got, err := parseTokenText(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, want, got, input)
+	}
+
+	_, err := parseTokenText("\n# nothing\n")
+	assert.Error(t, err)
+}
+
+func TestJWTExpiry(t *testing.T) {
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	got, ok := JWTExpiry(testJWT(t, expiresAt))
+	require.True(t, ok)
+	assert.True(t, expiresAt.Equal(got))
+
+	_, ok = JWTExpiry("not-a-jwt")
+	assert.False(t, ok)
+}
+
+func TestRefreshingTokenSource_RefreshesBeforeExpiry(t *testing.T) {
+	source := &countingSource{ttl: time.Hour}
+	tokens := NewRefreshingTokenSource(source, "test", 5*time.Minute)
+
+	first, err := tokens.Token(context.Background())
+	require.NoError(t, err)
+	second, err := tokens.Token(context.Background())
+	require.NoError(t, err)
+	assert.Same(t, first, second)
+	assert.Equal(t, 1, source.calls)
+
+	// Within refreshBefore of the expiry
+	source.ttl = time.Minute
+	tokens.Invalidate(first.AccessToken)
+	short, err := tokens.Token(context.Background())
+	require.NoError(t, err)
+	refreshed, err := tokens.Token(context.Background())
+	require.NoError(t, err)
+	assert.NotEqual(t, short.AccessToken, refreshed.AccessToken)
+	assert.Equal(t, 3, source.calls)
+}
+
+func TestRefreshingTokenSource_KeepsValidTokenOnError(t *testing.T) {
+	source := &countingSource{ttl: time.Minute}
+	tokens := NewRefreshingTokenSource(source, "test", 5*time.Minute)
+
+	first, err := tokens.Token(context.Background())
+	require.NoError(t, err)
+
+	source.err = errors.New("scontrol unavailable")
+	kept, err := tokens.Token(context.Background())
+	require.NoError(t, err)
+	assert.Same(t, first, kept)
+
+	// Once dropped there is nothing to fall back to
+	assert.True(t, tokens.Invalidate(first.AccessToken))
+	_, err = tokens.Token(context.Background())
+	assert.Error(t, err)
+}
+
+func TestRefreshingTokenSource_InvalidateOnlyRejected(t *testing.T) {
+	tokens := NewRefreshingTokenSource(&countingSource{}, "test", 0)
+	token, err := tokens.Token(context.Background())
+	require.NoError(t, err)
+
+	assert.False(t, tokens.Invalidate("other"))
+	assert.Same(t, token, tokens.Current())
+	assert.True(t, tokens.Invalidate(token.AccessToken))
+	assert.Nil(t, tokens.Current())
+}
+
+func TestNewTokenSource_FileAndEnv(t *testing.T) {
+	raw := testJWT(t, time.Now().Add(time.Hour))
+	path := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(path, []byte("SLURM_JWT="+raw+"\n"), 0o600))
+
+	tokens, err := NewTokenSource(&config.TokenSourceConfig{File: path}, "prod")
+	require.NoError(t, err)
+	assert.Equal(t, "file", tokens.Kind())
+	token, err := tokens.Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, raw, token.AccessToken)
+	assert.False(t, token.ExpiresAt.IsZero())
+
+	t.Setenv("TEST_S9S_TOKEN", "opaque")
+	tokens, err = NewTokenSource(&config.TokenSourceConfig{Env: "TEST_S9S_TOKEN"}, "prod")
+	require.NoError(t, err)
+	token, err = tokens.Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "opaque", token.AccessToken)
+	assert.True(t, token.ExpiresAt.IsZero())
+}
+
+func TestCommandTokenSource_QuotedArguments(t *testing.T) {
+	tokens, err := NewTokenSource(&config.TokenSourceConfig{Command: `printf '%s\n' "SLURM_JWT=quoted token" | tr -d ' '`}, "prod")
+	require.NoError(t, err)
+
+	token, err := tokens.Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "quotedtoken", token.AccessToken)
+	assert.Equal(t, "prod", token.ClusterID)
+}
+
+func TestNewTokenSource_Invalid(t *testing.T) {
+	_, err := NewTokenSource(&config.TokenSourceConfig{}, "prod")
+	assert.Error(t, err)
+
+	_, err = NewTokenSource(&config.TokenSourceConfig{File: "a", Env: "B"}, "prod")
+	assert.Error(t, err)
+
+	_, err = NewTokenSource(&config.TokenSourceConfig{Env: "B", RefreshBefore: "soon"}, "prod")
+	assert.Error(t, err)
+}
+
+func TestOAuth2TokenSource_UsesConfiguredRefreshBefore(t *testing.T) {
+	refreshes := 0
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		refreshes++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token": "renewed", "expires_in": 7200}`))
+	}))
+	defer provider.Close()
+
+	source := NewOAuth2TokenSource(&config.OAuth2TokenConfig{
+		ClientID:              "s9s",
+		ClientSecret:          "secret",
+		AuthorizationEndpoint: provider.URL + "/authorize",
+		TokenEndpoint:         provider.URL + "/token",
+	}, "test", 30*time.Minute)
+	source.tokens = NewTokenManager(t.TempDir(), false)
+	// Fresh by the default of five minutes, but due by the configured thirty
+	source.last = &Token{AccessToken: "current", RefreshToken: "refresh-1", ExpiresAt: time.Now().Add(20 * time.Minute)}
+
+	token, err := source.Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "renewed", token.AccessToken)
+	assert.Equal(t, 1, refreshes)
+
+	// Still fresh now, until slurmrestd rejects it
+	_, err = source.Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, refreshes)
+
+	source.Invalidate("renewed")
+	_, err = source.Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, refreshes)
+}
+
+func TestOAuth2TokenSource_CachesLoginPerClient(t *testing.T) {
+	newSource := func(clientID string) *OAuth2TokenSource {
+		return NewOAuth2TokenSource(&config.OAuth2TokenConfig{
+			ClientID:              clientID,
+			ClientSecret:          "secret",
+			AuthorizationEndpoint: "https://idp.example.com/authorize",
+			TokenEndpoint:         "https://idp.example.com/token",
+		}, "prod", DefaultRefreshBefore)
+	}
+	tokens := NewTokenManager(t.TempDir(), false)
+	require.NoError(t, tokens.SetToken("prod/alice", &Token{AccessToken: "alice-token", ExpiresAt: time.Now().Add(time.Hour)}))
+
+	alice := newSource("alice")
+	alice.tokens = tokens
+	token, err := alice.Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "alice-token", token.AccessToken)
+
+	// Another client of the same endpoint and cluster has a login of its own
+	_, err = tokens.GetToken(newSource("bob").cacheKey)
+	assert.Error(t, err)
+}
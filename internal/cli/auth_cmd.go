@@ -0,0 +1,160 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jontk/s9s/internal/auth"
+	"github.com/jontk/s9s/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// authCmd represents the auth command group
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Cluster credential commands",
+	Long: `Manage the tokens s9s uses to talk to slurmrestd.
+
+A cluster can take its token from a command, a file, the system keyring,
+an environment variable or an OAuth2 login instead of a plaintext token:
+
+  clusters:
+    - name: prod
+      cluster:
+        endpoint: https://slurm.example.com:6820
+        tokenFrom:
+          command: scontrol token lifespan=3600
+
+Tokens are refreshed before the expiry in their JWT claims, and again when
+slurmrestd rejects one.`,
+}
+
+// authStoreCmd represents the auth store command
+var authStoreCmd = &cobra.Command{
+	Use:   "store KEY",
+	Short: "Store a token in the system keyring",
+	Long: `Read a token from standard input and store it in the system keyring
+under KEY, for use with "tokenFrom: {keyring: KEY}".
+
+The input may be a bare token or the SLURM_JWT=... line printed by
+scontrol token.`,
+	Example: `  scontrol token lifespan=86400 | s9s auth store prod
+  s9s auth store prod < token.txt`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE:         runAuthStore,
+}
+
+// authStatusCmd represents the auth status command
+var authStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the token source and expiry of each cluster",
+	Long: `Fetch the token of every configured cluster and show where it came
+from and when it expires. OAuth2 logins are shown without logging in.`,
+	SilenceUsage: true,
+	RunE:         runAuthStatus,
+}
+
+func init() {
+	authCmd.AddCommand(authStoreCmd)
+	authCmd.AddCommand(authStatusCmd)
+	rootCmd.AddCommand(authCmd)
+}
+
+func runAuthStore(_ *cobra.Command, args []string) error {
+	data, err := readAllStdin()
+	if err != nil {
+		return fmt.Errorf("failed to read token: %w", err)
+	}
+	token := strings.TrimSpace(data)
+	if _, value, ok := strings.Cut(token, "SLURM_JWT="); ok {
+		token = strings.TrimSpace(value)
+	}
+	if token == "" {
+		return fmt.Errorf("no token on standard input")
+	}
+
+	if err := auth.StoreKeyringToken(args[0], token); err != nil {
+		return fmt.Errorf("failed to store token in keyring: %w", err)
+	}
+	fmt.Printf("Stored token %q in the keyring", args[0])
+	if expiresAt, ok := auth.JWTExpiry(token); ok {
+		fmt.Printf(" (expires %s)", expiresAt.Local().Format(time.RFC1123))
+	}
+	fmt.Println()
+	return nil
+}
+
+// readAllStdin reads standard input to EOF
+func readAllStdin() (string, error) {
+	var b strings.Builder
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		b.WriteString(scanner.Text())
+		b.WriteByte('\n')
+	}
+	return b.String(), scanner.Err()
+}
+
+func runAuthStatus(_ *cobra.Command, _ []string) error {
+	cfg, err := config.LoadWithPath(cfgFile)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if len(cfg.Clusters) == 0 {
+		fmt.Println("No clusters configured")
+		return nil
+	}
+
+	ctx := context.Background()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "CLUSTER\tSOURCE\tEXPIRES\tSTATUS")
+	for _, entry := range cfg.Clusters {
+		source, expires, status := clusterTokenStatus(ctx, &entry.Cluster)
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", entry.Name, source, expires, status)
+	}
+	return w.Flush()
+}
+
+// clusterTokenStatus returns the token source, expiry and status of a
+// cluster for auth status
+func clusterTokenStatus(ctx context.Context, cluster *config.ClusterConfig) (source, expires, status string) {
+	var token *auth.Token
+	switch {
+	case cluster.TokenFrom.IsSet():
+		kinds := cluster.TokenFrom.Kinds()
+		source = strings.Join(kinds, "+")
+		if len(kinds) == 1 && kinds[0] == "oauth2" {
+			return source, "-", "login on first use"
+		}
+		tokens, err := auth.NewTokenSource(&cluster.TokenFrom, cluster.Endpoint)
+		if err != nil {
+			return source, "-", err.Error()
+		}
+		if token, err = tokens.Token(ctx); err != nil {
+			return source, "-", err.Error()
+		}
+	case cluster.Token != "":
+		source = "token"
+		token = auth.NewBearerToken(cluster.Token, cluster.Endpoint)
+	default:
+		return "none", "-", "no token configured"
+	}
+
+	if token.ExpiresAt.IsZero() {
+		return source, "unknown", "ok"
+	}
+	expires = token.ExpiresAt.Local().Format("2006-01-02 15:04")
+	if remaining := time.Until(token.ExpiresAt); remaining <= 0 {
+		return source, expires, "expired"
+	} else if remaining < auth.DefaultRefreshBefore {
+		return source, expires, fmt.Sprintf("expires in %s", remaining.Round(time.Second))
+	}
+	return source, expires, "ok"
+}
@@ -7,6 +7,8 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
+	"text/tabwriter"
 
 	"github.com/jontk/s9s/internal/config"
 	"github.com/jontk/s9s/internal/fileperms"
@@ -20,13 +22,21 @@ var configCmd = &cobra.Command{
 	Short: "Configuration management commands",
 	Long: `Manage s9s configuration files and settings.
 
-Configuration files are searched in the following order:
-1. ~/.s9s/config.yaml
-2. ~/.config/s9s/config.yaml
-3. Environment variables
-4. Command-line flags`,
+The configuration is merged from these layers, later ones taking precedence:
+1. System: /etc/s9s/config.yaml
+2. Site: the file named by $S9S_SITE_CONFIG
+3. User: ~/.s9s/config.yaml, or the file given with --config
+4. Project: .s9s.yaml in the working directory or its nearest parent
+5. Environment variables
+6. Command-line flags`,
 }
 
+// configSchemaOutput is the file config schema writes to
+var configSchemaOutput string
+
+// configShowOrigin makes config show print where each setting comes from
+var configShowOrigin bool
+
 // configEditCmd represents the config edit command
 var configEditCmd = &cobra.Command{
 	Use:   "edit",
@@ -41,14 +51,15 @@ If no configuration file exists, a new one will be created with default settings
 var configValidateCmd = &cobra.Command{
 	Use:   "validate",
 	Short: "Validate the configuration file",
-	Long: `Validate the s9s configuration file for syntax and logical errors.
+	Long: `Validate the s9s configuration files for syntax and logical errors.
 
 This command checks:
-• YAML syntax validity
-• Required fields presence
-• Cluster connectivity (optional)
-• Authentication configuration`,
-	RunE: runConfigValidate,
+• YAML syntax validity of each config file
+• Unknown settings, and settings a project file may not set
+• Setting values against the configuration schema, file by file
+• Required fields presence in the merged configuration`,
+	SilenceUsage: true,
+	RunE:         runConfigValidate,
 }
 
 // configShowCmd represents the config show command
@@ -57,11 +68,79 @@ var configShowCmd = &cobra.Command{
 	Short: "Display current configuration",
 	Long: `Display the current s9s configuration with resolved values.
 
-This shows the effective configuration after merging:
-• Configuration files
-• Environment variables  
-• Command-line flags`,
-	RunE: runConfigShow,
+This lists the config files that were merged and every setting of the
+effective configuration. Tokens, passwords and client secrets are masked.
+With --origin, each setting shows where it comes from: the system, site,
+user or project file, the environment, or the defaults.`,
+	Example: `  s9s config show
+  s9s config show --origin`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runConfigShow,
+}
+
+// configGetCmd represents the config get command
+var configGetCmd = &cobra.Command{
+	Use:   "get <path>",
+	Short: "Print a configuration value",
+	Long: `Print the effective value of a configuration setting.
+
+The path is a dotted key such as refreshRate, views.jobs.maxJobs or
+clusters.0.cluster.endpoint. Sections are printed as YAML.`,
+	Example: `  s9s config get refreshRate
+  s9s config get views.jobs.columns
+  s9s config get clusters[0].cluster.endpoint`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE:         runConfigGet,
+}
+
+// configSetCmd represents the config set command
+var configSetCmd = &cobra.Command{
+	Use:   "set <path> <value>",
+	Short: "Change a configuration value",
+	Long: `Change a setting in the configuration file, keeping its comments.
+
+The value is checked against the configuration schema, and the file is only
+written if the resulting configuration passes validation. List settings take
+comma-separated values.`,
+	Example: `  s9s config set refreshRate 30s
+  s9s config set views.jobs.columns id,name,state
+  s9s config set clusters.0.readOnly true`,
+	Args:         cobra.ExactArgs(2),
+	SilenceUsage: true,
+	RunE:         runConfigSet,
+}
+
+// configDiffCmd represents the config diff command
+var configDiffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Show settings that differ from the defaults",
+	Long: `List every setting of the effective configuration that differs from the
+built-in defaults. Tokens, passwords and client secrets are masked.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runConfigDiff,
+}
+
+// configSchemaCmd represents the config schema command
+var configSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema of the configuration file",
+	Long: `Print the JSON Schema (draft 2020-12) of the configuration file, with the
+descriptions, allowed values and defaults of every setting.
+
+Editors using yaml-language-server, such as VS Code with the YAML extension
+or Neovim, validate and complete the config file against it. Config files
+written by s9s start with a comment pointing at the published schema; point
+it at a local copy to match the installed version of s9s:
+
+  # yaml-language-server: $schema=./config.schema.json`,
+	Example: `  s9s config schema
+  s9s config schema --output ~/.s9s/config.schema.json`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runConfigSchema,
 }
 
 func init() {
@@ -69,6 +148,13 @@ func init() {
 	configCmd.AddCommand(configEditCmd)
 	configCmd.AddCommand(configValidateCmd)
 	configCmd.AddCommand(configShowCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configDiffCmd)
+	configCmd.AddCommand(configSchemaCmd)
+
+	configShowCmd.Flags().BoolVar(&configShowOrigin, "origin", false, "show the config file or source of each setting")
+	configSchemaCmd.Flags().StringVarP(&configSchemaOutput, "output", "o", "", "write the schema to this file instead of stdout")
 
 	// Add config command to root
 	rootCmd.AddCommand(configCmd)
@@ -114,11 +200,34 @@ func runConfigEdit(_ *cobra.Command, _ []string) error {
 }
 
 func runConfigValidate(_ *cobra.Command, _ []string) error {
-	cfg, err := config.Load()
+	cfg, err := config.LoadWithPath(cfgFile)
 	if err != nil {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
 
+	// Each config file on its own
+	layerErrs := cfg.ValidateLayers()
+	for _, layer := range cfg.Layers {
+		var problems []config.LayerError
+		for _, lerr := range layerErrs {
+			if lerr.Layer.Path == layer.Path {
+				problems = append(problems, lerr)
+			}
+		}
+		if len(problems) == 0 {
+			fmt.Printf("✅ %s: %s\n", layer.Name, layer.Path)
+			continue
+		}
+		fmt.Printf("❌ %s: %s\n", layer.Name, layer.Path)
+		for _, problem := range problems {
+			if problem.Field == "" {
+				fmt.Printf("   %s\n", problem.Message)
+				continue
+			}
+			fmt.Printf("   [%s] %s\n", problem.Field, problem.Message)
+		}
+	}
+
 	// Basic validation - check if default cluster exists
 	if cfg.DefaultCluster != "" {
 		_, err := cfg.GetCluster(cfg.DefaultCluster)
@@ -136,28 +245,190 @@ func runConfigValidate(_ *cobra.Command, _ []string) error {
 		}
 	}
 
+	if len(layerErrs) > 0 {
+		return fmt.Errorf("configuration validation failed: %d problems in config files", len(layerErrs))
+	}
 	fmt.Println("✅ Configuration is valid")
 	return nil
 }
 
 func runConfigShow(_ *cobra.Command, _ []string) error {
-	cfg, err := config.Load()
+	cfg, err := config.LoadWithPath(cfgFile)
 	if err != nil {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
+	settings, err := cfg.Settings()
+	if err != nil {
+		return err
+	}
 
-	// Print configuration (with sensitive data masked)
-	fmt.Println("Current s9s configuration:")
-	fmt.Printf("Config path: %s\n\n", getConfigPath())
+	fmt.Println("Config files:")
+	if len(cfg.Layers) == 0 {
+		fmt.Println("  none, using the defaults")
+	}
+	for _, layer := range cfg.Layers {
+		fmt.Printf("  %-8s %s\n", layer.Name, layer.Path)
+		if len(layer.Ignored) > 0 {
+			fmt.Printf("           ignored: %s\n", strings.Join(layer.Ignored, ", "))
+		}
+	}
+	fmt.Println()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, setting := range settings {
+		value := diffValue(setting.Path, setting.Value)
+		if configShowOrigin {
+			_, _ = fmt.Fprintf(w, "%s:\t%s\t# %s\n", setting.Path, value, setting.Origin)
+			continue
+		}
+		_, _ = fmt.Fprintf(w, "%s: %s\n", setting.Path, value)
+	}
+	return w.Flush()
+}
 
-	// TODO: Implement config display with sensitive data masking
-	fmt.Printf("Clusters configured: %d\n", len(cfg.Clusters))
-	fmt.Printf("Default cluster: %s\n", cfg.DefaultCluster)
-	fmt.Printf("Mock mode: %v\n", cfg.UseMockClient)
+func runConfigGet(_ *cobra.Command, args []string) error {
+	cfg, err := config.LoadWithPath(cfgFile)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
 
+	value, err := cfg.Lookup(args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Println(config.FormatValue(value))
 	return nil
 }
 
+func runConfigSet(_ *cobra.Command, args []string) error {
+	cfg, err := config.LoadWithPath(cfgFile)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	configPath := cfg.ConfigPath
+
+	// A missing file is created with just this setting
+	mode := fileperms.ConfigFile
+	data, err := os.ReadFile(configPath)
+	if err == nil {
+		if info, statErr := os.Stat(configPath); statErr == nil {
+			mode = info.Mode().Perm()
+		}
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read configuration: %w", err)
+	}
+
+	updated, err := config.SetValue(data, args[0], args[1])
+	if err != nil {
+		return err
+	}
+
+	// Load the result the way s9s will, and refuse to write it if it adds
+	// validation errors
+	configDir := filepath.Dir(configPath)
+	if err := os.MkdirAll(configDir, fileperms.ConfigDir); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(configDir, ".config-*.yaml")
+	if err != nil {
+		return fmt.Errorf("failed to write configuration: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer func() { _ = os.Remove(tmpPath) }()
+	if _, err := tmp.Write(updated); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write configuration: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write configuration: %w", err)
+	}
+
+	newCfg, err := config.LoadWithPath(tmpPath)
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	base := cfg
+	if data == nil {
+		base = config.DefaultConfig()
+	}
+	introduced := config.ValidateAndFix(newCfg, false).NewErrors(config.ValidateAndFix(base, false))
+	if len(introduced) > 0 {
+		for _, verr := range introduced {
+			fmt.Printf("❌ [%s] %s\n", verr.Field, verr.Message)
+		}
+		return fmt.Errorf("configuration validation failed, %s not changed", configPath)
+	}
+
+	if err := os.Chmod(tmpPath, mode); err != nil {
+		return fmt.Errorf("failed to write configuration: %w", err)
+	}
+	if err := os.Rename(tmpPath, configPath); err != nil {
+		return fmt.Errorf("failed to write configuration: %w", err)
+	}
+
+	fmt.Printf("✅ Set %s in %s\n", args[0], configPath)
+	return nil
+}
+
+func runConfigDiff(_ *cobra.Command, _ []string) error {
+	cfg, err := config.LoadWithPath(cfgFile)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	changes, err := config.Diff(config.DefaultConfig(), cfg)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		fmt.Println("Configuration matches the defaults")
+		return nil
+	}
+	for _, change := range changes {
+		fmt.Printf("%s: %s -> %s\n", change.Path,
+			diffValue(change.Path, change.From), diffValue(change.Path, change.To))
+	}
+	return nil
+}
+
+func runConfigSchema(_ *cobra.Command, _ []string) error {
+	data, err := config.GetConfigSchema().MarshalJSONSchema()
+	if err != nil {
+		return err
+	}
+	if configSchemaOutput == "" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(configSchemaOutput), fileperms.ConfigDir); err != nil {
+		return fmt.Errorf("failed to create schema directory: %w", err)
+	}
+	if err := os.WriteFile(configSchemaOutput, data, fileperms.ConfigFile); err != nil {
+		return fmt.Errorf("failed to write schema: %w", err)
+	}
+	fmt.Printf("✅ Wrote the configuration schema to %s\n", configSchemaOutput)
+	return nil
+}
+
+// diffValue formats one side of a config diff on a single line
+func diffValue(path string, value any) string {
+	switch {
+	case value == nil:
+		return "(unset)"
+	case config.IsSensitive(path):
+		return "********"
+	}
+	if list, ok := value.([]any); ok {
+		items := make([]string, len(list))
+		for i, item := range list {
+			items[i] = config.FormatValue(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	}
+	return strings.ReplaceAll(config.FormatValue(value), "\n", " ")
+}
+
 func getConfigPath() string {
 	if cfgFile != "" {
 		return cfgFile
@@ -168,7 +439,7 @@ func getConfigPath() string {
 }
 
 func createDefaultConfig(path string) error {
-	defaultConfig := `# s9s Configuration File
+	defaultConfig := config.SchemaHeader + `# s9s Configuration File
 # Documentation: https://github.com/jontk/s9s/tree/main/docs
 
 refreshRate: "5s"
@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/jontk/s9s/internal/app"
+	"github.com/jontk/s9s/internal/exporter"
+	"github.com/jontk/s9s/internal/logging"
+	"github.com/jontk/s9s/internal/monitoring"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exporterListen      string
+	exporterPath        string
+	exporterInterval    time.Duration
+	exporterJobPageSize int
+)
+
+// exporterCmd runs s9s as a headless Prometheus exporter
+var exporterCmd = &cobra.Command{
+	Use:   "exporter",
+	Short: "Serve cluster metrics for Prometheus",
+	Long: `Run s9s headless and serve SLURM cluster metrics in the OpenMetrics format.
+
+The exporter polls the cluster selected by --cluster using the same
+configuration and authentication as the TUI. Scrapes are answered from the
+latest poll, so the scrape rate does not add load on slurmrestd.
+
+Exported metrics include job counts by partition, user and account, queue
+wait time quantiles, node states, CPU/memory/GPU allocation and the status
+of the built-in health checks and configured health rules.`,
+	Example: `  s9s exporter                             # Listen on :9341
+  s9s exporter --listen :9100 --interval 1m
+  s9s exporter --cluster production`,
+	RunE: runExporter,
+}
+
+func init() {
+	exporterCmd.Flags().StringVar(&exporterListen, "listen", exporter.DefaultListenAddress, "address to serve metrics on")
+	exporterCmd.Flags().StringVar(&exporterPath, "path", "/metrics", "HTTP path of the metrics endpoint")
+	exporterCmd.Flags().DurationVar(&exporterInterval, "interval", exporter.DefaultInterval, "time between polls of the cluster")
+	exporterCmd.Flags().IntVar(&exporterJobPageSize, "job-page-size", exporter.DefaultJobPageSize, "number of jobs fetched per request while paging through the job list")
+
+	rootCmd.AddCommand(exporterCmd)
+}
+
+func runExporter(cmd *cobra.Command, _ []string) error {
+	logConfig := logging.DefaultConfig()
+	logConfig.Console = true // Headless: log to the terminal or service journal
+	if debugMode {
+		logConfig.Level = logging.DebugLevel
+	}
+	logging.Init(logConfig)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cfg, err := initializeConfiguration(ctx, cmd)
+	if err != nil {
+		return err
+	}
+
+	client, err := app.NewSlurmClient(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create SLURM client: %w", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	rules, err := monitoring.NewRuleEngineFromConfig(cfg.Health.Rules)
+	if err != nil {
+		logging.Warnf("Skipping invalid health rules: %v", err)
+	}
+
+	exp := exporter.New(client, exporter.Options{
+		Interval:    exporterInterval,
+		JobPageSize: exporterJobPageSize,
+		Rules:       rules,
+	})
+
+	logging.Infof("Serving metrics on %s%s (poll interval %s)", exporterListen, exporterPath, exporterInterval)
+	if err := exp.ListenAndServe(ctx, exporterListen, exporterPath); err != nil {
+		return fmt.Errorf("exporter failed: %w", err)
+	}
+	return nil
+}
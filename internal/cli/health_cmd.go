@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jontk/s9s/internal/app"
+	"github.com/jontk/s9s/internal/logging"
+	"github.com/jontk/s9s/internal/monitoring"
+	"github.com/spf13/cobra"
+)
+
+var healthListMetrics bool
+
+// healthCmd evaluates the health checks and rules once
+var healthCmd = &cobra.Command{
+	Use:   "health",
+	Short: "Evaluate cluster health checks and rules",
+	Long: `Evaluate the built-in health checks and the health rules from the
+configuration once and print the result of each.
+
+The command exits with an error when any check is critical, so it can be
+used from cron or a CI job. Rules with a "for" duration report as pending
+on a single evaluation. Set S9S_ENABLE_MOCK and useMockClient to try
+rules against the mock cluster.`,
+	Example: `  s9s health                       # Check the default cluster
+  s9s health --cluster production
+  s9s health --metrics             # List the metrics usable in rules`,
+	SilenceUsage: true,
+	RunE:         runHealth,
+}
+
+func init() {
+	healthCmd.Flags().BoolVar(&healthListMetrics, "metrics", false, "list the metrics available to rule expressions")
+
+	rootCmd.AddCommand(healthCmd)
+}
+
+func runHealth(cmd *cobra.Command, _ []string) error {
+	if healthListMetrics {
+		printRuleMetrics()
+		return nil
+	}
+
+	logConfig := logging.DefaultConfig()
+	if debugMode {
+		logConfig.Console = true
+		logConfig.Level = logging.DebugLevel
+	}
+	logging.Init(logConfig)
+
+	ctx := context.Background()
+	cfg, err := initializeConfiguration(ctx, cmd)
+	if err != nil {
+		return err
+	}
+
+	rules, err := monitoring.NewRuleEngineFromConfig(cfg.Health.Rules)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: skipping invalid health rules: %v\n", err)
+	}
+
+	client, err := app.NewSlurmClient(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create SLURM client: %w", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	monitor := monitoring.NewHealthMonitor(client, 0)
+	monitor.SetRuleEngine(rules)
+	health := monitor.RunChecks()
+
+	printHealth(health)
+	if health.OverallStatus == monitoring.HealthStatusCritical {
+		return errors.New("cluster health is critical")
+	}
+	return nil
+}
+
+// printHealth prints one line per health check, sorted by name
+func printHealth(health *monitoring.ClusterHealth) {
+	names := make([]string, 0, len(health.Checks))
+	for name := range health.Checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "STATUS\tCHECK\tMESSAGE")
+	for _, name := range names {
+		check := health.Checks[name]
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", strings.ToUpper(string(check.Status)), name, check.Message)
+	}
+	_ = w.Flush()
+	fmt.Printf("\nOverall: %s\n", strings.ToUpper(string(health.OverallStatus)))
+}
+
+// printRuleMetrics prints the metrics rule expressions can reference
+func printRuleMetrics() {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "METRIC\tDESCRIPTION")
+	for _, name := range monitoring.RuleMetricNames() {
+		_, _ = fmt.Fprintf(w, "%s\t%s\n", name, monitoring.RuleMetricDescription(name))
+	}
+	_ = w.Flush()
+}
@@ -0,0 +1,277 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jontk/s9s/internal/app"
+	"github.com/jontk/s9s/internal/config"
+	"github.com/jontk/s9s/internal/pipeline"
+	"github.com/jontk/s9s/internal/views"
+	"github.com/spf13/cobra"
+)
+
+var pipelineDryRun bool
+
+// pipelineCmd represents the pipeline command group
+var pipelineCmd = &cobra.Command{
+	Use:   "pipeline",
+	Short: "Submit and follow multi-stage job pipelines",
+	Long: `Submit pipelines of jobs that depend on each other.
+
+A pipeline is a YAML file of stages. Every stage submits a job template
+with the given parameter values and may run after other stages; s9s
+submits the stages in dependency order and fills in the job IDs of the
+stages they wait for. Submitted pipelines are kept in ~/.s9s/pipelines.json
+and can be followed with "s9s pipeline status" or :pipeline in the TUI.
+
+  name: train-model
+  stages:
+    - name: prep
+      template: preprocess
+      array: "0-9%4"          # fan out as a job array
+    - name: train
+      template: gpu-training
+      after: [prep]
+      set: {epochs: "20"}
+    - name: eval
+      template: evaluate
+      after: [train]
+      dependency: afterany    # after, afterany, afterok (default), afternotok, aftercorr`,
+}
+
+// pipelineSubmitCmd submits a pipeline
+var pipelineSubmitCmd = &cobra.Command{
+	Use:   "submit <file>",
+	Short: "Submit the stages of a pipeline in dependency order",
+	Long: `Submit every stage of a pipeline file, each after the stages it waits
+for. Stages run in the directory of the pipeline file unless their
+template sets a working directory. The templates of every stage are
+checked before the first job is submitted.`,
+	Example: `  s9s pipeline submit train.yaml
+  s9s pipeline submit train.yaml --dry-run`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE:         runPipelineSubmit,
+}
+
+// pipelineStatusCmd shows the stages of a submitted pipeline
+var pipelineStatusCmd = &cobra.Command{
+	Use:          "status <run>",
+	Short:        "Show the job and state of every stage of a pipeline run",
+	Example:      `  s9s pipeline status train-model-20261018-091500`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE:         runPipelineStatus,
+}
+
+// pipelineResubmitCmd resubmits a stage of a submitted pipeline
+var pipelineResubmitCmd = &cobra.Command{
+	Use:   "resubmit <run> <stage>",
+	Short: "Resubmit a stage and the stages after it",
+	Long: `Submit a stage of a pipeline run again, together with every stage that
+waits for it directly or not. Pending jobs of those stages are cancelled
+first, as they would wait for the job being replaced.`,
+	Example:      `  s9s pipeline resubmit train-model-20261018-091500 train`,
+	Args:         cobra.ExactArgs(2),
+	SilenceUsage: true,
+	RunE:         runPipelineResubmit,
+}
+
+// pipelineListCmd lists the submitted pipelines
+var pipelineListCmd = &cobra.Command{
+	Use:          "list",
+	Short:        "List submitted pipeline runs",
+	SilenceUsage: true,
+	RunE:         runPipelineList,
+}
+
+func init() {
+	pipelineSubmitCmd.Flags().BoolVar(&pipelineDryRun, "dry-run", false, "print the submission order and job scripts instead of submitting")
+
+	pipelineCmd.AddCommand(pipelineSubmitCmd)
+	pipelineCmd.AddCommand(pipelineStatusCmd)
+	pipelineCmd.AddCommand(pipelineResubmitCmd)
+	pipelineCmd.AddCommand(pipelineListCmd)
+	rootCmd.AddCommand(pipelineCmd)
+}
+
+func runPipelineSubmit(cmd *cobra.Command, args []string) error {
+	spec, err := pipeline.Load(args[0])
+	if err != nil {
+		return err
+	}
+	path, err := filepath.Abs(args[0])
+	if err != nil {
+		return err
+	}
+
+	if pipelineDryRun {
+		cfg, err := config.LoadWithPath(cfgFile)
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		resolve := views.PipelineResolver(&cfg.Views.Jobs.Submission, spec, filepath.Dir(path))
+		order, _ := spec.Order()
+		for _, name := range order {
+			stage, _ := spec.Stage(name)
+			job, err := resolve(stage)
+			if err != nil {
+				return fmt.Errorf("stage %s: %w", name, err)
+			}
+			job.ArraySpec = stage.Array
+			fmt.Printf("# Stage %s", name)
+			if len(stage.After) > 0 {
+				fmt.Printf(" (%s %s)", stage.DependencyType(), strings.Join(stage.After, ", "))
+			}
+			fmt.Printf("\n%s\n", views.JobScript(job))
+		}
+		return nil
+	}
+
+	ctx := context.Background()
+	cfg, err := initializeConfiguration(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	store, err := loadPipelineRuns()
+	if err != nil {
+		return err
+	}
+	client, err := app.NewSlurmClient(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create SLURM client: %w", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	run := pipeline.NewRun(spec, time.Now())
+	run.SpecFile = path
+	run.WorkingDir = filepath.Dir(path)
+	resolve := views.PipelineResolver(&cfg.Views.Jobs.Submission, spec, run.WorkingDir)
+	submitErr := run.Submit(client.Jobs(), resolve, func(stage, jobID string) {
+		fmt.Printf("  %-20s job %s\n", stage, jobID)
+	})
+	if len(run.Jobs) == 0 {
+		return submitErr
+	}
+	if err := store.Add(run); err != nil {
+		return fmt.Errorf("pipeline %s was submitted but could not be saved: %w", run.ID, err)
+	}
+	if submitErr != nil {
+		return fmt.Errorf("submitted %d of %d stages of pipeline %s, then failed: %w", len(run.Jobs), len(spec.Stages), run.ID, submitErr)
+	}
+	fmt.Printf("✅ Submitted pipeline %s\n", run.ID)
+	return nil
+}
+
+func runPipelineStatus(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	cfg, err := initializeConfiguration(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	run, _, err := pipelineRun(args[0])
+	if err != nil {
+		return err
+	}
+	client, err := app.NewSlurmClient(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create SLURM client: %w", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	states := run.States(client.Jobs())
+	order, _ := run.Spec.Order()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "STAGE\tJOB\tSTATE\tAFTER")
+	for _, name := range order {
+		stage, _ := run.Spec.Stage(name)
+		jobID, state := run.Jobs[name], states[name]
+		if jobID == "" {
+			state = "NOT SUBMITTED"
+		}
+		after := strings.Join(stage.After, ",")
+		if after != "" {
+			after = stage.DependencyType() + ":" + after
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", name, dashIfEmpty(jobID), state, dashIfEmpty(after))
+	}
+	return w.Flush()
+}
+
+func runPipelineResubmit(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	cfg, err := initializeConfiguration(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	run, store, err := pipelineRun(args[0])
+	if err != nil {
+		return err
+	}
+	client, err := app.NewSlurmClient(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create SLURM client: %w", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	resolve := views.PipelineResolver(&cfg.Views.Jobs.Submission, &run.Spec, run.WorkingDir)
+	stages, resubmitErr := run.Resubmit(client.Jobs(), resolve, args[1])
+	if err := store.Save(run); err != nil {
+		return fmt.Errorf("failed to save pipeline %s: %w", run.ID, err)
+	}
+	if resubmitErr != nil {
+		return resubmitErr
+	}
+	for _, stage := range stages {
+		fmt.Printf("  %-20s job %s\n", stage, run.Jobs[stage])
+	}
+	fmt.Printf("✅ Resubmitted %d stage(s) of pipeline %s\n", len(stages), run.ID)
+	return nil
+}
+
+func runPipelineList(_ *cobra.Command, _ []string) error {
+	store, err := loadPipelineRuns()
+	if err != nil {
+		return err
+	}
+	runs := store.List()
+	if len(runs) == 0 {
+		fmt.Println("No pipeline runs. Submit one with: s9s pipeline submit <file>")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "RUN\tSTAGES\tSUBMITTED\tFILE")
+	for _, run := range runs {
+		_, _ = fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", run.ID, len(run.Spec.Stages), run.Created.Format("2006-01-02 15:04"), dashIfEmpty(run.SpecFile))
+	}
+	return w.Flush()
+}
+
+// loadPipelineRuns loads the store of submitted pipelines
+func loadPipelineRuns() (*pipeline.RunStore, error) {
+	store := pipeline.NewRunStore(pipeline.DefaultRunsPath())
+	if err := store.Load(); err != nil {
+		return nil, fmt.Errorf("failed to load pipeline runs: %w", err)
+	}
+	return store, nil
+}
+
+// pipelineRun returns the pipeline run with the given ID and its store
+func pipelineRun(id string) (*pipeline.Run, *pipeline.RunStore, error) {
+	store, err := loadPipelineRuns()
+	if err != nil {
+		return nil, nil, err
+	}
+	run, ok := store.Get(id)
+	if !ok {
+		return nil, nil, fmt.Errorf("pipeline run %s not found (see s9s pipeline list)", id)
+	}
+	return run, store, nil
+}
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -8,6 +9,7 @@ import (
 	"strings"
 	"text/tabwriter"
 
+	"github.com/jontk/s9s/internal/app"
 	"github.com/jontk/s9s/internal/config"
 	"github.com/jontk/s9s/internal/dao"
 	"github.com/jontk/s9s/internal/fileperms"
@@ -18,6 +20,10 @@ import (
 var (
 	exportForce bool
 	exportDir   string
+
+	templateValues []string
+	renderOutput   string
+	submitDryRun   bool
 )
 
 // templatesCmd represents the templates command group
@@ -26,10 +32,18 @@ var templatesCmd = &cobra.Command{
 	Short: "Template management commands",
 	Long: `Manage job submission templates.
 
-Templates are merged from three sources (highest to lowest priority):
+Templates are merged from the sources listed in
+views.jobs.submission.templateSources, later sources replacing templates
+of the same name. By default (highest to lowest priority):
 1. User-saved templates from ~/.s9s/templates/*.json
 2. Config YAML templates
-3. Built-in hardcoded templates`,
+3. Built-in hardcoded templates
+
+A source can also be a directory of template files, such as a git
+checkout of a catalog shared by a group.
+
+Templates with parameters prompt for them in the submission wizard; on
+the command line their values are given with --set name=value.`,
 }
 
 // templatesExportCmd represents the templates export command
@@ -52,17 +66,58 @@ Examples:
 var templatesListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List all available templates",
-	Long: `List all available templates merged from all three sources:
-built-in, config YAML, and user-saved templates.`,
-	RunE: runTemplatesList,
+	Long: `List the templates of the template sources, with their version, the
+source they come from and their parameters.`,
+	SilenceUsage: true,
+	RunE:         runTemplatesList,
+}
+
+// templatesRenderCmd prints the job script of a template
+var templatesRenderCmd = &cobra.Command{
+	Use:   "render <template-name>",
+	Short: "Print the job script of a template with its parameters filled in",
+	Long: `Render a template with the given parameter values and print the batch
+script the submission form would submit, with its options as #SBATCH
+directives. Parameters without a value take their default.
+
+Use --output json to print the job submission fields instead.`,
+	Example: `  s9s templates render "GPU Training" --set model=bert --set gpus=4
+  s9s templates render sweep --set walltime=90m -o json`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE:         runTemplatesRender,
+}
+
+// templatesSubmitCmd submits a job from a template
+var templatesSubmitCmd = &cobra.Command{
+	Use:   "submit <template-name>",
+	Short: "Submit a job from a template",
+	Long: `Render a template with the given parameter values and submit it. The
+job gets the form defaults of the configuration under the template values,
+and the current directory as its working directory unless the template
+sets one. It is checked like a job submitted from the form.`,
+	Example: `  s9s templates submit "GPU Training" --set model=bert --set gpus=4
+  s9s templates submit "GPU Training" --set model=bert --dry-run
+  s9s templates submit nightly --cluster production`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE:         runTemplatesSubmit,
 }
 
 func init() {
 	templatesExportCmd.Flags().BoolVar(&exportForce, "force", false, "overwrite existing files")
 	templatesExportCmd.Flags().StringVar(&exportDir, "dir", "", "output directory (default: ~/.s9s/templates/)")
 
+	for _, cmd := range []*cobra.Command{templatesRenderCmd, templatesSubmitCmd} {
+		cmd.Flags().StringArrayVar(&templateValues, "set", nil, "set a template parameter (name=value), repeatable")
+	}
+	templatesRenderCmd.Flags().StringVarP(&renderOutput, "output", "o", "script", "output format: script or json")
+	templatesSubmitCmd.Flags().BoolVar(&submitDryRun, "dry-run", false, "print the job script instead of submitting it")
+
 	templatesCmd.AddCommand(templatesExportCmd)
 	templatesCmd.AddCommand(templatesListCmd)
+	templatesCmd.AddCommand(templatesRenderCmd)
+	templatesCmd.AddCommand(templatesSubmitCmd)
 	rootCmd.AddCommand(templatesCmd)
 }
 
@@ -158,73 +213,128 @@ func runTemplatesList(_ *cobra.Command, _ []string) error {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
 
-	type templateEntry struct {
-		Name        string
-		Source      string
-		Description string
+	templates, err := views.LoadTemplateLibrary(&cfg.Views.Jobs.Submission)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: skipping templates: %v\n", err)
+	}
+	if len(templates) == 0 {
+		fmt.Println("No templates found.")
+		return nil
 	}
 
-	seen := make(map[string]int)
-	var entries []templateEntry
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "NAME\tVERSION\tSOURCE\tPARAMETERS\tDESCRIPTION")
+	for _, t := range templates {
+		version := t.Version
+		if t.Revision != "" {
+			version = strings.TrimSpace(version + " @" + t.Revision)
+		}
+		params := make([]string, 0, len(t.Parameters))
+		for _, p := range t.Parameters {
+			params = append(params, p.Name)
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			t.Name, dashIfEmpty(version), t.Source, dashIfEmpty(strings.Join(params, ",")), t.Description)
+	}
+	_ = w.Flush()
 
-	// 1. Built-in templates (lowest priority)
-	for _, t := range views.BuiltinTemplates() {
-		seen[t.Name] = len(entries)
-		entries = append(entries, templateEntry{
-			Name:        t.Name,
-			Source:      "builtin",
-			Description: t.Description,
-		})
+	return nil
+}
+
+func runTemplatesRender(_ *cobra.Command, args []string) error {
+	cfg, err := config.LoadWithPath(cfgFile)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	job, err := templateJob(cfg, args[0])
+	if err != nil {
+		return err
 	}
 
-	// 2. Config YAML templates
-	if cfg.Views.Jobs.Submission.Templates != nil {
-		for _, ct := range cfg.Views.Jobs.Submission.Templates {
-			e := templateEntry{
-				Name:        ct.Name,
-				Source:      "config",
-				Description: ct.Description,
-			}
-			if idx, ok := seen[ct.Name]; ok {
-				entries[idx] = e
-			} else {
-				seen[ct.Name] = len(entries)
-				entries = append(entries, e)
-			}
+	switch renderOutput {
+	case "script":
+		fmt.Print(views.JobScript(job))
+	case "json":
+		data, err := json.MarshalIndent(job, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal job: %w", err)
 		}
+		fmt.Println(string(data))
+	default:
+		return fmt.Errorf("unknown output format %q (use script or json)", renderOutput)
 	}
+	return nil
+}
 
-	// 3. Saved templates (highest priority)
-	mgr := views.NewJobTemplateManager()
-	for _, saved := range mgr.GetTemplates() {
-		e := templateEntry{
-			Name:        saved.Name,
-			Source:      "saved",
-			Description: saved.Description,
-		}
-		if idx, ok := seen[saved.Name]; ok {
-			entries[idx] = e
-		} else {
-			seen[saved.Name] = len(entries)
-			entries = append(entries, e)
+func runTemplatesSubmit(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	cfg, err := initializeConfiguration(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	job, err := templateJob(cfg, args[0])
+	if err != nil {
+		return err
+	}
+	if job.WorkingDir == "" {
+		if job.WorkingDir, err = os.Getwd(); err != nil {
+			return fmt.Errorf("failed to determine working directory: %w", err)
 		}
 	}
+	if err := views.ValidateJobSubmission(job); err != nil {
+		return err
+	}
 
-	if len(entries) == 0 {
-		fmt.Println("No templates found.")
+	if submitDryRun {
+		fmt.Print(views.JobScript(job))
 		return nil
 	}
 
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	_, _ = fmt.Fprintln(w, "NAME\tSOURCE\tDESCRIPTION")
-	for _, e := range entries {
-		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", e.Name, e.Source, e.Description)
+	client, err := app.NewSlurmClient(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create SLURM client: %w", err)
 	}
-	_ = w.Flush()
+	defer func() { _ = client.Close() }()
 
+	jobID, err := client.Jobs().Submit(job)
+	if err != nil {
+		return fmt.Errorf("failed to submit job: %w", err)
+	}
+	fmt.Printf("✅ Submitted job %s (%s)\n", jobID, job.Name)
 	return nil
 }
 
+// templateJob returns the job of the named template with the parameter
+// values of --set
+func templateJob(cfg *config.Config, name string) (*dao.JobSubmission, error) {
+	templates, libErr := views.LoadTemplateLibrary(&cfg.Views.Jobs.Submission)
+	t, err := views.FindTemplate(templates, name)
+	if err != nil {
+		if libErr != nil {
+			return nil, fmt.Errorf("%w (some templates could not be loaded: %v)", err, libErr)
+		}
+		return nil, err
+	}
+
+	set := make(map[string]string, len(templateValues))
+	for _, assignment := range templateValues {
+		key, value, ok := strings.Cut(assignment, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid --set %q: use name=value", assignment)
+		}
+		set[key] = value
+	}
+	return views.TemplateJob(&cfg.Views.Jobs.Submission, t, set)
+}
+
+// dashIfEmpty returns "-" for an empty table cell
+func dashIfEmpty(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
 // getExportableTemplates merges built-in and config templates (2 tiers only).
 // Saved templates are excluded since they are already on disk.
 func getExportableTemplates(cfg *config.Config) []*dao.JobTemplate {
@@ -2,11 +2,11 @@
 package config
 
 import (
-	"errors"
 	"fmt"
 	"os"
 	osuser "os/user"
 	"path/filepath"
+	"reflect"
 	"strings"
 
 	"github.com/jontk/s9s/internal/fileperms"
@@ -30,10 +30,19 @@ type Config struct {
 	PluginSettings PluginSettings    `mapstructure:"pluginSettings" yaml:"pluginSettings,omitempty"`
 	Discovery      DiscoveryConfig   `mapstructure:"discovery" yaml:"discovery,omitempty"`
 	Update         UpdateConfig      `mapstructure:"update" yaml:"update,omitempty"`
+	History        HistoryConfig     `mapstructure:"history" yaml:"history,omitempty"`
+	Health         HealthConfig      `mapstructure:"health" yaml:"health,omitempty"`
+	Audit          AuditConfig       `mapstructure:"audit" yaml:"audit,omitempty"`
+	Safety         SafetyConfig      `mapstructure:"safety" yaml:"safety,omitempty"`
 
 	// Computed fields
 	Cluster    ClusterConfig `mapstructure:"-" yaml:"-"`
-	ConfigPath string        `mapstructure:"-" yaml:"-"` // Path to the config file that was loaded
+	ConfigPath string        `mapstructure:"-" yaml:"-"` // Path to the user config file, which may not exist
+	Layers     []Layer       `mapstructure:"-" yaml:"-"` // Config files that were merged, in merge order
+
+	origins map[string]string // Origin of the settings set by layers or the environment, by path
+	loaded  map[string]any    // Settings as loaded, by path
+	own     map[string]any    // Settings of the user file, by path
 }
 
 // DiscoveryConfig holds settings for auto-discovery of slurmrestd endpoint and token
@@ -56,12 +65,63 @@ type ClusterContext struct {
 
 // ClusterConfig holds SLURM cluster connection details
 type ClusterConfig struct {
-	Endpoint   string `mapstructure:"endpoint" yaml:"endpoint"`
-	Token      string `mapstructure:"token" yaml:"token,omitempty"`
-	APIVersion string `mapstructure:"apiVersion" yaml:"apiVersion,omitempty"`
-	Insecure   bool   `mapstructure:"insecure" yaml:"insecure,omitempty"`
-	Timeout    string `mapstructure:"timeout" yaml:"timeout,omitempty"`
-	User       string `mapstructure:"user" yaml:"user,omitempty"`
+	Endpoint   string            `mapstructure:"endpoint" yaml:"endpoint"`
+	Token      string            `mapstructure:"token" yaml:"token,omitempty"`
+	TokenFrom  TokenSourceConfig `mapstructure:"tokenFrom" yaml:"tokenFrom,omitempty"` // Where to fetch the token; takes precedence over token
+	APIVersion string            `mapstructure:"apiVersion" yaml:"apiVersion,omitempty"`
+	Insecure   bool              `mapstructure:"insecure" yaml:"insecure,omitempty"`
+	Timeout    string            `mapstructure:"timeout" yaml:"timeout,omitempty"`
+	User       string            `mapstructure:"user" yaml:"user,omitempty"`
+}
+
+// TokenSourceConfig names where the token of a cluster comes from. Exactly
+// one of command, file, keyring, env or oauth2 is set.
+type TokenSourceConfig struct {
+	Command       string             `mapstructure:"command" yaml:"command,omitempty"`             // Command printing the token, e.g. "scontrol token lifespan=3600"
+	File          string             `mapstructure:"file" yaml:"file,omitempty"`                   // File holding the token, re-read on refresh
+	Keyring       string             `mapstructure:"keyring" yaml:"keyring,omitempty"`             // Key of the token in the system keyring
+	Env           string             `mapstructure:"env" yaml:"env,omitempty"`                     // Environment variable holding the token
+	OAuth2        *OAuth2TokenConfig `mapstructure:"oauth2" yaml:"oauth2,omitempty"`               // OAuth2/OIDC login
+	RefreshBefore string             `mapstructure:"refreshBefore" yaml:"refreshBefore,omitempty"` // Refresh this long before expiry (default: 5m)
+}
+
+// OAuth2TokenConfig holds the OAuth2/OIDC client used to obtain a token
+type OAuth2TokenConfig struct {
+	Provider              string `mapstructure:"provider" yaml:"provider,omitempty"` // okta, azure-ad, google, github or custom
+	ClientID              string `mapstructure:"clientId" yaml:"clientId"`
+	ClientSecret          string `mapstructure:"clientSecret" yaml:"clientSecret,omitempty"`
+	DiscoveryURL          string `mapstructure:"discoveryUrl" yaml:"discoveryUrl,omitempty"`
+	AuthorizationEndpoint string `mapstructure:"authorizationEndpoint" yaml:"authorizationEndpoint,omitempty"`
+	TokenEndpoint         string `mapstructure:"tokenEndpoint" yaml:"tokenEndpoint,omitempty"`
+	RedirectURI           string `mapstructure:"redirectUri" yaml:"redirectUri,omitempty"`
+	Scopes                string `mapstructure:"scopes" yaml:"scopes,omitempty"` // Space-separated
+}
+
+// Kinds returns the token sources that are set, in the order command,
+// file, keyring, env, oauth2
+func (t *TokenSourceConfig) Kinds() []string {
+	var kinds []string
+	if t.Command != "" {
+		kinds = append(kinds, "command")
+	}
+	if t.File != "" {
+		kinds = append(kinds, "file")
+	}
+	if t.Keyring != "" {
+		kinds = append(kinds, "keyring")
+	}
+	if t.Env != "" {
+		kinds = append(kinds, "env")
+	}
+	if t.OAuth2 != nil {
+		kinds = append(kinds, "oauth2")
+	}
+	return kinds
+}
+
+// IsSet reports whether a token source is configured
+func (t *TokenSourceConfig) IsSet() bool {
+	return len(t.Kinds()) > 0
 }
 
 // UIConfig holds UI-related settings
@@ -80,6 +140,8 @@ type ViewsConfig struct {
 	Jobs       JobsViewConfig       `mapstructure:"jobs" yaml:"jobs"`
 	Nodes      NodesViewConfig      `mapstructure:"nodes" yaml:"nodes"`
 	Partitions PartitionsViewConfig `mapstructure:"partitions" yaml:"partitions"`
+	Topology   TopologyViewConfig   `mapstructure:"topology" yaml:"topology,omitempty"`
+	SharedDir  string               `mapstructure:"sharedDir" yaml:"sharedDir,omitempty"` // Team directory of read-only saved views
 }
 
 // JobsViewConfig holds jobs view settings
@@ -89,6 +151,21 @@ type JobsViewConfig struct {
 	DefaultSort    string              `mapstructure:"defaultSort" yaml:"defaultSort,omitempty"`
 	MaxJobs        int                 `mapstructure:"maxJobs" yaml:"maxJobs,omitempty"`
 	Submission     JobSubmissionConfig `mapstructure:"submission" yaml:"submission,omitempty"`
+	Progress       JobProgressConfig   `mapstructure:"progress" yaml:"progress,omitempty"`
+}
+
+// JobProgressConfig holds settings for extracting progress and ETA from job output
+type JobProgressConfig struct {
+	Presets      []string                `mapstructure:"presets" yaml:"presets,omitempty"`           // Built-in extractors to use (default: all)
+	Patterns     []ProgressPatternConfig `mapstructure:"patterns" yaml:"patterns,omitempty"`         // Custom extractors, checked before presets
+	SampleOutput *bool                   `mapstructure:"sampleOutput" yaml:"sampleOutput,omitempty"` // Scan output tails of running jobs on refresh (default: true)
+}
+
+// ProgressPatternConfig is a custom progress regex with a "percent" named
+// group or "current" and "total" named groups
+type ProgressPatternConfig struct {
+	Name    string `mapstructure:"name" yaml:"name"`
+	Pattern string `mapstructure:"pattern" yaml:"pattern"`
 }
 
 // JobSubmissionConfig holds job submission form settings and templates
@@ -99,14 +176,38 @@ type JobSubmissionConfig struct {
 	ShowBuiltinTemplates *bool               `mapstructure:"showBuiltinTemplates" yaml:"showBuiltinTemplates,omitempty"`
 	TemplateSources      []string            `mapstructure:"templateSources" yaml:"templateSources,omitempty"`
 	Templates            []JobTemplateConfig `mapstructure:"templates" yaml:"templates,omitempty"`
+	Sweep                SweepConfig         `mapstructure:"sweep" yaml:"sweep,omitempty"`
+}
+
+// SweepConfig holds parameter sweep settings
+type SweepConfig struct {
+	MaxJobs        int    `mapstructure:"maxJobs" yaml:"maxJobs,omitempty"`               // Most individual jobs a sweep submits
+	SubmitInterval string `mapstructure:"submitInterval" yaml:"submitInterval,omitempty"` // Pause between submissions, e.g. "200ms"
 }
 
 // JobTemplateConfig represents a user-defined job submission template
 type JobTemplateConfig struct {
-	Name         string         `mapstructure:"name" yaml:"name"`
-	Description  string         `mapstructure:"description" yaml:"description"`
-	Defaults     map[string]any `mapstructure:"defaults" yaml:"defaults"`
-	HiddenFields []string       `mapstructure:"hiddenFields" yaml:"hiddenFields"`
+	Name         string                    `mapstructure:"name" yaml:"name"`
+	Description  string                    `mapstructure:"description" yaml:"description"`
+	Version      string                    `mapstructure:"version" yaml:"version,omitempty"`
+	Parameters   []TemplateParameterConfig `mapstructure:"parameters" yaml:"parameters,omitempty"`
+	Defaults     map[string]any            `mapstructure:"defaults" yaml:"defaults"`
+	HiddenFields []string                  `mapstructure:"hiddenFields" yaml:"hiddenFields"`
+}
+
+// TemplateParameterConfig is a variable of a parameterized job template.
+// Its value is substituted into the template defaults, which are Go
+// templates when the template declares parameters.
+type TemplateParameterConfig struct {
+	Name        string   `mapstructure:"name" yaml:"name"`
+	Type        string   `mapstructure:"type" yaml:"type,omitempty"` // string (default), int, bool, enum, path or duration
+	Description string   `mapstructure:"description" yaml:"description,omitempty"`
+	Default     any      `mapstructure:"default" yaml:"default,omitempty"`
+	Required    bool     `mapstructure:"required" yaml:"required,omitempty"`
+	Options     []string `mapstructure:"options" yaml:"options,omitempty"` // Values of an enum
+	Min         *int     `mapstructure:"min" yaml:"min,omitempty"`         // Bounds of an int
+	Max         *int     `mapstructure:"max" yaml:"max,omitempty"`
+	MustExist   bool     `mapstructure:"mustExist" yaml:"mustExist,omitempty"` // A path must exist
 }
 
 // NodesViewConfig holds nodes view settings
@@ -122,6 +223,20 @@ type PartitionsViewConfig struct {
 	ShowWaitTime   bool `mapstructure:"showWaitTime" yaml:"showWaitTime,omitempty"`
 }
 
+// TopologyViewConfig holds topology view settings
+type TopologyViewConfig struct {
+	Source  string               `mapstructure:"source" yaml:"source,omitempty"`   // auto, racks, file, slurm or prefix (default: auto)
+	File    string               `mapstructure:"file" yaml:"file,omitempty"`       // topology.conf to import
+	Racks   []TopologyRackConfig `mapstructure:"racks" yaml:"racks,omitempty"`     // Rack map, used before any other source
+	ColorBy string               `mapstructure:"colorBy" yaml:"colorBy,omitempty"` // state, cpu, memory, gpu or load (default: state)
+}
+
+// TopologyRackConfig places the nodes of a hostlist expression in a rack
+type TopologyRackConfig struct {
+	Name  string `mapstructure:"name" yaml:"name"`
+	Nodes string `mapstructure:"nodes" yaml:"nodes"` // e.g. "node[001-040]"
+}
+
 // FeaturesConfig holds feature flags
 type FeaturesConfig struct {
 	Streaming      bool `mapstructure:"streaming" yaml:"streaming,omitempty"`
@@ -153,6 +268,73 @@ type UpdateConfig struct {
 	PreRelease    bool   `mapstructure:"preRelease" yaml:"preRelease,omitempty"`
 }
 
+// HistoryConfig holds settings for the in-process metrics history
+type HistoryConfig struct {
+	Persist   bool   `mapstructure:"persist" yaml:"persist,omitempty"`     // Save history to ~/.s9s/history on exit
+	Retention string `mapstructure:"retention" yaml:"retention,omitempty"` // How much history to keep, e.g. "24h"
+}
+
+// AuditConfig holds settings for the audit log of mutating actions
+type AuditConfig struct {
+	Enabled   bool               `mapstructure:"enabled" yaml:"enabled"`
+	File      string             `mapstructure:"file" yaml:"file,omitempty"`           // JSON lines log (default: ~/.s9s/audit.log)
+	MaxSizeMB int                `mapstructure:"maxSizeMB" yaml:"maxSizeMB,omitempty"` // Rotate when the log reaches this size
+	MaxFiles  int                `mapstructure:"maxFiles" yaml:"maxFiles,omitempty"`   // Rotated logs to keep
+	Forward   AuditForwardConfig `mapstructure:"forward" yaml:"forward,omitempty"`
+}
+
+// AuditForwardConfig copies audit entries to a second file in a format a
+// log shipper can pick up
+type AuditForwardConfig struct {
+	Format string `mapstructure:"format" yaml:"format,omitempty"` // syslog (RFC 5424) or journald (journal export format)
+	File   string `mapstructure:"file" yaml:"file,omitempty"`
+}
+
+// SafetyConfig holds the policies every job, node and partition mutation
+// must pass, from the TUI, batch operations and headless commands alike
+type SafetyConfig struct {
+	AdminMode           bool                `mapstructure:"adminMode" yaml:"adminMode,omitempty"`                     // Start in admin mode, which allows cancelling other users' jobs
+	AllowOtherUsersJobs bool                `mapstructure:"allowOtherUsersJobs" yaml:"allowOtherUsersJobs,omitempty"` // Cancel and requeue other users' jobs without admin mode
+	MaxDrainNodes       int                 `mapstructure:"maxDrainNodes" yaml:"maxDrainNodes,omitempty"`             // Most nodes drained within drainWindow (0: no limit)
+	MaxDrainPercent     float64             `mapstructure:"maxDrainPercent" yaml:"maxDrainPercent,omitempty"`         // Most percent of a partition drained within drainWindow (0: no limit)
+	DrainWindow         string              `mapstructure:"drainWindow" yaml:"drainWindow,omitempty"`                 // How long drains count towards the limits (default: "10m")
+	Protect             []ProtectRuleConfig `mapstructure:"protect" yaml:"protect,omitempty"`
+}
+
+// ProtectRuleConfig protects the jobs, nodes or partitions it matches from
+// destructive actions. Every criterion that is set must match.
+type ProtectRuleConfig struct {
+	Name       string   `mapstructure:"name" yaml:"name,omitempty"`
+	Actions    []string `mapstructure:"actions" yaml:"actions,omitempty"`       // e.g. node.drain or job.*; default: every destructive action
+	Nodes      string   `mapstructure:"nodes" yaml:"nodes,omitempty"`           // Hostlist, e.g. login[01-02],gpu[01-08]
+	Partitions []string `mapstructure:"partitions" yaml:"partitions,omitempty"` // Partitions, or the partitions of the job or node
+	Jobs       []string `mapstructure:"jobs" yaml:"jobs,omitempty"`             // Job ID or name globs
+	Users      []string `mapstructure:"users" yaml:"users,omitempty"`           // Job owners
+	Mode       string   `mapstructure:"mode" yaml:"mode,omitempty"`             // confirm (type the name, default) or deny
+}
+
+// HealthConfig holds the health rules shared by the health view, dashboard
+// alerts, the exporter and `s9s health`
+type HealthConfig struct {
+	Rules []HealthRuleConfig `mapstructure:"rules" yaml:"rules,omitempty"`
+}
+
+// HealthRuleConfig is a declarative health rule. A rule named after a
+// built-in rule or check (nodes, queue, utilization) replaces or retunes it.
+type HealthRuleConfig struct {
+	Name        string   `mapstructure:"name" yaml:"name"`
+	Description string   `mapstructure:"description" yaml:"description,omitempty"`
+	Expr        string   `mapstructure:"expr" yaml:"expr,omitempty"`             // Metric expression, e.g. "jobs_pending / max(nodes_idle, 1)"
+	Partitions  []string `mapstructure:"partitions" yaml:"partitions,omitempty"` // Partitions to evaluate per; "*" for all
+	Warning     *float64 `mapstructure:"warning" yaml:"warning,omitempty"`
+	Critical    *float64 `mapstructure:"critical" yaml:"critical,omitempty"`
+	Below       bool     `mapstructure:"below" yaml:"below,omitempty"`       // Breach below the thresholds instead of above
+	For         string   `mapstructure:"for" yaml:"for,omitempty"`           // How long a breach must last, e.g. "10m"
+	Severity    string   `mapstructure:"severity" yaml:"severity,omitempty"` // info, warning or critical
+	Message     string   `mapstructure:"message" yaml:"message,omitempty"`
+	Disabled    bool     `mapstructure:"disabled" yaml:"disabled,omitempty"`
+}
+
 // PluginSettings contains global plugin settings
 type PluginSettings struct {
 	EnableAll     bool    `mapstructure:"enableAll" yaml:"enableAll,omitempty"`
@@ -186,6 +368,13 @@ func DefaultConfig() *Config {
 				ShowOnlyActive: true,                                                                 // Aligned with setDefaults
 				DefaultSort:    "time",                                                               // Aligned with setDefaults
 				MaxJobs:        1000,                                                                 // Aligned with setDefaults
+				Submission: JobSubmissionConfig{
+					TemplateSources: []string{"builtin", "config", "saved"}, // Aligned with setDefaults
+					Sweep: SweepConfig{
+						MaxJobs:        500,     // Aligned with setDefaults
+						SubmitInterval: "200ms", // Aligned with setDefaults
+					},
+				},
 			},
 			Nodes: NodesViewConfig{
 				GroupBy:         "partition", // Aligned with setDefaults
@@ -196,6 +385,10 @@ func DefaultConfig() *Config {
 				ShowQueueDepth: true,
 				ShowWaitTime:   true,
 			},
+			Topology: TopologyViewConfig{
+				Source:  "auto",  // Aligned with setDefaults
+				ColorBy: "state", // Aligned with setDefaults
+			},
 		},
 		Features: FeaturesConfig{
 			Streaming: true, // Aligned with setDefaults
@@ -226,6 +419,18 @@ func DefaultConfig() *Config {
 			CheckInterval: "24h",
 			PreRelease:    false,
 		},
+		History: HistoryConfig{
+			Persist:   false,
+			Retention: "24h",
+		},
+		Audit: AuditConfig{
+			Enabled:   true,
+			MaxSizeMB: 10,
+			MaxFiles:  5,
+		},
+		Safety: SafetyConfig{
+			DrainWindow: "10m",
+		},
 		Discovery: DiscoveryConfig{
 			Enabled:        true,  // Aligned with setDefaults
 			EnableEndpoint: true,  // Aligned with setDefaults
@@ -242,46 +447,30 @@ func Load() (*Config, error) {
 	return LoadWithPath("")
 }
 
-// LoadWithPath reads configuration from a specific file path
+// LoadWithPath reads the configuration from the config file layers and
+// the environment. configPath replaces the user file ~/.s9s/config.yaml and
+// must exist if given. The layers are merged in this order, later layers
+// taking precedence:
+//
+//   - system: /etc/s9s/config.yaml
+//   - site: the file named by $S9S_SITE_CONFIG
+//   - user: configPath or ~/.s9s/config.yaml
+//   - project: .s9s.yaml in the working directory or its nearest parent
+//
+// Environment variables take precedence over every layer.
 func LoadWithPath(configPath string) (*Config, error) {
-	v := viper.New()
-
-	// Set config file details
-	v.SetConfigName("config")
-	v.SetConfigType("yaml")
-
-	// Config search paths
-	if configPath != "" {
-		v.SetConfigFile(configPath)
-	} else {
-		v.AddConfigPath(".")
-		v.AddConfigPath("$HOME/.s9s")
-		v.AddConfigPath("/etc/s9s")
+	layers, err := findLayers(configPath)
+	if err != nil {
+		return nil, err
 	}
-
-	// Environment variable support
-	v.SetEnvPrefix("S9S")
-	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
-	v.AutomaticEnv()
-
-	// Set defaults
-	setDefaults(v)
-
-	// Read config file if exists
-	if err := v.ReadInConfig(); err != nil {
-		// If config file not found, use defaults and environment
-		var notFoundErr viper.ConfigFileNotFoundError
-		if !errors.As(err, &notFoundErr) {
-			return nil, fmt.Errorf("reading config: %w", err)
-		}
+	settings, origins, own, err := loadLayers(layers)
+	if err != nil {
+		return nil, err
 	}
 
-	// Create config struct
-	cfg := &Config{}
-
-	// Unmarshal configuration
-	if err := v.Unmarshal(cfg); err != nil {
-		return nil, fmt.Errorf("unmarshaling config: %w", err)
+	cfg, err := decodeSettings(settings, true)
+	if err != nil {
+		return nil, err
 	}
 
 	// Override with environment variables
@@ -292,13 +481,14 @@ func LoadWithPath(configPath string) (*Config, error) {
 		return nil, err
 	}
 
-	// Record the config file path that was used
-	if configPath != "" {
-		cfg.ConfigPath = configPath
-	} else if usedFile := v.ConfigFileUsed(); usedFile != "" {
-		cfg.ConfigPath = usedFile
-	} else {
-		cfg.ConfigPath = filepath.Join(os.Getenv("HOME"), ".s9s", "config.yaml")
+	// Record the user config file, which changes are saved to
+	cfg.ConfigPath = configPath
+	if cfg.ConfigPath == "" {
+		cfg.ConfigPath = defaultUserConfigPath()
+	}
+	cfg.Layers = layers
+	if err := cfg.recordOrigins(origins, own); err != nil {
+		return nil, err
 	}
 
 	// Ensure config directory exists
@@ -308,6 +498,34 @@ func LoadWithPath(configPath string) (*Config, error) {
 	return cfg, nil
 }
 
+// decodeSettings returns the configuration with settings, keyed like the
+// config file, over the defaults and, if env is set, under the S9S_
+// environment variables
+func decodeSettings(settings map[string]any, env bool) (*Config, error) {
+	v := viper.New()
+	v.SetConfigType("yaml")
+
+	// Environment variable support
+	if env {
+		v.SetEnvPrefix("S9S")
+		v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+		v.AutomaticEnv()
+	}
+
+	// Set defaults
+	setDefaults(v)
+
+	if err := v.MergeConfigMap(settings); err != nil {
+		return nil, fmt.Errorf("reading config: %w", err)
+	}
+
+	cfg := &Config{}
+	if err := v.Unmarshal(cfg); err != nil {
+		return nil, fmt.Errorf("unmarshaling config: %w", err)
+	}
+	return cfg, nil
+}
+
 // setDefaults sets default configuration values
 func setDefaults(v *viper.Viper) {
 	// General defaults
@@ -332,6 +550,8 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("views.jobs.maxJobs", 1000)
 
 	v.SetDefault("views.jobs.submission.templateSources", []string{"builtin", "config", "saved"})
+	v.SetDefault("views.jobs.submission.sweep.maxJobs", 500)
+	v.SetDefault("views.jobs.submission.sweep.submitInterval", "200ms")
 
 	v.SetDefault("views.nodes.groupBy", "partition")
 	v.SetDefault("views.nodes.showUtilization", true)
@@ -340,6 +560,9 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("views.partitions.showQueueDepth", true)
 	v.SetDefault("views.partitions.showWaitTime", true)
 
+	v.SetDefault("views.topology.source", "auto")
+	v.SetDefault("views.topology.colorBy", "state")
+
 	// Features defaults
 	v.SetDefault("features.streaming", true)
 	v.SetDefault("features.pulseye", true)
@@ -368,6 +591,18 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("update.checkInterval", "24h")
 	v.SetDefault("update.preRelease", false)
 
+	// History defaults
+	v.SetDefault("history.persist", false)
+	v.SetDefault("history.retention", "24h")
+
+	// Audit defaults
+	v.SetDefault("audit.enabled", true)
+	v.SetDefault("audit.maxSizeMB", 10)
+	v.SetDefault("audit.maxFiles", 5)
+
+	// Safety defaults
+	v.SetDefault("safety.drainWindow", "10m")
+
 	// Discovery defaults
 	v.SetDefault("discovery.enabled", true)
 	v.SetDefault("discovery.enableEndpoint", true)
@@ -511,13 +746,15 @@ func ResolveSlurmUserForCluster(cfg *ClusterConfig) string {
 	return ""
 }
 
-// SaveToFile saves the configuration to a file
+// SaveToFile saves the configuration to a file, starting with the
+// yaml-language-server modeline pointing editors at the JSON Schema
 func (c *Config) SaveToFile(path string) error {
 	dir := filepath.Dir(path)
 	if err := os.MkdirAll(dir, 0o750); err != nil {
 		return fmt.Errorf("creating config directory: %w", err)
 	}
 
+	existing, _ := os.ReadFile(path)
 	fullData, err := yaml.Marshal(c)
 	if err != nil {
 		return fmt.Errorf("marshaling config: %w", err)
@@ -525,13 +762,15 @@ func (c *Config) SaveToFile(path string) error {
 
 	// If the file already exists, merge to preserve its structure and
 	// prevent viper-injected defaults from polluting the file.
-	// For new or malformed files, write the full config.
+	// For new or malformed files, write the full config, less the settings
+	// inherited from other layers.
 	merged, err := c.mergeWithExisting(path, fullData)
-	if err != nil {
-		return os.WriteFile(path, fullData, 0o600)
-	}
-	if merged == nil {
-		return os.WriteFile(path, fullData, 0o600)
+	if err != nil || merged == nil {
+		fullData, err = c.ownData(fullData)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(path, withSchemaHeader(fullData, existing), 0o600)
 	}
 
 	out, err := yaml.Marshal(merged)
@@ -539,7 +778,28 @@ func (c *Config) SaveToFile(path string) error {
 		return fmt.Errorf("marshaling merged config: %w", err)
 	}
 
-	return os.WriteFile(path, out, 0o600)
+	return os.WriteFile(path, withSchemaHeader(out, existing), 0o600)
+}
+
+// ownData returns fullData, the full config, without the settings c
+// inherited from other layers or the environment
+func (c *Config) ownData(fullData []byte) ([]byte, error) {
+	if len(c.Layers) == 0 && len(c.origins) == 0 {
+		return fullData, nil
+	}
+	full := make(map[string]any)
+	if err := yaml.Unmarshal(fullData, &full); err != nil {
+		return nil, fmt.Errorf("marshaling config: %w", err)
+	}
+	own := c.withoutInherited(full)
+	if len(own) == len(full) && reflect.DeepEqual(own, full) {
+		return fullData, nil
+	}
+	data, err := yaml.Marshal(own)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling config: %w", err)
+	}
+	return data, nil
 }
 
 // mergeWithExisting reads an existing config file and returns a merged map
@@ -560,6 +820,7 @@ func (c *Config) mergeWithExisting(path string, fullData []byte) (map[string]any
 	if err := yaml.Unmarshal(fullData, &full); err != nil {
 		return nil, err
 	}
+	full = c.withoutInherited(full)
 
 	coreFields := []string{"refreshRate", "maxRetries", "defaultCluster", "clusters"}
 
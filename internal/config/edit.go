@@ -0,0 +1,449 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// sensitiveKeys are the setting names whose values are masked when shown
+var sensitiveKeys = map[string]bool{
+	"token":        true,
+	"clientsecret": true,
+	"password":     true,
+}
+
+// IsSensitive reports whether the setting at path holds a secret
+func IsSensitive(path string) bool {
+	keys := splitPath(path)
+	return len(keys) > 0 && sensitiveKeys[strings.ToLower(keys[len(keys)-1])]
+}
+
+// Lookup returns the value of the setting at a dotted path such as
+// views.jobs.maxJobs or clusters.0.cluster.endpoint. Keys are matched
+// case-insensitively. Unset optional settings return nil.
+func (c *Config) Lookup(path string) (any, error) {
+	keys := splitPath(path)
+	if len(keys) == 0 {
+		return nil, errors.New("empty configuration path")
+	}
+
+	v := reflect.ValueOf(c).Elem()
+	for i, key := range keys {
+		at := strings.Join(keys[:i], ".")
+		for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return nil, nil
+			}
+			v = v.Elem()
+		}
+
+		switch v.Kind() {
+		case reflect.Struct:
+			index, ok := fieldIndex(v.Type(), key)
+			if !ok {
+				return nil, unknownKeyError(keys[:i+1])
+			}
+			v = v.Field(index)
+		case reflect.Map:
+			value, ok := mapIndex(v, key)
+			if !ok {
+				return nil, nil
+			}
+			v = value
+		case reflect.Slice:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= v.Len() {
+				return nil, fmt.Errorf("%s has no element %s", at, key)
+			}
+			v = v.Index(index)
+		default:
+			return nil, fmt.Errorf("%s is not a section", at)
+		}
+	}
+
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+	return v.Interface(), nil
+}
+
+// FormatValue formats a setting for display: scalars as plain text,
+// sections and lists as YAML, and unset settings as an empty string
+func FormatValue(value any) string {
+	if value == nil {
+		return ""
+	}
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice:
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(value); err != nil {
+			return fmt.Sprint(value)
+		}
+		return strings.TrimSuffix(buf.String(), "\n")
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// SetValue returns data, the contents of a YAML config file, with the
+// setting at path set to raw. raw is parsed as the type of the setting and
+// checked against its schema field, if it has one. Lists of strings may be
+// given comma-separated. Comments and key order of data are kept.
+func SetValue(data []byte, path, raw string) ([]byte, error) {
+	keys := splitPath(path)
+	if len(keys) == 0 {
+		return nil, errors.New("empty configuration path")
+	}
+
+	t, canonical, schemaKey, err := settingType(keys)
+	if err != nil {
+		return nil, err
+	}
+	value, node, err := parseSetting(t, raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for %s: %w", strings.Join(canonical, "."), err)
+	}
+	if field := GetConfigSchema().GetFieldByKey(schemaKey); field != nil {
+		if result := field.ValidateField(value); !result.Valid {
+			return nil, errors.New(strings.Join(result.Errors, "; "))
+		}
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing config: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return nil, errors.New("config file is not a YAML mapping")
+	}
+	if err := setNode(doc.Content[0], canonical, node); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, fmt.Errorf("marshaling config: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("marshaling config: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Change is a setting whose value differs between two configurations. A
+// nil From or To means the setting is unset on that side.
+type Change struct {
+	Path string
+	From any
+	To   any
+}
+
+// Diff returns the settings of cfg that differ from base, sorted by path.
+// Sections and lists of sections are compared setting by setting.
+func Diff(base, cfg *Config) ([]Change, error) {
+	from, err := flattenConfig(base)
+	if err != nil {
+		return nil, err
+	}
+	to, err := flattenConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(from)+len(to))
+	for path := range from {
+		paths = append(paths, path)
+	}
+	for path := range to {
+		if _, ok := from[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	var changes []Change
+	for _, path := range paths {
+		if !reflect.DeepEqual(from[path], to[path]) {
+			changes = append(changes, Change{Path: path, From: from[path], To: to[path]})
+		}
+	}
+	return changes, nil
+}
+
+// flattenConfig returns the set settings of cfg by dotted path
+func flattenConfig(cfg *Config) (map[string]any, error) {
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling config: %w", err)
+	}
+	var tree any
+	if err := yaml.Unmarshal(data, &tree); err != nil {
+		return nil, fmt.Errorf("parsing config: %w", err)
+	}
+	flat := make(map[string]any)
+	flatten("", tree, flat)
+	return flat, nil
+}
+
+func flatten(prefix string, value any, flat map[string]any) {
+	join := func(key string) string {
+		if prefix == "" {
+			return key
+		}
+		return prefix + "." + key
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		if len(v) == 0 && prefix != "" {
+			flat[prefix] = v
+		}
+		for key, child := range v {
+			flatten(join(key), child, flat)
+		}
+	case []any:
+		// Lists of sections are compared per element, lists of scalars as
+		// a whole
+		sections := len(v) > 0
+		for _, item := range v {
+			if _, ok := item.(map[string]any); !ok {
+				sections = false
+				break
+			}
+		}
+		if !sections {
+			flat[prefix] = v
+			return
+		}
+		for i, item := range v {
+			flatten(join(strconv.Itoa(i)), item, flat)
+		}
+	default:
+		flat[prefix] = v
+	}
+}
+
+// splitPath splits a dotted path, accepting list[0] as well as list.0
+func splitPath(path string) []string {
+	path = strings.NewReplacer("[", ".", "]", "").Replace(strings.TrimSpace(path))
+	var keys []string
+	for _, key := range strings.Split(path, ".") {
+		if key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// fieldIndex returns the index of the field of t whose YAML name is key
+func fieldIndex(t reflect.Type, key string) (int, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		name := yamlName(t.Field(i))
+		if name != "" && strings.EqualFold(name, key) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// yamlName returns the key of a field in the config file, or "" for
+// fields that are not read from it
+func yamlName(field reflect.StructField) string {
+	if !field.IsExported() {
+		return ""
+	}
+	name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// mapIndex looks key up in a map with string keys, preferring an exact
+// match over a case-insensitive one
+func mapIndex(m reflect.Value, key string) (reflect.Value, bool) {
+	if value := m.MapIndex(reflect.ValueOf(key)); value.IsValid() {
+		return value, true
+	}
+	iter := m.MapRange()
+	for iter.Next() {
+		if strings.EqualFold(iter.Key().String(), key) {
+			return iter.Value(), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// settingType returns the type of the setting at keys, the keys with the
+// spelling of the config file, and the key of its schema field
+func settingType(keys []string) (reflect.Type, []string, string, error) {
+	t := reflect.TypeOf(Config{})
+	canonical := make([]string, 0, len(keys))
+	schemaKeys := make([]string, 0, len(keys))
+	for i, key := range keys {
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+
+		switch t.Kind() {
+		case reflect.Struct:
+			index, ok := fieldIndex(t, key)
+			if !ok {
+				return nil, nil, "", unknownKeyError(keys[:i+1])
+			}
+			field := t.Field(index)
+			canonical = append(canonical, yamlName(field))
+			schemaKeys = append(schemaKeys, yamlName(field))
+			t = field.Type
+		case reflect.Map:
+			canonical = append(canonical, key)
+			schemaKeys = append(schemaKeys, "*")
+			t = t.Elem()
+		case reflect.Slice:
+			if _, err := strconv.Atoi(key); err != nil {
+				return nil, nil, "", fmt.Errorf("%s is a list; use an index such as %s.0",
+					strings.Join(canonical, "."), strings.Join(canonical, "."))
+			}
+			canonical = append(canonical, key)
+			schemaKeys = append(schemaKeys, "*")
+			t = t.Elem()
+		default:
+			return nil, nil, "", fmt.Errorf("%s is not a section", strings.Join(canonical, "."))
+		}
+	}
+	return t, canonical, strings.Join(schemaKeys, "."), nil
+}
+
+// parseSetting parses raw as a value of t and returns it with its YAML node
+func parseSetting(t reflect.Type, raw string) (any, *yaml.Node, error) {
+	base := t
+	for base.Kind() == reflect.Pointer {
+		base = base.Elem()
+	}
+
+	switch {
+	case base.Kind() == reflect.String:
+		return raw, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: raw}, nil
+	case base.Kind() == reflect.Slice && base.Elem().Kind() == reflect.String &&
+		!strings.HasPrefix(strings.TrimSpace(raw), "["):
+		items := []string{}
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Style: yaml.FlowStyle}
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+				node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: item})
+			}
+		}
+		return items, node, nil
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(raw), &doc); err != nil {
+		return nil, nil, err
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil, errors.New("value is empty")
+	}
+	node := doc.Content[0]
+	value := reflect.New(t)
+	if err := node.Decode(value.Interface()); err != nil {
+		return nil, nil, fmt.Errorf("%q is not a valid %s", raw, typeName(base))
+	}
+	return value.Elem().Interface(), node, nil
+}
+
+// typeName describes a setting type in error messages
+func typeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice:
+		return "list"
+	case reflect.Map, reflect.Struct:
+		return "section"
+	default:
+		return t.String()
+	}
+}
+
+// setNode sets the node at keys below root to value, creating missing
+// sections. The comments of a replaced node are kept.
+func setNode(root *yaml.Node, keys []string, value *yaml.Node) error {
+	node := root
+	for i, key := range keys {
+		last := i == len(keys)-1
+		at := strings.Join(keys[:i], ".")
+
+		var slot **yaml.Node
+		switch node.Kind {
+		case yaml.MappingNode:
+			for j := 0; j+1 < len(node.Content); j += 2 {
+				if strings.EqualFold(node.Content[j].Value, key) {
+					slot = &node.Content[j+1]
+					break
+				}
+			}
+			if slot == nil {
+				if !last {
+					if _, err := strconv.Atoi(keys[i+1]); err == nil {
+						return fmt.Errorf("%s has no element %s", strings.Join(keys[:i+1], "."), keys[i+1])
+					}
+				}
+				node.Content = append(node.Content,
+					&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+					&yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"})
+				slot = &node.Content[len(node.Content)-1]
+			}
+		case yaml.SequenceNode:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(node.Content) {
+				return fmt.Errorf("%s has no element %s", at, key)
+			}
+			slot = &node.Content[index]
+		default:
+			return fmt.Errorf("%s is not a section in the config file", at)
+		}
+
+		if last {
+			value.HeadComment = (*slot).HeadComment
+			value.LineComment = (*slot).LineComment
+			value.FootComment = (*slot).FootComment
+			*slot = value
+			return nil
+		}
+		if (*slot).Kind == yaml.ScalarNode && (*slot).Tag == "!!null" {
+			// An empty section, such as "views:" with nothing below it
+			*slot = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", LineComment: (*slot).LineComment}
+		}
+		node = *slot
+	}
+	return nil
+}
+
+func unknownKeyError(keys []string) error {
+	return fmt.Errorf("unknown configuration key %q", strings.Join(keys, "."))
+}
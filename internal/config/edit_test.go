@@ -0,0 +1,199 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestDefaultConfigMatchesViperDefaults(t *testing.T) {
+	v := viper.New()
+	setDefaults(v)
+	cfg := &Config{}
+	require.NoError(t, v.Unmarshal(cfg))
+
+	changes, err := Diff(DefaultConfig(), cfg)
+	require.NoError(t, err)
+	assert.Empty(t, changes, "DefaultConfig and setDefaults must agree")
+}
+
+func TestLookup(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Clusters = []ClusterContext{{Name: "prod", Cluster: ClusterConfig{Endpoint: "https://prod:6820"}}}
+
+	tests := []struct {
+		path string
+		want any
+	}{
+		{"refreshRate", "10s"},
+		{"REFRESHRATE", "10s"},
+		{"views.jobs.maxJobs", 1000},
+		{"views.jobs.columns", []string{"id", "name", "user", "state", "time", "nodes", "priority"}},
+		{"clusters.0.cluster.endpoint", "https://prod:6820"},
+		{"clusters[0].name", "prod"},
+		{"aliases.kj", "kill job"},
+		{"clusters.0.cluster.tokenFrom.oauth2.clientId", nil},
+		{"views.jobs.progress.sampleOutput", nil},
+	}
+	for _, tt := range tests {
+		got, err := cfg.Lookup(tt.path)
+		require.NoError(t, err, tt.path)
+		assert.Equal(t, tt.want, got, tt.path)
+	}
+
+	_, err := cfg.Lookup("views.jobs.nope")
+	assert.ErrorContains(t, err, `unknown configuration key "views.jobs.nope"`)
+	_, err = cfg.Lookup("clusters.3.name")
+	assert.ErrorContains(t, err, "clusters has no element 3")
+	_, err = cfg.Lookup("refreshRate.x")
+	assert.ErrorContains(t, err, "refreshRate is not a section")
+}
+
+func TestFormatValue(t *testing.T) {
+	assert.Equal(t, "", FormatValue(nil))
+	assert.Equal(t, "5s", FormatValue("5s"))
+	assert.Equal(t, "true", FormatValue(true))
+	assert.Equal(t, "- a\n- b", FormatValue([]string{"a", "b"}))
+	assert.Equal(t, "groupBy: state", FormatValue(NodesViewConfig{GroupBy: "state"}))
+}
+
+func TestSetValue(t *testing.T) {
+	data := []byte(`# s9s configuration
+refreshRate: 10s # how often to refresh
+views:
+  jobs:
+    maxJobs: 1000
+clusters:
+  - name: prod
+    cluster:
+      endpoint: https://prod:6820
+`)
+
+	out, err := SetValue(data, "refreshRate", "5s")
+	require.NoError(t, err)
+	assert.Contains(t, string(out), "# s9s configuration")
+	assert.Contains(t, string(out), "refreshRate: 5s # how often to refresh")
+
+	out, err = SetValue(out, "views.jobs.maxJobs", "500")
+	require.NoError(t, err)
+	out, err = SetValue(out, "views.jobs.columns", "id, name,state")
+	require.NoError(t, err)
+	out, err = SetValue(out, "views.nodes.groupBy", "state")
+	require.NoError(t, err)
+	out, err = SetValue(out, "clusters.0.readOnly", "true")
+	require.NoError(t, err)
+	out, err = SetValue(out, "safety.maxDrainPercent", "12.5")
+	require.NoError(t, err)
+
+	var cfg Config
+	require.NoError(t, yaml.Unmarshal(out, &cfg))
+	assert.Equal(t, "5s", cfg.RefreshRate)
+	assert.Equal(t, 500, cfg.Views.Jobs.MaxJobs)
+	assert.Equal(t, []string{"id", "name", "state"}, cfg.Views.Jobs.Columns)
+	assert.Equal(t, "state", cfg.Views.Nodes.GroupBy)
+	assert.True(t, cfg.Clusters[0].ReadOnly)
+	assert.Equal(t, "https://prod:6820", cfg.Clusters[0].Cluster.Endpoint)
+	assert.InDelta(t, 12.5, cfg.Safety.MaxDrainPercent, 0)
+}
+
+func TestSetValueEmptyFile(t *testing.T) {
+	out, err := SetValue(nil, "ui.skin", "dracula")
+	require.NoError(t, err)
+	assert.Equal(t, "ui:\n  skin: dracula\n", string(out))
+
+	out, err = SetValue([]byte("views:\n"), "views.jobs.maxJobs", "200")
+	require.NoError(t, err)
+	assert.Equal(t, "views:\n  jobs:\n    maxJobs: 200\n", string(out))
+}
+
+func TestSetValueRejectsInvalidValues(t *testing.T) {
+	tests := []struct {
+		path, value, err string
+	}{
+		{"views.jobs.maxJobs", "many", `"many" is not a valid integer`},
+		{"views.jobs.maxJobs", "5", "Max Jobs to Display must be at least 10"},
+		{"refreshRate", "soon", "Refresh Rate must be a valid duration"},
+		{"views.nodes.groupBy", "rack", "Group Nodes By must be one of"},
+		{"views.jobs.columns", "id,color", "array element 'color' must be one of"},
+		{"ui.logoless", "maybe", `"maybe" is not a valid boolean`},
+		{"ui.nope", "1", `unknown configuration key "ui.nope"`},
+		{"clusters.name", "x", "clusters is a list"},
+		{"clusters.0.name", "x", "clusters has no element 0"},
+	}
+	for _, tt := range tests {
+		_, err := SetValue(nil, tt.path, tt.value)
+		assert.ErrorContains(t, err, tt.err, tt.path)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.RefreshRate = "5s"
+	cfg.Views.Jobs.Columns = []string{"id", "state"}
+	cfg.Clusters = []ClusterContext{{Name: "prod", Cluster: ClusterConfig{Endpoint: "https://prod:6820"}}}
+	delete(cfg.Aliases, "kj")
+
+	changes, err := Diff(DefaultConfig(), cfg)
+	require.NoError(t, err)
+
+	byPath := make(map[string]Change)
+	var paths []string
+	for _, change := range changes {
+		byPath[change.Path] = change
+		paths = append(paths, change.Path)
+	}
+	assert.Equal(t, []string{
+		"aliases.kj",
+		"clusters.0.cluster.endpoint",
+		"clusters.0.name",
+		"refreshRate",
+		"views.jobs.columns",
+	}, paths)
+	assert.Equal(t, Change{Path: "refreshRate", From: "10s", To: "5s"}, byPath["refreshRate"])
+	assert.Equal(t, Change{Path: "aliases.kj", From: "kill job"}, byPath["aliases.kj"])
+	assert.Nil(t, byPath["clusters.0.name"].From)
+}
+
+func TestIsSensitive(t *testing.T) {
+	assert.True(t, IsSensitive("clusters.0.cluster.token"))
+	assert.True(t, IsSensitive("clusters.0.cluster.tokenFrom.oauth2.clientSecret"))
+	assert.False(t, IsSensitive("clusters.0.cluster.tokenFrom"))
+}
+
+func TestValidationResultNewErrors(t *testing.T) {
+	before := &ValidationResult{Errors: []ValidationError{{Field: "clusters", Message: "No clusters defined"}}}
+	after := &ValidationResult{Errors: []ValidationError{
+		{Field: "clusters", Message: "No clusters defined"},
+		{Field: "audit.maxFiles", Message: "maxFiles cannot be negative"},
+	}}
+
+	introduced := after.NewErrors(before)
+	require.Len(t, introduced, 1)
+	assert.Equal(t, "audit.maxFiles", introduced[0].Field)
+	assert.Len(t, after.NewErrors(nil), 2)
+}
+
+func TestValidateShortcuts(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Shortcuts = []ShortcutConfig{
+		{Key: "ctrl+e", Action: "view running"},
+		{Key: "Ctrl+E", Action: "jobs"},
+		{Key: "", Action: "jobs"},
+		{Key: "F5", Action: ""},
+		{Key: "x", Action: "jobs"},
+		{Key: "X", Action: "nodes"},
+	}
+
+	result := NewConfigValidator(cfg, false).Validate()
+	var fields []string
+	for _, err := range result.Errors {
+		if strings.HasPrefix(err.Field, "shortcuts") {
+			fields = append(fields, err.Field)
+		}
+	}
+	assert.Equal(t, []string{"shortcuts[1].key", "shortcuts[2].key", "shortcuts[3].action"}, fields)
+}
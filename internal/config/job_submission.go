@@ -1,92 +1,96 @@
 package config
 
-import "strings"
+import (
+	"reflect"
+	"strings"
+)
 
 // JobSubmissionValues holds job submission field values parsed from config maps.
 // Its fields mirror dao.JobSubmission so callers can map between them directly.
+// The yaml tags are the keys of formDefaults and template defaults.
 type JobSubmissionValues struct {
-	Name                string
-	Script              string
-	Partition           string
-	Account             string
-	QoS                 string
-	Nodes               int
-	CPUs                int
-	Memory              string
-	GPUs                int
-	TimeLimit           string
-	WorkingDir          string
-	OutputFile          string
-	ErrorFile           string
-	EmailNotify         bool
-	Email               string
-	ArraySpec           string
-	Exclusive           bool
-	Requeue             bool
-	Constraints         string
-	NTasks              int
-	NTasksPerNode       int
-	Gres                string
-	Hold                bool
-	Reservation         string
-	Licenses            string
-	Wckey               string
-	ExcludeNodes        string
-	Priority            int
-	Nice                int
-	MemoryPerCPU        string
-	BeginTime           string
-	Comment             string
-	Distribution        string
-	Prefer              string
-	RequiredNodes       string
-	StandardInput       string
-	Container           string
-	ThreadsPerCore      int
-	TasksPerCore        int
-	TasksPerSocket      int
-	SocketsPerNode      int
-	MaximumNodes        int
-	MaximumCPUs         int
-	MinimumCPUsPerNode  int
-	TimeMinimum         string
-	Contiguous          bool
-	Overcommit          bool
-	KillOnNodeFail      bool
-	WaitAllNodes        bool
-	OpenMode            string
-	TRESPerTask         string
-	TRESPerSocket       string
-	Signal              string
-	TmpDiskPerNode      int
-	Deadline            string
-	NTasksPerTRES       int
-	CPUBinding          string
-	CPUFrequency        string
-	Network             string
-	X11                 string
-	Immediate           bool
-	BurstBuffer         string
-	BatchFeatures       string
-	TRESBind            string
-	TRESFreq            string
-	CoreSpecification   int
-	ThreadSpecification int
-	MemoryBinding       string
-	MinimumCPUs         int
-	TRESPerJob          string
-	CPUsPerTRES         string
-	MemoryPerTRES       string
-	Argv                string
-	Flags               string
-	ProfileTypes        string
-	CPUBindingFlags     string
-	MemoryBindingType   string
-	RequiredSwitches    int
-	WaitForSwitch       int
-	ClusterConstraint   string
-	Clusters            string
-	Dependencies        []string
+	Name                string   `yaml:"name"`
+	Script              string   `yaml:"script"`
+	Partition           string   `yaml:"partition"`
+	Account             string   `yaml:"account"`
+	QoS                 string   `yaml:"qos"`
+	Nodes               int      `yaml:"nodes"`
+	CPUs                int      `yaml:"cpus"`
+	Memory              string   `yaml:"memory"`
+	GPUs                int      `yaml:"gpus"`
+	TimeLimit           string   `yaml:"timeLimit"`
+	WorkingDir          string   `yaml:"workingDir"`
+	OutputFile          string   `yaml:"outputFile"`
+	ErrorFile           string   `yaml:"errorFile"`
+	EmailNotify         bool     `yaml:"emailNotify"`
+	Email               string   `yaml:"email"`
+	ArraySpec           string   `yaml:"arraySpec"`
+	Exclusive           bool     `yaml:"exclusive"`
+	Requeue             bool     `yaml:"requeue"`
+	Constraints         string   `yaml:"constraints"`
+	NTasks              int      `yaml:"ntasks"`
+	NTasksPerNode       int      `yaml:"ntasksPerNode"`
+	Gres                string   `yaml:"gres"`
+	Hold                bool     `yaml:"hold"`
+	Reservation         string   `yaml:"reservation"`
+	Licenses            string   `yaml:"licenses"`
+	Wckey               string   `yaml:"wckey"`
+	ExcludeNodes        string   `yaml:"excludeNodes"`
+	Priority            int      `yaml:"priority"`
+	Nice                int      `yaml:"nice"`
+	MemoryPerCPU        string   `yaml:"memoryPerCPU"`
+	BeginTime           string   `yaml:"beginTime"`
+	Comment             string   `yaml:"comment"`
+	Distribution        string   `yaml:"distribution"`
+	Prefer              string   `yaml:"prefer"`
+	RequiredNodes       string   `yaml:"requiredNodes"`
+	StandardInput       string   `yaml:"standardInput"`
+	Container           string   `yaml:"container"`
+	ThreadsPerCore      int      `yaml:"threadsPerCore"`
+	TasksPerCore        int      `yaml:"tasksPerCore"`
+	TasksPerSocket      int      `yaml:"tasksPerSocket"`
+	SocketsPerNode      int      `yaml:"socketsPerNode"`
+	MaximumNodes        int      `yaml:"maximumNodes"`
+	MaximumCPUs         int      `yaml:"maximumCPUs"`
+	MinimumCPUsPerNode  int      `yaml:"minimumCPUsPerNode"`
+	TimeMinimum         string   `yaml:"timeMinimum"`
+	Contiguous          bool     `yaml:"contiguous"`
+	Overcommit          bool     `yaml:"overcommit"`
+	KillOnNodeFail      bool     `yaml:"killOnNodeFail"`
+	WaitAllNodes        bool     `yaml:"waitAllNodes"`
+	OpenMode            string   `yaml:"openMode"`
+	TRESPerTask         string   `yaml:"tresPerTask"`
+	TRESPerSocket       string   `yaml:"tresPerSocket"`
+	Signal              string   `yaml:"signal"`
+	TmpDiskPerNode      int      `yaml:"tmpDiskPerNode"`
+	Deadline            string   `yaml:"deadline"`
+	NTasksPerTRES       int      `yaml:"ntasksPerTRES"`
+	CPUBinding          string   `yaml:"cpuBinding"`
+	CPUFrequency        string   `yaml:"cpuFrequency"`
+	Network             string   `yaml:"network"`
+	X11                 string   `yaml:"x11"`
+	Immediate           bool     `yaml:"immediate"`
+	BurstBuffer         string   `yaml:"burstBuffer"`
+	BatchFeatures       string   `yaml:"batchFeatures"`
+	TRESBind            string   `yaml:"tresBind"`
+	TRESFreq            string   `yaml:"tresFreq"`
+	CoreSpecification   int      `yaml:"coreSpecification"`
+	ThreadSpecification int      `yaml:"threadSpecification"`
+	MemoryBinding       string   `yaml:"memoryBinding"`
+	MinimumCPUs         int      `yaml:"minimumCPUs"`
+	TRESPerJob          string   `yaml:"tresPerJob"`
+	CPUsPerTRES         string   `yaml:"cpusPerTRES"`
+	MemoryPerTRES       string   `yaml:"memoryPerTRES"`
+	Argv                string   `yaml:"argv"`
+	Flags               string   `yaml:"flags"`
+	ProfileTypes        string   `yaml:"profile"`
+	CPUBindingFlags     string   `yaml:"cpuBindingFlags"`
+	MemoryBindingType   string   `yaml:"memoryBindingType"`
+	RequiredSwitches    int      `yaml:"requiredSwitches"`
+	WaitForSwitch       int      `yaml:"waitForSwitch"`
+	ClusterConstraint   string   `yaml:"clusterConstraint"`
+	Clusters            string   `yaml:"clusters"`
+	Dependencies        []string `yaml:"dependencies"`
 }
 
 // JobSubmissionFromMap converts a map of config keys to JobSubmissionValues.
@@ -366,7 +370,8 @@ func JobSubmissionFromMap(m map[string]any) JobSubmissionValues {
 // ResolveTemplateSources returns the effective template sources.
 // If TemplateSources is explicitly set, use it directly.
 // Otherwise, derive from ShowBuiltinTemplates for backward compatibility.
-// Valid sources: "builtin", "config", "saved"
+// Valid sources: "builtin", "config", "saved" and template catalog
+// directories (see IsTemplateDir)
 // Default (no config): all three sources.
 func ResolveTemplateSources(cfg *JobSubmissionConfig) []string {
 	var raw []string
@@ -383,7 +388,7 @@ func ResolveTemplateSources(cfg *JobSubmissionConfig) []string {
 	valid := map[string]bool{"builtin": true, "config": true, "saved": true}
 	var filtered []string
 	for _, s := range raw {
-		if valid[s] {
+		if valid[s] || IsTemplateDir(s) {
 			filtered = append(filtered, s)
 		}
 	}
@@ -403,6 +408,15 @@ func HasTemplateSource(sources []string, source string) bool {
 	return false
 }
 
+// IsTemplateDir reports whether a template source names a directory of
+// template files, such as a git checkout of a shared template catalog.
+// Directories are given as paths: absolute, relative ("./templates") or
+// under the home directory ("~/catalog").
+func IsTemplateDir(source string) bool {
+	return strings.HasPrefix(source, ".") || strings.HasPrefix(source, "~") ||
+		strings.ContainsAny(source, `/\`)
+}
+
 // toInt converts a value to int, handling both int and float64 types
 // that may result from YAML/JSON unmarshaling.
 func toInt(v any) int {
@@ -415,3 +429,14 @@ func toInt(v any) int {
 		return 0
 	}
 }
+
+// submissionFieldKeys returns the keys of the job submission form fields,
+// as used in formDefaults, template defaults and hiddenFields
+func submissionFieldKeys() []string {
+	t := reflect.TypeOf(JobSubmissionValues{})
+	keys := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		keys = append(keys, yamlName(t.Field(i)))
+	}
+	return keys
+}
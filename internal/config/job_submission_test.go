@@ -1,6 +1,7 @@
 package config
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -166,6 +167,14 @@ func TestResolveTemplateSources_InvalidFiltered(t *testing.T) {
 	assert.Equal(t, []string{"builtin"}, sources)
 }
 
+func TestResolveTemplateSources_Directories(t *testing.T) {
+	cfg := &JobSubmissionConfig{
+		TemplateSources: []string{"builtin", "/srv/templates", "~/catalog", "./templates", "catalog"},
+	}
+	sources := ResolveTemplateSources(cfg)
+	assert.Equal(t, []string{"builtin", "/srv/templates", "~/catalog", "./templates"}, sources)
+}
+
 func TestResolveTemplateSources_AllInvalidFallsBack(t *testing.T) {
 	cfg := &JobSubmissionConfig{
 		TemplateSources: []string{"typo", "wrong"},
@@ -180,3 +189,24 @@ func TestHasTemplateSource(t *testing.T) {
 	assert.True(t, HasTemplateSource(sources, "config"))
 	assert.False(t, HasTemplateSource(sources, "saved"))
 }
+
+func TestSubmissionFieldKeysAreRead(t *testing.T) {
+	// Every documented key must reach its field
+	for i, key := range submissionFieldKeys() {
+		field := reflect.TypeOf(JobSubmissionValues{}).Field(i)
+		var value any
+		switch field.Type.Kind() {
+		case reflect.String:
+			value = "x"
+		case reflect.Int:
+			value = 1
+		case reflect.Bool:
+			value = true
+		case reflect.Slice:
+			value = []any{"x"}
+		}
+
+		js := JobSubmissionFromMap(map[string]any{key: value})
+		assert.False(t, reflect.ValueOf(js).Field(i).IsZero(), "%s is not read into %s", key, field.Name)
+	}
+}
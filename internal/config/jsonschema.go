@@ -0,0 +1,213 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// SchemaURL is where the JSON Schema of the config file is published
+const SchemaURL = "https://raw.githubusercontent.com/jontk/s9s/main/docs/reference/config.schema.json"
+
+// SchemaHeader is the first line of generated config files. It points
+// editors using yaml-language-server at the JSON Schema.
+const SchemaHeader = "# yaml-language-server: $schema=" + SchemaURL + "\n"
+
+// durationPattern matches the durations time.ParseDuration accepts, and
+// the empty string
+const durationPattern = `^(0|([0-9]+(\.[0-9]*)?(ns|us|µs|ms|s|m|h))+)?$`
+
+// submissionValueSettings hold job submission form values keyed like the
+// fields of JobSubmissionValues
+var submissionValueSettings = map[string]bool{
+	"views.jobs.submission.formDefaults": true,
+	templateDefaultsSetting:              true,
+}
+
+// templateDefaultsSetting holds the defaults of templates, whose values
+// may be Go templates rendered from the template parameters
+const templateDefaultsSetting = "views.jobs.submission.templates.*.defaults"
+
+// JSONSchema returns the JSON Schema (draft 2020-12) of the config file.
+// The Config struct gives its structure and types, the schema fields the
+// titles, descriptions, enums, bounds and examples, and DefaultConfig the
+// defaults.
+func (cs *Schema) JSONSchema() map[string]any {
+	fields := make(map[string]*Field, len(cs.Fields))
+	for i := range cs.Fields {
+		fields[cs.Fields[i].Key] = &cs.Fields[i]
+	}
+
+	root := jsonSchemaType(fields, reflect.TypeOf(Config{}), "", reflect.ValueOf(*DefaultConfig()))
+	root["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	root["$id"] = SchemaURL
+	root["title"] = "s9s configuration"
+	root["description"] = "Configuration file of s9s, usually ~/.s9s/config.yaml"
+	return root
+}
+
+// MarshalJSONSchema returns the JSON Schema of the config file as indented
+// JSON, the way it is published
+func (cs *Schema) MarshalJSONSchema() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(cs.JSONSchema()); err != nil {
+		return nil, fmt.Errorf("encoding JSON schema: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// jsonSchemaType returns the JSON Schema of the setting at path, of type t
+// and with the default def, which is invalid inside lists and maps
+func jsonSchemaType(fields map[string]*Field, t reflect.Type, path string, def reflect.Value) map[string]any {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		if def.IsValid() {
+			if def.IsNil() {
+				def = reflect.Value{}
+			} else {
+				def = def.Elem()
+			}
+		}
+	}
+	if submissionValueSettings[path] {
+		t = reflect.TypeOf(JobSubmissionValues{})
+		def = reflect.Value{}
+	}
+
+	var schema map[string]any
+	switch t.Kind() {
+	case reflect.Struct:
+		properties := make(map[string]any, t.NumField())
+		for i := 0; i < t.NumField(); i++ {
+			name := yamlName(t.Field(i))
+			if name == "" {
+				continue
+			}
+			var fieldDef reflect.Value
+			if def.IsValid() {
+				fieldDef = def.Field(i)
+			}
+			properties[name] = jsonSchemaType(fields, t.Field(i).Type, joinKey(path, name), fieldDef)
+			if path == templateDefaultsSetting {
+				if prop := properties[name].(map[string]any); prop["type"] == "integer" || prop["type"] == "boolean" {
+					prop["type"] = []string{prop["type"].(string), "string"}
+				}
+			}
+		}
+		schema = map[string]any{"type": "object", "properties": properties, "additionalProperties": false}
+	case reflect.Slice:
+		schema = map[string]any{
+			"type":  "array",
+			"items": jsonSchemaType(fields, t.Elem(), joinKey(path, "*"), reflect.Value{}),
+		}
+	case reflect.Map:
+		schema = map[string]any{"type": "object"}
+		if t.Elem().Kind() != reflect.Interface {
+			schema["additionalProperties"] = jsonSchemaType(fields, t.Elem(), joinKey(path, "*"), reflect.Value{})
+		}
+	case reflect.Interface:
+		schema = map[string]any{}
+	case reflect.Bool:
+		schema = map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		schema = map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		schema = map[string]any{"type": "number"}
+	default:
+		schema = map[string]any{"type": "string"}
+	}
+
+	if field := fields[path]; field != nil {
+		describeJSONSchema(schema, field)
+	}
+	if _, ok := schema["default"]; !ok && hasJSONDefault(t, def) {
+		schema["default"] = def.Interface()
+	}
+	return schema
+}
+
+// describeJSONSchema adds the metadata of a schema field to its JSON Schema
+func describeJSONSchema(schema map[string]any, field *Field) {
+	if field.Label != "" {
+		schema["title"] = field.Label
+	}
+	description := field.Description
+	for _, dep := range field.Depends {
+		description += fmt.Sprintf("; only used when %s is %v", dep.Field, dep.Value)
+	}
+	if description != "" {
+		schema["description"] = description
+	}
+
+	if len(field.Options) > 0 {
+		if items, ok := schema["items"].(map[string]any); ok {
+			items["enum"] = field.Options
+		} else {
+			schema["enum"] = field.Options
+		}
+	}
+	if field.Min != nil {
+		schema["minimum"] = *field.Min
+	}
+	if field.Max != nil {
+		schema["maximum"] = *field.Max
+	}
+	switch {
+	case field.Pattern != "":
+		schema["pattern"] = field.Pattern
+	case field.Type == FieldTypeDuration:
+		schema["pattern"] = durationPattern
+	}
+	if field.Default != nil {
+		schema["default"] = field.Default
+	}
+	if len(field.Examples) > 0 {
+		schema["examples"] = field.Examples
+	}
+}
+
+// hasJSONDefault reports whether def is a default worth publishing: set,
+// and not a section or a list of sections, whose settings have their own
+func hasJSONDefault(t reflect.Type, def reflect.Value) bool {
+	if !def.IsValid() || def.IsZero() {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		return false
+	case reflect.Slice, reflect.Map:
+		elem := t.Elem()
+		if elem.Kind() == reflect.Ptr {
+			elem = elem.Elem()
+		}
+		return elem.Kind() != reflect.Struct && def.Len() > 0
+	}
+	return true
+}
+
+// withSchemaHeader returns data, a config file being written, starting
+// with the yaml-language-server modeline of existing, the file it
+// replaces, or with SchemaHeader if existing has none
+func withSchemaHeader(data, existing []byte) []byte {
+	header := SchemaHeader
+	for _, line := range strings.Split(string(existing), "\n") {
+		if strings.HasPrefix(line, "# yaml-language-server:") {
+			header = line + "\n"
+			break
+		}
+	}
+	return append([]byte(header), data...)
+}
+
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
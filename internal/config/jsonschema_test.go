@@ -0,0 +1,146 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// schemaFile is the published JSON Schema of the config file
+const schemaFile = "../../docs/reference/config.schema.json"
+
+func TestSchemaDescribesEverySetting(t *testing.T) {
+	schema := GetConfigSchema()
+	var walk func(t reflect.Type, prefix string)
+	var missing []string
+	walk = func(typ reflect.Type, prefix string) {
+		for i := 0; i < typ.NumField(); i++ {
+			name := yamlName(typ.Field(i))
+			if name == "" {
+				continue
+			}
+			key := joinKey(prefix, name)
+			if field := schema.GetFieldByKey(key); field == nil || field.Description == "" {
+				missing = append(missing, key)
+			}
+
+			ft := typ.Field(i).Type
+			for ft.Kind() == reflect.Ptr || ft.Kind() == reflect.Slice {
+				if ft.Kind() == reflect.Slice {
+					key += ".*"
+				}
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				walk(ft, key)
+			}
+		}
+	}
+	walk(reflect.TypeOf(Config{}), "")
+	assert.Empty(t, missing, "add these settings to the schema fields")
+}
+
+func TestDefaultConfigPassesSchema(t *testing.T) {
+	for key, result := range GetConfigSchema().ValidateConfig(DefaultConfig()) {
+		assert.True(t, result.Valid, "%s: %v", key, result.Errors)
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	root := GetConfigSchema().JSONSchema()
+	assert.Equal(t, "https://json-schema.org/draft/2020-12/schema", root["$schema"])
+
+	prop := func(path ...string) map[string]any {
+		t.Helper()
+		node := root
+		for _, key := range path {
+			if key == "*" {
+				node = node["items"].(map[string]any)
+				continue
+			}
+			next, ok := node["properties"].(map[string]any)[key]
+			require.True(t, ok, "no property %s", key)
+			node = next.(map[string]any)
+		}
+		return node
+	}
+
+	refresh := prop("refreshRate")
+	assert.Equal(t, "string", refresh["type"])
+	assert.Equal(t, "10s", refresh["default"])
+	assert.Equal(t, "How often to refresh data from the cluster", refresh["description"])
+	assert.Regexp(t, refresh["pattern"], "1m30s")
+	assert.NotRegexp(t, refresh["pattern"], "soon")
+
+	assert.NotContains(t, root["properties"], "ConfigPath")
+	assert.NotContains(t, root["properties"], "Cluster")
+	assert.Equal(t, false, root["additionalProperties"])
+
+	endpoint := prop("clusters", "*", "cluster", "endpoint")
+	assert.Equal(t, "URL of slurmrestd", endpoint["description"])
+	assert.Equal(t, []string{"okta", "azure-ad", "google", "github", "custom"},
+		prop("clusters", "*", "cluster", "tokenFrom", "oauth2", "provider")["enum"])
+
+	assert.Equal(t, "integer", prop("views", "jobs", "maxJobs")["type"])
+	assert.InDelta(t, 10, prop("views", "jobs", "maxJobs")["minimum"], 0)
+	assert.Equal(t, []string{"auto", "racks", "file", "slurm", "prefix"}, prop("views", "topology", "source")["enum"])
+	sources := prop("views", "jobs", "submission", "templateSources")
+	assert.NotContains(t, sources["items"], "enum", "sources may be directories")
+	assert.Equal(t, []string{"builtin", "config", "saved"}, sources["default"])
+	assert.Equal(t, []string{"string", "int", "bool", "enum", "path", "duration"},
+		prop("views", "jobs", "submission", "templates", "*", "parameters", "*", "type")["enum"])
+
+	// Form values are typed like the submission form fields, and template
+	// defaults may also be Go templates
+	for _, tc := range []struct {
+		path    []string
+		numeric any
+	}{
+		{[]string{"views", "jobs", "submission", "formDefaults"}, "integer"},
+		{[]string{"views", "jobs", "submission", "templates", "*", "defaults"}, []string{"integer", "string"}},
+	} {
+		form := prop(tc.path...)
+		assert.Equal(t, false, form["additionalProperties"])
+		props := form["properties"].(map[string]any)
+		assert.Equal(t, "string", props["timeLimit"].(map[string]any)["type"])
+		assert.Equal(t, tc.numeric, props["nodes"].(map[string]any)["type"])
+		assert.Equal(t, "array", props["dependencies"].(map[string]any)["type"])
+	}
+	assert.Contains(t, prop("views", "jobs", "submission", "hiddenFields")["items"].(map[string]any)["enum"], "timeLimit")
+
+	aliases := prop("aliases")
+	assert.Equal(t, map[string]any{"type": "string"}, aliases["additionalProperties"])
+	assert.Equal(t, DefaultConfig().Aliases, aliases["default"])
+
+	assert.Contains(t, prop("discovery", "enableToken")["description"], "only used when discovery.enabled is true")
+}
+
+func TestJSONSchemaFileIsCurrent(t *testing.T) {
+	want, err := GetConfigSchema().MarshalJSONSchema()
+	require.NoError(t, err)
+	got, err := os.ReadFile(schemaFile)
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(got),
+		"regenerate it with: go run ./cmd/s9s config schema -o docs/reference/config.schema.json")
+}
+
+func TestSaveToFileWritesSchemaHeader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, DefaultConfig().SaveToFile(path))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.True(t, len(data) > len(SchemaHeader) && string(data[:len(SchemaHeader)]) == SchemaHeader)
+
+	// A modeline pointing elsewhere is kept
+	local := "# yaml-language-server: $schema=./config.schema.json\n"
+	require.NoError(t, os.WriteFile(path, []byte(local+"refreshRate: 5s\n"), 0o600))
+	require.NoError(t, DefaultConfig().SaveToFile(path))
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, local, string(data[:len(local)]))
+	assert.NotContains(t, string(data), SchemaURL)
+}
@@ -0,0 +1,690 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config layers, from the lowest precedence to the highest
+const (
+	LayerSystem  = "system"  // /etc/s9s/config.yaml, shipped by the site admins
+	LayerSite    = "site"    // The file named by S9S_SITE_CONFIG, e.g. set by an environment module
+	LayerUser    = "user"    // ~/.s9s/config.yaml or --config
+	LayerProject = "project" // .s9s.yaml in the working directory or a parent
+)
+
+// Origins of settings that no layer set
+const (
+	OriginDefault = "default"
+	OriginEnv     = "env"
+)
+
+// Layer file locations, variables so that tests can move them
+var (
+	systemConfigPath  = "/etc/s9s/config.yaml"
+	siteConfigEnv     = "S9S_SITE_CONFIG"
+	projectConfigName = ".s9s.yaml"
+)
+
+// projectSettings are the settings a project file may set. Settings that
+// run commands or reach clusters are left to the files the user controls,
+// so that a checked-out repository cannot change them.
+var projectSettings = []string{
+	"defaultCluster",
+	"views.jobs.submission",
+}
+
+// Layer is a config file merged into the configuration
+type Layer struct {
+	Name    string   // LayerSystem, LayerSite, LayerUser or LayerProject
+	Path    string   // Absolute path of the file
+	Ignored []string // Settings dropped from the file: unknown keys, or not allowed in a project file
+}
+
+// findLayers returns the config files that exist, in merge order. An
+// explicit user file must exist; the default one may be missing.
+func findLayers(userPath string) ([]Layer, error) {
+	var layers []Layer
+	add := func(name, path string, required bool) error {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return fmt.Errorf("resolving %s config path: %w", name, err)
+		}
+		if _, err := os.Stat(abs); err != nil {
+			if required || !os.IsNotExist(err) {
+				return fmt.Errorf("reading config: %w", err)
+			}
+			return nil
+		}
+		for _, layer := range layers {
+			if layer.Path == abs {
+				return nil
+			}
+		}
+		layers = append(layers, Layer{Name: name, Path: abs})
+		return nil
+	}
+
+	if err := add(LayerSystem, systemConfigPath, false); err != nil {
+		return nil, err
+	}
+	if site := os.Getenv(siteConfigEnv); site != "" {
+		if err := add(LayerSite, site, true); err != nil {
+			return nil, err
+		}
+	}
+	required := userPath != "" && userPath != defaultUserConfigPath()
+	if userPath == "" {
+		userPath = defaultUserConfigPath()
+	}
+	if err := add(LayerUser, userPath, required); err != nil {
+		return nil, err
+	}
+	if project := findProjectConfig(); project != "" {
+		if err := add(LayerProject, project, false); err != nil {
+			return nil, err
+		}
+	}
+	return layers, nil
+}
+
+// defaultUserConfigPath returns ~/.s9s/config.yaml
+func defaultUserConfigPath() string {
+	return filepath.Join(os.Getenv("HOME"), ".s9s", "config.yaml")
+}
+
+// findProjectConfig returns the nearest project file in the working
+// directory or its parents, or "" if there is none
+func findProjectConfig() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	for {
+		path := filepath.Join(dir, projectConfigName)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// readLayer parses a layer file into settings keyed like the config file,
+// dropping the keys it may not set into layer.Ignored
+func readLayer(layer *Layer) (map[string]any, error) {
+	data, err := os.ReadFile(layer.Path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s config: %w", layer.Name, err)
+	}
+	var raw any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parsing %s config %s: %w", layer.Name, layer.Path, err)
+	}
+	if raw == nil {
+		return map[string]any{}, nil
+	}
+	if _, ok := raw.(map[string]any); !ok {
+		return nil, fmt.Errorf("parsing %s config %s: not a mapping of settings", layer.Name, layer.Path)
+	}
+
+	layer.Ignored = nil
+	settings := canonicalSettings(raw, reflect.TypeOf(Config{}), "", &layer.Ignored).(map[string]any)
+	if layer.Name == LayerProject {
+		settings = allowedSettings(settings, "", projectSettings, &layer.Ignored)
+	}
+	sort.Strings(layer.Ignored)
+	return settings, nil
+}
+
+// canonicalSettings returns value, settings of type t at path, with the
+// keys of sections spelled as in the Config struct. Keys that are not
+// settings are dropped into unknown.
+func canonicalSettings(value any, t reflect.Type, path string, unknown *[]string) any {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || submissionValueSettings[schemaKey(path)] {
+		return value
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, child := range v {
+			switch t.Kind() {
+			case reflect.Struct:
+				i, ok := fieldIndex(t, key)
+				if !ok {
+					*unknown = append(*unknown, joinKey(path, key))
+					continue
+				}
+				name := yamlName(t.Field(i))
+				out[name] = canonicalSettings(child, t.Field(i).Type, joinKey(path, name), unknown)
+			case reflect.Map:
+				out[key] = canonicalSettings(child, t.Elem(), joinKey(path, key), unknown)
+			default:
+				out[key] = child
+			}
+		}
+		return out
+	case []any:
+		if t.Kind() != reflect.Slice {
+			return value
+		}
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = canonicalSettings(item, t.Elem(), joinKey(path, strconv.Itoa(i)), unknown)
+		}
+		return out
+	}
+	return value
+}
+
+// allowedSettings returns the settings under the allowed paths, dropping
+// the others into ignored
+func allowedSettings(settings map[string]any, prefix string, allowed []string, ignored *[]string) map[string]any {
+	out := make(map[string]any)
+	for key, value := range settings {
+		path := joinKey(prefix, key)
+		switch {
+		case containsString(allowed, path):
+			out[key] = value
+		case hasPathPrefix(allowed, path):
+			if section, ok := value.(map[string]any); ok {
+				if kept := allowedSettings(section, path, allowed, ignored); len(kept) > 0 {
+					out[key] = kept
+				}
+				continue
+			}
+			*ignored = append(*ignored, path)
+		default:
+			*ignored = append(*ignored, path)
+		}
+	}
+	return out
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func hasPathPrefix(paths []string, prefix string) bool {
+	for _, path := range paths {
+		if strings.HasPrefix(path, prefix+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeSettings merges src, the settings of a higher layer, onto dst and
+// returns the result with the origins of its settings. Sections are merged
+// key by key, and a null value unsets the setting. Lists of sections whose
+// entries have a name (or a key, for shortcuts) are merged entry by entry:
+// an entry replaces the settings it sets in the entry of the same name, and
+// new entries are appended. Other lists replace the list below them.
+func mergeSettings(dst, dstOrigins, src any, t reflect.Type, layer string) (any, any) {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch v := src.(type) {
+	case map[string]any:
+		base, ok := dst.(map[string]any)
+		if !ok {
+			return src, settingOrigins(src, t, layer)
+		}
+		baseOrigins, _ := dstOrigins.(map[string]any)
+		out := make(map[string]any, len(base)+len(v))
+		outOrigins := make(map[string]any, len(base)+len(v))
+		for key, value := range base {
+			out[key] = value
+			outOrigins[key] = baseOrigins[key]
+		}
+		for key, value := range v {
+			if value == nil {
+				delete(out, key)
+				delete(outOrigins, key)
+				continue
+			}
+			out[key], outOrigins[key] = mergeSettings(out[key], outOrigins[key], value, childType(t, key), layer)
+		}
+		return out, outOrigins
+	case []any:
+		base, ok := dst.([]any)
+		mergeKey := listMergeKey(t)
+		if !ok || mergeKey == "" {
+			return src, settingOrigins(src, t, layer)
+		}
+		baseOrigins, _ := dstOrigins.([]any)
+		out := append([]any(nil), base...)
+		outOrigins := make([]any, len(base))
+		copy(outOrigins, baseOrigins)
+		for _, item := range v {
+			i := entryIndex(out, item, mergeKey)
+			if i < 0 {
+				out = append(out, item)
+				outOrigins = append(outOrigins, settingOrigins(item, t.Elem(), layer))
+				continue
+			}
+			before := outOrigins[i]
+			out[i], outOrigins[i] = mergeSettings(out[i], outOrigins[i], item, t.Elem(), layer)
+
+			// The entry's name still comes from the layer that added it
+			if from, ok := before.(map[string]any); ok {
+				if to, ok := outOrigins[i].(map[string]any); ok && from[mergeKey] != nil {
+					to[mergeKey] = from[mergeKey]
+				}
+			}
+		}
+		return out, outOrigins
+	}
+	return src, layer
+}
+
+// settingOrigins returns the origins of value, set by one layer, shaped
+// like the settings: sections and lists of sections hold the origins of
+// their settings, and every other setting is a single origin
+func settingOrigins(value any, t reflect.Type, layer string) any {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch v := value.(type) {
+	case map[string]any:
+		if len(v) == 0 {
+			return layer
+		}
+		origins := make(map[string]any, len(v))
+		for key, child := range v {
+			origins[key] = settingOrigins(child, childType(t, key), layer)
+		}
+		return origins
+	case []any:
+		if len(v) == 0 || t == nil || t.Kind() != reflect.Slice || elemKind(t) != reflect.Struct {
+			return layer
+		}
+		origins := make([]any, len(v))
+		for i, item := range v {
+			origins[i] = settingOrigins(item, t.Elem(), layer)
+		}
+		return origins
+	}
+	return layer
+}
+
+// childType returns the type of the setting key of a section of type t,
+// or nil if it is not known
+func childType(t reflect.Type, key string) reflect.Type {
+	if t == nil {
+		return nil
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		if i, ok := fieldIndex(t, key); ok {
+			return t.Field(i).Type
+		}
+	case reflect.Map:
+		return t.Elem()
+	}
+	return nil
+}
+
+func elemKind(t reflect.Type) reflect.Kind {
+	elem := t.Elem()
+	for elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	return elem.Kind()
+}
+
+// listMergeKey returns the setting that identifies the entries of a list
+// of type t, or "" if the list is replaced as a whole
+func listMergeKey(t reflect.Type) string {
+	if t == nil || t.Kind() != reflect.Slice || elemKind(t) != reflect.Struct {
+		return ""
+	}
+	elem := t.Elem()
+	for elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	for _, key := range []string{"name", "key"} {
+		if i, ok := fieldIndex(elem, key); ok && yamlName(elem.Field(i)) == key {
+			return key
+		}
+	}
+	return ""
+}
+
+// entryIndex returns the index of the entry of list with the same value of
+// key as item, or -1. Entries without one are never matched.
+func entryIndex(list []any, item any, key string) int {
+	entry, ok := item.(map[string]any)
+	if !ok {
+		return -1
+	}
+	id, ok := entry[key].(string)
+	if !ok || id == "" {
+		return -1
+	}
+	for i, other := range list {
+		if section, ok := other.(map[string]any); ok && section[key] == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// schemaKey returns the schema key of a setting path, with list indexes
+// replaced by *
+func schemaKey(path string) string {
+	keys := strings.Split(path, ".")
+	for i, key := range keys {
+		if _, err := strconv.Atoi(key); err == nil {
+			keys[i] = "*"
+		}
+	}
+	return strings.Join(keys, ".")
+}
+
+// loadLayers merges the settings of layers in order. It returns the merged
+// settings, their origins, and the settings of the user layer, which
+// higher layers may override, all keyed by setting path.
+func loadLayers(layers []Layer) (map[string]any, map[string]string, map[string]any, error) {
+	t := reflect.TypeOf(Config{})
+	var merged, origins any = map[string]any{}, map[string]any{}
+	own := make(map[string]any)
+	for i := range layers {
+		settings, err := readLayer(&layers[i])
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		merged, origins = mergeSettings(merged, origins, settings, t, layers[i].Name)
+
+		// Entries of merged lists keep their index, so the paths of the
+		// user's settings stay valid after higher layers are merged
+		if layers[i].Name == LayerUser {
+			values := make(map[string]any)
+			flatten("", merged, values)
+			byPath := flattenOrigins(origins)
+			for path, value := range values {
+				if path = strings.ToLower(path); byPath[path] == LayerUser {
+					own[path] = value
+				}
+			}
+		}
+	}
+	return merged.(map[string]any), flattenOrigins(origins), own, nil
+}
+
+// flattenOrigins returns the origins of settings by lower-case path, as
+// viper lower-cases the keys of maps such as aliases
+func flattenOrigins(origins any) map[string]string {
+	flat := make(map[string]any)
+	flatten("", origins, flat)
+	byPath := make(map[string]string, len(flat))
+	for path, origin := range flat {
+		if name, ok := origin.(string); ok {
+			byPath[strings.ToLower(path)] = name
+		}
+	}
+	return byPath
+}
+
+// recordOrigins stores where the settings of c come from: the layers, the
+// environment, or the defaults. It also keeps the loaded values and the
+// user's own, so that saving can tell inherited settings from changed ones.
+func (c *Config) recordOrigins(layers map[string]string, own map[string]any) error {
+	flat, err := flattenConfig(c)
+	if err != nil {
+		return err
+	}
+	c.loaded = flat
+	c.own = own
+	c.origins = make(map[string]string, len(layers))
+	for path, origin := range layers {
+		c.origins[path] = origin
+	}
+
+	for path := range flat {
+		if strings.ContainsAny(schemaKey(path), "*") {
+			continue
+		}
+		env := "S9S_" + strings.ToUpper(strings.ReplaceAll(path, ".", "_"))
+		if _, ok := os.LookupEnv(env); ok {
+			c.origins[strings.ToLower(path)] = OriginEnv + ":" + env
+		}
+	}
+	if os.Getenv("S9S_SLURM_REST_URL") != "" || os.Getenv("SLURM_REST_URL") != "" {
+		c.origins["defaultcluster"] = OriginEnv
+		for i, entry := range c.Clusters {
+			if entry.Name == "default" {
+				prefix := "clusters." + strconv.Itoa(i)
+				for path := range c.origins {
+					if strings.HasPrefix(path, prefix+".") {
+						delete(c.origins, path)
+					}
+				}
+				c.origins[prefix] = OriginEnv
+			}
+		}
+	}
+	return nil
+}
+
+// Origin returns where the setting at path comes from: the name of the
+// layer that set it, "env" or "env:VARIABLE" for environment variables, or
+// "default"
+func (c *Config) Origin(path string) string {
+	path = strings.ToLower(strings.Join(splitPath(path), "."))
+	for {
+		if origin, ok := c.origins[path]; ok {
+			return origin
+		}
+		i := strings.LastIndex(path, ".")
+		if i < 0 {
+			return OriginDefault
+		}
+		path = path[:i]
+	}
+}
+
+// Setting is one setting of the effective configuration
+type Setting struct {
+	Path   string
+	Value  any
+	Origin string
+}
+
+// Settings returns the settings of c sorted by path, with their origins.
+// Sections and lists of sections are listed setting by setting.
+func (c *Config) Settings() ([]Setting, error) {
+	flat, err := flattenConfig(c)
+	if err != nil {
+		return nil, err
+	}
+	settings := make([]Setting, 0, len(flat))
+	for path, value := range flat {
+		settings = append(settings, Setting{Path: path, Value: value, Origin: c.Origin(path)})
+	}
+	sort.Slice(settings, func(i, j int) bool { return settings[i].Path < settings[j].Path })
+	return settings, nil
+}
+
+// Files returns the config files c was loaded from and the user config
+// file, which may not exist yet: the files whose changes reload it
+func (c *Config) Files() []string {
+	if c.ConfigPath == "" {
+		return nil
+	}
+	files := []string{c.ConfigPath}
+	for _, layer := range c.Layers {
+		if !containsString(files, layer.Path) {
+			files = append(files, layer.Path)
+		}
+	}
+	return files
+}
+
+// inherited reports whether the setting at path holds the value another
+// layer or the environment set when c was loaded. Such settings do not
+// belong in the user file. In an entry another layer added, unchanged
+// defaults do not either.
+func (c *Config) inherited(path string, value any, inEntry bool) bool {
+	switch c.Origin(path) {
+	case LayerUser:
+		return false
+	case OriginDefault:
+		if !inEntry {
+			return false
+		}
+	}
+	loaded, ok := c.loaded[path]
+	return ok && reflect.DeepEqual(loaded, value)
+}
+
+// withoutInherited returns settings, the config file keys of c, without the
+// settings c inherited from other layers or the environment, except where
+// they override settings of the user file. Entries of
+// merged lists keep their name when any of their settings remain.
+func (c *Config) withoutInherited(settings map[string]any) map[string]any {
+	if len(c.loaded) == 0 {
+		return settings
+	}
+	pruned, _ := c.prune(settings, "", reflect.TypeOf(Config{}), false).(map[string]any)
+	if pruned == nil {
+		pruned = map[string]any{}
+	}
+	return pruned
+}
+
+// prune removes the inherited settings of value, at path and of type t,
+// or restores the user's value of those the user file sets. inEntry tells
+// whether value is in a list entry added by another layer. It returns nil
+// if nothing remains.
+func (c *Config) prune(value any, path string, t reflect.Type, inEntry bool) any {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch v := value.(type) {
+	case map[string]any:
+		if len(v) == 0 && path != "" {
+			break
+		}
+		out := make(map[string]any, len(v))
+		for key, child := range v {
+			if kept := c.prune(child, joinKey(path, key), childType(t, key), inEntry); kept != nil {
+				out[key] = kept
+			}
+		}
+		if len(out) == 0 {
+			return nil
+		}
+		return out
+	case []any:
+		mergeKey := listMergeKey(t)
+		if mergeKey == "" || !isSectionList(v) {
+			break
+		}
+		var out []any
+		for i, item := range v {
+			entryPath := joinKey(path, strconv.Itoa(i))
+			origin := c.Origin(joinKey(entryPath, mergeKey))
+			added := origin != LayerUser && origin != OriginDefault
+			kept, _ := c.prune(item, entryPath, t.Elem(), added).(map[string]any)
+			if kept == nil {
+				continue
+			}
+			if id, ok := item.(map[string]any)[mergeKey]; ok {
+				kept[mergeKey] = id
+			}
+			out = append(out, kept)
+		}
+		if len(out) == 0 {
+			return nil
+		}
+		return out
+	}
+	if c.inherited(path, value, inEntry) {
+		// A setting overridden by a higher layer keeps the user's value
+		return c.own[strings.ToLower(path)]
+	}
+	return value
+}
+
+// isSectionList reports whether list is a non-empty list of sections,
+// which flatten lists setting by setting
+func isSectionList(list []any) bool {
+	if len(list) == 0 {
+		return false
+	}
+	for _, item := range list {
+		if _, ok := item.(map[string]any); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// LayerError is a problem with one config file
+type LayerError struct {
+	Layer   Layer
+	Field   string // Setting path, or "" for the whole file
+	Message string
+}
+
+// ValidateLayers checks each config file c was loaded from on its own: its
+// YAML, settings that are ignored, and values the configuration schema
+// rejects. Checks that need the merged configuration, such as whether the
+// default cluster exists, are left to ValidateAndFix.
+func (c *Config) ValidateLayers() []LayerError {
+	schema := GetConfigSchema()
+	var errs []LayerError
+	for _, layer := range c.Layers {
+		settings, err := readLayer(&layer)
+		if err != nil {
+			errs = append(errs, LayerError{Layer: layer, Message: err.Error()})
+			continue
+		}
+		for _, path := range layer.Ignored {
+			message := "unknown setting"
+			if _, _, _, err := settingType(splitPath(path)); err == nil {
+				message = "cannot be set in a project file"
+			}
+			errs = append(errs, LayerError{Layer: layer, Field: path, Message: message})
+		}
+
+		cfg, err := decodeSettings(settings, false)
+		if err != nil {
+			errs = append(errs, LayerError{Layer: layer, Message: err.Error()})
+			continue
+		}
+		results := schema.ValidateConfig(cfg)
+		keys := make([]string, 0, len(results))
+		for key, result := range results {
+			if !result.Valid {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			errs = append(errs, LayerError{Layer: layer, Field: key, Message: strings.Join(results[key].Errors, "; ")})
+		}
+	}
+	return errs
+}
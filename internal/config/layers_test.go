@@ -0,0 +1,279 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// layerPaths are the config files of a layered test setup
+type layerPaths struct {
+	system, site, user, project string
+}
+
+// setupLayers isolates the config layers from the host: the system file
+// moves into a temp dir, HOME too, and the working directory is a
+// subdirectory of the project
+func setupLayers(t *testing.T) layerPaths {
+	t.Helper()
+	dir := t.TempDir()
+	for _, env := range []string{"SLURM_REST_URL", "S9S_SLURM_REST_URL", "SLURM_JWT", "S9S_SLURM_JWT", "SLURM_API_VERSION", siteConfigEnv} {
+		t.Setenv(env, "")
+	}
+	t.Setenv("HOME", filepath.Join(dir, "home"))
+
+	old := systemConfigPath
+	systemConfigPath = filepath.Join(dir, "etc", "s9s", "config.yaml")
+	t.Cleanup(func() { systemConfigPath = old })
+
+	workDir := filepath.Join(dir, "project", "scripts")
+	require.NoError(t, os.MkdirAll(workDir, 0o750))
+	t.Chdir(workDir)
+
+	return layerPaths{
+		system:  systemConfigPath,
+		site:    filepath.Join(dir, "site", "s9s.yaml"),
+		user:    filepath.Join(dir, "home", ".s9s", "config.yaml"),
+		project: filepath.Join(dir, "project", projectConfigName),
+	}
+}
+
+func writeLayer(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
+
+const systemLayer = `
+refreshRate: 20s
+defaultCluster: prod
+clusters:
+  - name: prod
+    cluster:
+      endpoint: https://prod:6820
+  - name: dev
+    cluster:
+      endpoint: https://dev:6820
+views:
+  jobs:
+    columns: [id, name, state]
+    submission:
+      templates:
+        - name: gpu
+          description: GPU job
+          defaults:
+            partition: gpu
+            gpus: 1
+safety:
+  protect:
+    - name: login
+      nodes: login[01-02]
+`
+
+func TestLoadMergesLayers(t *testing.T) {
+	paths := setupLayers(t)
+	writeLayer(t, paths.system, systemLayer)
+	writeLayer(t, paths.site, "views:\n  jobs:\n    maxJobs: 300\n")
+	t.Setenv(siteConfigEnv, paths.site)
+	writeLayer(t, paths.user, `
+refreshrate: 5s
+clusters:
+  - name: dev
+    readOnly: true
+views:
+  jobs:
+    columns: [id, state]
+`)
+	writeLayer(t, paths.project, `
+defaultCluster: dev
+clusters:
+  - name: evil
+    cluster:
+      endpoint: https://evil:6820
+views:
+  jobs:
+    maxJobs: 50
+    submission:
+      formDefaults:
+        account: proj42
+      templates:
+        - name: gpu
+          defaults:
+            gpus: 2
+        - name: train
+          description: Training run
+`)
+
+	cfg, err := LoadWithPath("")
+	require.NoError(t, err)
+
+	require.Len(t, cfg.Layers, 4)
+	for i, name := range []string{LayerSystem, LayerSite, LayerUser, LayerProject} {
+		assert.Equal(t, name, cfg.Layers[i].Name)
+	}
+	assert.Equal(t, paths.user, cfg.ConfigPath)
+	assert.Equal(t, []string{"clusters", "views.jobs.maxJobs"}, cfg.Layers[3].Ignored)
+
+	// Sections merge key by key, and scalar lists are replaced
+	assert.Equal(t, "5s", cfg.RefreshRate)
+	assert.Equal(t, 300, cfg.Views.Jobs.MaxJobs)
+	assert.Equal(t, []string{"id", "state"}, cfg.Views.Jobs.Columns)
+
+	// Named entries merge entry by entry, new ones are appended
+	require.Len(t, cfg.Clusters, 2)
+	assert.Equal(t, "https://dev:6820", cfg.Clusters[1].Cluster.Endpoint)
+	assert.True(t, cfg.Clusters[1].ReadOnly)
+	assert.Equal(t, "dev", cfg.DefaultCluster)
+	assert.Equal(t, "https://dev:6820", cfg.Cluster.Endpoint)
+
+	templates := cfg.Views.Jobs.Submission.Templates
+	require.Len(t, templates, 2)
+	assert.Equal(t, "GPU job", templates[0].Description)
+	assert.Equal(t, map[string]any{"partition": "gpu", "gpus": 2}, templates[0].Defaults)
+	assert.Equal(t, "train", templates[1].Name)
+	assert.Equal(t, "proj42", cfg.Views.Jobs.Submission.FormDefaults["account"])
+	require.Len(t, cfg.Safety.Protect, 1)
+
+	for path, origin := range map[string]string{
+		"refreshRate":                                          LayerUser,
+		"views.jobs.maxJobs":                                   LayerSite,
+		"views.jobs.columns":                                   LayerUser,
+		"clusters.1.cluster.endpoint":                          LayerSystem,
+		"clusters[1].readOnly":                                 LayerUser,
+		"clusters.1.name":                                      LayerSystem,
+		"defaultCluster":                                       LayerProject,
+		"views.jobs.submission.templates.0.name":               LayerSystem,
+		"views.jobs.submission.templates.0.defaults.gpus":      LayerProject,
+		"views.jobs.submission.templates.0.defaults.partition": LayerSystem,
+		"views.jobs.submission.formDefaults.account":           LayerProject,
+		"safety.protect.0.nodes":                               LayerSystem,
+		"ui.skin":                                              OriginDefault,
+	} {
+		assert.Equal(t, origin, cfg.Origin(path), path)
+	}
+
+	settings, err := cfg.Settings()
+	require.NoError(t, err)
+	for _, setting := range settings {
+		if setting.Path == "refreshRate" {
+			assert.Equal(t, Setting{Path: "refreshRate", Value: "5s", Origin: LayerUser}, setting)
+		}
+	}
+}
+
+func TestLoadNullUnsetsSetting(t *testing.T) {
+	paths := setupLayers(t)
+	writeLayer(t, paths.system, systemLayer)
+	writeLayer(t, paths.user, "views:\n  jobs:\n    columns: null\n")
+
+	cfg, err := LoadWithPath("")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultConfig().Views.Jobs.Columns, cfg.Views.Jobs.Columns)
+	assert.Equal(t, OriginDefault, cfg.Origin("views.jobs.columns"))
+}
+
+func TestLoadLayerEnvironmentOrigin(t *testing.T) {
+	paths := setupLayers(t)
+	writeLayer(t, paths.user, "refreshRate: 5s\n")
+	t.Setenv("S9S_REFRESHRATE", "7s")
+
+	cfg, err := LoadWithPath("")
+	require.NoError(t, err)
+	assert.Equal(t, "7s", cfg.RefreshRate)
+	assert.Equal(t, "env:S9S_REFRESHRATE", cfg.Origin("refreshRate"))
+}
+
+func TestLoadLayerErrors(t *testing.T) {
+	paths := setupLayers(t)
+
+	// A missing site file named by the environment is an error
+	t.Setenv(siteConfigEnv, paths.site)
+	_, err := LoadWithPath("")
+	assert.Error(t, err)
+
+	writeLayer(t, paths.site, "refreshRate: [\n")
+	_, err = LoadWithPath("")
+	assert.ErrorContains(t, err, "parsing site config")
+}
+
+func TestValidateLayers(t *testing.T) {
+	paths := setupLayers(t)
+	writeLayer(t, paths.system, systemLayer)
+	writeLayer(t, paths.user, "refreshRate: 5s\nbogus: true\nviews:\n  jobs:\n    maxJobs: 5\n")
+	writeLayer(t, paths.project, "defaultCluster: dev\nrefreshRate: 1s\n")
+
+	cfg, err := LoadWithPath("")
+	require.NoError(t, err)
+
+	var got []string
+	for _, lerr := range cfg.ValidateLayers() {
+		got = append(got, lerr.Layer.Name+" "+lerr.Field+": "+lerr.Message)
+	}
+	assert.Equal(t, []string{
+		"user bogus: unknown setting",
+		"user views.jobs.maxJobs: Max Jobs to Display must be at least 10",
+		"project refreshRate: cannot be set in a project file",
+	}, got)
+}
+
+func TestSaveToFileLeavesOtherLayersOut(t *testing.T) {
+	paths := setupLayers(t)
+	writeLayer(t, paths.system, systemLayer)
+	writeLayer(t, paths.user, `
+maxRetries: 4
+views:
+  jobs:
+    maxJobs: 200
+    submission:
+      formDefaults:
+        account: mine
+`)
+	writeLayer(t, paths.project, "views:\n  jobs:\n    submission:\n      formDefaults:\n        account: proj42\n")
+
+	cfg, err := LoadWithPath("")
+	require.NoError(t, err)
+	cfg.MaxRetries = 5
+	cfg.Views.Jobs.Columns = []string{"id"}
+	require.NoError(t, cfg.SaveToFile(cfg.ConfigPath))
+
+	data, err := os.ReadFile(cfg.ConfigPath)
+	require.NoError(t, err)
+	var saved map[string]any
+	require.NoError(t, yaml.Unmarshal(data, &saved))
+
+	// Settings of the system and project files stay there, and the user's
+	// own value of a setting the project overrides is kept
+	assert.NotContains(t, saved, "clusters")
+	assert.NotContains(t, saved, "refreshRate")
+	assert.NotContains(t, saved, "defaultCluster")
+	assert.Equal(t, 5, saved["maxRetries"])
+	jobs := saved["views"].(map[string]any)["jobs"].(map[string]any)
+	assert.Equal(t, 200, jobs["maxJobs"])
+	assert.Equal(t, []any{"id"}, jobs["columns"])
+	assert.NotContains(t, jobs["submission"], "templates")
+	assert.Equal(t, map[string]any{"account": "mine"}, jobs["submission"].(map[string]any)["formDefaults"])
+
+	reloaded, err := LoadWithPath("")
+	require.NoError(t, err)
+	assert.Equal(t, "20s", reloaded.RefreshRate)
+	assert.Len(t, reloaded.Clusters, 2)
+	assert.Equal(t, "proj42", reloaded.Views.Jobs.Submission.FormDefaults["account"])
+}
+
+func TestMergeSettingsListWithoutNames(t *testing.T) {
+	var merged, origins any = map[string]any{}, map[string]any{}
+	protect := func(nodes string) map[string]any {
+		return map[string]any{"safety": map[string]any{"protect": []any{map[string]any{"nodes": nodes}}}}
+	}
+	merged, origins = mergeSettings(merged, origins, protect("login01"), reflect.TypeOf(Config{}), LayerSystem)
+	merged, _ = mergeSettings(merged, origins, protect("gpu01"), reflect.TypeOf(Config{}), LayerUser)
+
+	// Entries without a name cannot be matched, so they add up
+	rules := merged.(map[string]any)["safety"].(map[string]any)["protect"].([]any)
+	assert.Len(t, rules, 2)
+}
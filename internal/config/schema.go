@@ -21,7 +21,7 @@ type Field struct {
 	Min         *float64     `json:"min,omitempty"`      // For numeric types
 	Max         *float64     `json:"max,omitempty"`      // For numeric types
 	Pattern     string       `json:"pattern,omitempty"`  // For string validation
-	Group       string       `json:"group"`              // For UI grouping
+	Group       string       `json:"group"`              // For UI grouping; ungrouped fields are not shown
 	Order       int          `json:"order"`              // For UI ordering
 	Sensitive   bool         `json:"sensitive"`          // For password fields
 	Depends     []Dependency `json:"depends,omitempty"`  // Conditional fields
@@ -91,7 +91,7 @@ func GetConfigSchema() *Schema {
 			{ID: "general", Name: "General", Description: "Basic application settings", Icon: "⚙️", Order: 1},
 			{ID: "views", Name: "View Settings", Description: "Table views and display options", Icon: "📊", Order: 2},
 		},
-		Fields: getConfigFields(),
+		Fields: append(getConfigFields(), getFileOnlyFields()...),
 	}
 }
 
@@ -464,6 +464,14 @@ func (cs *Schema) validateStruct(structValue reflect.Value, prefix string, resul
 			continue
 		}
 
+		// Unset optional settings have nothing to validate
+		if field.Kind() == reflect.Ptr {
+			if field.IsNil() {
+				continue
+			}
+			field = field.Elem()
+		}
+
 		// Get the mapstructure tag or use field name
 		fieldName := fieldType.Tag.Get("mapstructure")
 		if fieldName == "" {
@@ -0,0 +1,1049 @@
+package config
+
+// getFileOnlyFields returns the fields that are only set in the config
+// file. They have no group, so the settings UI does not show them, but they
+// describe the rest of the config for validation and the JSON Schema.
+// Elements of lists and values of maps are keyed with "*", e.g.
+// "clusters.*.cluster.endpoint".
+func getFileOnlyFields() []Field {
+	fields := []Field{
+		{
+			Key:         "useMockClient",
+			Label:       "Use Mock Client",
+			Description: "Use a simulated cluster instead of slurmrestd; requires S9S_ENABLE_MOCK",
+			Type:        FieldTypeBool,
+		},
+	}
+	fields = append(fields, clusterFields()...)
+	fields = append(fields, uiFields()...)
+	fields = append(fields, viewFields()...)
+	fields = append(fields, submissionFields()...)
+	fields = append(fields, keyboardFields()...)
+	fields = append(fields, pluginFields()...)
+	fields = append(fields, serviceFields()...)
+	fields = append(fields, policyFields()...)
+	return fields
+}
+
+// clusterFields describes the cluster contexts and their token sources
+func clusterFields() []Field {
+	return []Field{
+		{
+			Key:         "clusters",
+			Label:       "Clusters",
+			Description: "Cluster contexts s9s can connect to; defaultCluster picks the active one",
+			Type:        FieldTypeArray,
+		},
+		{
+			Key:         "clusters.*.name",
+			Label:       "Cluster Name",
+			Description: "Name of the cluster context, used by defaultCluster, --cluster and :ctx",
+			Type:        FieldTypeString,
+			Required:    true,
+		},
+		{
+			Key:         "clusters.*.cluster",
+			Label:       "Connection",
+			Description: "Connection to the cluster's slurmrestd",
+			Type:        FieldTypeObject,
+		},
+		{
+			Key:         "clusters.*.cluster.endpoint",
+			Label:       "Endpoint",
+			Description: "URL of slurmrestd",
+			Type:        FieldTypeString,
+			Required:    true,
+			Examples:    []string{"https://slurm.example.com:6820"},
+		},
+		{
+			Key:         "clusters.*.cluster.token",
+			Label:       "Token",
+			Description: "JWT sent to slurmrestd; tokenFrom takes precedence",
+			Type:        FieldTypeString,
+			Sensitive:   true,
+		},
+		{
+			Key:         "clusters.*.cluster.tokenFrom",
+			Label:       "Token Source",
+			Description: "Where to fetch the token from; set exactly one of command, file, keyring, env or oauth2",
+			Type:        FieldTypeObject,
+		},
+		{
+			Key:         "clusters.*.cluster.tokenFrom.command",
+			Label:       "Token Command",
+			Description: "Command printing the token, run with sh",
+			Type:        FieldTypeString,
+			Examples:    []string{"scontrol token lifespan=3600"},
+		},
+		{
+			Key:         "clusters.*.cluster.tokenFrom.file",
+			Label:       "Token File",
+			Description: "File holding the token, re-read on every refresh",
+			Type:        FieldTypeString,
+		},
+		{
+			Key:         "clusters.*.cluster.tokenFrom.keyring",
+			Label:       "Keyring Key",
+			Description: "Key of the token in the system keyring, stored with s9s auth store",
+			Type:        FieldTypeString,
+		},
+		{
+			Key:         "clusters.*.cluster.tokenFrom.env",
+			Label:       "Token Variable",
+			Description: "Environment variable holding the token",
+			Type:        FieldTypeString,
+		},
+		{
+			Key:         "clusters.*.cluster.tokenFrom.oauth2",
+			Label:       "OAuth2 Login",
+			Description: "OAuth2/OIDC client used to log in through the browser",
+			Type:        FieldTypeObject,
+		},
+		{
+			Key:         "clusters.*.cluster.tokenFrom.oauth2.provider",
+			Label:       "Provider",
+			Description: "Identity provider; custom needs the authorization and token endpoints",
+			Type:        FieldTypeSelect,
+			Options:     []string{"okta", "azure-ad", "google", "github", "custom"},
+		},
+		{
+			Key:         "clusters.*.cluster.tokenFrom.oauth2.clientId",
+			Label:       "Client ID",
+			Description: "OAuth2 client ID",
+			Type:        FieldTypeString,
+		},
+		{
+			Key:         "clusters.*.cluster.tokenFrom.oauth2.clientSecret",
+			Label:       "Client Secret",
+			Description: "OAuth2 client secret, if the client has one",
+			Type:        FieldTypeString,
+			Sensitive:   true,
+		},
+		{
+			Key:         "clusters.*.cluster.tokenFrom.oauth2.discoveryUrl",
+			Label:       "Discovery URL",
+			Description: "OpenID Connect discovery document of the provider",
+			Type:        FieldTypeString,
+			Examples:    []string{"https://idp.example.com/.well-known/openid-configuration"},
+		},
+		{
+			Key:         "clusters.*.cluster.tokenFrom.oauth2.authorizationEndpoint",
+			Label:       "Authorization Endpoint",
+			Description: "Authorization endpoint, when there is no discovery URL",
+			Type:        FieldTypeString,
+		},
+		{
+			Key:         "clusters.*.cluster.tokenFrom.oauth2.tokenEndpoint",
+			Label:       "Token Endpoint",
+			Description: "Token endpoint, when there is no discovery URL",
+			Type:        FieldTypeString,
+		},
+		{
+			Key:         "clusters.*.cluster.tokenFrom.oauth2.redirectUri",
+			Label:       "Redirect URI",
+			Description: "Redirect URI registered for the client",
+			Type:        FieldTypeString,
+		},
+		{
+			Key:         "clusters.*.cluster.tokenFrom.oauth2.scopes",
+			Label:       "Scopes",
+			Description: "Space-separated scopes to request",
+			Type:        FieldTypeString,
+			Examples:    []string{"openid profile"},
+		},
+		{
+			Key:         "clusters.*.cluster.tokenFrom.refreshBefore",
+			Label:       "Refresh Before",
+			Description: "Fetch a new token this long before the current one expires (default: 5m)",
+			Type:        FieldTypeDuration,
+		},
+		{
+			Key:         "clusters.*.cluster.apiVersion",
+			Label:       "API Version",
+			Description: "slurmrestd API version; detected when empty",
+			Type:        FieldTypeString,
+			Examples:    []string{"v0.0.43"},
+		},
+		{
+			Key:         "clusters.*.cluster.insecure",
+			Label:       "Insecure",
+			Description: "Skip TLS certificate verification",
+			Type:        FieldTypeBool,
+		},
+		{
+			Key:         "clusters.*.cluster.timeout",
+			Label:       "Timeout",
+			Description: "Timeout of requests to slurmrestd",
+			Type:        FieldTypeDuration,
+			Examples:    []string{"30s"},
+		},
+		{
+			Key:         "clusters.*.cluster.user",
+			Label:       "SLURM User",
+			Description: "SLURM user name sent with the token (default: the OS user)",
+			Type:        FieldTypeString,
+		},
+		{
+			Key:         "clusters.*.namespace",
+			Label:       "Namespace",
+			Description: "Namespace for multi-tenant setups",
+			Type:        FieldTypeString,
+		},
+		{
+			Key:         "clusters.*.readOnly",
+			Label:       "Read Only",
+			Description: "Refuse every job, node and partition change on this cluster",
+			Type:        FieldTypeBool,
+		},
+		{
+			Key:         "discovery",
+			Label:       "Auto-Discovery",
+			Description: "Find the slurmrestd endpoint and token when no cluster is configured",
+			Type:        FieldTypeObject,
+		},
+		{
+			Key:         "discovery.enabled",
+			Label:       "Enable Discovery",
+			Description: "Discover slurmrestd from the local SLURM installation",
+			Type:        FieldTypeBool,
+		},
+		{
+			Key:         "discovery.enableEndpoint",
+			Label:       "Discover Endpoint",
+			Description: "Discover the slurmrestd endpoint",
+			Type:        FieldTypeBool,
+			Depends:     []Dependency{{Field: "discovery.enabled", Value: true}},
+		},
+		{
+			Key:         "discovery.enableToken",
+			Label:       "Discover Token",
+			Description: "Generate a token with scontrol token",
+			Type:        FieldTypeBool,
+			Depends:     []Dependency{{Field: "discovery.enabled", Value: true}},
+		},
+		{
+			Key:         "discovery.timeout",
+			Label:       "Discovery Timeout",
+			Description: "How long discovery may take",
+			Type:        FieldTypeDuration,
+		},
+		{
+			Key:         "discovery.defaultPort",
+			Label:       "Default Port",
+			Description: "slurmrestd port to try",
+			Type:        FieldTypeInt,
+			Min:         &[]float64{0}[0],
+			Max:         &[]float64{65535}[0],
+		},
+		{
+			Key:         "discovery.scontrolPath",
+			Label:       "scontrol Path",
+			Description: "Path of the scontrol binary",
+			Type:        FieldTypeString,
+		},
+	}
+}
+
+// uiFields describes the ui section
+func uiFields() []Field {
+	return []Field{
+		{
+			Key:         "ui",
+			Label:       "UI",
+			Description: "Appearance of the terminal UI",
+			Type:        FieldTypeObject,
+		},
+		{
+			Key:         "ui.skin",
+			Label:       "Skin",
+			Description: "Color skin",
+			Type:        FieldTypeString,
+		},
+		{
+			Key:         "ui.logoless",
+			Label:       "Hide Logo",
+			Description: "Hide the logo",
+			Type:        FieldTypeBool,
+		},
+		{
+			Key:         "ui.crumbsless",
+			Label:       "Hide Breadcrumbs",
+			Description: "Hide the breadcrumbs",
+			Type:        FieldTypeBool,
+		},
+		{
+			Key:         "ui.statusless",
+			Label:       "Hide Status Bar",
+			Description: "Hide the status bar",
+			Type:        FieldTypeBool,
+		},
+		{
+			Key:         "ui.headless",
+			Label:       "Hide Header",
+			Description: "Hide the header",
+			Type:        FieldTypeBool,
+		},
+		{
+			Key:         "ui.noIcons",
+			Label:       "No Icons",
+			Description: "Disable icons",
+			Type:        FieldTypeBool,
+		},
+		{
+			Key:         "ui.enableMouse",
+			Label:       "Enable Mouse",
+			Description: "Enable mouse support",
+			Type:        FieldTypeBool,
+		},
+	}
+}
+
+// viewFields describes the views section, except job submission
+func viewFields() []Field {
+	return []Field{
+		{
+			Key:         "views",
+			Label:       "Views",
+			Description: "Settings of the individual views",
+			Type:        FieldTypeObject,
+		},
+		{
+			Key:         "views.jobs",
+			Label:       "Jobs View",
+			Description: "Jobs view settings",
+			Type:        FieldTypeObject,
+		},
+		{
+			Key:         "views.jobs.progress",
+			Label:       "Job Progress",
+			Description: "Progress and ETA extracted from job output",
+			Type:        FieldTypeObject,
+		},
+		{
+			Key:         "views.jobs.progress.presets",
+			Label:       "Progress Presets",
+			Description: "Built-in extractors of progress from job output (default: all)",
+			Type:        FieldTypeArray,
+			Options:     []string{"epoch", "step", "tqdm", "percent", "fraction"},
+		},
+		{
+			Key:         "views.jobs.progress.patterns",
+			Label:       "Progress Patterns",
+			Description: "Custom progress regexes, checked before the presets",
+			Type:        FieldTypeArray,
+		},
+		{
+			Key:         "views.jobs.progress.patterns.*.name",
+			Label:       "Pattern Name",
+			Description: "Name of the extractor",
+			Type:        FieldTypeString,
+		},
+		{
+			Key:         "views.jobs.progress.patterns.*.pattern",
+			Label:       "Pattern",
+			Description: `Regex with a "percent" named group, or "current" and "total" named groups`,
+			Type:        FieldTypeString,
+			Examples:    []string{`t=(?P<current>[0-9.]+) / (?P<total>[0-9.]+)`},
+		},
+		{
+			Key:         "views.jobs.progress.sampleOutput",
+			Label:       "Sample Output",
+			Description: "Scan the output of running jobs for progress on refresh (default: true)",
+			Type:        FieldTypeBool,
+		},
+		{
+			Key:         "views.nodes",
+			Label:       "Nodes View",
+			Description: "Nodes view settings",
+			Type:        FieldTypeObject,
+		},
+		{
+			Key:         "views.nodes.showUtilization",
+			Label:       "Show Utilization",
+			Description: "Show utilization metrics",
+			Type:        FieldTypeBool,
+		},
+		{
+			Key:         "views.nodes.maxNodes",
+			Label:       "Max Nodes",
+			Description: "Maximum number of nodes to display",
+			Type:        FieldTypeInt,
+			Min:         &[]float64{0}[0],
+		},
+		{
+			Key:         "views.partitions",
+			Label:       "Partitions View",
+			Description: "Partitions view settings",
+			Type:        FieldTypeObject,
+		},
+		{
+			Key:         "views.partitions.showQueueDepth",
+			Label:       "Show Queue Depth",
+			Description: "Show queue depth information",
+			Type:        FieldTypeBool,
+		},
+		{
+			Key:         "views.partitions.showWaitTime",
+			Label:       "Show Wait Time",
+			Description: "Show estimated wait times",
+			Type:        FieldTypeBool,
+		},
+		{
+			Key:         "views.topology",
+			Label:       "Topology View",
+			Description: "Topology view settings",
+			Type:        FieldTypeObject,
+		},
+		{
+			Key:         "views.topology.source",
+			Label:       "Topology Source",
+			Description: "Where node placement comes from; auto tries racks, file, slurm and prefix in turn",
+			Type:        FieldTypeSelect,
+			Options:     []string{"auto", "racks", "file", "slurm", "prefix"},
+		},
+		{
+			Key:         "views.topology.file",
+			Label:       "Topology File",
+			Description: "topology.conf with SwitchName or BlockName lines",
+			Type:        FieldTypeString,
+			Examples:    []string{"/etc/slurm/topology.conf"},
+		},
+		{
+			Key:         "views.topology.racks",
+			Label:       "Racks",
+			Description: "Rack map, used before any other topology source",
+			Type:        FieldTypeArray,
+		},
+		{
+			Key:         "views.topology.racks.*.name",
+			Label:       "Rack Name",
+			Description: "Name of the rack",
+			Type:        FieldTypeString,
+			Required:    true,
+		},
+		{
+			Key:         "views.topology.racks.*.nodes",
+			Label:       "Rack Nodes",
+			Description: "Hostlist of the nodes in the rack",
+			Type:        FieldTypeString,
+			Required:    true,
+			Examples:    []string{"node[001-040]"},
+		},
+		{
+			Key:         "views.topology.colorBy",
+			Label:       "Color By",
+			Description: "Initial cell color of the topology view",
+			Type:        FieldTypeSelect,
+			Options:     []string{"state", "cpu", "memory", "gpu", "load"},
+		},
+		{
+			Key:         "views.sharedDir",
+			Label:       "Shared Views Directory",
+			Description: "Directory of read-only saved views shared by a team",
+			Type:        FieldTypeString,
+		},
+	}
+}
+
+// submissionFields describes the job submission form and its templates
+func submissionFields() []Field {
+	keys := submissionFieldKeys()
+	return []Field{
+		{
+			Key:         "views.jobs.submission",
+			Label:       "Job Submission",
+			Description: "Job submission form settings and templates",
+			Type:        FieldTypeObject,
+		},
+		{
+			Key:         "views.jobs.submission.formDefaults",
+			Label:       "Form Defaults",
+			Description: "Values pre-filled in the submission form for every new job",
+			Type:        FieldTypeObject,
+		},
+		{
+			Key:         "views.jobs.submission.hiddenFields",
+			Label:       "Hidden Fields",
+			Description: "Form fields hidden for every template",
+			Type:        FieldTypeArray,
+			Options:     keys,
+		},
+		{
+			Key:         "views.jobs.submission.fieldOptions",
+			Label:       "Field Options",
+			Description: "Values the partition, qos and account dropdowns offer",
+			Type:        FieldTypeObject,
+		},
+		{
+			Key:         "views.jobs.submission.showBuiltinTemplates",
+			Label:       "Show Built-in Templates",
+			Description: "Deprecated: use templateSources",
+			Type:        FieldTypeBool,
+		},
+		{
+			Key:         "views.jobs.submission.templateSources",
+			Label:       "Template Sources",
+			Description: "Where templates are loaded from: builtin, config, saved or a directory of template files; later sources replace templates of the same name",
+			Type:        FieldTypeArray,
+			Examples:    []string{"builtin", "config", "saved", "~/src/hpc-templates"},
+		},
+		{
+			Key:         "views.jobs.submission.templates",
+			Label:       "Templates",
+			Description: "Job templates offered in the submission form",
+			Type:        FieldTypeArray,
+		},
+		{
+			Key:         "views.jobs.submission.templates.*.name",
+			Label:       "Template Name",
+			Description: "Name of the template; replaces a built-in template of the same name",
+			Type:        FieldTypeString,
+			Required:    true,
+		},
+		{
+			Key:         "views.jobs.submission.templates.*.description",
+			Label:       "Template Description",
+			Description: "Description shown in the template list",
+			Type:        FieldTypeString,
+		},
+		{
+			Key:         "views.jobs.submission.templates.*.version",
+			Label:       "Template Version",
+			Description: "Version of the template, shown in the template list",
+			Type:        FieldTypeString,
+		},
+		{
+			Key:         "views.jobs.submission.templates.*.parameters",
+			Label:       "Template Parameters",
+			Description: "Variables prompted for when the template is used; their values are substituted into the defaults",
+			Type:        FieldTypeArray,
+		},
+		{
+			Key:         "views.jobs.submission.templates.*.parameters.*.name",
+			Label:       "Parameter Name",
+			Description: "Name of the variable, as used in the defaults ({{ .name }})",
+			Type:        FieldTypeString,
+			Required:    true,
+			Pattern:     `^[A-Za-z_][A-Za-z0-9_]*$`,
+		},
+		{
+			Key:         "views.jobs.submission.templates.*.parameters.*.type",
+			Label:       "Parameter Type",
+			Description: "Type of the value",
+			Type:        FieldTypeSelect,
+			Default:     "string",
+			Options:     []string{"string", "int", "bool", "enum", "path", "duration"},
+		},
+		{
+			Key:         "views.jobs.submission.templates.*.parameters.*.description",
+			Label:       "Parameter Description",
+			Description: "Help shown when prompting for the value",
+			Type:        FieldTypeString,
+		},
+		{
+			Key:         "views.jobs.submission.templates.*.parameters.*.default",
+			Label:       "Parameter Default",
+			Description: "Value used when none is given",
+		},
+		{
+			Key:         "views.jobs.submission.templates.*.parameters.*.required",
+			Label:       "Parameter Required",
+			Description: "Whether a value must be given when the parameter has no default",
+			Type:        FieldTypeBool,
+		},
+		{
+			Key:         "views.jobs.submission.templates.*.parameters.*.options",
+			Label:       "Parameter Options",
+			Description: "Values allowed for an enum parameter",
+			Type:        FieldTypeArray,
+		},
+		{
+			Key:         "views.jobs.submission.templates.*.parameters.*.min",
+			Label:       "Parameter Minimum",
+			Description: "Smallest value of an int parameter",
+			Type:        FieldTypeInt,
+		},
+		{
+			Key:         "views.jobs.submission.templates.*.parameters.*.max",
+			Label:       "Parameter Maximum",
+			Description: "Largest value of an int parameter",
+			Type:        FieldTypeInt,
+		},
+		{
+			Key:         "views.jobs.submission.templates.*.parameters.*.mustExist",
+			Label:       "Parameter Must Exist",
+			Description: "Whether the file or directory of a path parameter must exist",
+			Type:        FieldTypeBool,
+		},
+		{
+			Key:         "views.jobs.submission.templates.*.defaults",
+			Label:       "Template Defaults",
+			Description: "Form values the template sets, over formDefaults",
+			Type:        FieldTypeObject,
+		},
+		{
+			Key:         "views.jobs.submission.templates.*.hiddenFields",
+			Label:       "Template Hidden Fields",
+			Description: "Form fields hidden when the template is selected, besides the global hiddenFields",
+			Type:        FieldTypeArray,
+			Options:     keys,
+		},
+		{
+			Key:         "views.jobs.submission.sweep",
+			Label:       "Parameter Sweeps",
+			Description: "Limits of parameter sweeps submitted from the submission form",
+			Type:        FieldTypeObject,
+		},
+		{
+			Key:         "views.jobs.submission.sweep.maxJobs",
+			Label:       "Sweep Max Jobs",
+			Description: "Most individual jobs a parameter sweep submits; larger sweeps must be submitted as a job array",
+			Type:        FieldTypeInt,
+			Default:     500,
+			Min:         &[]float64{1}[0],
+		},
+		{
+			Key:         "views.jobs.submission.sweep.submitInterval",
+			Label:       "Sweep Submit Interval",
+			Description: "Pause between the submissions of a parameter sweep, so as not to flood the scheduler",
+			Type:        FieldTypeDuration,
+			Default:     "200ms",
+		},
+	}
+}
+
+// keyboardFields describes shortcuts and aliases
+func keyboardFields() []Field {
+	return []Field{
+		{
+			Key:         "shortcuts",
+			Label:       "Shortcuts",
+			Description: "Custom keyboard shortcuts running a command",
+			Type:        FieldTypeArray,
+		},
+		{
+			Key:         "shortcuts.*.key",
+			Label:       "Key",
+			Description: `A character, "alt+" and a character, or a named key such as ctrl+t or F3`,
+			Type:        FieldTypeString,
+			Required:    true,
+			Examples:    []string{"ctrl+t", "F3", "alt+h", "x"},
+		},
+		{
+			Key:         "shortcuts.*.action",
+			Label:       "Action",
+			Description: `Command to run, as typed after ":"`,
+			Type:        FieldTypeString,
+			Required:    true,
+			Examples:    []string{"topology", "view running"},
+		},
+		{
+			Key:         "shortcuts.*.description",
+			Label:       "Description",
+			Description: "What the shortcut does",
+			Type:        FieldTypeString,
+		},
+		{
+			Key:         "aliases",
+			Label:       "Aliases",
+			Description: "Command aliases, mapping an alias to the command it expands to",
+			Type:        FieldTypeObject,
+		},
+	}
+}
+
+// pluginFields describes the plugins and the global plugin settings
+func pluginFields() []Field {
+	return []Field{
+		{
+			Key:         "plugins",
+			Label:       "Plugins",
+			Description: "Plugins to load or disable",
+			Type:        FieldTypeArray,
+		},
+		{
+			Key:         "plugins.*.name",
+			Label:       "Plugin Name",
+			Description: "Name of the plugin",
+			Type:        FieldTypeString,
+			Required:    true,
+		},
+		{
+			Key:         "plugins.*.enabled",
+			Label:       "Enabled",
+			Description: "Load the plugin",
+			Type:        FieldTypeBool,
+		},
+		{
+			Key:         "plugins.*.path",
+			Label:       "Plugin Path",
+			Description: "Path of the plugin; environment variables are expanded",
+			Type:        FieldTypeString,
+		},
+		{
+			Key:         "plugins.*.config",
+			Label:       "Plugin Config",
+			Description: "Settings passed to the plugin",
+			Type:        FieldTypeObject,
+		},
+		{
+			Key:         "pluginSettings",
+			Label:       "Plugin Settings",
+			Description: "Settings shared by all plugins",
+			Type:        FieldTypeObject,
+		},
+		{
+			Key:         "pluginSettings.enableAll",
+			Label:       "Enable All",
+			Description: "Load every discovered plugin",
+			Type:        FieldTypeBool,
+		},
+		{
+			Key:         "pluginSettings.pluginDir",
+			Label:       "Plugin Directory",
+			Description: "Directory plugins are discovered in",
+			Type:        FieldTypeString,
+			Examples:    []string{"$HOME/.s9s/plugins"},
+		},
+		{
+			Key:         "pluginSettings.autoDiscover",
+			Label:       "Auto-Discover",
+			Description: "Discover plugins in the plugin directory",
+			Type:        FieldTypeBool,
+		},
+		{
+			Key:         "pluginSettings.safeMode",
+			Label:       "Safe Mode",
+			Description: "Disable external plugins",
+			Type:        FieldTypeBool,
+		},
+		{
+			Key:         "pluginSettings.maxMemoryMB",
+			Label:       "Max Memory",
+			Description: "Memory limit per plugin, in MB",
+			Type:        FieldTypeInt,
+			Min:         &[]float64{0}[0],
+		},
+		{
+			Key:         "pluginSettings.maxCPUPercent",
+			Label:       "Max CPU",
+			Description: "CPU limit per plugin, in percent",
+			Type:        FieldTypeFloat,
+			Min:         &[]float64{0}[0],
+			Max:         &[]float64{100}[0],
+		},
+	}
+}
+
+// serviceFields describes features, updates, metrics history and health
+func serviceFields() []Field {
+	return []Field{
+		{
+			Key:         "features",
+			Label:       "Features",
+			Description: "Feature flags",
+			Type:        FieldTypeObject,
+		},
+		{
+			Key:         "features.streaming",
+			Label:       "Streaming",
+			Description: "Stream job output in real time",
+			Type:        FieldTypeBool,
+		},
+		{
+			Key:         "features.pulseye",
+			Label:       "Pulseye",
+			Description: "Health scanner",
+			Type:        FieldTypeBool,
+		},
+		{
+			Key:         "features.xray",
+			Label:       "Xray",
+			Description: "Deep inspection mode",
+			Type:        FieldTypeBool,
+		},
+		{
+			Key:         "features.appDiagnostics",
+			Label:       "App Diagnostics",
+			Description: "Application diagnostics",
+			Type:        FieldTypeBool,
+		},
+		{
+			Key:         "update",
+			Label:       "Updates",
+			Description: "Checks for new s9s releases on startup",
+			Type:        FieldTypeObject,
+		},
+		{
+			Key:         "update.enabled",
+			Label:       "Check for Updates",
+			Description: "Check for a new release on startup",
+			Type:        FieldTypeBool,
+		},
+		{
+			Key:         "update.autoInstall",
+			Label:       "Install Updates",
+			Description: "Install new releases instead of only announcing them",
+			Type:        FieldTypeBool,
+			Depends:     []Dependency{{Field: "update.enabled", Value: true}},
+		},
+		{
+			Key:         "update.checkInterval",
+			Label:       "Check Interval",
+			Description: "How often to check for a new release",
+			Type:        FieldTypeDuration,
+		},
+		{
+			Key:         "update.preRelease",
+			Label:       "Pre-Releases",
+			Description: "Include pre-releases",
+			Type:        FieldTypeBool,
+		},
+		{
+			Key:         "history",
+			Label:       "Metrics History",
+			Description: "In-process history of cluster metrics behind the dashboard trends",
+			Type:        FieldTypeObject,
+		},
+		{
+			Key:         "history.persist",
+			Label:       "Persist History",
+			Description: "Save the history to ~/.s9s/history on exit",
+			Type:        FieldTypeBool,
+		},
+		{
+			Key:         "history.retention",
+			Label:       "History Retention",
+			Description: "How much history to keep",
+			Type:        FieldTypeDuration,
+		},
+		{
+			Key:         "health",
+			Label:       "Health",
+			Description: "Health rules shared by the health view, dashboard alerts, the exporter and s9s health",
+			Type:        FieldTypeObject,
+		},
+		{
+			Key:         "health.rules",
+			Label:       "Health Rules",
+			Description: "Health rules; a rule named after a built-in rule or check replaces it",
+			Type:        FieldTypeArray,
+		},
+		{
+			Key:         "health.rules.*.name",
+			Label:       "Rule Name",
+			Description: "Unique name of the rule",
+			Type:        FieldTypeString,
+			Required:    true,
+		},
+		{
+			Key:         "health.rules.*.description",
+			Label:       "Rule Description",
+			Description: "What the rule checks",
+			Type:        FieldTypeString,
+		},
+		{
+			Key:         "health.rules.*.expr",
+			Label:       "Expression",
+			Description: "Metric expression; s9s health --metrics lists the metrics",
+			Type:        FieldTypeString,
+			Examples:    []string{"jobs_pending / max(nodes_idle, 1)"},
+		},
+		{
+			Key:         "health.rules.*.partitions",
+			Label:       "Partitions",
+			Description: `Partitions to evaluate the rule for; "*" for every partition`,
+			Type:        FieldTypeArray,
+		},
+		{
+			Key:         "health.rules.*.warning",
+			Label:       "Warning Threshold",
+			Description: "Value at which the rule warns",
+			Type:        FieldTypeFloat,
+		},
+		{
+			Key:         "health.rules.*.critical",
+			Label:       "Critical Threshold",
+			Description: "Value at which the rule is critical",
+			Type:        FieldTypeFloat,
+		},
+		{
+			Key:         "health.rules.*.below",
+			Label:       "Below",
+			Description: "Breach below the thresholds instead of above",
+			Type:        FieldTypeBool,
+		},
+		{
+			Key:         "health.rules.*.for",
+			Label:       "For",
+			Description: "How long a breach must last before the rule fires",
+			Type:        FieldTypeDuration,
+		},
+		{
+			Key:         "health.rules.*.severity",
+			Label:       "Severity",
+			Description: "Severity of every breach of the rule",
+			Type:        FieldTypeSelect,
+			Options:     []string{"info", "warning", "critical"},
+		},
+		{
+			Key:         "health.rules.*.message",
+			Label:       "Message",
+			Description: "Alert text with {value}, {partition}, {name} and {<metric>} placeholders",
+			Type:        FieldTypeString,
+		},
+		{
+			Key:         "health.rules.*.disabled",
+			Label:       "Disabled",
+			Description: "Turn off the rule or built-in check",
+			Type:        FieldTypeBool,
+		},
+	}
+}
+
+// policyFields describes the audit log and the safety policy
+func policyFields() []Field {
+	return []Field{
+		{
+			Key:         "audit",
+			Label:       "Audit Log",
+			Description: "Append-only log of every job, node and partition change",
+			Type:        FieldTypeObject,
+		},
+		{
+			Key:         "audit.enabled",
+			Label:       "Enable Audit Log",
+			Description: "Record every mutating action",
+			Type:        FieldTypeBool,
+		},
+		{
+			Key:         "audit.file",
+			Label:       "Audit File",
+			Description: "JSON lines log (default: ~/.s9s/audit.log)",
+			Type:        FieldTypeString,
+		},
+		{
+			Key:         "audit.maxSizeMB",
+			Label:       "Max Size",
+			Description: "Rotate the log when it reaches this size in MB; 0 never rotates",
+			Type:        FieldTypeInt,
+			Min:         &[]float64{0}[0],
+		},
+		{
+			Key:         "audit.maxFiles",
+			Label:       "Max Files",
+			Description: "Rotated logs to keep",
+			Type:        FieldTypeInt,
+			Min:         &[]float64{0}[0],
+		},
+		{
+			Key:         "audit.forward",
+			Label:       "Forward",
+			Description: "Copy entries to a second file for a log shipper",
+			Type:        FieldTypeObject,
+		},
+		{
+			Key:         "audit.forward.format",
+			Label:       "Forward Format",
+			Description: "syslog (RFC 5424) or journald (journal export format)",
+			Type:        FieldTypeSelect,
+			Options:     []string{"syslog", "journald"},
+		},
+		{
+			Key:         "audit.forward.file",
+			Label:       "Forward File",
+			Description: "File the log shipper reads",
+			Type:        FieldTypeString,
+		},
+		{
+			Key:         "safety",
+			Label:       "Safety Policies",
+			Description: "Policies every destructive job, node and partition action must pass",
+			Type:        FieldTypeObject,
+		},
+		{
+			Key:         "safety.adminMode",
+			Label:       "Admin Mode",
+			Description: "Start in admin mode, which allows cancelling other users' jobs",
+			Type:        FieldTypeBool,
+		},
+		{
+			Key:         "safety.allowOtherUsersJobs",
+			Label:       "Allow Other Users' Jobs",
+			Description: "Cancel and requeue other users' jobs without admin mode",
+			Type:        FieldTypeBool,
+		},
+		{
+			Key:         "safety.maxDrainNodes",
+			Label:       "Max Drained Nodes",
+			Description: "Most nodes drained within drainWindow; 0 for no limit",
+			Type:        FieldTypeInt,
+			Min:         &[]float64{0}[0],
+		},
+		{
+			Key:         "safety.maxDrainPercent",
+			Label:       "Max Drained Percent",
+			Description: "Most percent of a partition drained within drainWindow; 0 for no limit",
+			Type:        FieldTypeFloat,
+			Min:         &[]float64{0}[0],
+			Max:         &[]float64{100}[0],
+		},
+		{
+			Key:         "safety.drainWindow",
+			Label:       "Drain Window",
+			Description: "How long drains count towards the drain limits (default: 10m)",
+			Type:        FieldTypeDuration,
+		},
+		{
+			Key:         "safety.protect",
+			Label:       "Protect Rules",
+			Description: "Rules protecting jobs, nodes or partitions from destructive actions",
+			Type:        FieldTypeArray,
+		},
+		{
+			Key:         "safety.protect.*.name",
+			Label:       "Rule Name",
+			Description: "Name of the rule, shown when it applies",
+			Type:        FieldTypeString,
+		},
+		{
+			Key:         "safety.protect.*.actions",
+			Label:       "Actions",
+			Description: "Actions the rule covers, e.g. node.drain or job.*; default: every destructive action",
+			Type:        FieldTypeArray,
+			Examples:    []string{"job.cancel", "node.*"},
+		},
+		{
+			Key:         "safety.protect.*.nodes",
+			Label:       "Nodes",
+			Description: "Hostlist of protected nodes",
+			Type:        FieldTypeString,
+			Examples:    []string{"login[01-02],gpu[01-08]"},
+		},
+		{
+			Key:         "safety.protect.*.partitions",
+			Label:       "Partitions",
+			Description: "Protected partitions, or the partitions of protected jobs and nodes",
+			Type:        FieldTypeArray,
+		},
+		{
+			Key:         "safety.protect.*.jobs",
+			Label:       "Jobs",
+			Description: "Job ID or name globs",
+			Type:        FieldTypeArray,
+		},
+		{
+			Key:         "safety.protect.*.users",
+			Label:       "Users",
+			Description: "Owners of protected jobs",
+			Type:        FieldTypeArray,
+		},
+		{
+			Key:         "safety.protect.*.mode",
+			Label:       "Mode",
+			Description: "confirm requires typing the target's name, deny refuses the action",
+			Type:        FieldTypeSelect,
+			Options:     []string{"confirm", "deny"},
+		},
+	}
+}
@@ -292,7 +292,7 @@ func (tm *TemplateManager) GenerateConfig(templateName string, variables map[str
 		config = strings.ReplaceAll(config, placeholder, value)
 	}
 
-	return config, nil
+	return SchemaHeader + config, nil
 }
 
 // SaveTemplateAsConfig saves a generated template as a configuration file
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 	"time"
@@ -103,6 +104,21 @@ func (v *Validator) Validate() *ValidationResult {
 	// Performance settings validation
 	v.validatePerformanceSettings()
 
+	// Health rule validation
+	v.validateHealthRules()
+
+	// Topology view validation
+	v.validateTopology()
+
+	// Audit log validation
+	v.validateAudit()
+
+	// Safety policy validation
+	v.validateSafety()
+
+	// Keyboard shortcut validation
+	v.validateShortcuts()
+
 	// Security settings validation
 	v.validateSecurity()
 
@@ -244,6 +260,12 @@ func (v *Validator) validateAuthentication() {
 
 	// Basic token validation for existing ClusterConfig
 	for i, entry := range v.config.Clusters {
+		v.validateTokenSource(&entry.Cluster.TokenFrom, fmt.Sprintf("clusters[%d].cluster.tokenFrom", i))
+		if entry.Cluster.Token != "" && entry.Cluster.TokenFrom.IsSet() {
+			v.addWarning(fmt.Sprintf("clusters[%d].cluster.token", i),
+				"Both token and tokenFrom are set",
+				"The token is ignored; remove it from the config")
+		}
 		if entry.Cluster.Token != "" {
 			// Basic JWT token validation
 			parts := strings.Split(entry.Cluster.Token, ".")
@@ -256,6 +278,38 @@ func (v *Validator) validateAuthentication() {
 	}
 }
 
+// validateTokenSource validates where a cluster's token comes from
+func (v *Validator) validateTokenSource(source *TokenSourceConfig, basePath string) {
+	if !source.IsSet() {
+		if source.RefreshBefore != "" {
+			v.addWarning(basePath+".refreshBefore", "refreshBefore is set without a token source",
+				"Set one of command, file, keyring, env or oauth2")
+		}
+		return
+	}
+
+	if kinds := source.Kinds(); len(kinds) > 1 {
+		v.addError(basePath, fmt.Sprintf("Several token sources set: %s", strings.Join(kinds, ", ")),
+			"Set exactly one of command, file, keyring, env or oauth2", false)
+	}
+	if source.File != "" {
+		if _, err := os.Stat(source.File); err != nil {
+			v.addWarning(basePath+".file", fmt.Sprintf("Token file not readable: %s", source.File),
+				"Create the file or fix the path")
+		}
+	}
+	if source.OAuth2 != nil && source.OAuth2.ClientID == "" {
+		v.addError(basePath+".oauth2.clientId", "OAuth2 client ID is required",
+			"Set the client ID registered with your identity provider", false)
+	}
+	if source.RefreshBefore != "" {
+		if d, err := time.ParseDuration(source.RefreshBefore); err != nil || d < 0 {
+			v.addError(basePath+".refreshBefore", fmt.Sprintf("Invalid refreshBefore duration: %s", source.RefreshBefore),
+				"Use a duration such as 5m", false)
+		}
+	}
+}
+
 // validatePerformanceSettings validates performance-related settings
 func (v *Validator) validatePerformanceSettings() {
 	// Refresh rate validation
@@ -273,6 +327,17 @@ func (v *Validator) validatePerformanceSettings() {
 		}
 	}
 
+	// History retention validation
+	if v.config.History.Retention != "" {
+		if duration, err := time.ParseDuration(v.config.History.Retention); err != nil || duration < time.Minute {
+			v.fix("history.retention", "Invalid history retention duration", v.config.History.Retention, "24h")
+		} else if duration > 7*24*time.Hour {
+			v.addWarning("history.retention",
+				"Long history retention increases memory use",
+				"Consider keeping 7 days or less")
+		}
+	}
+
 	// Mock client warning
 	if v.config.UseMockClient {
 		v.addWarning("use_mock_client",
@@ -281,6 +346,190 @@ func (v *Validator) validatePerformanceSettings() {
 	}
 }
 
+// validateHealthRules validates the health rule settings. Expressions are
+// checked when the rules are compiled by the monitoring package.
+func (v *Validator) validateHealthRules() {
+	seen := make(map[string]bool)
+	for i, rule := range v.config.Health.Rules {
+		field := fmt.Sprintf("health.rules[%d]", i)
+		if rule.Name == "" {
+			v.addError(field+".name", "Health rule has no name", "Give every rule a unique name", false)
+			continue
+		}
+		if seen[rule.Name] {
+			v.addError(field+".name", fmt.Sprintf("Duplicate health rule %q", rule.Name),
+				"Rule names must be unique", false)
+		}
+		seen[rule.Name] = true
+
+		if rule.For != "" && !v.isValidDuration(rule.For) {
+			v.addError(field+".for", fmt.Sprintf("Invalid duration %q", rule.For),
+				"Use a Go duration such as 10m", false)
+		}
+		switch strings.ToLower(rule.Severity) {
+		case "", "info", "warning", "critical":
+		default:
+			v.addError(field+".severity", fmt.Sprintf("Unknown severity %q", rule.Severity),
+				"Use info, warning or critical", false)
+		}
+	}
+}
+
+// validateAudit validates the audit log settings
+func (v *Validator) validateAudit() {
+	audit := v.config.Audit
+	if !audit.Enabled {
+		return
+	}
+	if audit.MaxSizeMB < 0 {
+		v.addError("audit.maxSizeMB", "Audit log size limit cannot be negative",
+			"Set a size in MB, or 0 to never rotate", false)
+	}
+	if audit.MaxFiles < 0 {
+		v.addError("audit.maxFiles", "Number of rotated audit logs cannot be negative",
+			"Set the number of rotated logs to keep", false)
+	}
+
+	forward := audit.Forward
+	switch strings.ToLower(forward.Format) {
+	case "", "syslog", "journald":
+	default:
+		v.addError("audit.forward.format", fmt.Sprintf("Unknown audit forward format %q", forward.Format),
+			"Use syslog or journald", false)
+	}
+	if forward.Format != "" && forward.File == "" {
+		v.addError("audit.forward.file", "Audit forward format is set but no file is set",
+			"Set audit.forward.file to the file your log shipper reads", false)
+	}
+	if forward.File != "" && forward.Format == "" {
+		v.addError("audit.forward.format", "Audit forward file is set but no format is set",
+			"Use syslog or journald", false)
+	}
+}
+
+// validateSafety validates the safety policy settings. Node hostlists are
+// checked when the policy is built.
+func (v *Validator) validateSafety() {
+	safety := v.config.Safety
+	if safety.MaxDrainNodes < 0 {
+		v.addError("safety.maxDrainNodes", "Drain limit cannot be negative",
+			"Set the most nodes that may be drained at once, or 0 for no limit", false)
+	}
+	if safety.MaxDrainPercent < 0 || safety.MaxDrainPercent > 100 {
+		v.addError("safety.maxDrainPercent", "Drain percentage must be between 0 and 100",
+			"Set the largest share of a partition that may be drained at once, or 0 for no limit", false)
+	}
+	if safety.DrainWindow != "" {
+		if window, err := time.ParseDuration(safety.DrainWindow); err != nil || window <= 0 {
+			v.addError("safety.drainWindow", fmt.Sprintf("Invalid drain window %q", safety.DrainWindow),
+				"Use a positive duration such as 10m", false)
+		}
+	}
+	if safety.AdminMode {
+		v.addWarning("safety.adminMode", "s9s starts in admin mode",
+			"Other users' jobs can be cancelled without turning on :admin first")
+	}
+
+	for i, rule := range safety.Protect {
+		field := fmt.Sprintf("safety.protect[%d]", i)
+		switch strings.ToLower(rule.Mode) {
+		case "", "confirm", "deny":
+		default:
+			v.addError(field+".mode", fmt.Sprintf("Unknown protect mode %q", rule.Mode),
+				"Use confirm or deny", false)
+		}
+		for _, action := range rule.Actions {
+			if !isProtectableAction(action) {
+				v.addError(field+".actions", fmt.Sprintf("Action %q matches no destructive action", action),
+					"Use e.g. job.cancel, node.drain, partition.set_state, job.* or node.*", false)
+			}
+		}
+	}
+}
+
+// protectableActions are the actions protect rules can match
+var protectableActions = []string{
+	"job.cancel", "job.hold", "job.release", "job.requeue",
+	"node.drain", "node.resume", "node.set_state",
+	"partition.set_state", "partition.set_max_time",
+}
+
+// isProtectableAction reports whether the action glob matches at least one
+// destructive action
+func isProtectableAction(glob string) bool {
+	for _, action := range protectableActions {
+		if ok, _ := path.Match(glob, action); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// validateShortcuts validates the custom keyboard shortcuts
+func (v *Validator) validateShortcuts() {
+	seen := make(map[string]bool)
+	for i, shortcut := range v.config.Shortcuts {
+		field := fmt.Sprintf("shortcuts[%d]", i)
+		if strings.TrimSpace(shortcut.Key) == "" {
+			v.addError(field+".key", "Shortcut has no key", "Set key, e.g. \"ctrl+e\" or \"F5\"", false)
+			continue
+		}
+		if strings.TrimSpace(shortcut.Action) == "" {
+			v.addError(field+".action", fmt.Sprintf("Shortcut %s has no action", shortcut.Key),
+				"Set action to a command, e.g. \"view running\"", false)
+		}
+		key := strings.TrimSpace(shortcut.Key)
+		if len([]rune(key)) > 1 {
+			// Named keys are case-insensitive, single characters are not
+			key = strings.ToLower(key)
+		}
+		if seen[key] {
+			v.addError(field+".key", fmt.Sprintf("Duplicate shortcut key: %s", shortcut.Key),
+				"Bind each key once", false)
+		}
+		seen[key] = true
+	}
+}
+
+// validateTopology validates the topology view settings
+func (v *Validator) validateTopology() {
+	topo := v.config.Views.Topology
+	switch strings.ToLower(topo.Source) {
+	case "", "auto", "racks", "file", "slurm", "prefix":
+	default:
+		v.addError("views.topology.source", fmt.Sprintf("Unknown topology source %q", topo.Source),
+			"Use auto, racks, file, slurm or prefix", false)
+	}
+	switch strings.ToLower(topo.ColorBy) {
+	case "", "state", "cpu", "memory", "gpu", "load":
+	default:
+		v.addError("views.topology.colorBy", fmt.Sprintf("Unknown topology color mode %q", topo.ColorBy),
+			"Use state, cpu, memory, gpu or load", false)
+	}
+
+	if strings.EqualFold(topo.Source, "file") && topo.File == "" {
+		v.addError("views.topology.file", "Topology source is file but no file is set",
+			"Set views.topology.file to a topology.conf", false)
+	}
+	if topo.File != "" {
+		if _, err := os.Stat(topo.File); err != nil {
+			v.addWarning("views.topology.file",
+				fmt.Sprintf("Topology file not readable: %s", topo.File),
+				"The topology view falls back to the next topology source")
+		}
+	}
+
+	for i, rack := range topo.Racks {
+		field := fmt.Sprintf("views.topology.racks[%d]", i)
+		if rack.Name == "" {
+			v.addError(field+".name", "Rack has no name", "Give every rack a name", false)
+		}
+		if rack.Nodes == "" {
+			v.addError(field+".nodes", "Rack has no nodes", "Set nodes to a hostlist such as node[001-040]", false)
+		}
+	}
+}
+
 // validateSecurity validates security settings
 func (v *Validator) validateSecurity() {
 	for i, entry := range v.config.Clusters {
@@ -314,6 +563,12 @@ func (v *Validator) validatePaths() {
 	v.validateConfigDirectory(configDir)
 	v.validateCacheDirectory(configDir)
 	v.validateLogsDirectory(configDir)
+
+	if dir := v.config.Views.SharedDir; dir != "" && !v.directoryExists(dir) {
+		v.addWarning("views.sharedDir",
+			fmt.Sprintf("Shared views directory not found: %s", dir),
+			"Create the directory or remove views.sharedDir to use only personal views")
+	}
 }
 
 // validateConfigDirectory checks and creates the config directory
@@ -460,6 +715,8 @@ func (v *ConfigValidator) applyFix(field string, newValue interface{}) {
 		v.config.RefreshRate = newValue.(string)
 	case "default_cluster":
 		v.config.DefaultCluster = newValue.(string)
+	case "history.retention":
+		v.config.History.Retention = newValue.(string)
 		// Add more cases as needed for different fields
 	}
 }
@@ -514,6 +771,24 @@ func ValidateAndFix(config *Config, autoFix bool) *ValidationResult {
 	return validator.Validate()
 }
 
+// NewErrors returns the errors of r that before does not have, so that a
+// change can be refused only for the problems it introduces
+func (r *ValidationResult) NewErrors(before *ValidationResult) []ValidationError {
+	known := make(map[string]bool)
+	if before != nil {
+		for _, err := range before.Errors {
+			known[err.Field+"\x00"+err.Message] = true
+		}
+	}
+	var introduced []ValidationError
+	for _, err := range r.Errors {
+		if !known[err.Field+"\x00"+err.Message] {
+			introduced = append(introduced, err)
+		}
+	}
+	return introduced
+}
+
 // PrintValidationResult prints a formatted validation result
 func PrintValidationResult(result *ValidationResult, verbose bool) {
 	printValidationStatus(result.Valid)
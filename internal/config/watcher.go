@@ -0,0 +1,132 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/jontk/s9s/internal/debug"
+)
+
+// watchDebounce is how long a config file must stay unchanged before a
+// change is reported, so that an editor's save is reported once
+const watchDebounce = 250 * time.Millisecond
+
+// Watcher reports changes to config files. The files' directories are
+// watched rather than the files themselves, so that editors which save by
+// replacing a file keep being followed, and files created later are seen.
+type Watcher struct {
+	paths    map[string]bool
+	watcher  *fsnotify.Watcher
+	onChange func()
+
+	mu    sync.Mutex
+	timer *time.Timer
+	done  chan struct{}
+}
+
+// NewWatcher starts watching the config files at paths and calls onChange
+// from a background goroutine after each change. Files in directories that
+// do not exist are not watched.
+func NewWatcher(paths []string, onChange func()) (*Watcher, error) {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("creating config watcher: %w", err)
+	}
+
+	w := &Watcher{
+		paths:    make(map[string]bool, len(paths)),
+		watcher:  fsw,
+		onChange: onChange,
+		done:     make(chan struct{}),
+	}
+	dirs := make(map[string]bool)
+	for _, path := range paths {
+		path, err := filepath.Abs(path)
+		if err != nil {
+			_ = fsw.Close()
+			return nil, fmt.Errorf("resolving config path: %w", err)
+		}
+		dir := filepath.Dir(path)
+		if !dirs[dir] {
+			if _, err := os.Stat(dir); os.IsNotExist(err) {
+				continue
+			}
+			if err := fsw.Add(dir); err != nil {
+				_ = fsw.Close()
+				return nil, fmt.Errorf("watching %s: %w", dir, err)
+			}
+			dirs[dir] = true
+		}
+		w.paths[path] = true
+	}
+	if len(w.paths) == 0 {
+		_ = fsw.Close()
+		return nil, fmt.Errorf("no config file to watch")
+	}
+	go w.run()
+	return w, nil
+}
+
+// Paths returns the watched config files
+func (w *Watcher) Paths() []string {
+	paths := make([]string, 0, len(w.paths))
+	for path := range w.paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Close stops watching
+func (w *Watcher) Close() error {
+	w.mu.Lock()
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	w.mu.Unlock()
+	close(w.done)
+	return w.watcher.Close()
+}
+
+func (w *Watcher) run() {
+	for {
+		select {
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if !w.paths[filepath.Clean(event.Name)] || !event.Has(fsnotify.Write|fsnotify.Create|fsnotify.Rename) {
+				continue
+			}
+			w.schedule()
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			debug.Logger.Printf("Config watcher error: %v", err)
+		case <-w.done:
+			return
+		}
+	}
+}
+
+// schedule reports a change once the files have been quiet for watchDebounce
+func (w *Watcher) schedule() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	w.timer = time.AfterFunc(watchDebounce, func() {
+		select {
+		case <-w.done:
+		default:
+			w.onChange()
+		}
+	})
+}
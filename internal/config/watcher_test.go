@@ -0,0 +1,79 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatcherReportsChanges(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("refreshRate: 10s\n"), 0o600))
+
+	changed := make(chan struct{}, 10)
+	w, err := NewWatcher([]string{path}, func() { changed <- struct{}{} })
+	require.NoError(t, err)
+	defer func() { _ = w.Close() }()
+
+	// Other files in the directory are ignored
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "other.yaml"), []byte("x: 1\n"), 0o600))
+	select {
+	case <-changed:
+		t.Fatal("change reported for another file")
+	case <-time.After(2 * watchDebounce):
+	}
+
+	// A burst of writes is reported once
+	for _, rate := range []string{"1s", "2s", "3s"} {
+		require.NoError(t, os.WriteFile(path, []byte("refreshRate: "+rate+"\n"), 0o600))
+	}
+	waitForChange(t, changed)
+	select {
+	case <-changed:
+		t.Fatal("burst of writes reported more than once")
+	case <-time.After(2 * watchDebounce):
+	}
+
+	// Saving by replacing the file is followed
+	tmp := filepath.Join(dir, ".config.yaml.tmp")
+	require.NoError(t, os.WriteFile(tmp, []byte("refreshRate: 5s\n"), 0o600))
+	require.NoError(t, os.Rename(tmp, path))
+	waitForChange(t, changed)
+}
+
+func waitForChange(t *testing.T, changed <-chan struct{}) {
+	t.Helper()
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("change not reported")
+	}
+}
+
+func TestWatcherFollowsLayers(t *testing.T) {
+	system := filepath.Join(t.TempDir(), "config.yaml")
+	user := filepath.Join(t.TempDir(), "config.yaml")
+	missing := filepath.Join(t.TempDir(), "gone", ".s9s.yaml")
+	require.NoError(t, os.WriteFile(system, []byte("refreshRate: 10s\n"), 0o600))
+
+	changed := make(chan struct{}, 10)
+	w, err := NewWatcher([]string{system, user, missing}, func() { changed <- struct{}{} })
+	require.NoError(t, err)
+	defer func() { _ = w.Close() }()
+	assert.NotContains(t, w.Paths(), missing)
+
+	require.NoError(t, os.WriteFile(system, []byte("refreshRate: 20s\n"), 0o600))
+	waitForChange(t, changed)
+
+	// A layer file created later is followed
+	require.NoError(t, os.WriteFile(user, []byte("refreshRate: 5s\n"), 0o600))
+	waitForChange(t, changed)
+
+	_, err = NewWatcher([]string{missing}, func() {})
+	assert.Error(t, err)
+}
@@ -0,0 +1,17 @@
+package dao
+
+// Names of the mutating actions of the job, node and partition managers,
+// as recorded in the audit log and matched by safety policies
+const (
+	ActionJobSubmit           = "job.submit"
+	ActionJobCancel           = "job.cancel"
+	ActionJobHold             = "job.hold"
+	ActionJobRelease          = "job.release"
+	ActionJobRequeue          = "job.requeue"
+	ActionJobNotify           = "job.notify"
+	ActionNodeDrain           = "node.drain"
+	ActionNodeResume          = "node.resume"
+	ActionNodeSetState        = "node.set_state"
+	ActionPartitionSetState   = "partition.set_state"
+	ActionPartitionSetMaxTime = "partition.set_max_time"
+)
@@ -2,6 +2,7 @@ package dao
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 )
 
@@ -11,15 +12,24 @@ type cachedJobManager struct {
 	cache *DAOCache
 }
 
+// buildJobListCacheKey keys a job list by every option; value lists are
+// sorted so the same filter always maps to the same entry
 func buildJobListCacheKey(opts *ListJobsOptions) string {
 	if opts == nil {
 		return "jobs:list:"
 	}
-	return fmt.Sprintf("jobs:list:%s:%d:%d:%s:%s",
-		strings.Join(opts.States, ","),
+	return fmt.Sprintf("jobs:list:%s:%d:%d:%s:%s:%s:%s",
+		sortedJoin(opts.States),
 		opts.Limit, opts.Offset,
-		strings.Join(opts.Users, ","),
-		strings.Join(opts.Partitions, ","))
+		sortedJoin(opts.Users),
+		sortedJoin(opts.Partitions),
+		sortedJoin(opts.Accounts),
+		opts.ArrayJobID)
+}
+
+// sortedJoin joins a sorted copy of values with commas
+func sortedJoin(values []string) string {
+	return strings.Join(slices.Sorted(slices.Values(values)), ",")
 }
 
 func (c *cachedJobManager) List(opts *ListJobsOptions) (*JobList, error) {
@@ -120,6 +130,14 @@ func (c *cachedPartitionManager) List() (*PartitionList, error) {
 }
 
 func (c *cachedPartitionManager) Get(name string) (*Partition, error) { return c.inner.Get(name) }
+func (c *cachedPartitionManager) SetState(name, state string) error {
+	c.cache.InvalidatePrefix("partitions:")
+	return c.inner.SetState(name, state)
+}
+func (c *cachedPartitionManager) SetMaxTime(name, limit string) error {
+	c.cache.InvalidatePrefix("partitions:")
+	return c.inner.SetMaxTime(name, limit)
+}
 
 // copyJobList returns a shallow copy so callers can't corrupt the cached data.
 func copyJobList(src *JobList) *JobList {
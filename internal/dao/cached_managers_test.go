@@ -20,7 +20,7 @@ func TestBuildJobListCacheKey(t *testing.T) {
 		{
 			name:     "empty options",
 			opts:     &ListJobsOptions{},
-			expected: "jobs:list::0:0::",
+			expected: "jobs:list::0:0::::",
 		},
 		{
 			name: "with all fields",
@@ -30,8 +30,10 @@ func TestBuildJobListCacheKey(t *testing.T) {
 				Offset:     50,
 				Users:      []string{"alice"},
 				Partitions: []string{"gpu"},
+				Accounts:   []string{"physics", "chem"},
+				ArrayJobID: "4200",
 			},
-			expected: "jobs:list:RUNNING,PENDING:100:50:alice:gpu",
+			expected: "jobs:list:PENDING,RUNNING:100:50:alice:gpu:chem,physics:4200",
 		},
 	}
 
@@ -43,6 +45,39 @@ func TestBuildJobListCacheKey(t *testing.T) {
 	}
 }
 
+func TestBuildJobListCacheKeyIsCanonical(t *testing.T) {
+	opts := &ListJobsOptions{States: []string{"RUNNING", "PENDING"}, Users: []string{"bob", "alice"}}
+	reordered := &ListJobsOptions{States: []string{"PENDING", "RUNNING"}, Users: []string{"alice", "bob"}}
+	assert.Equal(t, buildJobListCacheKey(opts), buildJobListCacheKey(reordered))
+	assert.Equal(t, []string{"RUNNING", "PENDING"}, opts.States, "the options are not reordered")
+
+	withAccount := &ListJobsOptions{States: []string{"PENDING", "RUNNING"}, Users: []string{"alice", "bob"}, Accounts: []string{"physics"}}
+	assert.NotEqual(t, buildJobListCacheKey(reordered), buildJobListCacheKey(withAccount))
+}
+
+func TestPageJobs(t *testing.T) {
+	jobs := []*Job{{ID: "1"}, {ID: "2"}, {ID: "3"}}
+
+	assert.Len(t, pageJobs(jobs, 0, 0), 3)
+	assert.Equal(t, []*Job{{ID: "2"}}, pageJobs(jobs, 1, 1))
+	assert.Equal(t, []*Job{{ID: "3"}}, pageJobs(jobs, 5, 2))
+	assert.Empty(t, pageJobs(jobs, 2, 3))
+}
+
+func TestMatchesJobListOptions(t *testing.T) {
+	job := &Job{ID: "1", User: "alice", Partition: "gpu", Account: "physics", ArrayJobID: "1"}
+
+	assert.True(t, matchesJobListOptions(job, &ListJobsOptions{}))
+	assert.True(t, matchesJobListOptions(job, &ListJobsOptions{Users: []string{"bob", "alice"}, Accounts: []string{"physics"}}))
+	assert.False(t, matchesJobListOptions(job, &ListJobsOptions{Partitions: []string{"cpu", "debug"}}))
+	assert.False(t, matchesJobListOptions(job, &ListJobsOptions{Accounts: []string{"chem"}}))
+	assert.True(t, matchesJobListOptions(job, &ListJobsOptions{ArrayJobID: "1"}))
+	assert.False(t, matchesJobListOptions(job, &ListJobsOptions{ArrayJobID: "2"}))
+	assert.True(t, needsClientSideJobFilter(&ListJobsOptions{Accounts: []string{"chem"}}))
+	assert.True(t, needsClientSideJobFilter(&ListJobsOptions{ArrayJobID: "1"}))
+	assert.False(t, needsClientSideJobFilter(&ListJobsOptions{Users: []string{"alice"}, Partitions: []string{"gpu"}}))
+}
+
 func TestBuildNodeListCacheKey(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -0,0 +1,64 @@
+package dao
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Dependency types of SLURM, in the order sbatch documents them
+const (
+	DependencyAfter      = "after"      // After the jobs start
+	DependencyAfterAny   = "afterany"   // After the jobs end in any state
+	DependencyAfterOK    = "afterok"    // After the jobs complete successfully
+	DependencyAfterNotOK = "afternotok" // After the jobs fail
+	DependencyAfterCorr  = "aftercorr"  // Array tasks after the same task of the other array
+)
+
+// DependencyTypes are the dependency types a JobSubmission can use
+var DependencyTypes = []string{DependencyAfter, DependencyAfterAny, DependencyAfterOK, DependencyAfterNotOK, DependencyAfterCorr}
+
+// Dependency returns a JobSubmission dependency entry on the given jobs:
+// Dependency(DependencyAfterAny, "12", "13") is "afterany:12:13"
+func Dependency(typ string, ids ...string) string {
+	return typ + ":" + strings.Join(ids, ":")
+}
+
+// DependencyString returns the SLURM dependency of a job submission. An
+// entry of deps is either a bare job ID, which the job runs after once it
+// completes successfully, or a typed entry such as "afterany:12:13".
+func DependencyString(deps []string) string {
+	var ok []string
+	var typed []string
+	for _, dep := range deps {
+		dep = strings.TrimSpace(dep)
+		switch {
+		case dep == "":
+		case strings.Contains(dep, ":"):
+			typed = append(typed, dep)
+		default:
+			ok = append(ok, dep)
+		}
+	}
+	if len(ok) > 0 {
+		typed = append([]string{Dependency(DependencyAfterOK, ok...)}, typed...)
+	}
+	return strings.Join(typed, ",")
+}
+
+// ValidateDependencies checks the typed entries of deps
+func ValidateDependencies(deps []string) error {
+	for _, dep := range deps {
+		typ, ids, typed := strings.Cut(strings.TrimSpace(dep), ":")
+		if !typed {
+			continue
+		}
+		if !slices.Contains(DependencyTypes, typ) {
+			return fmt.Errorf("unknown dependency type %q in %q (use %s)", typ, dep, strings.Join(DependencyTypes, ", "))
+		}
+		if ids == "" || slices.Contains(strings.Split(ids, ":"), "") {
+			return fmt.Errorf("dependency %q lacks a job ID", dep)
+		}
+	}
+	return nil
+}
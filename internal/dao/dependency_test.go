@@ -0,0 +1,22 @@
+package dao
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDependencyString(t *testing.T) {
+	assert.Equal(t, "", DependencyString(nil))
+	assert.Equal(t, "afterok:1:2", DependencyString([]string{"1", " 2 ", ""}))
+	assert.Equal(t, "afterok:3,afterany:1:2,afternotok:4",
+		DependencyString([]string{Dependency(DependencyAfterAny, "1", "2"), "3", "afternotok:4"}))
+}
+
+func TestValidateDependencies(t *testing.T) {
+	assert.NoError(t, ValidateDependencies([]string{"12", "afterany:13:14", "aftercorr:15"}))
+	assert.EqualError(t, ValidateDependencies([]string{"afterok:12", "afterfoo:13"}),
+		`unknown dependency type "afterfoo" in "afterfoo:13" (use after, afterany, afterok, afternotok, aftercorr)`)
+	assert.EqualError(t, ValidateDependencies([]string{"afterany:"}), `dependency "afterany:" lacks a job ID`)
+	assert.EqualError(t, ValidateDependencies([]string{"afterany:1::2"}), `dependency "afterany:1::2" lacks a job ID`)
+}
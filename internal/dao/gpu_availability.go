@@ -0,0 +1,98 @@
+package dao
+
+import (
+	"sort"
+)
+
+// NodeGPUs is the GPU capacity of one node for one GPU model
+type NodeGPUs struct {
+	Node       string
+	Partitions []string
+	Total      int
+	Free       int
+}
+
+// GPUAvailability is the cluster-wide capacity of one GPU model. GPUs on
+// nodes that cannot start jobs are counted as unavailable, not free.
+type GPUAvailability struct {
+	Type        string // GPU model, empty for untyped GPUs
+	Total       int
+	Allocated   int
+	Unavailable int // GPUs on down, drained or failed nodes
+	Free        int
+	Nodes       []NodeGPUs // Nodes that can start jobs, most free GPUs first
+}
+
+// Partitions returns the partitions of nodes with at least count free GPUs
+func (a *GPUAvailability) Partitions(count int) []string {
+	seen := make(map[string]bool)
+	var partitions []string
+	for _, n := range a.NodesWithFree(count) {
+		for _, p := range n.Partitions {
+			if !seen[p] {
+				seen[p] = true
+				partitions = append(partitions, p)
+			}
+		}
+	}
+	sort.Strings(partitions)
+	return partitions
+}
+
+// NodesWithFree returns the nodes that can provide count GPUs on their own
+func (a *GPUAvailability) NodesWithFree(count int) []NodeGPUs {
+	var nodes []NodeGPUs
+	for _, n := range a.Nodes {
+		if n.Free >= count {
+			nodes = append(nodes, n)
+		}
+	}
+	return nodes
+}
+
+// NodeAcceptsJobs reports whether the node state allows new jobs to start
+func NodeAcceptsJobs(state string) bool {
+	return ParseNodeState(state).AcceptsJobs()
+}
+
+// BuildGPUAvailability summarises the GPUs of all nodes per GPU model,
+// sorted by model name
+func BuildGPUAvailability(nodes []*Node) []*GPUAvailability {
+	byType := make(map[string]*GPUAvailability)
+	for _, node := range nodes {
+		accepts := NodeAcceptsJobs(node.State)
+		for _, gpu := range node.GPUs() {
+			a, ok := byType[gpu.Type]
+			if !ok {
+				a = &GPUAvailability{Type: gpu.Type}
+				byType[gpu.Type] = a
+			}
+			a.Total += gpu.Total
+			a.Allocated += gpu.Allocated
+			if !accepts {
+				a.Unavailable += gpu.Free()
+				continue
+			}
+			a.Free += gpu.Free()
+			a.Nodes = append(a.Nodes, NodeGPUs{
+				Node:       node.Name,
+				Partitions: node.Partitions,
+				Total:      gpu.Total,
+				Free:       gpu.Free(),
+			})
+		}
+	}
+
+	result := make([]*GPUAvailability, 0, len(byType))
+	for _, a := range byType {
+		sort.SliceStable(a.Nodes, func(i, j int) bool {
+			if a.Nodes[i].Free != a.Nodes[j].Free {
+				return a.Nodes[i].Free > a.Nodes[j].Free
+			}
+			return a.Nodes[i].Node < a.Nodes[j].Node
+		})
+		result = append(result, a)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Type < result[j].Type })
+	return result
+}
@@ -0,0 +1,46 @@
+package dao
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildGPUAvailability(t *testing.T) {
+	nodes := []*Node{
+		{Name: "g1", State: NodeStateMixed, Partitions: []string{"gpu"}, GRES: ParseGRES("gpu:a100:8", "gpu:a100:6")},
+		{Name: "g2", State: NodeStateIdle, Partitions: []string{"gpu", "debug"}, GRES: ParseGRES("gpu:a100:8", "")},
+		{Name: "g3", State: NodeStateDrain, Partitions: []string{"gpu"}, GRES: ParseGRES("gpu:a100:8", "")},
+		{Name: "g4", State: NodeStateIdle, Partitions: []string{"old"}, GRES: ParseGRES("gpu:v100:4", "")},
+		{Name: "c1", State: NodeStateIdle, Partitions: []string{"cpu"}},
+	}
+
+	avail := BuildGPUAvailability(nodes)
+	require.Len(t, avail, 2)
+
+	a100 := avail[0]
+	assert.Equal(t, "a100", a100.Type)
+	assert.Equal(t, 24, a100.Total)
+	assert.Equal(t, 6, a100.Allocated)
+	assert.Equal(t, 8, a100.Unavailable)
+	assert.Equal(t, 10, a100.Free)
+	require.Len(t, a100.Nodes, 2)
+	assert.Equal(t, "g2", a100.Nodes[0].Node, "most free GPUs first")
+
+	assert.Len(t, a100.NodesWithFree(4), 1)
+	assert.Equal(t, []string{"debug", "gpu"}, a100.Partitions(4))
+	assert.Equal(t, []string{"debug", "gpu"}, a100.Partitions(1))
+	assert.Empty(t, a100.Partitions(9))
+
+	assert.Equal(t, "v100", avail[1].Type)
+	assert.Equal(t, 4, avail[1].Free)
+}
+
+func TestNodeAcceptsJobs(t *testing.T) {
+	assert.True(t, NodeAcceptsJobs(NodeStateIdle))
+	assert.True(t, NodeAcceptsJobs("MIXED"))
+	assert.False(t, NodeAcceptsJobs("IDLE+DRAIN"))
+	assert.False(t, NodeAcceptsJobs("DOWN"))
+	assert.False(t, NodeAcceptsJobs("IDLE*"))
+}
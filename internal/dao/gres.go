@@ -0,0 +1,225 @@
+package dao
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// GRES is one generic resource of a node, such as the GPUs of one model
+type GRES struct {
+	Name      string // Resource name, e.g. "gpu", "shard" or "mps"
+	Type      string // Resource type, e.g. "a100" (empty if untyped)
+	Total     int    // Configured count
+	Allocated int    // Count in use by jobs
+	Indices   []int  // Indices of the allocated devices, when reported
+}
+
+// Free returns the count not in use by jobs
+func (g GRES) Free() int {
+	return max(g.Total-g.Allocated, 0)
+}
+
+// String formats the resource as SLURM does, e.g. "gpu:a100:8"
+func (g GRES) String() string {
+	if g.Type == "" {
+		return g.Name + ":" + strconv.Itoa(g.Total)
+	}
+	return g.Name + ":" + g.Type + ":" + strconv.Itoa(g.Total)
+}
+
+// ParseGRES combines a node's configured and used GRES strings, such as
+// "gpu:a100:8(S:0-1),shard:a100:32" and "gpu:a100:2(IDX:0,3),shard:a100:0",
+// into one entry per resource name and type
+func ParseGRES(configured, used string) []GRES {
+	var result []GRES
+	index := make(map[[2]string]int)
+	for _, g := range parseGRESList(configured) {
+		key := [2]string{g.Name, g.Type}
+		if i, ok := index[key]; ok {
+			result[i].Total += g.Total
+			continue
+		}
+		index[key] = len(result)
+		result = append(result, GRES{Name: g.Name, Type: g.Type, Total: g.Total})
+	}
+
+	for _, u := range parseGRESList(used) {
+		i, ok := index[[2]string{u.Name, u.Type}]
+		if !ok && u.Type == "" {
+			// An untyped usage entry belongs to the only configured type
+			i, ok = soleGRESOfName(result, u.Name)
+		}
+		if !ok {
+			continue
+		}
+		result[i].Allocated += u.Total
+		result[i].Indices = append(result[i].Indices, u.Indices...)
+	}
+
+	for i := range result {
+		sort.Ints(result[i].Indices)
+	}
+	return result
+}
+
+// soleGRESOfName returns the index of the only resource with the given name
+func soleGRESOfName(gres []GRES, name string) (int, bool) {
+	found := -1
+	for i, g := range gres {
+		if g.Name != name {
+			continue
+		}
+		if found >= 0 {
+			return 0, false
+		}
+		found = i
+	}
+	return found, found >= 0
+}
+
+// parseGRESList parses a comma separated GRES string. Commas inside
+// parenthesized details such as "(IDX:0,3)" do not separate entries.
+func parseGRESList(s string) []GRES {
+	var result []GRES
+	depth, start := 0, 0
+	for i := 0; i <= len(s); i++ {
+		if i < len(s) {
+			switch s[i] {
+			case '(':
+				depth++
+				continue
+			case ')':
+				depth--
+				continue
+			case ',':
+				if depth > 0 {
+					continue
+				}
+			default:
+				continue
+			}
+		}
+		if g, ok := parseGRESEntry(s[start:i]); ok {
+			result = append(result, g)
+		}
+		start = i + 1
+	}
+	return result
+}
+
+// parseGRESEntry parses a single entry such as "gpu:a100:2(IDX:0-1)"
+func parseGRESEntry(entry string) (GRES, bool) {
+	entry = strings.TrimSpace(entry)
+	var detail string
+	if open := strings.IndexByte(entry, '('); open >= 0 {
+		detail = strings.TrimSuffix(entry[open+1:], ")")
+		entry = entry[:open]
+	}
+	if entry == "" || entry == "(null)" {
+		return GRES{}, false
+	}
+
+	parts := strings.Split(entry, ":")
+	g := GRES{Name: parts[0], Total: 1}
+	switch len(parts) {
+	case 1:
+	case 2:
+		if count, ok := parseGRESCount(parts[1]); ok {
+			g.Total = count
+		} else {
+			g.Type = parts[1]
+		}
+	default:
+		g.Type = parts[1]
+		count, ok := parseGRESCount(parts[2])
+		if !ok {
+			return GRES{}, false
+		}
+		g.Total = count
+	}
+	if g.Type == "(null)" {
+		g.Type = ""
+	}
+
+	if idx, ok := strings.CutPrefix(detail, "IDX:"); ok {
+		g.Indices = parseIndexList(idx)
+	}
+	return g, true
+}
+
+// parseGRESCount parses a GRES count with an optional K/M/G multiplier
+func parseGRESCount(s string) (int, bool) {
+	multiplier := 1
+	switch {
+	case strings.HasSuffix(s, "K"):
+		multiplier = 1024
+	case strings.HasSuffix(s, "M"):
+		multiplier = 1024 * 1024
+	case strings.HasSuffix(s, "G"):
+		multiplier = 1024 * 1024 * 1024
+	}
+	if multiplier > 1 {
+		s = s[:len(s)-1]
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return n * multiplier, true
+}
+
+// parseIndexList parses a device index list such as "0-1,3", ignoring
+// entries that are not numeric such as "N/A"
+func parseIndexList(s string) []int {
+	var indices []int
+	for _, part := range strings.Split(s, ",") {
+		lo, hi, isRange := strings.Cut(part, "-")
+		from, err := strconv.Atoi(lo)
+		if err != nil {
+			continue
+		}
+		to := from
+		if isRange {
+			if to, err = strconv.Atoi(hi); err != nil || to < from {
+				continue
+			}
+		}
+		for i := from; i <= to; i++ {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
+// GPUs returns the node's GPU resources
+func (n *Node) GPUs() []GRES {
+	var gpus []GRES
+	for _, g := range n.GRES {
+		if g.Name == "gpu" {
+			gpus = append(gpus, g)
+		}
+	}
+	return gpus
+}
+
+// GPUCounts returns the total and allocated number of GPUs on the node
+func (n *Node) GPUCounts() (total, allocated int) {
+	for _, g := range n.GPUs() {
+		total += g.Total
+		allocated += g.Allocated
+	}
+	return total, allocated
+}
+
+// GPUTypes returns the GPU models of the node, sorted
+func (n *Node) GPUTypes() []string {
+	var types []string
+	for _, g := range n.GPUs() {
+		if g.Type != "" {
+			types = append(types, g.Type)
+		}
+	}
+	sort.Strings(types)
+	return types
+}
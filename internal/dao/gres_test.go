@@ -0,0 +1,69 @@
+package dao
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGRES_TypedWithIndices(t *testing.T) {
+	gres := ParseGRES("gpu:a100:8(S:0-1),shard:a100:32", "gpu:a100:3(IDX:0-1,5),shard:a100:4")
+	require.Len(t, gres, 2)
+
+	assert.Equal(t, GRES{Name: "gpu", Type: "a100", Total: 8, Allocated: 3, Indices: []int{0, 1, 5}}, gres[0])
+	assert.Equal(t, 5, gres[0].Free())
+	assert.Equal(t, "gpu:a100:8", gres[0].String())
+
+	assert.Equal(t, "shard", gres[1].Name)
+	assert.Equal(t, 32, gres[1].Total)
+	assert.Equal(t, 4, gres[1].Allocated)
+}
+
+func TestParseGRES_UntypedAndMixed(t *testing.T) {
+	gres := ParseGRES("gpu:4,mps:400", "gpu:1(IDX:2),mps:0")
+	require.Len(t, gres, 2)
+	assert.Equal(t, GRES{Name: "gpu", Total: 4, Allocated: 1, Indices: []int{2}}, gres[0])
+	assert.Equal(t, "gpu:4", gres[0].String())
+	assert.Equal(t, 400, gres[1].Total)
+
+	// Two models on one node, usage reported without a type is ambiguous
+	gres = ParseGRES("gpu:a100:2,gpu:v100:2", "gpu:1")
+	require.Len(t, gres, 2)
+	assert.Zero(t, gres[0].Allocated)
+	assert.Zero(t, gres[1].Allocated)
+
+	// A single model claims untyped usage
+	gres = ParseGRES("gpu:h100:4", "gpu:2(IDX:0-1)")
+	require.Len(t, gres, 1)
+	assert.Equal(t, 2, gres[0].Allocated)
+}
+
+func TestParseGRES_Empty(t *testing.T) {
+	assert.Empty(t, ParseGRES("", ""))
+	assert.Empty(t, ParseGRES("(null)", "(null)"))
+
+	gres := ParseGRES("gpu:a100:2", "gpu:a100:0(IDX:N/A)")
+	require.Len(t, gres, 1)
+	assert.Zero(t, gres[0].Allocated)
+	assert.Empty(t, gres[0].Indices)
+}
+
+func TestParseGRESCount(t *testing.T) {
+	count, ok := parseGRESCount("2K")
+	require.True(t, ok)
+	assert.Equal(t, 2048, count)
+
+	_, ok = parseGRESCount("a100")
+	assert.False(t, ok)
+}
+
+func TestNodeGPUs(t *testing.T) {
+	node := &Node{GRES: ParseGRES("gpu:v100:2,gpu:a100:4,shard:a100:16", "gpu:a100:1")}
+	assert.Len(t, node.GPUs(), 2)
+	assert.Equal(t, []string{"a100", "v100"}, node.GPUTypes())
+
+	total, allocated := node.GPUCounts()
+	assert.Equal(t, 6, total)
+	assert.Equal(t, 1, allocated)
+}
@@ -93,6 +93,13 @@ type PartitionManager interface {
 
 	// Get returns details for a specific partition
 	Get(name string) (*Partition, error)
+
+	// SetState sets the state of a partition to UP, DOWN, DRAIN or INACTIVE
+	SetState(name string, state string) error
+
+	// SetMaxTime sets the run time limit of jobs in a partition, given in
+	// minutes, [D-]HH:MM:SS form or as UNLIMITED
+	SetMaxTime(name string, limit string) error
 }
 
 // ReservationManager provides operations for managing SLURM reservations
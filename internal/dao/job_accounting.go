@@ -0,0 +1,210 @@
+package dao
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jontk/s9s/internal/debug"
+)
+
+const (
+	// accountingBatchSize is how many job IDs are asked for per request
+	accountingBatchSize = 200
+	// accountingRetryInterval is how long accounting is not asked again
+	// after a request failed, e.g. because slurmrestd has no slurmdbd
+	accountingRetryInterval = 5 * time.Minute
+)
+
+// jobAccounting reads the resource usage of jobs from slurmdbd through the
+// slurmdb endpoints of slurmrestd. The job records of slurmctld carry no
+// usage, so the CPU time, peak memory and average TRES usage of a job come
+// from here, as they do for sacct.
+type jobAccounting struct {
+	baseURL      string
+	version      string // API version, e.g. "v0.0.42"
+	client       *http.Client
+	authenticate func(ctx context.Context, req *http.Request) error
+
+	mu      sync.Mutex
+	retryAt time.Time
+}
+
+// accountingJobsResponse is the part of a slurmdb jobs response that holds
+// usage
+type accountingJobsResponse struct {
+	Jobs []accountingJob `json:"jobs"`
+}
+
+type accountingJob struct {
+	JobID int64 `json:"job_id"`
+	Time  struct {
+		Total accountingDuration `json:"total"`
+	} `json:"time"`
+	Steps []accountingStep `json:"steps"`
+}
+
+type accountingStep struct {
+	Time struct {
+		Total accountingDuration `json:"total"`
+	} `json:"time"`
+	TRES struct {
+		Requested struct {
+			Max     []accountingTRES `json:"max"`
+			Average []accountingTRES `json:"average"`
+		} `json:"requested"`
+	} `json:"tres"`
+}
+
+type accountingDuration struct {
+	Seconds      int64 `json:"seconds"`
+	Microseconds int64 `json:"microseconds"`
+}
+
+func (d accountingDuration) duration() time.Duration {
+	return time.Duration(d.Seconds)*time.Second + time.Duration(d.Microseconds)*time.Microsecond
+}
+
+type accountingTRES struct {
+	Type  string `json:"type"`
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+// key returns the TRES name as written in TRES strings, e.g. "gres/gpuutil"
+func (t accountingTRES) key() string {
+	if t.Name == "" {
+		return strings.ToLower(t.Type)
+	}
+	return strings.ToLower(t.Type) + "/" + t.Name
+}
+
+// fill sets the usage of the jobs that have started. Failures are logged
+// and leave the usage empty, as the jobs are still worth showing.
+func (a *jobAccounting) fill(ctx context.Context, jobs []*Job) {
+	if a == nil {
+		return
+	}
+	byID := make(map[string]*Job)
+	for _, job := range jobs {
+		if job.StartTime != nil && job.State != JobStatePending {
+			byID[job.ID] = job
+		}
+	}
+	if len(byID) == 0 || !a.available() {
+		return
+	}
+
+	ids := make([]string, 0, len(byID))
+	for id := range byID {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for start := 0; start < len(ids); start += accountingBatchSize {
+		batch := ids[start:min(start+accountingBatchSize, len(ids))]
+		records, err := a.fetch(ctx, batch)
+		if err != nil {
+			debug.Logger.Printf("Job accounting unavailable, retrying in %s: %v", accountingRetryInterval, err)
+			a.mu.Lock()
+			a.retryAt = time.Now().Add(accountingRetryInterval)
+			a.mu.Unlock()
+			return
+		}
+		for _, record := range records {
+			if job, ok := byID[strconv.FormatInt(record.JobID, 10)]; ok {
+				applyAccounting(job, record)
+			}
+		}
+	}
+}
+
+// available reports whether accounting may be asked, i.e. it did not fail
+// recently
+func (a *jobAccounting) available() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return time.Now().After(a.retryAt)
+}
+
+// fetch reads the accounting records of the given job IDs
+func (a *jobAccounting) fetch(ctx context.Context, ids []string) ([]accountingJob, error) {
+	endpoint := fmt.Sprintf("%s/slurmdb/%s/jobs/?%s", strings.TrimSuffix(a.baseURL, "/"), a.version,
+		url.Values{"step": {strings.Join(ids, ",")}}.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if a.authenticate != nil {
+		if err := a.authenticate(ctx, req); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", req.URL.Path, resp.Status)
+	}
+
+	var result accountingJobsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("GET %s: %w", req.URL.Path, err)
+	}
+	return result.Jobs, nil
+}
+
+// applyAccounting sets the usage of job from its accounting record, the way
+// sacct reports it: the CPU time of all steps, the largest peak memory of
+// any step and, per TRES, the highest average of any step
+func applyAccounting(job *Job, record accountingJob) {
+	totalCPU := record.Time.Total.duration()
+	if totalCPU == 0 {
+		for _, step := range record.Steps {
+			totalCPU += step.Time.Total.duration()
+		}
+	}
+
+	var maxRSS int64
+	averages := make(map[string]int64)
+	for _, step := range record.Steps {
+		for _, tres := range step.TRES.Requested.Max {
+			if tres.key() == "mem" {
+				maxRSS = max(maxRSS, tres.Count)
+			}
+		}
+		for _, tres := range step.TRES.Requested.Average {
+			averages[tres.key()] = max(averages[tres.key()], tres.Count)
+		}
+	}
+
+	if totalCPU > 0 {
+		job.TotalCPU = totalCPU
+	}
+	if maxRSS > 0 {
+		job.MaxRSS = maxRSS / (1024 * 1024) // Bytes to MB
+	}
+	if len(averages) > 0 {
+		keys := make([]string, 0, len(averages))
+		for key := range averages {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		usage := make([]string, len(keys))
+		for i, key := range keys {
+			usage[i] = fmt.Sprintf("%s=%d", key, averages[key])
+		}
+		job.TRESUsageAve = strings.Join(usage, ",")
+	}
+}
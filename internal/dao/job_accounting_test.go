@@ -0,0 +1,128 @@
+package dao
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	slurm "github.com/jontk/slurm-client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slurmdbJobsResponse is a trimmed slurmdb jobs response for job 4242:
+// a batch step and an srun step on two GPUs
+const slurmdbJobsResponse = `{
+  "jobs": [{
+    "job_id": 4242,
+    "name": "train",
+    "time": {"elapsed": 3600, "total": {"seconds": 21600, "microseconds": 500000}},
+    "steps": [
+      {
+        "step": {"id": "4242.batch", "name": "batch"},
+        "time": {"total": {"seconds": 60, "microseconds": 0}},
+        "tres": {"requested": {
+          "max": [{"type": "cpu", "id": 1, "count": 60000}, {"type": "mem", "id": 2, "count": 536870912}],
+          "average": [{"type": "cpu", "id": 1, "count": 60000}, {"type": "gres", "name": "gpuutil", "id": 1002, "count": 0}]
+        }}
+      },
+      {
+        "step": {"id": "4242.0", "name": "python"},
+        "time": {"total": {"seconds": 21540, "microseconds": 500000}},
+        "tres": {"requested": {
+          "max": [{"type": "cpu", "id": 1, "count": 21540000}, {"type": "mem", "id": 2, "count": 12884901888}],
+          "average": [{"type": "cpu", "id": 1, "count": 10770000}, {"type": "gres", "name": "gpuutil", "id": 1002, "count": 63}]
+        }}
+      }
+    ]
+  }]
+}`
+
+// fakeSlurmJobs lists fixed slurmctld job records
+type fakeSlurmJobs struct {
+	slurm.JobManager
+	jobs []slurm.Job
+}
+
+func (f *fakeSlurmJobs) List(_ context.Context, _ *slurm.ListJobsOptions) (*slurm.JobList, error) {
+	return &slurm.JobList{Jobs: f.jobs, Total: len(f.jobs)}, nil
+}
+
+func TestJobManager_ListFillsUsageFromAccounting(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/slurmdb/v0.0.42/jobs/", r.URL.Path)
+		assert.Equal(t, "alice", r.Header.Get("X-SLURM-USER-NAME"))
+		query = r.URL.Query().Get("step")
+		_, _ = w.Write([]byte(slurmdbJobsResponse))
+	}))
+	defer server.Close()
+
+	id, pendingID, cpus, memory := int32(4242), int32(4243), uint32(8), uint64(16384)
+	name, user := "train", "alice"
+	manager := &jobManager{
+		client: &fakeSlurmJobs{jobs: []slurm.Job{
+			{
+				JobID:         &id,
+				Name:          &name,
+				UserName:      &user,
+				JobState:      []slurm.JobState{"RUNNING"},
+				StartTime:     time.Now().Add(-time.Hour),
+				CPUs:          &cpus,
+				MemoryPerNode: &memory,
+				TRESAllocStr:  stringPtr("cpu=8,mem=16G,node=1,billing=8,gres/gpu=2"),
+			},
+			{JobID: &pendingID, UserName: &user, JobState: []slurm.JobState{"PENDING"}},
+		}},
+		ctx: context.Background(),
+		accounting: &jobAccounting{
+			baseURL: server.URL,
+			version: "v0.0.42",
+			client:  server.Client(),
+			authenticate: func(_ context.Context, req *http.Request) error {
+				req.Header.Set("X-SLURM-USER-NAME", "alice")
+				return nil
+			},
+		},
+	}
+
+	list, err := manager.List(&ListJobsOptions{})
+	require.NoError(t, err)
+	require.Len(t, list.Jobs, 2)
+	assert.Equal(t, "4242", query, "only started jobs are looked up")
+
+	job := list.Jobs[0]
+	assert.Equal(t, 8, job.CPUs)
+	assert.Equal(t, int64(16384), job.MemoryPerNode)
+	assert.Equal(t, 6*time.Hour+500*time.Millisecond, job.TotalCPU)
+	assert.Equal(t, int64(12288), job.MaxRSS)
+	assert.Equal(t, "cpu=10770000,gres/gpuutil=63", job.TRESUsageAve)
+
+	pending := list.Jobs[1]
+	assert.Zero(t, pending.TotalCPU)
+	assert.Zero(t, pending.MaxRSS)
+}
+
+func TestJobAccounting_BacksOffAfterFailure(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+		http.Error(w, "slurmdbd not configured", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	accounting := &jobAccounting{baseURL: server.URL, version: "v0.0.42", client: server.Client()}
+	start := time.Now().Add(-time.Minute)
+	jobs := []*Job{{ID: "1", State: JobStateRunning, StartTime: &start}}
+
+	accounting.fill(context.Background(), jobs)
+	accounting.fill(context.Background(), jobs)
+	assert.Equal(t, 1, requests)
+	assert.Zero(t, jobs[0].TotalCPU)
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
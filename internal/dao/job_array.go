@@ -0,0 +1,220 @@
+package dao
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxArrayTasks bounds how many task IDs an array expression may expand to
+const maxArrayTasks = 1_000_000
+
+// jobStateShort maps job states to the short codes squeue prints
+var jobStateShort = map[string]string{
+	JobStatePending:     "PD",
+	JobStateRunning:     "R",
+	JobStateSuspended:   "S",
+	JobStateCompleting:  "CG",
+	JobStateConfiguring: "CF",
+	JobStateCompleted:   "CD",
+	JobStateCancelled:   "CA",
+	JobStateFailed:      "F",
+	JobStateTimeout:     "TO",
+	JobStatePreempted:   "PR",
+	"NODE_FAIL":         "NF",
+	"OUT_OF_MEMORY":     "OOM",
+	"BOOT_FAIL":         "BF",
+	"DEADLINE":          "DL",
+}
+
+// stateSummaryOrder is the order states appear in in an array summary
+var stateSummaryOrder = []string{
+	JobStateRunning, JobStatePending, JobStateSuspended, JobStateCompleting,
+	JobStateConfiguring, JobStateFailed, JobStateTimeout, "NODE_FAIL",
+	"OUT_OF_MEMORY", "BOOT_FAIL", "DEADLINE", JobStatePreempted,
+	JobStateCancelled, JobStateCompleted,
+}
+
+// JobStateShort returns the squeue code of a job state, e.g. "PD" for
+// PENDING, or the state itself when it has none
+func JobStateShort(state string) string {
+	if short, ok := jobStateShort[state]; ok {
+		return short
+	}
+	return state
+}
+
+// IsFailedJobState reports whether a job in state ended unsuccessfully and
+// is worth requeueing
+func IsFailedJobState(state string) bool {
+	switch state {
+	case JobStateFailed, JobStateTimeout, "NODE_FAIL", "OUT_OF_MEMORY", "BOOT_FAIL", "DEADLINE":
+		return true
+	}
+	return false
+}
+
+// ParseArrayTaskIDs expands a SLURM array task expression such as
+// "0-9,15,20-30:2%4" into task IDs. The "%N" concurrency limit is ignored.
+func ParseArrayTaskIDs(expr string) ([]int, error) {
+	expr = strings.TrimSpace(expr)
+	if i := strings.IndexByte(expr, '%'); i >= 0 {
+		expr = expr[:i]
+	}
+	expr = strings.TrimSuffix(strings.TrimPrefix(expr, "["), "]")
+	if expr == "" {
+		return nil, fmt.Errorf("empty array task expression")
+	}
+
+	var ids []int
+	for _, part := range strings.Split(expr, ",") {
+		part = strings.TrimSpace(part)
+		step := 1
+		if rangePart, stepPart, ok := strings.Cut(part, ":"); ok {
+			s, err := strconv.Atoi(stepPart)
+			if err != nil || s < 1 {
+				return nil, fmt.Errorf("invalid step in %q", part)
+			}
+			part, step = rangePart, s
+		}
+
+		first, last, isRange := strings.Cut(part, "-")
+		start, err := strconv.Atoi(first)
+		if err != nil || start < 0 {
+			return nil, fmt.Errorf("invalid task ID %q", first)
+		}
+		end := start
+		if isRange {
+			if end, err = strconv.Atoi(last); err != nil || end < start {
+				return nil, fmt.Errorf("invalid task range %q", part)
+			}
+		}
+		if len(ids)+(end-start)/step+1 > maxArrayTasks {
+			return nil, fmt.Errorf("array expression %q has more than %d tasks", expr, maxArrayTasks)
+		}
+		for id := start; id <= end; id += step {
+			ids = append(ids, id)
+		}
+	}
+
+	slices.Sort(ids)
+	return slices.Compact(ids), nil
+}
+
+// JobArraySummary aggregates the tasks of one job array. Pending tasks that
+// have not been split off yet are reported by a single record whose
+// ArrayTaskString covers all of them.
+type JobArraySummary struct {
+	ArrayJobID  string
+	Jobs        []*Job         // Task records in the order they were listed
+	TaskCount   int            // Tasks, counting every task of a pending record
+	StateCounts map[string]int // Tasks per state
+	Runtime     time.Duration  // Elapsed time of every started task, summed
+}
+
+// SummarizeJobArray builds the summary of the tasks of an array
+func SummarizeJobArray(arrayJobID string, jobs []*Job, now time.Time) *JobArraySummary {
+	summary := &JobArraySummary{
+		ArrayJobID:  arrayJobID,
+		Jobs:        jobs,
+		StateCounts: make(map[string]int),
+	}
+	for _, job := range jobs {
+		tasks := len(job.taskIDs())
+		summary.TaskCount += tasks
+		summary.StateCounts[job.State] += tasks
+
+		if job.StartTime == nil || job.StartTime.IsZero() {
+			continue
+		}
+		end := now
+		if job.EndTime != nil && !job.EndTime.IsZero() && job.EndTime.After(*job.StartTime) {
+			end = *job.EndTime
+		}
+		if elapsed := end.Sub(*job.StartTime); elapsed > 0 {
+			summary.Runtime += elapsed
+		}
+	}
+	return summary
+}
+
+// taskIDs returns the task IDs a record stands for; a record that is not
+// a pending range stands for one task even without a known ID
+func (j *Job) taskIDs() []int {
+	if j.ArrayTaskID == "" && j.ArrayTaskString != "" {
+		if ids, err := ParseArrayTaskIDs(j.ArrayTaskString); err == nil {
+			return ids
+		}
+	}
+	id, err := strconv.Atoi(j.ArrayTaskID)
+	if err != nil {
+		return []int{-1}
+	}
+	return []int{id}
+}
+
+// StateSummary formats the task counts like "4821 R / 5000 PD / 12 F"
+func (s *JobArraySummary) StateSummary() string {
+	states := make([]string, 0, len(s.StateCounts))
+	for state := range s.StateCounts {
+		states = append(states, state)
+	}
+	slices.SortFunc(states, func(a, b string) int {
+		ia, ib := slices.Index(stateSummaryOrder, a), slices.Index(stateSummaryOrder, b)
+		if ia < 0 {
+			ia = len(stateSummaryOrder)
+		}
+		if ib < 0 {
+			ib = len(stateSummaryOrder)
+		}
+		if ia != ib {
+			return ia - ib
+		}
+		return strings.Compare(a, b)
+	})
+
+	parts := make([]string, len(states))
+	for i, state := range states {
+		parts[i] = fmt.Sprintf("%d %s", s.StateCounts[state], JobStateShort(state))
+	}
+	return strings.Join(parts, " / ")
+}
+
+// FailedTaskIDs returns the job IDs of the tasks that ended unsuccessfully
+func (s *JobArraySummary) FailedTaskIDs() []string {
+	var ids []string
+	for _, job := range s.Jobs {
+		if IsFailedJobState(job.State) {
+			ids = append(ids, job.ID)
+		}
+	}
+	return ids
+}
+
+// TaskTargets returns the IDs to act on for the given task IDs: the job ID
+// of a task that has its own record, or ARRAYID_TASKID for a task that is
+// still part of a pending range. Task IDs not in the array are skipped.
+func (s *JobArraySummary) TaskTargets(taskIDs []int) []string {
+	wanted := make(map[int]bool, len(taskIDs))
+	for _, id := range taskIDs {
+		wanted[id] = true
+	}
+
+	var targets []string
+	for _, job := range s.Jobs {
+		if job.ArrayTaskID != "" {
+			if id, err := strconv.Atoi(job.ArrayTaskID); err == nil && wanted[id] {
+				targets = append(targets, job.ID)
+			}
+			continue
+		}
+		for _, id := range job.taskIDs() {
+			if id >= 0 && wanted[id] {
+				targets = append(targets, fmt.Sprintf("%s_%d", s.ArrayJobID, id))
+			}
+		}
+	}
+	return targets
+}
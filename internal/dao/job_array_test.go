@@ -0,0 +1,66 @@
+package dao
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseArrayTaskIDs(t *testing.T) {
+	tests := []struct {
+		expr     string
+		expected []int
+	}{
+		{"3", []int{3}},
+		{"0-4", []int{0, 1, 2, 3, 4}},
+		{"1-3,7,5", []int{1, 2, 3, 5, 7}},
+		{"0-10:5", []int{0, 5, 10}},
+		{"4-6%2", []int{4, 5, 6}},
+		{"[1-2,2]", []int{1, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			ids, err := ParseArrayTaskIDs(tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, ids)
+		})
+	}
+
+	for _, bad := range []string{"", "a", "5-1", "1-4:0", "-3", "0-2000000"} {
+		_, err := ParseArrayTaskIDs(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestSummarizeJobArray(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	started := now.Add(-time.Hour)
+	ended := now.Add(-30 * time.Minute)
+
+	jobs := []*Job{
+		{ID: "101", ArrayJobID: "100", ArrayTaskID: "1", State: JobStateRunning, StartTime: &started},
+		{ID: "102", ArrayJobID: "100", ArrayTaskID: "2", State: JobStateFailed, StartTime: &started, EndTime: &ended},
+		{ID: "103", ArrayJobID: "100", ArrayTaskID: "3", State: JobStateCompleted, StartTime: &started, EndTime: &ended},
+		{ID: "104", ArrayJobID: "100", ArrayTaskID: "4", State: JobStateTimeout, StartTime: &started, EndTime: &ended},
+		{ID: "100", ArrayJobID: "100", ArrayTaskString: "5-9%2", State: JobStatePending},
+	}
+
+	summary := SummarizeJobArray("100", jobs, now)
+	assert.Equal(t, 9, summary.TaskCount)
+	assert.Equal(t, 5, summary.StateCounts[JobStatePending])
+	assert.Equal(t, "1 R / 5 PD / 1 F / 1 TO / 1 CD", summary.StateSummary())
+	assert.Equal(t, 2*time.Hour+30*time.Minute, summary.Runtime)
+
+	assert.Equal(t, []string{"102", "104"}, summary.FailedTaskIDs())
+	assert.Equal(t, []string{"102", "100_6", "100_7"}, summary.TaskTargets([]int{2, 6, 7, 42}))
+}
+
+func TestJobStateShort(t *testing.T) {
+	assert.Equal(t, "PD", JobStateShort(JobStatePending))
+	assert.Equal(t, "OOM", JobStateShort("OUT_OF_MEMORY"))
+	assert.Equal(t, "REQUEUE_HOLD", JobStateShort("REQUEUE_HOLD"))
+	assert.True(t, IsFailedJobState("NODE_FAIL"))
+	assert.False(t, IsFailedJobState(JobStateCancelled))
+}
@@ -0,0 +1,245 @@
+package dao
+
+import (
+	"slices"
+	"strings"
+)
+
+// Additional base node states
+const (
+	NodeStateError   = "ERROR"
+	NodeStateFuture  = "FUTURE"
+	NodeStateUnknown = "UNKNOWN"
+)
+
+// Node state flags reported alongside the base state, e.g. "IDLE+DRAIN"
+const (
+	NodeFlagDrain           = "DRAIN"
+	NodeFlagCompleting      = "COMPLETING"
+	NodeFlagNotResponding   = "NOT_RESPONDING"
+	NodeFlagFail            = "FAIL"
+	NodeFlagMaintenance     = "MAINTENANCE"
+	NodeFlagReserved        = "RESERVED"
+	NodeFlagCloud           = "CLOUD"
+	NodeFlagPoweredDown     = "POWERED_DOWN"
+	NodeFlagPoweringUp      = "POWERING_UP"
+	NodeFlagPoweringDown    = "POWERING_DOWN"
+	NodeFlagRebootRequested = "REBOOT_REQUESTED"
+	NodeFlagRebootIssued    = "REBOOT_ISSUED"
+	NodeFlagPlanned         = "PLANNED"
+)
+
+// baseNodeStates are the states that can be a node's base state
+var baseNodeStates = []string{
+	NodeStateIdle, NodeStateAllocated, NodeStateMixed, NodeStateDown,
+	NodeStateError, NodeStateFuture, NodeStateUnknown,
+}
+
+// nodeStateAliases maps abbreviated and derived state names to their
+// canonical base state or flag
+var nodeStateAliases = map[string]string{
+	"ALLOC":          NodeStateAllocated,
+	"MIX":            NodeStateMixed,
+	"DRAINED":        NodeFlagDrain,
+	"DRAINING":       NodeFlagDrain,
+	"DRNG":           NodeFlagDrain,
+	"DRNG_COMPLETE":  NodeFlagDrain,
+	"COMP":           NodeFlagCompleting,
+	"MAINT":          NodeFlagMaintenance,
+	"RESV":           NodeFlagReserved,
+	"FAILING":        NodeFlagFail,
+	"NO_RESPOND":     NodeFlagNotResponding,
+	"POWER_DOWN":     NodeFlagPoweringDown,
+	"POWERED_OFF":    NodeFlagPoweredDown,
+	"REBOOT":         NodeFlagRebootRequested,
+	"REBOOT_PENDING": NodeFlagRebootRequested,
+}
+
+// nodeStateSuffixes maps sinfo short-form suffixes to the flag they denote
+var nodeStateSuffixes = map[byte]string{
+	'*': NodeFlagNotResponding,
+	'~': NodeFlagPoweredDown,
+	'#': NodeFlagPoweringUp,
+	'%': NodeFlagPoweringDown,
+	'!': NodeFlagPoweringDown,
+	'$': NodeFlagMaintenance,
+	'@': NodeFlagRebootRequested,
+	'^': NodeFlagRebootIssued,
+	'-': NodeFlagPlanned,
+}
+
+// NodeState is a node's base state together with its state flags
+type NodeState struct {
+	Base  string   // One of IDLE, ALLOCATED, MIXED, DOWN, ERROR, FUTURE or UNKNOWN; empty if not reported
+	Flags []string // Flags in the order SLURM reported them, e.g. DRAIN, COMPLETING
+}
+
+// ParseNodeState parses a compound node state such as "MIXED+DRAIN",
+// "IDLE+CLOUD+POWERED_DOWN" or the sinfo short form "idle*"
+func ParseNodeState(state string) NodeState {
+	var s NodeState
+	for _, token := range strings.FieldsFunc(strings.ToUpper(state), func(r rune) bool {
+		return r == '+' || r == ',' || r == ' '
+	}) {
+		for len(token) > 1 {
+			flag, ok := nodeStateSuffixes[token[len(token)-1]]
+			if !ok {
+				break
+			}
+			s.addFlag(flag)
+			token = token[:len(token)-1]
+		}
+		if alias, ok := nodeStateAliases[token]; ok {
+			token = alias
+		}
+		if s.Base == "" && slices.Contains(baseNodeStates, token) {
+			s.Base = token
+			continue
+		}
+		s.addFlag(token)
+	}
+	return s
+}
+
+// addFlag adds a flag unless it is already set
+func (s *NodeState) addFlag(flag string) {
+	if flag != "" && !s.Has(flag) {
+		s.Flags = append(s.Flags, flag)
+	}
+}
+
+// Has reports whether the flag is set
+func (s NodeState) Has(flag string) bool {
+	return slices.Contains(s.Flags, flag)
+}
+
+// WithFlag returns the state with the flag set
+func (s NodeState) WithFlag(flag string) NodeState {
+	s.Flags = slices.Clone(s.Flags)
+	s.addFlag(flag)
+	return s
+}
+
+// WithoutFlag returns the state with the flag cleared
+func (s NodeState) WithoutFlag(flag string) NodeState {
+	s.Flags = slices.DeleteFunc(slices.Clone(s.Flags), func(f string) bool { return f == flag })
+	return s
+}
+
+// String formats the state as SLURM does, e.g. "IDLE+DRAIN"
+func (s NodeState) String() string {
+	parts := make([]string, 0, len(s.Flags)+1)
+	if s.Base != "" {
+		parts = append(parts, s.Base)
+	}
+	return strings.Join(append(parts, s.Flags...), "+")
+}
+
+// IsDown reports whether the node is down or not responding
+func (s NodeState) IsDown() bool {
+	return s.Base == NodeStateDown || s.Has(NodeFlagNotResponding)
+}
+
+// IsDrain reports whether the node is drained or draining
+func (s NodeState) IsDrain() bool {
+	return s.Has(NodeFlagDrain)
+}
+
+// IsDraining reports whether the node is drained but still runs jobs
+func (s NodeState) IsDraining() bool {
+	return s.IsDrain() && (s.Base == NodeStateAllocated || s.Base == NodeStateMixed || s.Has(NodeFlagCompleting))
+}
+
+// IsPoweredDown reports whether the node is powered down or changing power state
+func (s NodeState) IsPoweredDown() bool {
+	return s.Has(NodeFlagPoweredDown) || s.Has(NodeFlagPoweringUp) || s.Has(NodeFlagPoweringDown)
+}
+
+// AcceptsJobs reports whether new jobs can be started on the node. Powered
+// down cloud nodes accept jobs because SLURM resumes them on demand.
+func (s NodeState) AcceptsJobs() bool {
+	if s.IsDown() || s.IsDrain() || s.Has(NodeFlagFail) || s.Has(NodeFlagMaintenance) {
+		return false
+	}
+	switch s.Base {
+	case NodeStateDown, NodeStateError, NodeStateFuture, NodeStateUnknown, "":
+		return false
+	}
+	return true
+}
+
+// Summary returns the single state that best describes the node, as sinfo
+// does in its long form: DOWN, FAIL, DRAINING, DRAIN (drained),
+// MAINTENANCE, RESERVED, POWERED_DOWN, COMPLETING or the base state
+func (s NodeState) Summary() string {
+	switch {
+	case s.IsDown():
+		return NodeStateDown
+	case s.Has(NodeFlagFail):
+		return NodeFlagFail
+	case s.IsDraining():
+		return NodeStateDraining
+	case s.IsDrain():
+		return NodeStateDrain
+	case s.Has(NodeFlagMaintenance):
+		return NodeStateMaintenance
+	case s.Has(NodeFlagReserved):
+		return NodeStateReserved
+	case s.IsPoweredDown():
+		return NodeFlagPoweredDown
+	case s.Has(NodeFlagCompleting):
+		return NodeFlagCompleting
+	case s.Base == "":
+		return NodeStateUnknown
+	default:
+		return s.Base
+	}
+}
+
+// Matches reports whether the node matches a state filter. A filter matches
+// the base state, any flag or the summary state, so "IDLE" matches
+// "IDLE+DRAIN" and "DRAIN" matches both drained and draining nodes.
+func (s NodeState) Matches(filter string) bool {
+	f := ParseNodeState(filter)
+	if f.Base != "" && f.Base != s.Base {
+		return false
+	}
+	for _, flag := range f.Flags {
+		if !s.Has(flag) {
+			return false
+		}
+	}
+	// "DRAINING" and "DRAINED" select one side of the drain flag
+	switch strings.ToUpper(strings.TrimSpace(filter)) {
+	case NodeStateDraining:
+		return s.IsDraining()
+	case "DRAINED":
+		return s.IsDrain() && !s.IsDraining()
+	}
+	return f.Base != "" || len(f.Flags) > 0
+}
+
+// Color returns the display color of the state
+func (s NodeState) Color() string {
+	switch {
+	case s.IsDown(), s.IsDrain(), s.Has(NodeFlagFail):
+		return "red"
+	case s.Has(NodeFlagMaintenance):
+		return "orange"
+	case s.Has(NodeFlagReserved):
+		return "yellow"
+	case s.IsPoweredDown():
+		return "gray"
+	case s.Base == NodeStateAllocated, s.Base == NodeStateMixed:
+		return "blue"
+	case s.Base == NodeStateIdle:
+		return "green"
+	default:
+		return "white"
+	}
+}
+
+// ParsedState returns the node's structured state
+func (n *Node) ParsedState() NodeState {
+	return ParseNodeState(n.State)
+}
@@ -0,0 +1,96 @@
+package dao
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseNodeState(t *testing.T) {
+	tests := []struct {
+		input string
+		base  string
+		flags []string
+		str   string
+	}{
+		{"IDLE", NodeStateIdle, nil, "IDLE"},
+		{"IDLE+DRAIN", NodeStateIdle, []string{NodeFlagDrain}, "IDLE+DRAIN"},
+		{"MIXED+COMPLETING", NodeStateMixed, []string{NodeFlagCompleting}, "MIXED+COMPLETING"},
+		{"DOWN+NOT_RESPONDING", NodeStateDown, []string{NodeFlagNotResponding}, "DOWN+NOT_RESPONDING"},
+		{"IDLE+CLOUD+POWERED_DOWN", NodeStateIdle, []string{NodeFlagCloud, NodeFlagPoweredDown}, "IDLE+CLOUD+POWERED_DOWN"},
+		{"idle*", NodeStateIdle, []string{NodeFlagNotResponding}, "IDLE+NOT_RESPONDING"},
+		{"alloc~", NodeStateAllocated, []string{NodeFlagPoweredDown}, "ALLOCATED+POWERED_DOWN"},
+		{"DRAINING", "", []string{NodeFlagDrain}, "DRAIN"},
+		{"", "", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			s := ParseNodeState(tt.input)
+			assert.Equal(t, tt.base, s.Base)
+			assert.Equal(t, tt.flags, s.Flags)
+			assert.Equal(t, tt.str, s.String())
+		})
+	}
+}
+
+func TestNodeStateSummary(t *testing.T) {
+	tests := map[string]string{
+		"IDLE":                    NodeStateIdle,
+		"IDLE+DRAIN":              NodeStateDrain,
+		"MIXED+DRAIN":             NodeStateDraining,
+		"ALLOCATED+DRAIN":         NodeStateDraining,
+		"IDLE+DRAIN+COMPLETING":   NodeStateDraining,
+		"MIXED+COMPLETING":        NodeFlagCompleting,
+		"DOWN+DRAIN":              NodeStateDown,
+		"IDLE+NOT_RESPONDING":     NodeStateDown,
+		"IDLE+CLOUD+POWERED_DOWN": NodeFlagPoweredDown,
+		"IDLE+MAINTENANCE":        NodeStateMaintenance,
+		"MIXED+FAIL":              NodeFlagFail,
+		"":                        NodeStateUnknown,
+	}
+	for input, want := range tests {
+		assert.Equal(t, want, ParseNodeState(input).Summary(), input)
+	}
+}
+
+func TestNodeStateMatches(t *testing.T) {
+	drained := ParseNodeState("IDLE+DRAIN")
+	draining := ParseNodeState("MIXED+DRAIN")
+
+	assert.True(t, drained.Matches("IDLE"))
+	assert.True(t, drained.Matches("drain"))
+	assert.True(t, drained.Matches("DRAINED"))
+	assert.False(t, drained.Matches("DRAINING"))
+	assert.False(t, drained.Matches("MIXED"))
+
+	assert.True(t, draining.Matches("DRAIN"))
+	assert.True(t, draining.Matches("DRAINING"))
+	assert.False(t, draining.Matches("DRAINED"))
+
+	assert.True(t, ParseNodeState("DOWN+NOT_RESPONDING").Matches("NOT_RESPONDING"))
+	assert.False(t, ParseNodeState("IDLE").Matches(""))
+}
+
+func TestNodeStateAcceptsJobs(t *testing.T) {
+	assert.True(t, ParseNodeState("IDLE").AcceptsJobs())
+	assert.True(t, ParseNodeState("MIXED+COMPLETING").AcceptsJobs())
+	assert.True(t, ParseNodeState("IDLE+CLOUD+POWERED_DOWN").AcceptsJobs(), "cloud nodes are resumed on demand")
+	assert.False(t, ParseNodeState("IDLE+DRAIN").AcceptsJobs())
+	assert.False(t, ParseNodeState("IDLE+MAINTENANCE").AcceptsJobs())
+	assert.False(t, ParseNodeState("FUTURE").AcceptsJobs())
+}
+
+func TestNodeStateWithFlag(t *testing.T) {
+	s := ParseNodeState("MIXED")
+	drained := s.WithFlag(NodeFlagDrain)
+	assert.Equal(t, "MIXED+DRAIN", drained.String())
+	assert.Equal(t, "MIXED", s.String(), "original is not modified")
+	assert.Equal(t, "MIXED", drained.WithoutFlag(NodeFlagDrain).String())
+}
+
+func TestNodeStateColor(t *testing.T) {
+	assert.Equal(t, "gray", GetNodeStateColor("IDLE+CLOUD+POWERED_DOWN"))
+	assert.Equal(t, "red", GetNodeStateColor("IDLE*"))
+	assert.Equal(t, "blue", GetNodeStateColor("MIXED+COMPLETING"))
+}
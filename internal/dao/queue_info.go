@@ -0,0 +1,71 @@
+package dao
+
+import "time"
+
+// BuildQueueInfo computes queue info for all partitions from a single jobs list.
+// Wait times of pending jobs are measured up to now.
+func BuildQueueInfo(jobs []*Job, partitions []*Partition, now time.Time) map[string]*QueueInfo {
+	// Build CPUs-per-node ratio map for allocated CPU estimation
+	cpusPerNode := make(map[string]float64, len(partitions))
+	for _, p := range partitions {
+		if p.TotalNodes > 0 {
+			cpusPerNode[p.Name] = float64(p.TotalCPUs) / float64(p.TotalNodes)
+		} else {
+			cpusPerNode[p.Name] = 1.0
+		}
+	}
+
+	// Initialize QueueInfo for every partition
+	infoMap := make(map[string]*QueueInfo, len(partitions))
+	for _, p := range partitions {
+		infoMap[p.Name] = &QueueInfo{Partition: p.Name}
+	}
+
+	// Per-partition wait time accumulators
+	type waitAcc struct {
+		total   time.Duration
+		longest time.Duration
+		count   int
+	}
+	waits := make(map[string]*waitAcc, len(partitions))
+
+	for _, job := range jobs {
+		info, ok := infoMap[job.Partition]
+		if !ok {
+			// Job belongs to a partition not in our list — skip
+			continue
+		}
+
+		info.TotalJobs++
+
+		switch job.State {
+		case JobStateRunning, JobStateCompleting:
+			info.RunningJobs++
+			// Accumulate allocated CPUs from running jobs
+			info.AllocatedCPUs += int(float64(job.NodeCount) * cpusPerNode[job.Partition])
+		case JobStatePending:
+			info.PendingJobs++
+			waitTime := now.Sub(job.SubmitTime)
+			w := waits[job.Partition]
+			if w == nil {
+				w = &waitAcc{}
+				waits[job.Partition] = w
+			}
+			w.total += waitTime
+			w.count++
+			if waitTime > w.longest {
+				w.longest = waitTime
+			}
+		}
+	}
+
+	// Compute average/longest wait times
+	for partName, w := range waits {
+		if w.count > 0 {
+			infoMap[partName].AverageWait = w.total / time.Duration(w.count)
+			infoMap[partName].LongestWait = w.longest
+		}
+	}
+
+	return infoMap
+}
@@ -3,11 +3,15 @@ package dao
 import (
 	"context"
 	"fmt"
+	"math"
+	"net/http"
 	"os"
 	osuser "os/user"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/jontk/s9s/internal/auth"
 	"github.com/jontk/s9s/internal/config"
 	"github.com/jontk/s9s/internal/debug"
 	"github.com/jontk/s9s/internal/errs"
@@ -17,14 +21,16 @@ import (
 
 // SlurmAdapter wraps the slurm-client library and provides version abstraction
 type SlurmAdapter struct {
-	client slurm.SlurmClient
-	config *config.ClusterConfig
-	ctx    context.Context
-	cache  *DAOCache
+	client     slurm.SlurmClient
+	config     *config.ClusterConfig
+	ctx        context.Context
+	cache      *DAOCache
+	accounting *jobAccounting
 }
 
-// NewSlurmAdapter creates a new SLURM adapter instance
-func NewSlurmAdapter(ctx context.Context, cfg *config.ClusterConfig) (*SlurmAdapter, error) {
+// NewSlurmAdapter creates a new SLURM adapter instance for the cluster
+// context with the given name
+func NewSlurmAdapter(ctx context.Context, name string, cfg *config.ClusterConfig) (*SlurmAdapter, error) {
 	if cfg == nil {
 		return nil, errs.Config("cluster config is required")
 	}
@@ -50,8 +56,35 @@ func NewSlurmAdapter(ctx context.Context, cfg *config.ClusterConfig) (*SlurmAdap
 		// Adapter implementation is now the default (WithUseAdapters removed in v0.3+)
 	}
 
-	// Add authentication if token is provided
-	if cfg.Token != "" {
+	// Job usage is read from the slurmdb endpoints with the same credentials
+	accounting := &jobAccounting{
+		baseURL: cfg.Endpoint,
+		client:  &http.Client{Timeout: timeout},
+	}
+
+	// Add authentication: a token source takes precedence over a static token
+	if cfg.TokenFrom.IsSet() {
+		username := config.ResolveSlurmUserForCluster(cfg)
+		if username == "" {
+			return nil, fmt.Errorf("cannot determine SLURM username: set 'user' in cluster config or SLURM_USER_NAME env var")
+		}
+		source, err := auth.NewTokenSource(&cfg.TokenFrom, name)
+		if err != nil {
+			return nil, errs.Wrap(err, errs.ErrorTypeConfiguration, "invalid tokenFrom")
+		}
+		debug.Logger.Printf("Using SLURM username %s with a token from %s", username, source.Kind())
+
+		tokenAuth := &tokenAuth{username: username, source: source}
+		accounting.authenticate = tokenAuth.Authenticate
+		accounting.client.Transport = &unauthorizedRetryTransport{base: http.DefaultTransport, auth: tokenAuth}
+		opts = append(opts,
+			slurm.WithAuth(tokenAuth),
+			slurm.WithHTTPClient(&http.Client{
+				Timeout:   30 * time.Second, // slurm-client's default
+				Transport: &unauthorizedRetryTransport{base: http.DefaultTransport, auth: tokenAuth},
+			}),
+		)
+	} else if cfg.Token != "" {
 		username := config.ResolveSlurmUserForCluster(cfg)
 		if username == "" {
 			return nil, fmt.Errorf("cannot determine SLURM username: set 'user' in cluster config or SLURM_USER_NAME env var")
@@ -61,6 +94,11 @@ func NewSlurmAdapter(ctx context.Context, cfg *config.ClusterConfig) (*SlurmAdap
 		// Use WithUserToken to set both X-SLURM-USER-NAME and X-SLURM-USER-TOKEN headers
 		// This is required for slurmrestd authentication
 		opts = append(opts, slurm.WithUserToken(username, cfg.Token))
+		accounting.authenticate = func(_ context.Context, req *http.Request) error {
+			req.Header.Set("X-SLURM-USER-NAME", username)
+			req.Header.Set("X-SLURM-USER-TOKEN", cfg.Token)
+			return nil
+		}
 	}
 
 	// Create the client
@@ -85,12 +123,14 @@ func NewSlurmAdapter(ctx context.Context, cfg *config.ClusterConfig) (*SlurmAdap
 	}
 
 	debug.Logger.Printf("SLURM client created successfully")
+	accounting.version = slurmClient.Version()
 
 	return &SlurmAdapter{
-		client: slurmClient,
-		config: cfg,
-		ctx:    ctx,
-		cache:  NewDAOCache(10*time.Second, 50),
+		client:     slurmClient,
+		config:     cfg,
+		ctx:        ctx,
+		cache:      NewDAOCache(10*time.Second, 50),
+		accounting: accounting,
 	}, nil
 }
 
@@ -103,8 +143,9 @@ func (s *SlurmAdapter) Close() error {
 func (s *SlurmAdapter) Jobs() JobManager {
 	return &cachedJobManager{
 		inner: &jobManager{
-			client: s.client.Jobs(),
-			ctx:    s.ctx,
+			client:     s.client.Jobs(),
+			ctx:        s.ctx,
+			accounting: s.accounting,
 		},
 		cache: s.cache,
 	}
@@ -189,28 +230,36 @@ func (s *SlurmAdapter) ClusterInfo() (*ClusterInfo, error) {
 
 // jobManager implements JobManager
 type jobManager struct {
-	client slurm.JobManager
-	ctx    context.Context
+	client     slurm.JobManager
+	ctx        context.Context
+	accounting *jobAccounting
 }
 
 func (j *jobManager) List(opts *ListJobsOptions) (*JobList, error) {
 	debug.Logger.Printf("Jobs List() called at %s", time.Now().Format("15:04:05.000"))
 	// Convert options to slurm-client format
 	// Note: slurm-client's ListJobsOptions only supports: UserID, States, Partition, Limit, Offset
-	// For other filters (Users, Partitions, Accounts), the adapter will apply client-side filtering
+	// A single user and partition are pushed down; several users or partitions,
+	// any accounts and the array are filtered here, and then the page is cut
+	// locally
 	clientOpts := &slurm.ListJobsOptions{}
+	clientSide := opts != nil && needsClientSideJobFilter(opts)
 	if opts != nil {
 		clientOpts.States = opts.States
-		clientOpts.Limit = opts.Limit
-		clientOpts.Offset = opts.Offset
-		// Note: If we need per-user filtering, convert first user to UserID
-		// For now, we pass empty and let adapter handle in applyClientSideFilters()
-		debug.Logger.Printf("List options - States: %v, Limit: %d, Offset: %d", clientOpts.States, clientOpts.Limit, clientOpts.Offset)
+		if len(opts.Users) == 1 {
+			clientOpts.UserID = opts.Users[0]
+		}
+		if len(opts.Partitions) == 1 {
+			clientOpts.Partition = opts.Partitions[0]
+		}
+		if !clientSide {
+			clientOpts.Limit = opts.Limit
+			clientOpts.Offset = opts.Offset
+		}
 	}
 
-	// DEBUG: Log what we're passing to the adapter
-	debug.Logger.Printf("DEBUG: Calling adapter with clientOpts: States=%v, Limit=%d, Offset=%d",
-		clientOpts.States, clientOpts.Limit, clientOpts.Offset)
+	debug.Logger.Printf("List options - States: %v, UserID: %q, Partition: %q, Limit: %d, Offset: %d, client-side: %v",
+		clientOpts.States, clientOpts.UserID, clientOpts.Partition, clientOpts.Limit, clientOpts.Offset, clientSide)
 
 	// Call the client
 	result, err := j.client.List(j.ctx, clientOpts)
@@ -225,37 +274,72 @@ func (j *jobManager) List(opts *ListJobsOptions) (*JobList, error) {
 		return &JobList{Jobs: []*Job{}, Total: 0}, nil
 	}
 
-	debug.Logger.Printf("DEBUG: Result object - type: %T, Jobs length: %d, Total: %d", result, len(result.Jobs), result.Total)
 	debug.Logger.Printf("Jobs List() returned %d jobs, total: %d", len(result.Jobs), result.Total)
 
 	// Convert to our types
 	jobs := make([]*Job, 0, len(result.Jobs))
 	for _, job := range result.Jobs {
 		converted := convertJob(&job)
-
-		// Apply client-side partition filter if specified
-		if opts != nil && len(opts.Partitions) > 0 {
-			matched := false
-			for _, filterPart := range opts.Partitions {
-				if converted.Partition == filterPart {
-					matched = true
-					break
-				}
-			}
-			if !matched {
-				continue
-			}
+		if clientSide && !matchesJobListOptions(converted, opts) {
+			continue
 		}
-
 		jobs = append(jobs, converted)
 	}
 
+	if clientSide {
+		total := len(jobs)
+		page := pageJobs(jobs, opts.Limit, opts.Offset)
+		j.accounting.fill(j.ctx, page)
+		return &JobList{Jobs: page, Total: total}, nil
+	}
+	j.accounting.fill(j.ctx, jobs)
+
+	total := result.Total
+	if offset := clientOpts.Offset + len(jobs); total < offset {
+		total = offset
+	}
 	return &JobList{
 		Jobs:  jobs,
-		Total: len(jobs),
+		Total: total,
 	}, nil
 }
 
+// needsClientSideJobFilter reports whether opts filter on something
+// slurm-client cannot: several users or partitions, accounts or an array
+func needsClientSideJobFilter(opts *ListJobsOptions) bool {
+	return len(opts.Users) > 1 || len(opts.Partitions) > 1 || len(opts.Accounts) > 0 || opts.ArrayJobID != ""
+}
+
+// matchesJobListOptions reports whether job passes the user, partition,
+// account and array filters of opts
+func matchesJobListOptions(job *Job, opts *ListJobsOptions) bool {
+	if len(opts.Users) > 0 && !slices.Contains(opts.Users, job.User) {
+		return false
+	}
+	if len(opts.Partitions) > 0 && !slices.Contains(opts.Partitions, job.Partition) {
+		return false
+	}
+	if len(opts.Accounts) > 0 && !slices.Contains(opts.Accounts, job.Account) {
+		return false
+	}
+	if opts.ArrayJobID != "" && job.ArrayJobID != opts.ArrayJobID {
+		return false
+	}
+	return true
+}
+
+// pageJobs returns the jobs of the page at offset; a limit of 0 means all
+func pageJobs(jobs []*Job, limit, offset int) []*Job {
+	if offset >= len(jobs) {
+		return []*Job{}
+	}
+	jobs = jobs[max(offset, 0):]
+	if limit > 0 && limit < len(jobs) {
+		jobs = jobs[:limit]
+	}
+	return jobs
+}
+
 func (j *jobManager) Get(id string) (*Job, error) {
 	debug.Logger.Printf("JobManager.Get() called for job %s", id)
 	job, err := j.client.Get(j.ctx, id)
@@ -277,7 +361,9 @@ func (j *jobManager) Get(id string) (*Job, error) {
 		jobState = string(job.JobState[0])
 	}
 	debug.Logger.Printf("JobManager.Get() returned job: ID=%s, State=%s", jobID, jobState)
-	return convertJob(job), nil
+	converted := convertJob(job)
+	j.accounting.fill(j.ctx, []*Job{converted})
+	return converted, nil
 }
 
 func (j *jobManager) Submit(job *JobSubmission) (string, error) {
@@ -421,9 +507,9 @@ func convertJobSubmissionToJobCreate(job *JobSubmission) *slurm.JobCreate {
 		jc.Requeue = ptrBool(true)
 	}
 
-	// Dependencies → "afterok:id1:id2"
-	if len(job.Dependencies) > 0 {
-		jc.Dependency = ptrString("afterok:" + strings.Join(job.Dependencies, ":"))
+	// Dependencies → "afterok:id1:id2[,afterany:id3]"
+	if dependency := DependencyString(job.Dependencies); dependency != "" {
+		jc.Dependency = ptrString(dependency)
 	}
 
 	// Constraints (node features)
@@ -1010,6 +1096,13 @@ func derefUint32Int(p *uint32) int {
 	return int(*p)
 }
 
+func derefInt32Int(p *int32) int {
+	if p == nil {
+		return 0
+	}
+	return int(*p)
+}
+
 func derefUint16Int(p *uint16) int {
 	if p == nil {
 		return 0
@@ -1197,13 +1290,9 @@ type nodeManager struct {
 }
 
 func (n *nodeManager) List(opts *ListNodesOptions) (*NodeList, error) {
-	// Convert options to slurm-client format
-	clientOpts := &slurm.ListNodesOptions{}
-	if opts != nil {
-		clientOpts.States = opts.States
-	}
-
-	result, err := n.client.List(n.ctx, clientOpts)
+	// States are filtered client-side so that compound states such as
+	// "IDLE+DRAIN" match both "IDLE" and "DRAIN" on every API version
+	result, err := n.client.List(n.ctx, &slurm.ListNodesOptions{})
 	if err != nil {
 		return nil, errs.SlurmAPI("list nodes", err)
 	}
@@ -1213,6 +1302,10 @@ func (n *nodeManager) List(opts *ListNodesOptions) (*NodeList, error) {
 	for _, node := range result.Nodes {
 		converted := convertNode(&node)
 
+		if opts != nil && len(opts.States) > 0 && !matchesNodeStates(converted, opts.States) {
+			continue
+		}
+
 		// Apply client-side partition filter if specified
 		if opts != nil && len(opts.Partitions) > 0 {
 			matched := false
@@ -1241,6 +1334,17 @@ func (n *nodeManager) List(opts *ListNodesOptions) (*NodeList, error) {
 	}, nil
 }
 
+// matchesNodeStates reports whether the node matches any of the state filters
+func matchesNodeStates(node *Node, filters []string) bool {
+	state := node.ParsedState()
+	for _, f := range filters {
+		if state.Matches(f) {
+			return true
+		}
+	}
+	return false
+}
+
 func (n *nodeManager) Get(name string) (*Node, error) {
 	node, err := n.client.Get(n.ctx, name)
 	if err != nil {
@@ -1307,6 +1411,31 @@ func (p *partitionManager) Get(name string) (*Partition, error) {
 	return convertPartition(partition), nil
 }
 
+func (p *partitionManager) SetState(name, state string) error {
+	state = strings.ToUpper(strings.TrimSpace(state))
+	if !IsSettablePartitionState(state) {
+		return errs.Invalidf("invalid partition state %q", state)
+	}
+
+	st := slurm.PartitionState(state)
+	if err := p.client.Update(p.ctx, name, &slurm.PartitionUpdate{State: &st}); err != nil {
+		return errs.DAOError("update", "partition", err).WithContext("partition_name", name)
+	}
+	return nil
+}
+
+func (p *partitionManager) SetMaxTime(name, limit string) error {
+	minutes, err := MaxTimeMinutes(limit)
+	if err != nil {
+		return errs.Invalidf("invalid max time: %v", err)
+	}
+
+	if err := p.client.Update(p.ctx, name, &slurm.PartitionUpdate{MaxTime: &minutes}); err != nil {
+		return errs.DAOError("update", "partition", err).WithContext("partition_name", name)
+	}
+	return nil
+}
+
 // reservationManager implements ReservationManager
 type reservationManager struct {
 	client slurm.ReservationManager
@@ -1606,6 +1735,8 @@ func convertJob(job *slurm.Job) *Job {
 		Wckey:          derefString(job.Wckey),
 		MailUser:       derefString(job.MailUser),
 		StateReason:    derefString(job.StateReason),
+
+		ArrayTaskString: derefString(job.ArrayTaskString),
 	}
 }
 
@@ -1616,11 +1747,12 @@ func convertNode(node *slurm.Node) *Node {
 		nodeName = *node.Name
 	}
 
-	// Get state as string (take first if multiple)
-	stateStr := ""
-	if len(node.State) > 0 {
-		stateStr = string(node.State[0])
+	// Keep the base state and every flag, e.g. "IDLE+DRAIN"
+	states := make([]string, len(node.State))
+	for i, s := range node.State {
+		states[i] = string(s)
 	}
+	stateStr := ParseNodeState(strings.Join(states, "+")).String()
 
 	// CPUs is *int32
 	cpusTotal := 0
@@ -1686,6 +1818,8 @@ func convertNode(node *slurm.Node) *Node {
 		Reason:          reason,
 		ReasonTime:      reasonTime,
 		AllocatedJobs:   []string{}, // Would need to query jobs for this node
+		GRES:            ParseGRES(derefString(node.GRES), derefString(node.GRESUsed)),
+		Topology:        derefString(node.Topology),
 	}
 }
 
@@ -1738,22 +1872,110 @@ func convertPartition(partition *slurm.Partition) *Partition {
 		defaultTime = fmt.Sprintf("%d", *partition.Defaults.Time)
 	}
 
-	// MaxTime is in partition.Maximums.Time
+	// MaxTime is in partition.Maximums.Time, INFINITE (all bits set) when
+	// unlimited
 	maxTime := "0"
 	if partition.Maximums != nil && partition.Maximums.Time != nil {
-		maxTime = fmt.Sprintf("%d", *partition.Maximums.Time)
+		if *partition.Maximums.Time == math.MaxUint32 {
+			maxTime = "UNLIMITED"
+		} else {
+			maxTime = fmt.Sprintf("%d", *partition.Maximums.Time)
+		}
 	}
 
-	return &Partition{
+	result := &Partition{
 		Name:        name,
 		State:       state,
 		TotalNodes:  totalNodes,
 		TotalCPUs:   totalCPUs,
 		DefaultTime: defaultTime,
 		MaxTime:     maxTime,
-		QOS:         []string{}, // Not available in basic Partition struct
-		Nodes:       []string{}, // Not available in basic Partition struct
+		QOS:         []string{},
+		Nodes:       []string{},
+	}
+
+	if partition.QoS != nil {
+		result.QOS = splitPartitionList(partition.QoS.Assigned)
+		result.AllowQOS = splitPartitionList(partition.QoS.Allowed)
+		result.DenyQOS = splitPartitionList(partition.QoS.Deny)
+	}
+	if partition.Nodes != nil {
+		result.Nodes = splitPartitionList(partition.Nodes.Configured)
+	}
+	if partition.Priority != nil {
+		result.PriorityTier = derefInt32Int(partition.Priority.Tier)
+		result.PriorityJobFactor = derefInt32Int(partition.Priority.JobFactor)
+	}
+	if partition.Accounts != nil {
+		result.AllowAccounts = splitPartitionList(partition.Accounts.Allowed)
+		result.DenyAccounts = splitPartitionList(partition.Accounts.Deny)
+	}
+	if partition.Groups != nil {
+		result.AllowGroups = splitPartitionList(partition.Groups.Allowed)
+	}
+	if partition.Maximums != nil {
+		result.OverSubscribe = formatOverSubscribe(partition.Maximums.Oversubscribe)
+		// MaxNodes is INFINITE (all bits set) when unlimited
+		if partition.Maximums.Nodes != nil && *partition.Maximums.Nodes != math.MaxUint32 {
+			result.MaxNodes = int(*partition.Maximums.Nodes)
+		}
+	}
+	if partition.TRES != nil {
+		result.BillingWeights = derefString(partition.TRES.BillingWeights)
+		result.TRES = derefString(partition.TRES.Configured)
+	}
+
+	return result
+}
+
+// splitPartitionList splits a comma-separated partition attribute, keeping
+// hostlist expressions such as "node[1,3-5]" whole
+func splitPartitionList(value *string) []string {
+	if value == nil || *value == "" {
+		return []string{}
+	}
+
+	var parts []string
+	depth, start := 0, 0
+	add := func(part string) {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	for i, r := range *value {
+		switch r {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				add((*value)[start:i])
+				start = i + 1
+			}
+		}
+	}
+	add((*value)[start:])
+	return parts
+}
+
+// formatOverSubscribe formats a partition's OverSubscribe setting the way
+// scontrol prints it
+func formatOverSubscribe(o *slurm.PartitionMaximumsOversubscribe) string {
+	if o == nil || o.Jobs == nil {
+		return ""
+	}
+	jobs := *o.Jobs
+	if slices.Contains(o.Flags, slurm.PartitionMaximumsOversubscribeFlagsValue("force")) {
+		return fmt.Sprintf("FORCE:%d", jobs)
+	}
+	switch jobs {
+	case 0:
+		return "EXCLUSIVE"
+	case 1:
+		return "NO"
 	}
+	return fmt.Sprintf("YES:%d", jobs)
 }
 
 func convertReservation(res *slurm.Reservation) *Reservation {
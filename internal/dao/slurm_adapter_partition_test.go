@@ -0,0 +1,60 @@
+package dao
+
+import (
+	"math"
+	"testing"
+
+	slurm "github.com/jontk/slurm-client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertPartition(t *testing.T) {
+	partition := &slurm.Partition{
+		Name:      ptrString("gpu"),
+		Partition: &slurm.PartitionPartition{State: []slurm.PartitionState{"DRAIN"}},
+		Nodes:     &slurm.PartitionNodes{Configured: ptrString("gpu[001-004,010],bigmem01"), Total: ptrInt32(5)},
+		Priority:  &slurm.PartitionPriority{Tier: ptrInt32(2), JobFactor: ptrInt32(10)},
+		Accounts:  &slurm.PartitionAccounts{Allowed: ptrString("ml,physics"), Deny: ptrString("guest")},
+		Groups:    &slurm.PartitionGroups{Allowed: ptrString("ALL")},
+		QoS:       &slurm.PartitionQoS{Assigned: ptrString("gpu"), Allowed: ptrString("normal,high"), Deny: ptrString("low")},
+		Maximums: &slurm.PartitionMaximums{
+			Nodes:         ptrUint32(math.MaxUint32),
+			Time:          ptrUint32(2880),
+			Oversubscribe: &slurm.PartitionMaximumsOversubscribe{Jobs: ptrInt32(4)},
+		},
+		TRES: &slurm.PartitionTRES{BillingWeights: ptrString("CPU=1.0,GRES/gpu=8.0"), Configured: ptrString("cpu=640,node=5")},
+	}
+
+	p := convertPartition(partition)
+	assert.Equal(t, "DRAIN", p.State)
+	assert.Equal(t, "2880", p.MaxTime)
+	assert.Equal(t, []string{"gpu[001-004,010]", "bigmem01"}, p.Nodes)
+	assert.Equal(t, []string{"gpu"}, p.QOS)
+	assert.Equal(t, 2, p.PriorityTier)
+	assert.Equal(t, 10, p.PriorityJobFactor)
+	assert.Equal(t, []string{"ml", "physics"}, p.AllowAccounts)
+	assert.Equal(t, []string{"guest"}, p.DenyAccounts)
+	assert.Equal(t, []string{"ALL"}, p.AllowGroups)
+	assert.Equal(t, []string{"normal", "high"}, p.AllowQOS)
+	assert.Equal(t, []string{"low"}, p.DenyQOS)
+	assert.Equal(t, "YES:4", p.OverSubscribe)
+	assert.Zero(t, p.MaxNodes, "INFINITE max nodes means unlimited")
+	assert.Equal(t, "CPU=1.0,GRES/gpu=8.0", p.BillingWeights)
+	assert.Equal(t, "cpu=640,node=5", p.TRES)
+
+	unlimited := convertPartition(&slurm.Partition{Maximums: &slurm.PartitionMaximums{Time: ptrUint32(math.MaxUint32)}})
+	assert.Equal(t, "UNLIMITED", unlimited.MaxTime, "INFINITE max time means unlimited")
+
+	empty := convertPartition(&slurm.Partition{Name: ptrString("debug")})
+	assert.Equal(t, []string{}, empty.Nodes)
+	assert.Equal(t, []string{}, empty.QOS)
+	assert.Empty(t, empty.OverSubscribe)
+}
+
+func TestFormatOverSubscribe(t *testing.T) {
+	force := []slurm.PartitionMaximumsOversubscribeFlagsValue{"force"}
+	assert.Equal(t, "EXCLUSIVE", formatOverSubscribe(&slurm.PartitionMaximumsOversubscribe{Jobs: ptrInt32(0)}))
+	assert.Equal(t, "NO", formatOverSubscribe(&slurm.PartitionMaximumsOversubscribe{Jobs: ptrInt32(1)}))
+	assert.Equal(t, "FORCE:4", formatOverSubscribe(&slurm.PartitionMaximumsOversubscribe{Jobs: ptrInt32(4), Flags: force}))
+	assert.Empty(t, formatOverSubscribe(nil))
+}
@@ -361,6 +361,17 @@ func TestConvertJobSubmissionToJobCreate_Dependencies(t *testing.T) {
 	assert.Equal(t, "afterok:123:456", *jc.Dependency)
 }
 
+func TestConvertJobSubmissionToJobCreate_TypedDependencies(t *testing.T) {
+	job := &JobSubmission{
+		Name:         "test",
+		Script:       "#!/bin/bash\n",
+		Dependencies: []string{"afterany:789", "123", Dependency(DependencyAfterCorr, "10", "11")},
+	}
+	jc := convertJobSubmissionToJobCreate(job)
+	require.NotNil(t, jc.Dependency)
+	assert.Equal(t, "afterok:123,afterany:789,aftercorr:10:11", *jc.Dependency)
+}
+
 func TestConvertJobSubmissionToJobCreate_Signal(t *testing.T) {
 	t.Run("B:USR1@300", func(t *testing.T) {
 		job := &JobSubmission{
@@ -0,0 +1,94 @@
+package dao
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseTimeLimit parses a SLURM time limit in minutes or [D-]HH:MM:SS form.
+// It returns false for unlimited or unparsable limits.
+func ParseTimeLimit(limit string) (time.Duration, bool) {
+	limit = strings.TrimSpace(limit)
+	if limit == "" || limit == "0" || strings.EqualFold(limit, "UNLIMITED") || strings.EqualFold(limit, "INFINITE") {
+		return 0, false
+	}
+
+	var days int
+	if day, rest, found := strings.Cut(limit, "-"); found {
+		d, err := strconv.Atoi(day)
+		if err != nil {
+			return 0, false
+		}
+		days, limit = d, rest
+	}
+
+	parts := strings.Split(limit, ":")
+	values := make([]int, len(parts))
+	for i, part := range parts {
+		v, err := strconv.Atoi(part)
+		if err != nil || v < 0 {
+			return 0, false
+		}
+		values[i] = v
+	}
+
+	var d time.Duration
+	switch len(values) {
+	case 1:
+		if days > 0 {
+			d = time.Duration(values[0]) * time.Hour
+		} else {
+			d = time.Duration(values[0]) * time.Minute
+		}
+	case 2:
+		if days > 0 {
+			d = time.Duration(values[0])*time.Hour + time.Duration(values[1])*time.Minute
+		} else {
+			d = time.Duration(values[0])*time.Minute + time.Duration(values[1])*time.Second
+		}
+	case 3:
+		d = time.Duration(values[0])*time.Hour + time.Duration(values[1])*time.Minute + time.Duration(values[2])*time.Second
+	default:
+		return 0, false
+	}
+
+	d += time.Duration(days) * 24 * time.Hour
+	return d, d > 0
+}
+
+// TimeLimitMinutes converts a SLURM time limit to whole minutes, rounding
+// partial minutes up. Unlimited limits are rejected.
+func TimeLimitMinutes(limit string) (int32, error) {
+	d, ok := ParseTimeLimit(limit)
+	if !ok {
+		return 0, fmt.Errorf("%q is not a positive time limit in minutes or [D-]HH:MM:SS form", limit)
+	}
+	minutes := (d + time.Minute - 1) / time.Minute
+	if minutes > math.MaxInt32 {
+		return 0, fmt.Errorf("time limit %q is too large", limit)
+	}
+	return int32(minutes), nil
+}
+
+// InfiniteTimeLimit is the time limit SLURM stores for UNLIMITED: INFINITE,
+// all 32 bits set, as carried by the signed minutes of the update API
+const InfiniteTimeLimit int32 = -1
+
+// IsUnlimitedTimeLimit reports whether limit is spelled UNLIMITED or INFINITE
+func IsUnlimitedTimeLimit(limit string) bool {
+	limit = strings.TrimSpace(limit)
+	return strings.EqualFold(limit, "UNLIMITED") || strings.EqualFold(limit, "INFINITE")
+}
+
+// MaxTimeMinutes converts a partition max time to whole minutes like
+// TimeLimitMinutes, but also accepts UNLIMITED and INFINITE, which become
+// InfiniteTimeLimit
+func MaxTimeMinutes(limit string) (int32, error) {
+	if IsUnlimitedTimeLimit(limit) {
+		return InfiniteTimeLimit, nil
+	}
+	return TimeLimitMinutes(limit)
+}
@@ -0,0 +1,66 @@
+package dao
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTimeLimit(t *testing.T) {
+	tests := map[string]time.Duration{
+		"90":         90 * time.Minute,
+		"30:15":      30*time.Minute + 15*time.Second,
+		"2:00:00":    2 * time.Hour,
+		"1-12":       36 * time.Hour,
+		"1-00:30":    24*time.Hour + 30*time.Minute,
+		"2-01:00:00": 49 * time.Hour,
+	}
+	for limit, want := range tests {
+		got, ok := ParseTimeLimit(limit)
+		assert.True(t, ok, limit)
+		assert.Equal(t, want, got, limit)
+	}
+
+	for _, limit := range []string{"", "UNLIMITED", "abc", "1:2:3:4"} {
+		_, ok := ParseTimeLimit(limit)
+		assert.False(t, ok, limit)
+	}
+}
+
+func TestTimeLimitMinutes(t *testing.T) {
+	tests := map[string]int32{
+		"90":         90,
+		"30:15":      31,
+		"2-01:00:00": 2940,
+	}
+	for limit, want := range tests {
+		got, err := TimeLimitMinutes(limit)
+		assert.NoError(t, err, limit)
+		assert.Equal(t, want, got, limit)
+	}
+
+	for _, limit := range []string{"", "0", "UNLIMITED", "soon"} {
+		_, err := TimeLimitMinutes(limit)
+		assert.Error(t, err, limit)
+	}
+}
+
+func TestMaxTimeMinutes(t *testing.T) {
+	for _, limit := range []string{"UNLIMITED", "infinite", " Unlimited "} {
+		got, err := MaxTimeMinutes(limit)
+		assert.NoError(t, err, limit)
+		assert.Equal(t, InfiniteTimeLimit, got, limit)
+		assert.Equal(t, uint32(math.MaxUint32), uint32(got), "INFINITE has all bits set")
+	}
+
+	got, err := MaxTimeMinutes("12:00:00")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(720), got)
+
+	for _, limit := range []string{"", "0", "soon"} {
+		_, err := MaxTimeMinutes(limit)
+		assert.Error(t, err, limit)
+	}
+}
@@ -0,0 +1,78 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/jontk/s9s/internal/auth"
+	"github.com/jontk/s9s/internal/debug"
+)
+
+// tokenAuth sets the SLURM user headers on every slurmrestd request from
+// the current token of a refreshing source, so a refreshed token is used
+// without rebuilding the client
+type tokenAuth struct {
+	username string
+	source   *auth.RefreshingTokenSource
+}
+
+// Authenticate adds the X-SLURM-USER-NAME and X-SLURM-USER-TOKEN headers
+func (a *tokenAuth) Authenticate(ctx context.Context, req *http.Request) error {
+	token, err := a.source.Token(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get SLURM token from %s: %w", a.source.Kind(), err)
+	}
+	req.Header.Set("X-SLURM-USER-NAME", a.username)
+	req.Header.Set("X-SLURM-USER-TOKEN", token.AccessToken)
+	return nil
+}
+
+// Type returns the authentication type
+func (a *tokenAuth) Type() string {
+	return "user-token"
+}
+
+// unauthorizedRetryTransport resends a request once with a new token when
+// slurmrestd rejects the current one with 401, e.g. because it was revoked
+// or expired early
+type unauthorizedRetryTransport struct {
+	base http.RoundTripper
+	auth *tokenAuth
+}
+
+// RoundTrip sends the request, retrying once on 401 Unauthorized
+func (t *unauthorizedRetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		// The body has been consumed and cannot be replayed
+		return resp, nil
+	}
+
+	rejected := req.Header.Get("X-SLURM-USER-TOKEN")
+	t.auth.source.Invalidate(rejected)
+
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, bodyErr := req.GetBody()
+		if bodyErr != nil {
+			return resp, nil
+		}
+		retry.Body = body
+	}
+	if err := t.auth.Authenticate(req.Context(), retry); err != nil {
+		debug.Logger.Printf("Token refresh after 401 failed: %v", err)
+		return resp, nil
+	}
+	if retry.Header.Get("X-SLURM-USER-TOKEN") == rejected {
+		// The source handed back the same token; retrying cannot help
+		return resp, nil
+	}
+
+	debug.Logger.Printf("slurmrestd rejected the token, retrying %s %s with a new one", req.Method, req.URL.Path)
+	_ = resp.Body.Close()
+	return t.base.RoundTrip(retry)
+}
@@ -0,0 +1,149 @@
+package dao
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jontk/s9s/internal/auth"
+	"github.com/jontk/s9s/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zalando/go-keyring"
+)
+
+// rotatingSource hands out "token-1", "token-2", ...
+type rotatingSource struct {
+	n atomic.Int32
+}
+
+func (r *rotatingSource) Token(_ context.Context) (*auth.Token, error) {
+	return &auth.Token{AccessToken: "token-" + string(rune('0'+r.n.Add(1)))}, nil
+}
+
+func TestUnauthorizedRetryTransport_RetriesWithNewToken(t *testing.T) {
+	var seen []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		seen = append(seen, r.Header.Get("X-SLURM-USER-TOKEN")+":"+string(body))
+		if r.Header.Get("X-SLURM-USER-TOKEN") == "token-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	tokenAuth := &tokenAuth{
+		username: "alice",
+		source:   auth.NewRefreshingTokenSource(&rotatingSource{}, "test", 0),
+	}
+	transport := &unauthorizedRetryTransport{base: http.DefaultTransport, auth: tokenAuth}
+
+	req, err := http.NewRequest(http.MethodPost, server.URL, strings.NewReader("payload"))
+	require.NoError(t, err)
+	require.NoError(t, tokenAuth.Authenticate(context.Background(), req))
+
+	resp, err := transport.RoundTrip(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []string{"token-1:payload", "token-2:payload"}, seen)
+	assert.Equal(t, "token-2", tokenAuth.source.Current().AccessToken)
+}
+
+func TestUnauthorizedRetryTransport_GivesUpOnSameToken(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	t.Setenv("TEST_S9S_TOKEN", "static")
+	tokenAuth := &tokenAuth{
+		username: "alice",
+		source:   auth.NewRefreshingTokenSource(&auth.EnvTokenSource{Name: "TEST_S9S_TOKEN"}, "env", 0),
+	}
+	transport := &unauthorizedRetryTransport{base: http.DefaultTransport, auth: tokenAuth}
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, http.NoBody)
+	require.NoError(t, err)
+	require.NoError(t, tokenAuth.Authenticate(context.Background(), req))
+
+	resp, err := transport.RoundTrip(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestUnauthorizedRetryTransport_RefreshesRejectedOAuth2Token(t *testing.T) {
+	keyring.MockInit()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	// A cached login that is far from expiring but has been revoked
+	cache := auth.NewTokenManager(filepath.Join(home, ".s9s"), true)
+	require.NoError(t, cache.SetToken("test/s9s", &auth.Token{
+		AccessToken:  "revoked",
+		RefreshToken: "refresh-1",
+		TokenType:    "Bearer",
+		ExpiresAt:    time.Now().Add(time.Hour),
+	}))
+
+	var refreshes atomic.Int32
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "refresh_token", r.PostForm.Get("grant_type"))
+		assert.Equal(t, "refresh-1", r.PostForm.Get("refresh_token"))
+		refreshes.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token": "renewed", "expires_in": 3600}`))
+	}))
+	defer provider.Close()
+
+	var seen []string
+	slurmrestd := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.Header.Get("X-SLURM-USER-TOKEN")
+		seen = append(seen, token)
+		if token != "renewed" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer slurmrestd.Close()
+
+	source := auth.NewOAuth2TokenSource(&config.OAuth2TokenConfig{
+		ClientID:              "s9s",
+		ClientSecret:          "secret",
+		AuthorizationEndpoint: provider.URL + "/authorize",
+		TokenEndpoint:         provider.URL + "/token",
+	}, "test", auth.DefaultRefreshBefore)
+	tokenAuth := &tokenAuth{
+		username: "alice",
+		source:   auth.NewRefreshingTokenSource(source, "oauth2", auth.DefaultRefreshBefore),
+	}
+	transport := &unauthorizedRetryTransport{base: http.DefaultTransport, auth: tokenAuth}
+
+	req, err := http.NewRequest(http.MethodGet, slurmrestd.URL, http.NoBody)
+	require.NoError(t, err)
+	require.NoError(t, tokenAuth.Authenticate(context.Background(), req))
+
+	resp, err := transport.RoundTrip(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []string{"revoked", "renewed"}, seen)
+	assert.Equal(t, int32(1), refreshes.Load())
+}
@@ -1,7 +1,7 @@
 package dao
 
 import (
-	"strings"
+	"slices"
 	"time"
 )
 
@@ -57,6 +57,14 @@ type Job struct {
 	Wckey          string // Workload characterization key
 	MailUser       string // Email notification user
 	StateReason    string // Why job is in current state
+
+	// Accounting usage, read from slurmdbd for jobs that have started
+	TotalCPU     time.Duration // CPU time consumed by all tasks (user + system)
+	MaxRSS       int64         // Peak resident memory in MB
+	TRESUsageAve string        // Average TRES usage (e.g., "cpu=00:10:00,gres/gpuutil=75")
+
+	// Tasks of a pending array record that covers several tasks (e.g., "4-5000%50")
+	ArrayTaskString string
 }
 
 // JobList represents a list of jobs
@@ -71,6 +79,7 @@ type ListJobsOptions struct {
 	Users      []string
 	Partitions []string
 	Accounts   []string
+	ArrayJobID string // Only the tasks of this job array
 	Limit      int
 	Offset     int
 }
@@ -189,6 +198,9 @@ type Node struct {
 	Reason          string
 	ReasonTime      *time.Time
 	AllocatedJobs   []string
+	GRES            []GRES // Configured generic resources with their allocation
+
+	Topology string // SLURM topology placement, e.g. "default:core:leaf01"; empty when not reported
 }
 
 // NodeList represents a list of nodes
@@ -214,6 +226,18 @@ type Partition struct {
 	MaxTime     string
 	QOS         []string
 	Nodes       []string
+
+	PriorityTier      int
+	PriorityJobFactor int
+	AllowAccounts     []string
+	DenyAccounts      []string
+	AllowGroups       []string
+	AllowQOS          []string
+	DenyQOS           []string
+	OverSubscribe     string // NO, YES:N, FORCE:N or EXCLUSIVE
+	MaxNodes          int    // Per job; 0 means unlimited
+	BillingWeights    string // TRESBillingWeights, e.g. "CPU=1.0,Mem=0.25G"
+	TRES              string // Configured TRES, e.g. "cpu=3200,mem=12T,node=100"
 }
 
 // PartitionList represents a list of partitions
@@ -321,26 +345,8 @@ func GetJobStateColor(state string) string {
 
 // GetNodeStateColor returns the color for a node state
 func GetNodeStateColor(state string) string {
-	// Handle compound states like "IDLE+DRAIN" - prioritize DRAIN over IDLE
-	if strings.Contains(state, NodeStateDrain) || strings.Contains(state, NodeStateDraining) {
-		return "red"
-	}
-	if strings.Contains(state, NodeStateDown) {
-		return "red"
-	}
-	if strings.Contains(state, NodeStateReserved) {
-		return "yellow"
-	}
-	if strings.Contains(state, NodeStateMaintenance) {
-		return "orange"
-	}
-	if strings.Contains(state, NodeStateAllocated) || strings.Contains(state, NodeStateMixed) {
-		return "blue"
-	}
-	if strings.Contains(state, NodeStateIdle) {
-		return "green"
-	}
-	return "white"
+	// Compound states like "IDLE+DRAIN" are colored by their most severe flag
+	return ParseNodeState(state).Color()
 }
 
 // IsJobActive returns true if the job is in an active state
@@ -355,12 +361,24 @@ func IsJobActive(state string) bool {
 
 // IsNodeAvailable returns true if the node is available for jobs
 func IsNodeAvailable(state string) bool {
-	switch state {
-	case NodeStateIdle, NodeStateMixed:
-		return true
-	default:
-		return false
-	}
+	s := ParseNodeState(state)
+	return (s.Base == NodeStateIdle || s.Base == NodeStateMixed) && s.AcceptsJobs() && !s.Has(NodeFlagReserved)
+}
+
+// partitionSettableStates are the states an administrator can set a
+// partition to
+var partitionSettableStates = []string{
+	PartitionStateUp, PartitionStateDown, PartitionStateDrain, PartitionStateInactive,
+}
+
+// PartitionSettableStates returns the states a partition can be set to
+func PartitionSettableStates() []string {
+	return slices.Clone(partitionSettableStates)
+}
+
+// IsSettablePartitionState reports whether a partition can be set to state
+func IsSettablePartitionState(state string) bool {
+	return slices.Contains(partitionSettableStates, state)
 }
 
 // GetPartitionStateColor returns the color for a partition state
@@ -0,0 +1,254 @@
+// Package efficiency computes seff-style resource efficiency of SLURM jobs
+// from their accounting data, without requiring an external metrics backend.
+package efficiency
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jontk/s9s/internal/dao"
+)
+
+const (
+	// PoorThreshold is the efficiency percentage below which a job is
+	// considered to waste most of its allocation
+	PoorThreshold = 30.0
+	// GoodThreshold is the efficiency percentage from which a job is
+	// considered to use its allocation well
+	GoodThreshold = 70.0
+)
+
+// Metric is the usage of one resource against its allocation
+type Metric struct {
+	Used      float64 // Amount used (core-seconds, MB, seconds or GPU-seconds)
+	Allocated float64 // Amount allocated in the same unit
+	Available bool    // Whether the job reports enough data to compute the metric
+}
+
+// Percent returns the used amount as a percentage of the allocation
+func (m Metric) Percent() float64 {
+	if !m.Available || m.Allocated <= 0 {
+		return 0
+	}
+	return m.Used / m.Allocated * 100
+}
+
+// Wasted returns the unused part of the allocation
+func (m Metric) Wasted() float64 {
+	if !m.Available {
+		return 0
+	}
+	return max(m.Allocated-m.Used, 0)
+}
+
+// Report is the efficiency of a single job
+type Report struct {
+	JobID   string
+	User    string
+	Account string
+	State   string
+	Elapsed time.Duration
+	CPUs    int
+	GPUs    int
+
+	CPU    Metric // Core-seconds: CPU time vs elapsed x allocated cores
+	Memory Metric // MB: peak RSS vs requested memory
+	Time   Metric // Seconds: elapsed vs time limit
+	GPU    Metric // GPU-seconds: average utilisation x elapsed x allocated GPUs
+}
+
+// Score returns the lowest of the CPU, memory and GPU efficiencies, which
+// is the resource the job wastes most. It returns false if none is known.
+func (r *Report) Score() (float64, bool) {
+	score, ok := 0.0, false
+	for _, m := range []Metric{r.CPU, r.Memory, r.GPU} {
+		if !m.Available {
+			continue
+		}
+		if p := m.Percent(); !ok || p < score {
+			score, ok = p, true
+		}
+	}
+	return score, ok
+}
+
+// HasUsage reports whether any resource usage is known for the job
+func (r *Report) HasUsage() bool {
+	return r.CPU.Available || r.Memory.Available || r.GPU.Available
+}
+
+// Calculate computes the efficiency of a job that has started running.
+// Running jobs are measured up to now. It returns false for jobs that have
+// not started.
+func Calculate(job *dao.Job, now time.Time) (*Report, bool) {
+	elapsed, ok := elapsedTime(job, now)
+	if !ok {
+		return nil, false
+	}
+
+	alloc := parseTRES(job.TRESAlloc)
+	report := &Report{
+		JobID:   job.ID,
+		User:    job.User,
+		Account: job.Account,
+		State:   job.State,
+		Elapsed: elapsed,
+		CPUs:    allocatedCPUs(job, alloc),
+		GPUs:    allocatedGPUs(alloc),
+	}
+	seconds := elapsed.Seconds()
+
+	if report.CPUs > 0 && job.TotalCPU > 0 {
+		report.CPU = Metric{
+			Used:      job.TotalCPU.Seconds(),
+			Allocated: seconds * float64(report.CPUs),
+			Available: true,
+		}
+	}
+
+	if requested := requestedMemoryMB(job, report.CPUs, alloc); requested > 0 && job.MaxRSS > 0 {
+		report.Memory = Metric{
+			Used:      float64(job.MaxRSS),
+			Allocated: float64(requested),
+			Available: true,
+		}
+	}
+
+	if limit, ok := dao.ParseTimeLimit(job.TimeLimit); ok {
+		report.Time = Metric{
+			Used:      seconds,
+			Allocated: limit.Seconds(),
+			Available: true,
+		}
+	}
+
+	if util, ok := gpuUtilization(job.TRESUsageAve); ok && report.GPUs > 0 {
+		allocated := seconds * float64(report.GPUs)
+		report.GPU = Metric{
+			Used:      allocated * util / 100,
+			Allocated: allocated,
+			Available: true,
+		}
+	}
+
+	return report, true
+}
+
+// elapsedTime returns how long a job has run, up to now for running jobs
+func elapsedTime(job *dao.Job, now time.Time) (time.Duration, bool) {
+	if job.State == dao.JobStatePending {
+		return 0, false
+	}
+	if job.StartTime != nil && !job.StartTime.IsZero() {
+		end := now
+		if job.EndTime != nil && !job.EndTime.IsZero() && job.EndTime.Before(now) && job.State != dao.JobStateRunning {
+			end = *job.EndTime
+		}
+		if elapsed := end.Sub(*job.StartTime); elapsed > 0 {
+			return elapsed, true
+		}
+		return 0, false
+	}
+	if job.TimeUsed != "" {
+		return dao.ParseTimeLimit(job.TimeUsed)
+	}
+	return 0, false
+}
+
+// allocatedCPUs returns the number of cores allocated to a job
+func allocatedCPUs(job *dao.Job, alloc map[string]string) int {
+	if job.CPUs > 0 {
+		return job.CPUs
+	}
+	if n, err := strconv.Atoi(alloc["cpu"]); err == nil && n > 0 {
+		return n
+	}
+	return 0
+}
+
+// allocatedGPUs returns the number of GPUs in an allocated TRES map,
+// preferring the untyped total over the sum of typed counts
+func allocatedGPUs(alloc map[string]string) int {
+	if n, err := strconv.Atoi(alloc["gres/gpu"]); err == nil && n > 0 {
+		return n
+	}
+	total := 0
+	for key, value := range alloc {
+		if !strings.HasPrefix(key, "gres/gpu:") {
+			continue
+		}
+		if n, err := strconv.Atoi(value); err == nil && n > 0 {
+			total += n
+		}
+	}
+	return total
+}
+
+// requestedMemoryMB returns the memory requested by a job in MB
+func requestedMemoryMB(job *dao.Job, cpus int, alloc map[string]string) int64 {
+	switch {
+	case job.MemoryPerNode > 0:
+		return job.MemoryPerNode * int64(max(job.NodeCount, 1))
+	case job.MemoryPerCPU > 0 && cpus > 0:
+		return job.MemoryPerCPU * int64(cpus)
+	}
+	if mem, ok := alloc["mem"]; ok {
+		return parseMemoryMB(mem)
+	}
+	return parseMemoryMB(parseTRES(job.TRESReq)["mem"])
+}
+
+// gpuUtilization returns the average GPU utilisation percentage from a TRES
+// usage string, as reported by SLURM's GPU accounting plugin
+func gpuUtilization(usage string) (float64, bool) {
+	value, ok := parseTRES(usage)["gres/gpuutil"]
+	if !ok {
+		return 0, false
+	}
+	util, err := strconv.ParseFloat(value, 64)
+	if err != nil || util < 0 {
+		return 0, false
+	}
+	return min(util, 100), true
+}
+
+// parseTRES splits a TRES string such as "cpu=8,mem=32G,gres/gpu=2" into a map
+func parseTRES(tres string) map[string]string {
+	result := make(map[string]string)
+	for _, entry := range strings.Split(tres, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if ok && key != "" {
+			result[key] = value
+		}
+	}
+	return result
+}
+
+// parseMemoryMB parses a SLURM memory amount such as "4000M" or "32G" into
+// MB; amounts without a unit are in MB
+func parseMemoryMB(mem string) int64 {
+	mem = strings.ToUpper(strings.TrimSpace(mem))
+	if mem == "" {
+		return 0
+	}
+
+	multiplier := 1.0
+	switch mem[len(mem)-1] {
+	case 'K':
+		multiplier = 1.0 / 1024
+	case 'M':
+	case 'G':
+		multiplier = 1024
+	case 'T':
+		multiplier = 1024 * 1024
+	default:
+		mem += "M"
+	}
+
+	value, err := strconv.ParseFloat(mem[:len(mem)-1], 64)
+	if err != nil || value < 0 {
+		return 0
+	}
+	return int64(value * multiplier)
+}
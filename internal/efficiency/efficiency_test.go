@@ -0,0 +1,134 @@
+package efficiency
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jontk/s9s/internal/dao"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func completedJob(user string, start time.Time, elapsed time.Duration) *dao.Job {
+	end := start.Add(elapsed)
+	return &dao.Job{
+		ID:        "1",
+		User:      user,
+		State:     dao.JobStateCompleted,
+		StartTime: &start,
+		EndTime:   &end,
+		TimeLimit: "240",
+	}
+}
+
+func TestCalculate_CompletedJob(t *testing.T) {
+	now := time.Now()
+	job := completedJob("alice", now.Add(-3*time.Hour), time.Hour)
+	job.CPUs = 8
+	job.NodeCount = 2
+	job.MemoryPerNode = 8192
+	job.TotalCPU = 2 * time.Hour
+	job.MaxRSS = 4096
+	job.TRESAlloc = "cpu=8,mem=16G,node=2,gres/gpu=2,gres/gpu:a100=2"
+	job.TRESUsageAve = "cpu=00:15:00,gres/gpuutil=40"
+
+	report, ok := Calculate(job, now)
+	require.True(t, ok)
+	assert.Equal(t, time.Hour, report.Elapsed)
+	assert.Equal(t, 2, report.GPUs)
+
+	assert.InDelta(t, 25.0, report.CPU.Percent(), 0.01)
+	assert.InDelta(t, 6*3600.0, report.CPU.Wasted(), 0.01)
+	assert.InDelta(t, 25.0, report.Memory.Percent(), 0.01)
+	assert.InDelta(t, 25.0, report.Time.Percent(), 0.01)
+	assert.InDelta(t, 40.0, report.GPU.Percent(), 0.01)
+
+	score, ok := report.Score()
+	require.True(t, ok)
+	assert.InDelta(t, 25.0, score, 0.01)
+}
+
+func TestCalculate_RunningJobUsesNow(t *testing.T) {
+	now := time.Now()
+	start := now.Add(-30 * time.Minute)
+	expectedEnd := now.Add(time.Hour)
+	job := &dao.Job{
+		State:     dao.JobStateRunning,
+		StartTime: &start,
+		EndTime:   &expectedEnd,
+		TRESAlloc: "cpu=4,mem=4000M",
+		TotalCPU:  time.Hour,
+		MaxRSS:    1000,
+	}
+
+	report, ok := Calculate(job, now)
+	require.True(t, ok)
+	assert.Equal(t, 30*time.Minute, report.Elapsed)
+	assert.Equal(t, 4, report.CPUs)
+	assert.InDelta(t, 50.0, report.CPU.Percent(), 0.01)
+	assert.InDelta(t, 25.0, report.Memory.Percent(), 0.01)
+	assert.False(t, report.Time.Available, "time limit 0 is unlimited")
+	assert.False(t, report.GPU.Available)
+}
+
+func TestCalculate_WithoutUsage(t *testing.T) {
+	now := time.Now()
+	report, ok := Calculate(completedJob("bob", now.Add(-2*time.Hour), time.Hour), now)
+	require.True(t, ok)
+	assert.False(t, report.HasUsage())
+	assert.True(t, report.Time.Available)
+	_, ok = report.Score()
+	assert.False(t, ok)
+
+	_, ok = Calculate(&dao.Job{State: dao.JobStatePending, SubmitTime: now}, now)
+	assert.False(t, ok)
+}
+
+func TestParseMemoryMB(t *testing.T) {
+	tests := map[string]int64{
+		"4000":  4000,
+		"4000M": 4000,
+		"32G":   32768,
+		"1.5G":  1536,
+		"1T":    1048576,
+		"2048K": 2,
+		"":      0,
+		"abc":   0,
+	}
+	for input, want := range tests {
+		assert.Equal(t, want, parseMemoryMB(input), input)
+	}
+}
+
+func TestSummarizeWaste(t *testing.T) {
+	now := time.Now()
+
+	alice := completedJob("alice", now.Add(-5*time.Hour), 2*time.Hour)
+	alice.CPUs = 10
+	alice.TotalCPU = 5 * time.Hour
+	alice.MemoryPerCPU = 1024
+	alice.MaxRSS = 2560
+
+	bob := completedJob("bob", now.Add(-5*time.Hour), time.Hour)
+	bob.CPUs = 4
+	bob.TotalCPU = 4 * time.Hour
+
+	noUsage := completedJob("carol", now.Add(-5*time.Hour), time.Hour)
+
+	summaries := SummarizeWaste([]*dao.Job{bob, alice, noUsage}, now)
+	require.Len(t, summaries, 2)
+
+	assert.Equal(t, "alice", summaries[0].User)
+	assert.Equal(t, 1, summaries[0].Jobs)
+	assert.InDelta(t, 20.0, summaries[0].CPUHours, 0.01)
+	assert.InDelta(t, 15.0, summaries[0].CPUHoursWasted, 0.01)
+	assert.InDelta(t, 25.0, summaries[0].CPUEfficiency(), 0.01)
+	assert.InDelta(t, 20.0, summaries[0].MemGBHours, 0.01)
+	assert.InDelta(t, 15.0, summaries[0].MemGBHoursWasted, 0.01)
+	assert.InDelta(t, 25.0, summaries[0].MemoryEfficiency(), 0.01)
+
+	assert.Equal(t, "bob", summaries[1].User)
+	assert.InDelta(t, 0.0, summaries[1].CPUHoursWasted, 0.01)
+	assert.InDelta(t, 100.0, summaries[1].CPUEfficiency(), 0.01)
+	assert.Zero(t, summaries[1].MemGBHours)
+}
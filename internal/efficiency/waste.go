@@ -0,0 +1,89 @@
+package efficiency
+
+import (
+	"sort"
+	"time"
+
+	"github.com/jontk/s9s/internal/dao"
+)
+
+// UserWaste summarises how much of their allocations a user's jobs left unused
+type UserWaste struct {
+	User string
+	Jobs int // Jobs with known resource usage
+
+	CPUHours         float64 // Allocated core-hours
+	CPUHoursWasted   float64 // Allocated but unused core-hours
+	MemGBHours       float64 // Requested memory GB-hours
+	MemGBHoursWasted float64 // Requested but unused memory GB-hours
+	GPUHours         float64 // Allocated GPU-hours
+	GPUHoursWasted   float64 // Allocated but idle GPU-hours
+}
+
+// CPUEfficiency returns the percentage of allocated core-hours that were used
+func (w *UserWaste) CPUEfficiency() float64 {
+	return usedPercent(w.CPUHours, w.CPUHoursWasted)
+}
+
+// MemoryEfficiency returns the percentage of requested memory that was used
+func (w *UserWaste) MemoryEfficiency() float64 {
+	return usedPercent(w.MemGBHours, w.MemGBHoursWasted)
+}
+
+// GPUEfficiency returns the percentage of allocated GPU-hours that were used
+func (w *UserWaste) GPUEfficiency() float64 {
+	return usedPercent(w.GPUHours, w.GPUHoursWasted)
+}
+
+// SummarizeWaste aggregates the unused allocation of jobs per user, sorted by
+// wasted core-hours. Jobs without known resource usage are skipped.
+func SummarizeWaste(jobs []*dao.Job, now time.Time) []*UserWaste {
+	byUser := make(map[string]*UserWaste)
+	for _, job := range jobs {
+		report, ok := Calculate(job, now)
+		if !ok || !report.HasUsage() {
+			continue
+		}
+
+		w, ok := byUser[job.User]
+		if !ok {
+			w = &UserWaste{User: job.User}
+			byUser[job.User] = w
+		}
+		w.Jobs++
+
+		hours := report.Elapsed.Hours()
+		if report.CPU.Available {
+			w.CPUHours += report.CPU.Allocated / 3600
+			w.CPUHoursWasted += report.CPU.Wasted() / 3600
+		}
+		if report.Memory.Available {
+			w.MemGBHours += report.Memory.Allocated / 1024 * hours
+			w.MemGBHoursWasted += report.Memory.Wasted() / 1024 * hours
+		}
+		if report.GPU.Available {
+			w.GPUHours += report.GPU.Allocated / 3600
+			w.GPUHoursWasted += report.GPU.Wasted() / 3600
+		}
+	}
+
+	summaries := make([]*UserWaste, 0, len(byUser))
+	for _, w := range byUser {
+		summaries = append(summaries, w)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].CPUHoursWasted != summaries[j].CPUHoursWasted {
+			return summaries[i].CPUHoursWasted > summaries[j].CPUHoursWasted
+		}
+		return summaries[i].User < summaries[j].User
+	})
+	return summaries
+}
+
+// usedPercent returns the used share of a total as a percentage
+func usedPercent(total, wasted float64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return (total - wasted) / total * 100
+}
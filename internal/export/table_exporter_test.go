@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/jontk/s9s/internal/dao"
+	"github.com/jontk/s9s/internal/efficiency"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -111,15 +112,22 @@ func TestJobsTableData(t *testing.T) {
 
 func TestNodesTableData(t *testing.T) {
 	nodes := []*dao.Node{
-		{Name: "node01", State: "IDLE", Partitions: []string{"cpu", "gpu"},
-			CPUsTotal: 48, CPUsAllocated: 12, CPUsIdle: 36, MemoryTotal: 256000},
+		{Name: "node01", State: "MIXED+DRAIN+COMPLETING", Partitions: []string{"cpu", "gpu"},
+			CPUsTotal: 48, CPUsAllocated: 12, CPUsIdle: 36, MemoryTotal: 256000,
+			GRES: dao.ParseGRES("gpu:a100:4,shard:a100:16", "gpu:a100:1")},
 	}
 	td := NodesTableData(nodes)
 	assert.Equal(t, "Nodes", td.Title)
 	require.Len(t, td.Rows, 1)
 	assert.Equal(t, "node01", td.Rows[0][0])
-	assert.Equal(t, "cpu,gpu", td.Rows[0][2])
-	assert.Equal(t, "48", td.Rows[0][3])
+	assert.Equal(t, "MIXED+DRAIN+COMPLETING", td.Rows[0][1])
+	assert.Equal(t, "MIXED", td.Rows[0][2])
+	assert.Equal(t, "DRAIN,COMPLETING", td.Rows[0][3])
+	assert.Equal(t, "cpu,gpu", td.Rows[0][4])
+	assert.Equal(t, "48", td.Rows[0][5])
+	assert.Equal(t, "4", td.Rows[0][12])
+	assert.Equal(t, "1", td.Rows[0][13])
+	assert.Equal(t, "gpu:a100:4,shard:a100:16", td.Rows[0][14])
 }
 
 func TestPartitionsTableData(t *testing.T) {
@@ -181,3 +189,16 @@ func TestUsersTableData(t *testing.T) {
 	assert.Equal(t, "1001", td.Rows[0][1])
 	assert.Equal(t, "research,ml", td.Rows[0][3])
 }
+
+func TestWasteTableData(t *testing.T) {
+	summaries := []*efficiency.UserWaste{
+		{User: "alice", Jobs: 3, CPUHours: 20, CPUHoursWasted: 15},
+	}
+	td := WasteTableData(summaries)
+	assert.Equal(t, "Efficiency Waste", td.Title)
+	require.Len(t, td.Rows, 1)
+	assert.Equal(t, "alice", td.Rows[0][0])
+	assert.Equal(t, "15.0", td.Rows[0][3])
+	assert.Equal(t, "25.0", td.Rows[0][4])
+	assert.Empty(t, td.Rows[0][7], "no memory usage measured")
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/jontk/s9s/internal/dao"
+	"github.com/jontk/s9s/internal/efficiency"
 )
 
 // JobsTableData converts a slice of jobs to TableData for export.
@@ -41,9 +42,9 @@ func JobsTableData(jobs []*dao.Job) *TableData {
 // NodesTableData converts a slice of nodes to TableData for export.
 func NodesTableData(nodes []*dao.Node) *TableData {
 	headers := []string{
-		"Name", "State", "Partitions", "CPUs Total", "CPUs Alloc",
+		"Name", "State", "Base State", "State Flags", "Partitions", "CPUs Total", "CPUs Alloc",
 		"CPUs Idle", "CPU Load", "Memory Total (MB)", "Memory Alloc (MB)",
-		"Memory Free (MB)", "Features", "Reason",
+		"Memory Free (MB)", "GPUs Total", "GPUs Alloc", "GRES", "Features", "Reason",
 	}
 
 	rows := make([][]string, len(nodes))
@@ -52,9 +53,17 @@ func NodesTableData(nodes []*dao.Node) *TableData {
 		if n.CPULoad >= 0 {
 			cpuLoad = fmt.Sprintf("%.2f", n.CPULoad)
 		}
+		state := n.ParsedState()
+		gpusTotal, gpusAllocated := n.GPUCounts()
+		gres := make([]string, len(n.GRES))
+		for j, g := range n.GRES {
+			gres[j] = g.String()
+		}
 		rows[i] = []string{
 			n.Name,
 			n.State,
+			state.Base,
+			strings.Join(state.Flags, ","),
 			strings.Join(n.Partitions, ","),
 			fmt.Sprintf("%d", n.CPUsTotal),
 			fmt.Sprintf("%d", n.CPUsAllocated),
@@ -63,6 +72,9 @@ func NodesTableData(nodes []*dao.Node) *TableData {
 			fmt.Sprintf("%d", n.MemoryTotal),
 			fmt.Sprintf("%d", n.MemoryAllocated),
 			fmt.Sprintf("%d", n.MemoryFree),
+			fmt.Sprintf("%d", gpusTotal),
+			fmt.Sprintf("%d", gpusAllocated),
+			strings.Join(gres, ","),
 			strings.Join(n.Features, ","),
 			n.Reason,
 		}
@@ -211,6 +223,42 @@ func UsersTableData(users []*dao.User) *TableData {
 	return &TableData{Title: "Users", Headers: headers, Rows: rows, ExportedAt: time.Now()}
 }
 
+// WasteTableData converts a per-user efficiency waste summary to TableData for export.
+func WasteTableData(summaries []*efficiency.UserWaste) *TableData {
+	headers := []string{
+		"User", "Jobs",
+		"CPU Hours", "CPU Hours Wasted", "CPU Eff %",
+		"Mem GB-Hours", "Mem GB-Hours Wasted", "Mem Eff %",
+		"GPU Hours", "GPU Hours Wasted", "GPU Eff %",
+	}
+
+	rows := make([][]string, len(summaries))
+	for i, w := range summaries {
+		rows[i] = []string{
+			w.User,
+			fmt.Sprintf("%d", w.Jobs),
+			fmt.Sprintf("%.1f", w.CPUHours),
+			fmt.Sprintf("%.1f", w.CPUHoursWasted),
+			formatEfficiency(w.CPUHours, w.CPUEfficiency()),
+			fmt.Sprintf("%.1f", w.MemGBHours),
+			fmt.Sprintf("%.1f", w.MemGBHoursWasted),
+			formatEfficiency(w.MemGBHours, w.MemoryEfficiency()),
+			fmt.Sprintf("%.1f", w.GPUHours),
+			fmt.Sprintf("%.1f", w.GPUHoursWasted),
+			formatEfficiency(w.GPUHours, w.GPUEfficiency()),
+		}
+	}
+	return &TableData{Title: "Efficiency Waste", Headers: headers, Rows: rows, ExportedAt: time.Now()}
+}
+
+// formatEfficiency formats an efficiency percentage, or nothing if no usage was measured.
+func formatEfficiency(total, percent float64) string {
+	if total <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%.1f", percent)
+}
+
 // formatDuration formats a duration in HH:MM:SS style (same as the views package).
 func formatDuration(d time.Duration) string {
 	d = d.Round(time.Second)
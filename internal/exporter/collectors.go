@@ -0,0 +1,244 @@
+package exporter
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jontk/s9s/internal/dao"
+	"github.com/jontk/s9s/internal/monitoring"
+)
+
+// waitQuantiles are the quantiles reported for pending job wait times
+var waitQuantiles = []float64{0.5, 0.9, 0.99}
+
+// healthStates are the states of the health stateset, in severity order
+var healthStates = []monitoring.HealthStatus{
+	monitoring.HealthStatusHealthy,
+	monitoring.HealthStatusWarning,
+	monitoring.HealthStatusCritical,
+	monitoring.HealthStatusUnknown,
+}
+
+// labelCounter counts occurrences of label value tuples
+type labelCounter map[[2]string]float64
+
+// addTo appends the counts to a family, sorted by label values
+func (c labelCounter) addTo(f *metricFamily, first, second string) {
+	keys := make([][2]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i][0] != keys[j][0] {
+			return keys[i][0] < keys[j][0]
+		}
+		return keys[i][1] < keys[j][1]
+	})
+	for _, k := range keys {
+		f.add(c[k], first, k[0], second, k[1])
+	}
+}
+
+// clusterInfoFamily reports the cluster name and SLURM version
+func clusterInfoFamily(info *dao.ClusterInfo) *metricFamily {
+	f := newFamily("s9s_cluster", typeInfo, "", "SLURM cluster information")
+	f.addSuffixed("_info", 1, "cluster", info.Name, "version", info.Version)
+	return f
+}
+
+// jobFamilies reports job counts by partition, user and account
+func jobFamilies(jobs []*dao.Job) []*metricFamily {
+	byPartition := labelCounter{}
+	byUser := labelCounter{}
+	byAccount := labelCounter{}
+	for _, job := range jobs {
+		state := job.State
+		byPartition[[2]string{job.Partition, state}]++
+		byUser[[2]string{job.User, state}]++
+		byAccount[[2]string{job.Account, state}]++
+	}
+
+	partitionJobs := newFamily("s9s_jobs", typeGauge, "", "Number of jobs by partition and state")
+	byPartition.addTo(partitionJobs, "partition", "state")
+	userJobs := newFamily("s9s_user_jobs", typeGauge, "", "Number of jobs by user and state")
+	byUser.addTo(userJobs, "user", "state")
+	accountJobs := newFamily("s9s_account_jobs", typeGauge, "", "Number of jobs by account and state")
+	byAccount.addTo(accountJobs, "account", "state")
+
+	return []*metricFamily{partitionJobs, userJobs, accountJobs}
+}
+
+// queueWaitFamilies reports wait time quantiles of pending jobs per partition
+func queueWaitFamilies(jobs []*dao.Job, partitions []*dao.Partition, now time.Time) []*metricFamily {
+	waits := make(map[string][]float64)
+	for _, job := range jobs {
+		if job.State == dao.JobStatePending {
+			waits[job.Partition] = append(waits[job.Partition], now.Sub(job.SubmitTime).Seconds())
+		}
+	}
+
+	summary := newFamily("s9s_queue_wait_seconds", typeSummary, "seconds", "Time pending jobs have waited in the queue")
+	longest := newFamily("s9s_queue_longest_wait_seconds", typeGauge, "seconds", "Longest wait of a pending job in the queue")
+
+	queueInfo := dao.BuildQueueInfo(jobs, partitions, now)
+	for _, name := range sortedKeys(queueInfo) {
+		values := waits[name]
+		sort.Float64s(values)
+
+		var sum float64
+		for _, v := range values {
+			sum += v
+		}
+		for _, q := range waitQuantiles {
+			summary.add(quantile(values, q), "partition", name, "quantile", strconv.FormatFloat(q, 'g', -1, 64))
+		}
+		summary.addSuffixed("_sum", sum, "partition", name)
+		summary.addSuffixed("_count", float64(len(values)), "partition", name)
+
+		longest.add(queueInfo[name].LongestWait.Seconds(), "partition", name)
+	}
+
+	return []*metricFamily{summary, longest}
+}
+
+// quantile returns the nearest-rank quantile of sorted values, or NaN if empty
+func quantile(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return math.NaN()
+	}
+	rank := int(math.Ceil(q*float64(len(sorted)))) - 1
+	return sorted[min(max(rank, 0), len(sorted)-1)]
+}
+
+// nodeFamilies reports node states and CPU/memory allocation
+func nodeFamilies(nodes []*dao.Node) []*metricFamily {
+	states := map[string]float64{}
+	flags := map[string]float64{}
+	partitionStates := labelCounter{}
+	partitionCPUs := map[string][2]float64{}
+
+	var cpusTotal, cpusAlloc, memTotal, memAlloc float64
+	for _, node := range nodes {
+		parsed := node.ParsedState()
+		state := parsed.Base
+		if state == "" {
+			state = dao.NodeStateUnknown
+		}
+		states[state]++
+		for _, flag := range parsed.Flags {
+			flags[flag]++
+		}
+
+		cpusTotal += float64(node.CPUsTotal)
+		cpusAlloc += float64(node.CPUsAllocated)
+		memTotal += float64(node.MemoryTotal) * 1024 * 1024
+		memAlloc += float64(node.MemoryAllocated) * 1024 * 1024
+
+		for _, partition := range node.Partitions {
+			partitionStates[[2]string{partition, state}]++
+			cpus := partitionCPUs[partition]
+			partitionCPUs[partition] = [2]float64{cpus[0] + float64(node.CPUsTotal), cpus[1] + float64(node.CPUsAllocated)}
+		}
+	}
+
+	nodeStates := newFamily("s9s_nodes", typeGauge, "", "Number of nodes by state")
+	for _, state := range sortedKeys(states) {
+		nodeStates.add(states[state], "state", state)
+	}
+	nodeFlags := newFamily("s9s_node_flags", typeGauge, "", "Number of nodes by state flag, such as DRAIN or NOT_RESPONDING")
+	for _, flag := range sortedKeys(flags) {
+		nodeFlags.add(flags[flag], "flag", flag)
+	}
+	partitionNodes := newFamily("s9s_partition_nodes", typeGauge, "", "Number of nodes by partition and state; nodes in several partitions are counted in each")
+	partitionStates.addTo(partitionNodes, "partition", "state")
+
+	cpusConfigured := newFamily("s9s_cpus_configured", typeGauge, "", "Number of configured CPUs")
+	cpusConfigured.add(cpusTotal)
+	cpusAllocated := newFamily("s9s_cpus_allocated", typeGauge, "", "Number of allocated CPUs")
+	cpusAllocated.add(cpusAlloc)
+	memConfigured := newFamily("s9s_memory_configured_bytes", typeGauge, "bytes", "Configured node memory")
+	memConfigured.add(memTotal)
+	memAllocated := newFamily("s9s_memory_allocated_bytes", typeGauge, "bytes", "Allocated node memory")
+	memAllocated.add(memAlloc)
+
+	partitionConfigured := newFamily("s9s_partition_cpus_configured", typeGauge, "", "Number of configured CPUs by partition")
+	partitionAllocated := newFamily("s9s_partition_cpus_allocated", typeGauge, "", "Number of allocated CPUs by partition")
+	for _, partition := range sortedKeys(partitionCPUs) {
+		partitionConfigured.add(partitionCPUs[partition][0], "partition", partition)
+		partitionAllocated.add(partitionCPUs[partition][1], "partition", partition)
+	}
+
+	return []*metricFamily{
+		nodeStates, nodeFlags, partitionNodes,
+		cpusConfigured, cpusAllocated, memConfigured, memAllocated,
+		partitionConfigured, partitionAllocated,
+	}
+}
+
+// gpuFamily reports GPUs allocated to running jobs by partition and GPU type
+func gpuFamily(jobs []*dao.Job) *metricFamily {
+	allocated := labelCounter{}
+	for _, job := range jobs {
+		if job.State != dao.JobStateRunning {
+			continue
+		}
+		for gpuType, count := range parseTRESGPUs(job.TRESAlloc) {
+			allocated[[2]string{job.Partition, gpuType}] += float64(count)
+		}
+	}
+
+	f := newFamily("s9s_gpus_allocated", typeGauge, "", "Number of GPUs allocated to running jobs by partition and GPU type")
+	allocated.addTo(f, "partition", "type")
+	return f
+}
+
+// parseTRESGPUs returns the GPU counts in a TRES string such as
+// "cpu=8,mem=32G,gres/gpu=2,gres/gpu:a100=2". Typed counts are preferred;
+// an untyped total is reported with an empty type.
+func parseTRESGPUs(tres string) map[string]int {
+	typed := map[string]int{}
+	untyped := 0
+	for _, entry := range strings.Split(tres, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok || (key != "gres/gpu" && !strings.HasPrefix(key, "gres/gpu:")) {
+			continue
+		}
+		count, err := strconv.Atoi(value)
+		if err != nil || count <= 0 {
+			continue
+		}
+		if gpuType := strings.TrimPrefix(key, "gres/gpu"); gpuType != "" {
+			typed[strings.TrimPrefix(gpuType, ":")] += count
+		} else {
+			untyped += count
+		}
+	}
+
+	if len(typed) > 0 {
+		return typed
+	}
+	if untyped > 0 {
+		return map[string]int{"": untyped}
+	}
+	return nil
+}
+
+// healthFamily reports the status of each health check as a stateset
+func healthFamily(health *monitoring.ClusterHealth) *metricFamily {
+	f := newFamily("s9s_health", typeStateSet, "", "Cluster health status by check")
+
+	addCheck := func(check string, status monitoring.HealthStatus) {
+		for _, state := range healthStates {
+			f.add(boolValue(status == state), "check", check, "s9s_health", string(state))
+		}
+	}
+
+	addCheck("overall", health.OverallStatus)
+	for _, name := range sortedKeys(health.Checks) {
+		addCheck(name, health.Checks[name].Status)
+	}
+	return f
+}
@@ -0,0 +1,256 @@
+// Package exporter serves SLURM cluster metrics collected through the DAO in
+// the OpenMetrics text format, so s9s can be scraped by Prometheus.
+package exporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jontk/s9s/internal/dao"
+	"github.com/jontk/s9s/internal/logging"
+	"github.com/jontk/s9s/internal/monitoring"
+)
+
+const (
+	// DefaultListenAddress is the address the exporter listens on by default
+	DefaultListenAddress = ":9341"
+	// DefaultInterval is the default time between polls of the cluster
+	DefaultInterval = 30 * time.Second
+	// DefaultJobPageSize is the number of jobs fetched per request; a poll
+	// pages through the whole job list
+	DefaultJobPageSize = 100_000
+
+	metricsCacheKey = "exporter:metrics"
+	// staleAfter is the number of poll intervals after which cached metrics
+	// are dropped and the exporter reports itself as down
+	staleAfter = 3
+)
+
+// Options configures an Exporter
+type Options struct {
+	Interval    time.Duration // Time between polls (default: DefaultInterval)
+	JobPageSize int           // Number of jobs fetched per request (default: DefaultJobPageSize)
+
+	// Rules are evaluated with the health checks and reported in s9s_health
+	Rules *monitoring.RuleEngine
+}
+
+// Exporter periodically polls a SLURM cluster and serves the collected
+// metrics. Scrapes are answered from the cache, so the scrape rate does not
+// affect the load on slurmrestd.
+type Exporter struct {
+	client dao.SlurmClient
+	cache  *dao.DAOCache
+	health *monitoring.HealthMonitor
+	opts   Options
+
+	mu           sync.RWMutex
+	lastSuccess  time.Time
+	lastDuration time.Duration
+	pollErrors   int
+}
+
+// New creates an exporter for the given client
+func New(client dao.SlurmClient, opts Options) *Exporter {
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultInterval
+	}
+	if opts.JobPageSize <= 0 {
+		opts.JobPageSize = DefaultJobPageSize
+	}
+
+	health := monitoring.NewHealthMonitor(client, opts.Interval)
+	if opts.Rules != nil {
+		health.SetRuleEngine(opts.Rules)
+	}
+
+	return &Exporter{
+		client: client,
+		cache:  dao.NewDAOCache(staleAfter*opts.Interval, 1),
+		health: health,
+		opts:   opts,
+	}
+}
+
+// Run polls the cluster immediately and then at every interval until the
+// context is cancelled
+func (e *Exporter) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := e.Poll(); err != nil {
+			logging.Warnf("Exporter poll failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll collects metrics from the cluster once and caches them for scrapes
+func (e *Exporter) Poll() error {
+	start := time.Now()
+	families, err := e.collect(start)
+	duration := time.Since(start)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.lastDuration = duration
+	if err != nil {
+		e.pollErrors++
+		return err
+	}
+
+	e.lastSuccess = start
+	e.cache.Set(metricsCacheKey, families, 0)
+	return nil
+}
+
+// ServeHTTP writes the latest metrics in the OpenMetrics text format
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	var families []*metricFamily
+	cached, up := e.cache.Get(metricsCacheKey)
+	if up {
+		families = append(families, cached.([]*metricFamily)...)
+	}
+	families = append(families, e.selfFamilies(up)...)
+
+	w.Header().Set("Content-Type", ContentType)
+	if err := writeOpenMetrics(w, families); err != nil {
+		logging.Warnf("Failed to write metrics: %v", err)
+	}
+}
+
+// Handler returns an HTTP handler serving metrics on the given path and a
+// short landing page on "/"
+func (e *Exporter) Handler(metricsPath string) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle(metricsPath, e)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = fmt.Fprintf(w, "<html><head><title>s9s exporter</title></head><body><h1>s9s exporter</h1><p><a href=%q>Metrics</a></p></body></html>\n", metricsPath)
+	})
+	return mux
+}
+
+// ListenAndServe polls the cluster and serves metrics on addr until the
+// context is cancelled
+func (e *Exporter) ListenAndServe(ctx context.Context, addr, metricsPath string) error {
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           e.Handler(metricsPath),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go e.Run(ctx)
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errChan:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	}
+}
+
+// collect fetches cluster state and builds all cluster metric families
+func (e *Exporter) collect(now time.Time) ([]*metricFamily, error) {
+	jobs, err := e.listJobs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	nodeList, err := e.client.Nodes().List(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	partitionList, err := e.client.Partitions().List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list partitions: %w", err)
+	}
+
+	var families []*metricFamily
+	if info, err := e.client.ClusterInfo(); err == nil && info != nil {
+		families = append(families, clusterInfoFamily(info))
+	}
+	families = append(families, jobFamilies(jobs)...)
+	families = append(families, queueWaitFamilies(jobs, partitionList.Partitions, now)...)
+	families = append(families, nodeFamilies(nodeList.Nodes)...)
+	families = append(families, gpuFamily(jobs))
+	families = append(families, healthFamily(e.health.RunChecks()))
+	return families, nil
+}
+
+// listJobs pages through the whole job list. Jobs that move between pages
+// while paging are counted once.
+func (e *Exporter) listJobs() ([]*dao.Job, error) {
+	var jobs []*dao.Job
+	seen := make(map[string]bool)
+	for offset := 0; ; {
+		page, err := e.client.Jobs().List(&dao.ListJobsOptions{Limit: e.opts.JobPageSize, Offset: offset})
+		if err != nil {
+			return nil, err
+		}
+		for _, job := range page.Jobs {
+			if !seen[job.ID] {
+				seen[job.ID] = true
+				jobs = append(jobs, job)
+			}
+		}
+
+		offset += len(page.Jobs)
+		if len(page.Jobs) < e.opts.JobPageSize || offset >= page.Total {
+			return jobs, nil
+		}
+	}
+}
+
+// selfFamilies reports the state of the exporter itself
+func (e *Exporter) selfFamilies(up bool) []*metricFamily {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	upFamily := newFamily("s9s_up", typeGauge, "", "Whether the last poll of the SLURM cluster succeeded recently")
+	upFamily.add(boolValue(up))
+
+	lastPoll := newFamily("s9s_last_poll_timestamp_seconds", typeGauge, "seconds", "Time of the last successful poll")
+	if !e.lastSuccess.IsZero() {
+		lastPoll.add(float64(e.lastSuccess.UnixNano()) / 1e9)
+	}
+
+	duration := newFamily("s9s_poll_duration_seconds", typeGauge, "seconds", "Duration of the last poll")
+	duration.add(e.lastDuration.Seconds())
+
+	pollErrors := newFamily("s9s_poll_errors", typeCounter, "", "Number of failed polls")
+	pollErrors.addSuffixed("_total", float64(e.pollErrors))
+
+	return []*metricFamily{upFamily, lastPoll, duration, pollErrors}
+}
+
+// boolValue converts a boolean to a sample value
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,187 @@
+package exporter
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jontk/s9s/internal/dao"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClient serves fixed jobs, nodes and partitions
+type fakeClient struct {
+	dao.SlurmClient
+	jobs       []*dao.Job
+	nodes      []*dao.Node
+	partitions []*dao.Partition
+	jobsErr    error
+
+	jobOffsets []int // Offsets of the paged job requests
+}
+
+func (c *fakeClient) Jobs() dao.JobManager   { return &fakeJobs{client: c} }
+func (c *fakeClient) Nodes() dao.NodeManager { return &fakeNodes{nodes: c.nodes} }
+func (c *fakeClient) Partitions() dao.PartitionManager {
+	return &fakePartitions{partitions: c.partitions}
+}
+func (c *fakeClient) Info() dao.InfoManager { return nil }
+func (c *fakeClient) ClusterInfo() (*dao.ClusterInfo, error) {
+	return &dao.ClusterInfo{Name: "test", Version: "24.05"}, nil
+}
+
+type fakeJobs struct {
+	dao.JobManager
+	client *fakeClient
+}
+
+func (m *fakeJobs) List(opts *dao.ListJobsOptions) (*dao.JobList, error) {
+	if opts != nil && opts.Limit > 0 {
+		m.client.jobOffsets = append(m.client.jobOffsets, opts.Offset)
+	}
+	if m.client.jobsErr != nil {
+		return nil, m.client.jobsErr
+	}
+	jobs := m.client.jobs
+	if opts != nil {
+		jobs = jobs[min(opts.Offset, len(jobs)):]
+	}
+	if opts != nil && opts.Limit > 0 && opts.Limit < len(jobs) {
+		jobs = jobs[:opts.Limit]
+	}
+	return &dao.JobList{Jobs: jobs, Total: len(m.client.jobs)}, nil
+}
+
+type fakeNodes struct {
+	dao.NodeManager
+	nodes []*dao.Node
+}
+
+func (m *fakeNodes) List(_ *dao.ListNodesOptions) (*dao.NodeList, error) {
+	return &dao.NodeList{Nodes: m.nodes, Total: len(m.nodes)}, nil
+}
+
+type fakePartitions struct {
+	dao.PartitionManager
+	partitions []*dao.Partition
+}
+
+func (m *fakePartitions) List() (*dao.PartitionList, error) {
+	return &dao.PartitionList{Partitions: m.partitions}, nil
+}
+
+func newTestClient(now time.Time) *fakeClient {
+	return &fakeClient{
+		jobs: []*dao.Job{
+			{ID: "1", User: "alice", Account: "phys", Partition: "gpu", State: dao.JobStateRunning, TRESAlloc: "cpu=8,mem=32G,gres/gpu=2,gres/gpu:a100=2"},
+			{ID: "2", User: "bob", Account: "chem", Partition: "gpu", State: dao.JobStateRunning, TRESAlloc: "cpu=4,gres/gpu=1"},
+			{ID: "3", User: "alice", Account: "phys", Partition: "cpu", State: dao.JobStatePending, SubmitTime: now.Add(-10 * time.Second)},
+			{ID: "4", User: "alice", Account: "phys", Partition: "cpu", State: dao.JobStatePending, SubmitTime: now.Add(-20 * time.Second)},
+			{ID: "5", User: "bob", Account: "chem", Partition: "cpu", State: dao.JobStatePending, SubmitTime: now.Add(-30 * time.Second)},
+		},
+		nodes: []*dao.Node{
+			{Name: "g1", State: "MIXED", Partitions: []string{"gpu"}, CPUsTotal: 64, CPUsAllocated: 12, MemoryTotal: 1024, MemoryAllocated: 512},
+			{Name: "c1", State: "IDLE+DRAIN", Partitions: []string{"cpu"}, CPUsTotal: 32},
+			{Name: "c2", State: "idle*", Partitions: []string{"cpu"}, CPUsTotal: 32},
+		},
+		partitions: []*dao.Partition{{Name: "cpu"}, {Name: "gpu"}},
+	}
+}
+
+func scrape(t *testing.T, e *Exporter) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	e.Handler("/metrics").ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, ContentType, rec.Header().Get("Content-Type"))
+	return rec.Body.String()
+}
+
+func TestExporter_Metrics(t *testing.T) {
+	e := New(newTestClient(time.Now()), Options{Interval: time.Minute})
+	require.NoError(t, e.Poll())
+
+	body := scrape(t, e)
+	assert.True(t, strings.HasSuffix(body, "# EOF\n"))
+
+	for _, line := range []string{
+		`s9s_cluster_info{cluster="test",version="24.05"} 1`,
+		`s9s_jobs{partition="cpu",state="PENDING"} 3`,
+		`s9s_jobs{partition="gpu",state="RUNNING"} 2`,
+		`s9s_user_jobs{user="alice",state="PENDING"} 2`,
+		`s9s_account_jobs{account="chem",state="RUNNING"} 1`,
+		`s9s_queue_wait_seconds_count{partition="cpu"} 3`,
+		`s9s_queue_wait_seconds_count{partition="gpu"} 0`,
+		`s9s_nodes{state="IDLE"} 2`,
+		`s9s_nodes{state="MIXED"} 1`,
+		`s9s_node_flags{flag="DRAIN"} 1`,
+		`s9s_node_flags{flag="NOT_RESPONDING"} 1`,
+		`s9s_cpus_configured 128`,
+		`s9s_cpus_allocated 12`,
+		`s9s_memory_allocated_bytes 5.36870912e+08`,
+		`s9s_partition_cpus_configured{partition="cpu"} 64`,
+		`s9s_gpus_allocated{partition="gpu",type=""} 1`,
+		`s9s_gpus_allocated{partition="gpu",type="a100"} 2`,
+		`s9s_health{check="utilization",s9s_health="unknown"} 1`,
+		`s9s_up 1`,
+		`s9s_poll_errors_total 0`,
+	} {
+		assert.Contains(t, body, line+"\n")
+	}
+
+	assert.Contains(t, body, "# TYPE s9s_health stateset\n")
+	assert.Contains(t, body, "# UNIT s9s_queue_wait_seconds seconds\n")
+}
+
+func TestExporter_PagesThroughJobs(t *testing.T) {
+	client := newTestClient(time.Now())
+	e := New(client, Options{Interval: time.Minute, JobPageSize: 2})
+	require.NoError(t, e.Poll())
+
+	assert.Equal(t, []int{0, 2, 4}, client.jobOffsets)
+	body := scrape(t, e)
+	assert.Contains(t, body, `s9s_jobs{partition="cpu",state="PENDING"} 3`+"\n")
+	assert.Contains(t, body, `s9s_jobs{partition="gpu",state="RUNNING"} 2`+"\n")
+}
+
+func TestExporter_FailedPoll(t *testing.T) {
+	client := newTestClient(time.Now())
+	client.jobsErr = errors.New("slurmrestd unavailable")
+	e := New(client, Options{Interval: time.Minute})
+
+	require.Error(t, e.Poll())
+
+	body := scrape(t, e)
+	assert.Contains(t, body, "s9s_up 0\n")
+	assert.Contains(t, body, "s9s_poll_errors_total 1\n")
+	assert.NotContains(t, body, "s9s_jobs{")
+}
+
+func TestQuantile(t *testing.T) {
+	values := []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	assert.Equal(t, 5.0, quantile(values, 0.5))
+	assert.Equal(t, 9.0, quantile(values, 0.9))
+	assert.Equal(t, 10.0, quantile(values, 0.99))
+	assert.True(t, math.IsNaN(quantile(nil, 0.5)))
+}
+
+func TestParseTRESGPUs(t *testing.T) {
+	assert.Equal(t, map[string]int{"a100": 2}, parseTRESGPUs("cpu=8,gres/gpu=2,gres/gpu:a100=2"))
+	assert.Equal(t, map[string]int{"": 4}, parseTRESGPUs("cpu=8,gres/gpu=4"))
+	assert.Nil(t, parseTRESGPUs("cpu=8,mem=4G"))
+}
+
+func TestWriteOpenMetrics_Escaping(t *testing.T) {
+	f := newFamily("s9s_test", typeGauge, "", "Help with \\ backslash")
+	f.add(1, "name", "a \"quoted\"\nvalue")
+
+	var b strings.Builder
+	require.NoError(t, writeOpenMetrics(&b, []*metricFamily{f}))
+	assert.Equal(t, "# TYPE s9s_test gauge\n# HELP s9s_test Help with \\\\ backslash\n"+
+		"s9s_test{name=\"a \\\"quoted\\\"\\nvalue\"} 1\n# EOF\n", b.String())
+}
@@ -0,0 +1,139 @@
+package exporter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ContentType is the media type of the OpenMetrics text exposition format
+const ContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+// metricType is an OpenMetrics metric family type
+type metricType string
+
+const (
+	typeGauge    metricType = "gauge"
+	typeSummary  metricType = "summary"
+	typeStateSet metricType = "stateset"
+	typeInfo     metricType = "info"
+	typeCounter  metricType = "counter"
+)
+
+// label is a single label name/value pair
+type label struct {
+	name, value string
+}
+
+// sample is one line of a metric family
+type sample struct {
+	suffix string // Appended to the family name, e.g. "_count" or "_info"
+	labels []label
+	value  float64
+}
+
+// metricFamily is a set of samples sharing a name, type and help text
+type metricFamily struct {
+	name    string
+	typ     metricType
+	unit    string
+	help    string
+	samples []sample
+}
+
+// newFamily creates an empty metric family
+func newFamily(name string, typ metricType, unit, help string) *metricFamily {
+	return &metricFamily{name: name, typ: typ, unit: unit, help: help}
+}
+
+// add appends a sample with alternating label names and values
+func (f *metricFamily) add(value float64, labelPairs ...string) {
+	f.addSuffixed("", value, labelPairs...)
+}
+
+// addSuffixed appends a sample whose name carries a suffix such as "_sum"
+func (f *metricFamily) addSuffixed(suffix string, value float64, labelPairs ...string) {
+	labels := make([]label, 0, len(labelPairs)/2)
+	for i := 0; i+1 < len(labelPairs); i += 2 {
+		labels = append(labels, label{name: labelPairs[i], value: labelPairs[i+1]})
+	}
+	f.samples = append(f.samples, sample{suffix: suffix, labels: labels, value: value})
+}
+
+// writeOpenMetrics renders metric families in the OpenMetrics text format,
+// terminated by the mandatory EOF marker
+func writeOpenMetrics(w io.Writer, families []*metricFamily) error {
+	bw := bufio.NewWriter(w)
+	for _, f := range families {
+		fmt.Fprintf(bw, "# TYPE %s %s\n", f.name, f.typ)
+		if f.unit != "" {
+			fmt.Fprintf(bw, "# UNIT %s %s\n", f.name, f.unit)
+		}
+		if f.help != "" {
+			fmt.Fprintf(bw, "# HELP %s %s\n", f.name, escapeHelp(f.help))
+		}
+		for _, s := range f.samples {
+			bw.WriteString(f.name)
+			bw.WriteString(s.suffix)
+			writeLabels(bw, s.labels)
+			bw.WriteByte(' ')
+			bw.WriteString(formatValue(s.value))
+			bw.WriteByte('\n')
+		}
+	}
+	bw.WriteString("# EOF\n")
+	return bw.Flush()
+}
+
+// writeLabels writes a label set in braces, or nothing if it is empty
+func writeLabels(bw *bufio.Writer, labels []label) {
+	if len(labels) == 0 {
+		return
+	}
+	bw.WriteByte('{')
+	for i, l := range labels {
+		if i > 0 {
+			bw.WriteByte(',')
+		}
+		bw.WriteString(l.name)
+		bw.WriteString(`="`)
+		bw.WriteString(escapeLabelValue(l.value))
+		bw.WriteByte('"')
+	}
+	bw.WriteByte('}')
+}
+
+// formatValue renders a sample value
+func formatValue(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabelValue(v string) string { return labelValueEscaper.Replace(v) }
+func escapeHelp(v string) string       { return helpEscaper.Replace(v) }
+
+// sortedKeys returns the keys of a map in ascending order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,162 @@
+package jobtemplate
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jontk/s9s/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// LoadDir reads the templates of a catalog directory: one template per
+// *.yaml or *.yml file, in the format of the templates in the config file.
+// A template without a name is named after its file. Files that cannot be
+// read are left out and reported in the returned error.
+func LoadDir(dir string) ([]*Template, error) {
+	dir = ExpandPath(dir)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading template catalog: %w", err)
+	}
+
+	revision := Revision(dir)
+	var files []string
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if !entry.IsDir() && (ext == ".yaml" || ext == ".yml") {
+			files = append(files, entry.Name())
+		}
+	}
+	sort.Strings(files)
+
+	var templates []*Template
+	var errs []error
+	for _, name := range files {
+		path := filepath.Join(dir, name)
+		t, err := loadFile(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+			continue
+		}
+		t.Source = dir
+		t.Revision = revision
+		templates = append(templates, t)
+	}
+	return templates, errors.Join(errs...)
+}
+
+// loadFile reads the template of a catalog file
+func loadFile(path string) (*Template, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // catalog files are chosen by the user
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Template{File: path}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&t.JobTemplateConfig); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if t.Name == "" {
+		t.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if err := t.Check(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// FromConfig returns the template of a config file template
+func FromConfig(tc config.JobTemplateConfig) *Template {
+	return &Template{JobTemplateConfig: tc, Source: "config"}
+}
+
+// Revision returns the abbreviated commit checked out in the git working
+// tree containing dir, or "" when dir is not in one. It reads .git itself
+// rather than running git, which may not be installed on login nodes.
+func Revision(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	for {
+		gitDir := filepath.Join(dir, ".git")
+		if info, err := os.Stat(gitDir); err == nil {
+			if !info.IsDir() {
+				// Worktrees and submodules point at their git directory
+				gitDir = gitDirFromFile(gitDir)
+			}
+			return headCommit(gitDir)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// gitDirFromFile returns the git directory a ".git" file points at
+func gitDirFromFile(path string) string {
+	data, err := os.ReadFile(path) //nolint:gosec // path is a .git file found above the catalog
+	if err != nil {
+		return ""
+	}
+	gitDir := strings.TrimSpace(strings.TrimPrefix(string(data), "gitdir:"))
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(filepath.Dir(path), gitDir)
+	}
+	return gitDir
+}
+
+// headCommit returns the abbreviated commit HEAD of gitDir resolves to
+func headCommit(gitDir string) string {
+	head, err := os.ReadFile(filepath.Join(gitDir, "HEAD")) //nolint:gosec // git metadata
+	if err != nil {
+		return ""
+	}
+	ref, symbolic := strings.CutPrefix(strings.TrimSpace(string(head)), "ref: ")
+	if !symbolic {
+		return abbreviate(ref)
+	}
+
+	// Worktrees keep their refs in the common git directory
+	dirs := []string{gitDir}
+	if common, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil { //nolint:gosec // git metadata
+		dir := strings.TrimSpace(string(common))
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(gitDir, dir)
+		}
+		dirs = append(dirs, dir)
+	}
+	for _, dir := range dirs {
+		if commit, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(ref))); err == nil { //nolint:gosec // git metadata
+			return abbreviate(strings.TrimSpace(string(commit)))
+		}
+		packed, err := os.ReadFile(filepath.Join(dir, "packed-refs")) //nolint:gosec // git metadata
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(packed), "\n") {
+			if commit, name, ok := strings.Cut(strings.TrimSpace(line), " "); ok && name == ref {
+				return abbreviate(commit)
+			}
+		}
+	}
+	return ""
+}
+
+// abbreviate shortens a commit hash the way git log --oneline does
+func abbreviate(commit string) string {
+	if len(commit) > 7 {
+		return commit[:7]
+	}
+	return commit
+}
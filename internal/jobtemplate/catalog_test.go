@@ -0,0 +1,86 @@
+package jobtemplate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
+
+func TestLoadDir(t *testing.T) {
+	repo := t.TempDir()
+	writeFile(t, filepath.Join(repo, ".git", "HEAD"), "ref: refs/heads/main\n")
+	writeFile(t, filepath.Join(repo, ".git", "packed-refs"),
+		"# pack-refs with: peeled fully-peeled sorted\n0123456789abcdef0123456789abcdef01234567 refs/heads/main\n")
+
+	dir := filepath.Join(repo, "templates")
+	writeFile(t, filepath.Join(dir, "train.yaml"), `
+name: GPU Training
+description: Train a model
+version: "3"
+parameters:
+  - name: gpus
+    type: int
+    default: 1
+defaults:
+  gpus: "{{ .gpus }}"
+`)
+	writeFile(t, filepath.Join(dir, "cpu.yml"), "defaults:\n  cpus: 4\n")
+	writeFile(t, filepath.Join(dir, "broken.yaml"), "name: broken\nparamters: []\n")
+	writeFile(t, filepath.Join(dir, "bad-param.yaml"), "parameters:\n  - name: x\n    type: float\n")
+	writeFile(t, filepath.Join(dir, "README.md"), "# Templates\n")
+
+	templates, err := LoadDir(dir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "bad-param.yaml: parameter x: unknown type \"float\"")
+	assert.Contains(t, err.Error(), "broken.yaml: yaml: unmarshal errors")
+
+	require.Len(t, templates, 2)
+	assert.Equal(t, "cpu", templates[0].Name)
+	assert.Equal(t, "GPU Training", templates[1].Name)
+	assert.Equal(t, "3", templates[1].Version)
+	for _, tmpl := range templates {
+		assert.Equal(t, dir, tmpl.Source)
+		assert.Equal(t, "0123456", tmpl.Revision)
+	}
+	assert.Equal(t, filepath.Join(dir, "train.yaml"), templates[1].File)
+
+	rendered, err := templates[1].Render(map[string]string{"gpus": "2"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, rendered["gpus"])
+}
+
+func TestLoadDirMissing(t *testing.T) {
+	_, err := LoadDir(filepath.Join(t.TempDir(), "nope"))
+	assert.ErrorContains(t, err, "reading template catalog")
+}
+
+func TestRevision(t *testing.T) {
+	repo := t.TempDir()
+	assert.Empty(t, Revision(repo))
+
+	// Loose ref
+	writeFile(t, filepath.Join(repo, ".git", "HEAD"), "ref: refs/heads/main\n")
+	writeFile(t, filepath.Join(repo, ".git", "refs", "heads", "main"), "fedcba9876543210fedcba9876543210fedcba98\n")
+	assert.Equal(t, "fedcba9", Revision(repo))
+
+	// Detached HEAD
+	writeFile(t, filepath.Join(repo, ".git", "HEAD"), "abcdef0123456789abcdef0123456789abcdef01\n")
+	assert.Equal(t, "abcdef0", Revision(filepath.Join(repo, "sub", "dir")))
+
+	// Worktree pointing at the main repository
+	worktree := t.TempDir()
+	gitDir := filepath.Join(repo, ".git", "worktrees", "wt")
+	writeFile(t, filepath.Join(worktree, ".git"), "gitdir: "+gitDir+"\n")
+	writeFile(t, filepath.Join(gitDir, "HEAD"), "ref: refs/heads/main\n")
+	writeFile(t, filepath.Join(gitDir, "commondir"), "../..\n")
+	assert.Equal(t, "fedcba9", Revision(worktree))
+}
@@ -0,0 +1,377 @@
+// Package jobtemplate implements parameterized job templates: templates
+// declare typed parameters whose values are validated and substituted into
+// the template defaults, the script included, with Go's text/template.
+// Templates are read from the configuration or from catalog directories,
+// typically a git checkout shared by a group.
+package jobtemplate
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/jontk/s9s/internal/config"
+	"github.com/jontk/s9s/internal/dao"
+)
+
+// Parameter types
+const (
+	TypeString   = "string"
+	TypeInt      = "int"
+	TypeBool     = "bool"
+	TypeEnum     = "enum"
+	TypePath     = "path"
+	TypeDuration = "duration"
+)
+
+// parameterName matches the names usable as {{ .name }} in Go templates
+var parameterName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Template is a job template of the library
+type Template struct {
+	config.JobTemplateConfig
+
+	// Source is builtin, config, saved or the catalog directory the
+	// template was read from
+	Source string
+	// File is the catalog file of the template
+	File string
+	// Revision is the git commit of the catalog checkout
+	Revision string
+	// Job is the submission of a built-in or saved template, which have
+	// no parameters; other templates are rendered from their defaults
+	Job *dao.JobSubmission
+}
+
+// Parameterized reports whether the template declares parameters. Only
+// the defaults of parameterized templates are rendered, so existing
+// templates keep scripts containing "{{" as they are.
+func (t *Template) Parameterized() bool {
+	return len(t.Parameters) > 0
+}
+
+// Parameter returns the parameter of the template with the given name
+func (t *Template) Parameter(name string) (config.TemplateParameterConfig, bool) {
+	for _, p := range t.Parameters {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return config.TemplateParameterConfig{}, false
+}
+
+// Check validates the parameter declarations of the template
+func (t *Template) Check() error {
+	var errs []error
+	seen := make(map[string]bool, len(t.Parameters))
+	for _, p := range t.Parameters {
+		if !parameterName.MatchString(p.Name) {
+			errs = append(errs, fmt.Errorf("parameter %q: name must be a letter or underscore followed by letters, digits or underscores", p.Name))
+			continue
+		}
+		if seen[p.Name] {
+			errs = append(errs, fmt.Errorf("parameter %s: declared twice", p.Name))
+		}
+		seen[p.Name] = true
+
+		switch p.Type {
+		case "", TypeString, TypeInt, TypeBool, TypePath, TypeDuration:
+		case TypeEnum:
+			if len(p.Options) == 0 {
+				errs = append(errs, fmt.Errorf("parameter %s: an enum needs options", p.Name))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("parameter %s: unknown type %q", p.Name, p.Type))
+			continue
+		}
+		if p.Default != nil {
+			if _, err := parseValue(p, fmt.Sprint(p.Default)); err != nil {
+				errs = append(errs, fmt.Errorf("parameter %s: default: %w", p.Name, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Values returns the typed values of the template parameters: the values
+// given in set, keyed by parameter name, or else the parameter defaults.
+// Unknown names, missing required values and invalid values are errors.
+func (t *Template) Values(set map[string]string) (map[string]any, error) {
+	var errs []error
+	for name := range set {
+		if _, ok := t.Parameter(name); !ok {
+			errs = append(errs, fmt.Errorf("template %s has no parameter %q", t.Name, name))
+		}
+	}
+
+	values := make(map[string]any, len(t.Parameters))
+	for _, p := range t.Parameters {
+		raw, ok := set[p.Name]
+		if !ok && p.Default != nil {
+			raw, ok = fmt.Sprint(p.Default), true
+		}
+		if !ok && p.Required {
+			errs = append(errs, fmt.Errorf("parameter %s is required", p.Name))
+			continue
+		}
+		if !ok && p.Type == TypeEnum {
+			values[p.Name] = ""
+			continue
+		}
+		value, err := parseValue(p, raw)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("parameter %s: %w", p.Name, err))
+			continue
+		}
+		values[p.Name] = value
+	}
+	if len(errs) > 0 {
+		slices.SortFunc(errs, func(a, b error) int { return strings.Compare(a.Error(), b.Error()) })
+		return nil, errors.Join(errs...)
+	}
+	return values, nil
+}
+
+// Render returns the template defaults with the parameter values of set
+// substituted, as a map for config.JobSubmissionFromMap. String defaults
+// are Go templates over the parameter values; rendered values of numeric
+// and boolean form fields are converted back to numbers and booleans.
+func (t *Template) Render(set map[string]string) (map[string]any, error) {
+	values, err := t.Values(set)
+	if err != nil {
+		return nil, err
+	}
+	if !t.Parameterized() {
+		return t.Defaults, nil
+	}
+
+	kinds := fieldKinds()
+	rendered := make(map[string]any, len(t.Defaults))
+	var errs []error
+	for key, value := range t.Defaults {
+		v, err := renderValue(key, value, values)
+		if err == nil {
+			v, err = convert(v, kinds[strings.ToLower(key)])
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("defaults.%s: %w", key, err))
+			continue
+		}
+		rendered[key] = v
+	}
+	if len(errs) > 0 {
+		slices.SortFunc(errs, func(a, b error) int { return strings.Compare(a.Error(), b.Error()) })
+		return nil, errors.Join(errs...)
+	}
+	return rendered, nil
+}
+
+// parseValue parses the value of parameter p given as text. The empty
+// text is the zero value of the parameter type.
+func parseValue(p config.TemplateParameterConfig, raw string) (any, error) {
+	switch p.Type {
+	case TypeInt:
+		if raw == "" {
+			raw = "0"
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a whole number", raw)
+		}
+		if p.Min != nil && n < *p.Min {
+			return nil, fmt.Errorf("%d is less than %d", n, *p.Min)
+		}
+		if p.Max != nil && n > *p.Max {
+			return nil, fmt.Errorf("%d is more than %d", n, *p.Max)
+		}
+		return n, nil
+	case TypeBool:
+		if raw == "" {
+			return false, nil
+		}
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not true or false", raw)
+		}
+		return b, nil
+	case TypeEnum:
+		if !slices.Contains(p.Options, raw) {
+			return nil, fmt.Errorf("%q is not one of %s", raw, strings.Join(p.Options, ", "))
+		}
+		return raw, nil
+	case TypePath:
+		if raw == "" {
+			return "", nil
+		}
+		path := ExpandPath(raw)
+		if p.MustExist {
+			if _, err := os.Stat(path); err != nil {
+				return nil, fmt.Errorf("%s does not exist", path)
+			}
+		}
+		return path, nil
+	case TypeDuration:
+		if raw == "" {
+			return Duration{}, nil
+		}
+		return ParseDuration(raw)
+	default:
+		return raw, nil
+	}
+}
+
+// ExpandPath expands a leading ~ to the home directory and environment
+// variables in path
+func ExpandPath(path string) string {
+	path = os.ExpandEnv(path)
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[1:])
+		}
+	}
+	return path
+}
+
+// renderValue renders the Go templates in the strings of value
+func renderValue(key string, value any, values map[string]any) (any, error) {
+	switch v := value.(type) {
+	case string:
+		return Execute(key, v, values)
+	case []any:
+		items := make([]any, 0, len(v))
+		for _, item := range v {
+			rendered, err := renderValue(key, item, values)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, rendered)
+		}
+		return items, nil
+	default:
+		return value, nil
+	}
+}
+
+// Execute renders text as a Go template over data, with the template
+// functions of job templates. Referencing a missing value is an error.
+func Execute(name, text string, data map[string]any) (string, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Funcs(funcs).Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// funcs are the functions available in templates besides the built-in ones
+var funcs = template.FuncMap{
+	// quote quotes a value for the shell
+	"quote": func(v any) string { return ShellQuote(fmt.Sprint(v)) },
+}
+
+// ShellQuote quotes s as a single word for the shell
+func ShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// convert converts a rendered string to the kind of its form field
+func convert(value any, kind reflect.Kind) (any, error) {
+	s, ok := value.(string)
+	if !ok {
+		return value, nil
+	}
+	switch kind {
+	case reflect.Int:
+		n, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a whole number", s)
+		}
+		return n, nil
+	case reflect.Bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(s))
+		if err != nil {
+			return nil, fmt.Errorf("%q is not true or false", s)
+		}
+		return b, nil
+	default:
+		return s, nil
+	}
+}
+
+// fieldKinds returns the kinds of the submission form fields, keyed by
+// the lowercased keys of the template defaults
+func fieldKinds() map[string]reflect.Kind {
+	t := reflect.TypeOf(config.JobSubmissionValues{})
+	kinds := make(map[string]reflect.Kind, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+		kinds[strings.ToLower(name)] = t.Field(i).Type.Kind()
+	}
+	return kinds
+}
+
+// Duration is the value of a duration parameter. It prints in the SLURM
+// time format, [days-]hours:minutes:seconds, so that {{ .walltime }} can
+// be used as a time limit; {{ .walltime.Minutes }} and the other methods
+// of time.Duration are available too.
+type Duration struct {
+	time.Duration
+}
+
+// String returns the duration in the SLURM time format
+func (d Duration) String() string {
+	total := int64(d.Duration.Round(time.Second) / time.Second)
+	days, rest := total/86400, total%86400
+	clock := fmt.Sprintf("%02d:%02d:%02d", rest/3600, rest%3600/60, rest%60)
+	if days > 0 {
+		return fmt.Sprintf("%d-%s", days, clock)
+	}
+	return clock
+}
+
+// slurmTime matches the SLURM time formats with a colon or a day count:
+// D-HH, D-HH:MM, D-HH:MM:SS, HH:MM:SS and MM:SS
+var slurmTime = regexp.MustCompile(`^(?:(\d+)-)?(\d+)(?::(\d+))?(?::(\d+))?$`)
+
+// ParseDuration parses a duration given as a Go duration ("90m", "2h30m")
+// or in the SLURM time format ("1-12:00:00", "02:00:00"); a bare number is
+// minutes, as for sbatch --time
+func ParseDuration(s string) (Duration, error) {
+	s = strings.TrimSpace(s)
+	if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return Duration{d}, nil
+	}
+	m := slurmTime.FindStringSubmatch(s)
+	if m == nil {
+		return Duration{}, fmt.Errorf("%q is not a duration such as 90m, 2h30m or 1-12:00:00", s)
+	}
+	n := make([]time.Duration, len(m))
+	for i, part := range m[1:] {
+		if part != "" {
+			v, _ := strconv.Atoi(part)
+			n[i+1] = time.Duration(v)
+		}
+	}
+	days, a, b, c := n[1], n[2], n[3], n[4]
+	switch {
+	case m[1] != "": // D-HH[:MM[:SS]]
+		return Duration{days*24*time.Hour + a*time.Hour + b*time.Minute + c*time.Second}, nil
+	case m[4] != "": // HH:MM:SS
+		return Duration{a*time.Hour + b*time.Minute + c*time.Second}, nil
+	case m[3] != "": // MM:SS
+		return Duration{a*time.Minute + b*time.Second}, nil
+	default: // minutes
+		return Duration{a * time.Minute}, nil
+	}
+}
@@ -0,0 +1,162 @@
+package jobtemplate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jontk/s9s/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func intPtr(n int) *int { return &n }
+
+func trainTemplate() *Template {
+	return FromConfig(config.JobTemplateConfig{
+		Name:    "train",
+		Version: "3",
+		Parameters: []config.TemplateParameterConfig{
+			{Name: "model", Type: TypeEnum, Options: []string{"resnet", "bert"}, Required: true},
+			{Name: "gpus", Type: TypeInt, Default: 1, Min: intPtr(1), Max: intPtr(8)},
+			{Name: "walltime", Type: TypeDuration, Default: "2h"},
+			{Name: "data", Type: TypePath, Default: "$DATA_ROOT/imagenet"},
+			{Name: "exclusive", Type: TypeBool},
+			{Name: "tag"},
+		},
+		Defaults: map[string]any{
+			"name":      "train-{{ .model }}{{ with .tag }}-{{ . }}{{ end }}",
+			"partition": "gpu",
+			"gpus":      "{{ .gpus }}",
+			"timeLimit": "{{ .walltime }}",
+			"exclusive": "{{ .exclusive }}",
+			"script":    "#!/bin/bash\npython train.py --model {{ .model }} --data {{ quote .data }}\n",
+			"nodes":     1,
+		},
+	})
+}
+
+func TestRender(t *testing.T) {
+	t.Setenv("DATA_ROOT", "/scratch")
+	tmpl := trainTemplate()
+
+	rendered, err := tmpl.Render(map[string]string{"model": "bert", "gpus": "4", "walltime": "1-12:00:00"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"name":      "train-bert",
+		"partition": "gpu",
+		"gpus":      4,
+		"timeLimit": "1-12:00:00",
+		"exclusive": false,
+		"script":    "#!/bin/bash\npython train.py --model bert --data '/scratch/imagenet'\n",
+		"nodes":     1,
+	}, rendered)
+
+	vals := config.JobSubmissionFromMap(rendered)
+	assert.Equal(t, 4, vals.GPUs)
+	assert.Equal(t, "train-bert", vals.Name)
+
+	rendered, err = tmpl.Render(map[string]string{"model": "resnet", "tag": "ablation", "exclusive": "true"})
+	require.NoError(t, err)
+	assert.Equal(t, "train-resnet-ablation", rendered["name"])
+	assert.Equal(t, "02:00:00", rendered["timeLimit"])
+	assert.Equal(t, true, rendered["exclusive"])
+}
+
+func TestRenderValidatesParameters(t *testing.T) {
+	tmpl := trainTemplate()
+
+	_, err := tmpl.Render(map[string]string{"gpus": "16", "walltime": "soon", "modle": "bert"})
+	require.Error(t, err)
+	assert.Equal(t, `parameter gpus: 16 is more than 8
+parameter model is required
+parameter walltime: "soon" is not a duration such as 90m, 2h30m or 1-12:00:00
+template train has no parameter "modle"`, err.Error())
+
+	_, err = tmpl.Render(map[string]string{"model": "gpt"})
+	assert.EqualError(t, err, `parameter model: "gpt" is not one of resnet, bert`)
+}
+
+func TestRenderErrors(t *testing.T) {
+	tmpl := trainTemplate()
+	tmpl.Defaults = map[string]any{"cpus": "{{ .model }}", "comment": "{{ .nope }}"}
+
+	_, err := tmpl.Render(map[string]string{"model": "bert"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `defaults.comment: template: comment:1:3: executing "comment" at <.nope>: map has no entry for key "nope"`)
+	assert.Contains(t, err.Error(), `defaults.cpus: "bert" is not a whole number`)
+}
+
+func TestRenderWithoutParameters(t *testing.T) {
+	// Templates without parameters are not rendered
+	tmpl := FromConfig(config.JobTemplateConfig{
+		Name:     "plain",
+		Defaults: map[string]any{"script": "echo {{ not a template"},
+	})
+	rendered, err := tmpl.Render(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "echo {{ not a template", rendered["script"])
+
+	_, err = tmpl.Render(map[string]string{"x": "1"})
+	assert.EqualError(t, err, `template plain has no parameter "x"`)
+}
+
+func TestCheck(t *testing.T) {
+	tmpl := FromConfig(config.JobTemplateConfig{
+		Name: "bad",
+		Parameters: []config.TemplateParameterConfig{
+			{Name: "n", Type: TypeInt, Default: "many"},
+			{Name: "n"},
+			{Name: "size", Type: TypeEnum},
+			{Name: "x", Type: "float"},
+			{Name: "two words"},
+		},
+	})
+	err := tmpl.Check()
+	require.Error(t, err)
+	assert.Equal(t, `parameter n: default: "many" is not a whole number
+parameter n: declared twice
+parameter size: an enum needs options
+parameter x: unknown type "float"
+parameter "two words": name must be a letter or underscore followed by letters, digits or underscores`, err.Error())
+
+	assert.NoError(t, trainTemplate().Check())
+}
+
+func TestParseDuration(t *testing.T) {
+	for in, want := range map[string]time.Duration{
+		"90m":        90 * time.Minute,
+		"2h30m":      150 * time.Minute,
+		"30":         30 * time.Minute,
+		"45:30":      45*time.Minute + 30*time.Second,
+		"02:00:00":   2 * time.Hour,
+		"1-12":       36 * time.Hour,
+		"1-12:30":    36*time.Hour + 30*time.Minute,
+		"2-00:00:10": 48*time.Hour + 10*time.Second,
+	} {
+		d, err := ParseDuration(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, d.Duration, in)
+	}
+
+	_, err := ParseDuration("-1h")
+	assert.Error(t, err)
+
+	assert.Equal(t, "00:45:30", Duration{45*time.Minute + 30*time.Second}.String())
+	assert.Equal(t, "2-00:00:10", Duration{48*time.Hour + 10*time.Second}.String())
+}
+
+func TestPathParameter(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	p := config.TemplateParameterConfig{Name: "data", Type: TypePath, MustExist: true}
+
+	_, err := parseValue(p, "~/data")
+	assert.EqualError(t, err, filepath.Join(home, "data")+" does not exist")
+
+	require.NoError(t, os.Mkdir(filepath.Join(home, "data"), 0o750))
+	value, err := parseValue(p, "~/data")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(home, "data"), value)
+}
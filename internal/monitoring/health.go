@@ -2,6 +2,7 @@ package monitoring
 
 import (
 	"fmt"
+	"slices"
 	"sync"
 	"time"
 
@@ -69,6 +70,9 @@ type HealthMonitor struct {
 	health       *ClusterHealth
 	alertManager *AlertManager
 	checks       map[string]HealthCheckFunc
+	rules        *RuleEngine
+	ruleChecks   map[string]bool // Names of the checks produced by rules
+	ruleResults  []RuleResult    // Results of the last rule evaluation
 	interval     time.Duration
 	running      bool
 	mu           sync.RWMutex
@@ -84,6 +88,7 @@ func NewHealthMonitor(client dao.SlurmClient, interval time.Duration) *HealthMon
 		interval:     interval,
 		alertManager: NewAlertManager(),
 		checks:       make(map[string]HealthCheckFunc),
+		ruleChecks:   make(map[string]bool),
 		health: &ClusterHealth{
 			OverallStatus: HealthStatusUnknown,
 			Checks:        make(map[string]*HealthCheck),
@@ -138,6 +143,45 @@ func (hm *HealthMonitor) GetHealth() *ClusterHealth {
 	return healthCopy
 }
 
+// RunChecks runs all health checks once and returns the resulting health.
+// It is used by callers that poll on their own schedule instead of Start.
+func (hm *HealthMonitor) RunChecks() *ClusterHealth {
+	hm.performHealthChecks()
+	return hm.GetHealth()
+}
+
+// SetRuleEngine evaluates the engine's rules alongside the built-in checks.
+// Rules named after a built-in check retune its thresholds or disable it.
+func (hm *HealthMonitor) SetRuleEngine(engine *RuleEngine) {
+	hm.health.mu.Lock()
+	defer hm.health.mu.Unlock()
+
+	hm.rules = engine
+	for name := range builtinChecks {
+		if override := engine.builtinOverride(name); override != nil && override.Disabled {
+			delete(hm.checks, name)
+			delete(hm.health.Checks, name)
+		}
+	}
+}
+
+// SetClient sets the client the checks query from the next check on
+func (hm *HealthMonitor) SetClient(client dao.SlurmClient) {
+	hm.health.mu.Lock()
+	defer hm.health.mu.Unlock()
+	hm.client = client
+}
+
+// RuleResults returns the results of the last evaluation of the health
+// rules. Views that show rule alerts read them here rather than evaluating
+// the rules themselves, as the engine tracks how long each rule has been
+// breached and must see one snapshot of the whole cluster at a time.
+func (hm *HealthMonitor) RuleResults() []RuleResult {
+	hm.health.mu.RLock()
+	defer hm.health.mu.RUnlock()
+	return slices.Clone(hm.ruleResults)
+}
+
 // GetAlertManager returns the alert manager
 func (hm *HealthMonitor) GetAlertManager() *AlertManager {
 	return hm.alertManager
@@ -190,11 +234,56 @@ func (hm *HealthMonitor) performHealthChecks() {
 		}
 	}
 
+	if hm.rules != nil && len(hm.rules.Rules()) > 0 {
+		hm.ruleResults = hm.rules.Evaluate(CollectSnapshot(hm.client, 0))
+		hm.applyRuleResults(hm.ruleResults)
+	}
+
 	// Update overall status
 	hm.updateOverallStatus()
 	hm.health.LastUpdated = time.Now()
 }
 
+// applyRuleResults stores rule results as health checks, dropping checks of
+// scopes that no longer exist
+func (hm *HealthMonitor) applyRuleResults(results []RuleResult) {
+	current := make(map[string]bool, len(results))
+	for i := range results {
+		result := &results[i]
+		name := result.CheckName()
+		current[name] = true
+
+		description := result.Rule.Description
+		if description == "" {
+			description = result.Rule.Expr
+		}
+		check := &HealthCheck{
+			Name:        name,
+			Description: description,
+			Status:      result.Status,
+			Message:     result.Message,
+			LastCheck:   time.Now(),
+			Threshold:   result.Rule.threshold(),
+		}
+		if prior, exists := hm.health.Checks[name]; exists {
+			check.CheckCount = prior.CheckCount
+		}
+		check.CheckCount++
+		hm.health.Checks[name] = check
+
+		if result.Firing() && (check.Status == HealthStatusCritical || check.Status == HealthStatusWarning) {
+			hm.generateAlert(check)
+		}
+	}
+
+	for name := range hm.ruleChecks {
+		if !current[name] {
+			delete(hm.health.Checks, name)
+		}
+	}
+	hm.ruleChecks = current
+}
+
 // updateOverallStatus calculates the overall cluster health status
 func (hm *HealthMonitor) updateOverallStatus() {
 	hasCritical := false
@@ -253,10 +342,7 @@ func (hm *HealthMonitor) checkNodes(client dao.SlurmClient) *HealthCheck {
 		Name:        "nodes",
 		Description: "Monitor node availability and health",
 		LastCheck:   time.Now(),
-		Threshold: HealthThreshold{
-			WarningMax:  floatPtr(10.0),
-			CriticalMax: floatPtr(25.0),
-		},
+		Threshold:   hm.builtinThreshold("nodes", 10.0, 25.0),
 	}
 
 	nodeList, err := client.Nodes().List(&dao.ListNodesOptions{})
@@ -292,10 +378,7 @@ func (hm *HealthMonitor) checkQueue(client dao.SlurmClient) *HealthCheck {
 		Name:        "queue",
 		Description: "Monitor job queue depth and wait times",
 		LastCheck:   time.Now(),
-		Threshold: HealthThreshold{
-			WarningMax:  floatPtr(100.0),
-			CriticalMax: floatPtr(500.0),
-		},
+		Threshold:   hm.builtinThreshold("queue", 100.0, 500.0),
 	}
 
 	jobList, err := client.Jobs().List(&dao.ListJobsOptions{
@@ -325,10 +408,7 @@ func (hm *HealthMonitor) checkUtilization(client dao.SlurmClient) *HealthCheck {
 		Name:        "utilization",
 		Description: "Monitor cluster resource utilization",
 		LastCheck:   time.Now(),
-		Threshold: HealthThreshold{
-			WarningMax:  floatPtr(90.0),
-			CriticalMax: floatPtr(95.0),
-		},
+		Threshold:   hm.builtinThreshold("utilization", 90.0, 95.0),
 	}
 
 	infoMgr := client.Info()
@@ -382,6 +462,21 @@ func (hm *HealthMonitor) checkUtilization(client dao.SlurmClient) *HealthCheck {
 	return check
 }
 
+// builtinThreshold returns the thresholds of a built-in check, taking
+// overrides from a rule of the same name
+func (hm *HealthMonitor) builtinThreshold(name string, warning, critical float64) HealthThreshold {
+	threshold := HealthThreshold{WarningMax: floatPtr(warning), CriticalMax: floatPtr(critical)}
+	if override := hm.rules.builtinOverride(name); override != nil {
+		if override.Warning != nil {
+			threshold.WarningMax = floatPtr(*override.Warning)
+		}
+		if override.Critical != nil {
+			threshold.CriticalMax = floatPtr(*override.Critical)
+		}
+	}
+	return threshold
+}
+
 // setCheckStatus sets the check status based on threshold comparison
 func (hm *HealthMonitor) setCheckStatus(check *HealthCheck, value float64, criticalMsg func(HealthStatus) string, healthyMsg func() string) {
 	switch {
@@ -405,10 +500,10 @@ func countNodeStates(nodes []*dao.Node) (down, drain int) {
 		if node == nil {
 			continue
 		}
-		switch node.State {
+		switch node.ParsedState().Summary() {
 		case dao.NodeStateDown:
 			down++
-		case dao.NodeStateDrain, dao.NodeStateDraining:
+		case dao.NodeStateDrain, dao.NodeStateDraining, dao.NodeFlagFail:
 			drain++
 		}
 	}
@@ -201,6 +201,14 @@ func (m *mockPartitionManager) Get(name string) (*dao.Partition, error) {
 	return nil, errors.New("not implemented")
 }
 
+func (m *mockPartitionManager) SetState(_, _ string) error {
+	return errors.New("not implemented")
+}
+
+func (m *mockPartitionManager) SetMaxTime(_, _ string) error {
+	return errors.New("not implemented")
+}
+
 // mockReservationManager implements dao.ReservationManager for testing
 type mockReservationManager struct {
 	listFunc func() (*dao.ReservationList, error)
@@ -607,6 +615,23 @@ func TestCheckNodes(t *testing.T) {
 			expectedStatus:  HealthStatusCritical,
 			expectedMessage: "30.0% of nodes unavailable (1 down, 2 drain out of 10 total)",
 		},
+		{
+			name: "compound states - flags decide availability",
+			nodes: []*dao.Node{
+				{Name: "node1", State: dao.NodeStateIdle},
+				{Name: "node2", State: dao.NodeStateIdle},
+				{Name: "node3", State: dao.NodeStateIdle},
+				{Name: "node4", State: dao.NodeStateIdle},
+				{Name: "node5", State: "MIXED+COMPLETING"},
+				{Name: "node6", State: "IDLE+CLOUD+POWERED_DOWN"},
+				{Name: "node7", State: "IDLE+DRAIN"},
+				{Name: "node8", State: "MIXED+DRAIN"},
+				{Name: "node9", State: "DOWN+NOT_RESPONDING"},
+				{Name: "node10", State: dao.NodeStateIdle},
+			},
+			expectedStatus:  HealthStatusCritical,
+			expectedMessage: "30.0% of nodes unavailable (1 down, 2 drain out of 10 total)",
+		},
 		{
 			name:            "no nodes - critical",
 			nodes:           []*dao.Node{},
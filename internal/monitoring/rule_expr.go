@@ -0,0 +1,225 @@
+package monitoring
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// metricExpr is a compiled rule expression
+type metricExpr interface {
+	eval(vars map[string]float64) float64
+}
+
+type numberExpr float64
+
+func (e numberExpr) eval(map[string]float64) float64 { return float64(e) }
+
+type metricRef string
+
+func (e metricRef) eval(vars map[string]float64) float64 { return vars[string(e)] }
+
+type negExpr struct{ x metricExpr }
+
+func (e negExpr) eval(vars map[string]float64) float64 { return -e.x.eval(vars) }
+
+type binaryExpr struct {
+	op   byte
+	l, r metricExpr
+}
+
+func (e binaryExpr) eval(vars map[string]float64) float64 {
+	l, r := e.l.eval(vars), e.r.eval(vars)
+	switch e.op {
+	case '+':
+		return l + r
+	case '-':
+		return l - r
+	case '*':
+		return l * r
+	default:
+		// Ratios over empty scopes, e.g. a partition without nodes, are 0
+		if r == 0 {
+			return 0
+		}
+		return l / r
+	}
+}
+
+type callExpr struct {
+	fn   string
+	args []metricExpr
+}
+
+func (e callExpr) eval(vars map[string]float64) float64 {
+	v := e.args[0].eval(vars)
+	for _, arg := range e.args[1:] {
+		switch e.fn {
+		case "max":
+			v = math.Max(v, arg.eval(vars))
+		case "min":
+			v = math.Min(v, arg.eval(vars))
+		}
+	}
+	if e.fn == "abs" {
+		v = math.Abs(v)
+	}
+	return v
+}
+
+// exprFuncs are the functions available in rule expressions
+var exprFuncs = map[string]bool{"max": true, "min": true, "abs": true}
+
+// exprParser is a recursive descent parser for rule expressions:
+//
+//	expr   = term { ("+" | "-") term }
+//	term   = factor { ("*" | "/") factor }
+//	factor = number | metric | func "(" expr { "," expr } ")" | "(" expr ")" | "-" factor
+type exprParser struct {
+	src     string
+	pos     int
+	metrics []string // metrics referenced by the expression
+}
+
+// parseMetricExpr compiles an expression over the rule metrics
+func parseMetricExpr(src string) (metricExpr, []string, error) {
+	p := &exprParser{src: src}
+	e, err := p.parseExpr()
+	if err != nil {
+		return nil, nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.src) {
+		return nil, nil, fmt.Errorf("unexpected %q at position %d", p.src[p.pos:], p.pos+1)
+	}
+	return e, p.metrics, nil
+}
+
+func (p *exprParser) skipSpace() {
+	for p.pos < len(p.src) && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// peek returns the next non-space byte, or 0 at the end
+func (p *exprParser) peek() byte {
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *exprParser) parseExpr() (metricExpr, error) {
+	l, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for op := p.peek(); op == '+' || op == '-'; op = p.peek() {
+		p.pos++
+		r, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		l = binaryExpr{op: op, l: l, r: r}
+	}
+	return l, nil
+}
+
+func (p *exprParser) parseTerm() (metricExpr, error) {
+	l, err := p.parseFactor()
+	if err != nil {
+		return nil, err
+	}
+	for op := p.peek(); op == '*' || op == '/'; op = p.peek() {
+		p.pos++
+		r, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		l = binaryExpr{op: op, l: l, r: r}
+	}
+	return l, nil
+}
+
+func (p *exprParser) parseFactor() (metricExpr, error) {
+	switch c := p.peek(); {
+	case c == 0:
+		return nil, fmt.Errorf("unexpected end of expression")
+	case c == '-':
+		p.pos++
+		x, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		return negExpr{x: x}, nil
+	case c == '(':
+		p.pos++
+		e, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ')' {
+			return nil, fmt.Errorf("missing ) at position %d", p.pos+1)
+		}
+		p.pos++
+		return e, nil
+	case c == '.' || (c >= '0' && c <= '9'):
+		start := p.pos
+		for p.pos < len(p.src) && (p.src[p.pos] == '.' || (p.src[p.pos] >= '0' && p.src[p.pos] <= '9')) {
+			p.pos++
+		}
+		v, err := strconv.ParseFloat(p.src[start:p.pos], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", p.src[start:p.pos])
+		}
+		return numberExpr(v), nil
+	case c == '_' || unicode.IsLetter(rune(c)):
+		return p.parseIdent()
+	default:
+		return nil, fmt.Errorf("unexpected %q at position %d", string(c), p.pos+1)
+	}
+}
+
+func (p *exprParser) parseIdent() (metricExpr, error) {
+	start := p.pos
+	for p.pos < len(p.src) && (p.src[p.pos] == '_' || unicode.IsLetter(rune(p.src[p.pos])) || unicode.IsDigit(rune(p.src[p.pos]))) {
+		p.pos++
+	}
+	name := strings.ToLower(p.src[start:p.pos])
+
+	if p.peek() != '(' {
+		if _, ok := ruleMetrics[name]; !ok {
+			return nil, fmt.Errorf("unknown metric %q", name)
+		}
+		p.metrics = append(p.metrics, name)
+		return metricRef(name), nil
+	}
+
+	if !exprFuncs[name] {
+		return nil, fmt.Errorf("unknown function %q", name)
+	}
+	p.pos++ // (
+	var args []metricExpr
+	for {
+		arg, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		if p.peek() != ',' {
+			break
+		}
+		p.pos++
+	}
+	if p.peek() != ')' {
+		return nil, fmt.Errorf("missing ) after %s arguments", name)
+	}
+	p.pos++
+	if name == "abs" && len(args) != 1 {
+		return nil, fmt.Errorf("wrong number of arguments to %s", name)
+	}
+	return callExpr{fn: name, args: args}, nil
+}
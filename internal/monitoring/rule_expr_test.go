@@ -0,0 +1,68 @@
+package monitoring
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMetricExpr(t *testing.T) {
+	vars := map[string]float64{
+		"jobs_pending": 30,
+		"nodes_idle":   4,
+		"nodes_total":  10,
+		"nodes_down":   0,
+	}
+
+	tests := []struct {
+		expr    string
+		want    float64
+		metrics []string
+	}{
+		{"jobs_pending", 30, []string{"jobs_pending"}},
+		{"1 + 2 * 3", 7, nil},
+		{"(1 + 2) * 3", 9, nil},
+		{"-2 + 5", 3, nil},
+		{"jobs_pending / nodes_idle", 7.5, []string{"jobs_pending", "nodes_idle"}},
+		{"jobs_pending / nodes_down", 0, []string{"jobs_pending", "nodes_down"}},
+		{"jobs_pending / max(nodes_idle, 1)", 7.5, []string{"jobs_pending", "nodes_idle"}},
+		{"min(nodes_idle, nodes_total, 2)", 2, []string{"nodes_idle", "nodes_total"}},
+		{"abs(nodes_idle - nodes_total)", 6, []string{"nodes_idle", "nodes_total"}},
+		{"NODES_IDLE * 0.5", 2, []string{"nodes_idle"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			e, metrics, err := parseMetricExpr(tt.expr)
+			require.NoError(t, err)
+			assert.InDelta(t, tt.want, e.eval(vars), 1e-9)
+			assert.Equal(t, tt.metrics, metrics)
+		})
+	}
+}
+
+func TestParseMetricExprErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		err  string
+	}{
+		{"", "unexpected end"},
+		{"jobs_pending +", "unexpected end"},
+		{"unknown_metric", "unknown metric"},
+		{"median(jobs_pending)", "unknown function"},
+		{"abs(jobs_pending, 1)", "wrong number of arguments"},
+		{"(jobs_pending", "missing )"},
+		{"jobs_pending jobs_running", "unexpected"},
+		{"1.2.3", "invalid number"},
+		{"jobs_pending > 1", "unexpected"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, _, err := parseMetricExpr(tt.expr)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package monitoring
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jontk/s9s/internal/dao"
+)
+
+// Data sources rule metrics are computed from
+const (
+	sourceNodes = "nodes"
+	sourceJobs  = "jobs"
+)
+
+// ruleMetric describes a metric available to rule expressions
+type ruleMetric struct {
+	Source      string
+	Description string
+}
+
+// ruleMetrics are the metrics rule expressions can reference. Each is
+// computed cluster-wide or over the nodes and jobs of one partition.
+var ruleMetrics = map[string]ruleMetric{
+	"nodes_total":           {sourceNodes, "Number of nodes"},
+	"nodes_down":            {sourceNodes, "Nodes down or not responding"},
+	"nodes_drain":           {sourceNodes, "Nodes drained, draining or failed"},
+	"nodes_idle":            {sourceNodes, "Idle nodes that accept jobs"},
+	"nodes_unavailable_pct": {sourceNodes, "Percent of nodes down, drained or failed"},
+	"cpus_total":            {sourceNodes, "Configured CPUs"},
+	"cpus_alloc":            {sourceNodes, "Allocated CPUs"},
+	"cpu_alloc_pct":         {sourceNodes, "Percent of CPUs allocated"},
+	"mem_alloc_pct":         {sourceNodes, "Percent of memory allocated"},
+	"gpus_total":            {sourceNodes, "Configured GPUs"},
+	"gpus_alloc":            {sourceNodes, "Allocated GPUs"},
+	"gpus_free":             {sourceNodes, "Free GPUs on nodes that accept jobs"},
+	"jobs_total":            {sourceJobs, "Jobs in the queue"},
+	"jobs_running":          {sourceJobs, "Running jobs"},
+	"jobs_pending":          {sourceJobs, "Pending jobs"},
+	"jobs_failed":           {sourceJobs, "Failed jobs"},
+	"jobs_waiting_1h":       {sourceJobs, "Jobs pending for more than an hour"},
+	"jobs_waiting_24h":      {sourceJobs, "Jobs pending for more than a day"},
+	"max_wait_hours":        {sourceJobs, "Longest wait of a pending job in hours"},
+}
+
+// RuleMetricNames returns the names of the metrics usable in rules, sorted
+func RuleMetricNames() []string {
+	names := make([]string, 0, len(ruleMetrics))
+	for name := range ruleMetrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RuleMetricDescription returns the description of a rule metric
+func RuleMetricDescription(name string) string {
+	return ruleMetrics[name].Description
+}
+
+// Snapshot is the cluster data rules are evaluated against
+type Snapshot struct {
+	Time       time.Time
+	Nodes      []*dao.Node
+	Jobs       []*dao.Job
+	Partitions []*dao.Partition
+	NodesErr   error // Rules using node metrics are unknown when set
+	JobsErr    error // Rules using job metrics are unknown when set
+}
+
+// CollectSnapshot fetches the data rules need from the client. At most
+// jobLimit jobs are fetched; 0 means no limit.
+func CollectSnapshot(client dao.SlurmClient, jobLimit int) *Snapshot {
+	snap := &Snapshot{Time: time.Now()}
+
+	if nodeList, err := client.Nodes().List(&dao.ListNodesOptions{}); err != nil {
+		snap.NodesErr = fmt.Errorf("failed to get node list: %w", err)
+	} else {
+		snap.Nodes = nodeList.Nodes
+	}
+
+	if jobList, err := client.Jobs().List(&dao.ListJobsOptions{Limit: jobLimit}); err != nil {
+		snap.JobsErr = fmt.Errorf("failed to get job list: %w", err)
+	} else {
+		snap.Jobs = jobList.Jobs
+	}
+
+	if partitionMgr := client.Partitions(); partitionMgr != nil {
+		if partitionList, err := partitionMgr.List(); err == nil {
+			snap.Partitions = partitionList.Partitions
+		}
+	}
+	return snap
+}
+
+// partitionNames returns the partitions of the snapshot, falling back to
+// the partitions referenced by nodes and jobs
+func (s *Snapshot) partitionNames() []string {
+	var names []string
+	for _, p := range s.Partitions {
+		names = append(names, p.Name)
+	}
+	if len(names) == 0 {
+		for _, n := range s.Nodes {
+			names = append(names, n.Partitions...)
+		}
+		for _, j := range s.Jobs {
+			names = append(names, jobPartitions(j)...)
+		}
+	}
+	sort.Strings(names)
+	return slices.Compact(names)
+}
+
+// metrics computes the rule metrics over the snapshot, restricted to one
+// partition unless partition is empty
+func (s *Snapshot) metrics(partition string) map[string]float64 {
+	m := make(map[string]float64, len(ruleMetrics))
+
+	var memTotal, memAlloc int64
+	for _, node := range s.Nodes {
+		if node == nil || (partition != "" && !slices.Contains(node.Partitions, partition)) {
+			continue
+		}
+		state := node.ParsedState()
+		m["nodes_total"]++
+		switch state.Summary() {
+		case dao.NodeStateDown:
+			m["nodes_down"]++
+		case dao.NodeStateDrain, dao.NodeStateDraining, dao.NodeFlagFail:
+			m["nodes_drain"]++
+		}
+		if state.Base == dao.NodeStateIdle && state.AcceptsJobs() {
+			m["nodes_idle"]++
+		}
+		m["cpus_total"] += float64(node.CPUsTotal)
+		m["cpus_alloc"] += float64(node.CPUsAllocated)
+		memTotal += node.MemoryTotal
+		memAlloc += node.MemoryAllocated
+
+		total, allocated := node.GPUCounts()
+		m["gpus_total"] += float64(total)
+		m["gpus_alloc"] += float64(allocated)
+		if state.AcceptsJobs() {
+			m["gpus_free"] += float64(total - allocated)
+		}
+	}
+	m["nodes_unavailable_pct"] = percent(m["nodes_down"]+m["nodes_drain"], m["nodes_total"])
+	m["cpu_alloc_pct"] = percent(m["cpus_alloc"], m["cpus_total"])
+	m["mem_alloc_pct"] = percent(float64(memAlloc), float64(memTotal))
+
+	for _, job := range s.Jobs {
+		if job == nil || (partition != "" && !slices.Contains(jobPartitions(job), partition)) {
+			continue
+		}
+		m["jobs_total"]++
+		switch job.State {
+		case dao.JobStateRunning:
+			m["jobs_running"]++
+		case dao.JobStateFailed:
+			m["jobs_failed"]++
+		case dao.JobStatePending:
+			m["jobs_pending"]++
+			if job.SubmitTime.IsZero() {
+				continue
+			}
+			wait := s.Time.Sub(job.SubmitTime)
+			if wait > time.Hour {
+				m["jobs_waiting_1h"]++
+			}
+			if wait > 24*time.Hour {
+				m["jobs_waiting_24h"]++
+			}
+			m["max_wait_hours"] = max(m["max_wait_hours"], wait.Hours())
+		}
+	}
+	return m
+}
+
+// jobPartitions returns the partitions a job was submitted to
+func jobPartitions(job *dao.Job) []string {
+	return strings.Split(job.Partition, ",")
+}
+
+// percent returns part as a percentage of total, or 0 for an empty total
+func percent(part, total float64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return part * 100 / total
+}
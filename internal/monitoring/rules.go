@@ -0,0 +1,430 @@
+package monitoring
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jontk/s9s/internal/config"
+)
+
+// builtinChecks are the health checks implemented in code. A rule with one
+// of these names and no expression retunes or disables the check.
+var builtinChecks = map[string]bool{"nodes": true, "queue": true, "utilization": true}
+
+// Rule is a declarative health check: a metric expression over the cluster
+// data with warning and critical thresholds
+type Rule struct {
+	Name        string
+	Description string
+	Expr        string        // Metric expression, e.g. "jobs_pending / max(nodes_idle, 1)"
+	Partitions  []string      // Evaluate per partition; "*" means every partition, empty cluster-wide
+	Warning     *float64      // Warning when the value is above this threshold
+	Critical    *float64      // Critical when the value is above this threshold
+	Below       bool          // Breach when the value falls below the thresholds instead
+	For         time.Duration // How long a breach must last before the rule fires
+	Severity    AlertSeverity // Overrides the severity of a breach, e.g. info
+	Message     string        // Message template with {value}, {partition}, {name} and metric placeholders
+	Disabled    bool
+
+	expr    metricExpr
+	sources map[string]bool
+}
+
+// DefaultRules returns the rules evaluated when none are configured
+func DefaultRules() []Rule {
+	return []Rule{
+		{Name: "down-nodes", Description: "Nodes down or not responding", Expr: "nodes_down",
+			Warning: floatPtr(0), Message: "{value} node(s) are down"},
+		{Name: "cpu-allocation", Description: "CPU allocation", Expr: "cpu_alloc_pct",
+			Warning: floatPtr(90), Message: "High CPU utilization: {value}%"},
+		{Name: "memory-allocation", Description: "Memory allocation", Expr: "mem_alloc_pct",
+			Warning: floatPtr(90), Message: "High memory utilization: {value}%"},
+		{Name: "long-waiting-jobs", Description: "Jobs pending for more than a day", Expr: "jobs_waiting_24h",
+			Warning: floatPtr(0), Severity: AlertSeverityInfo, Message: "{value} job(s) waiting >24h"},
+		{Name: "failed-jobs", Description: "Failed jobs in the queue", Expr: "jobs_failed",
+			Critical: floatPtr(10), Message: "{value} failed jobs detected"},
+	}
+}
+
+// RulesFromConfig merges the configured rules into the default rules. A
+// configured rule replaces the default rule of the same name.
+func RulesFromConfig(configured []config.HealthRuleConfig) ([]Rule, error) {
+	rules := DefaultRules()
+	var errs []error
+	for _, rc := range configured {
+		rule := Rule{
+			Name:        rc.Name,
+			Description: rc.Description,
+			Expr:        rc.Expr,
+			Partitions:  rc.Partitions,
+			Warning:     rc.Warning,
+			Critical:    rc.Critical,
+			Below:       rc.Below,
+			Severity:    AlertSeverity(strings.ToLower(rc.Severity)),
+			Message:     rc.Message,
+			Disabled:    rc.Disabled,
+		}
+		if rc.For != "" {
+			d, err := time.ParseDuration(rc.For)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("health rule %q: invalid for duration %q", rc.Name, rc.For))
+				continue
+			}
+			rule.For = d
+		}
+
+		replaced := false
+		for i := range rules {
+			if rules[i].Name == rule.Name {
+				rules[i] = rule
+				replaced = true
+			}
+		}
+		if !replaced {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, errors.Join(errs...)
+}
+
+// compile validates the rule and compiles its expression
+func (r *Rule) compile() error {
+	if r.Name == "" {
+		return errors.New("health rule without a name")
+	}
+	switch r.Severity {
+	case "", AlertSeverityInfo, AlertSeverityWarning, AlertSeverityCritical:
+	default:
+		return fmt.Errorf("health rule %q: unknown severity %q", r.Name, r.Severity)
+	}
+	if r.Expr == "" {
+		if builtinChecks[r.Name] {
+			return nil
+		}
+		return fmt.Errorf("health rule %q: missing expr", r.Name)
+	}
+	if r.Warning == nil && r.Critical == nil {
+		return fmt.Errorf("health rule %q: needs a warning or critical threshold", r.Name)
+	}
+
+	expr, metrics, err := parseMetricExpr(r.Expr)
+	if err != nil {
+		return fmt.Errorf("health rule %q: %w", r.Name, err)
+	}
+	r.expr = expr
+	r.sources = make(map[string]bool)
+	for _, m := range metrics {
+		r.sources[ruleMetrics[m].Source] = true
+	}
+	return nil
+}
+
+// breaches reports whether value is past threshold
+func (r *Rule) breaches(value float64, threshold *float64) bool {
+	if threshold == nil {
+		return false
+	}
+	if r.Below {
+		return value < *threshold
+	}
+	return value > *threshold
+}
+
+// level returns the severity of value, or "" when within thresholds
+func (r *Rule) level(value float64) AlertSeverity {
+	var level AlertSeverity
+	switch {
+	case r.breaches(value, r.Critical):
+		level = AlertSeverityCritical
+	case r.breaches(value, r.Warning):
+		level = AlertSeverityWarning
+	default:
+		return ""
+	}
+	if r.Severity != "" {
+		return r.Severity
+	}
+	return level
+}
+
+// threshold returns the rule thresholds as a HealthThreshold
+func (r *Rule) threshold() HealthThreshold {
+	if r.Below {
+		return HealthThreshold{WarningMin: r.Warning, CriticalMin: r.Critical}
+	}
+	return HealthThreshold{WarningMax: r.Warning, CriticalMax: r.Critical}
+}
+
+// RuleResult is the outcome of one rule for one scope
+type RuleResult struct {
+	Rule      *Rule
+	Partition string // Empty for cluster-wide rules
+	Value     float64
+	Status    HealthStatus
+	Severity  AlertSeverity // Severity of a firing rule, empty otherwise
+	Pending   bool          // Breached, but not for the rule's For duration yet
+	Since     time.Time     // When the current breach started
+	Message   string
+}
+
+// CheckName returns the health check name of the result
+func (r *RuleResult) CheckName() string {
+	if r.Partition == "" {
+		return r.Rule.Name
+	}
+	return fmt.Sprintf("%s[%s]", r.Rule.Name, r.Partition)
+}
+
+// Firing reports whether the rule has been breached for long enough to
+// raise an alert
+func (r *RuleResult) Firing() bool {
+	return r.Severity != ""
+}
+
+// RuleEngine evaluates health rules and tracks how long each has been
+// breached. It is safe for concurrent use.
+type RuleEngine struct {
+	rules    []*Rule
+	builtins map[string]*Rule
+
+	mu     sync.Mutex
+	breach map[string]time.Time // Start of the current breach by check name
+}
+
+// NewRuleEngine compiles the rules. Invalid rules are skipped and reported
+// in the returned error; the engine is usable either way.
+func NewRuleEngine(rules []Rule) (*RuleEngine, error) {
+	e := &RuleEngine{
+		builtins: make(map[string]*Rule),
+		breach:   make(map[string]time.Time),
+	}
+	var errs []error
+	for i := range rules {
+		rule := rules[i]
+		if err := rule.compile(); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if rule.Expr == "" {
+			e.builtins[rule.Name] = &rule
+			continue
+		}
+		if !rule.Disabled {
+			e.rules = append(e.rules, &rule)
+		}
+	}
+	return e, errors.Join(errs...)
+}
+
+// NewRuleEngineFromConfig builds an engine from the default rules merged
+// with the configured ones. Invalid rules are skipped and reported.
+func NewRuleEngineFromConfig(configured []config.HealthRuleConfig) (*RuleEngine, error) {
+	rules, configErr := RulesFromConfig(configured)
+	engine, err := NewRuleEngine(rules)
+	return engine, errors.Join(configErr, err)
+}
+
+// Rules returns the enabled expression rules
+func (e *RuleEngine) Rules() []*Rule {
+	return e.rules
+}
+
+// builtinOverride returns the rule retuning a built-in check, if any
+func (e *RuleEngine) builtinOverride(name string) *Rule {
+	if e == nil {
+		return nil
+	}
+	return e.builtins[name]
+}
+
+// Evaluate evaluates every rule against the snapshot
+func (e *RuleEngine) Evaluate(snap *Snapshot) []RuleResult {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	metrics := make(map[string]map[string]float64)
+	scopeMetrics := func(partition string) map[string]float64 {
+		if m, ok := metrics[partition]; ok {
+			return m
+		}
+		m := snap.metrics(partition)
+		metrics[partition] = m
+		return m
+	}
+
+	var results []RuleResult
+	seen := make(map[string]bool)
+	for _, rule := range e.rules {
+		for _, partition := range e.scopes(rule, snap) {
+			result := RuleResult{Rule: rule, Partition: partition}
+			name := result.CheckName()
+			seen[name] = true
+
+			if err := snap.sourceErr(rule); err != nil {
+				result.Status = HealthStatusUnknown
+				result.Message = capitalize(err.Error())
+				delete(e.breach, name)
+				results = append(results, result)
+				continue
+			}
+
+			vars := scopeMetrics(partition)
+			result.Value = rule.expr.eval(vars)
+			level := rule.level(result.Value)
+			if level == "" {
+				delete(e.breach, name)
+				result.Status = HealthStatusHealthy
+				result.Message = rule.healthyMessage(result.Value, partition)
+				results = append(results, result)
+				continue
+			}
+
+			since, ok := e.breach[name]
+			if !ok {
+				since = snap.Time
+				e.breach[name] = since
+			}
+			result.Since = since
+			result.Message = rule.render(result.Value, partition, vars)
+			if breached := snap.Time.Sub(since); breached < rule.For {
+				result.Pending = true
+				result.Status = HealthStatusHealthy
+				result.Message += fmt.Sprintf(" (pending %s of %s)", breached.Round(time.Second), rule.For)
+			} else {
+				result.Severity = level
+				result.Status = severityStatus(level)
+			}
+			results = append(results, result)
+		}
+	}
+
+	// Forget breaches of scopes that disappeared, e.g. a removed partition
+	for name := range e.breach {
+		if !seen[name] {
+			delete(e.breach, name)
+		}
+	}
+	return results
+}
+
+// scopes returns the partitions a rule is evaluated for; "" is cluster-wide
+func (e *RuleEngine) scopes(rule *Rule, snap *Snapshot) []string {
+	if len(rule.Partitions) == 0 {
+		return []string{""}
+	}
+	for _, p := range rule.Partitions {
+		if p == "*" {
+			return snap.partitionNames()
+		}
+	}
+	return rule.Partitions
+}
+
+// sourceErr returns the fetch error of a data source the rule needs
+func (s *Snapshot) sourceErr(rule *Rule) error {
+	if rule.sources[sourceNodes] && s.NodesErr != nil {
+		return s.NodesErr
+	}
+	if rule.sources[sourceJobs] && s.JobsErr != nil {
+		return s.JobsErr
+	}
+	return nil
+}
+
+// severityStatus maps an alert severity to a health status. Info rules
+// are reported without degrading health.
+func severityStatus(severity AlertSeverity) HealthStatus {
+	switch severity {
+	case AlertSeverityCritical:
+		return HealthStatusCritical
+	case AlertSeverityWarning:
+		return HealthStatusWarning
+	default:
+		return HealthStatusHealthy
+	}
+}
+
+// placeholderRe matches message template placeholders such as {value}
+var placeholderRe = regexp.MustCompile(`\{(\w+)\}`)
+
+// render renders the breach message of the rule
+func (r *Rule) render(value float64, partition string, vars map[string]float64) string {
+	if r.Message == "" {
+		return r.scopePrefix(partition) + fmt.Sprintf("%s is %s%s", r.label(), formatRuleValue(value), r.thresholdHint())
+	}
+	msg := placeholderRe.ReplaceAllStringFunc(r.Message, func(ph string) string {
+		key := ph[1 : len(ph)-1]
+		switch key {
+		case "value":
+			return formatRuleValue(value)
+		case "partition":
+			return partition
+		case "name":
+			return r.Name
+		}
+		if v, ok := vars[key]; ok {
+			return formatRuleValue(v)
+		}
+		return ph
+	})
+	if partition != "" && !strings.Contains(r.Message, "{partition}") {
+		msg = r.scopePrefix(partition) + msg
+	}
+	return msg
+}
+
+// healthyMessage describes a rule within its thresholds
+func (r *Rule) healthyMessage(value float64, partition string) string {
+	return r.scopePrefix(partition) + fmt.Sprintf("%s is %s%s", r.label(), formatRuleValue(value), r.thresholdHint())
+}
+
+func (r *Rule) label() string {
+	if r.Description != "" {
+		return r.Description
+	}
+	return r.Expr
+}
+
+func (r *Rule) scopePrefix(partition string) string {
+	if partition == "" {
+		return ""
+	}
+	return fmt.Sprintf("Partition %s: ", partition)
+}
+
+// thresholdHint describes the rule thresholds, e.g. " (warning >10, critical >25)"
+func (r *Rule) thresholdHint() string {
+	op := ">"
+	if r.Below {
+		op = "<"
+	}
+	var parts []string
+	if r.Warning != nil {
+		parts = append(parts, fmt.Sprintf("warning %s%s", op, formatRuleValue(*r.Warning)))
+	}
+	if r.Critical != nil {
+		parts = append(parts, fmt.Sprintf("critical %s%s", op, formatRuleValue(*r.Critical)))
+	}
+	return " (" + strings.Join(parts, ", ") + ")"
+}
+
+// formatRuleValue formats whole numbers without decimals and others with one
+func formatRuleValue(v float64) string {
+	if v == float64(int64(v)) {
+		return strconv.FormatInt(int64(v), 10)
+	}
+	return strconv.FormatFloat(v, 'f', 1, 64)
+}
+
+// capitalize upper-cases the first letter of s
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,252 @@
+package monitoring
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jontk/s9s/internal/config"
+	"github.com/jontk/s9s/internal/dao"
+	"github.com/jontk/s9s/pkg/slurm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ruleSnapshot returns a snapshot with two partitions: cpu with one down
+// node and a deep queue, gpu healthy
+func ruleSnapshot(now time.Time) *Snapshot {
+	return &Snapshot{
+		Time: now,
+		Nodes: []*dao.Node{
+			{Name: "c1", State: "IDLE", Partitions: []string{"cpu"}, CPUsTotal: 10},
+			{Name: "c2", State: "DOWN", Partitions: []string{"cpu"}, CPUsTotal: 10},
+			{Name: "g1", State: "ALLOCATED", Partitions: []string{"gpu"}, CPUsTotal: 10, CPUsAllocated: 10},
+		},
+		Jobs: []*dao.Job{
+			{ID: "1", State: dao.JobStatePending, Partition: "cpu", SubmitTime: now.Add(-48 * time.Hour)},
+			{ID: "2", State: dao.JobStatePending, Partition: "cpu", SubmitTime: now.Add(-2 * time.Hour)},
+			{ID: "3", State: dao.JobStatePending, Partition: "cpu", SubmitTime: now},
+			{ID: "4", State: dao.JobStateRunning, Partition: "gpu"},
+		},
+	}
+}
+
+// resultByName returns the result of the named check
+func resultByName(t *testing.T, results []RuleResult, name string) RuleResult {
+	t.Helper()
+	for _, r := range results {
+		if r.CheckName() == name {
+			return r
+		}
+	}
+	require.Failf(t, "missing result", "no result for %s", name)
+	return RuleResult{}
+}
+
+func TestSnapshotMetrics(t *testing.T) {
+	snap := ruleSnapshot(time.Now())
+
+	all := snap.metrics("")
+	assert.Equal(t, 3.0, all["nodes_total"])
+	assert.Equal(t, 1.0, all["nodes_down"])
+	assert.Equal(t, 1.0, all["nodes_idle"])
+	assert.InDelta(t, 100.0/3, all["cpu_alloc_pct"], 1e-9)
+	assert.Equal(t, 3.0, all["jobs_pending"])
+	assert.Equal(t, 2.0, all["jobs_waiting_1h"])
+	assert.Equal(t, 1.0, all["jobs_waiting_24h"])
+	assert.InDelta(t, 48.0, all["max_wait_hours"], 1e-6)
+
+	gpu := snap.metrics("gpu")
+	assert.Equal(t, 1.0, gpu["nodes_total"])
+	assert.Equal(t, 0.0, gpu["jobs_pending"])
+	assert.Equal(t, 1.0, gpu["jobs_running"])
+	assert.Equal(t, 100.0, gpu["cpu_alloc_pct"])
+
+	assert.Equal(t, []string{"cpu", "gpu"}, snap.partitionNames())
+}
+
+func TestRuleEngineThresholds(t *testing.T) {
+	engine, err := NewRuleEngine([]Rule{
+		{Name: "pending", Expr: "jobs_pending", Warning: floatPtr(2), Critical: floatPtr(5)},
+		{Name: "idle", Expr: "nodes_idle", Warning: floatPtr(2), Below: true},
+		{Name: "waiting", Expr: "jobs_waiting_24h", Warning: floatPtr(0), Severity: AlertSeverityInfo,
+			Message: "{value} job(s) of {jobs_pending} waiting >24h"},
+		{Name: "off", Expr: "nodes_down", Warning: floatPtr(0), Disabled: true},
+	})
+	require.NoError(t, err)
+
+	results := engine.Evaluate(ruleSnapshot(time.Now()))
+	require.Len(t, results, 3, "disabled rules are not evaluated")
+
+	pending := resultByName(t, results, "pending")
+	assert.Equal(t, HealthStatusWarning, pending.Status)
+	assert.Equal(t, AlertSeverityWarning, pending.Severity)
+	assert.Equal(t, 3.0, pending.Value)
+
+	idle := resultByName(t, results, "idle")
+	assert.Equal(t, HealthStatusWarning, idle.Status, "below rules breach under the threshold")
+
+	waiting := resultByName(t, results, "waiting")
+	assert.True(t, waiting.Firing())
+	assert.Equal(t, AlertSeverityInfo, waiting.Severity)
+	assert.Equal(t, HealthStatusHealthy, waiting.Status, "info rules do not degrade health")
+	assert.Equal(t, "1 job(s) of 3 waiting >24h", waiting.Message)
+}
+
+func TestRuleEnginePartitionScope(t *testing.T) {
+	engine, err := NewRuleEngine([]Rule{
+		{Name: "down", Expr: "nodes_down", Partitions: []string{"*"}, Critical: floatPtr(0)},
+		{Name: "gpu-pending", Expr: "jobs_pending", Partitions: []string{"gpu"}, Warning: floatPtr(0)},
+	})
+	require.NoError(t, err)
+
+	results := engine.Evaluate(ruleSnapshot(time.Now()))
+	require.Len(t, results, 3)
+
+	cpu := resultByName(t, results, "down[cpu]")
+	assert.Equal(t, HealthStatusCritical, cpu.Status)
+	assert.Equal(t, "cpu", cpu.Partition)
+	assert.Contains(t, cpu.Message, "Partition cpu:")
+
+	assert.Equal(t, HealthStatusHealthy, resultByName(t, results, "down[gpu]").Status)
+	assert.Equal(t, HealthStatusHealthy, resultByName(t, results, "gpu-pending[gpu]").Status)
+}
+
+func TestRuleEngineForDuration(t *testing.T) {
+	engine, err := NewRuleEngine([]Rule{
+		{Name: "pending", Expr: "jobs_pending", Warning: floatPtr(0), For: 10 * time.Minute},
+	})
+	require.NoError(t, err)
+
+	start := time.Now()
+	first := engine.Evaluate(ruleSnapshot(start))[0]
+	assert.True(t, first.Pending)
+	assert.False(t, first.Firing())
+	assert.Equal(t, HealthStatusHealthy, first.Status)
+
+	later := engine.Evaluate(ruleSnapshot(start.Add(10 * time.Minute)))[0]
+	assert.False(t, later.Pending)
+	assert.True(t, later.Firing())
+	assert.Equal(t, start, later.Since)
+
+	// Recovering resets the breach
+	healthy := ruleSnapshot(start.Add(11 * time.Minute))
+	healthy.Jobs = nil
+	assert.False(t, engine.Evaluate(healthy)[0].Pending)
+
+	again := engine.Evaluate(ruleSnapshot(start.Add(12 * time.Minute)))[0]
+	assert.True(t, again.Pending)
+}
+
+func TestRuleEngineSourceErrors(t *testing.T) {
+	engine, err := NewRuleEngine([]Rule{
+		{Name: "pending", Expr: "jobs_pending", Warning: floatPtr(0)},
+		{Name: "down", Expr: "nodes_down", Warning: floatPtr(0)},
+	})
+	require.NoError(t, err)
+
+	snap := ruleSnapshot(time.Now())
+	snap.JobsErr = errors.New("failed to get job list: timeout")
+	results := engine.Evaluate(snap)
+
+	pending := resultByName(t, results, "pending")
+	assert.Equal(t, HealthStatusUnknown, pending.Status)
+	assert.Equal(t, "Failed to get job list: timeout", pending.Message)
+	assert.Equal(t, HealthStatusWarning, resultByName(t, results, "down").Status)
+}
+
+func TestNewRuleEngineInvalidRules(t *testing.T) {
+	engine, err := NewRuleEngine([]Rule{
+		{Name: "ok", Expr: "nodes_down", Warning: floatPtr(0)},
+		{Name: "bad-expr", Expr: "nodes_down +", Warning: floatPtr(0)},
+		{Name: "no-threshold", Expr: "nodes_down"},
+		{Name: "no-expr", Warning: floatPtr(0)},
+		{Name: "bad-severity", Expr: "nodes_down", Warning: floatPtr(0), Severity: "fatal"},
+		{Name: "queue", Warning: floatPtr(5)},
+	})
+	require.Error(t, err)
+	for _, name := range []string{"bad-expr", "no-threshold", "no-expr", "bad-severity"} {
+		assert.Contains(t, err.Error(), name)
+	}
+	require.Len(t, engine.Rules(), 1)
+	assert.Equal(t, "ok", engine.Rules()[0].Name)
+	assert.NotNil(t, engine.builtinOverride("queue"))
+}
+
+func TestRulesFromConfig(t *testing.T) {
+	rules, err := RulesFromConfig([]config.HealthRuleConfig{
+		{Name: "failed-jobs", Expr: "jobs_failed", Critical: floatPtr(50), For: "5m", Severity: "Warning"},
+		{Name: "gpu-free", Expr: "gpus_free", Warning: floatPtr(1), Below: true, Partitions: []string{"gpu"}},
+		{Name: "broken", Expr: "nodes_down", Warning: floatPtr(0), For: "soon"},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "broken")
+
+	require.Len(t, rules, len(DefaultRules())+1)
+	byName := make(map[string]Rule)
+	for _, r := range rules {
+		byName[r.Name] = r
+	}
+	assert.Equal(t, 50.0, *byName["failed-jobs"].Critical)
+	assert.Equal(t, 5*time.Minute, byName["failed-jobs"].For)
+	assert.Equal(t, AlertSeverityWarning, byName["failed-jobs"].Severity)
+	assert.True(t, byName["gpu-free"].Below)
+	assert.NotContains(t, byName, "broken")
+}
+
+func TestDefaultRulesAgainstMockClient(t *testing.T) {
+	mock := slurm.NewMockClient()
+	mock.SetDelay(0)
+
+	engine, err := NewRuleEngine(DefaultRules())
+	require.NoError(t, err)
+
+	snap := CollectSnapshot(mock, 0)
+	require.NoError(t, snap.NodesErr)
+	require.NoError(t, snap.JobsErr)
+
+	results := engine.Evaluate(snap)
+	require.Len(t, results, len(DefaultRules()))
+	for _, r := range results {
+		assert.NotEqual(t, HealthStatusUnknown, r.Status, r.CheckName())
+		assert.NotEmpty(t, r.Message, r.CheckName())
+	}
+}
+
+func TestHealthMonitorRuleEngine(t *testing.T) {
+	mock := slurm.NewMockClient()
+	mock.SetDelay(0)
+
+	engine, err := NewRuleEngine([]Rule{
+		{Name: "queue", Warning: floatPtr(1), Critical: floatPtr(2)},
+		{Name: "utilization", Disabled: true},
+		{Name: "pending", Expr: "jobs_pending", Partitions: []string{"*"}, Warning: floatPtr(1000)},
+	})
+	require.NoError(t, err)
+
+	hm := NewHealthMonitor(mock, time.Minute)
+	hm.SetRuleEngine(engine)
+	health := hm.RunChecks()
+
+	assert.NotContains(t, health.Checks, "utilization", "disabled built-in checks are removed")
+	require.Contains(t, health.Checks, "queue")
+	assert.Equal(t, 1.0, *health.Checks["queue"].Threshold.WarningMax)
+	assert.Equal(t, 2.0, *health.Checks["queue"].Threshold.CriticalMax)
+
+	found := false
+	for name, check := range health.Checks {
+		if strings.HasPrefix(name, "pending[") {
+			found = true
+			assert.Equal(t, HealthStatusHealthy, check.Status)
+			assert.Equal(t, 1000.0, *check.Threshold.WarningMax)
+		}
+	}
+	assert.True(t, found, "per-partition rule checks are reported")
+
+	results := hm.RuleResults()
+	require.NotEmpty(t, results, "the last rule evaluation is kept for the dashboard")
+	for _, result := range results {
+		assert.Contains(t, health.Checks, result.CheckName())
+	}
+}
@@ -0,0 +1,238 @@
+// Package pipeline submits multi-stage job pipelines. A pipeline spec lists
+// stages that each submit a job template and run after other stages; the
+// stages are submitted in dependency order with the job IDs of the stages
+// they wait for filled in as SLURM dependencies. A submitted pipeline is a
+// Run, which maps every stage to its job and can resubmit a stage together
+// with the stages downstream of it.
+package pipeline
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/jontk/s9s/internal/dao"
+	"gopkg.in/yaml.v3"
+)
+
+// namePattern restricts pipeline and stage names to what can be typed as
+// a command argument
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Spec is a pipeline: its stages, in any order
+type Spec struct {
+	Name        string  `yaml:"name" json:"name"`
+	Description string  `yaml:"description,omitempty" json:"description,omitempty"`
+	Stages      []Stage `yaml:"stages" json:"stages"`
+}
+
+// Stage is a job of a pipeline, submitted from a job template
+type Stage struct {
+	Name       string            `yaml:"name" json:"name"`
+	Template   string            `yaml:"template" json:"template"`
+	Set        map[string]string `yaml:"set,omitempty" json:"set,omitempty"`               // Template parameter values
+	After      []string          `yaml:"after,omitempty" json:"after,omitempty"`           // Stages the job waits for
+	Dependency string            `yaml:"dependency,omitempty" json:"dependency,omitempty"` // How it waits for them (default: afterok)
+	Array      string            `yaml:"array,omitempty" json:"array,omitempty"`           // Fans the stage out as a job array, e.g. "0-9%4"
+}
+
+// DependencyType returns how the stage waits for the stages it is after
+func (s Stage) DependencyType() string {
+	if s.Dependency == "" {
+		return dao.DependencyAfterOK
+	}
+	return s.Dependency
+}
+
+// Load reads a pipeline spec from a YAML file and validates it
+func Load(path string) (*Spec, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // the spec is chosen by the user
+	if err != nil {
+		return nil, err
+	}
+	spec, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return spec, nil
+}
+
+// Parse parses and validates a pipeline spec
+func Parse(data []byte) (*Spec, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	var spec Spec
+	if err := decoder.Decode(&spec); err != nil {
+		return nil, err
+	}
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+	return &spec, nil
+}
+
+// Validate checks the names, templates and dependencies of the stages and
+// that they have no cycle
+func (s *Spec) Validate() error {
+	if !namePattern.MatchString(s.Name) {
+		return fmt.Errorf("invalid pipeline name %q: use letters, digits, '.', '-' and '_'", s.Name)
+	}
+	if len(s.Stages) == 0 {
+		return errors.New("the pipeline has no stages")
+	}
+
+	var errs []error
+	seen := make(map[string]bool, len(s.Stages))
+	for _, stage := range s.Stages {
+		if seen[stage.Name] {
+			errs = append(errs, fmt.Errorf("stage %s is declared twice", stage.Name))
+		}
+		seen[stage.Name] = true
+	}
+	for _, stage := range s.Stages {
+		if err := s.validateStage(stage, seen); err != nil {
+			errs = append(errs, fmt.Errorf("stage %s: %w", stage.Name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	_, err := s.Order()
+	return err
+}
+
+// validateStage checks one stage; names holds the stage names
+func (s *Spec) validateStage(stage Stage, names map[string]bool) error {
+	if !namePattern.MatchString(stage.Name) {
+		return errors.New("invalid name: use letters, digits, '.', '-' and '_'")
+	}
+	if stage.Template == "" {
+		return errors.New("no template")
+	}
+	for i, after := range stage.After {
+		switch {
+		case after == stage.Name:
+			return errors.New("a stage cannot run after itself")
+		case !names[after]:
+			return fmt.Errorf("runs after unknown stage %q", after)
+		case slices.Contains(stage.After[:i], after):
+			return fmt.Errorf("runs after %s twice", after)
+		}
+	}
+	if !slices.Contains(dao.DependencyTypes, stage.DependencyType()) {
+		return fmt.Errorf("unknown dependency %q (use %s)", stage.Dependency, strings.Join(dao.DependencyTypes, ", "))
+	}
+	if stage.Dependency != "" && len(stage.After) == 0 {
+		return errors.New("a dependency needs stages to run after")
+	}
+	if stage.DependencyType() == dao.DependencyAfterCorr {
+		if stage.Array == "" {
+			return errors.New("aftercorr needs the stage to be an array")
+		}
+		for _, after := range stage.After {
+			if upstream, _ := s.Stage(after); upstream.Array == "" {
+				return fmt.Errorf("aftercorr needs %s to be an array", after)
+			}
+		}
+	}
+	return nil
+}
+
+// Stage returns the named stage
+func (s *Spec) Stage(name string) (Stage, bool) {
+	for _, stage := range s.Stages {
+		if stage.Name == name {
+			return stage, true
+		}
+	}
+	return Stage{}, false
+}
+
+// Order returns the stage names in the order they can be submitted: every
+// stage after the stages it waits for, otherwise in declaration order
+func (s *Spec) Order() ([]string, error) {
+	placed := make(map[string]bool, len(s.Stages))
+	order := make([]string, 0, len(s.Stages))
+	for len(order) < len(s.Stages) {
+		progress := false
+		for _, stage := range s.Stages {
+			if placed[stage.Name] || !allPlaced(stage.After, placed) {
+				continue
+			}
+			placed[stage.Name] = true
+			order = append(order, stage.Name)
+			progress = true
+		}
+		if !progress {
+			var cycle []string
+			for _, stage := range s.Stages {
+				if !placed[stage.Name] {
+					cycle = append(cycle, stage.Name)
+				}
+			}
+			return nil, fmt.Errorf("stages %s depend on each other in a cycle", strings.Join(cycle, ", "))
+		}
+	}
+	return order, nil
+}
+
+// allPlaced reports whether every name is placed
+func allPlaced(names []string, placed map[string]bool) bool {
+	for _, name := range names {
+		if !placed[name] {
+			return false
+		}
+	}
+	return true
+}
+
+// Levels groups the stages by depth: the stages that wait for no other
+// stage, then the stages that wait only for those, and so on
+func (s *Spec) Levels() [][]string {
+	order, err := s.Order()
+	if err != nil {
+		return nil
+	}
+	depth := make(map[string]int, len(order))
+	var levels [][]string
+	for _, name := range order {
+		stage, _ := s.Stage(name)
+		d := 0
+		for _, after := range stage.After {
+			d = max(d, depth[after]+1)
+		}
+		depth[name] = d
+		if d == len(levels) {
+			levels = append(levels, nil)
+		}
+		levels[d] = append(levels[d], name)
+	}
+	return levels
+}
+
+// Descendants returns the named stage and every stage that waits for it,
+// directly or not, in submission order
+func (s *Spec) Descendants(name string) []string {
+	affected := map[string]bool{name: true}
+	order, err := s.Order()
+	if err != nil {
+		return nil
+	}
+	var result []string
+	for _, stageName := range order {
+		stage, _ := s.Stage(stageName)
+		for _, after := range stage.After {
+			if affected[after] {
+				affected[stageName] = true
+			}
+		}
+		if affected[stageName] {
+			result = append(result, stageName)
+		}
+	}
+	return result
+}
@@ -0,0 +1,123 @@
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const trainSpec = `
+name: train
+stages:
+  - name: eval
+    template: evaluate
+    after: [train]
+  - name: prep
+    template: preprocess
+    array: "0-3"
+  - name: train
+    template: train-gpu
+    after: [prep]
+    dependency: afterany
+    set:
+      epochs: "20"
+  - name: report
+    template: report
+    after: [eval, prep]
+`
+
+func TestParse(t *testing.T) {
+	spec, err := Parse([]byte(trainSpec))
+	require.NoError(t, err)
+	assert.Equal(t, "train", spec.Name)
+	require.Len(t, spec.Stages, 4)
+
+	train, ok := spec.Stage("train")
+	require.True(t, ok)
+	assert.Equal(t, "afterany", train.DependencyType())
+	assert.Equal(t, map[string]string{"epochs": "20"}, train.Set)
+
+	eval, _ := spec.Stage("eval")
+	assert.Equal(t, "afterok", eval.DependencyType(), "afterok by default")
+
+	order, err := spec.Order()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"prep", "train", "eval", "report"}, order)
+	assert.Equal(t, [][]string{{"prep"}, {"train"}, {"eval"}, {"report"}}, spec.Levels())
+	assert.Equal(t, []string{"train", "eval", "report"}, spec.Descendants("train"))
+	assert.Equal(t, []string{"report"}, spec.Descendants("report"))
+}
+
+func TestParseUnknownField(t *testing.T) {
+	_, err := Parse([]byte("name: p\nstages:\n  - name: a\n    templte: x\n"))
+	assert.ErrorContains(t, err, "field templte not found")
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		spec Spec
+		err  string
+	}{
+		{
+			name: "bad name",
+			spec: Spec{Name: "my pipeline", Stages: []Stage{{Name: "a", Template: "t"}}},
+			err:  `invalid pipeline name "my pipeline"`,
+		},
+		{
+			name: "no stages",
+			spec: Spec{Name: "p"},
+			err:  "the pipeline has no stages",
+		},
+		{
+			name: "duplicate stage",
+			spec: Spec{Name: "p", Stages: []Stage{{Name: "a", Template: "t"}, {Name: "a", Template: "t"}}},
+			err:  "stage a is declared twice",
+		},
+		{
+			name: "no template",
+			spec: Spec{Name: "p", Stages: []Stage{{Name: "a"}}},
+			err:  "stage a: no template",
+		},
+		{
+			name: "unknown stage",
+			spec: Spec{Name: "p", Stages: []Stage{{Name: "a", Template: "t", After: []string{"b"}}}},
+			err:  `stage a: runs after unknown stage "b"`,
+		},
+		{
+			name: "after itself",
+			spec: Spec{Name: "p", Stages: []Stage{{Name: "a", Template: "t", After: []string{"a"}}}},
+			err:  "stage a: a stage cannot run after itself",
+		},
+		{
+			name: "unknown dependency",
+			spec: Spec{Name: "p", Stages: []Stage{{Name: "a", Template: "t"}, {Name: "b", Template: "t", After: []string{"a"}, Dependency: "afterwards"}}},
+			err:  `stage b: unknown dependency "afterwards"`,
+		},
+		{
+			name: "dependency without after",
+			spec: Spec{Name: "p", Stages: []Stage{{Name: "a", Template: "t", Dependency: "afterany"}}},
+			err:  "stage a: a dependency needs stages to run after",
+		},
+		{
+			name: "aftercorr needs arrays",
+			spec: Spec{Name: "p", Stages: []Stage{{Name: "a", Template: "t"}, {Name: "b", Template: "t", After: []string{"a"}, Dependency: "aftercorr", Array: "0-3"}}},
+			err:  "stage b: aftercorr needs a to be an array",
+		},
+		{
+			name: "cycle",
+			spec: Spec{Name: "p", Stages: []Stage{
+				{Name: "a", Template: "t"},
+				{Name: "b", Template: "t", After: []string{"a", "c"}},
+				{Name: "c", Template: "t", After: []string{"b"}},
+			}},
+			err: "stages b, c depend on each other in a cycle",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorContains(t, tt.spec.Validate(), tt.err)
+		})
+	}
+}
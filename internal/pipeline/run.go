@@ -0,0 +1,153 @@
+package pipeline
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/jontk/s9s/internal/dao"
+)
+
+// StateUnknown is the state of a stage whose job cannot be read
+const StateUnknown = "UNKNOWN"
+
+// Resolver returns the job a stage submits, from its template and
+// parameter values. The pipeline adds the array and dependencies.
+type Resolver func(stage Stage) (*dao.JobSubmission, error)
+
+// Run is a submitted pipeline
+type Run struct {
+	ID         string              `json:"id"`
+	Spec       Spec                `json:"spec"`
+	SpecFile   string              `json:"spec_file,omitempty"`
+	WorkingDir string              `json:"working_dir,omitempty"`
+	Created    time.Time           `json:"created"`
+	Jobs       map[string]string   `json:"jobs"`               // Stage -> job ID of its latest submission
+	Previous   map[string][]string `json:"previous,omitempty"` // Stage -> job IDs of earlier submissions
+}
+
+// NewRun returns a run of spec that has not submitted anything yet
+func NewRun(spec *Spec, created time.Time) *Run {
+	return &Run{
+		ID:       spec.Name + "-" + created.Format("20060102-150405"),
+		Spec:     *spec,
+		Created:  created,
+		Jobs:     make(map[string]string),
+		Previous: make(map[string][]string),
+	}
+}
+
+// Submit submits every stage of the run in order. The stages are resolved
+// before any is submitted, so a missing template submits nothing; a failed
+// submission leaves the stages before it submitted, and recorded in the run.
+func (r *Run) Submit(jobs dao.JobManager, resolve Resolver, progress func(stage, jobID string)) error {
+	order, err := r.Spec.Order()
+	if err != nil {
+		return err
+	}
+	return r.submitStages(jobs, resolve, order, progress)
+}
+
+// Resubmit submits the named stage again, with every stage downstream of
+// it. Pending jobs of those stages are cancelled first, as they wait for
+// the job being replaced. It returns the resubmitted stages.
+func (r *Run) Resubmit(jobs dao.JobManager, resolve Resolver, stage string) ([]string, error) {
+	if _, ok := r.Spec.Stage(stage); !ok {
+		return nil, fmt.Errorf("pipeline %s has no stage %s", r.Spec.Name, stage)
+	}
+	stages := r.Spec.Descendants(stage)
+	for _, name := range stages {
+		id := r.Jobs[name]
+		if id == "" {
+			continue
+		}
+		job, err := jobs.Get(id)
+		if err != nil || job == nil || job.State != dao.JobStatePending {
+			continue
+		}
+		if err := jobs.Cancel(id); err != nil {
+			return nil, fmt.Errorf("failed to cancel job %s of stage %s: %w", id, name, err)
+		}
+	}
+	return stages, r.submitStages(jobs, resolve, stages, nil)
+}
+
+// submitStages submits the given stages, which are in submission order
+func (r *Run) submitStages(jobs dao.JobManager, resolve Resolver, stages []string, progress func(stage, jobID string)) error {
+	resolved := make([]*dao.JobSubmission, len(stages))
+	for i, name := range stages {
+		stage, _ := r.Spec.Stage(name)
+		job, err := resolve(stage)
+		if err != nil {
+			return fmt.Errorf("stage %s: %w", name, err)
+		}
+		if stage.Array != "" {
+			job.ArraySpec = stage.Array
+		}
+		resolved[i] = job
+	}
+
+	if r.Jobs == nil {
+		r.Jobs = make(map[string]string)
+	}
+	if r.Previous == nil {
+		r.Previous = make(map[string][]string)
+	}
+	for i, name := range stages {
+		stage, _ := r.Spec.Stage(name)
+		job := resolved[i]
+		if len(stage.After) > 0 {
+			ids := make([]string, len(stage.After))
+			for j, after := range stage.After {
+				if ids[j] = r.Jobs[after]; ids[j] == "" {
+					return fmt.Errorf("stage %s: stage %s has no job to wait for", name, after)
+				}
+			}
+			job.Dependencies = append(slices.Clone(job.Dependencies), dao.Dependency(stage.DependencyType(), ids...))
+		}
+
+		id, err := jobs.Submit(job)
+		if err != nil {
+			return fmt.Errorf("stage %s: failed to submit job: %w", name, err)
+		}
+		if previous := r.Jobs[name]; previous != "" {
+			r.Previous[name] = append(r.Previous[name], previous)
+		}
+		r.Jobs[name] = id
+		if progress != nil {
+			progress(name, id)
+		}
+	}
+	return nil
+}
+
+// States returns the state of the job of every submitted stage. Jobs that
+// cannot be read, such as jobs that left the queue, are UNKNOWN.
+func (r *Run) States(jobs dao.JobManager) map[string]string {
+	states := make(map[string]string, len(r.Jobs))
+	for stage, id := range r.Jobs {
+		job, err := jobs.Get(id)
+		if err != nil || job == nil {
+			states[stage] = StateUnknown
+			continue
+		}
+		states[stage] = job.State
+	}
+	return states
+}
+
+// Clone returns a deep copy of the run
+func (r *Run) Clone() *Run {
+	c := *r
+	c.Spec.Stages = slices.Clone(r.Spec.Stages)
+	c.Jobs = maps.Clone(r.Jobs)
+	c.Previous = make(map[string][]string, len(r.Previous))
+	for stage, ids := range r.Previous {
+		c.Previous[stage] = slices.Clone(ids)
+	}
+	if c.Jobs == nil {
+		c.Jobs = make(map[string]string)
+	}
+	return &c
+}
@@ -0,0 +1,147 @@
+package pipeline
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jontk/s9s/internal/dao"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeJobs records submissions and cancellations
+type fakeJobs struct {
+	dao.JobManager
+	submitted []*dao.JobSubmission
+	states    map[string]string
+	cancelled []string
+	failOn    string // Name of a job whose submission fails
+}
+
+func (f *fakeJobs) Submit(job *dao.JobSubmission) (string, error) {
+	if job.Name == f.failOn {
+		return "", errors.New("QOS limit reached")
+	}
+	f.submitted = append(f.submitted, job)
+	id := fmt.Sprint(100 + len(f.submitted))
+	if f.states == nil {
+		f.states = make(map[string]string)
+	}
+	f.states[id] = dao.JobStatePending
+	return id, nil
+}
+
+func (f *fakeJobs) Get(id string) (*dao.Job, error) {
+	state, ok := f.states[id]
+	if !ok {
+		return nil, fmt.Errorf("job %s not found", id)
+	}
+	return &dao.Job{ID: id, State: state}, nil
+}
+
+func (f *fakeJobs) Cancel(id string) error {
+	f.cancelled = append(f.cancelled, id)
+	f.states[id] = dao.JobStateCancelled
+	return nil
+}
+
+func resolveByName(stage Stage) (*dao.JobSubmission, error) {
+	if stage.Template == "missing" {
+		return nil, errors.New("template missing not found")
+	}
+	return &dao.JobSubmission{Name: stage.Name, Script: "#!/bin/bash\n"}, nil
+}
+
+func submittedByName(jobs *fakeJobs) map[string]*dao.JobSubmission {
+	byName := make(map[string]*dao.JobSubmission)
+	for _, job := range jobs.submitted {
+		byName[job.Name] = job
+	}
+	return byName
+}
+
+func TestRunSubmit(t *testing.T) {
+	spec, err := Parse([]byte(trainSpec))
+	require.NoError(t, err)
+	run := NewRun(spec, time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC))
+	assert.Equal(t, "train-20261001-120000", run.ID)
+
+	jobs := &fakeJobs{}
+	var progress []string
+	require.NoError(t, run.Submit(jobs, resolveByName, func(stage, id string) {
+		progress = append(progress, stage+"="+id)
+	}))
+
+	assert.Equal(t, []string{"prep=101", "train=102", "eval=103", "report=104"}, progress)
+	assert.Equal(t, map[string]string{"prep": "101", "train": "102", "eval": "103", "report": "104"}, run.Jobs)
+
+	byName := submittedByName(jobs)
+	assert.Equal(t, "0-3", byName["prep"].ArraySpec)
+	assert.Empty(t, byName["prep"].Dependencies)
+	assert.Equal(t, []string{"afterany:101"}, byName["train"].Dependencies)
+	assert.Equal(t, []string{"afterok:102"}, byName["eval"].Dependencies)
+	assert.Equal(t, []string{"afterok:103:101"}, byName["report"].Dependencies)
+}
+
+func TestRunSubmitFailures(t *testing.T) {
+	spec, err := Parse([]byte(trainSpec))
+	require.NoError(t, err)
+
+	t.Run("unresolved stage submits nothing", func(t *testing.T) {
+		broken := *spec
+		broken.Stages = append([]Stage(nil), spec.Stages...)
+		broken.Stages[0].Template = "missing"
+		jobs := &fakeJobs{}
+		err := NewRun(&broken, time.Now()).Submit(jobs, resolveByName, nil)
+		assert.EqualError(t, err, "stage eval: template missing not found")
+		assert.Empty(t, jobs.submitted)
+	})
+
+	t.Run("failed submission keeps earlier stages", func(t *testing.T) {
+		jobs := &fakeJobs{failOn: "eval"}
+		run := NewRun(spec, time.Now())
+		err := run.Submit(jobs, resolveByName, nil)
+		assert.EqualError(t, err, "stage eval: failed to submit job: QOS limit reached")
+		assert.Equal(t, map[string]string{"prep": "101", "train": "102"}, run.Jobs)
+	})
+}
+
+func TestRunResubmit(t *testing.T) {
+	spec, err := Parse([]byte(trainSpec))
+	require.NoError(t, err)
+	run := NewRun(spec, time.Now())
+	jobs := &fakeJobs{}
+	require.NoError(t, run.Submit(jobs, resolveByName, nil))
+
+	// train failed, so eval and report are stuck pending on it
+	jobs.states["101"] = dao.JobStateCompleted
+	jobs.states["102"] = dao.JobStateFailed
+
+	stages, err := run.Resubmit(jobs, resolveByName, "train")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"train", "eval", "report"}, stages)
+	assert.Equal(t, []string{"103", "104"}, jobs.cancelled, "only pending jobs are cancelled")
+
+	assert.Equal(t, map[string]string{"prep": "101", "train": "105", "eval": "106", "report": "107"}, run.Jobs)
+	assert.Equal(t, map[string][]string{"train": {"102"}, "eval": {"103"}, "report": {"104"}}, run.Previous)
+
+	byName := submittedByName(jobs)
+	assert.Equal(t, []string{"afterany:101"}, byName["train"].Dependencies)
+	assert.Equal(t, []string{"afterok:105"}, byName["eval"].Dependencies)
+	assert.Equal(t, []string{"afterok:106:101"}, byName["report"].Dependencies)
+
+	states := run.States(jobs)
+	assert.Equal(t, dao.JobStateCompleted, states["prep"])
+	assert.Equal(t, dao.JobStatePending, states["train"])
+
+	_, err = run.Resubmit(jobs, resolveByName, "nope")
+	assert.EqualError(t, err, "pipeline train has no stage nope")
+}
+
+func TestRunStates(t *testing.T) {
+	run := &Run{Jobs: map[string]string{"a": "1", "b": "2"}}
+	jobs := &fakeJobs{states: map[string]string{"1": dao.JobStateRunning}}
+	assert.Equal(t, map[string]string{"a": dao.JobStateRunning, "b": StateUnknown}, run.States(jobs))
+}
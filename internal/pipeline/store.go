@@ -0,0 +1,172 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/jontk/s9s/internal/fileperms"
+)
+
+// RunStore keeps pipeline runs in a JSON file, by default
+// ~/.s9s/pipelines.json
+type RunStore struct {
+	mu   sync.RWMutex
+	path string
+	runs map[string]*Run
+}
+
+// DefaultRunsPath returns the file pipeline runs are stored in
+func DefaultRunsPath() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".s9s", "pipelines.json")
+}
+
+// NewRunStore creates a store for the file at path. Runs are not read
+// until Load is called.
+func NewRunStore(path string) *RunStore {
+	return &RunStore{path: path, runs: make(map[string]*Run)}
+}
+
+// Load (re)reads the runs. A missing file is an empty store.
+func (s *RunStore) Load() error {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	var list []*Run
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("%s: %w", s.path, err)
+	}
+
+	runs := make(map[string]*Run, len(list))
+	for _, run := range list {
+		if run.ID != "" {
+			runs[run.ID] = run
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.runs = runs
+	return nil
+}
+
+// Add stores a new run. A run whose ID is taken, such as a pipeline
+// submitted twice in a second, gets a numbered ID.
+func (s *RunStore) Add(run *Run) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	base := run.ID
+	for n := 2; s.runs[run.ID] != nil; n++ {
+		run.ID = fmt.Sprintf("%s-%d", base, n)
+	}
+	s.runs[run.ID] = run.Clone()
+	if err := s.write(); err != nil {
+		delete(s.runs, run.ID)
+		return err
+	}
+	return nil
+}
+
+// Save replaces a stored run with run
+func (s *RunStore) Save(run *Run) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, ok := s.runs[run.ID]
+	if !ok {
+		return fmt.Errorf("pipeline run %s not found", run.ID)
+	}
+	s.runs[run.ID] = run.Clone()
+	if err := s.write(); err != nil {
+		s.runs[run.ID] = previous
+		return err
+	}
+	return nil
+}
+
+// Get returns a copy of the run with the given ID
+func (s *RunStore) Get(id string) (*Run, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	run, ok := s.runs[id]
+	if !ok {
+		return nil, false
+	}
+	return run.Clone(), true
+}
+
+// List returns every run, newest first
+func (s *RunStore) List() []*Run {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	runs := make([]*Run, 0, len(s.runs))
+	for _, run := range s.runs {
+		runs = append(runs, run.Clone())
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		if !runs[i].Created.Equal(runs[j].Created) {
+			return runs[i].Created.After(runs[j].Created)
+		}
+		return runs[i].ID < runs[j].ID
+	})
+	return runs
+}
+
+// IDs returns the IDs of every run, sorted
+func (s *RunStore) IDs() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ids := make([]string, 0, len(s.runs))
+	for id := range s.runs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Delete forgets the run with the given ID; its jobs are left alone
+func (s *RunStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	run, ok := s.runs[id]
+	if !ok {
+		return fmt.Errorf("pipeline run %s not found", id)
+	}
+	delete(s.runs, id)
+	if err := s.write(); err != nil {
+		s.runs[id] = run
+		return err
+	}
+	return nil
+}
+
+// write writes the runs to disk; callers hold s.mu
+func (s *RunStore) write() error {
+	list := make([]*Run, 0, len(s.runs))
+	for _, run := range s.runs {
+		list = append(list, run)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), fileperms.ConfigDir); err != nil {
+		return fmt.Errorf("failed to create pipelines directory: %w", err)
+	}
+	return os.WriteFile(s.path, data, fileperms.ConfigFile)
+}
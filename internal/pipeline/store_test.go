@@ -0,0 +1,54 @@
+package pipeline
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunStore(t *testing.T) {
+	spec, err := Parse([]byte(trainSpec))
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "s9s", "pipelines.json")
+	store := NewRunStore(path)
+	require.NoError(t, store.Load(), "a missing file is an empty store")
+	assert.Empty(t, store.List())
+
+	created := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	first := NewRun(spec, created)
+	first.Jobs["prep"] = "101"
+	require.NoError(t, store.Add(first))
+	second := NewRun(spec, created)
+	require.NoError(t, store.Add(second))
+	assert.Equal(t, "train-20261001-120000-2", second.ID, "a taken ID is numbered")
+
+	later := NewRun(spec, created.Add(time.Hour))
+	require.NoError(t, store.Add(later))
+
+	// Read back from disk
+	store = NewRunStore(path)
+	require.NoError(t, store.Load())
+	runs := store.List()
+	require.Len(t, runs, 3)
+	assert.Equal(t, later.ID, runs[0].ID, "newest first")
+	assert.Equal(t, []string{"train-20261001-120000", "train-20261001-120000-2", "train-20261001-130000"}, store.IDs())
+
+	run, ok := store.Get(first.ID)
+	require.True(t, ok)
+	assert.Equal(t, "101", run.Jobs["prep"])
+	assert.Len(t, run.Spec.Stages, 4)
+
+	run.Jobs["prep"] = "201"
+	stored, _ := store.Get(first.ID)
+	assert.Equal(t, "101", stored.Jobs["prep"], "Get returns a copy")
+	require.NoError(t, store.Save(run))
+	stored, _ = store.Get(first.ID)
+	assert.Equal(t, "201", stored.Jobs["prep"])
+
+	require.NoError(t, store.Delete(first.ID))
+	assert.EqualError(t, store.Delete(first.ID), "pipeline run train-20261001-120000 not found")
+	assert.EqualError(t, store.Save(first), "pipeline run train-20261001-120000 not found")
+}
@@ -0,0 +1,285 @@
+package safety
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jontk/s9s/internal/dao"
+)
+
+// guardedClient wraps a SlurmClient and checks every job, node and
+// partition mutation against a policy before it is sent
+type guardedClient struct {
+	dao.SlurmClient
+	policy *Policy
+}
+
+// NewClient returns a client that enforces policy on the mutations made
+// through client. With a nil policy client is returned unchanged.
+func NewClient(client dao.SlurmClient, policy *Policy) dao.SlurmClient {
+	if policy == nil {
+		return client
+	}
+	return &guardedClient{SlurmClient: client, policy: policy}
+}
+
+// Unwrap returns the wrapped client
+func (c *guardedClient) Unwrap() dao.SlurmClient {
+	return c.SlurmClient
+}
+
+// Policy returns the enforced policy
+func (c *guardedClient) Policy() *Policy {
+	return c.policy
+}
+
+func (c *guardedClient) Jobs() dao.JobManager {
+	return &guardedJobManager{JobManager: c.SlurmClient.Jobs(), policy: c.policy}
+}
+
+func (c *guardedClient) Nodes() dao.NodeManager {
+	return &guardedNodeManager{NodeManager: c.SlurmClient.Nodes(), client: c.SlurmClient, policy: c.policy}
+}
+
+func (c *guardedClient) Partitions() dao.PartitionManager {
+	return &guardedPartitionManager{PartitionManager: c.SlurmClient.Partitions(), policy: c.policy}
+}
+
+// PolicyOf returns the policy enforced on client, or nil if none is
+func PolicyOf(client dao.SlurmClient) *Policy {
+	if guarded := guardedOf(client); guarded != nil {
+		return guarded.policy
+	}
+	return nil
+}
+
+// CheckDrain reports whether draining all of nodes at once is allowed by
+// the policy enforced on client, so that a batch can be refused before
+// any node is drained. Each drain is still checked when it is sent.
+func CheckDrain(client dao.SlurmClient, nodes []string) error {
+	guarded := guardedOf(client)
+	if guarded == nil {
+		return nil
+	}
+	m := &guardedNodeManager{NodeManager: guarded.SlurmClient.Nodes(), client: guarded.SlurmClient, policy: guarded.policy}
+	return m.checkDrain(nodes)
+}
+
+// guardedOf returns the guarded client in the wrapper chain of client
+func guardedOf(client dao.SlurmClient) *guardedClient {
+	for client != nil {
+		if guarded, ok := client.(*guardedClient); ok {
+			return guarded
+		}
+		wrapper, ok := client.(interface{ Unwrap() dao.SlurmClient })
+		if !ok {
+			return nil
+		}
+		client = wrapper.Unwrap()
+	}
+	return nil
+}
+
+// guardedJobManager checks job mutations
+type guardedJobManager struct {
+	dao.JobManager
+	policy *Policy
+}
+
+func (m *guardedJobManager) Submit(job *dao.JobSubmission) (string, error) {
+	if err := m.policy.checkWrite(dao.ActionJobSubmit, ""); err != nil {
+		return "", err
+	}
+	return m.JobManager.Submit(job)
+}
+
+func (m *guardedJobManager) Cancel(id string) error {
+	if err := m.check(dao.ActionJobCancel, id); err != nil {
+		return err
+	}
+	return m.JobManager.Cancel(id)
+}
+
+func (m *guardedJobManager) Hold(id string) error {
+	if err := m.check(dao.ActionJobHold, id); err != nil {
+		return err
+	}
+	return m.JobManager.Hold(id)
+}
+
+func (m *guardedJobManager) Release(id string) error {
+	if err := m.check(dao.ActionJobRelease, id); err != nil {
+		return err
+	}
+	return m.JobManager.Release(id)
+}
+
+func (m *guardedJobManager) Requeue(id string) (*dao.Job, error) {
+	if err := m.check(dao.ActionJobRequeue, id); err != nil {
+		return nil, err
+	}
+	return m.JobManager.Requeue(id)
+}
+
+func (m *guardedJobManager) Notify(id string, message string) error {
+	if err := m.policy.checkWrite(dao.ActionJobNotify, id); err != nil {
+		return err
+	}
+	return m.JobManager.Notify(id, message)
+}
+
+// check looks up the job if the policy needs its owner, name or partition
+// and checks action on it
+func (m *guardedJobManager) check(action, id string) error {
+	t := &target{kind: "job", name: id}
+	if m.policy.needsDetails(action, "job") {
+		job, err := m.lookup(id)
+		if err != nil {
+			return &RefusedError{Action: action, Target: id, Reason: fmt.Sprintf("cannot look up job: %v", err)}
+		}
+		t.jobName = job.Name
+		t.user = job.User
+		t.partitions = strings.Split(job.Partition, ",")
+	}
+	return m.policy.check(action, t)
+}
+
+// lookup returns the job with id, falling back to the array job for task
+// ranges such as 1234_[1-10] or 1234_*
+func (m *guardedJobManager) lookup(id string) (*dao.Job, error) {
+	job, err := m.JobManager.Get(id)
+	if err == nil && job != nil {
+		return job, nil
+	}
+	if base, _, ok := strings.Cut(id, "_"); ok {
+		if job, baseErr := m.JobManager.Get(base); baseErr == nil && job != nil {
+			return job, nil
+		}
+	}
+	if err == nil {
+		err = fmt.Errorf("job %s not found", id)
+	}
+	return nil, err
+}
+
+// guardedNodeManager checks node mutations and counts drains towards the
+// drain limits
+type guardedNodeManager struct {
+	dao.NodeManager
+	client dao.SlurmClient
+	policy *Policy
+}
+
+func (m *guardedNodeManager) Drain(name string, reason string) error {
+	partitions, err := m.checkNode(dao.ActionNodeDrain, name, "")
+	if err != nil {
+		return err
+	}
+	if err := m.NodeManager.Drain(name, reason); err != nil {
+		return err
+	}
+	m.policy.recordDrain(name, partitions)
+	return nil
+}
+
+func (m *guardedNodeManager) Resume(name string) error {
+	if _, err := m.checkNode(dao.ActionNodeResume, name, ""); err != nil {
+		return err
+	}
+	return m.NodeManager.Resume(name)
+}
+
+func (m *guardedNodeManager) SetState(name string, state string) error {
+	partitions, err := m.checkNode(dao.ActionNodeSetState, name, state)
+	if err != nil {
+		return err
+	}
+	if err := m.NodeManager.SetState(name, state); err != nil {
+		return err
+	}
+	if isDrain(dao.ActionNodeSetState, state) {
+		m.policy.recordDrain(name, partitions)
+	}
+	return nil
+}
+
+// checkNode checks action on a node, including the drain limits when it
+// drains the node, and returns the node's partitions if they were needed
+func (m *guardedNodeManager) checkNode(action, name, state string) ([]string, error) {
+	if err := m.policy.checkWrite(action, name); err != nil {
+		return nil, err
+	}
+	t := &target{kind: "node", name: name}
+	drain := isDrain(action, state)
+	if m.policy.needsDetails(action, "node") || (drain && m.policy.maxDrainPercent > 0) {
+		node, err := m.NodeManager.Get(name)
+		if err != nil {
+			return nil, &RefusedError{Action: action, Target: name, Reason: fmt.Sprintf("cannot look up node: %v", err)}
+		}
+		t.partitions = node.Partitions
+	}
+	if err := m.policy.check(action, t); err != nil {
+		return nil, err
+	}
+	if drain {
+		err := m.policy.checkDrainLimits([]string{name}, map[string][]string{name: t.partitions}, m.partitionSize)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return t.partitions, nil
+}
+
+// checkDrain checks the drain limits for draining all of nodes at once
+func (m *guardedNodeManager) checkDrain(nodes []string) error {
+	if err := m.policy.checkWrite(dao.ActionNodeDrain, describeTargets(nodes)); err != nil {
+		return err
+	}
+	partitionsOf := make(map[string][]string, len(nodes))
+	if m.policy.maxDrainPercent > 0 {
+		for _, name := range nodes {
+			node, err := m.NodeManager.Get(name)
+			if err != nil {
+				return &RefusedError{Action: dao.ActionNodeDrain, Target: name, Reason: fmt.Sprintf("cannot look up node: %v", err)}
+			}
+			partitionsOf[name] = node.Partitions
+		}
+	}
+	return m.policy.checkDrainLimits(nodes, partitionsOf, m.partitionSize)
+}
+
+// partitionSize returns the number of nodes in a partition
+func (m *guardedNodeManager) partitionSize(name string) (int, error) {
+	partition, err := m.client.Partitions().Get(name)
+	if err != nil {
+		return 0, err
+	}
+	if partition.TotalNodes > 0 {
+		return partition.TotalNodes, nil
+	}
+	return len(partition.Nodes), nil
+}
+
+// guardedPartitionManager checks partition mutations
+type guardedPartitionManager struct {
+	dao.PartitionManager
+	policy *Policy
+}
+
+func (m *guardedPartitionManager) SetState(name string, state string) error {
+	if err := m.policy.check(dao.ActionPartitionSetState, partitionTarget(name)); err != nil {
+		return err
+	}
+	return m.PartitionManager.SetState(name, state)
+}
+
+func (m *guardedPartitionManager) SetMaxTime(name string, limit string) error {
+	if err := m.policy.check(dao.ActionPartitionSetMaxTime, partitionTarget(name)); err != nil {
+		return err
+	}
+	return m.PartitionManager.SetMaxTime(name, limit)
+}
+
+func partitionTarget(name string) *target {
+	return &target{kind: "partition", name: name, partitions: []string{name}}
+}
@@ -0,0 +1,480 @@
+// Package safety enforces the configured policies for destructive actions:
+// read-only cluster contexts, protected jobs, nodes and partitions that
+// need a typed confirmation or are off limits, drain limits and the
+// admin mode required to act on other users' jobs. The policy is applied
+// by a client decorator so the TUI, batch operations and headless commands
+// all obey it.
+package safety
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jontk/s9s/internal/config"
+	"github.com/jontk/s9s/internal/dao"
+	"github.com/jontk/s9s/internal/topology"
+)
+
+// Rule modes
+const (
+	ModeConfirm = "confirm"
+	ModeDeny    = "deny"
+)
+
+// DefaultDrainWindow is how long drains count towards the drain limits
+// when safety.drainWindow is not set
+const DefaultDrainWindow = 10 * time.Minute
+
+// destructiveActions are the actions protect rules, drain limits and the
+// owner check apply to. Every mutation is refused on a read-only cluster.
+var destructiveActions = []string{
+	dao.ActionJobCancel,
+	dao.ActionJobHold,
+	dao.ActionJobRelease,
+	dao.ActionJobRequeue,
+	dao.ActionNodeDrain,
+	dao.ActionNodeResume,
+	dao.ActionNodeSetState,
+	dao.ActionPartitionSetState,
+	dao.ActionPartitionSetMaxTime,
+}
+
+// IsDestructive reports whether action is subject to the safety policy
+// beyond the read-only check
+func IsDestructive(action string) bool {
+	for _, a := range destructiveActions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// Confirmer asks the user to type the name of a protected target before a
+// destructive action runs on it
+type Confirmer interface {
+	// ConfirmTyped reports whether the user typed expected to allow
+	// action on target. It may block until the user answers.
+	ConfirmTyped(action, target, expected string) bool
+}
+
+// RefusedError is returned for actions the policy does not allow
+type RefusedError struct {
+	Action string
+	Target string
+	Reason string
+}
+
+func (e *RefusedError) Error() string {
+	if e.Target == "" {
+		return fmt.Sprintf("refused by safety policy: %s: %s", e.Action, e.Reason)
+	}
+	return fmt.Sprintf("refused by safety policy: %s %s: %s", e.Action, e.Target, e.Reason)
+}
+
+// IsRefused reports whether err is a safety policy refusal
+func IsRefused(err error) bool {
+	var refused *RefusedError
+	return errors.As(err, &refused)
+}
+
+// Policy decides whether an action may run
+type Policy struct {
+	cluster         string
+	readOnly        bool
+	slurmUser       string
+	allowOthers     bool
+	maxDrainNodes   int
+	maxDrainPercent float64
+	drainWindow     time.Duration
+	rules           []rule
+
+	adminMode atomic.Bool
+
+	mu        sync.Mutex
+	confirmer Confirmer
+	drains    []drainRecord
+	now       func() time.Time
+}
+
+// drainRecord is a node drained through s9s, counted towards the limits
+// until it leaves the drain window
+type drainRecord struct {
+	node       string
+	partitions []string
+	at         time.Time
+}
+
+// NewPolicy builds the policy of the current cluster of cfg
+func NewPolicy(cfg *config.Config) (*Policy, error) {
+	safety := cfg.Safety
+	p := &Policy{
+		cluster:         cfg.DefaultCluster,
+		slurmUser:       cfg.ResolveSlurmUser(),
+		allowOthers:     safety.AllowOtherUsersJobs,
+		maxDrainNodes:   safety.MaxDrainNodes,
+		maxDrainPercent: safety.MaxDrainPercent,
+		drainWindow:     DefaultDrainWindow,
+		now:             time.Now,
+	}
+	for _, cl := range cfg.Clusters {
+		if cl.Name == cfg.DefaultCluster {
+			p.readOnly = cl.ReadOnly
+		}
+	}
+	p.adminMode.Store(safety.AdminMode)
+
+	if safety.DrainWindow != "" {
+		window, err := time.ParseDuration(safety.DrainWindow)
+		if err != nil {
+			return nil, fmt.Errorf("invalid safety.drainWindow %q: %w", safety.DrainWindow, err)
+		}
+		p.drainWindow = window
+	}
+	for i := range safety.Protect {
+		r, err := compileRule(i, &safety.Protect[i])
+		if err != nil {
+			return nil, err
+		}
+		p.rules = append(p.rules, r)
+	}
+	return p, nil
+}
+
+// ReadOnly reports whether the cluster refuses every mutation
+func (p *Policy) ReadOnly() bool {
+	return p.readOnly
+}
+
+// AdminMode reports whether actions on other users' jobs are allowed
+func (p *Policy) AdminMode() bool {
+	return p.adminMode.Load()
+}
+
+// SetAdminMode turns admin mode on or off
+func (p *Policy) SetAdminMode(on bool) {
+	p.adminMode.Store(on)
+}
+
+// SetConfirmer sets who is asked for typed confirmations. Without one,
+// actions on targets protected in confirm mode are refused.
+func (p *Policy) SetConfirmer(confirmer Confirmer) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.confirmer = confirmer
+}
+
+// target is the subject of an action with the details rules match on
+type target struct {
+	kind       string // job, node or partition
+	name       string // Job ID, node or partition name
+	jobName    string
+	user       string
+	partitions []string
+}
+
+// checkWrite refuses every mutation on a read-only cluster
+func (p *Policy) checkWrite(action, name string) error {
+	if p.readOnly {
+		return &RefusedError{Action: action, Target: name, Reason: fmt.Sprintf("cluster %s is read-only", p.clusterName())}
+	}
+	return nil
+}
+
+func (p *Policy) clusterName() string {
+	if p.cluster == "" {
+		return "default"
+	}
+	return p.cluster
+}
+
+// check decides whether action may run on t, asking for a typed
+// confirmation if a confirm rule protects t
+func (p *Policy) check(action string, t *target) error {
+	if err := p.checkWrite(action, t.name); err != nil {
+		return err
+	}
+	if !IsDestructive(action) {
+		return nil
+	}
+
+	if p.ownerCheckApplies(action) && t.user != "" && p.slurmUser != "" && t.user != p.slurmUser {
+		return &RefusedError{Action: action, Target: t.name,
+			Reason: fmt.Sprintf("job belongs to %s; enable admin mode to act on other users' jobs", t.user)}
+	}
+
+	var confirmBy []string
+	for i := range p.rules {
+		r := &p.rules[i]
+		if !r.matches(action, t) {
+			continue
+		}
+		if r.mode == ModeDeny {
+			return &RefusedError{Action: action, Target: t.name, Reason: fmt.Sprintf("protected by rule %s", r.name)}
+		}
+		confirmBy = append(confirmBy, r.name)
+	}
+	if len(confirmBy) == 0 {
+		return nil
+	}
+
+	p.mu.Lock()
+	confirmer := p.confirmer
+	p.mu.Unlock()
+	if confirmer == nil {
+		return &RefusedError{Action: action, Target: t.name,
+			Reason: fmt.Sprintf("protected by rule %s, which needs a typed confirmation", strings.Join(confirmBy, ", "))}
+	}
+	if !confirmer.ConfirmTyped(action, t.name, t.name) {
+		return &RefusedError{Action: action, Target: t.name, Reason: "confirmation not given"}
+	}
+	return nil
+}
+
+// ownerCheckApplies reports whether action is refused on other users' jobs
+func (p *Policy) ownerCheckApplies(action string) bool {
+	if p.allowOthers || p.AdminMode() {
+		return false
+	}
+	return action == dao.ActionJobCancel || action == dao.ActionJobRequeue
+}
+
+// needsDetails reports whether checking action on a target of kind needs
+// its owner, name or partitions
+func (p *Policy) needsDetails(action, kind string) bool {
+	if p.readOnly || !IsDestructive(action) {
+		return false
+	}
+	if kind == "job" && p.ownerCheckApplies(action) && p.slurmUser != "" {
+		return true
+	}
+	for i := range p.rules {
+		if p.rules[i].needsDetails(action, kind) {
+			return true
+		}
+	}
+	return false
+}
+
+// isDrain reports whether action with state drains a node
+func isDrain(action, state string) bool {
+	return action == dao.ActionNodeDrain ||
+		(action == dao.ActionNodeSetState && strings.EqualFold(state, "DRAIN"))
+}
+
+// checkDrainLimits refuses draining nodes if, together with the nodes
+// drained within the drain window, more than the allowed number of nodes
+// or share of a partition would be drained. partitionsOf returns the
+// partitions of a node and partitionSize the node count of a partition.
+func (p *Policy) checkDrainLimits(nodes []string, partitionsOf map[string][]string, partitionSize func(string) (int, error)) error {
+	if p.maxDrainNodes <= 0 && p.maxDrainPercent <= 0 {
+		return nil
+	}
+
+	p.mu.Lock()
+	drained := make(map[string][]string)
+	cutoff := p.now().Add(-p.drainWindow)
+	kept := p.drains[:0]
+	for _, d := range p.drains {
+		if d.at.After(cutoff) {
+			kept = append(kept, d)
+			drained[d.node] = d.partitions
+		}
+	}
+	p.drains = kept
+	p.mu.Unlock()
+
+	for _, node := range nodes {
+		drained[node] = partitionsOf[node]
+	}
+	target := describeTargets(nodes)
+
+	if p.maxDrainNodes > 0 && len(drained) > p.maxDrainNodes {
+		return &RefusedError{Action: dao.ActionNodeDrain, Target: target,
+			Reason: fmt.Sprintf("%d nodes would be drained within %s, more than the limit of %d",
+				len(drained), p.drainWindow, p.maxDrainNodes)}
+	}
+
+	if p.maxDrainPercent > 0 {
+		perPartition := make(map[string]int)
+		for _, partitions := range drained {
+			for _, partition := range partitions {
+				perPartition[partition]++
+			}
+		}
+		names := make([]string, 0, len(perPartition))
+		for _, node := range nodes {
+			for _, partition := range partitionsOf[node] {
+				names = append(names, partition)
+			}
+		}
+		sort.Strings(names)
+		for i, partition := range names {
+			if i > 0 && names[i-1] == partition {
+				continue
+			}
+			size, err := partitionSize(partition)
+			if err != nil {
+				return &RefusedError{Action: dao.ActionNodeDrain, Target: target,
+					Reason: fmt.Sprintf("cannot look up partition %s: %v", partition, err)}
+			}
+			if size <= 0 {
+				continue
+			}
+			percent := float64(perPartition[partition]) * 100 / float64(size)
+			if percent > p.maxDrainPercent {
+				return &RefusedError{Action: dao.ActionNodeDrain, Target: target,
+					Reason: fmt.Sprintf("%.0f%% of partition %s would be drained within %s, more than the limit of %.0f%%",
+						percent, partition, p.drainWindow, p.maxDrainPercent)}
+			}
+		}
+	}
+	return nil
+}
+
+// recordDrain counts a drained node towards the drain limits
+func (p *Policy) recordDrain(node string, partitions []string) {
+	if p.maxDrainNodes <= 0 && p.maxDrainPercent <= 0 {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.drains = append(p.drains, drainRecord{node: node, partitions: partitions, at: p.now()})
+}
+
+// describeTargets names one target or counts several
+func describeTargets(names []string) string {
+	if len(names) == 1 {
+		return names[0]
+	}
+	return fmt.Sprintf("%d nodes", len(names))
+}
+
+// rule is a compiled protect rule
+type rule struct {
+	name       string
+	actions    []string
+	nodes      map[string]bool
+	partitions map[string]bool
+	jobs       []string
+	users      map[string]bool
+	mode       string
+}
+
+func compileRule(index int, cfg *config.ProtectRuleConfig) (rule, error) {
+	r := rule{name: cfg.Name, actions: cfg.Actions, jobs: cfg.Jobs, mode: strings.ToLower(cfg.Mode)}
+	if r.name == "" {
+		r.name = fmt.Sprintf("#%d", index+1)
+	}
+	if r.mode == "" {
+		r.mode = ModeConfirm
+	}
+	if r.mode != ModeConfirm && r.mode != ModeDeny {
+		return r, fmt.Errorf("safety rule %s: unknown mode %q (use confirm or deny)", r.name, cfg.Mode)
+	}
+	for _, pattern := range append(append([]string{}, cfg.Actions...), cfg.Jobs...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return r, fmt.Errorf("safety rule %s: invalid pattern %q", r.name, pattern)
+		}
+	}
+
+	if cfg.Nodes != "" {
+		nodes, err := topology.ExpandHostlist(cfg.Nodes)
+		if err != nil {
+			return r, fmt.Errorf("safety rule %s: invalid nodes %q: %w", r.name, cfg.Nodes, err)
+		}
+		r.nodes = toSet(nodes)
+	}
+	if len(cfg.Partitions) > 0 {
+		r.partitions = toSet(cfg.Partitions)
+	}
+	if len(cfg.Users) > 0 {
+		r.users = toSet(cfg.Users)
+	}
+	return r, nil
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
+
+// appliesTo reports whether the rule covers action
+func (r *rule) appliesTo(action string) bool {
+	if len(r.actions) == 0 {
+		return IsDestructive(action)
+	}
+	for _, pattern := range r.actions {
+		if ok, _ := path.Match(pattern, action); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// needsDetails reports whether matching action on a target of kind needs
+// more than the target's name
+func (r *rule) needsDetails(action, kind string) bool {
+	if !r.appliesTo(action) {
+		return false
+	}
+	switch kind {
+	case "job":
+		return r.partitions != nil || r.users != nil || len(r.jobs) > 0
+	case "node":
+		return r.partitions != nil
+	}
+	return false
+}
+
+// matches reports whether the rule protects t from action. Every
+// criterion that is set must match; criteria about another kind of target
+// never match.
+func (r *rule) matches(action string, t *target) bool {
+	if !r.appliesTo(action) {
+		return false
+	}
+	if r.nodes != nil && (t.kind != "node" || !r.nodes[t.name]) {
+		return false
+	}
+	if len(r.jobs) > 0 && (t.kind != "job" || !matchesAny(r.jobs, t.name, t.jobName)) {
+		return false
+	}
+	if r.users != nil && (t.kind != "job" || !r.users[t.user]) {
+		return false
+	}
+	if r.partitions != nil {
+		found := false
+		for _, partition := range t.partitions {
+			found = found || r.partitions[partition]
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesAny reports whether any value matches any of the globs
+func matchesAny(globs []string, values ...string) bool {
+	for _, glob := range globs {
+		for _, value := range values {
+			if value == "" {
+				continue
+			}
+			if ok, _ := path.Match(glob, value); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
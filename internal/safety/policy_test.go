@@ -0,0 +1,172 @@
+package safety
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jontk/s9s/internal/config"
+	"github.com/jontk/s9s/internal/dao"
+	"github.com/jontk/s9s/pkg/slurm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeConfirmer answers typed confirmations and remembers what it was asked
+type fakeConfirmer struct {
+	answer bool
+	asked  []string
+}
+
+func (c *fakeConfirmer) ConfirmTyped(_, _, expected string) bool {
+	c.asked = append(c.asked, expected)
+	return c.answer
+}
+
+func newTestClient(t *testing.T, safety config.SafetyConfig) (dao.SlurmClient, *Policy, *slurm.MockClient) {
+	t.Helper()
+	t.Setenv("SLURM_USER_NAME", "alice")
+	mock := slurm.NewMockClient()
+	mock.SetDelay(0)
+	policy, err := NewPolicy(&config.Config{Safety: safety})
+	require.NoError(t, err)
+	return NewClient(mock, policy), policy, mock
+}
+
+// jobOwnedBy returns the ID of a running or pending job of user, or of
+// another user if other is set
+func jobOwnedBy(t *testing.T, mock *slurm.MockClient, user string, other bool) string {
+	t.Helper()
+	list, err := mock.Jobs().List(&dao.ListJobsOptions{States: []string{dao.JobStateRunning, dao.JobStatePending}})
+	require.NoError(t, err)
+	for _, job := range list.Jobs {
+		if (job.User == user) != other {
+			return job.ID
+		}
+	}
+	t.Skipf("mock has no matching job for %s", user)
+	return ""
+}
+
+func TestReadOnlyRefusesEveryMutation(t *testing.T) {
+	mock := slurm.NewMockClient()
+	mock.SetDelay(0)
+	policy, err := NewPolicy(&config.Config{
+		DefaultCluster: "prod",
+		Clusters:       []config.ClusterContext{{Name: "prod", ReadOnly: true}},
+	})
+	require.NoError(t, err)
+	client := NewClient(mock, policy)
+
+	_, err = client.Jobs().Submit(&dao.JobSubmission{Name: "test", Script: "#!/bin/bash"})
+	assert.True(t, IsRefused(err))
+	assert.Contains(t, err.Error(), "cluster prod is read-only")
+	assert.True(t, IsRefused(client.Jobs().Hold("1001")))
+	assert.True(t, IsRefused(client.Nodes().Drain("node001", "test")))
+	assert.True(t, IsRefused(client.Partitions().SetState("debug", "DOWN")))
+
+	_, err = client.Jobs().List(nil)
+	assert.NoError(t, err, "reads are allowed")
+}
+
+func TestOtherUsersJobsNeedAdminMode(t *testing.T) {
+	client, policy, mock := newTestClient(t, config.SafetyConfig{})
+	own := jobOwnedBy(t, mock, "alice", false)
+	other := jobOwnedBy(t, mock, "alice", true)
+
+	err := client.Jobs().Cancel(other)
+	require.True(t, IsRefused(err), "cancelling another user's job is refused")
+	assert.Contains(t, err.Error(), "admin mode")
+	assert.NoError(t, client.Jobs().Cancel(own))
+
+	policy.SetAdminMode(true)
+	assert.NoError(t, client.Jobs().Cancel(other))
+}
+
+func TestAllowOtherUsersJobs(t *testing.T) {
+	client, _, mock := newTestClient(t, config.SafetyConfig{AllowOtherUsersJobs: true})
+	assert.NoError(t, client.Jobs().Cancel(jobOwnedBy(t, mock, "alice", true)))
+}
+
+func TestProtectRules(t *testing.T) {
+	client, policy, _ := newTestClient(t, config.SafetyConfig{
+		Protect: []config.ProtectRuleConfig{
+			{Name: "login", Nodes: "node[001-002]", Mode: "deny"},
+			{Name: "gpu", Actions: []string{"node.*"}, Partitions: []string{"gpu"}},
+			{Name: "debug", Actions: []string{"partition.set_state"}, Partitions: []string{"debug"}},
+		},
+	})
+
+	err := client.Nodes().Drain("node001", "maintenance")
+	require.True(t, IsRefused(err))
+	assert.Contains(t, err.Error(), "protected by rule login")
+	assert.NoError(t, client.Nodes().Drain("node003", "maintenance"))
+
+	err = client.Nodes().Drain("gpu001", "maintenance")
+	require.True(t, IsRefused(err), "confirm rules need a confirmer")
+	assert.Contains(t, err.Error(), "typed confirmation")
+
+	confirmer := &fakeConfirmer{answer: false}
+	policy.SetConfirmer(confirmer)
+	assert.True(t, IsRefused(client.Nodes().Drain("gpu001", "maintenance")))
+	confirmer.answer = true
+	assert.NoError(t, client.Nodes().Drain("gpu001", "maintenance"))
+	assert.Equal(t, []string{"gpu001", "gpu001"}, confirmer.asked)
+
+	assert.NoError(t, client.Partitions().SetMaxTime("debug", "60"), "rule covers set_state only")
+	require.NoError(t, client.Partitions().SetState("debug", "DOWN"))
+	assert.Len(t, confirmer.asked, 3)
+}
+
+func TestDrainNodeLimit(t *testing.T) {
+	client, policy, _ := newTestClient(t, config.SafetyConfig{MaxDrainNodes: 2, DrainWindow: "10m"})
+	now := time.Now()
+	policy.now = func() time.Time { return now }
+
+	assert.True(t, IsRefused(CheckDrain(client, []string{"node001", "node002", "node003"})))
+	assert.NoError(t, CheckDrain(client, []string{"node001", "node002"}))
+
+	require.NoError(t, client.Nodes().Drain("node001", "test"))
+	require.NoError(t, client.Nodes().Drain("node002", "test"))
+	assert.NoError(t, client.Nodes().Drain("node002", "again"), "redraining counts once")
+	err := client.Nodes().Drain("node003", "test")
+	require.True(t, IsRefused(err))
+	assert.Contains(t, err.Error(), "limit of 2")
+
+	now = now.Add(11 * time.Minute)
+	assert.NoError(t, client.Nodes().Drain("node003", "test"), "old drains leave the window")
+}
+
+func TestDrainPercentLimit(t *testing.T) {
+	// The mock gpu partition has 20 nodes
+	client, _, _ := newTestClient(t, config.SafetyConfig{MaxDrainPercent: 10})
+
+	require.NoError(t, client.Nodes().Drain("gpu001", "test"))
+	require.NoError(t, client.Nodes().Drain("gpu002", "test"))
+	err := client.Nodes().Drain("gpu003", "test")
+	require.True(t, IsRefused(err))
+	assert.Contains(t, err.Error(), "partition gpu")
+	assert.NoError(t, client.Nodes().Drain("node001", "test"), "other partitions have their own share")
+	assert.True(t, IsRefused(client.Nodes().SetState("gpu003", "DRAIN")), "DRAIN state changes count as drains")
+}
+
+func TestNewPolicyRejectsInvalidRules(t *testing.T) {
+	_, err := NewPolicy(&config.Config{Safety: config.SafetyConfig{
+		Protect: []config.ProtectRuleConfig{{Name: "bad", Mode: "maybe"}},
+	}})
+	assert.Error(t, err)
+
+	_, err = NewPolicy(&config.Config{Safety: config.SafetyConfig{
+		Protect: []config.ProtectRuleConfig{{Nodes: "node[001-"}},
+	}})
+	assert.Error(t, err)
+
+	_, err = NewPolicy(&config.Config{Safety: config.SafetyConfig{DrainWindow: "soon"}})
+	assert.Error(t, err)
+}
+
+func TestPolicyOf(t *testing.T) {
+	client, policy, mock := newTestClient(t, config.SafetyConfig{})
+	assert.Same(t, policy, PolicyOf(client))
+	assert.Nil(t, PolicyOf(mock))
+	assert.NoError(t, CheckDrain(mock, []string{"node001"}), "unguarded clients are not checked")
+}
@@ -380,7 +380,7 @@ func (w *Wizard) testConnection() {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	adapter, err := dao.NewSlurmAdapter(ctx, &clusterCfg)
+	adapter, err := dao.NewSlurmAdapter(ctx, w.config.Clusters[0].Name, &clusterCfg)
 	if err != nil {
 		fmt.Printf("   ❌ Connection failed: %v\n", err)
 		fmt.Println()
@@ -450,6 +450,7 @@ func (w *Wizard) saveConfiguration() error {
 func (w *Wizard) renderConfig() string {
 	var b strings.Builder
 
+	b.WriteString(config.SchemaHeader)
 	b.WriteString("# s9s configuration — generated by s9s setup\n\n")
 
 	if w.config.DefaultCluster != "" {
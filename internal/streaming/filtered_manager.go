@@ -9,8 +9,12 @@ import (
 
 	"github.com/jontk/s9s/internal/dao"
 	"github.com/jontk/s9s/internal/ssh"
+	"github.com/rivo/tview"
 )
 
+// searchHighlightID keys search matches among the filter highlights of a line
+const searchHighlightID = "search"
+
 // FilteredStreamManager extends StreamManager with filtering capabilities
 type FilteredStreamManager struct {
 	*StreamManager
@@ -141,7 +145,10 @@ func (fsm *FilteredStreamManager) GetSearchHistory() []string {
 	return fsm.searchHistory.Get()
 }
 
-// GetFilteredContent returns the filtered content for a stream
+// GetFilteredContent returns the filtered lines of a stream. With
+// includeHighlights they are rendered as tview markup for display, keeping
+// the colors of the output on lines without filter or search matches;
+// otherwise they are the plain text kept in the buffer.
 func (fsm *FilteredStreamManager) GetFilteredContent(jobID, outputType string, includeHighlights bool) ([]string, error) {
 	fsm.mu.RLock()
 	streamKey := fsm.makeStreamKey(jobID, outputType)
@@ -154,11 +161,11 @@ func (fsm *FilteredStreamManager) GetFilteredContent(jobID, outputType string, i
 	}
 
 	// Get all lines from buffer
-	allLines := stream.Buffer.GetLines()
+	allLines, markup := stream.Lines()
 	filteredLines := make([]string, 0)
 
 	// Process each line through filters
-	for _, line := range allLines {
+	for i, line := range allLines {
 		timestamp := time.Now() // Would be better to store actual timestamps
 
 		// Apply filters
@@ -167,18 +174,26 @@ func (fsm *FilteredStreamManager) GetFilteredContent(jobID, outputType string, i
 			continue
 		}
 
-		// Apply highlighting if requested
-		if includeHighlights && len(highlights) > 0 {
+		// Apply filter and search highlighting if requested
+		if includeHighlights {
 			colors := make(map[string]string)
 			for id := range highlights {
 				colors[id] = "yellow" // Default color
 			}
-			line = HighlightLine(line, highlights, colors)
-		}
-
-		// Apply search highlighting if active
-		if includeHighlights && searcher != nil {
-			line = searcher.GetHighlightedLine(line, "cyan")
+			if searcher != nil {
+				if indices := searcher.MatchIndices(line); len(indices) > 0 {
+					if highlights == nil {
+						highlights = make(map[string][]int)
+					}
+					highlights[searchHighlightID] = indices
+					colors[searchHighlightID] = "cyan"
+				}
+			}
+			if len(highlights) > 0 {
+				line = HighlightLine(line, highlights, colors)
+			} else {
+				line = markup[i]
+			}
 		}
 
 		filteredLines = append(filteredLines, line)
@@ -194,35 +209,49 @@ func (fsm *FilteredStreamManager) GetFilterStats() map[string]FilterStats {
 
 // EmitFilteredEvent emits an event after applying filters
 func (fsm *FilteredStreamManager) EmitFilteredEvent(event *StreamEvent) {
-	// Extract lines from content
-	lines := strings.Split(event.Content, "\n")
+	// Only emit if there's content after filtering
+	if fsm.filterOutputEvent(event) || event.EventType != StreamEventNewOutput {
+		fsm.eventBus.Publish(event)
+	}
+}
+
+// EventLines returns the new lines of an output event as tview markup,
+// index for index with its NewLines
+func EventLines(event *StreamEvent) []string {
+	rendered := strings.Split(strings.TrimSuffix(event.Content, "\n"), "\n")
+	if len(rendered) == len(event.NewLines) {
+		return rendered
+	}
+	escaped := make([]string, len(event.NewLines))
+	for i, line := range event.NewLines {
+		escaped[i] = tview.Escape(line)
+	}
+	return escaped
+}
+
+// filterOutputEvent keeps the new lines of event that pass the active
+// filters and reports whether any did. Filters match the plain new lines;
+// the rendered content keeps the markup of the same lines.
+func (fsm *FilteredStreamManager) filterOutputEvent(event *StreamEvent) bool {
+	rendered := EventLines(event)
 	filteredLines := make([]string, 0)
 	filteredNewLines := make([]string, 0)
 
-	// Apply filters to each line
-	for _, line := range lines {
+	for i, line := range event.NewLines {
 		matched, _ := fsm.filterManager.ApplyActiveFilters(line, event.Timestamp)
-		if matched {
-			filteredLines = append(filteredLines, line)
+		if !matched {
+			continue
 		}
+		filteredNewLines = append(filteredNewLines, line)
+		filteredLines = append(filteredLines, rendered[i])
 	}
 
-	// Apply filters to new lines
-	for _, line := range event.NewLines {
-		matched, _ := fsm.filterManager.ApplyActiveFilters(line, event.Timestamp)
-		if matched {
-			filteredNewLines = append(filteredNewLines, line)
-		}
+	event.Content = ""
+	if len(filteredLines) > 0 {
+		event.Content = strings.Join(filteredLines, "\n") + "\n"
 	}
-
-	// Update event with filtered content
-	event.Content = strings.Join(filteredLines, "\n")
 	event.NewLines = filteredNewLines
-
-	// Only emit if there's content after filtering
-	if len(filteredLines) > 0 || event.EventType != StreamEventNewOutput {
-		fsm.eventBus.Publish(event)
-	}
+	return len(filteredNewLines) > 0
 }
 
 // StopFilteredStream stops a stream and cleans up resources
@@ -337,9 +366,7 @@ func (fsm *FilteredStreamManager) processStreamEvent(ctx context.Context, event
 
 // processOutputEvent filters output event lines and sends if matching lines exist
 func (fsm *FilteredStreamManager) processOutputEvent(ctx context.Context, event *StreamEvent, filteredChan chan *StreamEvent) {
-	filteredNewLines := fsm.filterEventLines(event.NewLines, event.Timestamp)
-	if len(filteredNewLines) > 0 {
-		event.NewLines = filteredNewLines
+	if fsm.filterOutputEvent(event) {
 		fsm.sendToFilteredChannel(ctx, event, filteredChan)
 	}
 }
@@ -349,18 +376,6 @@ func (fsm *FilteredStreamManager) processOtherEvent(ctx context.Context, event *
 	fsm.sendToFilteredChannel(ctx, event, filteredChan)
 }
 
-// filterEventLines applies active filters to event lines
-func (fsm *FilteredStreamManager) filterEventLines(lines []string, timestamp time.Time) []string {
-	filtered := make([]string, 0)
-	for _, line := range lines {
-		matched, _ := fsm.filterManager.ApplyActiveFilters(line, timestamp)
-		if matched {
-			filtered = append(filtered, line)
-		}
-	}
-	return filtered
-}
-
 // sendToFilteredChannel sends an event to the filtered channel, respecting context cancellation
 func (fsm *FilteredStreamManager) sendToFilteredChannel(ctx context.Context, event *StreamEvent, filteredChan chan *StreamEvent) {
 	select {
@@ -11,6 +11,7 @@ import (
 	"github.com/fsnotify/fsnotify"
 	"github.com/jontk/s9s/internal/dao"
 	"github.com/jontk/s9s/internal/ssh"
+	"github.com/rivo/tview"
 )
 
 // remotePollingInterval is the interval between SSH-based file content polls
@@ -31,16 +32,21 @@ func NewStreamManager(client dao.SlurmClient, sshManager *ssh.SessionManager, ss
 	ctx, cancel := context.WithCancel(context.Background())
 
 	sm := &StreamManager{
-		client:        client,
-		sshManager:    sshManager,
-		sshExecutor:   sshExecutor,
-		fileWatcher:   watcher,
-		activeStreams: make(map[string]*JobStream),
-		eventBus:      NewEventBus(),
-		slurmConfig:   config,
-		pathResolver:  NewPathResolver(client, config),
-		ctx:           ctx,
-		cancel:        cancel,
+		client:             client,
+		sshManager:         sshManager,
+		sshExecutor:        sshExecutor,
+		fileWatcher:        watcher,
+		activeStreams:      make(map[string]*JobStream),
+		eventBus:           NewEventBus(),
+		taskStreams:        make(map[string]*MultiTaskStream),
+		taskEventBus:       NewEventBus(),
+		maxConcurrentTails: DefaultMaxConcurrentTails,
+		progress:           NewProgressTracker(),
+		progressOffsets:    make(map[string]progressOffset),
+		slurmConfig:        config,
+		pathResolver:       NewPathResolver(client, config),
+		ctx:                ctx,
+		cancel:             cancel,
 	}
 
 	// Start the file watcher goroutine
@@ -86,6 +92,8 @@ func (sm *StreamManager) StartStream(jobID, outputType string) error {
 		JobID:       jobID,
 		OutputType:  outputType,
 		Buffer:      NewCircularBuffer(sm.slurmConfig.BufferSize),
+		Markup:      NewCircularBuffer(sm.slurmConfig.BufferSize),
+		Processor:   NewTerminalProcessor(),
 		FilePath:    filePath,
 		LastOffset:  initialOffset,
 		IsActive:    true,
@@ -186,6 +194,39 @@ func (sm *StreamManager) GetBuffer(jobID, outputType string) ([]string, error) {
 	return stream.Buffer.GetLines(), nil
 }
 
+// GetRenderedBuffer returns the buffered lines of a stream as tview markup,
+// keeping the colors of the output
+func (sm *StreamManager) GetRenderedBuffer(jobID, outputType string) ([]string, error) {
+	sm.mu.RLock()
+	stream, exists := sm.activeStreams[sm.makeStreamKey(jobID, outputType)]
+	sm.mu.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("no stream found for job %s %s", jobID, outputType)
+	}
+
+	_, markup := stream.Lines()
+	return markup, nil
+}
+
+// Lines returns the buffered lines of the stream as plain text and as
+// tview markup, index for index
+func (s *JobStream) Lines() (texts, markup []string) {
+	s.linesMu.RLock()
+	texts = s.Buffer.GetLines()
+	styled := s.Markup.GetLines()
+	s.linesMu.RUnlock()
+
+	markup = make([]string, len(texts))
+	for i, text := range texts {
+		if i < len(styled) && styled[i] != "" {
+			markup[i] = styled[i]
+		} else {
+			markup[i] = tview.Escape(text)
+		}
+	}
+	return texts, markup
+}
+
 // GetStats returns streaming statistics
 func (sm *StreamManager) GetStats() StreamingStats {
 	sm.mu.RLock()
@@ -198,7 +239,7 @@ func (sm *StreamManager) GetStats() StreamingStats {
 		if stream.IsActive {
 			activeCount++
 		}
-		memoryUsage += stream.Buffer.EstimateMemoryUsage()
+		memoryUsage += stream.Buffer.EstimateMemoryUsage() + stream.Markup.EstimateMemoryUsage()
 	}
 
 	return StreamingStats{
@@ -220,14 +261,23 @@ func (sm *StreamManager) Close() error {
 	for _, stream := range sm.activeStreams {
 		stream.IsActive = false
 	}
+	for _, stream := range sm.taskStreams {
+		stream.mu.Lock()
+		if stream.IsActive {
+			stream.IsActive = false
+			close(stream.stop)
+		}
+		stream.mu.Unlock()
+	}
 
 	// Close file watcher
 	if sm.fileWatcher != nil {
 		_ = sm.fileWatcher.Close()
 	}
 
-	// Clear event bus
+	// Clear event buses
 	sm.eventBus.Clear()
+	sm.taskEventBus.Clear()
 
 	return nil
 }
@@ -493,24 +543,39 @@ func (sm *StreamManager) emitNewContent(stream *JobStream, content string, newOf
 		return
 	}
 
-	// Add to buffer
-	stream.Buffer.AppendString(content)
+	// Interpret terminal control sequences and collapse carriage-return
+	// redraws so only completed lines reach the buffer. The buffer keeps the
+	// plain text, which search, filters and export work on; the markup is
+	// kept alongside for display.
+	lines := stream.Processor.ProcessLines(content)
+	texts := LineTexts(lines)
+	stream.linesMu.Lock()
+	stream.Buffer.Append(texts)
+	stream.Markup.Append(styledMarkup(lines))
+	stream.linesMu.Unlock()
 
-	// Split into lines for event
-	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
-	if lines[len(lines)-1] == "" {
-		lines = lines[:len(lines)-1]
+	partial := stream.Processor.PartialLine()
+	if stream.OutputType == "stdout" {
+		now := GetCurrentTime()
+		sm.progress.Observe(stream.JobID, texts, now)
+		sm.progress.Observe(stream.JobID, []string{partial.Text}, now)
+	}
+
+	rendered := ""
+	if len(lines) > 0 {
+		rendered = strings.Join(lineMarkup(lines), "\n") + "\n"
 	}
 
 	// Emit event
 	event := &StreamEvent{
-		JobID:      stream.JobID,
-		OutputType: stream.OutputType,
-		Content:    content,
-		NewLines:   lines,
-		Timestamp:  GetCurrentTime(),
-		EventType:  StreamEventNewOutput,
-		FileOffset: newOffset,
+		JobID:       stream.JobID,
+		OutputType:  stream.OutputType,
+		Content:     rendered,
+		NewLines:    texts,
+		PartialLine: partial.Markup,
+		Timestamp:   GetCurrentTime(),
+		EventType:   StreamEventNewOutput,
+		FileOffset:  newOffset,
 	}
 
 	sm.eventBus.Publish(event)
@@ -0,0 +1,490 @@
+package streaming
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rivo/tview"
+)
+
+const (
+	// DefaultMaxConcurrentTails bounds how many task files are read at once
+	DefaultMaxConcurrentTails = 8
+	// initialTaskTailBytes limits how much existing output is loaded per task
+	initialTaskTailBytes = 64 * 1024
+)
+
+// taskPrefixColors are cycled through to color the [task] prefix of merged lines
+var taskPrefixColors = []string{"cyan", "green", "yellow", "fuchsia", "aqua", "orange", "lime", "skyblue"}
+
+// errorMarkers identify lines that indicate a task has failed
+var errorMarkers = []string{
+	string(LogLevelError),
+	string(LogLevelFatal),
+	"TRACEBACK",
+	"EXCEPTION",
+	"SEGMENTATION FAULT",
+	"OUT OF MEMORY",
+	"OOM-KILL",
+}
+
+// TaskError records the first error line seen in an aggregated stream
+type TaskError struct {
+	TaskID    string    `json:"task_id"`
+	Line      string    `json:"line"`      // Prefixed plain line as stored in the merged buffer
+	Timestamp time.Time `json:"timestamp"` // When the line was read
+}
+
+// TaskStream tracks the tailing state of one task within a MultiTaskStream
+type TaskStream struct {
+	Source     TaskSource
+	LastOffset int64
+	Label      string // Plain [task] prefix prepended to every buffered line
+	Prefix     string // Colored [task] prefix prepended to every displayed line
+	processor  *TerminalProcessor
+}
+
+// MultiTaskStream merges the output of every task of a job into one stream
+type MultiTaskStream struct {
+	JobID      string
+	OutputType string
+	Buffer     *CircularBuffer // Merged, prefixed plain lines from all tasks
+	Tasks      []*TaskStream
+	IsActive   bool
+	LastUpdate time.Time
+
+	taskFilter map[string]bool // Empty means all tasks are shown
+	firstError *TaskError
+	stop       chan struct{}
+	mu         sync.RWMutex
+}
+
+// StartMultiTaskStream begins tailing the output files of every task of a
+// job and merges them into a single stream with a colored [task] prefix.
+func (sm *StreamManager) StartMultiTaskStream(jobID, outputType string) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	streamKey := sm.makeStreamKey(jobID, outputType)
+	if stream, exists := sm.taskStreams[streamKey]; exists && stream.IsActive {
+		return fmt.Errorf("multi-task stream already active for job %s %s", jobID, outputType)
+	}
+
+	sources, err := sm.pathResolver.ResolveTaskOutputPaths(jobID, outputType)
+	if err != nil {
+		return fmt.Errorf("failed to resolve task output paths: %w", err)
+	}
+	if len(sources) == 0 {
+		return fmt.Errorf("no task output files found for job %s", jobID)
+	}
+
+	stream := &MultiTaskStream{
+		JobID:      jobID,
+		OutputType: outputType,
+		Buffer:     NewCircularBuffer(sm.slurmConfig.BufferSize),
+		Tasks:      make([]*TaskStream, 0, len(sources)),
+		IsActive:   true,
+		LastUpdate: GetCurrentTime(),
+		taskFilter: make(map[string]bool),
+		stop:       make(chan struct{}),
+	}
+
+	remote := false
+	for i, source := range sources {
+		if err := sm.pathResolver.ValidateOutputPath(source.FilePath, source.IsRemote, source.NodeID); err != nil {
+			continue
+		}
+		color := taskPrefixColors[i%len(taskPrefixColors)]
+		stream.Tasks = append(stream.Tasks, &TaskStream{
+			Source:     source,
+			LastOffset: -1, // Resolved on first read
+			Label:      fmt.Sprintf("[%s] ", source.TaskID),
+			Prefix:     fmt.Sprintf("[%s][%s[][-] ", color, source.TaskID),
+			processor:  NewTerminalProcessor(),
+		})
+		remote = remote || source.IsRemote
+	}
+	if len(stream.Tasks) == 0 {
+		return fmt.Errorf("no valid task output paths for job %s", jobID)
+	}
+
+	sm.taskStreams[streamKey] = stream
+
+	interval := sm.slurmConfig.FileCheckInterval
+	if remote || interval <= 0 {
+		interval = remotePollingInterval
+	}
+	go sm.pollTaskStream(stream, interval, sm.maxConcurrentTails)
+
+	sm.taskEventBus.Publish(&StreamEvent{
+		JobID:      jobID,
+		OutputType: outputType,
+		EventType:  StreamEventStreamStart,
+		Timestamp:  GetCurrentTime(),
+	})
+
+	return nil
+}
+
+// SetMaxConcurrentTails sets how many task files an aggregated stream reads at once
+func (sm *StreamManager) SetMaxConcurrentTails(limit int) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.maxConcurrentTails = limit
+}
+
+// StopMultiTaskStream stops an aggregated stream
+func (sm *StreamManager) StopMultiTaskStream(jobID, outputType string) error {
+	sm.mu.Lock()
+	streamKey := sm.makeStreamKey(jobID, outputType)
+	stream, exists := sm.taskStreams[streamKey]
+	if !exists {
+		sm.mu.Unlock()
+		return fmt.Errorf("no multi-task stream for job %s %s", jobID, outputType)
+	}
+	delete(sm.taskStreams, streamKey)
+	sm.mu.Unlock()
+
+	stream.mu.Lock()
+	if stream.IsActive {
+		stream.IsActive = false
+		close(stream.stop)
+	}
+	stream.mu.Unlock()
+
+	sm.taskEventBus.Publish(&StreamEvent{
+		JobID:      jobID,
+		OutputType: outputType,
+		EventType:  StreamEventStreamStop,
+		Timestamp:  GetCurrentTime(),
+	})
+	sm.taskEventBus.UnsubscribeAll(jobID, outputType)
+
+	return nil
+}
+
+// SubscribeTasks adds a subscriber for merged events of an aggregated stream
+func (sm *StreamManager) SubscribeTasks(jobID, outputType string) <-chan StreamEvent {
+	ch := make(chan StreamEvent, 100)
+	sm.taskEventBus.Subscribe(jobID, outputType, ch)
+	return ch
+}
+
+// IsMultiTaskStreamActive returns true if an aggregated stream is running
+func (sm *StreamManager) IsMultiTaskStreamActive(jobID, outputType string) bool {
+	stream, err := sm.getTaskStream(jobID, outputType)
+	if err != nil {
+		return false
+	}
+	stream.mu.RLock()
+	defer stream.mu.RUnlock()
+	return stream.IsActive
+}
+
+// GetTaskSources returns the task sources of an aggregated stream
+func (sm *StreamManager) GetTaskSources(jobID, outputType string) ([]TaskSource, error) {
+	stream, err := sm.getTaskStream(jobID, outputType)
+	if err != nil {
+		return nil, err
+	}
+
+	sources := make([]TaskSource, 0, len(stream.Tasks))
+	for _, task := range stream.Tasks {
+		sources = append(sources, task.Source)
+	}
+	return sources, nil
+}
+
+// GetMultiTaskBuffer returns the merged lines, restricted to the task filter
+func (sm *StreamManager) GetMultiTaskBuffer(jobID, outputType string) ([]string, error) {
+	stream, err := sm.getTaskStream(jobID, outputType)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := stream.Buffer.GetLines()
+
+	stream.mu.RLock()
+	defer stream.mu.RUnlock()
+	if len(stream.taskFilter) == 0 {
+		return lines, nil
+	}
+
+	filtered := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if stream.lineVisible(line) {
+			filtered = append(filtered, line)
+		}
+	}
+	return filtered, nil
+}
+
+// RenderMultiTaskLines converts merged plain lines into tview markup with the
+// colored [task] prefix of their task
+func (sm *StreamManager) RenderMultiTaskLines(jobID, outputType string, lines []string) ([]string, error) {
+	stream, err := sm.getTaskStream(jobID, outputType)
+	if err != nil {
+		return nil, err
+	}
+
+	rendered := make([]string, len(lines))
+	for i, line := range lines {
+		rendered[i] = tview.Escape(line)
+		for _, task := range stream.Tasks {
+			if rest, ok := strings.CutPrefix(line, task.Label); ok {
+				rendered[i] = task.Prefix + tview.Escape(rest)
+				break
+			}
+		}
+	}
+	return rendered, nil
+}
+
+// SetTaskFilter restricts an aggregated stream to the given task IDs.
+// An empty list shows all tasks again.
+func (sm *StreamManager) SetTaskFilter(jobID, outputType string, taskIDs []string) error {
+	stream, err := sm.getTaskStream(jobID, outputType)
+	if err != nil {
+		return err
+	}
+
+	stream.mu.Lock()
+	defer stream.mu.Unlock()
+
+	stream.taskFilter = make(map[string]bool, len(taskIDs))
+	for _, id := range taskIDs {
+		stream.taskFilter[id] = true
+	}
+	return nil
+}
+
+// FirstTaskError returns the first error line printed by any task, or nil
+func (sm *StreamManager) FirstTaskError(jobID, outputType string) (*TaskError, error) {
+	stream, err := sm.getTaskStream(jobID, outputType)
+	if err != nil {
+		return nil, err
+	}
+
+	stream.mu.RLock()
+	defer stream.mu.RUnlock()
+	if stream.firstError == nil {
+		return nil, nil
+	}
+	taskErr := *stream.firstError
+	return &taskErr, nil
+}
+
+// getTaskStream looks up an aggregated stream
+func (sm *StreamManager) getTaskStream(jobID, outputType string) (*MultiTaskStream, error) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	stream, exists := sm.taskStreams[sm.makeStreamKey(jobID, outputType)]
+	if !exists {
+		return nil, fmt.Errorf("no multi-task stream for job %s %s", jobID, outputType)
+	}
+	return stream, nil
+}
+
+// pollTaskStream reads all task files on every tick until the stream stops
+func (sm *StreamManager) pollTaskStream(stream *MultiTaskStream, interval time.Duration, limit int) {
+	sm.readTaskIncrements(stream, limit)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-sm.ctx.Done():
+			return
+		case <-stream.stop:
+			return
+		case <-ticker.C:
+			sm.readTaskIncrements(stream, limit)
+		}
+	}
+}
+
+// readTaskIncrements reads new content from every task with bounded
+// concurrency, then merges it in task order so output stays deterministic.
+func (sm *StreamManager) readTaskIncrements(stream *MultiTaskStream, limit int) {
+	if limit <= 0 {
+		limit = DefaultMaxConcurrentTails
+	}
+
+	contents := make([]string, len(stream.Tasks))
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+
+	for i, task := range stream.Tasks {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, task *TaskStream) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			contents[i] = sm.readTaskIncrement(task)
+		}(i, task)
+	}
+	wg.Wait()
+
+	for i, content := range contents {
+		if content != "" {
+			sm.emitTaskContent(stream, stream.Tasks[i], content)
+		}
+	}
+}
+
+// readTaskIncrement returns content appended to a task file since the last read
+func (sm *StreamManager) readTaskIncrement(task *TaskStream) string {
+	if task.Source.IsRemote {
+		return sm.readRemoteTaskIncrement(task)
+	}
+
+	if task.LastOffset < 0 {
+		task.LastOffset = 0
+		if info, err := os.Stat(task.Source.FilePath); err == nil && info.Size() > initialTaskTailBytes {
+			task.LastOffset = info.Size() - initialTaskTailBytes
+		}
+	}
+
+	content, offset, err := sm.readFileFromOffset(task.Source.FilePath, task.LastOffset)
+	if err != nil {
+		return ""
+	}
+	task.LastOffset = offset
+	return content
+}
+
+// readRemoteTaskIncrement tails a task file on a compute node over SSH
+func (sm *StreamManager) readRemoteTaskIncrement(task *TaskStream) string {
+	if sm.sshExecutor == nil || task.Source.NodeID == "" {
+		return ""
+	}
+	escapedPath := strings.ReplaceAll(task.Source.FilePath, "'", "'\\''")
+
+	if task.LastOffset < 0 {
+		sizeOut, err := sm.sshExecutor.ExecuteCommand(sm.ctx, task.Source.NodeID,
+			fmt.Sprintf("wc -c < '%s' 2>/dev/null", escapedPath))
+		if err != nil {
+			return ""
+		}
+		size, err := strconv.ParseInt(strings.TrimSpace(sizeOut), 10, 64)
+		if err != nil {
+			return ""
+		}
+		task.LastOffset = 0
+		if size > initialTaskTailBytes {
+			task.LastOffset = size - initialTaskTailBytes
+		}
+	}
+
+	// tail -c +N outputs from byte N (1-based)
+	content, err := sm.sshExecutor.ExecuteCommand(sm.ctx, task.Source.NodeID,
+		fmt.Sprintf("tail -c +%d '%s' 2>/dev/null", task.LastOffset+1, escapedPath))
+	if err != nil {
+		return ""
+	}
+	task.LastOffset += int64(len(content))
+	return content
+}
+
+// emitTaskContent processes complete lines from a task, prefixes them, stores their
+// plain text in the merged buffer and publishes the ones that pass the task filter.
+func (sm *StreamManager) emitTaskContent(stream *MultiTaskStream, task *TaskStream, content string) {
+	lines := task.processor.ProcessLines(content)
+	if len(lines) == 0 {
+		return
+	}
+	prefixed := make([]string, 0, len(lines))
+	rendered := make([]string, 0, len(lines))
+	now := GetCurrentTime()
+
+	stream.mu.Lock()
+	for _, raw := range lines {
+		line := task.Label + raw.Text
+		prefixed = append(prefixed, line)
+		rendered = append(rendered, task.Prefix+raw.Markup)
+		if stream.firstError == nil && isErrorLine(raw.Text) {
+			stream.firstError = &TaskError{
+				TaskID:    task.Source.TaskID,
+				Line:      line,
+				Timestamp: now,
+			}
+		}
+	}
+	stream.LastUpdate = now
+	visible := len(stream.taskFilter) == 0 || stream.taskFilter[task.Source.TaskID]
+	stream.mu.Unlock()
+
+	stream.Buffer.Append(prefixed)
+
+	if !visible {
+		return
+	}
+
+	sm.taskEventBus.Publish(&StreamEvent{
+		JobID:      stream.JobID,
+		OutputType: stream.OutputType,
+		TaskID:     task.Source.TaskID,
+		Content:    strings.Join(rendered, "\n") + "\n",
+		NewLines:   prefixed,
+		Timestamp:  now,
+		EventType:  StreamEventNewOutput,
+		FileOffset: task.LastOffset,
+	})
+}
+
+// lineVisible reports whether a merged line belongs to a filtered-in task
+// (assumes stream lock held)
+func (s *MultiTaskStream) lineVisible(line string) bool {
+	for _, task := range s.Tasks {
+		if s.taskFilter[task.Source.TaskID] && strings.HasPrefix(line, task.Label) {
+			return true
+		}
+	}
+	return false
+}
+
+// isErrorLine reports whether a raw output line looks like an error
+func isErrorLine(line string) bool {
+	upper := strings.ToUpper(line)
+	for _, marker := range errorMarkers {
+		if strings.Contains(upper, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseTaskSelection expands a task selection such as "0-3,7,node12" into
+// individual task IDs. Numeric ranges are expanded; other items are kept as-is.
+func ParseTaskSelection(spec string) ([]string, error) {
+	var ids []string
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		bounds := strings.SplitN(part, "-", 2)
+		if len(bounds) == 2 {
+			start, errStart := strconv.Atoi(bounds[0])
+			end, errEnd := strconv.Atoi(bounds[1])
+			if errStart == nil && errEnd == nil {
+				if end < start {
+					return nil, fmt.Errorf("invalid task range %q", part)
+				}
+				for i := start; i <= end; i++ {
+					ids = append(ids, strconv.Itoa(i))
+				}
+				continue
+			}
+		}
+		ids = append(ids, part)
+	}
+	return ids, nil
+}
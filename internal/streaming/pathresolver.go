@@ -3,6 +3,8 @@ package streaming
 import (
 	"fmt"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/jontk/s9s/internal/dao"
@@ -15,7 +17,26 @@ func expandSlurmPattern(pattern string, job *dao.Job) string {
 	if !strings.Contains(pattern, "%") {
 		return pattern
 	}
+	return slurmPatternReplace(pattern, job)
+}
+
+// expandTaskPattern expands a per-task output pattern. In addition to the
+// job-level patterns it replaces %t=task rank, %n=node index relative to the
+// job and %N=the node the task runs on.
+func expandTaskPattern(pattern string, job *dao.Job, node string, nodeIndex, taskRank int) string {
+	if !strings.Contains(pattern, "%") {
+		return pattern
+	}
+	return slurmPatternReplace(pattern, job,
+		"%t", strconv.Itoa(taskRank),
+		"%n", strconv.Itoa(nodeIndex),
+		"%N", node,
+	)
+}
 
+// slurmPatternReplace performs the pattern substitution. Overrides are
+// matched before the job-level defaults so they take precedence.
+func slurmPatternReplace(pattern string, job *dao.Job, overrides ...string) string {
 	// For %A: use ArrayJobID if set, otherwise fall back to job ID
 	arrayJobID := job.ArrayJobID
 	if arrayJobID == "" {
@@ -27,8 +48,9 @@ func expandSlurmPattern(pattern string, job *dao.Job) string {
 		arrayTaskID = "0"
 	}
 
-	r := strings.NewReplacer(
-		"%%", "\x00", // Temporarily escape literal %% to avoid double-replacement
+	pairs := []string{"%%", "\x00"} // Temporarily escape literal %% to avoid double-replacement
+	pairs = append(pairs, overrides...)
+	pairs = append(pairs,
 		"%A", arrayJobID,
 		"%a", arrayTaskID,
 		"%j", job.ID,
@@ -37,7 +59,7 @@ func expandSlurmPattern(pattern string, job *dao.Job) string {
 		"%u", job.User,
 		"%N", job.NodeList,
 	)
-	result := r.Replace(pattern)
+	result := strings.NewReplacer(pairs...).Replace(pattern)
 	return strings.ReplaceAll(result, "\x00", "%")
 }
 
@@ -181,8 +203,8 @@ func (pr *PathResolver) parseNodeList(nodeList string) []string {
 
 	var nodes []string
 
-	// Handle comma-separated nodes
-	parts := strings.Split(nodeList, ",")
+	// Handle comma-separated nodes, ignoring commas inside brackets
+	parts := splitOutsideBrackets(nodeList)
 
 	for _, part := range parts {
 		part = strings.TrimSpace(part)
@@ -199,6 +221,27 @@ func (pr *PathResolver) parseNodeList(nodeList string) []string {
 	return nodes
 }
 
+// splitOutsideBrackets splits a node list on commas that are not inside a
+// bracketed range, so "cn[1,3],gpu01" yields ["cn[1,3]", "gpu01"]
+func splitOutsideBrackets(nodeList string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range nodeList {
+		switch r {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, nodeList[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, nodeList[start:])
+}
+
 // expandNodeRange expands SLURM node range notation like "node[1-3]" to ["node1", "node2", "node3"]
 func (pr *PathResolver) expandNodeRange(nodeRange string) []string {
 	// Find bracket positions
@@ -248,9 +291,12 @@ func (pr *PathResolver) parseNumericRange(prefix, rangeStr string) []string {
 		return nil
 	}
 
+	// Preserve zero padding such as node[001-010]
+	width := len(strings.TrimSpace(rangeParts[0]))
+
 	var nodes []string
 	for i := start; i <= end; i++ {
-		nodes = append(nodes, fmt.Sprintf("%s%d", prefix, i))
+		nodes = append(nodes, fmt.Sprintf("%s%0*d", prefix, width, i))
 	}
 	return nodes
 }
@@ -315,3 +361,146 @@ func (pr *PathResolver) GetJobOutputPaths(jobID string) (stdoutPath, stderrPath
 
 	return stdoutPath, stderrPath, isRemote, nodeID, nil
 }
+
+// TaskSource describes the output file of a single task in an aggregated stream
+type TaskSource struct {
+	TaskID   string // Array task ID, task rank or node name
+	FilePath string // Resolved output file path
+	IsRemote bool   // Whether the file must be read over SSH
+	NodeID   string // Node holding the file when remote
+}
+
+// ResolveTaskOutputPaths resolves the output file of every task that belongs
+// to a job. Array jobs yield one source per array task; jobs whose output
+// pattern contains %t, %n or %N yield one source per task rank or node.
+// Any other job yields a single source.
+func (pr *PathResolver) ResolveTaskOutputPaths(jobID, outputType string) ([]TaskSource, error) {
+	job, err := pr.client.Jobs().Get(jobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job info from SLURM API: %w", err)
+	}
+
+	if job.ArrayJobID != "" {
+		return pr.resolveArrayTaskPaths(job, outputType)
+	}
+
+	pattern := job.StdOut
+	if outputType == "stderr" {
+		pattern = job.StdErr
+	}
+
+	switch {
+	case strings.Contains(pattern, "%t"):
+		return pr.resolveRankPaths(job, pattern), nil
+	case strings.Contains(pattern, "%N"), strings.Contains(pattern, "%n"):
+		return pr.resolveNodePaths(job, pattern), nil
+	}
+
+	path := pr.resolveStdoutPath(job)
+	if outputType == "stderr" {
+		path = pr.resolveStderrPath(job)
+	}
+	return []TaskSource{{
+		TaskID:   "0",
+		FilePath: path,
+		IsRemote: pr.isRemoteNode(job.NodeList),
+		NodeID:   pr.extractPrimaryNode(job.NodeList),
+	}}, nil
+}
+
+// resolveArrayTaskPaths resolves one output path per task of a job array.
+// Only the array's tasks are listed; the owner narrows the query on the
+// server.
+func (pr *PathResolver) resolveArrayTaskPaths(job *dao.Job, outputType string) ([]TaskSource, error) {
+	opts := &dao.ListJobsOptions{ArrayJobID: job.ArrayJobID}
+	if job.User != "" {
+		opts.Users = []string{job.User}
+	}
+	jobList, err := pr.client.Jobs().List(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list array tasks: %w", err)
+	}
+
+	var tasks []*dao.Job
+	for _, candidate := range jobList.Jobs {
+		if candidate.ArrayJobID == job.ArrayJobID && candidate.ArrayTaskID != "" {
+			tasks = append(tasks, candidate)
+		}
+	}
+	if len(tasks) == 0 {
+		tasks = []*dao.Job{job}
+	}
+
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return lessTaskID(tasks[i].ArrayTaskID, tasks[j].ArrayTaskID)
+	})
+
+	sources := make([]TaskSource, 0, len(tasks))
+	for _, task := range tasks {
+		path := pr.resolveStdoutPath(task)
+		if outputType == "stderr" {
+			path = pr.resolveStderrPath(task)
+		}
+		sources = append(sources, TaskSource{
+			TaskID:   task.ArrayTaskID,
+			FilePath: path,
+			IsRemote: pr.isRemoteNode(task.NodeList),
+			NodeID:   pr.extractPrimaryNode(task.NodeList),
+		})
+	}
+	return sources, nil
+}
+
+// resolveRankPaths resolves one output path per task rank (%t). Ranks are
+// assigned to nodes in blocks, matching SLURM's default distribution.
+func (pr *PathResolver) resolveRankPaths(job *dao.Job, pattern string) []TaskSource {
+	nodes := pr.parseNodeList(job.NodeList)
+	if len(nodes) == 0 {
+		nodes = []string{""}
+	}
+	ranks := job.Tasks
+	if ranks <= 0 {
+		ranks = len(nodes)
+	}
+
+	sources := make([]TaskSource, 0, ranks)
+	for rank := 0; rank < ranks; rank++ {
+		nodeIndex := rank * len(nodes) / ranks
+		node := nodes[nodeIndex]
+		path := pr.makeAbsolute(expandTaskPattern(pattern, job, node, nodeIndex, rank), job.WorkingDir)
+		sources = append(sources, TaskSource{
+			TaskID:   strconv.Itoa(rank),
+			FilePath: path,
+			IsRemote: pr.isRemoteNode(node),
+			NodeID:   node,
+		})
+	}
+	return sources
+}
+
+// resolveNodePaths resolves one output path per allocated node (%N, %n)
+func (pr *PathResolver) resolveNodePaths(job *dao.Job, pattern string) []TaskSource {
+	nodes := pr.parseNodeList(job.NodeList)
+
+	sources := make([]TaskSource, 0, len(nodes))
+	for i, node := range nodes {
+		path := pr.makeAbsolute(expandTaskPattern(pattern, job, node, i, i), job.WorkingDir)
+		sources = append(sources, TaskSource{
+			TaskID:   node,
+			FilePath: path,
+			IsRemote: pr.isRemoteNode(node),
+			NodeID:   node,
+		})
+	}
+	return sources
+}
+
+// lessTaskID orders task IDs numerically when possible, lexically otherwise
+func lessTaskID(a, b string) bool {
+	ai, errA := strconv.Atoi(a)
+	bi, errB := strconv.Atoi(b)
+	if errA == nil && errB == nil {
+		return ai < bi
+	}
+	return a < b
+}
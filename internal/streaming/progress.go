@@ -0,0 +1,452 @@
+package streaming
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jontk/s9s/internal/dao"
+)
+
+const (
+	// minRateWindow is the shortest span of observations used to compute a
+	// rate; shorter series fall back to the progress made since job start
+	minRateWindow = 30 * time.Second
+	// progressSampleBytes is how much of an output file tail is scanned when
+	// sampling progress of jobs without an active stream
+	progressSampleBytes = 16 * 1024
+	// progressRetention is how long an unobserved job's progress is kept
+	progressRetention = 24 * time.Hour
+)
+
+// ProgressSample is a single progress reading extracted from an output line
+type ProgressSample struct {
+	Percent float64 // Completion in percent (0-100)
+	Current float64 // Completed units, if the line reports them
+	Total   float64 // Total units, if the line reports them
+}
+
+// ProgressExtractor recognizes progress information in a line of job output.
+// Lines are passed without color tags.
+type ProgressExtractor interface {
+	Name() string
+	Extract(line string) (ProgressSample, bool)
+}
+
+// RegexProgressExtractor extracts progress with a regular expression using
+// either a "percent" named group or "current" and "total" named groups
+type RegexProgressExtractor struct {
+	name    string
+	re      *regexp.Regexp
+	percent int
+	current int
+	total   int
+}
+
+// progressPresets are the built-in extractor patterns, in priority order.
+// Coarse whole-job counters come first so that per-epoch bars do not
+// override them.
+var progressPresets = []struct {
+	name    string
+	pattern string
+}{
+	{"epoch", `(?i)\bepoch[\s:=]*(?P<current>\d+)\s*(?:/|of)\s*(?P<total>\d+)`},
+	{"step", `(?i)\b(?:step|iter(?:ation)?|batch)[\s:=]*(?P<current>\d+)\s*(?:/|of)\s*(?P<total>\d+)`},
+	{"tqdm", `(?P<percent>\d{1,3}(?:\.\d+)?)%\|`},
+	{"percent", `(?i)(?:progress|complete|completed|done)[\s:=]*(?P<percent>\d{1,3}(?:\.\d+)?)\s*%`},
+	{"fraction", `\[\s*(?P<current>\d+)\s*/\s*(?P<total>\d+)\s*\]`},
+}
+
+// NewRegexProgressExtractor creates an extractor from a pattern with named groups
+func NewRegexProgressExtractor(name, pattern string) (*RegexProgressExtractor, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid progress pattern %q: %w", name, err)
+	}
+
+	e := &RegexProgressExtractor{
+		name:    name,
+		re:      re,
+		percent: re.SubexpIndex("percent"),
+		current: re.SubexpIndex("current"),
+		total:   re.SubexpIndex("total"),
+	}
+	if e.percent < 0 && (e.current < 0 || e.total < 0) {
+		return nil, fmt.Errorf("progress pattern %q needs a (?P<percent>) group or (?P<current>) and (?P<total>) groups", name)
+	}
+	return e, nil
+}
+
+// Name returns the extractor name
+func (e *RegexProgressExtractor) Name() string {
+	return e.name
+}
+
+// Extract returns the last progress reading in the line
+func (e *RegexProgressExtractor) Extract(line string) (ProgressSample, bool) {
+	matches := e.re.FindAllStringSubmatch(line, -1)
+	if len(matches) == 0 {
+		return ProgressSample{}, false
+	}
+	m := matches[len(matches)-1]
+
+	if e.current >= 0 && e.total >= 0 && m[e.current] != "" && m[e.total] != "" {
+		current, err1 := strconv.ParseFloat(m[e.current], 64)
+		total, err2 := strconv.ParseFloat(m[e.total], 64)
+		if err1 != nil || err2 != nil || total <= 0 || current > total {
+			return ProgressSample{}, false
+		}
+		return ProgressSample{Percent: current / total * 100, Current: current, Total: total}, true
+	}
+
+	if e.percent >= 0 && m[e.percent] != "" {
+		percent, err := strconv.ParseFloat(m[e.percent], 64)
+		if err != nil || percent > 100 {
+			return ProgressSample{}, false
+		}
+		return ProgressSample{Percent: percent}, true
+	}
+
+	return ProgressSample{}, false
+}
+
+// ProgressPresetNames returns the names of the built-in extractors
+func ProgressPresetNames() []string {
+	names := make([]string, len(progressPresets))
+	for i, preset := range progressPresets {
+		names[i] = preset.name
+	}
+	return names
+}
+
+// ProgressPreset returns the built-in extractor with the given name
+func ProgressPreset(name string) (ProgressExtractor, error) {
+	for _, preset := range progressPresets {
+		if preset.name == name {
+			return NewRegexProgressExtractor(preset.name, preset.pattern)
+		}
+	}
+	return nil, fmt.Errorf("unknown progress preset %q", name)
+}
+
+// DefaultProgressExtractors returns all built-in extractors in priority order
+func DefaultProgressExtractors() []ProgressExtractor {
+	extractors := make([]ProgressExtractor, 0, len(progressPresets))
+	for _, preset := range progressPresets {
+		e, err := NewRegexProgressExtractor(preset.name, preset.pattern)
+		if err != nil {
+			panic(err) // Built-in patterns are constant
+		}
+		extractors = append(extractors, e)
+	}
+	return extractors
+}
+
+// ProgressEstimate is the projected completion of a job
+type ProgressEstimate struct {
+	Percent     float64       // Latest completion in percent
+	Rate        float64       // Percentage points per minute
+	ETA         time.Duration // Projected time until completion, 0 if unknown
+	Remaining   time.Duration // Time left before the job's time limit, 0 if unknown
+	WillTimeOut bool          // ETA exceeds the time remaining before the limit
+	Source      string        // Name of the extractor that produced the estimate
+	UpdatedAt   time.Time     // When the latest reading was observed
+}
+
+// HasETA reports whether a completion time could be projected
+func (e *ProgressEstimate) HasETA() bool {
+	return e.Rate > 0 && e.Percent < 100
+}
+
+// progressSeries tracks readings of one extractor for one job
+type progressSeries struct {
+	first, last       ProgressSample
+	firstAt, lastAt   time.Time
+	extractorPriority int
+}
+
+// ProgressTracker extracts progress readings from job output and projects
+// completion times. It is safe for concurrent use.
+type ProgressTracker struct {
+	extractors []ProgressExtractor
+	series     map[string]map[string]*progressSeries // jobID -> extractor name -> series
+	mu         sync.RWMutex
+}
+
+// NewProgressTracker creates a tracker using the given extractors in priority
+// order, or the built-in presets if none are given
+func NewProgressTracker(extractors ...ProgressExtractor) *ProgressTracker {
+	if len(extractors) == 0 {
+		extractors = DefaultProgressExtractors()
+	}
+	return &ProgressTracker{
+		extractors: extractors,
+		series:     make(map[string]map[string]*progressSeries),
+	}
+}
+
+// SetExtractors replaces the extractors and discards all readings
+func (pt *ProgressTracker) SetExtractors(extractors []ProgressExtractor) {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	pt.extractors = extractors
+	pt.series = make(map[string]map[string]*progressSeries)
+}
+
+// Observe scans output lines of a job for progress readings made at the
+// given time. Lines are plain text, as kept in stream buffers.
+func (pt *ProgressTracker) Observe(jobID string, lines []string, at time.Time) {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+		for priority, extractor := range pt.extractors {
+			sample, ok := extractor.Extract(line)
+			if !ok {
+				continue
+			}
+			pt.record(jobID, extractor.Name(), priority, sample, at)
+		}
+	}
+}
+
+// record adds a reading to a job's series for one extractor
+func (pt *ProgressTracker) record(jobID, name string, priority int, sample ProgressSample, at time.Time) {
+	jobSeries := pt.series[jobID]
+	if jobSeries == nil {
+		jobSeries = make(map[string]*progressSeries)
+		pt.series[jobID] = jobSeries
+	}
+
+	s := jobSeries[name]
+	// A falling reading means the counter restarted (e.g. a new phase)
+	if s == nil || sample.Percent < s.last.Percent {
+		jobSeries[name] = &progressSeries{
+			first: sample, last: sample,
+			firstAt: at, lastAt: at,
+			extractorPriority: priority,
+		}
+		return
+	}
+	s.last = sample
+	s.lastAt = at
+}
+
+// Estimate projects the completion of a job from its readings. The job, if
+// given, provides the start time and time limit used for the rate fallback
+// and the timeout check.
+func (pt *ProgressTracker) Estimate(jobID string, job *dao.Job, now time.Time) (*ProgressEstimate, bool) {
+	pt.mu.RLock()
+	var best *progressSeries
+	var source string
+	for name, s := range pt.series[jobID] {
+		if best == nil || s.extractorPriority < best.extractorPriority {
+			best, source = s, name
+		}
+	}
+	var s progressSeries
+	if best != nil {
+		s = *best
+	}
+	pt.mu.RUnlock()
+
+	if best == nil {
+		return nil, false
+	}
+
+	estimate := &ProgressEstimate{
+		Percent:   s.last.Percent,
+		Source:    source,
+		UpdatedAt: s.lastAt,
+	}
+
+	if span := s.lastAt.Sub(s.firstAt); span >= minRateWindow && s.last.Percent > s.first.Percent {
+		estimate.Rate = (s.last.Percent - s.first.Percent) / span.Minutes()
+	} else if job != nil && job.StartTime != nil {
+		if elapsed := s.lastAt.Sub(*job.StartTime); elapsed > 0 && s.last.Percent > 0 {
+			estimate.Rate = s.last.Percent / elapsed.Minutes()
+		}
+	}
+
+	if estimate.HasETA() {
+		minutes := (100 - estimate.Percent) / estimate.Rate
+		eta := time.Duration(minutes*float64(time.Minute)) - now.Sub(s.lastAt)
+		estimate.ETA = max(eta, time.Second)
+	}
+
+	if job != nil && job.StartTime != nil {
+		if limit, ok := dao.ParseTimeLimit(job.TimeLimit); ok {
+			estimate.Remaining = max(limit-now.Sub(*job.StartTime), 0)
+			estimate.WillTimeOut = estimate.HasETA() && estimate.ETA > estimate.Remaining
+		}
+	}
+
+	return estimate, true
+}
+
+// Forget discards the readings of a job
+func (pt *ProgressTracker) Forget(jobID string) {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	delete(pt.series, jobID)
+}
+
+// progressOffset records how far a job's output file was read when it was
+// last sampled
+type progressOffset struct {
+	size      int64     // File size at the sample
+	next      int64     // Where the next read resumes: the start of the unfinished line
+	sampledAt time.Time // When the file was last looked at
+}
+
+// Prune discards the readings of jobs not observed since the given time
+func (pt *ProgressTracker) Prune(before time.Time) {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	for jobID, jobSeries := range pt.series {
+		stale := true
+		for _, s := range jobSeries {
+			if !s.lastAt.Before(before) {
+				stale = false
+				break
+			}
+		}
+		if stale {
+			delete(pt.series, jobID)
+		}
+	}
+}
+
+// Progress returns the tracker fed by the stream manager
+func (sm *StreamManager) Progress() *ProgressTracker {
+	return sm.progress
+}
+
+// EstimateProgress projects the completion of a job from its output
+func (sm *StreamManager) EstimateProgress(job *dao.Job) (*ProgressEstimate, bool) {
+	return sm.progress.Estimate(job.ID, job, GetCurrentTime())
+}
+
+// SampleProgress reads the stdout tail of running jobs that have no active
+// stream and feeds new content to the progress tracker. Only files on the
+// local or shared filesystem are sampled; reads are bounded by the
+// concurrent tail limit.
+func (sm *StreamManager) SampleProgress(jobs []*dao.Job) {
+	sm.SampleProgressAt(jobs, GetCurrentTime())
+}
+
+// SampleProgressAt is SampleProgress with readings taken at the given time
+func (sm *StreamManager) SampleProgressAt(jobs []*dao.Job, now time.Time) {
+	sm.mu.RLock()
+	limit := sm.maxConcurrentTails
+	var pending []*dao.Job
+	for _, job := range jobs {
+		if job.State != dao.JobStateRunning {
+			continue
+		}
+		if stream, ok := sm.activeStreams[sm.makeStreamKey(job.ID, "stdout")]; ok && stream.IsActive {
+			continue
+		}
+		if sm.pathResolver.isRemoteNode(job.NodeList) {
+			continue
+		}
+		pending = append(pending, job)
+	}
+	sm.mu.RUnlock()
+
+	sem := make(chan struct{}, max(limit, 1))
+	var wg sync.WaitGroup
+	for _, job := range pending {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(job *dao.Job) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			sm.sampleJobProgress(job, now)
+		}(job)
+	}
+	wg.Wait()
+
+	sm.PruneProgress(now.Add(-progressRetention))
+}
+
+// PruneProgress discards the progress readings of jobs not observed since
+// the given time, along with the output offsets of jobs not sampled since
+// then, so finished jobs are forgotten
+func (sm *StreamManager) PruneProgress(before time.Time) {
+	sm.progress.Prune(before)
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	for jobID, offset := range sm.progressOffsets {
+		if offset.sampledAt.Before(before) {
+			delete(sm.progressOffsets, jobID)
+		}
+	}
+}
+
+// sampleJobProgress reads what a job's stdout file gained since the last
+// sample, at most its last progressSampleBytes, and observes those lines.
+// Lines read before are not observed again: an old reading lower than the
+// latest would restart the series and lose its rate window.
+func (sm *StreamManager) sampleJobProgress(job *dao.Job, now time.Time) {
+	path := sm.pathResolver.resolveStdoutPath(job)
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	size := info.Size()
+
+	sm.mu.Lock()
+	last, seen := sm.progressOffsets[job.ID]
+	unchanged := seen && size == last.size
+	if unchanged {
+		last.sampledAt = now
+		sm.progressOffsets[job.ID] = last
+	}
+	sm.mu.Unlock()
+	if unchanged {
+		return
+	}
+
+	// Resume at the unfinished line of the last sample unless the file was
+	// truncated or grew by more than a tail since
+	tail := max(size-progressSampleBytes, 0)
+	start, resumed := tail, false
+	if seen && last.next <= size && last.next >= tail {
+		start, resumed = last.next, true
+	}
+
+	content, end, err := sm.readFileFromOffset(path, start)
+	if err != nil {
+		return
+	}
+	next := end
+	if i := strings.LastIndexByte(content, '\n'); i >= 0 {
+		next = start + int64(i) + 1
+	} else if resumed {
+		next = start
+	}
+	if start > 0 && !resumed {
+		// The tail starts inside a line; keep from the next line or redraw
+		if i := strings.IndexAny(content, "\r\n"); i >= 0 {
+			content = content[i+1:]
+		}
+	}
+
+	sm.mu.Lock()
+	sm.progressOffsets[job.ID] = progressOffset{size: end, next: next, sampledAt: now}
+	sm.mu.Unlock()
+
+	processor := NewTerminalProcessor()
+	lines := processor.ProcessLines(content)
+	lines = append(lines, processor.FlushLines()...)
+	sm.progress.Observe(job.ID, LineTexts(lines), now)
+}
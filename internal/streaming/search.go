@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"regexp"
 	"sync"
+
+	"github.com/rivo/tview"
 )
 
 // SearchResult represents a search match in the stream
@@ -135,18 +137,19 @@ func (ss *StreamSearcher) SearchPrevious(startLine int) (*SearchResult, error) {
 	return nil, fmt.Errorf("no more matches found")
 }
 
-// GetHighlightedLine returns a line with search matches highlighted
+// GetHighlightedLine renders a plain line as tview markup with search matches
+// highlighted
 func (ss *StreamSearcher) GetHighlightedLine(line, highlightColor string) string {
 	ss.mu.RLock()
 	defer ss.mu.RUnlock()
 
 	if ss.currentQuery == "" {
-		return line
+		return tview.Escape(line)
 	}
 
 	matches := ss.findMatches(line)
 	if len(matches) == 0 {
-		return line
+		return tview.Escape(line)
 	}
 
 	// Build highlighted line
@@ -155,18 +158,35 @@ func (ss *StreamSearcher) GetHighlightedLine(line, highlightColor string) string
 
 	for _, match := range matches {
 		// Add text before match
-		highlighted += line[lastEnd:match.Start]
+		highlighted += tview.Escape(line[lastEnd:match.Start])
 		// Add highlighted match
-		highlighted += fmt.Sprintf("[%s]%s[white]", highlightColor, line[match.Start:match.End])
+		highlighted += fmt.Sprintf("[%s]%s[white]", highlightColor, tview.Escape(line[match.Start:match.End]))
 		lastEnd = match.End
 	}
 
 	// Add remaining text
-	highlighted += line[lastEnd:]
+	highlighted += tview.Escape(line[lastEnd:])
 
 	return highlighted
 }
 
+// MatchIndices returns the start and end offsets of the matches of the current
+// query in a line, in the form used by HighlightLine
+func (ss *StreamSearcher) MatchIndices(line string) []int {
+	ss.mu.RLock()
+	defer ss.mu.RUnlock()
+
+	if ss.currentQuery == "" {
+		return nil
+	}
+
+	var indices []int
+	for _, match := range ss.findMatches(line) {
+		indices = append(indices, match.Start, match.End)
+	}
+	return indices
+}
+
 // preparePattern prepares the search pattern based on options
 func (ss *StreamSearcher) preparePattern(query string) error {
 	pattern := query
@@ -299,10 +319,11 @@ func (ss *StreamSearcher) Clear() {
 	ss.results = make([]*SearchResult, 0)
 }
 
-// HighlightLine applies multiple highlights to a line (for both search and filters)
+// HighlightLine renders a plain line as tview markup with multiple highlights
+// applied (for both search and filters)
 func HighlightLine(line string, highlights map[string][]int, colors map[string]string) string {
 	if len(highlights) == 0 {
-		return line
+		return tview.Escape(line)
 	}
 
 	ranges := collectHighlightRanges(highlights, colors)
@@ -368,14 +389,14 @@ func buildHighlightedLine(line string, ranges []highlightRange) string {
 		}
 
 		// Add text before highlight
-		highlighted += line[lastEnd:r.start]
+		highlighted += tview.Escape(line[lastEnd:r.start])
 		// Add highlighted text
-		highlighted += fmt.Sprintf("[%s]%s[white]", r.color, line[r.start:r.end])
+		highlighted += fmt.Sprintf("[%s]%s[white]", r.color, tview.Escape(line[r.start:r.end]))
 		lastEnd = r.end
 	}
 
 	// Add remaining text
-	highlighted += line[lastEnd:]
+	highlighted += tview.Escape(line[lastEnd:])
 
 	return highlighted
 }
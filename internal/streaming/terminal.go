@@ -0,0 +1,525 @@
+package streaming
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/rivo/tview"
+)
+
+const (
+	escapeChar   = 0x1b
+	bellChar     = 0x07
+	tabStopWidth = 8
+	// maxPendingEscape bounds how much of an unterminated escape sequence is
+	// carried between chunks before it is treated as garbage
+	maxPendingEscape = 4096
+	// maxLineWidth bounds cursor movements, so a sequence such as
+	// ESC[999999999C cannot pad a line to an arbitrary width
+	maxLineWidth = 16384
+)
+
+// ansiBasicColors maps SGR colors 30-37/40-47 to tview color names. The names
+// refer to palette entries 0-7, so they follow the user's terminal theme.
+var ansiBasicColors = []string{"black", "maroon", "green", "olive", "navy", "purple", "teal", "silver"}
+
+// ansiBrightColors maps SGR colors 90-97/100-107 to palette entries 8-15
+var ansiBrightColors = []string{"gray", "red", "lime", "yellow", "blue", "fuchsia", "aqua", "white"}
+
+// textStyle is the SGR state applied to a terminal cell
+type textStyle struct {
+	fg, bg                              string
+	bold, dim, italic, underline, blink bool
+	reverse, strikethrough              bool
+}
+
+// tag renders the style as a tview style tag, or "" for the default style
+func (s textStyle) tag() string {
+	if s == (textStyle{}) {
+		return ""
+	}
+
+	fg, bg := s.fg, s.bg
+	if fg == "" {
+		fg = "-"
+	}
+	if bg == "" {
+		bg = "-"
+	}
+
+	var attrs strings.Builder
+	for _, attr := range []struct {
+		on   bool
+		flag byte
+	}{
+		{s.bold, 'b'}, {s.dim, 'd'}, {s.italic, 'i'}, {s.underline, 'u'},
+		{s.blink, 'l'}, {s.reverse, 'r'}, {s.strikethrough, 's'},
+	} {
+		if attr.on {
+			attrs.WriteByte(attr.flag)
+		}
+	}
+	if attrs.Len() == 0 {
+		attrs.WriteByte('-')
+	}
+
+	return fmt.Sprintf("[%s:%s:%s]", fg, bg, attrs.String())
+}
+
+// terminalCell is a single rune on the current line with its style
+type terminalCell struct {
+	r     rune
+	style textStyle
+}
+
+// TerminalProcessor converts raw terminal output into lines of plain text and
+// lines formatted with tview color tags. SGR sequences become color tags, carriage returns and
+// backspaces overwrite the current line like a terminal would, and all other
+// control sequences are stripped. Input may be fed in arbitrary chunks;
+// incomplete escape sequences and UTF-8 runes are carried over to the next
+// call. A processor is not safe for concurrent use.
+type TerminalProcessor struct {
+	line    []terminalCell
+	cursor  int
+	style   textStyle
+	pending string // Incomplete escape sequence or rune from the previous chunk
+}
+
+// NewTerminalProcessor creates a new terminal output processor
+func NewTerminalProcessor() *TerminalProcessor {
+	return &TerminalProcessor{}
+}
+
+// TerminalLine is a processed line of output, as plain text for storing,
+// searching and exporting, and as tview markup with its colors for display
+type TerminalLine struct {
+	Text   string
+	Markup string
+}
+
+// Process consumes a chunk of raw output and returns the lines completed by
+// it as tview markup. The line still being written is available through
+// Partial.
+func (tp *TerminalProcessor) Process(chunk string) []string {
+	return lineMarkup(tp.ProcessLines(chunk))
+}
+
+// ProcessLines is like Process but returns both the plain text and the
+// markup of the completed lines
+func (tp *TerminalProcessor) ProcessLines(chunk string) []TerminalLine {
+	data := tp.pending + chunk
+	tp.pending = ""
+
+	var lines []TerminalLine
+	for i := 0; i < len(data); {
+		b := data[i]
+		switch {
+		case b == escapeChar:
+			n, complete := tp.handleEscape(data[i:])
+			if !complete {
+				if len(data)-i <= maxPendingEscape {
+					tp.pending = data[i:]
+					return lines
+				}
+				n = 1 // Drop the ESC and treat the rest as text
+			}
+			i += n
+			continue
+		case b == '\n':
+			lines = append(lines, tp.currentLine())
+			tp.line = tp.line[:0]
+			tp.cursor = 0
+		case b == '\r':
+			tp.cursor = 0
+		case b == '\b':
+			if tp.cursor > 0 {
+				tp.cursor--
+			}
+		case b == '\t':
+			for {
+				tp.put(' ')
+				if tp.cursor%tabStopWidth == 0 {
+					break
+				}
+			}
+		case b < 0x20 || b == 0x7f:
+			// Drop other control characters (BEL, NUL, ...)
+		default:
+			r, size := utf8.DecodeRuneInString(data[i:])
+			if r == utf8.RuneError && size <= 1 && !utf8.FullRuneInString(data[i:]) {
+				tp.pending = data[i:]
+				return lines
+			}
+			tp.put(r)
+			i += size
+			continue
+		}
+		i++
+	}
+
+	return lines
+}
+
+// Partial returns the rendered line currently being written, such as the
+// latest state of a progress bar
+func (tp *TerminalProcessor) Partial() string {
+	return tp.PartialLine().Markup
+}
+
+// PartialLine is like Partial but returns both the plain text and the markup
+// of the line
+func (tp *TerminalProcessor) PartialLine() TerminalLine {
+	if len(tp.line) == 0 {
+		return TerminalLine{}
+	}
+	return tp.currentLine()
+}
+
+// Flush returns the pending partial line, if any, as a completed line and
+// resets the line state
+func (tp *TerminalProcessor) Flush() []string {
+	return lineMarkup(tp.FlushLines())
+}
+
+// FlushLines is like Flush but returns both the plain text and the markup of
+// the line
+func (tp *TerminalProcessor) FlushLines() []TerminalLine {
+	tp.pending = ""
+	if len(tp.line) == 0 {
+		return nil
+	}
+	line := tp.currentLine()
+	tp.line = tp.line[:0]
+	tp.cursor = 0
+	return []TerminalLine{line}
+}
+
+// LineTexts returns the plain text of lines
+func LineTexts(lines []TerminalLine) []string {
+	texts := make([]string, len(lines))
+	for i, line := range lines {
+		texts[i] = line.Text
+	}
+	return texts
+}
+
+// styledMarkup returns the markup of lines that carry styling and an empty
+// string for the others, whose markup is their escaped text
+func styledMarkup(lines []TerminalLine) []string {
+	markup := make([]string, len(lines))
+	for i, line := range lines {
+		if line.Markup != tview.Escape(line.Text) {
+			markup[i] = line.Markup
+		}
+	}
+	return markup
+}
+
+// lineMarkup returns the markup of lines
+func lineMarkup(lines []TerminalLine) []string {
+	if lines == nil {
+		return nil
+	}
+	markup := make([]string, len(lines))
+	for i, line := range lines {
+		markup[i] = line.Markup
+	}
+	return markup
+}
+
+// RenderTerminalOutput converts complete terminal output, such as a whole
+// output file, into tview-formatted text
+func RenderTerminalOutput(content string) string {
+	tp := NewTerminalProcessor()
+	lines := tp.Process(content)
+	lines = append(lines, tp.Flush()...)
+	if len(lines) == 0 {
+		return ""
+	}
+
+	result := strings.Join(lines, "\n")
+	if strings.HasSuffix(content, "\n") {
+		result += "\n"
+	}
+	return result
+}
+
+// put writes a rune at the cursor, overwriting any existing cell
+func (tp *TerminalProcessor) put(r rune) {
+	for len(tp.line) < tp.cursor {
+		tp.line = append(tp.line, terminalCell{r: ' '})
+	}
+	cell := terminalCell{r: r, style: tp.style}
+	if tp.cursor < len(tp.line) {
+		tp.line[tp.cursor] = cell
+	} else {
+		tp.line = append(tp.line, cell)
+	}
+	tp.cursor++
+}
+
+// currentLine returns the current line as plain text and as markup
+func (tp *TerminalProcessor) currentLine() TerminalLine {
+	return TerminalLine{Text: tp.plainLine(), Markup: tp.renderLine()}
+}
+
+// plainLine returns the text of the current line without styles
+func (tp *TerminalProcessor) plainLine() string {
+	var out strings.Builder
+	for _, cell := range tp.line {
+		out.WriteRune(cell.r)
+	}
+	return out.String()
+}
+
+// renderLine renders the current line with tview tags and escaped text
+func (tp *TerminalProcessor) renderLine() string {
+	var out, run strings.Builder
+	current := ""
+
+	flush := func() {
+		out.WriteString(tview.Escape(run.String()))
+		run.Reset()
+	}
+
+	for _, cell := range tp.line {
+		if tag := cell.style.tag(); tag != current {
+			flush()
+			if tag == "" {
+				out.WriteString("[-:-:-]")
+			} else {
+				out.WriteString(tag)
+			}
+			current = tag
+		}
+		run.WriteRune(cell.r)
+	}
+	flush()
+	if current != "" {
+		out.WriteString("[-:-:-]")
+	}
+
+	return out.String()
+}
+
+// handleEscape interprets the escape sequence at the start of data. It returns
+// the number of bytes consumed, or complete=false if the sequence is cut off.
+func (tp *TerminalProcessor) handleEscape(data string) (n int, complete bool) {
+	if len(data) < 2 {
+		return 0, false
+	}
+
+	switch data[1] {
+	case '[':
+		return tp.handleCSI(data)
+	case ']', 'P', '_', '^':
+		// OSC/DCS/APC/PM strings end with BEL or ESC \
+		for i := 2; i < len(data); i++ {
+			if data[i] == bellChar {
+				return i + 1, true
+			}
+			if data[i] == escapeChar {
+				if i+1 >= len(data) {
+					return 0, false
+				}
+				if data[i+1] == '\\' {
+					return i + 2, true
+				}
+			}
+		}
+		return 0, false
+	case '(', ')', '*', '+', '#', '%':
+		// Character set designation takes one more byte
+		if len(data) < 3 {
+			return 0, false
+		}
+		return 3, true
+	default:
+		if data[1] < 0x20 {
+			// Stray ESC before a control character; drop only the ESC
+			return 1, true
+		}
+		return 2, true
+	}
+}
+
+// handleCSI interprets a Control Sequence Introducer sequence
+func (tp *TerminalProcessor) handleCSI(data string) (n int, complete bool) {
+	for i := 2; i < len(data); i++ {
+		final := data[i]
+		if final < 0x40 || final > 0x7e {
+			continue
+		}
+
+		params := data[2:i]
+		switch final {
+		case 'm':
+			tp.applySGR(params)
+		case 'K':
+			tp.eraseInLine(params)
+		case 'G':
+			tp.cursor = min(max(csiParam(params, 1)-1, 0), maxLineWidth)
+		case 'C':
+			tp.cursor = min(tp.cursor+csiParam(params, 1), maxLineWidth)
+		case 'D':
+			tp.cursor = max(tp.cursor-csiParam(params, 1), 0)
+		}
+		// Cursor movement across lines, screen clearing, etc. are stripped
+		return i + 1, true
+	}
+	return 0, false
+}
+
+// eraseInLine implements CSI K (0: to end, 1: to start, 2: whole line)
+func (tp *TerminalProcessor) eraseInLine(params string) {
+	switch csiParam(params, 0) {
+	case 0:
+		if tp.cursor < len(tp.line) {
+			tp.line = tp.line[:tp.cursor]
+		}
+	case 1:
+		for i := 0; i < tp.cursor && i < len(tp.line); i++ {
+			tp.line[i] = terminalCell{r: ' '}
+		}
+	case 2:
+		tp.line = tp.line[:0]
+	}
+}
+
+// applySGR updates the current style from Select Graphic Rendition parameters
+func (tp *TerminalProcessor) applySGR(params string) {
+	if params == "" {
+		tp.style = textStyle{}
+		return
+	}
+
+	codes := strings.Split(strings.ReplaceAll(params, ":", ";"), ";")
+	for i := 0; i < len(codes); i++ {
+		code, err := strconv.Atoi(codes[i])
+		if err != nil && codes[i] != "" {
+			continue
+		}
+
+		switch {
+		case code == 0:
+			tp.style = textStyle{}
+		case code == 1:
+			tp.style.bold = true
+		case code == 2:
+			tp.style.dim = true
+		case code == 3:
+			tp.style.italic = true
+		case code == 4:
+			tp.style.underline = true
+		case code == 5 || code == 6:
+			tp.style.blink = true
+		case code == 7:
+			tp.style.reverse = true
+		case code == 9:
+			tp.style.strikethrough = true
+		case code == 22:
+			tp.style.bold, tp.style.dim = false, false
+		case code == 23:
+			tp.style.italic = false
+		case code == 24:
+			tp.style.underline = false
+		case code == 25:
+			tp.style.blink = false
+		case code == 27:
+			tp.style.reverse = false
+		case code == 29:
+			tp.style.strikethrough = false
+		case code >= 30 && code <= 37:
+			tp.style.fg = ansiBasicColors[code-30]
+		case code >= 40 && code <= 47:
+			tp.style.bg = ansiBasicColors[code-40]
+		case code >= 90 && code <= 97:
+			tp.style.fg = ansiBrightColors[code-90]
+		case code >= 100 && code <= 107:
+			tp.style.bg = ansiBrightColors[code-100]
+		case code == 39:
+			tp.style.fg = ""
+		case code == 49:
+			tp.style.bg = ""
+		case code == 38 || code == 48:
+			color, consumed := extendedColor(codes[i+1:])
+			i += consumed
+			if color == "" {
+				continue
+			}
+			if code == 38 {
+				tp.style.fg = color
+			} else {
+				tp.style.bg = color
+			}
+		}
+	}
+}
+
+// extendedColor parses the arguments of SGR 38/48 (5;n or 2;r;g;b) and
+// returns the color and the number of parameters consumed
+func extendedColor(args []string) (string, int) {
+	if len(args) == 0 {
+		return "", 0
+	}
+
+	switch args[0] {
+	case "5":
+		if len(args) < 2 {
+			return "", len(args)
+		}
+		index, err := strconv.Atoi(args[1])
+		if err != nil || index < 0 || index > 255 {
+			return "", 2
+		}
+		return paletteColor(index), 2
+	case "2":
+		if len(args) < 4 {
+			return "", len(args)
+		}
+		var rgb [3]int
+		for j := range rgb {
+			v, err := strconv.Atoi(args[j+1])
+			if err != nil {
+				return "", 4
+			}
+			rgb[j] = min(max(v, 0), 255)
+		}
+		return fmt.Sprintf("#%02x%02x%02x", rgb[0], rgb[1], rgb[2]), 4
+	}
+	return "", 1
+}
+
+// paletteColor converts an xterm 256-color palette index to a tview color
+func paletteColor(index int) string {
+	switch {
+	case index < 8:
+		return ansiBasicColors[index]
+	case index < 16:
+		return ansiBrightColors[index-8]
+	case index < 232:
+		// 6x6x6 color cube
+		index -= 16
+		levels := []int{0, 95, 135, 175, 215, 255}
+		return fmt.Sprintf("#%02x%02x%02x", levels[index/36], levels[(index/6)%6], levels[index%6])
+	default:
+		gray := 8 + (index-232)*10
+		return fmt.Sprintf("#%02x%02x%02x", gray, gray, gray)
+	}
+}
+
+// csiParam returns the first numeric CSI parameter, or def if absent
+func csiParam(params string, def int) int {
+	first := strings.SplitN(params, ";", 2)[0]
+	first = strings.TrimLeft(first, "?>=")
+	if first == "" {
+		return def
+	}
+	v, err := strconv.Atoi(first)
+	if err != nil || v < 0 {
+		return def
+	}
+	return v
+}
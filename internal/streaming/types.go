@@ -30,14 +30,16 @@ const (
 
 // StreamEvent represents an event in the streaming system
 type StreamEvent struct {
-	JobID      string          `json:"job_id"`
-	OutputType string          `json:"output_type"` // "stdout" or "stderr"
-	Content    string          `json:"content"`
-	NewLines   []string        `json:"new_lines"` // New lines added since last event
-	Timestamp  time.Time       `json:"timestamp"`
-	EventType  StreamEventType `json:"event_type"`
-	FileOffset int64           `json:"file_offset"` // Current file position
-	Error      error           `json:"error,omitempty"`
+	JobID       string          `json:"job_id"`
+	OutputType  string          `json:"output_type"`            // "stdout" or "stderr"
+	TaskID      string          `json:"task_id,omitempty"`      // Set for events from multi-task streams
+	Content     string          `json:"content"`                // New lines rendered as tview markup
+	NewLines    []string        `json:"new_lines"`              // New lines added since last event, as plain text
+	PartialLine string          `json:"partial_line,omitempty"` // Rendered line still being written (e.g. a progress bar)
+	Timestamp   time.Time       `json:"timestamp"`
+	EventType   StreamEventType `json:"event_type"`
+	FileOffset  int64           `json:"file_offset"` // Current file position
+	Error       error           `json:"error,omitempty"`
 }
 
 // SSHCommandExecutor executes commands on remote nodes via SSH.
@@ -48,24 +50,31 @@ type SSHCommandExecutor interface {
 
 // StreamManager manages real-time job output streaming
 type StreamManager struct {
-	client        dao.SlurmClient     // Uses SLURM API to get job metadata including file paths
-	sshManager    *ssh.SessionManager // For remote file access
-	sshExecutor   SSHCommandExecutor  // For direct SSH command execution on compute nodes
-	fileWatcher   *fsnotify.Watcher   // For local file watching
-	activeStreams map[string]*JobStream
-	eventBus      *EventBus
-	slurmConfig   *SlurmConfig // SLURM fallback paths and settings
-	pathResolver  *PathResolver
-	mu            sync.RWMutex
-	ctx           context.Context
-	cancel        context.CancelFunc
+	client             dao.SlurmClient     // Uses SLURM API to get job metadata including file paths
+	sshManager         *ssh.SessionManager // For remote file access
+	sshExecutor        SSHCommandExecutor  // For direct SSH command execution on compute nodes
+	fileWatcher        *fsnotify.Watcher   // For local file watching
+	activeStreams      map[string]*JobStream
+	eventBus           *EventBus
+	taskStreams        map[string]*MultiTaskStream // Aggregated multi-task streams
+	taskEventBus       *EventBus                   // Events of aggregated streams
+	maxConcurrentTails int                         // Bound on concurrent task file reads
+	progress           *ProgressTracker            // Progress readings extracted from job output
+	progressOffsets    map[string]progressOffset   // Output file sizes at the last progress sample
+	slurmConfig        *SlurmConfig                // SLURM fallback paths and settings
+	pathResolver       *PathResolver
+	mu                 sync.RWMutex
+	ctx                context.Context
+	cancel             context.CancelFunc
 }
 
 // JobStream represents an active streaming session for a job's output
 type JobStream struct {
 	JobID       string               // SLURM job ID
 	OutputType  string               // "stdout" or "stderr"
-	Buffer      *CircularBuffer      // Memory-efficient output storage
+	Buffer      *CircularBuffer      // Memory-efficient output storage, as plain text
+	Markup      *CircularBuffer      // Buffer's lines as tview markup; empty for lines without styling
+	Processor   *TerminalProcessor   // Converts ANSI/carriage-return output into display lines
 	FilePath    string               // Path to output file
 	LastOffset  int64                // File offset for tailing
 	IsActive    bool                 // Whether streaming is active
@@ -74,6 +83,7 @@ type JobStream struct {
 	Subscribers []chan<- StreamEvent // Event subscribers
 	FileWatcher *FileWatcher         // Individual file watcher
 	LastUpdate  time.Time            // Last update timestamp
+	linesMu     sync.RWMutex         // Keeps Buffer and Markup aligned
 	// TODO(lint): Review unused code - field mu is unused
 	// mu            sync.RWMutex         // Stream-specific mutex
 }
@@ -0,0 +1,240 @@
+package sweep
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jontk/s9s/internal/fileperms"
+)
+
+// groupNamePattern restricts group names to what can be typed as a command
+// argument and used in a file name
+var groupNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Group is a named set of jobs submitted together, such as the jobs of a
+// sweep
+type Group struct {
+	Name        string    `json:"name"`
+	Created     time.Time `json:"created"`
+	Mode        string    `json:"mode,omitempty"` // ModeJobs or ModeArray
+	Variables   []string  `json:"variables,omitempty"`
+	JobIDs      []string  `json:"job_ids"`
+	MappingFile string    `json:"mapping_file,omitempty"` // Array sweeps only
+}
+
+// ValidateGroupName checks that name can be used as a group name
+func ValidateGroupName(name string) error {
+	if !groupNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid group name %q: use letters, digits, '.', '-' and '_'", name)
+	}
+	return nil
+}
+
+// GroupStore keeps job groups in a JSON file, by default ~/.s9s/groups.json
+type GroupStore struct {
+	mu     sync.RWMutex
+	path   string
+	groups map[string]*Group
+	jobs   map[string]string // job ID -> group name
+}
+
+// DefaultGroupsPath returns the file job groups are stored in
+func DefaultGroupsPath() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".s9s", "groups.json")
+}
+
+// NewGroupStore creates a store for the file at path. Groups are not read
+// until Load is called.
+func NewGroupStore(path string) *GroupStore {
+	return &GroupStore{
+		path:   path,
+		groups: make(map[string]*Group),
+		jobs:   make(map[string]string),
+	}
+}
+
+// Load (re)reads the groups. A missing file is an empty store.
+func (s *GroupStore) Load() error {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	var list []*Group
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("%s: %w", s.path, err)
+	}
+
+	groups := make(map[string]*Group, len(list))
+	for _, group := range list {
+		if ValidateGroupName(group.Name) == nil {
+			groups[group.Name] = group
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.groups = groups
+	s.reindex()
+	return nil
+}
+
+// Create adds an empty group; the name must not be taken
+func (s *GroupStore) Create(group Group) error {
+	if err := ValidateGroupName(group.Name); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.groups[group.Name]; exists {
+		return fmt.Errorf("group %s already exists", group.Name)
+	}
+	if group.Created.IsZero() {
+		group.Created = time.Now()
+	}
+	group.JobIDs = append([]string(nil), group.JobIDs...)
+	s.groups[group.Name] = &group
+	s.reindex()
+	if err := s.write(); err != nil {
+		delete(s.groups, group.Name)
+		s.reindex()
+		return err
+	}
+	return nil
+}
+
+// AddJobs adds job IDs to the named group
+func (s *GroupStore) AddJobs(name string, ids ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	group, ok := s.groups[name]
+	if !ok {
+		return fmt.Errorf("group %s not found", name)
+	}
+	previous := len(group.JobIDs)
+	group.JobIDs = append(group.JobIDs, ids...)
+	s.reindex()
+	if err := s.write(); err != nil {
+		group.JobIDs = group.JobIDs[:previous]
+		s.reindex()
+		return err
+	}
+	return nil
+}
+
+// Get returns a copy of the named group
+func (s *GroupStore) Get(name string) (Group, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	group, ok := s.groups[name]
+	if !ok {
+		return Group{}, false
+	}
+	return copyGroup(group), true
+}
+
+// List returns every group, newest first
+func (s *GroupStore) List() []Group {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	groups := make([]Group, 0, len(s.groups))
+	for _, group := range s.groups {
+		groups = append(groups, copyGroup(group))
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if !groups[i].Created.Equal(groups[j].Created) {
+			return groups[i].Created.After(groups[j].Created)
+		}
+		return groups[i].Name < groups[j].Name
+	})
+	return groups
+}
+
+// Names returns the names of every group, sorted
+func (s *GroupStore) Names() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	names := make([]string, 0, len(s.groups))
+	for name := range s.groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Delete forgets the named group; its jobs are left alone
+func (s *GroupStore) Delete(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	group, ok := s.groups[name]
+	if !ok {
+		return fmt.Errorf("group %s not found", name)
+	}
+	delete(s.groups, name)
+	s.reindex()
+	if err := s.write(); err != nil {
+		s.groups[name] = group
+		s.reindex()
+		return err
+	}
+	return nil
+}
+
+// GroupOf returns the name of the group job ID belongs to, or ""
+func (s *GroupStore) GroupOf(id string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.jobs[id]
+}
+
+// reindex rebuilds the job ID index; callers hold s.mu
+func (s *GroupStore) reindex() {
+	s.jobs = make(map[string]string)
+	for name, group := range s.groups {
+		for _, id := range group.JobIDs {
+			s.jobs[id] = name
+		}
+	}
+}
+
+// write writes the groups to disk; callers hold s.mu
+func (s *GroupStore) write() error {
+	list := make([]*Group, 0, len(s.groups))
+	for _, group := range s.groups {
+		list = append(list, group)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), fileperms.ConfigDir); err != nil {
+		return fmt.Errorf("failed to create groups directory: %w", err)
+	}
+	return os.WriteFile(s.path, data, fileperms.ConfigFile)
+}
+
+// copyGroup copies group so callers cannot change the store
+func copyGroup(group *Group) Group {
+	c := *group
+	c.Variables = append([]string(nil), group.Variables...)
+	c.JobIDs = append([]string(nil), group.JobIDs...)
+	return c
+}
@@ -0,0 +1,51 @@
+package sweep
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "s9s", "groups.json")
+	store := NewGroupStore(path)
+	require.NoError(t, store.Load(), "a missing file is an empty store")
+	assert.Empty(t, store.List())
+
+	created := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, store.Create(Group{Name: "lr-sweep", Created: created, Mode: ModeJobs, Variables: []string{"lr"}}))
+	require.NoError(t, store.AddJobs("lr-sweep", "101", "102"))
+	require.NoError(t, store.Create(Group{Name: "ablation", Created: created.Add(time.Hour), Mode: ModeArray, JobIDs: []string{"200"}}))
+
+	assert.EqualError(t, store.Create(Group{Name: "lr-sweep"}), "group lr-sweep already exists")
+	assert.ErrorContains(t, store.Create(Group{Name: "two words"}), "invalid group name")
+	assert.EqualError(t, store.AddJobs("nope", "1"), "group nope not found")
+
+	// Read back from disk
+	store = NewGroupStore(path)
+	require.NoError(t, store.Load())
+	groups := store.List()
+	require.Len(t, groups, 2)
+	assert.Equal(t, "ablation", groups[0].Name, "newest first")
+	assert.Equal(t, []string{"101", "102"}, groups[1].JobIDs)
+	assert.True(t, created.Equal(groups[1].Created))
+	assert.Equal(t, []string{"ablation", "lr-sweep"}, store.Names())
+
+	assert.Equal(t, "lr-sweep", store.GroupOf("102"))
+	assert.Equal(t, "ablation", store.GroupOf("200"))
+	assert.Empty(t, store.GroupOf("999"))
+
+	group, ok := store.Get("lr-sweep")
+	require.True(t, ok)
+	group.JobIDs[0] = "changed"
+	assert.Equal(t, "lr-sweep", store.GroupOf("101"), "Get returns a copy")
+
+	require.NoError(t, store.Delete("lr-sweep"))
+	assert.Empty(t, store.GroupOf("101"))
+	assert.EqualError(t, store.Delete("lr-sweep"), "group lr-sweep not found")
+	_, ok = store.Get("lr-sweep")
+	assert.False(t, ok)
+}
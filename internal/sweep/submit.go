@@ -0,0 +1,44 @@
+package sweep
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jontk/s9s/internal/dao"
+)
+
+// Progress is called after each job of a sweep is submitted, with the
+// number of jobs done so far, the total and the job ID or error
+type Progress func(done, total int, id string, err error)
+
+// Submit submits subs one at a time, waiting interval between them so as
+// not to flood the scheduler, and returns the IDs of the submitted jobs.
+// It stops at the first error or when ctx is done; the IDs returned are
+// those of the jobs submitted until then.
+func Submit(ctx context.Context, jobs dao.JobManager, subs []*dao.JobSubmission, interval time.Duration, progress Progress) ([]string, error) {
+	ids := make([]string, 0, len(subs))
+	for i, sub := range subs {
+		if i > 0 && interval > 0 {
+			timer := time.NewTimer(interval)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ids, ctx.Err()
+			case <-timer.C:
+			}
+		} else if err := ctx.Err(); err != nil {
+			return ids, err
+		}
+
+		id, err := jobs.Submit(sub)
+		if progress != nil {
+			progress(i+1, len(subs), id, err)
+		}
+		if err != nil {
+			return ids, fmt.Errorf("job %d of %d (%s): %w", i+1, len(subs), sub.Name, err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
@@ -0,0 +1,326 @@
+// Package sweep expands a parameter grid or a CSV table into job
+// submissions: one job per combination of values, or one job array whose
+// tasks read their values from a mapping file. The jobs of a sweep are
+// submitted at a limited rate and tagged with a named group, so they can
+// be followed together.
+package sweep
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jontk/s9s/internal/dao"
+	"github.com/jontk/s9s/internal/jobtemplate"
+)
+
+// Modes of submitting a sweep
+const (
+	ModeJobs  = "jobs"  // One job per point
+	ModeArray = "array" // One array task per point
+)
+
+// variableName matches the names usable as {{ .name }} and $name
+var variableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Sweep is the set of points a job is submitted for: a value of every
+// variable per point
+type Sweep struct {
+	Names  []string            // Variables, in column order
+	Points []map[string]string // Values of the variables, one map per job
+}
+
+// ParseGrid parses a grid given as whitespace-separated axes, such as
+// "lr=1e-3,1e-4 seed=1..5", into the sweep over every combination of
+// their values. The last axis varies fastest.
+func ParseGrid(spec string) (*Sweep, error) {
+	s := &Sweep{Points: []map[string]string{{}}}
+	for _, field := range strings.Fields(spec) {
+		name, values, err := ParseAxis(field)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.addName(name); err != nil {
+			return nil, err
+		}
+		points := make([]map[string]string, 0, len(s.Points)*len(values))
+		for _, point := range s.Points {
+			for _, value := range values {
+				next := make(map[string]string, len(point)+1)
+				for k, v := range point {
+					next[k] = v
+				}
+				next[name] = value
+				points = append(points, next)
+			}
+		}
+		s.Points = points
+	}
+	if len(s.Names) == 0 {
+		return nil, errors.New("no parameters to sweep: use name=value1,value2 or name=first..last")
+	}
+	return s, nil
+}
+
+// ParseAxis parses one axis of a grid: name=v1,v2,... or an integer range
+// name=first..last, optionally with a step (name=0..100..10). Lists and
+// ranges can be mixed: seed=1..3,7.
+func ParseAxis(spec string) (string, []string, error) {
+	name, list, ok := strings.Cut(spec, "=")
+	if !ok || list == "" {
+		return "", nil, fmt.Errorf("invalid axis %q: use name=value1,value2 or name=first..last", spec)
+	}
+	if !variableName.MatchString(name) {
+		return "", nil, fmt.Errorf("invalid parameter name %q: use letters, digits and underscores", name)
+	}
+
+	var values []string
+	for _, item := range strings.Split(list, ",") {
+		if item == "" {
+			return "", nil, fmt.Errorf("axis %s: empty value", name)
+		}
+		if !strings.Contains(item, "..") {
+			values = append(values, item)
+			continue
+		}
+		expanded, err := expandRange(item)
+		if err != nil {
+			return "", nil, fmt.Errorf("axis %s: %w", name, err)
+		}
+		values = append(values, expanded...)
+	}
+	return name, values, nil
+}
+
+// expandRange expands first..last[..step]
+func expandRange(item string) ([]string, error) {
+	parts := strings.Split(item, "..")
+	if len(parts) > 3 {
+		return nil, fmt.Errorf("invalid range %q", item)
+	}
+	bounds := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid range %q: bounds and step must be integers", item)
+		}
+		bounds[i] = n
+	}
+	first, last, step := bounds[0], bounds[1], 1
+	if len(bounds) == 3 {
+		step = bounds[2]
+	}
+	if step <= 0 {
+		return nil, fmt.Errorf("invalid range %q: the step must be positive", item)
+	}
+	if last < first {
+		return nil, fmt.Errorf("invalid range %q: %d is less than %d", item, last, first)
+	}
+
+	values := make([]string, 0, (last-first)/step+1)
+	for n := first; n <= last; n += step {
+		values = append(values, strconv.Itoa(n))
+	}
+	return values, nil
+}
+
+// ReadCSV reads a sweep from CSV: a header row naming the variables, then
+// one row of values per point
+func ReadCSV(r io.Reader) (*Sweep, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("empty CSV: the first row must name the parameters")
+		}
+		return nil, err
+	}
+
+	s := &Sweep{}
+	for _, name := range header {
+		if !variableName.MatchString(name) {
+			return nil, fmt.Errorf("invalid parameter name %q in the CSV header: use letters, digits and underscores", name)
+		}
+		if err := s.addName(name); err != nil {
+			return nil, err
+		}
+	}
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range records {
+		point := make(map[string]string, len(header))
+		for i, name := range header {
+			point[name] = record[i]
+		}
+		s.Points = append(s.Points, point)
+	}
+	if len(s.Points) == 0 {
+		return nil, errors.New("the CSV has no rows of values")
+	}
+	return s, nil
+}
+
+// LoadCSV reads a sweep from a CSV file
+func LoadCSV(path string) (*Sweep, error) {
+	f, err := os.Open(jobtemplate.ExpandPath(path)) //nolint:gosec // the file is chosen by the user
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	s, err := ReadCSV(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+// addName adds a variable, rejecting duplicates
+func (s *Sweep) addName(name string) error {
+	for _, existing := range s.Names {
+		if existing == name {
+			return fmt.Errorf("parameter %s is given twice", name)
+		}
+	}
+	s.Names = append(s.Names, name)
+	return nil
+}
+
+// Label describes point i, such as "lr=1e-3 seed=2"
+func (s *Sweep) Label(i int) string {
+	parts := make([]string, len(s.Names))
+	for j, name := range s.Names {
+		parts[j] = name + "=" + s.Points[i][name]
+	}
+	return strings.Join(parts, " ")
+}
+
+// Jobs returns a job per point. The variables are substituted into the
+// text fields of base, which are Go templates ({{ .lr }}), and exported to
+// the script as shell variables ($lr).
+func (s *Sweep) Jobs(base *dao.JobSubmission) ([]*dao.JobSubmission, error) {
+	jobs := make([]*dao.JobSubmission, 0, len(s.Points))
+	for i, point := range s.Points {
+		data := make(map[string]any, len(point))
+		for name, value := range point {
+			data[name] = value
+		}
+		job := *base
+		if err := renderFields(&job, data, false); err != nil {
+			return nil, fmt.Errorf("job %d (%s): %w", i+1, s.Label(i), err)
+		}
+
+		exports := make([]string, len(s.Names))
+		for j, name := range s.Names {
+			exports[j] = name + "=" + jobtemplate.ShellQuote(point[name])
+		}
+		job.Script = insertPreamble(job.Script, "# s9s sweep variables\nexport "+strings.Join(exports, " "))
+		jobs = append(jobs, &job)
+	}
+	return jobs, nil
+}
+
+// Array returns a job array with a task per point and the content of its
+// mapping file, which the tasks read their variables from: a header row
+// and a tab-separated row per task. Only the script of base can use the
+// variables: {{ .lr }} becomes ${lr}. maxRunning limits how many tasks run
+// at once (0: no limit).
+func (s *Sweep) Array(base *dao.JobSubmission, mappingFile string, maxRunning int) (*dao.JobSubmission, []byte, error) {
+	if base.ArraySpec != "" {
+		return nil, nil, errors.New("the job is already a job array; clear its array field to sweep it as an array")
+	}
+
+	var mapping strings.Builder
+	mapping.WriteString("task\t" + strings.Join(s.Names, "\t") + "\n")
+	for i, point := range s.Points {
+		row := make([]string, len(s.Names))
+		for j, name := range s.Names {
+			value := point[name]
+			if value == "" || strings.ContainsAny(value, "\t\n\r") {
+				return nil, nil, fmt.Errorf("task %d: value %q of %s cannot be used in an array: values must be non-empty and on one line without tabs", i, value, name)
+			}
+			row[j] = value
+		}
+		fmt.Fprintf(&mapping, "%d\t%s\n", i, strings.Join(row, "\t"))
+	}
+
+	job := *base
+	data := make(map[string]any, len(s.Names))
+	for _, name := range s.Names {
+		data[name] = "${" + name + "}"
+	}
+	if err := renderFields(&job, data, true); err != nil {
+		return nil, nil, err
+	}
+
+	names := strings.Join(s.Names, " ")
+	job.Script = insertPreamble(job.Script, fmt.Sprintf(`# s9s sweep variables of array task $SLURM_ARRAY_TASK_ID
+IFS='	' read -r s9s_task %s <<S9S_SWEEP
+$(sed -n "$((SLURM_ARRAY_TASK_ID + 2))p" %s)
+S9S_SWEEP
+export %s`, names, jobtemplate.ShellQuote(mappingFile), names))
+
+	job.ArraySpec = fmt.Sprintf("0-%d", len(s.Points)-1)
+	if maxRunning > 0 {
+		job.ArraySpec += fmt.Sprintf("%%%d", maxRunning)
+	}
+	return &job, []byte(mapping.String()), nil
+}
+
+// MappingFile returns where the mapping file of an array sweep is written:
+// next to the job, in its working directory, which the tasks can read
+func MappingFile(workingDir, group string) string {
+	return filepath.Join(workingDir, group+".sweep.tsv")
+}
+
+// renderFields renders the Go templates in the text fields of job. With
+// scriptOnly, only the script may use them.
+func renderFields(job *dao.JobSubmission, data map[string]any, scriptOnly bool) error {
+	v := reflect.ValueOf(job).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := v.Field(i)
+		if field.Kind() != reflect.String || !strings.Contains(field.String(), "{{") {
+			continue
+		}
+		name := t.Field(i).Name
+		if scriptOnly && name != "Script" {
+			return fmt.Errorf("%s uses sweep parameters, which only the script can in an array; use $name in the script or submit individual jobs", name)
+		}
+		rendered, err := jobtemplate.Execute(name, field.String(), data)
+		if err != nil {
+			return err
+		}
+		field.SetString(rendered)
+	}
+	return nil
+}
+
+// insertPreamble inserts lines into script after its leading comments, so
+// that they follow the shebang and #SBATCH directives
+func insertPreamble(script, preamble string) string {
+	lines := strings.Split(script, "\n")
+	at := len(lines)
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			at = i
+			break
+		}
+	}
+	result := make([]string, 0, len(lines)+2)
+	result = append(result, lines[:at]...)
+	result = append(result, preamble, "")
+	result = append(result, lines[at:]...)
+	return strings.Join(result, "\n")
+}
@@ -0,0 +1,181 @@
+package sweep
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/jontk/s9s/internal/dao"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGrid(t *testing.T) {
+	s, err := ParseGrid("lr=1e-3,1e-4  seed=1..3")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"lr", "seed"}, s.Names)
+	require.Len(t, s.Points, 6)
+	assert.Equal(t, map[string]string{"lr": "1e-3", "seed": "1"}, s.Points[0])
+	assert.Equal(t, map[string]string{"lr": "1e-3", "seed": "2"}, s.Points[1])
+	assert.Equal(t, map[string]string{"lr": "1e-4", "seed": "3"}, s.Points[5])
+	assert.Equal(t, "lr=1e-4 seed=3", s.Label(5))
+
+	_, values, err := ParseAxis("n=0..10..5,64")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0", "5", "10", "64"}, values)
+
+	for spec, want := range map[string]string{
+		"":                 "no parameters to sweep",
+		"lr":               `invalid axis "lr"`,
+		"lr=":              `invalid axis "lr="`,
+		"1x=2":             `invalid parameter name "1x"`,
+		"lr=1,,2":          "axis lr: empty value",
+		"n=5..1":           `invalid range "5..1": 1 is less than 5`,
+		"n=1..5..0":        "the step must be positive",
+		"n=a..b":           "bounds and step must be integers",
+		"lr=1 seed=1 lr=2": "parameter lr is given twice",
+	} {
+		_, err := ParseGrid(spec)
+		assert.ErrorContains(t, err, want, spec)
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	s, err := ReadCSV(strings.NewReader("model, lr\nresnet, 0.1\n\"bert large\",0.01\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"model", "lr"}, s.Names)
+	assert.Equal(t, []map[string]string{
+		{"model": "resnet", "lr": "0.1"},
+		{"model": "bert large", "lr": "0.01"},
+	}, s.Points)
+
+	_, err = ReadCSV(strings.NewReader(""))
+	assert.ErrorContains(t, err, "empty CSV")
+	_, err = ReadCSV(strings.NewReader("model,lr\n"))
+	assert.ErrorContains(t, err, "no rows")
+	_, err = ReadCSV(strings.NewReader("model,learning rate\nx,1\n"))
+	assert.ErrorContains(t, err, `invalid parameter name "learning rate"`)
+	_, err = ReadCSV(strings.NewReader("model,lr\nx\n"))
+	assert.ErrorContains(t, err, "wrong number of fields")
+
+	path := filepath.Join(t.TempDir(), "points.csv")
+	require.NoError(t, os.WriteFile(path, []byte("seed\n1\n2\n"), 0o600))
+	s, err = LoadCSV(path)
+	require.NoError(t, err)
+	assert.Len(t, s.Points, 2)
+}
+
+func TestJobs(t *testing.T) {
+	s, err := ParseGrid("lr=0.1,0.01 seed=1..2")
+	require.NoError(t, err)
+	base := &dao.JobSubmission{
+		Name:      "train-{{ .lr }}-{{ .seed }}",
+		Partition: "gpu",
+		Script:    "#!/bin/bash\n#SBATCH --gres=gpu:1\n\npython train.py --lr $lr --seed {{ .seed }}\n",
+	}
+
+	jobs, err := s.Jobs(base)
+	require.NoError(t, err)
+	require.Len(t, jobs, 4)
+	assert.Equal(t, "train-0.1-1", jobs[0].Name)
+	assert.Equal(t, "train-0.01-2", jobs[3].Name)
+	assert.Equal(t, "gpu", jobs[3].Partition)
+	assert.Equal(t, "#!/bin/bash\n#SBATCH --gres=gpu:1\n\n# s9s sweep variables\nexport lr='0.01' seed='2'\n\npython train.py --lr $lr --seed 2\n", jobs[3].Script)
+	assert.Equal(t, "train-{{ .lr }}-{{ .seed }}", base.Name, "the base job is not changed")
+
+	base.Name = "train-{{ .momentum }}"
+	_, err = s.Jobs(base)
+	assert.ErrorContains(t, err, `job 1 (lr=0.1 seed=1): template: Name:1:9: executing "Name" at <.momentum>: map has no entry for key "momentum"`)
+}
+
+func TestArray(t *testing.T) {
+	s, err := ReadCSV(strings.NewReader("model,lr\nresnet,0.1\nbert large,0.01\n"))
+	require.NoError(t, err)
+	base := &dao.JobSubmission{
+		Name:   "train",
+		Script: "#!/bin/bash\necho \"{{ .model }} at $lr\"\n",
+	}
+
+	dir := t.TempDir()
+	mappingFile := MappingFile(dir, "ablation")
+	assert.Equal(t, filepath.Join(dir, "ablation.sweep.tsv"), mappingFile)
+
+	job, mapping, err := s.Array(base, mappingFile, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "0-1%1", job.ArraySpec)
+	assert.Equal(t, "task\tmodel\tlr\n0\tresnet\t0.1\n1\tbert large\t0.01\n", string(mapping))
+	assert.Contains(t, job.Script, "echo \"${model} at $lr\"")
+
+	// Every task reads its own row of the mapping file
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not available")
+	}
+	require.NoError(t, os.WriteFile(mappingFile, mapping, 0o600))
+	for task, want := range []string{"resnet at 0.1\n", "bert large at 0.01\n"} {
+		cmd := exec.Command("bash", "-c", job.Script) //nolint:gosec // test script
+		cmd.Env = append(os.Environ(), "SLURM_ARRAY_TASK_ID="+strconv.Itoa(task))
+		out, err := cmd.Output()
+		require.NoError(t, err)
+		assert.Equal(t, want, string(out))
+	}
+}
+
+func TestArrayErrors(t *testing.T) {
+	s, err := ParseGrid("lr=0.1,0.01")
+	require.NoError(t, err)
+
+	_, _, err = s.Array(&dao.JobSubmission{ArraySpec: "1-4"}, "map.tsv", 0)
+	assert.ErrorContains(t, err, "already a job array")
+	_, _, err = s.Array(&dao.JobSubmission{Name: "train-{{ .lr }}"}, "map.tsv", 0)
+	assert.ErrorContains(t, err, "Name uses sweep parameters")
+
+	s.Points[1]["lr"] = "a\tb"
+	_, _, err = s.Array(&dao.JobSubmission{}, "map.tsv", 0)
+	assert.ErrorContains(t, err, `task 1: value "a\tb" of lr cannot be used in an array`)
+}
+
+// submitter is a job manager that only submits
+type submitter struct {
+	dao.JobManager
+	submitted []string
+	fail      string
+}
+
+func (s *submitter) Submit(job *dao.JobSubmission) (string, error) {
+	if job.Name == s.fail {
+		return "", errors.New("QOS limit reached")
+	}
+	s.submitted = append(s.submitted, job.Name)
+	return "10" + job.Name, nil
+}
+
+func TestSubmit(t *testing.T) {
+	subs := []*dao.JobSubmission{{Name: "1"}, {Name: "2"}, {Name: "3"}}
+	jobs := &submitter{}
+	var progress []int
+	ids, err := Submit(context.Background(), jobs, subs, 0, func(done, total int, _ string, _ error) {
+		assert.Equal(t, 3, total)
+		progress = append(progress, done)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"101", "102", "103"}, ids)
+	assert.Equal(t, []int{1, 2, 3}, progress)
+
+	// Stops at the first error
+	jobs = &submitter{fail: "2"}
+	ids, err = Submit(context.Background(), jobs, subs, 0, nil)
+	assert.EqualError(t, err, "job 2 of 3 (2): QOS limit reached")
+	assert.Equal(t, []string{"101"}, ids)
+	assert.Equal(t, []string{"1"}, jobs.submitted)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	ids, err = Submit(ctx, &submitter{}, subs, 0, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, ids)
+}
@@ -0,0 +1,93 @@
+package timeseries
+
+import (
+	"strings"
+	"time"
+
+	"github.com/jontk/s9s/internal/dao"
+)
+
+// Names of the cluster series recorded by the observers
+const (
+	SeriesJobsRunning     = "jobs.running"
+	SeriesJobsPending     = "jobs.pending"
+	SeriesNodesDown       = "nodes.down"
+	SeriesCPUAllocated    = "cpu.allocated"    // percent of CPUs allocated
+	SeriesMemoryAllocated = "memory.allocated" // percent of memory allocated
+
+	partitionPendingPrefix = "partition.pending."
+)
+
+// PartitionPendingSeries returns the name of the queue depth series of a
+// partition
+func PartitionPendingSeries(partition string) string {
+	return partitionPendingPrefix + partition
+}
+
+// ObserveJobs records job counts and per-partition queue depth from an
+// unfiltered job list. Partitions that have had pending jobs before are
+// recorded as zero once their queue drains.
+func (s *Store) ObserveJobs(jobs []*dao.Job, t time.Time) {
+	running, pending := 0, 0
+	queued := make(map[string]int)
+	for _, job := range jobs {
+		switch job.State {
+		case dao.JobStateRunning:
+			running++
+		case dao.JobStatePending:
+			pending++
+			queued[job.Partition]++
+		}
+	}
+
+	s.Record(SeriesJobsRunning, t, float64(running))
+	s.Record(SeriesJobsPending, t, float64(pending))
+	for _, name := range s.Names() {
+		if partition, ok := strings.CutPrefix(name, partitionPendingPrefix); ok && queued[partition] == 0 {
+			s.Record(name, t, 0)
+		}
+	}
+	for partition, count := range queued {
+		s.Record(PartitionPendingSeries(partition), t, float64(count))
+	}
+}
+
+// ObserveNodes records down nodes and CPU and memory allocation from an
+// unfiltered node list
+func (s *Store) ObserveNodes(nodes []*dao.Node, t time.Time) {
+	down := 0
+	var totalCPUs, allocCPUs int
+	var totalMem, allocMem int64
+	for _, node := range nodes {
+		if node.ParsedState().IsDown() {
+			down++
+		}
+		totalCPUs += node.CPUsTotal
+		allocCPUs += node.CPUsAllocated
+		totalMem += node.MemoryTotal
+		allocMem += node.MemoryAllocated
+	}
+
+	s.Record(SeriesNodesDown, t, float64(down))
+	if totalCPUs > 0 {
+		s.Record(SeriesCPUAllocated, t, float64(allocCPUs)*100/float64(totalCPUs))
+	}
+	if totalMem > 0 {
+		s.Record(SeriesMemoryAllocated, t, float64(allocMem)*100/float64(totalMem))
+	}
+}
+
+// ObserveStats records the cluster-wide job counts and utilization
+func (s *Store) ObserveStats(metrics *dao.ClusterMetrics, t time.Time) {
+	if metrics == nil {
+		return
+	}
+	s.Record(SeriesJobsRunning, t, float64(metrics.RunningJobs))
+	s.Record(SeriesJobsPending, t, float64(metrics.PendingJobs))
+	if metrics.CPUUsage >= 0 {
+		s.Record(SeriesCPUAllocated, t, metrics.CPUUsage)
+	}
+	if metrics.MemoryUsage >= 0 {
+		s.Record(SeriesMemoryAllocated, t, metrics.MemoryUsage)
+	}
+}
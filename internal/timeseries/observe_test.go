@@ -0,0 +1,82 @@
+package timeseries
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jontk/s9s/internal/dao"
+	"github.com/jontk/s9s/pkg/slurm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func latestValue(t *testing.T, s *Store, name string) float64 {
+	t.Helper()
+	p, ok := s.Latest(name)
+	require.True(t, ok, name)
+	return p.Value
+}
+
+func TestObserveJobs(t *testing.T) {
+	s := NewStore(time.Minute, time.Hour)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	s.ObserveJobs([]*dao.Job{
+		{State: dao.JobStateRunning, Partition: "cpu"},
+		{State: dao.JobStatePending, Partition: "cpu"},
+		{State: dao.JobStatePending, Partition: "gpu"},
+		{State: dao.JobStatePending, Partition: "gpu"},
+		{State: dao.JobStateCompleted, Partition: "gpu"},
+	}, now)
+
+	assert.Equal(t, 1.0, latestValue(t, s, SeriesJobsRunning))
+	assert.Equal(t, 3.0, latestValue(t, s, SeriesJobsPending))
+	assert.Equal(t, 1.0, latestValue(t, s, PartitionPendingSeries("cpu")))
+	assert.Equal(t, 2.0, latestValue(t, s, PartitionPendingSeries("gpu")))
+
+	// A drained queue is recorded as zero rather than left stale
+	s.ObserveJobs([]*dao.Job{{State: dao.JobStatePending, Partition: "cpu"}}, now.Add(time.Minute))
+	assert.Equal(t, 0.0, latestValue(t, s, PartitionPendingSeries("gpu")))
+}
+
+func TestObserveNodes(t *testing.T) {
+	s := NewStore(time.Minute, time.Hour)
+	s.ObserveNodes([]*dao.Node{
+		{State: "MIXED", CPUsTotal: 10, CPUsAllocated: 5, MemoryTotal: 100, MemoryAllocated: 25},
+		{State: "IDLE+NOT_RESPONDING", CPUsTotal: 10},
+		{State: "DOWN+DRAIN", CPUsTotal: 0},
+	}, time.Now())
+
+	assert.Equal(t, 2.0, latestValue(t, s, SeriesNodesDown))
+	assert.Equal(t, 25.0, latestValue(t, s, SeriesCPUAllocated))
+	assert.Equal(t, 25.0, latestValue(t, s, SeriesMemoryAllocated))
+}
+
+func TestRecordingClient(t *testing.T) {
+	mock := slurm.NewMockClient()
+	mock.SetDelay(0)
+	s := NewStore(time.Minute, time.Hour)
+	client := NewRecordingClient(mock, s)
+
+	_, err := client.Jobs().List(&dao.ListJobsOptions{States: []string{dao.JobStateRunning}})
+	require.NoError(t, err)
+	_, err = client.Nodes().List(&dao.ListNodesOptions{Partitions: []string{"gpu"}})
+	require.NoError(t, err)
+	assert.Empty(t, s.Names(), "filtered listings are not recorded")
+
+	page, err := client.Jobs().List(&dao.ListJobsOptions{Limit: 1})
+	require.NoError(t, err)
+	require.Greater(t, page.Total, len(page.Jobs))
+	assert.Empty(t, s.Names(), "pages cut short by their limit are not recorded")
+
+	_, err = client.Jobs().List(&dao.ListJobsOptions{Limit: 100000})
+	require.NoError(t, err)
+	_, err = client.Nodes().List(&dao.ListNodesOptions{})
+	require.NoError(t, err)
+	assert.Contains(t, s.Names(), SeriesJobsRunning)
+	assert.Contains(t, s.Names(), SeriesNodesDown)
+
+	unwrapper, ok := client.(interface{ Unwrap() dao.SlurmClient })
+	require.True(t, ok)
+	assert.Same(t, mock, unwrapper.Unwrap())
+}
@@ -0,0 +1,101 @@
+package timeseries
+
+import (
+	"time"
+
+	"github.com/jontk/s9s/internal/dao"
+)
+
+// recordingClient wraps a SlurmClient and feeds every unfiltered job and
+// node listing and every stats fetch into a store
+type recordingClient struct {
+	dao.SlurmClient
+	store *Store
+}
+
+// NewRecordingClient returns a client that records cluster series into
+// store as a side effect of the requests made through it
+func NewRecordingClient(client dao.SlurmClient, store *Store) dao.SlurmClient {
+	return &recordingClient{SlurmClient: client, store: store}
+}
+
+// Unwrap returns the wrapped client
+func (c *recordingClient) Unwrap() dao.SlurmClient {
+	return c.SlurmClient
+}
+
+func (c *recordingClient) Jobs() dao.JobManager {
+	return &recordingJobManager{JobManager: c.SlurmClient.Jobs(), store: c.store}
+}
+
+func (c *recordingClient) Nodes() dao.NodeManager {
+	return &recordingNodeManager{NodeManager: c.SlurmClient.Nodes(), store: c.store}
+}
+
+func (c *recordingClient) Info() dao.InfoManager {
+	info := c.SlurmClient.Info()
+	if info == nil {
+		return nil
+	}
+	return &recordingInfoManager{InfoManager: info, store: c.store}
+}
+
+// recordingJobManager records job series from unfiltered listings
+type recordingJobManager struct {
+	dao.JobManager
+	store *Store
+}
+
+func (m *recordingJobManager) List(opts *dao.ListJobsOptions) (*dao.JobList, error) {
+	list, err := m.JobManager.List(opts)
+	if err == nil && list != nil && coversWholeQueue(opts, list) {
+		m.store.ObserveJobs(list.Jobs, time.Now())
+	}
+	return list, err
+}
+
+// coversWholeQueue reports whether a listing holds every job in the queue:
+// it is not filtered and not a page cut short by its limit
+func coversWholeQueue(opts *dao.ListJobsOptions, list *dao.JobList) bool {
+	if opts == nil {
+		return true
+	}
+	if len(opts.States) > 0 || len(opts.Users) > 0 || len(opts.Partitions) > 0 ||
+		len(opts.Accounts) > 0 || opts.ArrayJobID != "" || opts.Offset > 0 {
+		return false
+	}
+	return opts.Limit == 0 || len(list.Jobs) >= list.Total
+}
+
+// recordingNodeManager records node series from unfiltered listings
+type recordingNodeManager struct {
+	dao.NodeManager
+	store *Store
+}
+
+func (m *recordingNodeManager) List(opts *dao.ListNodesOptions) (*dao.NodeList, error) {
+	list, err := m.NodeManager.List(opts)
+	if err == nil && list != nil && isUnfilteredNodeList(opts) {
+		m.store.ObserveNodes(list.Nodes, time.Now())
+	}
+	return list, err
+}
+
+// isUnfilteredNodeList reports whether a listing covers every node
+func isUnfilteredNodeList(opts *dao.ListNodesOptions) bool {
+	return opts == nil || (len(opts.States) == 0 && len(opts.Partitions) == 0 && len(opts.Features) == 0)
+}
+
+// recordingInfoManager records cluster-wide series from stats fetches
+type recordingInfoManager struct {
+	dao.InfoManager
+	store *Store
+}
+
+func (m *recordingInfoManager) GetStats() (*dao.ClusterMetrics, error) {
+	metrics, err := m.InfoManager.GetStats()
+	if err == nil {
+		m.store.ObserveStats(metrics, time.Now())
+	}
+	return metrics, err
+}
@@ -0,0 +1,68 @@
+package timeseries
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// sparkChars are the block characters used for sparklines, lowest first
+var sparkChars = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline renders values as a line of block characters at most width
+// wide. Longer inputs are averaged down to width buckets, and the chart is
+// scaled between the minimum and maximum value.
+func Sparkline(values []float64, width int) string {
+	values = Resample(values, width)
+	if len(values) == 0 {
+		return ""
+	}
+
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		lo = math.Min(lo, v)
+		hi = math.Max(hi, v)
+	}
+
+	var b strings.Builder
+	for _, v := range values {
+		level := 0
+		if hi > lo {
+			level = int((v - lo) / (hi - lo) * float64(len(sparkChars)-1))
+		}
+		b.WriteRune(sparkChars[level])
+	}
+	return b.String()
+}
+
+// Resample averages values down to at most width buckets
+func Resample(values []float64, width int) []float64 {
+	if width <= 0 || len(values) <= width {
+		return values
+	}
+
+	out := make([]float64, width)
+	for i := range out {
+		from := i * len(values) / width
+		to := (i + 1) * len(values) / width
+		sum := 0.0
+		for _, v := range values[from:to] {
+			sum += v
+		}
+		out[i] = sum / float64(to-from)
+	}
+	return out
+}
+
+// FormatDelta formats a change with an explicit sign and trend arrow,
+// e.g. "↑+12", "↓-3.5" or "→0"
+func FormatDelta(delta float64, precision int) string {
+	switch {
+	case delta > 0:
+		return fmt.Sprintf("↑+%.*f", precision, delta)
+	case delta < 0:
+		return fmt.Sprintf("↓%.*f", precision, delta)
+	default:
+		return "→0"
+	}
+}
@@ -0,0 +1,26 @@
+package timeseries
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSparkline(t *testing.T) {
+	assert.Equal(t, "", Sparkline(nil, 10))
+	assert.Equal(t, "▁▁▁", Sparkline([]float64{4, 4, 4}, 10))
+	assert.Equal(t, "▁▄█", Sparkline([]float64{0, 50, 100}, 10))
+	assert.Equal(t, 5, len([]rune(Sparkline(make([]float64, 60), 5))))
+}
+
+func TestResample(t *testing.T) {
+	assert.Equal(t, []float64{1, 2}, Resample([]float64{1, 2}, 5))
+	assert.Equal(t, []float64{1.5, 3.5}, Resample([]float64{1, 2, 3, 4}, 2))
+	assert.Equal(t, []float64{1, 2.5}, Resample([]float64{1, 2, 3}, 2))
+}
+
+func TestFormatDelta(t *testing.T) {
+	assert.Equal(t, "↑+12", FormatDelta(12, 0))
+	assert.Equal(t, "↓-3.5", FormatDelta(-3.5, 1))
+	assert.Equal(t, "→0", FormatDelta(0, 1))
+}
@@ -0,0 +1,252 @@
+// Package timeseries keeps a short in-process history of cluster metrics so
+// views can render trends without an external metrics backend.
+package timeseries
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jontk/s9s/internal/fileperms"
+)
+
+// Default store settings: one sample per minute for a day
+const (
+	DefaultResolution = time.Minute
+	DefaultRetention  = 24 * time.Hour
+)
+
+// Point is one sample of a series
+type Point struct {
+	Time  time.Time `json:"t"`
+	Value float64   `json:"v"`
+}
+
+// ring is a fixed-capacity circular buffer of points in time order
+type ring struct {
+	points []Point
+	start  int
+	size   int
+}
+
+func newRing(capacity int) *ring {
+	return &ring{points: make([]Point, capacity)}
+}
+
+// at returns the i-th oldest point
+func (r *ring) at(i int) Point {
+	return r.points[(r.start+i)%len(r.points)]
+}
+
+// last returns a pointer to the newest point
+func (r *ring) last() *Point {
+	return &r.points[(r.start+r.size-1)%len(r.points)]
+}
+
+// push appends a point, overwriting the oldest one when full
+func (r *ring) push(p Point) {
+	if r.size < len(r.points) {
+		r.points[(r.start+r.size)%len(r.points)] = p
+		r.size++
+		return
+	}
+	r.points[r.start] = p
+	r.start = (r.start + 1) % len(r.points)
+}
+
+// Store holds named series downsampled to a fixed resolution. Samples that
+// fall into the same bucket replace each other, so the newest value wins.
+// It is safe for concurrent use.
+type Store struct {
+	mu         sync.RWMutex
+	resolution time.Duration
+	retention  time.Duration
+	series     map[string]*ring
+}
+
+// NewStore creates a store keeping retention worth of samples per series
+// at the given resolution. Non-positive values select the defaults.
+func NewStore(resolution, retention time.Duration) *Store {
+	if resolution <= 0 {
+		resolution = DefaultResolution
+	}
+	if retention < resolution {
+		retention = DefaultRetention
+	}
+	return &Store{
+		resolution: resolution,
+		retention:  retention,
+		series:     make(map[string]*ring),
+	}
+}
+
+// Resolution returns the bucket size of the store
+func (s *Store) Resolution() time.Duration {
+	return s.resolution
+}
+
+// capacity returns the number of points kept per series
+func (s *Store) capacity() int {
+	return int(s.retention / s.resolution)
+}
+
+// Record adds a sample. Samples older than the newest point of the series
+// are ignored.
+func (s *Store) Record(name string, t time.Time, value float64) {
+	bucket := t.Truncate(s.resolution)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.series[name]
+	if !ok {
+		r = newRing(s.capacity())
+		s.series[name] = r
+	}
+	if r.size > 0 {
+		last := r.last()
+		if last.Time.Equal(bucket) {
+			last.Value = value
+			return
+		}
+		if bucket.Before(last.Time) {
+			return
+		}
+	}
+	r.push(Point{Time: bucket, Value: value})
+}
+
+// Series returns all points of a series, oldest first
+func (s *Store) Series(name string) []Point {
+	return s.Since(name, time.Time{})
+}
+
+// Since returns the points of a series at or after since, oldest first
+func (s *Store) Since(name string, since time.Time) []Point {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	r, ok := s.series[name]
+	if !ok {
+		return nil
+	}
+	var points []Point
+	for i := 0; i < r.size; i++ {
+		if p := r.at(i); !p.Time.Before(since) {
+			points = append(points, p)
+		}
+	}
+	return points
+}
+
+// Values returns the values of a series at or after since, oldest first
+func (s *Store) Values(name string, since time.Time) []float64 {
+	points := s.Since(name, since)
+	values := make([]float64, len(points))
+	for i, p := range points {
+		values[i] = p.Value
+	}
+	return values
+}
+
+// Latest returns the newest point of a series
+func (s *Store) Latest(name string) (Point, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	r, ok := s.series[name]
+	if !ok || r.size == 0 {
+		return Point{}, false
+	}
+	return *r.last(), true
+}
+
+// Delta returns the change of a series over the window d ending at its
+// newest point. It reports false until the series has a point inside the
+// window other than the newest one.
+func (s *Store) Delta(name string, d time.Duration) (float64, bool) {
+	latest, ok := s.Latest(name)
+	if !ok {
+		return 0, false
+	}
+	points := s.Since(name, latest.Time.Add(-d))
+	if len(points) < 2 {
+		return 0, false
+	}
+	return latest.Value - points[0].Value, true
+}
+
+// Names returns the names of all series, sorted
+func (s *Store) Names() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	names := make([]string, 0, len(s.series))
+	for name := range s.series {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// snapshot is the on-disk form of a store
+type snapshot struct {
+	Resolution string             `json:"resolution"`
+	Series     map[string][]Point `json:"series"`
+}
+
+// Save writes all series to path as JSON
+func (s *Store) Save(path string) error {
+	snap := snapshot{
+		Resolution: s.resolution.String(),
+		Series:     make(map[string][]Point),
+	}
+	for _, name := range s.Names() {
+		snap.Series[name] = s.Series(name)
+	}
+
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), fileperms.ConfigDir); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, fileperms.ConfigFile); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Load merges the series saved at path into the store, dropping points
+// older than the retention window. A missing file is not an error.
+func (s *Store) Load(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var snap snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return err
+	}
+
+	cutoff := time.Now().Add(-s.retention)
+	for name, points := range snap.Series {
+		for _, p := range points {
+			if p.Time.Before(cutoff) {
+				continue
+			}
+			s.Record(name, p.Time, p.Value)
+		}
+	}
+	return nil
+}
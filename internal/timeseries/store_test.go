@@ -0,0 +1,85 @@
+package timeseries
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreRecordBucketsSamples(t *testing.T) {
+	s := NewStore(time.Minute, time.Hour)
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	s.Record("x", base, 1)
+	s.Record("x", base.Add(20*time.Second), 2) // same bucket, newest wins
+	s.Record("x", base.Add(time.Minute), 3)
+	s.Record("x", base.Add(-time.Minute), 9) // older than newest point, ignored
+
+	points := s.Series("x")
+	require.Len(t, points, 2)
+	assert.Equal(t, Point{Time: base, Value: 2}, points[0])
+	assert.Equal(t, Point{Time: base.Add(time.Minute), Value: 3}, points[1])
+}
+
+func TestStoreRingDropsOldest(t *testing.T) {
+	s := NewStore(time.Minute, 5*time.Minute)
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i := range 8 {
+		s.Record("x", base.Add(time.Duration(i)*time.Minute), float64(i))
+	}
+
+	assert.Equal(t, []float64{3, 4, 5, 6, 7}, s.Values("x", time.Time{}))
+	assert.Equal(t, []float64{6, 7}, s.Values("x", base.Add(6*time.Minute)))
+
+	latest, ok := s.Latest("x")
+	require.True(t, ok)
+	assert.Equal(t, 7.0, latest.Value)
+}
+
+func TestStoreDelta(t *testing.T) {
+	s := NewStore(time.Minute, 24*time.Hour)
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	_, ok := s.Delta("x", time.Hour)
+	assert.False(t, ok, "no data")
+
+	s.Record("x", base, 10)
+	_, ok = s.Delta("x", time.Hour)
+	assert.False(t, ok, "a single point has no change")
+
+	s.Record("x", base.Add(30*time.Minute), 4)
+	s.Record("x", base.Add(2*time.Hour), 15)
+
+	delta, ok := s.Delta("x", time.Hour)
+	assert.False(t, ok, "no earlier point within the last hour")
+	assert.Zero(t, delta)
+
+	delta, ok = s.Delta("x", 90*time.Minute)
+	require.True(t, ok)
+	assert.Equal(t, 11.0, delta)
+
+	delta, ok = s.Delta("x", 24*time.Hour)
+	require.True(t, ok)
+	assert.Equal(t, 5.0, delta, "shorter history yields the change over the history available")
+}
+
+func TestStoreSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history", "default.json")
+	now := time.Now().Truncate(time.Minute)
+
+	s := NewStore(time.Minute, time.Hour)
+	s.Record(SeriesJobsRunning, now.Add(-2*time.Hour), 1) // evicted by retention on load
+	s.Record(SeriesJobsRunning, now.Add(-time.Minute), 5)
+	s.Record(SeriesJobsRunning, now, 7)
+	require.NoError(t, s.Save(path))
+
+	loaded := NewStore(time.Minute, time.Hour)
+	require.NoError(t, loaded.Load(path))
+	assert.Equal(t, []string{SeriesJobsRunning}, loaded.Names())
+	assert.Equal(t, []float64{5, 7}, loaded.Values(SeriesJobsRunning, time.Time{}))
+
+	assert.NoError(t, NewStore(0, 0).Load(filepath.Join(t.TempDir(), "missing.json")))
+}
@@ -0,0 +1,129 @@
+package topology
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// confEntry is one SwitchName or BlockName line of a topology.conf
+type confEntry struct {
+	name     string
+	nodes    string
+	switches string
+	line     int
+}
+
+// LoadConf reads a topology.conf file
+func LoadConf(path string) (*Topology, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	topo, err := ParseConf(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return topo, nil
+}
+
+// ParseConf parses the SwitchName lines of a topology/tree configuration or
+// the BlockName lines of a topology/block configuration. Every switch or
+// block with Nodes becomes a group, placed under the switches that list it.
+func ParseConf(r io.Reader) (*Topology, error) {
+	var entries []*confEntry
+	byName := make(map[string]*confEntry)
+
+	scanner := bufio.NewScanner(r)
+	lineNo, pending := 0, ""
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if strings.HasSuffix(line, "\\") {
+			pending += strings.TrimSuffix(line, "\\") + " "
+			continue
+		}
+		line, pending = pending+line, ""
+		if line == "" {
+			continue
+		}
+
+		entry := &confEntry{line: lineNo}
+		for _, field := range strings.Fields(line) {
+			key, value, ok := strings.Cut(field, "=")
+			if !ok {
+				return nil, fmt.Errorf("line %d: expected KEY=VALUE, got %q", lineNo, field)
+			}
+			switch strings.ToLower(key) {
+			case "switchname", "blockname":
+				entry.name = value
+			case "nodes":
+				entry.nodes = value
+			case "switches":
+				entry.switches = value
+			}
+		}
+		if entry.name == "" {
+			// BlockSizes and other global settings
+			continue
+		}
+		if _, dup := byName[entry.name]; dup {
+			return nil, fmt.Errorf("line %d: %s is defined twice", lineNo, entry.name)
+		}
+		entries = append(entries, entry)
+		byName[entry.name] = entry
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	parent := make(map[string]string)
+	for _, entry := range entries {
+		if entry.switches == "" {
+			continue
+		}
+		children, err := ExpandHostlist(entry.switches)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", entry.line, err)
+		}
+		for _, child := range children {
+			parent[child] = entry.name
+		}
+	}
+
+	topo := &Topology{Source: SourceFile}
+	for _, entry := range entries {
+		if entry.nodes == "" {
+			continue
+		}
+		nodes, err := ExpandHostlist(entry.nodes)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", entry.line, err)
+		}
+		topo.Groups = append(topo.Groups, &Group{
+			Name:  entry.name,
+			Path:  ancestors(entry.name, parent),
+			Nodes: nodes,
+		})
+	}
+	return topo, nil
+}
+
+// ancestors returns the switches above name, from the root down
+func ancestors(name string, parent map[string]string) []string {
+	var path []string
+	seen := map[string]bool{name: true}
+	for p, ok := parent[name]; ok && !seen[p]; p, ok = parent[p] {
+		seen[p] = true
+		path = append([]string{p}, path...)
+	}
+	return path
+}
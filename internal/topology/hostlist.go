@@ -0,0 +1,97 @@
+package topology
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// maxHostlistNames bounds how many names a hostlist expression may expand to
+const maxHostlistNames = 1_000_000
+
+// ExpandHostlist expands a SLURM hostlist expression such as
+// "node[001-040,045],gpu[01-04]" into host names, keeping zero padding
+func ExpandHostlist(expr string) ([]string, error) {
+	var names []string
+	for _, part := range splitHostlist(expr) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		expanded, err := expandHostPart(part, maxHostlistNames-len(names))
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, expanded...)
+	}
+	return names, nil
+}
+
+// splitHostlist splits a hostlist on commas outside brackets
+func splitHostlist(expr string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range expr {
+		switch r {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, expr[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, expr[start:])
+}
+
+// expandHostPart expands one hostlist element, which may hold several
+// bracketed ranges such as "r[1-2]n[01-04]"
+func expandHostPart(part string, limit int) ([]string, error) {
+	open := strings.IndexByte(part, '[')
+	if open < 0 {
+		if strings.ContainsRune(part, ']') {
+			return nil, fmt.Errorf("unbalanced brackets in %q", part)
+		}
+		return []string{part}, nil
+	}
+	closing := strings.IndexByte(part[open:], ']')
+	if closing < 0 {
+		return nil, fmt.Errorf("unbalanced brackets in %q", part)
+	}
+	closing += open
+
+	prefix, ranges, rest := part[:open], part[open+1:closing], part[closing+1:]
+	suffixes, err := expandHostPart(rest, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, r := range strings.Split(ranges, ",") {
+		first, last, isRange := strings.Cut(strings.TrimSpace(r), "-")
+		start, err := strconv.Atoi(first)
+		if err != nil || start < 0 {
+			return nil, fmt.Errorf("invalid range %q in %q", r, part)
+		}
+		end := start
+		if isRange {
+			if end, err = strconv.Atoi(last); err != nil || end < start {
+				return nil, fmt.Errorf("invalid range %q in %q", r, part)
+			}
+		}
+		if len(names)+(end-start+1)*len(suffixes) > limit {
+			return nil, fmt.Errorf("hostlist %q has more than %d names", part, maxHostlistNames)
+		}
+
+		width := len(first)
+		for i := start; i <= end; i++ {
+			for _, suffix := range suffixes {
+				names = append(names, fmt.Sprintf("%s%0*d%s", prefix, width, i, suffix))
+			}
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,24 @@
+package topology
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandHostlist(t *testing.T) {
+	names, err := ExpandHostlist("node[001-003,010],gpu01,r[1-2]n[1-2]")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"node001", "node002", "node003", "node010", "gpu01",
+		"r1n1", "r1n2", "r2n1", "r2n2",
+	}, names)
+}
+
+func TestExpandHostlist_Errors(t *testing.T) {
+	for _, expr := range []string{"node[1-", "node1]", "node[3-1]", "node[a-b]", "node[1-999999999]"} {
+		_, err := ExpandHostlist(expr)
+		assert.Error(t, err, expr)
+	}
+}
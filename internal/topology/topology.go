@@ -0,0 +1,227 @@
+// Package topology places nodes in racks and switches for the topology view.
+// Placement comes from a rack map in the config, an imported topology.conf,
+// the topology SLURM reports for each node, or the node name prefixes.
+package topology
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jontk/s9s/internal/config"
+	"github.com/jontk/s9s/internal/dao"
+)
+
+// Topology sources
+const (
+	SourceAuto   = "auto"
+	SourceRacks  = "racks"
+	SourceFile   = "file"
+	SourceSlurm  = "slurm"
+	SourcePrefix = "prefix"
+)
+
+// UnplacedGroup names the group of nodes that the source does not place
+const UnplacedGroup = "(unplaced)"
+
+// Group is a rack, leaf switch or block and the nodes in it
+type Group struct {
+	Name  string
+	Path  []string // Switches above the group, from the root down
+	Nodes []string
+}
+
+// Label returns the group name prefixed by its switch path, e.g.
+// "core/leaf01"
+func (g *Group) Label() string {
+	return strings.Join(append(slices.Clone(g.Path), g.Name), "/")
+}
+
+// Topology is the ordered list of node groups of a cluster
+type Topology struct {
+	Source string
+	Groups []*Group
+}
+
+// GroupOf returns the group holding node, or nil
+func (t *Topology) GroupOf(node string) *Group {
+	for _, group := range t.Groups {
+		if slices.Contains(group.Nodes, node) {
+			return group
+		}
+	}
+	return nil
+}
+
+// Load places nodes using the configured source. In auto mode the rack
+// map, the topology file and the SLURM node topology are used in that
+// order when present, falling back to node name prefixes. Nodes the
+// source does not place are collected in an UnplacedGroup.
+func Load(cfg *config.TopologyViewConfig, nodes []*dao.Node) (*Topology, error) {
+	source := strings.ToLower(cfg.Source)
+	if source == "" || source == SourceAuto {
+		switch {
+		case len(cfg.Racks) > 0:
+			source = SourceRacks
+		case cfg.File != "":
+			source = SourceFile
+		case hasNodeTopology(nodes):
+			source = SourceSlurm
+		default:
+			source = SourcePrefix
+		}
+	}
+
+	var topo *Topology
+	var err error
+	switch source {
+	case SourceRacks:
+		topo, err = FromRacks(cfg.Racks)
+	case SourceFile:
+		if cfg.File == "" {
+			return nil, fmt.Errorf("topology source is file but views.topology.file is not set")
+		}
+		topo, err = LoadConf(cfg.File)
+	case SourceSlurm:
+		if !hasNodeTopology(nodes) {
+			return nil, fmt.Errorf("SLURM reports no node topology; set views.topology.racks or views.topology.file")
+		}
+		topo = FromNodeTopology(nodes)
+	case SourcePrefix:
+		topo = ByPrefix(nodes)
+	default:
+		return nil, fmt.Errorf("unknown topology source %q", cfg.Source)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	topo.addUnplaced(nodes)
+	return topo, nil
+}
+
+// FromRacks builds a topology from a rack map
+func FromRacks(racks []config.TopologyRackConfig) (*Topology, error) {
+	topo := &Topology{Source: SourceRacks}
+	for _, rack := range racks {
+		nodes, err := ExpandHostlist(rack.Nodes)
+		if err != nil {
+			return nil, fmt.Errorf("rack %s: %w", rack.Name, err)
+		}
+		topo.Groups = append(topo.Groups, &Group{Name: rack.Name, Nodes: nodes})
+	}
+	return topo, nil
+}
+
+// FromNodeTopology groups nodes by the topology SLURM reports for them.
+// Only the first topology of a node is used; its switch path follows the
+// topology name, as in "default:core:leaf01".
+func FromNodeTopology(nodes []*dao.Node) *Topology {
+	topo := &Topology{Source: SourceSlurm}
+	groups := make(map[string]*Group)
+	for _, node := range sortedNodes(nodes) {
+		if node.Topology == "" {
+			continue
+		}
+		first, _, _ := strings.Cut(node.Topology, ",")
+		path := strings.Split(first, ":")
+		if len(path) > 1 {
+			path = path[1:] // Drop the topology name
+		}
+		key := strings.Join(path, "/")
+		group, ok := groups[key]
+		if !ok {
+			group = &Group{Name: path[len(path)-1], Path: path[:len(path)-1]}
+			groups[key] = group
+			topo.Groups = append(topo.Groups, group)
+		}
+		group.Nodes = append(group.Nodes, node.Name)
+	}
+	slices.SortStableFunc(topo.Groups, func(a, b *Group) int { return compareNatural(a.Label(), b.Label()) })
+	return topo
+}
+
+// ByPrefix groups nodes by their name without trailing digits, so
+// node001-node100 and gpu01-gpu20 form two groups
+func ByPrefix(nodes []*dao.Node) *Topology {
+	topo := &Topology{Source: SourcePrefix}
+	groups := make(map[string]*Group)
+	for _, node := range sortedNodes(nodes) {
+		prefix := strings.TrimRight(node.Name, "0123456789")
+		if prefix == "" {
+			prefix = node.Name
+		}
+		group, ok := groups[prefix]
+		if !ok {
+			group = &Group{Name: prefix}
+			groups[prefix] = group
+			topo.Groups = append(topo.Groups, group)
+		}
+		group.Nodes = append(group.Nodes, node.Name)
+	}
+	return topo
+}
+
+// addUnplaced collects the nodes no group holds in an UnplacedGroup
+func (t *Topology) addUnplaced(nodes []*dao.Node) {
+	placed := make(map[string]bool)
+	for _, group := range t.Groups {
+		for _, name := range group.Nodes {
+			placed[name] = true
+		}
+	}
+
+	unplaced := &Group{Name: UnplacedGroup}
+	for _, node := range sortedNodes(nodes) {
+		if !placed[node.Name] {
+			unplaced.Nodes = append(unplaced.Nodes, node.Name)
+		}
+	}
+	if len(unplaced.Nodes) > 0 {
+		t.Groups = append(t.Groups, unplaced)
+	}
+}
+
+// hasNodeTopology reports whether SLURM reported a topology for any node
+func hasNodeTopology(nodes []*dao.Node) bool {
+	return slices.ContainsFunc(nodes, func(n *dao.Node) bool { return n.Topology != "" })
+}
+
+// sortedNodes returns nodes in natural name order
+func sortedNodes(nodes []*dao.Node) []*dao.Node {
+	sorted := slices.Clone(nodes)
+	slices.SortStableFunc(sorted, func(a, b *dao.Node) int { return compareNatural(a.Name, b.Name) })
+	return sorted
+}
+
+// compareNatural compares strings with digit runs compared by value, so
+// "node2" sorts before "node10"
+func compareNatural(a, b string) int {
+	for a != "" && b != "" {
+		da, db := leadingDigits(a), leadingDigits(b)
+		if da != "" && db != "" {
+			na, _ := strconv.Atoi(da)
+			nb, _ := strconv.Atoi(db)
+			if na != nb {
+				return na - nb
+			}
+			a, b = a[len(da):], b[len(db):]
+			continue
+		}
+		if a[0] != b[0] {
+			return int(a[0]) - int(b[0])
+		}
+		a, b = a[1:], b[1:]
+	}
+	return len(a) - len(b)
+}
+
+// leadingDigits returns the run of digits s starts with
+func leadingDigits(s string) string {
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	return s[:i]
+}
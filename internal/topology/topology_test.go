@@ -0,0 +1,94 @@
+package topology
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jontk/s9s/internal/config"
+	"github.com/jontk/s9s/internal/dao"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testNodes(names ...string) []*dao.Node {
+	nodes := make([]*dao.Node, len(names))
+	for i, name := range names {
+		nodes[i] = &dao.Node{Name: name}
+	}
+	return nodes
+}
+
+func TestParseConf_Tree(t *testing.T) {
+	conf := `# tree topology
+SwitchName=core Switches=leaf[1-2]
+SwitchName=leaf1 Nodes=node[01-02]
+SwitchName=leaf2 \
+    Nodes=node[03-04]
+`
+	topo, err := ParseConf(strings.NewReader(conf))
+	require.NoError(t, err)
+	require.Len(t, topo.Groups, 2)
+	assert.Equal(t, "core/leaf1", topo.Groups[0].Label())
+	assert.Equal(t, []string{"node01", "node02"}, topo.Groups[0].Nodes)
+	assert.Equal(t, "core/leaf2", topo.Groups[1].Label())
+	assert.Equal(t, []string{"node03", "node04"}, topo.Groups[1].Nodes)
+}
+
+func TestParseConf_Block(t *testing.T) {
+	conf := "BlockName=b1 Nodes=node[1-4]\nBlockName=b2 Nodes=node[5-8]\nBlockSizes=4,8\n"
+	topo, err := ParseConf(strings.NewReader(conf))
+	require.NoError(t, err)
+	require.Len(t, topo.Groups, 2)
+	assert.Equal(t, "b2", topo.Groups[1].Label())
+}
+
+func TestParseConf_Errors(t *testing.T) {
+	_, err := ParseConf(strings.NewReader("SwitchName=s1 Nodes=n1\nSwitchName=s1 Nodes=n2\n"))
+	assert.ErrorContains(t, err, "defined twice")
+
+	_, err = ParseConf(strings.NewReader("SwitchName=s1 garbage\n"))
+	assert.ErrorContains(t, err, "line 1")
+}
+
+func TestLoad_AutoPrefersRacks(t *testing.T) {
+	cfg := &config.TopologyViewConfig{
+		Racks: []config.TopologyRackConfig{{Name: "rack01", Nodes: "node[1-2]"}},
+	}
+	nodes := testNodes("node1", "node2", "node3")
+	nodes[0].Topology = "default:core:leaf01"
+
+	topo, err := Load(cfg, nodes)
+	require.NoError(t, err)
+	assert.Equal(t, SourceRacks, topo.Source)
+	require.Len(t, topo.Groups, 2)
+	assert.Equal(t, "rack01", topo.Groups[0].Name)
+	assert.Equal(t, UnplacedGroup, topo.Groups[1].Name)
+	assert.Equal(t, []string{"node3"}, topo.Groups[1].Nodes)
+	assert.Equal(t, "rack01", topo.GroupOf("node2").Name)
+}
+
+func TestLoad_SlurmTopology(t *testing.T) {
+	nodes := testNodes("n10", "n2", "n1")
+	nodes[0].Topology = "default:core:leaf02"
+	nodes[1].Topology = "default:core:leaf01"
+	nodes[2].Topology = "default:core:leaf01"
+
+	topo, err := Load(&config.TopologyViewConfig{Source: "auto"}, nodes)
+	require.NoError(t, err)
+	assert.Equal(t, SourceSlurm, topo.Source)
+	require.Len(t, topo.Groups, 2)
+	assert.Equal(t, "core/leaf01", topo.Groups[0].Label())
+	assert.Equal(t, []string{"n1", "n2"}, topo.Groups[0].Nodes)
+
+	_, err = Load(&config.TopologyViewConfig{Source: "slurm"}, testNodes("n1"))
+	assert.Error(t, err)
+}
+
+func TestLoad_ByPrefix(t *testing.T) {
+	topo, err := Load(&config.TopologyViewConfig{}, testNodes("node10", "gpu01", "node2"))
+	require.NoError(t, err)
+	assert.Equal(t, SourcePrefix, topo.Source)
+	require.Len(t, topo.Groups, 2)
+	assert.Equal(t, "gpu", topo.Groups[0].Name)
+	assert.Equal(t, []string{"node2", "node10"}, topo.Groups[1].Nodes)
+}
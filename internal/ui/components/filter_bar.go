@@ -332,6 +332,13 @@ func (fb *FilterBar) ShowFilterHelp() {
   <=   Less or equal        nodes<=10
   =~   Regex match          name=~^job_\d+
   in   In list              state in (running,pending)
+  not in  Not in list       state not in (failed,cancelled)
+
+[teal]Boolean Logic:[white]
+  and, or, not              state=pending and partition=gpu
+  ( )                       (state=pending and partition=gpu) or user=alice
+  not (a or b)              not (state=failed or state=cancelled)
+  "quoted value"            name="my job"
 
 [teal]Memory & Size Units:[white]
   memory>4G                 Memory greater than 4 gigabytes
@@ -361,7 +368,8 @@ func (fb *FilterBar) ShowFilterHelp() {
 [teal]Field Names:[white]
   Jobs:       id, name, user, state, partition, priority, cpus, memory, time,
               submittime, starttime, endtime, elapsed, runtime
-  Nodes:      name, state, partition, cpus, memory, features, uptime
+  Nodes:      name, state, partition, cpus, memory, features, uptime,
+              gpus, gputype, gpusfree, basestate, flags
   Partitions: name, state, nodes, cpus, qos, maxmemory, maxtime
 
 [teal]Examples:[white]
@@ -378,6 +386,7 @@ func (fb *FilterBar) ShowFilterHelp() {
   • Date ranges: today, yesterday, "last week", "last N days"
   • Regex patterns: Use =~ for pattern matching
   • Multiple conditions are AND'ed by default
+  • "not" binds tightest, then "and", then "or"
   • Field names are case-insensitive
   • Press Tab to see and select from saved presets
 
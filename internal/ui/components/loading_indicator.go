@@ -20,6 +20,8 @@ type LoadingIndicator struct {
 	spinnerFrames []string
 	currentFrame  int
 	baseMessage   string
+	onCancel      func() // Called on Esc while shown; nil if the work cannot be cancelled
+	cancelling    bool   // Esc was pressed and the work is winding down
 }
 
 // NewLoadingIndicator creates a new loading indicator
@@ -37,10 +39,49 @@ func NewLoadingIndicator(app *tview.Application) *LoadingIndicator {
 		SetText("Loading...").
 		AddButtons([]string{}).
 		SetBackgroundColor(tcell.ColorDefault)
+	li.Modal.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() != tcell.KeyEsc {
+			return event
+		}
+		li.mu.Lock()
+		cancel := li.onCancel
+		li.onCancel = nil
+		if cancel != nil {
+			li.cancelling = true
+			li.SetText(li.text())
+		}
+		li.mu.Unlock()
+		if cancel == nil {
+			return event
+		}
+		cancel()
+		return nil
+	})
 
 	return li
 }
 
+// text returns the message shown for the current spinner frame. The caller
+// holds li.mu.
+func (li *LoadingIndicator) text() string {
+	text := li.spinnerFrames[li.currentFrame] + " " + li.baseMessage
+	switch {
+	case li.cancelling:
+		text += "\n\nCancelling..."
+	case li.onCancel != nil:
+		text += "\n\nPress Esc to cancel"
+	}
+	return text
+}
+
+// SetCancel makes Esc call cancel, once, while the indicator is shown
+func (li *LoadingIndicator) SetCancel(cancel func()) {
+	li.mu.Lock()
+	defer li.mu.Unlock()
+	li.onCancel = cancel
+	li.SetText(li.text())
+}
+
 // Show displays the loading indicator with the given message
 func (li *LoadingIndicator) Show(message string) {
 	li.mu.Lock()
@@ -58,7 +99,7 @@ func (li *LoadingIndicator) Show(message string) {
 	li.ctx, li.cancel = context.WithCancel(context.Background())
 
 	// Set initial message
-	li.SetText(li.spinnerFrames[0] + " " + message)
+	li.SetText(li.text())
 
 	// Start spinner animation
 	go li.animate()
@@ -74,6 +115,8 @@ func (li *LoadingIndicator) Hide() {
 	}
 
 	li.isActive = false
+	li.onCancel = nil
+	li.cancelling = false
 
 	if li.cancel != nil {
 		li.cancel()
@@ -106,13 +149,12 @@ func (li *LoadingIndicator) animate() {
 
 			// Update spinner frame
 			li.currentFrame = (li.currentFrame + 1) % len(li.spinnerFrames)
-			spinnerText := li.spinnerFrames[li.currentFrame]
-			message := li.baseMessage
+			text := li.text()
 			li.mu.Unlock()
 
 			if li.app != nil {
 				li.app.QueueUpdateDraw(func() {
-					li.SetText(spinnerText + " " + message)
+					li.SetText(text)
 				})
 			}
 		}
@@ -126,9 +168,9 @@ func (li *LoadingIndicator) SetMessage(message string) {
 
 	li.baseMessage = message
 	if li.app != nil {
+		text := li.text()
 		li.app.QueueUpdateDraw(func() {
-			spinnerText := li.spinnerFrames[li.currentFrame]
-			li.SetText(spinnerText + " " + message)
+			li.SetText(text)
 		})
 	}
 }
@@ -212,6 +254,17 @@ func (lm *LoadingManager) SetMessage(viewName, message string) {
 	}
 }
 
+// SetCancel makes Esc on the loading indicator of a view call cancel
+func (lm *LoadingManager) SetCancel(viewName string, cancel func()) {
+	lm.mu.RLock()
+	indicator, exists := lm.indicators[viewName]
+	lm.mu.RUnlock()
+
+	if exists {
+		indicator.SetCancel(cancel)
+	}
+}
+
 // IsActive returns true if loading is active for the given view
 func (lm *LoadingManager) IsActive(viewName string) bool {
 	lm.mu.RLock()
@@ -260,6 +313,24 @@ func (lw *LoadingWrapper) WithLoadingAsync(message string, fn func() error, call
 	}()
 }
 
+// WithCancellableLoadingAsync executes the given function asynchronously
+// with a loading indicator. Its context is derived from ctx and cancelled
+// when Esc is pressed on the indicator.
+func (lw *LoadingWrapper) WithCancellableLoadingAsync(ctx context.Context, message string, fn func(ctx context.Context) error, callback func(error)) {
+	ctx, cancel := context.WithCancel(ctx)
+	lw.manager.Show(lw.viewName, message)
+	lw.manager.SetCancel(lw.viewName, cancel)
+
+	go func() {
+		err := fn(ctx)
+		cancel()
+		lw.manager.Hide(lw.viewName)
+		if callback != nil {
+			callback(err)
+		}
+	}()
+}
+
 // UpdateMessage updates the loading message
 func (lw *LoadingWrapper) UpdateMessage(message string) {
 	lw.manager.SetMessage(lw.viewName, message)
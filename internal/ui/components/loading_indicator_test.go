@@ -0,0 +1,68 @@
+package components
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+func TestLoadingWrapper_EscCancelsWork(t *testing.T) {
+	manager := NewLoadingManager(nil, nil)
+	wrapper := NewLoadingWrapper(manager, "sweep")
+
+	done := make(chan error, 1)
+	wrapper.WithCancellableLoadingAsync(context.Background(), "Submitting", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, func(err error) {
+		done <- err
+	})
+
+	indicator := manager.indicators["sweep"]
+	if text := indicator.text(); !strings.Contains(text, "Press Esc to cancel") {
+		t.Errorf("Expected a cancel hint, got %q", text)
+	}
+
+	indicator.InputHandler()(tcell.NewEventKey(tcell.KeyEsc, 0, tcell.ModNone), func(tview.Primitive) {})
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected the work to be cancelled, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Esc did not cancel the work")
+	}
+	if manager.IsActive("sweep") {
+		t.Error("Expected the indicator to be hidden after the work stopped")
+	}
+}
+
+func TestLoadingWrapper_ParentContextCancelsWork(t *testing.T) {
+	manager := NewLoadingManager(nil, nil)
+	wrapper := NewLoadingWrapper(manager, "sweep")
+
+	parent, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	wrapper.WithCancellableLoadingAsync(parent, "Submitting", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, func(err error) {
+		done <- err
+	})
+	cancel()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected the work to be cancelled, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Cancelling the parent context did not stop the work")
+	}
+}
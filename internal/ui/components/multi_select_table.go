@@ -3,6 +3,7 @@ package components
 import (
 	"fmt"
 	"sync"
+	"sync/atomic"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
@@ -19,6 +20,17 @@ type MultiSelectTable struct {
 	onRowToggle       func(row int, selected bool, data []string)
 	showCheckboxes    bool
 	selectAllState    int // 0=none, 1=some, 2=all
+
+	// marks is the selection snapshot cells are decorated from; it is read
+	// while drawing, so it never takes mst.mu
+	marks atomic.Pointer[selectionMarks]
+}
+
+// selectionMarks is an immutable copy of the selection state
+type selectionMarks struct {
+	checkboxes     bool
+	selectedRows   map[int]bool
+	selectAllState int
 }
 
 // NewMultiSelectTable creates a new multi-select table
@@ -37,6 +49,8 @@ func NewMultiSelectTable(config *TableConfig) *MultiSelectTable {
 		showCheckboxes:  true,
 		selectAllState:  0,
 	}
+	mst.marks.Store(&selectionMarks{})
+	baseTable.decorate = mst.decorateCell
 
 	// Note: InputCapture is NOT set here. Views handle all keyboard input in their OnKey methods.
 	// The base Table also doesn't set InputCapture anymore to allow View.OnKey to work correctly.
@@ -412,120 +426,50 @@ func (mst *MultiSelectTable) updateSelectAllStateUnsafe() {
 	}
 }
 
-// refreshDisplay updates the visual display with selection indicators
+// refreshDisplay publishes the selection state the cells are drawn from;
+// callers hold mst.mu
 func (mst *MultiSelectTable) refreshDisplay() {
-	// Acquire Table's mutex to protect tview.Table operations
-	mst.Table.mu.Lock()
-	defer mst.Table.mu.Unlock()
-
-	mst.Table.Table.Clear()
-
-	if len(mst.config.Columns) == 0 {
-		return
-	}
-
-	// Render header row
-	headerRow := mst.renderHeaderRow()
-
-	// Render data rows
-	mst.renderDataRows(headerRow)
-}
-
-// renderHeaderRow adds the header row to the table
-func (mst *MultiSelectTable) renderHeaderRow() int {
-	headerRow := 0
-	if !mst.config.ShowHeader {
-		return headerRow
-	}
-
-	for col, column := range mst.config.Columns {
-		if column.Hidden {
-			continue
+	marks := &selectionMarks{
+		checkboxes:     mst.multiSelectMode && mst.showCheckboxes,
+		selectedRows:   make(map[int]bool, len(mst.selectedRows)),
+		selectAllState: mst.selectAllState,
+	}
+	for row, selected := range mst.selectedRows {
+		if selected {
+			marks.selectedRows[row] = true
 		}
-
-		// Get column name with sort indicator if applicable
-		columnName := column.Name
-		if column.Sortable && col == mst.sortColumn {
-			if mst.sortAscending {
-				columnName += " ▲"
-			} else {
-				columnName += " ▼"
-			}
-		}
-
-		var cellText string
-		if col == 0 && mst.multiSelectMode && mst.showCheckboxes {
-			selectAllIcon := mst.getSelectAllIcon()
-			cellText = fmt.Sprintf("%s %s", selectAllIcon, columnName)
-		} else {
-			cellText = columnName
-		}
-
-		cell := tview.NewTableCell(cellText).
-			SetTextColor(mst.config.HeaderColor).
-			SetAlign(column.Alignment).
-			SetSelectable(false).
-			SetExpansion(1)
-
-		mst.SetCell(headerRow, col, cell)
-	}
-	return 1
-}
-
-// renderDataRows adds all data rows to the table
-func (mst *MultiSelectTable) renderDataRows(headerRow int) {
-	filteredData := mst.filteredData
-
-	for rowIndex, rowData := range filteredData {
-		displayRow := headerRow + rowIndex
-		mst.renderRow(displayRow, rowIndex, rowData)
 	}
+	mst.marks.Store(marks)
 }
 
-// renderRow renders a single data row with proper styling and checkboxes
-func (mst *MultiSelectTable) renderRow(displayRow, rowIndex int, rowData []string) {
-	for col, cellData := range rowData {
-		if col >= len(mst.config.Columns) {
-			break
-		}
-
-		column := mst.config.Columns[col]
-		if column.Hidden {
-			continue
-		}
+// decorateCell adds the checkboxes and selection highlight to a cell
+func (mst *MultiSelectTable) decorateCell(rowIdx, displayCol int, cell *tview.TableCell) {
+	marks := mst.marks.Load()
 
-		var cellText string
-		if col == 0 && mst.multiSelectMode && mst.showCheckboxes {
-			checkboxIcon := mst.getCheckboxIcon(mst.selectedRows[rowIndex])
-			cellText = fmt.Sprintf("%s %s", checkboxIcon, cellData)
-		} else {
-			cellText = cellData
+	if rowIdx < 0 {
+		if displayCol == 0 && marks.checkboxes {
+			cell.SetText(fmt.Sprintf("%s %s", selectAllIcon(marks.selectAllState), cell.Text))
 		}
-
-		cell := mst.createStyledCell(cellText, column, rowIndex)
-		mst.SetCell(displayRow, col, cell)
+		return
 	}
-}
 
-// createStyledCell creates a table cell with proper styling based on selection state
-func (mst *MultiSelectTable) createStyledCell(cellText string, column Column, rowIndex int) *tview.TableCell {
-	cell := tview.NewTableCell(cellText).
-		SetAlign(column.Alignment).
-		SetExpansion(1)
+	selected := marks.selectedRows[rowIdx]
+	if displayCol == 0 && marks.checkboxes {
+		cell.SetText(fmt.Sprintf("%s %s", mst.getCheckboxIcon(selected), cell.Text))
+	}
 
-	if mst.selectedRows[rowIndex] {
+	switch {
+	case selected:
 		cell.SetBackgroundColor(tcell.ColorDarkBlue).
 			SetTextColor(tcell.ColorWhite)
-	} else {
+	case rowIdx%2 == 0:
 		// Alternate row colors
-		if rowIndex%2 == 0 {
-			cell.SetBackgroundColor(mst.config.EvenRowColor)
-		} else {
-			cell.SetBackgroundColor(mst.config.OddRowColor)
-		}
+		cell.SetBackgroundColor(mst.config.EvenRowColor).
+			SetTextColor(tview.Styles.PrimaryTextColor)
+	default:
+		cell.SetBackgroundColor(mst.config.OddRowColor).
+			SetTextColor(tview.Styles.PrimaryTextColor)
 	}
-
-	return cell
 }
 
 // getCheckboxIcon returns the appropriate checkbox icon
@@ -536,9 +480,9 @@ func (mst *MultiSelectTable) getCheckboxIcon(selected bool) string {
 	return "[gray]☐[white]"
 }
 
-// getSelectAllIcon returns the appropriate select-all icon
-func (mst *MultiSelectTable) getSelectAllIcon() string {
-	switch mst.selectAllState {
+// selectAllIcon returns the select-all icon for state
+func selectAllIcon(state int) string {
+	switch state {
 	case 0:
 		return "[gray]☐[white]" // None selected
 	case 1:
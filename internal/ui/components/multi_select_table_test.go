@@ -273,3 +273,31 @@ func TestMultiSelectTable_GetHints(t *testing.T) {
 		}
 	}
 }
+
+func TestMultiSelectTable_Checkboxes(t *testing.T) {
+	table := NewMultiSelectTable(DefaultTableConfig())
+	table.config.Columns = []Column{NewColumn("ID").Build(), NewColumn("User").Build()}
+	table.SetData([][]string{{"job1", "user1"}, {"job2", "user2"}})
+
+	if got := table.GetCell(1, 0).Text; got != "job1" {
+		t.Errorf("Expected no checkbox outside multi-select mode, got %q", got)
+	}
+
+	table.SetMultiSelectMode(true)
+	table.ToggleRow(2)
+
+	if got := table.GetCell(1, 0).Text; !strings.Contains(got, "☐") || !strings.HasSuffix(got, "job1") {
+		t.Errorf("Expected an empty checkbox on job1, got %q", got)
+	}
+	selected := table.GetCell(2, 0)
+	_, background, _ := selected.Style.Decompose()
+	if !strings.Contains(selected.Text, "☑") || background != tcell.ColorDarkBlue {
+		t.Errorf("Expected job2 to be checked and highlighted, got %q", selected.Text)
+	}
+	if got := table.GetCell(2, 1).Text; got != "user2" {
+		t.Errorf("Expected the checkbox only in the first column, got %q", got)
+	}
+	if got := table.GetCell(0, 0).Text; !strings.Contains(got, "◐") {
+		t.Errorf("Expected a partial select-all icon, got %q", got)
+	}
+}
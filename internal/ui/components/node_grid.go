@@ -0,0 +1,442 @@
+package components
+
+import (
+	"sync"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// maxGridLabelWidth caps the width of the group label column
+const maxGridLabelWidth = 24
+
+// GridCell is one node of a NodeGrid
+type GridCell struct {
+	Name  string
+	Glyph rune
+	Color tcell.Color
+}
+
+// GridGroup is a labelled block of cells, such as a rack or a switch
+type GridGroup struct {
+	Label string
+	Cells []GridCell
+}
+
+// gridPos addresses a cell by group and index within the group
+type gridPos struct {
+	group, index int
+}
+
+// gridLine is one drawn line of a group's cells
+type gridLine struct {
+	group, start, count int
+}
+
+// NodeGrid draws nodes as a compact grid of colored cells, one block of
+// lines per group, with a cursor and multi-selection marks
+type NodeGrid struct {
+	*tview.Box
+
+	mu       sync.RWMutex
+	groups   []GridGroup
+	cursor   gridPos
+	marked   map[string]bool
+	perLine  int // Cells per line at the last draw
+	offset   int // First visible line
+	onSelect func(name string)
+	onChange func(name string)
+}
+
+// NewNodeGrid creates an empty node grid
+func NewNodeGrid() *NodeGrid {
+	return &NodeGrid{
+		Box:     tview.NewBox(),
+		marked:  make(map[string]bool),
+		perLine: 64,
+	}
+}
+
+// SetGroups replaces the grid's contents, keeping the cursor on the same
+// node and the marks of nodes that are still present
+func (g *NodeGrid) SetGroups(groups []GridGroup) *NodeGrid {
+	g.mu.Lock()
+	current := g.currentNameLocked()
+	g.groups = groups
+
+	present := make(map[string]bool)
+	for _, group := range groups {
+		for _, cell := range group.Cells {
+			present[cell.Name] = true
+		}
+	}
+	for name := range g.marked {
+		if !present[name] {
+			delete(g.marked, name)
+		}
+	}
+
+	g.cursor = g.firstCellLocked()
+	if pos, ok := g.findLocked(current); ok {
+		g.cursor = pos
+	}
+	changed := g.onChange
+	name := g.currentNameLocked()
+	g.mu.Unlock()
+
+	if changed != nil && name != current {
+		changed(name)
+	}
+	return g
+}
+
+// SetSelectedFunc sets the handler called with the node under the cursor
+// when Enter is pressed
+func (g *NodeGrid) SetSelectedFunc(handler func(name string)) *NodeGrid {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.onSelect = handler
+	return g
+}
+
+// SetChangedFunc sets the handler called when the cursor moves to another
+// node
+func (g *NodeGrid) SetChangedFunc(handler func(name string)) *NodeGrid {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.onChange = handler
+	return g
+}
+
+// Current returns the node under the cursor, or "" for an empty grid
+func (g *NodeGrid) Current() string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.currentNameLocked()
+}
+
+// CurrentGroup returns the index of the group under the cursor, or -1
+func (g *NodeGrid) CurrentGroup() int {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if g.currentNameLocked() == "" {
+		return -1
+	}
+	return g.cursor.group
+}
+
+// SelectNode moves the cursor to the named node
+func (g *NodeGrid) SelectNode(name string) bool {
+	g.mu.Lock()
+	pos, ok := g.findLocked(name)
+	if ok {
+		g.cursor = pos
+	}
+	changed := g.onChange
+	g.mu.Unlock()
+
+	if ok && changed != nil {
+		changed(name)
+	}
+	return ok
+}
+
+// ToggleMark marks or unmarks the node under the cursor
+func (g *NodeGrid) ToggleMark() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	name := g.currentNameLocked()
+	if name == "" {
+		return
+	}
+	if g.marked[name] {
+		delete(g.marked, name)
+	} else {
+		g.marked[name] = true
+	}
+}
+
+// ToggleGroupMarks marks every node of the cursor's group, or unmarks them
+// all when they are already marked
+func (g *NodeGrid) ToggleGroupMarks() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.currentNameLocked() == "" {
+		return
+	}
+	cells := g.groups[g.cursor.group].Cells
+	all := true
+	for _, cell := range cells {
+		if !g.marked[cell.Name] {
+			all = false
+			break
+		}
+	}
+	for _, cell := range cells {
+		if all {
+			delete(g.marked, cell.Name)
+		} else {
+			g.marked[cell.Name] = true
+		}
+	}
+}
+
+// ClearMarks unmarks every node
+func (g *NodeGrid) ClearMarks() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	clear(g.marked)
+}
+
+// Marked returns the marked nodes in grid order
+func (g *NodeGrid) Marked() []string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	var names []string
+	for _, group := range g.groups {
+		for _, cell := range group.Cells {
+			if g.marked[cell.Name] {
+				names = append(names, cell.Name)
+			}
+		}
+	}
+	return names
+}
+
+// Draw draws the grid
+func (g *NodeGrid) Draw(screen tcell.Screen) {
+	g.DrawForSubclass(screen, g)
+	x, y, width, height := g.GetInnerRect()
+	if width <= 0 || height <= 0 {
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	labelWidth := 0
+	for _, group := range g.groups {
+		labelWidth = max(labelWidth, tview.TaggedStringWidth(group.Label))
+	}
+	labelWidth = min(labelWidth, maxGridLabelWidth, width/3)
+	g.perLine = max(width-labelWidth-1, 1)
+
+	lines := g.linesLocked()
+	cursorLine := g.cursorLineLocked(lines)
+	if cursorLine < g.offset {
+		g.offset = cursorLine
+	} else if cursorLine >= g.offset+height {
+		g.offset = cursorLine - height + 1
+	}
+	g.offset = max(min(g.offset, len(lines)-height), 0)
+
+	for row := 0; row < height && g.offset+row < len(lines); row++ {
+		line := lines[g.offset+row]
+		group := g.groups[line.group]
+		if line.start == 0 {
+			tview.Print(screen, group.Label, x, y+row, labelWidth, tview.AlignLeft, tcell.ColorYellow)
+		}
+		for i := 0; i < line.count; i++ {
+			index := line.start + i
+			cell := group.Cells[index]
+			style := tcell.StyleDefault.Foreground(cell.Color)
+			if g.marked[cell.Name] {
+				style = style.Background(tcell.ColorGray)
+			}
+			if line.group == g.cursor.group && index == g.cursor.index {
+				style = style.Reverse(true)
+			}
+			glyph := cell.Glyph
+			if glyph == 0 {
+				glyph = '■'
+			}
+			screen.SetContent(x+labelWidth+1+i, y+row, glyph, nil, style)
+		}
+	}
+}
+
+// InputHandler moves the cursor with the arrow keys, Home/End and
+// PgUp/PgDn, jumps between groups with [ and ] and marks with Space
+func (g *NodeGrid) InputHandler() func(event *tcell.EventKey, setFocus func(p tview.Primitive)) {
+	return g.WrapInputHandler(func(event *tcell.EventKey, _ func(p tview.Primitive)) {
+		g.mu.Lock()
+		before := g.currentNameLocked()
+		lines := g.linesLocked()
+		_, _, _, height := g.GetInnerRect()
+
+		switch event.Key() {
+		case tcell.KeyLeft:
+			g.stepLocked(-1)
+		case tcell.KeyRight:
+			g.stepLocked(1)
+		case tcell.KeyUp:
+			g.moveLinesLocked(lines, -1)
+		case tcell.KeyDown:
+			g.moveLinesLocked(lines, 1)
+		case tcell.KeyPgUp:
+			g.moveLinesLocked(lines, -max(height-1, 1))
+		case tcell.KeyPgDn:
+			g.moveLinesLocked(lines, max(height-1, 1))
+		case tcell.KeyHome:
+			g.cursor = g.firstCellLocked()
+		case tcell.KeyEnd:
+			g.cursor = g.lastCellLocked()
+		case tcell.KeyEnter:
+			selected, name := g.onSelect, before
+			g.mu.Unlock()
+			if selected != nil && name != "" {
+				selected(name)
+			}
+			return
+		case tcell.KeyRune:
+			switch event.Rune() {
+			case '[':
+				g.jumpGroupLocked(-1)
+			case ']':
+				g.jumpGroupLocked(1)
+			case ' ':
+				g.mu.Unlock()
+				g.ToggleMark()
+				return
+			}
+		}
+
+		after, changed := g.currentNameLocked(), g.onChange
+		g.mu.Unlock()
+		if changed != nil && after != before {
+			changed(after)
+		}
+	})
+}
+
+// currentNameLocked returns the node under the cursor
+func (g *NodeGrid) currentNameLocked() string {
+	if g.cursor.group < 0 || g.cursor.group >= len(g.groups) {
+		return ""
+	}
+	cells := g.groups[g.cursor.group].Cells
+	if g.cursor.index < 0 || g.cursor.index >= len(cells) {
+		return ""
+	}
+	return cells[g.cursor.index].Name
+}
+
+// findLocked returns the position of the named node
+func (g *NodeGrid) findLocked(name string) (gridPos, bool) {
+	if name == "" {
+		return gridPos{}, false
+	}
+	for gi, group := range g.groups {
+		for ci, cell := range group.Cells {
+			if cell.Name == name {
+				return gridPos{gi, ci}, true
+			}
+		}
+	}
+	return gridPos{}, false
+}
+
+// firstCellLocked returns the position of the first cell
+func (g *NodeGrid) firstCellLocked() gridPos {
+	for gi, group := range g.groups {
+		if len(group.Cells) > 0 {
+			return gridPos{gi, 0}
+		}
+	}
+	return gridPos{}
+}
+
+// lastCellLocked returns the position of the last cell
+func (g *NodeGrid) lastCellLocked() gridPos {
+	for gi := len(g.groups) - 1; gi >= 0; gi-- {
+		if n := len(g.groups[gi].Cells); n > 0 {
+			return gridPos{gi, n - 1}
+		}
+	}
+	return gridPos{}
+}
+
+// stepLocked moves the cursor delta cells, crossing group boundaries
+func (g *NodeGrid) stepLocked(delta int) {
+	pos := g.cursor
+	for pos.index += delta; ; {
+		if pos.group < 0 || pos.group >= len(g.groups) {
+			return
+		}
+		if pos.index >= 0 && pos.index < len(g.groups[pos.group].Cells) {
+			g.cursor = pos
+			return
+		}
+		if pos.index < 0 {
+			pos.group--
+			if pos.group >= 0 {
+				pos.index = len(g.groups[pos.group].Cells) - 1
+			}
+		} else {
+			pos.group++
+			pos.index = 0
+		}
+	}
+}
+
+// jumpGroupLocked moves the cursor to the first cell of the previous or
+// next non-empty group
+func (g *NodeGrid) jumpGroupLocked(delta int) {
+	for gi := g.cursor.group + delta; gi >= 0 && gi < len(g.groups); gi += delta {
+		if len(g.groups[gi].Cells) > 0 {
+			g.cursor = gridPos{gi, 0}
+			return
+		}
+	}
+}
+
+// linesLocked lays the groups out in lines of perLine cells
+func (g *NodeGrid) linesLocked() []gridLine {
+	var lines []gridLine
+	for gi, group := range g.groups {
+		if len(group.Cells) == 0 {
+			lines = append(lines, gridLine{group: gi})
+			continue
+		}
+		for start := 0; start < len(group.Cells); start += g.perLine {
+			lines = append(lines, gridLine{group: gi, start: start, count: min(g.perLine, len(group.Cells)-start)})
+		}
+	}
+	return lines
+}
+
+// cursorLineLocked returns the index of the line holding the cursor
+func (g *NodeGrid) cursorLineLocked(lines []gridLine) int {
+	for i, line := range lines {
+		if line.group == g.cursor.group && g.cursor.index >= line.start && g.cursor.index < line.start+max(line.count, 1) {
+			return i
+		}
+	}
+	return 0
+}
+
+// moveLinesLocked moves the cursor delta lines up or down, keeping its
+// column where the target line is long enough
+func (g *NodeGrid) moveLinesLocked(lines []gridLine, delta int) {
+	if len(lines) == 0 {
+		return
+	}
+	current := g.cursorLineLocked(lines)
+	column := g.cursor.index - lines[current].start
+
+	target := current + delta
+	step := 1
+	if delta < 0 {
+		step = -1
+	}
+	target = max(min(target, len(lines)-1), 0)
+	// Skip the lines of empty groups
+	for target >= 0 && target < len(lines) && lines[target].count == 0 {
+		target += step
+	}
+	if target < 0 || target >= len(lines) {
+		return
+	}
+	line := lines[target]
+	g.cursor = gridPos{line.group, line.start + min(column, line.count-1)}
+}
@@ -4,9 +4,11 @@ import (
 	"fmt"
 	"os"
 	"regexp"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/jontk/s9s/internal/ui/navigation"
@@ -98,6 +100,158 @@ type Table struct {
 	onSelect      func(row, col int)
 	onSort        func(col int, ascending bool)
 	vimMode       *navigation.VimMode
+
+	// view is the snapshot the table draws from; cells are built on demand
+	// for the rows on screen, so large data sets cost nothing to render
+	view     atomic.Pointer[tableView]
+	decorate func(rowIdx, displayCol int, cell *tview.TableCell)
+	lastDiff TableDiff
+
+	// onNeedMore is called when the selection nears the last row
+	onNeedMore    func()
+	needMoreAhead int
+	needMoreAt    atomic.Int64 // Row count onNeedMore last fired at
+}
+
+// tableView is an immutable snapshot of what a Table shows
+type tableView struct {
+	rows       [][]string
+	columns    []Column // Visible columns
+	indexes    []int    // Data index of each visible column
+	headers    []string
+	fixedRows  int
+	showHeader bool
+}
+
+// tableContent serves the cells of a Table from its current snapshot
+type tableContent struct {
+	tview.TableContentReadOnly
+	table *Table
+}
+
+// GetRowCount returns the header rows plus the data rows
+func (c *tableContent) GetRowCount() int {
+	view := c.table.view.Load()
+	if view == nil {
+		return 0
+	}
+	return view.fixedRows + len(view.rows)
+}
+
+// GetColumnCount returns the number of visible columns
+func (c *tableContent) GetColumnCount() int {
+	view := c.table.view.Load()
+	if view == nil {
+		return 0
+	}
+	return len(view.columns)
+}
+
+// GetCell builds the cell at row and column
+func (c *tableContent) GetCell(row, column int) *tview.TableCell {
+	view := c.table.view.Load()
+	if view == nil || column < 0 || column >= len(view.columns) || row < 0 {
+		return nil
+	}
+
+	if row < view.fixedRows {
+		if row != 0 || !view.showHeader {
+			return nil
+		}
+		cell := tview.NewTableCell(view.headers[column]).
+			SetTextColor(c.table.config.HeaderColor).
+			SetAlign(view.columns[column].Alignment).
+			SetSelectable(false).
+			SetExpansion(1)
+		if c.table.decorate != nil {
+			c.table.decorate(-1, column, cell)
+		}
+		return cell
+	}
+
+	rowIdx := row - view.fixedRows
+	if rowIdx >= len(view.rows) {
+		return nil
+	}
+	rowData := view.rows[rowIdx]
+	dataCol := view.indexes[column]
+	if dataCol >= len(rowData) {
+		return nil
+	}
+
+	// Truncate text if necessary, accounting for color codes
+	text := rowData[dataCol]
+	if maxWidth := view.columns[column].Width; maxWidth > 0 {
+		text = truncateWithColorCodes(text, maxWidth)
+	}
+
+	cell := tview.NewTableCell(text).
+		SetAlign(view.columns[column].Alignment).
+		SetExpansion(1)
+	c.table.applyRowColoring(cell, rowIdx)
+	if c.table.decorate != nil {
+		c.table.decorate(rowIdx, column, cell)
+	}
+	return cell
+}
+
+// TableDiff summarises how the last SetData changed the rows. Rows are
+// matched by their first column.
+type TableDiff struct {
+	Added     int
+	Removed   int
+	Changed   int
+	Unchanged int
+}
+
+// Empty reports whether the data did not change
+func (d TableDiff) Empty() bool {
+	return d.Added == 0 && d.Removed == 0 && d.Changed == 0
+}
+
+// diffRows compares two data sets row by row, keyed by the first column
+func diffRows(oldRows, newRows [][]string) TableDiff {
+	var diff TableDiff
+	if len(oldRows) == len(newRows) {
+		same := true
+		for i := range newRows {
+			if !slices.Equal(oldRows[i], newRows[i]) {
+				same = false
+				break
+			}
+		}
+		if same {
+			diff.Unchanged = len(newRows)
+			return diff
+		}
+	}
+
+	previous := make(map[string][]string, len(oldRows))
+	for _, row := range oldRows {
+		previous[rowKey(row)] = row
+	}
+	for _, row := range newRows {
+		old, ok := previous[rowKey(row)]
+		switch {
+		case !ok:
+			diff.Added++
+		case slices.Equal(old, row):
+			diff.Unchanged++
+		default:
+			diff.Changed++
+		}
+		delete(previous, rowKey(row))
+	}
+	diff.Removed = len(previous)
+	return diff
+}
+
+// rowKey identifies a row across updates by its first column
+func rowKey(row []string) string {
+	if len(row) == 0 {
+		return ""
+	}
+	return row[0]
 }
 
 // NewTable creates a new table component
@@ -116,6 +270,11 @@ func NewTable(config *TableConfig) *Table {
 		vimMode:       navigation.NewVimMode(),
 	}
 
+	// Cells are served from the snapshot instead of being stored in the table
+	table.Table.SetContent(&tableContent{table: table})
+	table.Table.SetSelectionChangedFunc(table.checkNeedMore)
+	table.refresh()
+
 	// Configure tview table
 	table.Table.SetBorders(true).
 		SetSelectable(config.Selectable, false).
@@ -145,15 +304,81 @@ func (t *Table) SetColumns(columns []Column) {
 	t.refresh()
 }
 
-// SetData sets the table data
+// SetData sets the table data. Rows are compared with the previous data by
+// their first column: an identical update leaves the table untouched, and
+// otherwise the selection stays on the row it was on.
 func (t *Table) SetData(data [][]string) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
+	t.lastDiff = diffRows(t.data, data)
+	if t.lastDiff.Empty() && len(t.data) == len(data) {
+		t.data = data
+		return
+	}
+
+	selectedKey, hadSelection := t.selectedKey()
 	t.data = data
 	t.applyFilter()
 	t.applySort()
 	t.refresh()
+	if hadSelection {
+		t.selectKey(selectedKey)
+	}
+}
+
+// LastDiff returns how the last SetData changed the rows
+func (t *Table) LastDiff() TableDiff {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.lastDiff
+}
+
+// selectedKey returns the key of the selected row; callers hold t.mu
+func (t *Table) selectedKey() (string, bool) {
+	row, _ := t.GetSelection()
+	idx := row - t.config.FixedRows
+	if idx < 0 || idx >= len(t.filteredData) {
+		return "", false
+	}
+	return rowKey(t.filteredData[idx]), true
+}
+
+// selectKey moves the selection to the row with key, if it is still shown;
+// callers hold t.mu
+func (t *Table) selectKey(key string) {
+	row, col := t.GetSelection()
+	if idx := row - t.config.FixedRows; idx >= 0 && idx < len(t.filteredData) && rowKey(t.filteredData[idx]) == key {
+		return
+	}
+	for i, data := range t.filteredData {
+		if rowKey(data) == key {
+			t.Select(i+t.config.FixedRows, col)
+			return
+		}
+	}
+}
+
+// SetOnNeedMore sets fn to be called when the selection comes within ahead
+// rows of the last row, so more rows can be loaded. It fires once per row
+// count and must not block.
+func (t *Table) SetOnNeedMore(ahead int, fn func()) {
+	t.needMoreAhead = ahead
+	t.onNeedMore = fn
+}
+
+// checkNeedMore fires onNeedMore when the selection nears the end
+func (t *Table) checkNeedMore(row, _ int) {
+	view := t.view.Load()
+	if t.onNeedMore == nil || view == nil || len(view.rows) == 0 {
+		return
+	}
+	count := int64(len(view.rows))
+	if row-view.fixedRows < len(view.rows)-t.needMoreAhead || t.needMoreAt.Load() == count {
+		return
+	}
+	t.needMoreAt.Store(count)
+	t.onNeedMore()
 }
 
 // GetData returns the current table data
@@ -259,36 +484,25 @@ func (t *Table) Clear() {
 
 	t.data = [][]string{}
 	t.filteredData = [][]string{}
-	t.Table.Clear()
+	t.refresh()
 }
 
-// refresh updates the table display
+// refresh publishes a new snapshot for drawing; callers hold t.mu
 func (t *Table) refresh() {
-	t.Table.Clear()
-	t.renderHeader()
-	t.renderDataRows()
-}
-
-// renderHeader renders the table header row
-func (t *Table) renderHeader() {
-	if !t.config.ShowHeader || len(t.config.Columns) == 0 {
-		return
+	view := &tableView{
+		rows:       t.filteredData,
+		fixedRows:  t.config.FixedRows,
+		showHeader: t.config.ShowHeader,
 	}
-
 	for col, column := range t.config.Columns {
 		if column.Hidden {
 			continue
 		}
-
-		header := t.getHeaderText(col, column)
-		cell := tview.NewTableCell(header).
-			SetTextColor(t.config.HeaderColor).
-			SetAlign(column.Alignment).
-			SetSelectable(false).
-			SetExpansion(1)
-
-		t.SetCell(0, col, cell)
+		view.columns = append(view.columns, column)
+		view.indexes = append(view.indexes, col)
+		view.headers = append(view.headers, t.getHeaderText(col, column))
 	}
+	t.view.Store(view)
 }
 
 // getHeaderText returns the header text with sort indicator if applicable
@@ -304,36 +518,6 @@ func (t *Table) getHeaderText(col int, column Column) string {
 	return header
 }
 
-// renderDataRows renders all data rows
-func (t *Table) renderDataRows() {
-	for rowIdx, rowData := range t.filteredData {
-		displayRow := rowIdx + t.config.FixedRows
-		t.renderRow(displayRow, rowIdx, rowData)
-	}
-}
-
-// renderRow renders a single data row
-func (t *Table) renderRow(displayRow, rowIdx int, rowData []string) {
-	for colIdx, cellData := range rowData {
-		if colIdx >= len(t.config.Columns) || t.config.Columns[colIdx].Hidden {
-			continue
-		}
-
-		// Truncate text if necessary, accounting for color codes
-		maxWidth := t.config.Columns[colIdx].Width
-		if maxWidth > 0 {
-			cellData = truncateWithColorCodes(cellData, maxWidth)
-		}
-
-		cell := tview.NewTableCell(cellData).
-			SetAlign(t.config.Columns[colIdx].Alignment).
-			SetExpansion(1)
-
-		t.applyRowColoring(cell, rowIdx)
-		t.SetCell(displayRow, colIdx, cell)
-	}
-}
-
 // applyRowColoring applies alternating row colors
 func (t *Table) applyRowColoring(cell *tview.TableCell, rowIdx int) {
 	if rowIdx%2 == 0 && t.config.EvenRowColor != tcell.ColorDefault {
@@ -372,6 +556,8 @@ func (t *Table) applySort() {
 		return
 	}
 
+	// Sort a copy: the published snapshot may still be drawn from
+	t.filteredData = slices.Clone(t.filteredData)
 	sort.Slice(t.filteredData, func(i, j int) bool {
 		if t.sortColumn >= len(t.filteredData[i]) || t.sortColumn >= len(t.filteredData[j]) {
 			return false
@@ -627,7 +813,112 @@ func (t *Table) SetSortColumn(column int) {
 
 // ClearSort removes any active sort and restores the original data order
 func (t *Table) ClearSort() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
 	t.sortColumn = -1
 	t.sortAscending = true
-	t.renderHeader()
+	t.refresh()
+}
+
+// normalizeColumnName folds a column name for matching, so "Time Limit",
+// "time_limit" and "timelimit" name the same column
+func normalizeColumnName(name string) string {
+	return strings.NewReplacer(" ", "", "_", "", "-", "").Replace(strings.ToLower(name))
+}
+
+// ColumnIndex returns the index of the named column, or -1 if there is none.
+// Names match case-insensitively, ignoring spaces, dashes and underscores.
+func (t *Table) ColumnIndex(name string) int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.columnIndex(name)
+}
+
+// columnIndex looks up a column; callers hold t.mu
+func (t *Table) columnIndex(name string) int {
+	want := normalizeColumnName(name)
+	for i, col := range t.config.Columns {
+		if normalizeColumnName(col.Name) == want {
+			return i
+		}
+	}
+	return -1
+}
+
+// ColumnCount returns the number of columns, hidden ones included
+func (t *Table) ColumnCount() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return len(t.config.Columns)
+}
+
+// ColumnName returns the name of the column at index, or "" if out of range
+func (t *Table) ColumnName(index int) string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if index < 0 || index >= len(t.config.Columns) {
+		return ""
+	}
+	return t.config.Columns[index].Name
+}
+
+// VisibleColumns returns the names of the columns that are shown
+func (t *Table) VisibleColumns() []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	var names []string
+	for _, col := range t.config.Columns {
+		if !col.Hidden {
+			names = append(names, col.Name)
+		}
+	}
+	return names
+}
+
+// SetVisibleColumns shows only the named columns, in their table order; an
+// empty list shows every column. Unknown names leave the table unchanged.
+func (t *Table) SetVisibleColumns(names []string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	visible := make(map[int]bool, len(names))
+	for _, name := range names {
+		idx := t.columnIndex(name)
+		if idx < 0 {
+			return fmt.Errorf("unknown column %q", name)
+		}
+		visible[idx] = true
+	}
+
+	for i := range t.config.Columns {
+		t.config.Columns[i].Hidden = len(names) > 0 && !visible[i]
+	}
+	t.refresh()
+	return nil
+}
+
+// SetSort sorts by column in the given direction. A negative column clears
+// the sort and restores the original data order.
+func (t *Table) SetSort(column int, ascending bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if column >= len(t.config.Columns) || (column >= 0 && !t.config.Columns[column].Sortable) {
+		return
+	}
+	if column < 0 {
+		column, ascending = -1, true
+	}
+
+	t.sortColumn = column
+	t.sortAscending = ascending
+	t.applyFilter()
+	t.applySort()
+	t.refresh()
+
+	if column >= 0 && t.onSort != nil {
+		t.onSort(column, ascending)
+	}
 }
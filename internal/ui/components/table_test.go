@@ -0,0 +1,109 @@
+package components
+
+import (
+	"fmt"
+	"testing"
+)
+
+func newTestTable() *Table {
+	config := DefaultTableConfig()
+	config.Columns = []Column{
+		NewColumn("ID").Build(),
+		NewColumn("User").Width(4).Build(),
+		NewColumn("State").Sortable(true).Build(),
+	}
+	return NewTable(config)
+}
+
+func TestTable_VirtualContent(t *testing.T) {
+	table := newTestTable()
+	rows := make([][]string, 10000)
+	for i := range rows {
+		rows[i] = []string{fmt.Sprintf("%05d", i), "someone", "RUNNING"}
+	}
+	table.SetData(rows)
+
+	if got := table.GetRowCount(); got != len(rows)+1 {
+		t.Fatalf("GetRowCount() = %d, want %d", got, len(rows)+1)
+	}
+	if got := table.GetCell(0, 0).Text; got != "ID" {
+		t.Errorf("Header cell = %q, want ID", got)
+	}
+	if got := table.GetCell(9001, 1).Text; got != "s..." {
+		t.Errorf("Expected the user to be truncated to the column width, got %q", got)
+	}
+	if got := table.GetCell(9001, 0).Text; got != "09000" {
+		t.Errorf("Cell(9001, 0) = %q, want 09000", got)
+	}
+
+	// Hidden columns are skipped
+	if err := table.SetVisibleColumns([]string{"ID", "State"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := table.GetColumnCount(); got != 2 {
+		t.Errorf("GetColumnCount() = %d, want 2", got)
+	}
+	if got := table.GetCell(1, 1).Text; got != "RUNNING" {
+		t.Errorf("Cell(1, 1) = %q, want RUNNING", got)
+	}
+}
+
+func TestTable_SetDataDiff(t *testing.T) {
+	table := newTestTable()
+	table.SetData([][]string{
+		{"1", "alice", "RUNNING"},
+		{"2", "bob", "PENDING"},
+		{"3", "carol", "PENDING"},
+	})
+	table.Select(3, 0) // Job 3
+
+	table.SetData([][]string{
+		{"1", "alice", "RUNNING"},
+		{"2", "bob", "PENDING"},
+		{"3", "carol", "PENDING"},
+	})
+	if diff := table.LastDiff(); !diff.Empty() || diff.Unchanged != 3 {
+		t.Errorf("Expected an empty diff, got %+v", diff)
+	}
+
+	table.SetData([][]string{
+		{"0", "dave", "PENDING"},
+		{"1", "alice", "COMPLETED"},
+		{"3", "carol", "PENDING"},
+	})
+	diff := table.LastDiff()
+	if diff.Added != 1 || diff.Removed != 1 || diff.Changed != 1 || diff.Unchanged != 1 {
+		t.Errorf("Unexpected diff %+v", diff)
+	}
+	if data := table.GetSelectedData(); len(data) == 0 || data[0] != "3" {
+		t.Errorf("Expected the selection to stay on job 3, got %v", data)
+	}
+}
+
+func TestTable_OnNeedMore(t *testing.T) {
+	table := newTestTable()
+	rows := make([][]string, 100)
+	for i := range rows {
+		rows[i] = []string{fmt.Sprint(i), "user", "RUNNING"}
+	}
+	table.SetData(rows)
+
+	calls := 0
+	table.SetOnNeedMore(10, func() { calls++ })
+
+	table.Select(50, 0)
+	if calls != 0 {
+		t.Fatalf("Expected no call far from the end, got %d", calls)
+	}
+	table.Select(95, 0)
+	table.Select(96, 0)
+	if calls != 1 {
+		t.Fatalf("Expected one call near the end, got %d", calls)
+	}
+
+	table.SetData(append(rows, []string{"100", "user", "RUNNING"}))
+	table.Select(100, 0)
+	if calls != 2 {
+		t.Errorf("Expected another call after more rows were loaded, got %d", calls)
+	}
+}
@@ -0,0 +1,468 @@
+package filters
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// FilterNode is a node of a parsed filter expression. Conditions are
+// *FilterExpression leaves combined by AndNode, OrNode and NotNode.
+type FilterNode interface {
+	Evaluate(data map[string]interface{}) bool
+}
+
+// AndNode matches when all of its children match
+type AndNode struct {
+	Children []FilterNode
+}
+
+// Evaluate evaluates the conjunction
+func (n *AndNode) Evaluate(data map[string]interface{}) bool {
+	for _, child := range n.Children {
+		if !child.Evaluate(data) {
+			return false
+		}
+	}
+	return true
+}
+
+// OrNode matches when any of its children matches
+type OrNode struct {
+	Children []FilterNode
+}
+
+// Evaluate evaluates the disjunction
+func (n *OrNode) Evaluate(data map[string]interface{}) bool {
+	for _, child := range n.Children {
+		if child.Evaluate(data) {
+			return true
+		}
+	}
+	return false
+}
+
+// NotNode matches when its child does not
+type NotNode struct {
+	Child FilterNode
+}
+
+// Evaluate evaluates the negation
+func (n *NotNode) Evaluate(data map[string]interface{}) bool {
+	return !n.Child.Evaluate(data)
+}
+
+// ParseError is a filter syntax error
+type ParseError struct {
+	Pos int // 1-based position in the filter string
+	Msg string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s at position %d", e.Msg, e.Pos)
+}
+
+// comparisonOperators are the infix operators, longest first so that "!="
+// is not read as "!" followed by "="
+var comparisonOperators = []struct {
+	op  string
+	typ FilterOperator
+}{
+	{"!=", OpNotEquals},
+	{"!~", OpNotContains},
+	{">=", OpGreaterEq},
+	{"<=", OpLessEq},
+	{"=~", OpRegex},
+	{"=", OpEquals},
+	{"~", OpContains},
+	{">", OpGreater},
+	{"<", OpLess},
+}
+
+// exprParser is a recursive descent parser for filter strings:
+//
+//	or      = and { "or" and }
+//	and     = unary { ["and"] unary }      juxtaposed conditions are AND'ed
+//	unary   = "not" unary | primary
+//	primary = "(" or ")" | field op value | field ["not"] "in" list
+//
+// Keywords are case-insensitive; "and" binds tighter than "or".
+type exprParser struct {
+	parser *FilterParser
+	src    string
+	pos    int
+	leaves []FilterExpression
+}
+
+// parse parses the whole input
+func (p *exprParser) parse() (FilterNode, error) {
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.src) {
+		if p.src[p.pos] == ')' {
+			return nil, p.errorf("unexpected )")
+		}
+		return nil, p.errorf("unexpected %q", p.src[p.pos:])
+	}
+	return node, nil
+}
+
+func (p *exprParser) parseOr() (FilterNode, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	children := []FilterNode{first}
+	for p.acceptKeyword("or") {
+		next, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, next)
+	}
+	if len(children) == 1 {
+		return first, nil
+	}
+	return &OrNode{Children: children}, nil
+}
+
+func (p *exprParser) parseAnd() (FilterNode, error) {
+	first, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	children := []FilterNode{first}
+	for {
+		if !p.acceptKeyword("and") {
+			// Juxtaposition is AND unless the group or input ends
+			p.skipSpace()
+			if p.pos >= len(p.src) || p.src[p.pos] == ')' || p.peekKeyword("or") {
+				break
+			}
+		}
+		next, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, next)
+	}
+	if len(children) == 1 {
+		return first, nil
+	}
+	return &AndNode{Children: children}, nil
+}
+
+func (p *exprParser) parseUnary() (FilterNode, error) {
+	if p.acceptKeyword("not") {
+		child, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &NotNode{Child: child}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *exprParser) parsePrimary() (FilterNode, error) {
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return nil, p.errorf("expected a condition")
+	}
+	if p.src[p.pos] != '(' {
+		return p.parseCondition()
+	}
+
+	open := p.pos
+	p.pos++
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos >= len(p.src) || p.src[p.pos] != ')' {
+		return nil, &ParseError{Pos: open + 1, Msg: "unclosed ("}
+	}
+	p.pos++
+	return node, nil
+}
+
+// parseCondition parses a single field comparison
+func (p *exprParser) parseCondition() (FilterNode, error) {
+	start := p.pos
+	for p.pos < len(p.src) && isFieldChar(p.src[p.pos]) {
+		p.pos++
+	}
+	if p.pos == start {
+		return nil, p.errorf("expected a field name")
+	}
+	rawField := p.src[start:p.pos]
+	field := p.parser.normalizeField(rawField)
+
+	expr := FilterExpression{Field: field}
+	switch {
+	case p.acceptKeyword("in"):
+		expr.Operator = OpIn
+	case p.acceptKeywords("not", "in"):
+		expr.Operator = OpNotIn
+	}
+	if expr.Operator != "" {
+		values, err := p.parseList()
+		if err != nil {
+			return nil, err
+		}
+		expr.Value = values
+		return p.addLeaf(expr), nil
+	}
+
+	p.skipSpace()
+	for _, op := range comparisonOperators {
+		if strings.HasPrefix(p.src[p.pos:], op.op) {
+			expr.Operator = op.typ
+			p.pos += len(op.op)
+			break
+		}
+	}
+	if expr.Operator == "" {
+		return nil, p.errorf("expected an operator after %q", rawField)
+	}
+
+	p.skipSpace()
+	valuePos := p.pos
+	raw, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	if raw == "" {
+		return nil, p.errorf("missing value for %s", rawField)
+	}
+
+	value, err := p.convertValue(expr.Field, expr.Operator, raw)
+	if err != nil {
+		return nil, &ParseError{Pos: valuePos + 1, Msg: err.Error()}
+	}
+	expr.Value = value
+	return p.addLeaf(expr), nil
+}
+
+// convertValue converts a raw value according to the operator and field
+func (p *exprParser) convertValue(field string, op FilterOperator, raw string) (interface{}, error) {
+	switch op {
+	case OpRegex:
+		if _, err := regexp.Compile(raw); err != nil {
+			return nil, fmt.Errorf("invalid regex %q", raw)
+		}
+		return raw, nil
+	case OpContains, OpNotContains:
+		return raw, nil
+	}
+
+	if IsDateField(field) && p.parser.dateRange != nil {
+		dateRange, err := p.parser.dateRange(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date %q", raw)
+		}
+		dateRange.Field = field
+		return dateRange, nil
+	}
+	return p.parser.parseValue(raw), nil
+}
+
+// addLeaf records a condition and returns it as a node
+func (p *exprParser) addLeaf(expr FilterExpression) FilterNode {
+	p.leaves = append(p.leaves, expr)
+	return &expr
+}
+
+// parseValue reads a quoted string or a bare word. Bare words end at
+// whitespace or an unbalanced ")", so regexes may contain groups.
+func (p *exprParser) parseValue() (string, error) {
+	if p.pos < len(p.src) && (p.src[p.pos] == '"' || p.src[p.pos] == '\'') {
+		return p.parseQuoted()
+	}
+
+	start := p.pos
+	depth := 0
+	for ; p.pos < len(p.src); p.pos++ {
+		c := p.src[p.pos]
+		if c == ' ' || c == '\t' {
+			break
+		}
+		if c == '(' {
+			depth++
+		}
+		if c == ')' {
+			if depth == 0 {
+				break
+			}
+			depth--
+		}
+	}
+	return p.src[start:p.pos], nil
+}
+
+// parseQuoted reads a string in single or double quotes
+func (p *exprParser) parseQuoted() (string, error) {
+	quote := p.src[p.pos]
+	start := p.pos
+	end := strings.IndexByte(p.src[start+1:], quote)
+	if end < 0 {
+		return "", &ParseError{Pos: start + 1, Msg: "unterminated string"}
+	}
+	p.pos = start + 1 + end + 1
+	return p.src[start+1 : start+1+end], nil
+}
+
+// parseList reads the values of "in": "(a, b, c)" or "a,b,c"
+func (p *exprParser) parseList() ([]string, error) {
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return nil, p.errorf("expected a list of values")
+	}
+
+	if p.src[p.pos] != '(' {
+		raw, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		if raw == "" {
+			return nil, p.errorf("expected a list of values")
+		}
+		values := strings.Split(raw, ",")
+		for i := range values {
+			values[i] = strings.Trim(values[i], "\"'")
+		}
+		return values, nil
+	}
+
+	open := p.pos
+	p.pos++
+	var values []string
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.src) {
+			return nil, &ParseError{Pos: open + 1, Msg: "unclosed ("}
+		}
+		var value string
+		if c := p.src[p.pos]; c == '"' || c == '\'' {
+			v, err := p.parseQuoted()
+			if err != nil {
+				return nil, err
+			}
+			value = v
+		} else {
+			start := p.pos
+			for p.pos < len(p.src) && !strings.ContainsRune(",) \t", rune(p.src[p.pos])) {
+				p.pos++
+			}
+			value = p.src[start:p.pos]
+		}
+		if value == "" {
+			return nil, p.errorf("expected a value")
+		}
+		values = append(values, value)
+
+		p.skipSpace()
+		if p.pos >= len(p.src) {
+			return nil, &ParseError{Pos: open + 1, Msg: "unclosed ("}
+		}
+		switch p.src[p.pos] {
+		case ',':
+			p.pos++
+		case ')':
+			p.pos++
+			return values, nil
+		default:
+			return nil, p.errorf("expected , or )")
+		}
+	}
+}
+
+func (p *exprParser) skipSpace() {
+	for p.pos < len(p.src) && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// peekKeyword reports whether the next word is the given keyword
+func (p *exprParser) peekKeyword(keyword string) bool {
+	p.skipSpace()
+	end := p.pos + len(keyword)
+	if end > len(p.src) || !strings.EqualFold(p.src[p.pos:end], keyword) {
+		return false
+	}
+	// The keyword must end the word, so "order=1" is a condition
+	return end == len(p.src) || p.src[end] == ' ' || p.src[end] == '\t' || p.src[end] == '('
+}
+
+// acceptKeyword consumes the keyword if it is next
+func (p *exprParser) acceptKeyword(keyword string) bool {
+	if !p.peekKeyword(keyword) {
+		return false
+	}
+	p.pos += len(keyword)
+	return true
+}
+
+// acceptKeywords consumes a sequence of keywords, or nothing
+func (p *exprParser) acceptKeywords(keywords ...string) bool {
+	saved := p.pos
+	for _, keyword := range keywords {
+		if !p.acceptKeyword(keyword) {
+			p.pos = saved
+			return false
+		}
+	}
+	return true
+}
+
+func (p *exprParser) errorf(format string, args ...interface{}) error {
+	return &ParseError{Pos: p.pos + 1, Msg: fmt.Sprintf(format, args...)}
+}
+
+// isFieldChar reports whether c may appear in a field name
+func isFieldChar(c byte) bool {
+	return c == '_' || c == '.' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// evaluateDateRange compares a timestamp with a date range. "=" matches
+// within the range, ">" after it and "<" before it.
+func evaluateDateRange(operator FilterOperator, value interface{}, r *DateRangeFilter) bool {
+	t, ok := timeValue(value)
+	if !ok || r.Start == nil || r.End == nil {
+		return false
+	}
+	before, after := t.Before(*r.Start), t.After(*r.End)
+	switch operator {
+	case OpEquals:
+		return !before && !after
+	case OpNotEquals:
+		return before || after
+	case OpGreater:
+		return after
+	case OpGreaterEq:
+		return !before
+	case OpLess:
+		return before
+	case OpLessEq:
+		return !after
+	}
+	return false
+}
+
+// timeValue extracts a set timestamp from a filter data value
+func timeValue(v interface{}) (time.Time, bool) {
+	switch val := v.(type) {
+	case time.Time:
+		return val, !val.IsZero()
+	case *time.Time:
+		if val == nil || val.IsZero() {
+			return time.Time{}, false
+		}
+		return *val, true
+	}
+	return time.Time{}, false
+}
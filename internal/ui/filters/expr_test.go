@@ -0,0 +1,142 @@
+package filters
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBooleanFilterExpressions(t *testing.T) {
+	parser := NewFilterParser()
+
+	pendingGPU := map[string]interface{}{"State": "PENDING", "Partition": "gpu", "User": "bob", "Priority": 10}
+	pendingCPU := map[string]interface{}{"State": "PENDING", "Partition": "cpu", "User": "bob", "Priority": 10}
+	aliceHigh := map[string]interface{}{"State": "RUNNING", "Partition": "cpu", "User": "alice", "Priority": 5000}
+	aliceLow := map[string]interface{}{"State": "RUNNING", "Partition": "cpu", "User": "alice", "Priority": 10}
+
+	testCases := []struct {
+		name     string
+		filter   string
+		data     map[string]interface{}
+		expected bool
+	}{
+		{"grouped_or_first", "(state=PENDING and partition=gpu) or (user=alice and priority>1000)", pendingGPU, true},
+		{"grouped_or_second", "(state=PENDING and partition=gpu) or (user=alice and priority>1000)", aliceHigh, true},
+		{"grouped_or_none", "(state=PENDING and partition=gpu) or (user=alice and priority>1000)", aliceLow, false},
+		{"grouped_or_partial", "(state=PENDING and partition=gpu) or (user=alice and priority>1000)", pendingCPU, false},
+		{"and_binds_tighter", "state=PENDING and partition=gpu or user=alice", aliceLow, true},
+		{"and_binds_tighter_false", "state=PENDING and partition=gpu or user=alice", pendingCPU, false},
+		{"juxtaposition_is_and", "state=PENDING partition=gpu or user=alice", pendingCPU, false},
+		{"parentheses_override", "state=PENDING and (partition=gpu or user=alice)", pendingCPU, false},
+		{"not", "not state=RUNNING", pendingCPU, true},
+		{"not_group", "not (user=alice or partition=gpu)", pendingCPU, true},
+		{"not_group_false", "not (user=alice or partition=gpu)", aliceLow, false},
+		{"keywords_case_insensitive", "user=alice AND NOT priority<100", aliceHigh, true},
+		{"not_in", "user not in (alice, carol) and state in (PENDING,RUNNING)", pendingGPU, true},
+		{"quoted_value", `partition="gpu" or user='nobody here'`, pendingGPU, true},
+		{"spaces_around_operator", "priority > 1000", aliceHigh, true},
+		{"regex_group", "user=~^(alice|carol)$", aliceLow, true},
+		{"regex_group_in_parens", "(user=~(alice|carol) and priority>=5000)", aliceHigh, true},
+		{"field_prefixed_by_keyword", "order=1 or notes~x", map[string]interface{}{"Order": 1}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			filter, err := parser.Parse(tc.filter)
+			if err != nil {
+				t.Fatalf("Failed to parse filter '%s': %v", tc.filter, err)
+			}
+			if result := filter.Evaluate(tc.data); result != tc.expected {
+				t.Errorf("Filter '%s' with data %v: expected %v, got %v", tc.filter, tc.data, tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestBooleanFilterKeepsConditions(t *testing.T) {
+	filter, err := NewFilterParser().Parse("(state=PENDING and partition=gpu) or not user=alice")
+	if err != nil {
+		t.Fatalf("Failed to parse filter: %v", err)
+	}
+	if len(filter.Expressions) != 3 {
+		t.Fatalf("Expected 3 expressions, got %d", len(filter.Expressions))
+	}
+	if filter.Expressions[2].Field != "User" {
+		t.Errorf("Expected the last condition on User, got %s", filter.Expressions[2].Field)
+	}
+	if _, ok := filter.Root.(*OrNode); !ok {
+		t.Errorf("Expected an OR at the root, got %T", filter.Root)
+	}
+}
+
+func TestFilterParseErrorPositions(t *testing.T) {
+	parser := NewFilterParser()
+
+	testCases := []struct {
+		filter string
+		pos    int
+	}{
+		{"(state=PENDING and partition=gpu", 1},
+		{"state=PENDING)", 14},
+		{"state=PENDING and", 18},
+		{"state @ running", 7},
+		{"state=", 7},
+		{"user=alice or (", 16},
+		{`name="unterminated`, 6},
+		{"name=~[a-z", 7},
+		{"state in (a, b", 10},
+		{"submittime=someday", 12},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.filter, func(t *testing.T) {
+			_, err := parser.Parse(tc.filter)
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("Expected a ParseError for '%s', got %v", tc.filter, err)
+			}
+			if parseErr.Pos != tc.pos {
+				t.Errorf("Filter '%s': expected error at position %d, got %d (%v)", tc.filter, tc.pos, parseErr.Pos, err)
+			}
+		})
+	}
+}
+
+func TestDateFilterExpressions(t *testing.T) {
+	parser := NewFilterParser()
+	now := time.Now()
+	twoDaysAgo := now.Add(-48 * time.Hour)
+	jan15 := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		filter   string
+		value    interface{}
+		expected bool
+	}{
+		{"today", "submittime=today", now, true},
+		{"today_false", "submittime=today", twoDaysAgo, false},
+		{"not_today", "submittime!=today", twoDaysAgo, true},
+		{"relative", `submittime="last 7 days"`, twoDaysAgo, true},
+		{"range", "submittime=2024-01-01..2024-01-31", jan15, true},
+		{"after_day", "submittime>2024-01-14", jan15, true},
+		{"before_day", "submittime<2024-01-15", jan15, false},
+		{"on_or_before_day", "submittime<=2024-01-15", jan15, true},
+		{"pointer", "starttime=today", &now, true},
+		{"nil_pointer", "starttime=today", (*time.Time)(nil), false},
+		{"combined", "submittime=yesterday or submittime=today", now, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			filter, err := parser.Parse(tc.filter)
+			if err != nil {
+				t.Fatalf("Failed to parse filter '%s': %v", tc.filter, err)
+			}
+			field := filter.Expressions[0].Field
+			if result := filter.Evaluate(map[string]interface{}{field: tc.value}); result != tc.expected {
+				t.Errorf("Filter '%s' with %v: expected %v, got %v", tc.filter, tc.value, tc.expected, result)
+			}
+		})
+	}
+}
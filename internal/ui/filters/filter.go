@@ -45,15 +45,18 @@ type FilterExpression struct {
 
 // Filter represents a complex filter with multiple expressions
 type Filter struct {
-	Expressions []FilterExpression
-	Logic       string // "AND" or "OR"
-	Name        string // For saved filters
+	Expressions []FilterExpression // Every condition of the filter, in input order
+	Logic       string             // "AND" or "OR"; used when Root is nil
+	Root        FilterNode         // Parsed expression tree; nil for filters built from Expressions
+	Source      string             // Filter string the filter was parsed from
+	Name        string             // For saved filters
 	Description string
 }
 
 // FilterParser parses filter strings into filter expressions
 type FilterParser struct {
 	fieldAliases map[string]string
+	dateRange    func(string) (*DateRangeFilter, error) // Parses values of date fields
 }
 
 // NewFilterParser creates a new filter parser
@@ -61,23 +64,36 @@ func NewFilterParser() *FilterParser {
 	return &FilterParser{
 		fieldAliases: map[string]string{
 			// Common aliases
-			"name":      "Name",
-			"user":      "User",
-			"state":     "State",
-			"partition": "Partition",
-			"status":    "State",
-			"node":      "NodeList",
-			"nodes":     "NodeList",
-			"time":      "TimeUsed",
-			"timelimit": "TimeLimit",
-			"cpu":       "CPUs",
-			"cpus":      "CPUs",
-			"mem":       "Memory",
-			"memory":    "Memory",
-			"account":   "Account",
-			"qos":       "QoS",
-			"priority":  "Priority",
+			"name":       "Name",
+			"user":       "User",
+			"state":      "State",
+			"partition":  "Partition",
+			"status":     "State",
+			"basestate":  "BaseState",
+			"flags":      "StateFlags",
+			"stateflags": "StateFlags",
+			"node":       "NodeList",
+			"nodes":      "NodeList",
+			"time":       "TimeUsed",
+			"timelimit":  "TimeLimit",
+			"cpu":        "CPUs",
+			"cpus":       "CPUs",
+			"mem":        "Memory",
+			"memory":     "Memory",
+			"account":    "Account",
+			"qos":        "QoS",
+			"priority":   "Priority",
+			"gpu":        "GPUs",
+			"gpus":       "GPUs",
+			"gputype":    "GPUType",
+			"gpumodel":   "GPUType",
+			"gpusfree":   "GPUsFree",
+			"freegpus":   "GPUsFree",
+			"labels":     "Label",
+			"notes":      "Note",
+			"bookmark":   "Bookmarked",
 		},
+		dateRange: (&AdvancedFilterParser{dateFormats: defaultDateFormats}).ParseDateRange,
 	}
 }
 
@@ -88,101 +104,28 @@ func NewFilterParser() *FilterParser {
 //   - "memory>4G cpus>=8"
 //   - "name~test partition=gpu"
 //   - "state in (running,pending)"
+//   - "(state=pending and partition=gpu) or (user=alice and priority>1000)"
+//   - "not state=completed submittime=\"last 7 days\""
+//
+// Conditions next to each other are AND'ed. Errors are *ParseError values
+// carrying the position of the problem.
 func (p *FilterParser) Parse(filterStr string) (*Filter, error) {
-	if filterStr == "" {
-		return &Filter{Logic: "AND"}, nil
+	if strings.TrimSpace(filterStr) == "" {
+		return &Filter{Logic: "AND", Source: filterStr}, nil
 	}
 
-	filter := &Filter{
-		Logic:       "AND",
-		Expressions: []FilterExpression{},
-	}
-
-	// Pre-process for multi-word operators like "in" and "not in"
-	parts := p.smartSplit(filterStr)
-
-	for _, part := range parts {
-		expr, err := p.parseExpression(part)
-		if err != nil {
-			return nil, fmt.Errorf("invalid filter expression '%s': %w", part, err)
-		}
-		filter.Expressions = append(filter.Expressions, *expr)
-	}
-
-	return filter, nil
-}
-
-// parseExpression parses a single filter expression
-func (p *FilterParser) parseExpression(expr string) (*FilterExpression, error) {
-	// Check for "not in" operator FIRST (longer pattern)
-	if strings.Contains(expr, " not in ") {
-		parts := strings.SplitN(expr, " not in ", 2)
-		if len(parts) != 2 {
-			return nil, fmt.Errorf("invalid 'not in' expression")
-		}
-		field := p.normalizeField(strings.TrimSpace(parts[0]))
-		valueList := strings.Trim(parts[1], "()")
-		values := strings.Split(valueList, ",")
-		for i := range values {
-			values[i] = strings.TrimSpace(values[i])
-		}
-		return &FilterExpression{
-			Field:    field,
-			Operator: OpNotIn,
-			Value:    values,
-		}, nil
-	}
-
-	// Check for "in" operator
-	if strings.Contains(expr, " in ") {
-		parts := strings.SplitN(expr, " in ", 2)
-		if len(parts) != 2 {
-			return nil, fmt.Errorf("invalid 'in' expression")
-		}
-		field := p.normalizeField(strings.TrimSpace(parts[0]))
-		valueList := strings.Trim(parts[1], "()")
-		values := strings.Split(valueList, ",")
-		for i := range values {
-			values[i] = strings.TrimSpace(values[i])
-		}
-		return &FilterExpression{
-			Field:    field,
-			Operator: OpIn,
-			Value:    values,
-		}, nil
-	}
-
-	// Regular operators
-	operators := []struct {
-		op  string
-		typ FilterOperator
-	}{
-		{"!=", OpNotEquals},
-		{"!~", OpNotContains},
-		{">=", OpGreaterEq},
-		{"<=", OpLessEq},
-		{"=~", OpRegex},
-		{"=", OpEquals},
-		{"~", OpContains},
-		{">", OpGreater},
-		{"<", OpLess},
-	}
-
-	for _, op := range operators {
-		if idx := strings.Index(expr, op.op); idx > 0 {
-			field := p.normalizeField(strings.TrimSpace(expr[:idx]))
-			value := strings.TrimSpace(expr[idx+len(op.op):])
-			value = strings.Trim(value, "\"'")
-
-			return &FilterExpression{
-				Field:    field,
-				Operator: op.typ,
-				Value:    p.parseValue(value),
-			}, nil
-		}
+	ep := &exprParser{parser: p, src: filterStr}
+	root, err := ep.parse()
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter: %w", err)
 	}
 
-	return nil, fmt.Errorf("no valid operator found")
+	return &Filter{
+		Logic:       "AND",
+		Root:        root,
+		Expressions: ep.leaves,
+		Source:      filterStr,
+	}, nil
 }
 
 // normalizeField converts field aliases to canonical field names
@@ -229,6 +172,9 @@ func (p *FilterParser) parseValue(value string) interface{} {
 
 // Evaluate evaluates a filter against a data object
 func (f *Filter) Evaluate(data map[string]interface{}) bool {
+	if f.Root != nil {
+		return f.Root.Evaluate(data)
+	}
 	if len(f.Expressions) == 0 {
 		return true
 	}
@@ -258,11 +204,31 @@ func (e *FilterExpression) Evaluate(data map[string]interface{}) bool {
 		return false
 	}
 
+	if values, ok := value.([]string); ok {
+		return evaluateList(e.Operator, values, e.Value)
+	}
 	return evaluateOperator(e.Operator, value, e.Value)
 }
 
+// evaluateList applies an operator to a field with several values, such as
+// the labels of a job: a condition holds when any value satisfies it, and
+// a negated one when every value does, so label!=x excludes rows labelled x
+func evaluateList(operator FilterOperator, values []string, expected interface{}) bool {
+	negated := operator == OpNotEquals || operator == OpNotContains || operator == OpNotIn
+	for _, value := range values {
+		if evaluateOperator(operator, value, expected) != negated {
+			return !negated
+		}
+	}
+	return negated
+}
+
 // evaluateOperator applies the appropriate comparison operator
 func evaluateOperator(operator FilterOperator, value, expected interface{}) bool {
+	if dateRange, ok := expected.(*DateRangeFilter); ok {
+		return evaluateDateRange(operator, value, dateRange)
+	}
+
 	// Handle special cases that require multiple comparisons
 	if operator == OpGreaterEq {
 		return compareGreater(value, expected) || compareEqual(value, expected)
@@ -429,24 +395,3 @@ func processQuoteChar(inQuotes *bool, quoteChar *rune, r rune, current *strings.
 		current.WriteRune(r)
 	}
 }
-
-// smartSplit splits filter string while respecting multi-word operators like "in" and "not in"
-func (p *FilterParser) smartSplit(s string) []string {
-	// Pre-process to handle multi-word operators
-	// Replace " not in " with a placeholder first (longer match) - no spaces in placeholder
-	s = strings.ReplaceAll(s, " not in ", "__NOT_IN__")
-	// Replace " in " with a placeholder (but not the ones already replaced) - no spaces in placeholder
-	s = strings.ReplaceAll(s, " in ", "__IN__")
-
-	// Now split normally
-	parts := SplitRespectingQuotes(s)
-
-	// Post-process to restore operators
-	for i, part := range parts {
-		part = strings.ReplaceAll(part, "__NOT_IN__", " not in ")
-		part = strings.ReplaceAll(part, "__IN__", " in ")
-		parts[i] = part
-	}
-
-	return parts
-}
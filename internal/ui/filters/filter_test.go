@@ -331,3 +331,40 @@ func TestComplexFilters(t *testing.T) {
 		})
 	}
 }
+
+func TestListFields(t *testing.T) {
+	parser := NewFilterParser()
+	labelled := map[string]interface{}{"Label": []string{"baseline", "lr-sweep"}}
+	unlabelled := map[string]interface{}{"Label": []string{}}
+
+	testCases := []struct {
+		filter     string
+		labelled   bool
+		unlabelled bool
+	}{
+		{"label=baseline", true, false},
+		{"labels=lr-sweep", true, false},
+		{"label=other", false, false},
+		{"label!=baseline", false, true},
+		{"label!=other", true, true},
+		{"label~sweep", true, false},
+		{"label!~sweep", false, true},
+		{"label in (other,baseline)", true, false},
+		{"not label=baseline", false, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.filter, func(t *testing.T) {
+			filter, err := parser.Parse(tc.filter)
+			if err != nil {
+				t.Fatalf("Failed to parse filter '%s': %v", tc.filter, err)
+			}
+			if got := filter.Evaluate(labelled); got != tc.labelled {
+				t.Errorf("labelled: expected %v, got %v", tc.labelled, got)
+			}
+			if got := filter.Evaluate(unlabelled); got != tc.unlabelled {
+				t.Errorf("unlabelled: expected %v, got %v", tc.unlabelled, got)
+			}
+		})
+	}
+}
@@ -173,19 +173,19 @@ func (m *PresetManager) createDefaultPresets() {
 			Name:        "Available Nodes",
 			Description: "Show idle and mixed nodes",
 			ViewType:    "nodes",
-			FilterStr:   "state in (IDLE,MIXED)",
+			FilterStr:   "basestate in (IDLE,MIXED) flags!~DRAIN flags!~NOT_RESPONDING",
 		},
 		{
 			Name:        "Down Nodes",
 			Description: "Show down and drain nodes",
 			ViewType:    "nodes",
-			FilterStr:   "state in (DOWN,DRAIN,DRAINING)",
+			FilterStr:   "state=~^DOWN|DRAIN|NOT_RESPONDING|FAIL",
 		},
 		{
 			Name:        "GPU Nodes",
 			Description: "Show nodes with GPU resources",
 			ViewType:    "nodes",
-			FilterStr:   "features~gpu",
+			FilterStr:   "gpus>0",
 		},
 		{
 			Name:        "High Memory",
@@ -207,9 +207,9 @@ func (m *PresetManager) createDefaultPresets() {
 		},
 		{
 			Name:        "Multi-GPU Nodes",
-			Description: "Nodes with multiple GPU features",
+			Description: "Nodes with more than one GPU",
 			ViewType:    "nodes",
-			FilterStr:   "features=~gpu.*gpu|features~v100|features~a100",
+			FilterStr:   "gpus>1",
 		},
 		{
 			Name:        "Specific Node Pattern",
@@ -0,0 +1,90 @@
+package filters
+
+import (
+	"fmt"
+	"slices"
+)
+
+// RequiredValues returns the values field must equal for a row to match the
+// filter, so the condition can be pushed down to the data source. ok is
+// false when the filter does not restrict field to a fixed set of values,
+// e.g. when it is only compared with ~, != or inside a not. An empty set
+// with ok true means no row can match.
+func (f *Filter) RequiredValues(field string) (values []string, ok bool) {
+	if f == nil {
+		return nil, false
+	}
+	if f.Root != nil {
+		return requiredValues(f.Root, field)
+	}
+
+	children := make([]FilterNode, len(f.Expressions))
+	for i := range f.Expressions {
+		children[i] = &f.Expressions[i]
+	}
+	if f.Logic == "OR" {
+		return requiredValues(&OrNode{Children: children}, field)
+	}
+	return requiredValues(&AndNode{Children: children}, field)
+}
+
+// requiredValues computes RequiredValues for a node
+func requiredValues(node FilterNode, field string) ([]string, bool) {
+	switch n := node.(type) {
+	case *FilterExpression:
+		if n.Field != field {
+			return nil, false
+		}
+		switch n.Operator {
+		case OpEquals:
+			return []string{fmt.Sprintf("%v", n.Value)}, true
+		case OpIn:
+			list, _ := n.Value.([]string)
+			return dedupe(list), true
+		}
+		return nil, false
+
+	case *AndNode:
+		// Every constrained child must hold, so the values intersect
+		var values []string
+		constrained := false
+		for _, child := range n.Children {
+			childValues, ok := requiredValues(child, field)
+			if !ok {
+				continue
+			}
+			if !constrained {
+				values, constrained = childValues, true
+				continue
+			}
+			values = slices.DeleteFunc(values, func(v string) bool {
+				return !slices.Contains(childValues, v)
+			})
+		}
+		return values, constrained
+
+	case *OrNode:
+		// Any child may hold, so every child must be constrained
+		var values []string
+		for _, child := range n.Children {
+			childValues, ok := requiredValues(child, field)
+			if !ok {
+				return nil, false
+			}
+			values = append(values, childValues...)
+		}
+		return dedupe(values), true
+	}
+	return nil, false
+}
+
+// dedupe returns values without duplicates, in their first order
+func dedupe(values []string) []string {
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if !slices.Contains(result, v) {
+			result = append(result, v)
+		}
+	}
+	return result
+}
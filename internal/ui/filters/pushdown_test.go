@@ -0,0 +1,69 @@
+package filters
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFilterRequiredValues(t *testing.T) {
+	parser := NewFilterParser()
+
+	testCases := []struct {
+		name   string
+		filter string
+		field  string
+		values []string
+		ok     bool
+	}{
+		{"empty", "", "State", nil, false},
+		{"equals", "state=PENDING", "State", []string{"PENDING"}, true},
+		{"other_field", "state=PENDING", "User", nil, false},
+		{"in", "state in (PENDING,RUNNING,PENDING)", "State", []string{"PENDING", "RUNNING"}, true},
+		{"and_with_other_fields", "state=PENDING and user=alice and priority>10", "User", []string{"alice"}, true},
+		{"and_intersects", "state in (PENDING,RUNNING) and state=RUNNING", "State", []string{"RUNNING"}, true},
+		{"and_disjoint", "state=PENDING and state=RUNNING", "State", []string{}, true},
+		{"or_unions", "state=PENDING or state in (RUNNING,PENDING)", "State", []string{"PENDING", "RUNNING"}, true},
+		{"or_unconstrained_branch", "state=PENDING or user=alice", "State", nil, false},
+		{"grouped", "(state=PENDING and partition=gpu) or (partition=cpu and user=alice)", "Partition", []string{"gpu", "cpu"}, true},
+		{"not", "not state=PENDING", "State", nil, false},
+		{"not_in", "state not in (PENDING)", "State", nil, false},
+		{"contains", "partition~gpu", "Partition", nil, false},
+		{"not_equals", "user!=alice", "User", nil, false},
+		{"alias", "account=physics", "Account", []string{"physics"}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			filter, err := parser.Parse(tc.filter)
+			if err != nil {
+				t.Fatalf("Parse(%q) failed: %v", tc.filter, err)
+			}
+			values, ok := filter.RequiredValues(tc.field)
+			if ok != tc.ok {
+				t.Fatalf("RequiredValues(%s) ok = %v, want %v", tc.field, ok, tc.ok)
+			}
+			if tc.ok && !reflect.DeepEqual(values, tc.values) {
+				t.Errorf("RequiredValues(%s) = %#v, want %#v", tc.field, values, tc.values)
+			}
+		})
+	}
+}
+
+func TestFilterRequiredValuesFromExpressions(t *testing.T) {
+	filter := &Filter{
+		Logic: "OR",
+		Expressions: []FilterExpression{
+			{Field: "User", Operator: OpEquals, Value: "alice"},
+			{Field: "User", Operator: OpEquals, Value: "bob"},
+		},
+	}
+	values, ok := filter.RequiredValues("User")
+	if !ok || !reflect.DeepEqual(values, []string{"alice", "bob"}) {
+		t.Errorf("RequiredValues(User) = %v, %v", values, ok)
+	}
+
+	var none *Filter
+	if _, ok := none.RequiredValues("User"); ok {
+		t.Error("Expected a nil filter to be unconstrained")
+	}
+}
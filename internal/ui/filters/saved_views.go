@@ -0,0 +1,248 @@
+package filters
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/jontk/s9s/internal/fileperms"
+)
+
+// savedViewsFile is the file user views are stored in
+const savedViewsFile = "views.json"
+
+// savedViewNamePattern restricts view names to what can be typed as a command argument
+var savedViewNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// SavedView is a named snapshot of a table view: which view it belongs to,
+// the advanced filter, the sort and the visible columns
+type SavedView struct {
+	Name           string   `json:"name"`
+	Description    string   `json:"description,omitempty"`
+	ViewType       string   `json:"view_type"` // "jobs", "nodes", ...
+	Filter         string   `json:"filter,omitempty"`
+	SortColumn     string   `json:"sort_column,omitempty"`
+	SortDescending bool     `json:"sort_descending,omitempty"`
+	Columns        []string `json:"columns,omitempty"`  // Visible columns; empty shows all
+	GroupBy        string   `json:"group_by,omitempty"` // Nodes view only
+	Shared         bool     `json:"-"`                  // Loaded from the shared directory
+}
+
+// Validate checks that the view can be saved and recalled
+func (v SavedView) Validate() error {
+	if !savedViewNamePattern.MatchString(v.Name) {
+		return fmt.Errorf("invalid view name %q: use letters, digits, '.', '-' and '_'", v.Name)
+	}
+	if v.ViewType == "" {
+		return fmt.Errorf("view %s has no view type", v.Name)
+	}
+	if _, err := NewFilterParser().Parse(v.Filter); err != nil {
+		return fmt.Errorf("view %s: %w", v.Name, err)
+	}
+	return nil
+}
+
+// SavedViewStore keeps named views in the user's ~/.s9s/views directory and
+// reads read-only views from an optional shared (team) directory. A user
+// view hides a shared view of the same name.
+type SavedViewStore struct {
+	mu        sync.RWMutex
+	userDir   string
+	sharedDir string
+	user      map[string]SavedView
+	shared    map[string]SavedView
+}
+
+// DefaultSavedViewsDir returns the directory user views are stored in
+func DefaultSavedViewsDir() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".s9s", "views")
+}
+
+// NewSavedViewStore creates a store for userDir and an optional sharedDir.
+// Views are not read until Load is called.
+func NewSavedViewStore(userDir, sharedDir string) *SavedViewStore {
+	return &SavedViewStore{
+		userDir:   userDir,
+		sharedDir: sharedDir,
+		user:      make(map[string]SavedView),
+		shared:    make(map[string]SavedView),
+	}
+}
+
+// Load (re)reads the user views and every *.json file of the shared
+// directory. Invalid entries are skipped and reported in the returned
+// error; the valid ones are still loaded.
+func (s *SavedViewStore) Load() error {
+	var problems []string
+
+	user, err := readSavedViews(filepath.Join(s.userDir, savedViewsFile))
+	if err != nil && !os.IsNotExist(err) {
+		problems = append(problems, err.Error())
+	}
+	userViews := indexSavedViews(user, false, &problems)
+
+	sharedViews := make(map[string]SavedView)
+	if s.sharedDir != "" {
+		files, err := filepath.Glob(filepath.Join(s.sharedDir, "*.json"))
+		if err != nil {
+			problems = append(problems, err.Error())
+		}
+		sort.Strings(files)
+		for _, file := range files {
+			views, err := readSavedViews(file)
+			if err != nil {
+				problems = append(problems, err.Error())
+				continue
+			}
+			for name, view := range indexSavedViews(views, true, &problems) {
+				sharedViews[name] = view
+			}
+		}
+	}
+
+	s.mu.Lock()
+	s.user = userViews
+	s.shared = sharedViews
+	s.mu.Unlock()
+
+	if len(problems) > 0 {
+		return fmt.Errorf("saved views: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// readSavedViews reads a JSON array of views from file
+func readSavedViews(file string) ([]SavedView, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var views []SavedView
+	if err := json.Unmarshal(data, &views); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return views, nil
+}
+
+// indexSavedViews keys views by name, dropping invalid ones
+func indexSavedViews(views []SavedView, shared bool, problems *[]string) map[string]SavedView {
+	indexed := make(map[string]SavedView, len(views))
+	for _, view := range views {
+		if err := view.Validate(); err != nil {
+			*problems = append(*problems, err.Error())
+			continue
+		}
+		view.Shared = shared
+		indexed[view.Name] = view
+	}
+	return indexed
+}
+
+// Get returns the named view
+func (s *SavedViewStore) Get(name string) (SavedView, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if view, ok := s.user[name]; ok {
+		return view, true
+	}
+	view, ok := s.shared[name]
+	return view, ok
+}
+
+// List returns every view sorted by name
+func (s *SavedViewStore) List() []SavedView {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	views := make([]SavedView, 0, len(s.user)+len(s.shared))
+	for _, view := range s.user {
+		views = append(views, view)
+	}
+	for name, view := range s.shared {
+		if _, hidden := s.user[name]; !hidden {
+			views = append(views, view)
+		}
+	}
+	sort.Slice(views, func(i, j int) bool { return views[i].Name < views[j].Name })
+	return views
+}
+
+// Names returns the names of every view, sorted
+func (s *SavedViewStore) Names() []string {
+	views := s.List()
+	names := make([]string, len(views))
+	for i, view := range views {
+		names[i] = view.Name
+	}
+	return names
+}
+
+// Save stores view as a user view, replacing any user view of the same name
+func (s *SavedViewStore) Save(view SavedView) error {
+	if err := view.Validate(); err != nil {
+		return err
+	}
+	view.Shared = false
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, existed := s.user[view.Name]
+	s.user[view.Name] = view
+	if err := s.writeUserViews(); err != nil {
+		if existed {
+			s.user[view.Name] = previous
+		} else {
+			delete(s.user, view.Name)
+		}
+		return err
+	}
+	return nil
+}
+
+// Delete removes the named user view. Shared views can only be removed
+// from the shared directory.
+func (s *SavedViewStore) Delete(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	view, ok := s.user[name]
+	if !ok {
+		if _, shared := s.shared[name]; shared {
+			return fmt.Errorf("view %s is shared and cannot be deleted here", name)
+		}
+		return fmt.Errorf("view %s not found", name)
+	}
+
+	delete(s.user, name)
+	if err := s.writeUserViews(); err != nil {
+		s.user[name] = view
+		return err
+	}
+	return nil
+}
+
+// writeUserViews writes the user views to disk; callers hold s.mu
+func (s *SavedViewStore) writeUserViews() error {
+	views := make([]SavedView, 0, len(s.user))
+	for _, view := range s.user {
+		views = append(views, view)
+	}
+	sort.Slice(views, func(i, j int) bool { return views[i].Name < views[j].Name })
+
+	data, err := json.MarshalIndent(views, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.userDir, fileperms.ConfigDir); err != nil {
+		return fmt.Errorf("failed to create views directory: %w", err)
+	}
+	return os.WriteFile(filepath.Join(s.userDir, savedViewsFile), data, fileperms.ConfigFile)
+}
@@ -0,0 +1,120 @@
+package filters
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSavedViewStoreSaveAndLoad(t *testing.T) {
+	userDir := filepath.Join(t.TempDir(), "views")
+	store := NewSavedViewStore(userDir, "")
+
+	view := SavedView{
+		Name:           "my-gpu-pending",
+		Description:    "Pending GPU jobs",
+		ViewType:       "jobs",
+		Filter:         "state=PENDING and partition=gpu",
+		SortColumn:     "Priority",
+		SortDescending: true,
+		Columns:        []string{"ID", "User", "Priority"},
+	}
+	if err := store.Save(view); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	reloaded := NewSavedViewStore(userDir, "")
+	if err := reloaded.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	got, ok := reloaded.Get("my-gpu-pending")
+	if !ok {
+		t.Fatal("Expected the saved view after reloading")
+	}
+	if !reflect.DeepEqual(got, view) {
+		t.Errorf("Reloaded view = %+v, want %+v", got, view)
+	}
+
+	if err := reloaded.Delete("my-gpu-pending"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if len(reloaded.List()) != 0 {
+		t.Errorf("Expected no views after delete, got %v", reloaded.Names())
+	}
+	if err := reloaded.Delete("my-gpu-pending"); err == nil {
+		t.Error("Expected an error deleting a missing view")
+	}
+}
+
+func TestSavedViewStoreSharedDir(t *testing.T) {
+	userDir := t.TempDir()
+	sharedDir := t.TempDir()
+
+	shared := `[
+  {"name": "team-down", "view_type": "nodes", "filter": "state=DOWN", "group_by": "partition"},
+  {"name": "team-failed", "view_type": "jobs", "filter": "state=FAILED"},
+  {"name": "broken", "view_type": "jobs", "filter": "state @ FAILED"}
+]`
+	if err := os.WriteFile(filepath.Join(sharedDir, "team.json"), []byte(shared), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(sharedDir, "README.md"), []byte("not a view"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	store := NewSavedViewStore(userDir, sharedDir)
+	if err := store.Save(SavedView{Name: "team-failed", ViewType: "jobs", Filter: "state=FAILED user=alice"}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	err := store.Load()
+	if err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("Expected Load to report the invalid shared view, got %v", err)
+	}
+
+	if names := store.Names(); !reflect.DeepEqual(names, []string{"team-down", "team-failed"}) {
+		t.Errorf("Names() = %v", names)
+	}
+
+	down, ok := store.Get("team-down")
+	if !ok || !down.Shared || down.GroupBy != "partition" {
+		t.Errorf("Expected the shared team-down view, got %+v", down)
+	}
+	failed, _ := store.Get("team-failed")
+	if failed.Shared || failed.Filter != "state=FAILED user=alice" {
+		t.Errorf("Expected the user view to hide the shared one, got %+v", failed)
+	}
+
+	if err := store.Delete("team-down"); err == nil {
+		t.Error("Expected an error deleting a shared view")
+	}
+}
+
+func TestSavedViewValidate(t *testing.T) {
+	testCases := []struct {
+		name  string
+		view  SavedView
+		valid bool
+	}{
+		{"valid", SavedView{Name: "my.view_1", ViewType: "jobs", Filter: "state=RUNNING"}, true},
+		{"no_filter", SavedView{Name: "all", ViewType: "nodes"}, true},
+		{"empty_name", SavedView{ViewType: "jobs"}, false},
+		{"space_in_name", SavedView{Name: "my view", ViewType: "jobs"}, false},
+		{"no_view_type", SavedView{Name: "x"}, false},
+		{"bad_filter", SavedView{Name: "x", ViewType: "jobs", Filter: "state @ running"}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.view.Validate()
+			if tc.valid && err != nil {
+				t.Errorf("Expected %+v to be valid, got %v", tc.view, err)
+			}
+			if !tc.valid && err == nil {
+				t.Errorf("Expected %+v to be invalid", tc.view)
+			}
+		})
+	}
+}
@@ -0,0 +1,343 @@
+package views
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/jontk/s9s/internal/audit"
+	"github.com/jontk/s9s/internal/ui/components"
+	"github.com/jontk/s9s/internal/ui/styles"
+	"github.com/rivo/tview"
+)
+
+// auditViewLimit is the number of recent entries the audit view loads
+const auditViewLimit = 1000
+
+// auditTimeFormat is the timestamp format of the audit table
+const auditTimeFormat = "2006-01-02 15:04:05"
+
+// AuditView lists recent mutating actions from the audit log
+type AuditView struct {
+	*BaseView
+	path         string
+	enabled      bool
+	entries      []audit.Entry
+	mu           sync.RWMutex
+	failuresOnly bool
+	table        *components.Table
+	filterInput  *tview.InputField
+	container    *tview.Flex
+	app          *tview.Application
+	pages        *tview.Pages
+	statusBar    *components.StatusBar
+}
+
+// NewAuditView creates an audit view reading the log at path. enabled
+// reports whether this s9s records to the log.
+func NewAuditView(path string, enabled bool) *AuditView {
+	v := &AuditView{
+		BaseView: NewBaseView("audit", "Audit"),
+		path:     path,
+		enabled:  enabled,
+	}
+
+	columns := []components.Column{
+		components.NewColumn("Time").Width(19).Sortable(true).Build(),
+		components.NewColumn("User").Width(12).Sortable(true).Build(),
+		components.NewColumn("Cluster").Width(12).Sortable(true).Build(),
+		components.NewColumn("Source").Width(6).Build(),
+		components.NewColumn("Action").Width(22).Sortable(true).Build(),
+		components.NewColumn("Targets").Width(24).Build(),
+		components.NewColumn("Result").Width(7).Sortable(true).Build(),
+		components.NewColumn("Error").Width(40).Build(),
+	}
+	v.table = components.NewTableBuilder().
+		WithColumns(columns...).
+		WithSelectable(true).
+		WithHeader(true).
+		WithColors(tcell.ColorYellow, tcell.ColorTeal, tcell.ColorWhite).
+		Build()
+
+	v.filterInput = styles.NewStyledInputField().
+		SetLabel("Filter: ").
+		SetFieldWidth(30).
+		SetChangedFunc(func(text string) { v.table.SetFilter(text) }).
+		SetDoneFunc(func(tcell.Key) {
+			if v.app != nil {
+				v.app.SetFocus(v.table.Table)
+			}
+		})
+
+	v.container = tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(v.filterInput, 1, 0, false).
+		AddItem(v.table, 0, 1, true)
+
+	return v
+}
+
+// SetApp sets the application reference
+func (v *AuditView) SetApp(app *tview.Application) {
+	v.app = app
+}
+
+// SetPages sets the pages reference for modal handling
+func (v *AuditView) SetPages(pages *tview.Pages) {
+	v.pages = pages
+}
+
+// SetStatusBar sets the status bar reference
+func (v *AuditView) SetStatusBar(statusBar *components.StatusBar) {
+	v.statusBar = statusBar
+}
+
+// Init initializes the audit view
+func (v *AuditView) Init(ctx context.Context) error {
+	_ = v.BaseView.Init(ctx)
+	return nil
+}
+
+// Render returns the view's main component
+func (v *AuditView) Render() tview.Primitive {
+	return v.container
+}
+
+// Refresh reloads recent entries from the audit log
+func (v *AuditView) Refresh() error {
+	if !v.refreshing.CompareAndSwap(false, true) {
+		return nil
+	}
+
+	go func() {
+		defer v.refreshing.Store(false)
+
+		entries, err := audit.ReadRecent(v.path, auditViewLimit)
+		if err != nil {
+			v.SetLastError(err)
+		}
+		if v.app != nil {
+			v.app.QueueUpdateDraw(func() {
+				v.mu.Lock()
+				v.entries = entries
+				v.mu.Unlock()
+				v.updateTable()
+			})
+		}
+	}()
+
+	return nil
+}
+
+// Stop stops the view
+func (v *AuditView) Stop() error {
+	return nil
+}
+
+// Hints returns keyboard hints
+func (v *AuditView) Hints() []string {
+	hints := []string{"[yellow]Enter[white] Details", "[yellow]/[white] Filter"}
+	if v.failuresOnly {
+		hints = append(hints, "[yellow]x[green]✓[white] Failures Only")
+	} else {
+		hints = append(hints, "[yellow]x[white] Failures Only")
+	}
+	hints = append(hints, "[yellow]R[white] Refresh")
+	if !v.enabled {
+		hints = append(hints, "[red]Audit log disabled[white]")
+	}
+	return hints
+}
+
+// OnKey handles keyboard events
+func (v *AuditView) OnKey(event *tcell.EventKey) *tcell.EventKey {
+	if v.filterInput.HasFocus() {
+		if event.Key() == tcell.KeyEsc {
+			v.app.SetFocus(v.table.Table)
+			return nil
+		}
+		return event
+	}
+	if v.pages != nil && v.pages.GetPageCount() > 1 {
+		return event
+	}
+
+	switch event.Key() {
+	case tcell.KeyEnter:
+		v.showEntryDetails()
+		return nil
+	case tcell.KeyRune:
+		switch event.Rune() {
+		case '/':
+			v.app.SetFocus(v.filterInput)
+			return nil
+		case 'x', 'X':
+			v.failuresOnly = !v.failuresOnly
+			v.updateTable()
+			if v.statusBar != nil {
+				v.statusBar.SetHints(v.Hints())
+			}
+			return nil
+		case 'R':
+			go func() { _ = v.Refresh() }()
+			return nil
+		}
+	}
+	return event
+}
+
+// OnFocus handles focus events. Entries are reloaded on every focus since
+// other views add to the log.
+func (v *AuditView) OnFocus() error {
+	v.SetFocused(true)
+	if v.app != nil {
+		v.app.SetFocus(v.table.Table)
+	}
+	v.SetInitialized(true)
+	go func() { _ = v.Refresh() }()
+	return nil
+}
+
+// OnLoseFocus handles loss of focus
+func (v *AuditView) OnLoseFocus() error {
+	v.SetFocused(false)
+	return nil
+}
+
+// visibleEntries returns the entries shown with the current toggles,
+// newest first
+func (v *AuditView) visibleEntries() []audit.Entry {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	visible := make([]audit.Entry, 0, len(v.entries))
+	for _, entry := range v.entries {
+		if v.failuresOnly && !entry.Failed() {
+			continue
+		}
+		visible = append(visible, entry)
+	}
+	sort.SliceStable(visible, func(i, j int) bool {
+		return visible[i].Timestamp.After(visible[j].Timestamp)
+	})
+	return visible
+}
+
+// updateTable fills the table from the loaded entries
+func (v *AuditView) updateTable() {
+	entries := v.visibleEntries()
+	data := make([][]string, 0, len(entries))
+	for i := range entries {
+		entry := &entries[i]
+		result := "[green]" + entry.Result + "[white]"
+		if entry.Failed() {
+			result = "[red]" + entry.Result + "[white]"
+		}
+		data = append(data, []string{
+			entry.Timestamp.Local().Format(auditTimeFormat),
+			entry.OSUser,
+			entry.Cluster,
+			entry.Source,
+			entry.Action,
+			strings.Join(entry.Targets, ","),
+			result,
+			entry.Error,
+		})
+	}
+	v.table.SetData(data)
+}
+
+// selectedEntry returns the entry of the selected row
+func (v *AuditView) selectedEntry() *audit.Entry {
+	row := v.table.GetSelectedData()
+	if len(row) < 6 {
+		return nil
+	}
+	for _, entry := range v.visibleEntries() {
+		if entry.Timestamp.Local().Format(auditTimeFormat) == row[0] &&
+			entry.OSUser == row[1] && entry.Action == row[4] &&
+			strings.Join(entry.Targets, ",") == row[5] {
+			return &entry
+		}
+	}
+	return nil
+}
+
+// showEntryDetails shows every field of the selected entry
+func (v *AuditView) showEntryDetails() {
+	entry := v.selectedEntry()
+	if entry == nil || v.pages == nil {
+		return
+	}
+
+	textView := tview.NewTextView().
+		SetDynamicColors(true).
+		SetText(formatAuditEntry(entry)).
+		SetScrollable(true)
+
+	modal := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(textView, 0, 1, true).
+		AddItem(tview.NewTextView().SetText("Press ESC to close"), 1, 0, false)
+	modal.SetBorder(true).
+		SetTitle(fmt.Sprintf(" %s ", entry.Action)).
+		SetTitleAlign(tview.AlignCenter)
+
+	centeredModal := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(modal, 0, 8, true).
+			AddItem(nil, 0, 1, false), 0, 8, true).
+		AddItem(nil, 0, 1, false)
+
+	modal.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEsc {
+			v.pages.RemovePage("audit-details")
+			return nil
+		}
+		return event
+	})
+
+	v.pages.AddPage("audit-details", centeredModal, true, true)
+}
+
+// formatAuditEntry formats an entry for the details modal
+func formatAuditEntry(entry *audit.Entry) string {
+	var b strings.Builder
+	row := func(label, value string) {
+		if value != "" {
+			b.WriteString(fmt.Sprintf("[yellow]%s:[white] %s\n", label, tview.Escape(value)))
+		}
+	}
+
+	row("Time", entry.Timestamp.Local().Format("2006-01-02 15:04:05 MST"))
+	row("OS User", entry.OSUser)
+	row("SLURM User", entry.SlurmUser)
+	row("Cluster", entry.Cluster)
+	row("Source", entry.Source)
+	row("Action", entry.Action)
+	row("Targets", strings.Join(entry.Targets, ", "))
+	if entry.Failed() {
+		b.WriteString(fmt.Sprintf("[yellow]Result:[white] [red]%s[white]\n", entry.Result))
+	} else {
+		b.WriteString(fmt.Sprintf("[yellow]Result:[white] [green]%s[white]\n", entry.Result))
+	}
+	row("Error", entry.Error)
+
+	if len(entry.Params) > 0 {
+		keys := make([]string, 0, len(entry.Params))
+		for key := range entry.Params {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		b.WriteString("\n[yellow]Parameters:[white]\n")
+		for _, key := range keys {
+			b.WriteString(fmt.Sprintf("  %s: %s\n", key, tview.Escape(entry.Params[key])))
+		}
+	}
+	return b.String()
+}
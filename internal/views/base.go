@@ -10,6 +10,7 @@ import (
 	"github.com/gdamore/tcell/v2"
 	"github.com/jontk/s9s/internal/dao"
 	"github.com/jontk/s9s/internal/debug"
+	"github.com/jontk/s9s/internal/timeseries"
 	"github.com/rivo/tview"
 )
 
@@ -24,6 +25,12 @@ type ClientSetter interface {
 	SetClient(client dao.SlurmClient)
 }
 
+// HistorySetter is implemented by views that render trends from the
+// metrics history
+type HistorySetter interface {
+	SetHistory(history *timeseries.Store)
+}
+
 // View represents a base interface for all views in S9s
 type View interface {
 	// Name returns the unique name of the view (e.g., "jobs", "nodes")
@@ -252,18 +259,6 @@ var jobStateColors = map[string]string{
 	"TIMEOUT":     "red",
 }
 
-// nodeStateColors maps node states to their display colors
-var nodeStateColors = map[string]string{
-	"IDLE":        "green",
-	"ALLOCATED":   "blue",
-	"MIXED":       "blue",
-	"DOWN":        "red",
-	"DRAIN":       "red",
-	"DRAINING":    "red",
-	"RESERVED":    "yellow",
-	"MAINTENANCE": "orange",
-}
-
 // partitionStateColors maps partition states to their display colors
 var partitionStateColors = map[string]string{
 	"UP":       "green",
@@ -282,10 +277,7 @@ func GetJobStateColor(state string) string {
 
 // GetNodeStateColor returns the color for a node state
 func GetNodeStateColor(state string) string {
-	if color, exists := nodeStateColors[state]; exists {
-		return color
-	}
-	return "white"
+	return dao.GetNodeStateColor(state)
 }
 
 // GetPartitionStateColor returns the color for a partition state
@@ -384,6 +376,27 @@ func (vm *ViewManager) AddView(view View) error {
 	return nil
 }
 
+// RemoveView stops a view and removes it from the manager. The current
+// view cannot be removed.
+func (vm *ViewManager) RemoveView(name string) error {
+	view, exists := vm.views[name]
+	if !exists {
+		return fmt.Errorf("view %s not found", name)
+	}
+	if name == vm.currentView {
+		return fmt.Errorf("view %s is the current view", name)
+	}
+
+	delete(vm.views, name)
+	for i, viewName := range vm.viewOrder {
+		if viewName == name {
+			vm.viewOrder = append(vm.viewOrder[:i], vm.viewOrder[i+1:]...)
+			break
+		}
+	}
+	return view.Stop()
+}
+
 // setViewReferences sets app and pages references on views that support it
 func (vm *ViewManager) setViewReferences(view View) {
 	// Set BaseView reference
@@ -11,7 +11,6 @@ import (
 	"github.com/jontk/s9s/internal/export"
 	"github.com/jontk/s9s/internal/ui/components"
 	"github.com/jontk/s9s/internal/ui/styles"
-	"github.com/jontk/s9s/pkg/slurm"
 	"github.com/rivo/tview"
 )
 
@@ -444,7 +443,7 @@ func (v *BatchOperationsView) batchOperationHandlers(jobMgr dao.JobManager, jobI
 			return jobMgr.Cancel(jobID)
 		},
 		BatchPriority: func() error {
-			if _, ok := v.client.(*slurm.MockClient); ok {
+			if isMockClient(v.client) {
 				return nil // Mock success
 			}
 			return fmt.Errorf("priority setting not implemented for real client")
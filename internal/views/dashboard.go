@@ -10,6 +10,8 @@ import (
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/jontk/s9s/internal/dao"
+	"github.com/jontk/s9s/internal/monitoring"
+	"github.com/jontk/s9s/internal/timeseries"
 	"github.com/rivo/tview"
 )
 
@@ -40,6 +42,14 @@ type DashboardView struct {
 	nodes          []*dao.Node
 	partitions     []*dao.Partition
 	lastUpdate     time.Time
+
+	// history supplies the trend sparklines; nil shows the current snapshot only
+	history *timeseries.Store
+
+	// health supplies the rule results shown in the alerts panel; without
+	// it the dashboard evaluates the default rules itself
+	health *monitoring.HealthMonitor
+	rules  *monitoring.RuleEngine
 }
 
 // SetPages sets the pages reference for modal handling
@@ -53,6 +63,7 @@ func NewDashboardView(client dao.SlurmClient) *DashboardView {
 		BaseView: NewBaseView("dashboard", "Dashboard"),
 		client:   client,
 	}
+	v.rules, _ = monitoring.NewRuleEngine(monitoring.DefaultRules())
 
 	// Create dashboard components
 	v.clusterOverview = tview.NewTextView().SetDynamicColors(true)
@@ -95,6 +106,21 @@ func NewDashboardView(client dao.SlurmClient) *DashboardView {
 	return v
 }
 
+// SetHistory sets the metrics history used for trend sparklines
+func (v *DashboardView) SetHistory(history *timeseries.Store) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.history = history
+}
+
+// SetHealthMonitor sets the health monitor whose rule results drive the
+// alerts panel, so the dashboard shows the same alerts as the health view
+func (v *DashboardView) SetHealthMonitor(health *monitoring.HealthMonitor) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.health = health
+}
+
 // SetClient sets the SLURM client for the dashboard view
 func (v *DashboardView) SetClient(client dao.SlurmClient) {
 	v.mu.Lock()
@@ -413,7 +439,7 @@ func (v *DashboardView) updateNodesSummary() {
 	allocatedMemory := int64(0)
 
 	for _, node := range v.nodes {
-		nodeStats[node.State]++
+		nodeStats[node.ParsedState().Summary()]++
 		totalCPUs += node.CPUsTotal
 		allocatedCPUs += node.CPUsAllocated
 		totalMemory += node.MemoryTotal
@@ -427,7 +453,7 @@ func (v *DashboardView) updateNodesSummary() {
 	content.WriteString(fmt.Sprintf("[blue]Allocated:[white] %d\n", nodeStats[dao.NodeStateAllocated]))
 	content.WriteString(fmt.Sprintf("[yellow]Mixed:[white] %d\n", nodeStats[dao.NodeStateMixed]))
 	content.WriteString(fmt.Sprintf("[red]Down:[white] %d\n", nodeStats[dao.NodeStateDown]))
-	content.WriteString(fmt.Sprintf("[orange]Drain:[white] %d\n", nodeStats[dao.NodeStateDrain]))
+	content.WriteString(fmt.Sprintf("[orange]Drain:[white] %d\n", nodeStats[dao.NodeStateDrain]+nodeStats[dao.NodeStateDraining]))
 
 	// Resource utilization
 	if totalCPUs > 0 {
@@ -533,7 +559,15 @@ func (v *DashboardView) updateTrendsPanel() {
 
 	var content strings.Builder
 
-	content.WriteString("[yellow]Performance Overview[white]\n\n")
+	if v.history != nil {
+		content.WriteString("[yellow]Cluster Trends[white] [gray](last hour)[white]\n")
+		for _, t := range clusterTrends {
+			content.WriteString(formatTrendLine(v.history, t, 30) + "\n")
+		}
+		content.WriteString("\n")
+	} else {
+		content.WriteString("[yellow]Performance Overview[white]\n\n")
+	}
 
 	content.WriteString("[teal]Resource Efficiency:[white]\n")
 	if v.clusterMetrics != nil {
@@ -593,7 +627,7 @@ func (v *DashboardView) calculateNodeHealthDeduction() float64 {
 
 	downNodes := 0
 	for _, node := range v.nodes {
-		if node.State == dao.NodeStateDown {
+		if node.ParsedState().IsDown() {
 			downNodes++
 		}
 	}
@@ -700,129 +734,40 @@ type Alert struct {
 	Message string
 }
 
+// generateAlerts returns the firing health rules: those of the health
+// monitor, or else the default rules evaluated against the cached data
 func (v *DashboardView) generateAlerts() []Alert {
-	var alerts []Alert
-
-	// Check each alert condition and collect non-empty alerts
-	if alert := v.checkDownNodesAlert(); alert != nil {
-		alerts = append(alerts, *alert)
-	}
-
-	if alert := v.checkResourceUtilizationAlert(); alert != nil {
-		alerts = append(alerts, *alert)
-	}
-
-	if alert := v.checkCPUAlertIfHigh(); alert != nil {
-		alerts = append(alerts, *alert)
-	}
-
-	if alert := v.checkLongWaitingJobsAlert(); alert != nil {
-		alerts = append(alerts, *alert)
-	}
-
-	if alert := v.checkFailedJobsAlert(); alert != nil {
-		alerts = append(alerts, *alert)
-	}
-
-	return alerts
-}
-
-func (v *DashboardView) checkDownNodesAlert() *Alert {
-	if len(v.nodes) == 0 {
-		return nil
-	}
-
-	downNodes := 0
-	for _, node := range v.nodes {
-		if node.State == dao.NodeStateDown {
-			downNodes++
-		}
-	}
-
-	if downNodes == 0 {
-		return nil
-	}
-
-	return &Alert{
-		Level:   "WARNING",
-		Icon:    "⚠",
-		Color:   "yellow",
-		Message: fmt.Sprintf("%d node(s) are down", downNodes),
-	}
-}
-
-func (v *DashboardView) checkResourceUtilizationAlert() *Alert {
-	if v.clusterMetrics == nil || v.clusterMetrics.MemoryUsage <= 90 {
-		return nil
-	}
-
-	return &Alert{
-		Level:   "WARNING",
-		Icon:    "⚠",
-		Color:   "orange",
-		Message: fmt.Sprintf("High memory utilization: %.1f%%", v.clusterMetrics.MemoryUsage),
-	}
-}
-
-func (v *DashboardView) checkCPUAlertIfHigh() *Alert {
-	if v.clusterMetrics == nil || v.clusterMetrics.CPUUsage <= 90 {
-		return nil
-	}
-
-	return &Alert{
-		Level:   "WARNING",
-		Icon:    "⚠",
-		Color:   "orange",
-		Message: fmt.Sprintf("High CPU utilization: %.1f%%", v.clusterMetrics.CPUUsage),
-	}
-}
-
-func (v *DashboardView) checkLongWaitingJobsAlert() *Alert {
-	if len(v.jobs) == 0 {
-		return nil
+	var results []monitoring.RuleResult
+	if v.health != nil {
+		results = v.health.RuleResults()
+	} else {
+		results = v.rules.Evaluate(&monitoring.Snapshot{
+			Time:       time.Now(),
+			Nodes:      v.nodes,
+			Jobs:       v.jobs,
+			Partitions: v.partitions,
+		})
 	}
 
-	longWaitingJobs := 0
-	now := time.Now()
-	for _, job := range v.jobs {
-		if job.State == dao.JobStatePending && now.Sub(job.SubmitTime) > 24*time.Hour {
-			longWaitingJobs++
+	var alerts []Alert
+	for _, result := range results {
+		if !result.Firing() {
+			continue
 		}
+		alerts = append(alerts, ruleAlert(result))
 	}
-
-	if longWaitingJobs == 0 {
-		return nil
-	}
-
-	return &Alert{
-		Level:   "INFO",
-		Icon:    "ℹ",
-		Color:   "cyan",
-		Message: fmt.Sprintf("%d job(s) waiting >24h", longWaitingJobs),
-	}
+	return alerts
 }
 
-func (v *DashboardView) checkFailedJobsAlert() *Alert {
-	if len(v.jobs) == 0 {
-		return nil
-	}
-
-	failedJobs := 0
-	for _, job := range v.jobs {
-		if job.State == dao.JobStateFailed {
-			failedJobs++
-		}
-	}
-
-	if failedJobs <= 10 {
-		return nil
-	}
-
-	return &Alert{
-		Level:   "ERROR",
-		Icon:    "✗",
-		Color:   "red",
-		Message: fmt.Sprintf("%d failed jobs detected", failedJobs),
+// ruleAlert converts a firing rule into a dashboard alert
+func ruleAlert(result monitoring.RuleResult) Alert {
+	switch result.Severity {
+	case monitoring.AlertSeverityCritical:
+		return Alert{Level: "ERROR", Icon: "✗", Color: "red", Message: result.Message}
+	case monitoring.AlertSeverityWarning:
+		return Alert{Level: "WARNING", Icon: "⚠", Color: "yellow", Message: result.Message}
+	default:
+		return Alert{Level: "INFO", Icon: "ℹ", Color: "cyan", Message: result.Message}
 	}
 }
 
@@ -985,7 +930,7 @@ func (v *DashboardView) appendNodeAnalysis(w *strings.Builder) {
 	var totalMemory, allocatedMemory int64
 
 	for _, node := range v.nodes {
-		stateStats[node.State]++
+		stateStats[node.ParsedState().Summary()]++
 		totalCPUs += node.CPUsTotal
 		allocatedCPUs += node.CPUsAllocated
 		totalMemory += node.MemoryTotal
@@ -1111,10 +1056,10 @@ func (v *DashboardView) generateNodeHealthSection() string {
 	drainNodes := 0
 
 	for _, node := range v.nodes {
-		switch node.State {
+		switch node.ParsedState().Summary() {
 		case dao.NodeStateDown:
 			downNodes++
-		case dao.NodeStateDrain, dao.NodeStateDraining:
+		case dao.NodeStateDrain, dao.NodeStateDraining, dao.NodeFlagFail:
 			drainNodes++
 		}
 	}
@@ -1306,7 +1251,7 @@ func (v *DashboardView) addNodeHealthRecommendations(recommendations *[]string)
 func (v *DashboardView) countDownNodes() int {
 	count := 0
 	for _, node := range v.nodes {
-		if node.State == dao.NodeStateDown {
+		if node.ParsedState().IsDown() {
 			count++
 		}
 	}
@@ -318,12 +318,13 @@ func (v *FilteredJobOutputView) handleStreamEvent(event *streaming.StreamEvent)
 	v.app.QueueUpdateDraw(func() {
 		switch event.EventType {
 		case streaming.StreamEventNewOutput:
-			// Add new lines to text view
-			for _, line := range event.NewLines {
+			// Add the new lines to the text view as rendered, with the
+			// colors of the output
+			for i, line := range streaming.EventLines(event) {
 				// Apply search highlighting if active
 				if v.searchBar.IsActive() {
 					// Simple highlighting - would be enhanced in real implementation
-					line = "[cyan]" + line + "[white]"
+					line = "[cyan]" + tview.Escape(event.NewLines[i]) + "[white]"
 				}
 
 				_, _ = v.textView.Write([]byte(line + "\n"))
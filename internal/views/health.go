@@ -10,6 +10,7 @@ import (
 	"github.com/gdamore/tcell/v2"
 	"github.com/jontk/s9s/internal/dao"
 	"github.com/jontk/s9s/internal/monitoring"
+	"github.com/jontk/s9s/internal/timeseries"
 	"github.com/rivo/tview"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
@@ -27,6 +28,7 @@ type HealthView struct {
 	statusBar     *tview.TextView
 	app           *tview.Application
 	pages         *tview.Pages
+	history       *timeseries.Store
 }
 
 // NewHealthView creates a new health monitoring view
@@ -36,6 +38,8 @@ func NewHealthView(client dao.SlurmClient) *HealthView {
 		client:        client,
 		healthMonitor: monitoring.NewHealthMonitor(client, 30*time.Second), // Check every 30 seconds
 	}
+	rules, _ := monitoring.NewRuleEngine(monitoring.DefaultRules())
+	v.healthMonitor.SetRuleEngine(rules)
 
 	// Create health overview box
 	v.healthBox = tview.NewTextView()
@@ -81,6 +85,18 @@ func NewHealthView(client dao.SlurmClient) *HealthView {
 // SetClient sets the SLURM client for the health view
 func (v *HealthView) SetClient(client dao.SlurmClient) {
 	v.client = client
+	v.healthMonitor.SetClient(client)
+}
+
+// SetHealthMonitor replaces the view's own health monitor, e.g. with one
+// shared with the dashboard. It must be called before Init.
+func (v *HealthView) SetHealthMonitor(monitor *monitoring.HealthMonitor) {
+	v.healthMonitor = monitor
+}
+
+// SetHistory sets the metrics history used for trend sparklines
+func (v *HealthView) SetHistory(history *timeseries.Store) {
+	v.history = history
 }
 
 // SetApp sets the application reference
@@ -252,6 +268,15 @@ func (v *HealthView) updateHealthOverview() {
 	overview.WriteString(fmt.Sprintf("  Active: %d | Acknowledged: %d\n\n",
 		alertStats.Active, alertStats.Acknowledged))
 
+	// Trends
+	if v.history != nil {
+		overview.WriteString("[teal]Trends:[white]\n")
+		for _, t := range clusterTrends {
+			overview.WriteString("  " + formatTrendLine(v.history, t, 12) + "\n")
+		}
+		overview.WriteString("\n")
+	}
+
 	// Last update
 	overview.WriteString(fmt.Sprintf("[gray]Last Updated: %s[white]",
 		health.LastUpdated.Format("15:04:05")))
@@ -312,7 +337,7 @@ func (v *HealthView) updateHealthChecks() {
 		icon := v.getStatusIcon(check.Status)
 
 		checksText.WriteString(fmt.Sprintf("[%s]%s %s[white]\n",
-			statusColor, icon, cases.Title(language.English).String(name)))
+			statusColor, icon, checkTitle(name)))
 		checksText.WriteString(fmt.Sprintf("   %s\n", check.Message))
 		checksText.WriteString(fmt.Sprintf("   [gray]Last Check: %s | Count: %d[white]\n\n",
 			check.LastCheck.Format("15:04:05"), check.CheckCount))
@@ -341,6 +366,17 @@ func (v *HealthView) updateStatusBar() {
 }
 */
 
+// checkTitle title-cases a check name, leaving the partition of per-partition
+// rules such as "queue-depth[gpu]" as is
+func checkTitle(name string) string {
+	base, scope, scoped := strings.Cut(name, "[")
+	title := cases.Title(language.English).String(base)
+	if scoped {
+		title += "[" + scope
+	}
+	return title
+}
+
 // getStatusColor returns the color for a health status
 func (v *HealthView) getStatusColor(status monitoring.HealthStatus) string {
 	switch status {
@@ -478,7 +514,7 @@ func (v *HealthView) showHealthDetails() {
 		check := health.Checks[name]
 		statusColor := v.getStatusColor(check.Status)
 		details.WriteString(fmt.Sprintf("[%s]%s %s[white]\n",
-			statusColor, v.getStatusIcon(check.Status), cases.Title(language.English).String(name)))
+			statusColor, v.getStatusIcon(check.Status), checkTitle(name)))
 		details.WriteString(fmt.Sprintf("Description: %s\n", check.Description))
 		details.WriteString(fmt.Sprintf("Status: %s\n", check.Status))
 		details.WriteString(fmt.Sprintf("Message: %s\n", check.Message))
@@ -495,6 +531,15 @@ func (v *HealthView) showHealthDetails() {
 				details.WriteString(fmt.Sprintf("  Critical: %.1f\n", *check.Threshold.CriticalMax))
 			}
 		}
+		if check.Threshold.WarningMin != nil || check.Threshold.CriticalMin != nil {
+			details.WriteString("Thresholds (below):\n")
+			if check.Threshold.WarningMin != nil {
+				details.WriteString(fmt.Sprintf("  Warning: %.1f\n", *check.Threshold.WarningMin))
+			}
+			if check.Threshold.CriticalMin != nil {
+				details.WriteString(fmt.Sprintf("  Critical: %.1f\n", *check.Threshold.CriticalMin))
+			}
+		}
 		details.WriteString("\n")
 	}
 
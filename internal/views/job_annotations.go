@@ -0,0 +1,210 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/jontk/s9s/internal/annotations"
+	"github.com/jontk/s9s/internal/dao"
+	"github.com/jontk/s9s/internal/export"
+	"github.com/rivo/tview"
+)
+
+// SetAnnotationStore sets the store of the notes, labels and bookmarks of
+// jobs, and the cluster they are kept for
+func (v *JobsView) SetAnnotationStore(store *annotations.Store, cluster string) {
+	v.annotations = store
+	v.cluster = cluster
+}
+
+// jobAnnotation returns the annotation of job: its own or that of its array
+func (v *JobsView) jobAnnotation(job *dao.Job) annotations.Annotation {
+	return v.annotationOf(job.ID, job.ArrayJobID)
+}
+
+// annotationOf returns the annotation of a job ID, falling back to that of
+// arrayJobID when it is set
+func (v *JobsView) annotationOf(jobID, arrayJobID string) annotations.Annotation {
+	if v.annotations == nil {
+		return annotations.Annotation{}
+	}
+	a, ok := v.annotations.Get(v.cluster, jobID)
+	if !ok && arrayJobID != "" {
+		a, _ = v.annotations.Get(v.cluster, arrayJobID)
+	}
+	return a
+}
+
+// annotationCells renders an annotation as the bookmark, labels and note
+// columns of a row
+func annotationCells(a annotations.Annotation) []string {
+	bookmark := ""
+	if a.Bookmarked {
+		bookmark = "[yellow]★[white]"
+	}
+	note, _, _ := strings.Cut(a.Note, "\n")
+	return []string{bookmark, strings.Join(a.Labels, ","), tview.Escape(note)}
+}
+
+// writeAnnotationDetails adds the annotation of job to its details
+func (v *JobsView) writeAnnotationDetails(d *strings.Builder, job *dao.Job) {
+	a := v.jobAnnotation(job)
+	if a.Empty() {
+		return
+	}
+	d.WriteString("[teal]Annotations[white]\n")
+	if a.Bookmarked {
+		writeDetailIndented(d, "Bookmarked", "yes")
+	}
+	writeDetailIndented(d, "Labels", strings.Join(a.Labels, ", "))
+	writeDetailIndented(d, "Note", strings.ReplaceAll(tview.Escape(a.Note), "\n", "\n"+strings.Repeat(" ", 15)))
+	d.WriteString("\n")
+}
+
+// selectedAnnotationTarget returns the job ID and name of the selected row
+// to annotate; a collapsed array row stands for the array
+func (v *JobsView) selectedAnnotationTarget() (jobID, jobName string) {
+	data := v.table.GetSelectedData()
+	if len(data) == 0 {
+		return "", ""
+	}
+	jobID = strings.TrimSuffix(data[0], arrayRowSuffix)
+
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	for _, job := range v.jobs {
+		if job.ID == jobID || job.ArrayJobID == jobID {
+			return jobID, job.Name
+		}
+	}
+	return jobID, ""
+}
+
+// toggleSelectedBookmark bookmarks the selected job, or removes its bookmark
+func (v *JobsView) toggleSelectedBookmark() {
+	if v.annotations == nil {
+		return
+	}
+	jobID, jobName := v.selectedAnnotationTarget()
+	if jobID == "" {
+		return
+	}
+	bookmarked, err := v.annotations.ToggleBookmark(v.cluster, jobID, jobName)
+	if v.mainStatusBar != nil {
+		switch {
+		case err != nil:
+			v.mainStatusBar.Error(fmt.Sprintf("Failed to bookmark job %s: %v", jobID, err))
+		case bookmarked:
+			v.mainStatusBar.Success(fmt.Sprintf("Bookmarked job %s", jobID))
+		default:
+			v.mainStatusBar.Info(fmt.Sprintf("Removed the bookmark of job %s", jobID))
+		}
+	}
+	v.updateTable()
+}
+
+// showAnnotationEditor edits the note, labels and bookmark of the selected job
+func (v *JobsView) showAnnotationEditor() {
+	if v.annotations == nil || v.pages == nil {
+		return
+	}
+	jobID, jobName := v.selectedAnnotationTarget()
+	if jobID == "" {
+		return
+	}
+	const page = "job-annotation"
+
+	a, _ := v.annotations.Get(v.cluster, jobID)
+	if jobName != "" {
+		a.JobName = jobName
+	}
+
+	form := tview.NewForm()
+	form.AddTextArea("Note", a.Note, 60, 5, 0, nil)
+	form.AddInputField("Labels", strings.Join(a.Labels, ", "), 60, nil, nil)
+	form.AddCheckbox("Bookmarked", a.Bookmarked, nil)
+
+	closeEditor := func() {
+		v.pages.RemovePage(page)
+		if v.app != nil {
+			v.app.SetFocus(v.table.Table)
+		}
+	}
+	form.AddButton("Save", func() {
+		labels, err := annotations.ParseLabels(form.GetFormItemByLabel("Labels").(*tview.InputField).GetText())
+		if err != nil {
+			if v.mainStatusBar != nil {
+				v.mainStatusBar.Error(err.Error())
+			}
+			return
+		}
+		a.Note = form.GetFormItemByLabel("Note").(*tview.TextArea).GetText()
+		a.Labels = labels
+		a.Bookmarked = form.GetFormItemByLabel("Bookmarked").(*tview.Checkbox).IsChecked()
+		if err := v.annotations.Put(a); err != nil {
+			if v.mainStatusBar != nil {
+				v.mainStatusBar.Error(fmt.Sprintf("Failed to save the note of job %s: %v", jobID, err))
+			}
+			return
+		}
+		closeEditor()
+		if v.mainStatusBar != nil {
+			v.mainStatusBar.Success(fmt.Sprintf("Saved the note of job %s", jobID))
+		}
+		v.updateTable()
+	})
+	form.AddButton("Cancel", closeEditor)
+	form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEsc {
+			closeEditor()
+			return nil
+		}
+		return event
+	})
+
+	title := fmt.Sprintf(" Note: job %s ", jobID)
+	if jobName != "" {
+		title = fmt.Sprintf(" Note: job %s (%s) ", jobID, jobName)
+	}
+	form.SetBorder(true).
+		SetTitle(title).
+		SetTitleAlign(tview.AlignCenter)
+
+	v.pages.AddPage(page, createCenteredModal(form, 76, 15), true, true)
+	if v.app != nil {
+		v.app.SetFocus(form)
+	}
+}
+
+// ShowLabel filters the view to the jobs with the given label, in any
+// state, so that finished jobs with the label stay listed
+func (v *JobsView) ShowLabel(label string) error {
+	if _, err := annotations.ParseLabels(label); err != nil {
+		return err
+	}
+	return v.showFilter("label=" + label)
+}
+
+// ShowJob filters the view to the job with the given ID, in any state
+func (v *JobsView) ShowJob(jobID string) error {
+	return v.showFilter("id=" + jobID)
+}
+
+// jobsExportData returns the export of jobs with their annotations
+func (v *JobsView) jobsExportData(jobs []*dao.Job) *export.TableData {
+	data := export.JobsTableData(jobs)
+	if v.annotations == nil {
+		return data
+	}
+	data.Headers = append(data.Headers, "Bookmarked", "Labels", "Note")
+	for i, job := range jobs {
+		a := v.jobAnnotation(job)
+		bookmarked := ""
+		if a.Bookmarked {
+			bookmarked = "yes"
+		}
+		data.Rows[i] = append(data.Rows[i], bookmarked, strings.Join(a.Labels, ","), a.Note)
+	}
+	return data
+}
@@ -0,0 +1,69 @@
+package views
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jontk/s9s/internal/annotations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func annotatedJobsView(t *testing.T) *JobsView {
+	t.Helper()
+	store := annotations.NewStore(filepath.Join(t.TempDir(), "annotations.json"))
+	require.NoError(t, store.Put(annotations.Annotation{Cluster: "prod", JobID: "10", Note: "first try\nlr=0.1", Labels: []string{"baseline"}, Bookmarked: true}))
+	require.NoError(t, store.Put(annotations.Annotation{Cluster: "prod", JobID: "20", Labels: []string{"sweep"}}))
+	require.NoError(t, store.Put(annotations.Annotation{Cluster: "dev", JobID: "30", Labels: []string{"baseline"}}))
+
+	v := NewJobsView(nil)
+	v.SetAnnotationStore(store, "prod")
+	return v
+}
+
+func TestJobsViewAnnotationColumns(t *testing.T) {
+	v := annotatedJobsView(t)
+	rows := v.buildJobRows(arrayTestJobs(), time.Now())
+	require.Len(t, rows, 3)
+
+	assert.Equal(t, []string{"[yellow]★[white]", "baseline", "first try"}, rows[0][13:], "only the first line of the note is shown")
+	assert.Equal(t, []string{"", "sweep", ""}, rows[1][13:], "an array row shows the annotation of the array")
+	assert.Equal(t, []string{"", "", ""}, rows[2][13:], "annotations of other clusters are not shown")
+
+	v.expandedArrays["20"] = true
+	rows = v.buildJobRows(arrayTestJobs(), time.Now())
+	assert.Equal(t, "sweep", rows[2][14], "array tasks inherit the annotation of the array")
+}
+
+func TestJobsViewFiltersByLabel(t *testing.T) {
+	v := annotatedJobsView(t)
+	jobs := arrayTestJobs()
+
+	v.advancedFilter = parseJobsFilter(t, "label=baseline")
+	filtered := v.applyAdvancedFilter(jobs)
+	require.Len(t, filtered, 1)
+	assert.Equal(t, "10", filtered[0].ID)
+
+	v.advancedFilter = parseJobsFilter(t, "label=sweep")
+	assert.Len(t, v.applyAdvancedFilter(jobs), 3, "the tasks of a labelled array match")
+
+	v.advancedFilter = parseJobsFilter(t, "bookmarked=true")
+	assert.Len(t, v.applyAdvancedFilter(jobs), 1)
+
+	v.advancedFilter = parseJobsFilter(t, "note~lr=0.1")
+	assert.Len(t, v.applyAdvancedFilter(jobs), 1)
+
+	v.advancedFilter = parseJobsFilter(t, "label!=baseline")
+	assert.Len(t, v.applyAdvancedFilter(jobs), 4)
+}
+
+func TestJobsViewExportIncludesAnnotations(t *testing.T) {
+	v := annotatedJobsView(t)
+	jobs := arrayTestJobs()[:2]
+
+	data := v.jobsExportData(jobs)
+	assert.Equal(t, []string{"Bookmarked", "Labels", "Note"}, data.Headers[len(data.Headers)-3:])
+	assert.Equal(t, []string{"yes", "baseline", "first try\nlr=0.1"}, data.Rows[0][len(data.Rows[0])-3:])
+	assert.Equal(t, []string{"", "sweep", ""}, data.Rows[1][len(data.Rows[1])-3:])
+}
@@ -0,0 +1,435 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/jontk/s9s/internal/dao"
+	"github.com/jontk/s9s/internal/ui/styles"
+	"github.com/rivo/tview"
+)
+
+// arrayRowSuffix marks the ID of a collapsed job array row, e.g. "1234_*"
+const arrayRowSuffix = "_*"
+
+// arrayAction is a job operation that can be applied to array tasks
+type arrayAction struct {
+	name string // Shown in titles, e.g. "Cancel"
+	done string // Past tense for the result, e.g. "cancelled"
+	run  func(jobs dao.JobManager, id string) error
+}
+
+var (
+	arrayCancel = arrayAction{"Cancel", "cancelled", func(jobs dao.JobManager, id string) error {
+		return jobs.Cancel(id)
+	}}
+	arrayHold = arrayAction{"Hold", "held", func(jobs dao.JobManager, id string) error {
+		return jobs.Hold(id)
+	}}
+	arrayRelease = arrayAction{"Release", "released", func(jobs dao.JobManager, id string) error {
+		return jobs.Release(id)
+	}}
+	arrayRequeue = arrayAction{"Requeue", "requeued", func(jobs dao.JobManager, id string) error {
+		_, err := jobs.Requeue(id)
+		return err
+	}}
+)
+
+// buildJobRows renders jobs as table rows. While arrays are collapsed the
+// tasks of an array are replaced by one summary row where its first task
+// was, followed by the tasks when the array is expanded.
+func (v *JobsView) buildJobRows(jobs []*dao.Job, now time.Time) [][]string {
+	if !v.collapseArrays {
+		rows := make([][]string, len(jobs))
+		for i, job := range jobs {
+			rows[i] = v.jobRow(job, now)
+		}
+		return rows
+	}
+
+	tasks := make(map[string][]*dao.Job)
+	for _, job := range jobs {
+		if job.ArrayJobID != "" {
+			tasks[job.ArrayJobID] = append(tasks[job.ArrayJobID], job)
+		}
+	}
+
+	rows := make([][]string, 0, len(jobs))
+	for _, job := range jobs {
+		if job.ArrayJobID == "" {
+			rows = append(rows, v.jobRow(job, now))
+			continue
+		}
+		arrayTasks, pending := tasks[job.ArrayJobID]
+		if !pending {
+			continue // Already rendered with the first task
+		}
+		delete(tasks, job.ArrayJobID)
+
+		summary := dao.SummarizeJobArray(job.ArrayJobID, arrayTasks, now)
+		expanded := v.expandedArrays[job.ArrayJobID]
+		row := arraySummaryRow(summary, expanded)
+		rows = append(rows, append(row, annotationCells(v.annotationOf(job.ArrayJobID, ""))...))
+		if expanded {
+			for _, task := range arrayTasks {
+				row := v.jobRow(task, now)
+				row[1] = "  └ " + row[1]
+				rows = append(rows, row)
+			}
+		}
+	}
+	return rows
+}
+
+// arraySummaryRow renders the collapsed row of a job array: task counts
+// per state in the State column and the summed runtime of its tasks
+func arraySummaryRow(summary *dao.JobArraySummary, expanded bool) []string {
+	first := summary.Jobs[0]
+	marker := "▸"
+	if expanded {
+		marker = "▾"
+	}
+
+	nodes := 0
+	priority := first.Priority
+	submitted := first.SubmitTime
+	for _, job := range summary.Jobs {
+		nodes += job.NodeCount
+		priority = max(priority, job.Priority)
+		if !job.SubmitTime.IsZero() && (submitted.IsZero() || job.SubmitTime.Before(submitted)) {
+			submitted = job.SubmitTime
+		}
+	}
+
+	return []string{
+		summary.ArrayJobID + arrayRowSuffix,
+		fmt.Sprintf("%s %s [%d tasks]", marker, first.Name, summary.TaskCount),
+		first.User,
+		first.Account,
+		summary.StateSummary(),
+		first.Partition,
+		fmt.Sprintf("%d", nodes),
+		FormatDurationDetailed(summary.Runtime),
+		first.TimeLimit,
+		fmt.Sprintf("%.0f", priority),
+		submitted.Format("2006-01-02 15:04:05"),
+		"",
+		"",
+	}
+}
+
+// selectedArrayID returns the array ID of the selected row when it is a
+// collapsed array row
+func (v *JobsView) selectedArrayID() string {
+	data := v.table.GetSelectedData()
+	if len(data) == 0 {
+		return ""
+	}
+	arrayID, ok := strings.CutSuffix(data[0], arrayRowSuffix)
+	if !ok {
+		return ""
+	}
+	return arrayID
+}
+
+// arrayOfSelection returns the array the selected row belongs to, either
+// as its summary row or as one of its tasks
+func (v *JobsView) arrayOfSelection() string {
+	if arrayID := v.selectedArrayID(); arrayID != "" {
+		return arrayID
+	}
+	data := v.table.GetSelectedData()
+	if len(data) == 0 {
+		return ""
+	}
+
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	for _, job := range v.jobs {
+		if job.ID == data[0] {
+			return job.ArrayJobID
+		}
+	}
+	return ""
+}
+
+// loadArraySummary lists every task of an array and summarises them. The
+// loaded pages may hold only part of a large array, while array-wide
+// actions apply to all of its tasks.
+func (v *JobsView) loadArraySummary(arrayID string) (*dao.JobArraySummary, error) {
+	opts := &dao.ListJobsOptions{ArrayJobID: arrayID}
+	v.mu.RLock()
+	for _, job := range v.jobs {
+		if job.ArrayJobID == arrayID && job.User != "" {
+			// All tasks belong to one user, which narrows the listing
+			opts.Users = []string{job.User}
+			break
+		}
+	}
+	v.mu.RUnlock()
+
+	list, err := v.client.Jobs().List(opts)
+	if err != nil {
+		return nil, err
+	}
+	return dao.SummarizeJobArray(arrayID, list.Jobs, time.Now()), nil
+}
+
+// withArraySummary loads the summary of an array in the background and
+// passes it to fn on the UI goroutine
+func (v *JobsView) withArraySummary(arrayID string, fn func(summary *dao.JobArraySummary)) {
+	if v.mainStatusBar != nil {
+		v.mainStatusBar.Info(fmt.Sprintf("Loading the tasks of array %s...", arrayID))
+	}
+	go func() {
+		summary, err := v.loadArraySummary(arrayID)
+		if v.app == nil {
+			return
+		}
+		v.app.QueueUpdateDraw(func() {
+			if err != nil {
+				if v.mainStatusBar != nil {
+					v.mainStatusBar.Error(fmt.Sprintf("Failed to list the tasks of array %s: %v", arrayID, err))
+				}
+				return
+			}
+			fn(summary)
+		})
+	}()
+}
+
+// toggleSelectedArray expands or collapses the selected array row and
+// reports whether the selection was an array row
+func (v *JobsView) toggleSelectedArray() bool {
+	arrayID := v.selectedArrayID()
+	if arrayID == "" {
+		return false
+	}
+	v.expandedArrays[arrayID] = !v.expandedArrays[arrayID]
+	v.updateTable()
+	return true
+}
+
+// toggleArrayCollapse switches between one row per array and one row per task
+func (v *JobsView) toggleArrayCollapse() {
+	v.collapseArrays = !v.collapseArrays
+	v.updateTable()
+	if v.mainStatusBar != nil {
+		if v.collapseArrays {
+			v.mainStatusBar.Info("Job arrays collapsed (Enter expands an array)")
+		} else {
+			v.mainStatusBar.Info("Showing every array task")
+		}
+	}
+}
+
+// actOnSelection runs action on the selected array through the scope
+// prompt, or calls single for any other row
+func (v *JobsView) actOnSelection(action arrayAction, single func()) {
+	if arrayID := v.selectedArrayID(); arrayID != "" {
+		v.showArrayActionScope(action, arrayID)
+		return
+	}
+	single()
+}
+
+// showArrayActionScope asks whether action applies to the whole array, a
+// range of tasks or only the failed tasks
+func (v *JobsView) showArrayActionScope(action arrayAction, arrayID string) {
+	v.withArraySummary(arrayID, func(summary *dao.JobArraySummary) {
+		v.showArrayScopeList(action, summary)
+	})
+}
+
+// showArrayScopeList offers the scopes of action over the tasks of summary
+func (v *JobsView) showArrayScopeList(action arrayAction, summary *dao.JobArraySummary) {
+	arrayID := summary.ArrayJobID
+	failed := summary.FailedTaskIDs()
+
+	list := tview.NewList()
+	list.AddItem(fmt.Sprintf("Whole array (%d tasks)", summary.TaskCount), summary.StateSummary(), 'a', func() {
+		v.closeArrayPage()
+		v.confirmArrayAction(action, arrayID, []string{arrayID}, summary.TaskCount)
+	})
+	list.AddItem("Task range...", "e.g. 1-10,15", 't', func() {
+		v.closeArrayPage()
+		v.promptArrayTaskRange(action, summary)
+	})
+	list.AddItem(fmt.Sprintf("Failed tasks only (%d)", len(failed)), "FAILED, TIMEOUT, NODE_FAIL, OUT_OF_MEMORY, ...", 'f', func() {
+		v.closeArrayPage()
+		if len(failed) == 0 {
+			v.warnArray(fmt.Sprintf("Array %s has no failed tasks", arrayID))
+			return
+		}
+		v.confirmArrayAction(action, arrayID, failed, len(failed))
+	})
+
+	list.SetBorder(true).
+		SetTitle(fmt.Sprintf(" %s Array %s ", action.name, arrayID)).
+		SetTitleAlign(tview.AlignCenter)
+	list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEsc {
+			v.closeArrayPage()
+			return nil
+		}
+		return event
+	})
+
+	v.showArrayPage(list, 10)
+}
+
+// promptArrayTaskRange asks for the task IDs to apply action to
+func (v *JobsView) promptArrayTaskRange(action arrayAction, summary *dao.JobArraySummary) {
+	input := styles.NewStyledInputField().
+		SetLabel("Tasks: ").
+		SetFieldWidth(30).
+		SetPlaceholder("1-10,15,20-30:2")
+
+	input.SetDoneFunc(func(key tcell.Key) {
+		v.closeArrayPage()
+		if key != tcell.KeyEnter {
+			return
+		}
+		ids, err := dao.ParseArrayTaskIDs(input.GetText())
+		if err != nil {
+			v.warnArray(fmt.Sprintf("Invalid task range: %v", err))
+			return
+		}
+		targets := summary.TaskTargets(ids)
+		if len(targets) == 0 {
+			v.warnArray(fmt.Sprintf("No task of array %s matches %s", summary.ArrayJobID, input.GetText()))
+			return
+		}
+		v.confirmArrayAction(action, summary.ArrayJobID, targets, len(targets))
+	})
+
+	input.SetBorder(true).
+		SetTitle(fmt.Sprintf(" %s Tasks of Array %s ", action.name, summary.ArrayJobID)).
+		SetTitleAlign(tview.AlignCenter)
+
+	v.showArrayPage(input, 3)
+}
+
+// requeueFailedTasks requeues every failed task of the selected array
+func (v *JobsView) requeueFailedTasks() {
+	arrayID := v.arrayOfSelection()
+	if arrayID == "" {
+		v.warnArray("Select a job array to requeue its failed tasks")
+		return
+	}
+	v.withArraySummary(arrayID, func(summary *dao.JobArraySummary) {
+		failed := summary.FailedTaskIDs()
+		if len(failed) == 0 {
+			v.warnArray(fmt.Sprintf("Array %s has no failed tasks", arrayID))
+			return
+		}
+		v.confirmArrayAction(arrayRequeue, arrayID, failed, len(failed))
+	})
+}
+
+// confirmArrayAction asks before applying action to targets, which stand
+// for tasks tasks of the array, and runs it once confirmed
+func (v *JobsView) confirmArrayAction(action arrayAction, arrayID string, targets []string, tasks int) {
+	if v.pages == nil {
+		return
+	}
+	const page = "array-action-confirmation"
+
+	text := fmt.Sprintf("%s %d task(s) of array %s?", action.name, tasks, arrayID)
+	if action.name == arrayCancel.name {
+		text += "\n\nRunning tasks are terminated immediately.\nThis action cannot be undone."
+	}
+	modal := tview.NewModal().
+		SetText(text).
+		AddButtons([]string{action.name, "Back"}).
+		SetDoneFunc(func(buttonIndex int, _ string) {
+			v.pages.RemovePage(page)
+			if v.app != nil {
+				v.app.SetFocus(v.table.Table)
+			}
+			if buttonIndex == 0 {
+				v.runArrayAction(action, arrayID, targets)
+			}
+		})
+	modal.SetBorder(true).
+		SetTitle(fmt.Sprintf(" %s Array %s ", action.name, arrayID)).
+		SetTitleAlign(tview.AlignCenter)
+
+	v.pages.AddPage(page, modal, true, true)
+	if v.app != nil {
+		v.app.SetFocus(modal)
+	}
+}
+
+// runArrayAction applies action to targets in the background and reports
+// how many succeeded
+func (v *JobsView) runArrayAction(action arrayAction, arrayID string, targets []string) {
+	if v.mainStatusBar != nil {
+		v.mainStatusBar.Info(fmt.Sprintf("%s: %d target(s) of array %s...", action.name, len(targets), arrayID))
+	}
+
+	go func() {
+		jobs := v.client.Jobs()
+		var failures []string
+		for _, id := range targets {
+			if err := action.run(jobs, id); err != nil {
+				failures = append(failures, fmt.Sprintf("%s: %v", id, err))
+			}
+		}
+
+		if v.app != nil {
+			v.app.QueueUpdateDraw(func() {
+				if v.mainStatusBar == nil {
+					return
+				}
+				done := len(targets) - len(failures)
+				if len(failures) > 0 {
+					v.mainStatusBar.Error(fmt.Sprintf("Array %s: %d of %d %s; first error %s",
+						arrayID, done, len(targets), action.done, failures[0]))
+					return
+				}
+				v.mainStatusBar.Success(fmt.Sprintf("Array %s: %d of %d %s", arrayID, done, len(targets), action.done))
+			})
+		}
+
+		time.Sleep(500 * time.Millisecond)
+		_ = v.Refresh()
+	}()
+}
+
+// showArrayPage shows a centered array dialog of the given height
+func (v *JobsView) showArrayPage(content tview.Primitive, height int) {
+	if v.pages == nil {
+		return
+	}
+	centered := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(content, height, 0, true).
+			AddItem(nil, 0, 1, false), 60, 0, true).
+		AddItem(nil, 0, 1, false)
+	v.pages.AddPage("array-action", centered, true, true)
+	if v.app != nil {
+		v.app.SetFocus(content)
+	}
+}
+
+// closeArrayPage closes the array dialog
+func (v *JobsView) closeArrayPage() {
+	if v.pages != nil {
+		v.pages.RemovePage("array-action")
+	}
+	if v.app != nil {
+		v.app.SetFocus(v.table.Table)
+	}
+}
+
+// warnArray shows an array related warning
+func (v *JobsView) warnArray(message string) {
+	if v.mainStatusBar != nil {
+		v.mainStatusBar.Warning(message)
+	}
+}
@@ -0,0 +1,127 @@
+package views
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jontk/s9s/internal/dao"
+	"github.com/rivo/tview"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func arrayTestJobs() []*dao.Job {
+	return []*dao.Job{
+		{ID: "10", Name: "single", User: "alice", State: dao.JobStateRunning},
+		{ID: "21", Name: "sweep", User: "bob", ArrayJobID: "20", ArrayTaskID: "1", State: dao.JobStateRunning, NodeCount: 1, Priority: 5},
+		{ID: "30", Name: "other", User: "carol", State: dao.JobStatePending},
+		{ID: "22", Name: "sweep", User: "bob", ArrayJobID: "20", ArrayTaskID: "2", State: dao.JobStateFailed, NodeCount: 1, Priority: 7},
+		{ID: "20", Name: "sweep", User: "bob", ArrayJobID: "20", ArrayTaskString: "3-10", State: dao.JobStatePending},
+	}
+}
+
+// arrayJobsClient lists the jobs of a cluster whose loaded page holds only
+// part of array 20, and remembers the options it was asked with
+type arrayJobsClient struct {
+	dao.SlurmClient
+	jobs   []*dao.Job
+	listed []dao.ListJobsOptions
+}
+
+func (c *arrayJobsClient) Jobs() dao.JobManager {
+	return &arrayJobManager{client: c}
+}
+
+type arrayJobManager struct {
+	dao.JobManager
+	client *arrayJobsClient
+}
+
+func (m *arrayJobManager) List(opts *dao.ListJobsOptions) (*dao.JobList, error) {
+	m.client.listed = append(m.client.listed, *opts)
+	var jobs []*dao.Job
+	for _, job := range m.client.jobs {
+		if opts.ArrayJobID == "" || job.ArrayJobID == opts.ArrayJobID {
+			jobs = append(jobs, job)
+		}
+	}
+	return &dao.JobList{Jobs: jobs, Total: len(jobs)}, nil
+}
+
+func TestJobsViewCollapsesArrays(t *testing.T) {
+	v := NewJobsView(nil)
+	now := time.Now()
+
+	rows := v.buildJobRows(arrayTestJobs(), now)
+	require.Len(t, rows, 3)
+	assert.Equal(t, "10", rows[0][0])
+	assert.Equal(t, "20"+arrayRowSuffix, rows[1][0], "the array takes the place of its first task")
+	assert.Equal(t, "▸ sweep [10 tasks]", rows[1][1])
+	assert.Equal(t, "1 R / 8 PD / 1 F", rows[1][4])
+	assert.Equal(t, "2", rows[1][6])
+	assert.Equal(t, "7", rows[1][9])
+	assert.Equal(t, "30", rows[2][0])
+
+	v.expandedArrays["20"] = true
+	rows = v.buildJobRows(arrayTestJobs(), now)
+	require.Len(t, rows, 6)
+	assert.Equal(t, "▾ sweep [10 tasks]", rows[1][1])
+	assert.Equal(t, []string{"21", "22", "20"}, []string{rows[2][0], rows[3][0], rows[4][0]})
+	assert.Equal(t, "  └ sweep", rows[2][1])
+
+	v.collapseArrays = false
+	assert.Len(t, v.buildJobRows(arrayTestJobs(), now), 5)
+}
+
+func TestJobsViewArraySelection(t *testing.T) {
+	v := NewJobsView(nil)
+	v.jobs = arrayTestJobs()
+	v.updateTable()
+
+	v.table.Select(2, 0) // The array row
+	assert.Equal(t, "20", v.selectedArrayID())
+	assert.Equal(t, "20", v.arrayOfSelection())
+
+	require.True(t, v.toggleSelectedArray())
+	assert.True(t, v.expandedArrays["20"])
+	assert.Len(t, v.table.GetFilteredData(), 6)
+
+	v.table.Select(4, 0) // Task 22
+	assert.Empty(t, v.selectedArrayID())
+	assert.Equal(t, "20", v.arrayOfSelection())
+	assert.False(t, v.toggleSelectedArray())
+
+}
+
+func TestJobsViewLoadsWholeArray(t *testing.T) {
+	// Task 23 failed on a page that is not loaded
+	unloaded := &dao.Job{ID: "23", Name: "sweep", User: "bob", ArrayJobID: "20", ArrayTaskID: "11", State: dao.JobStateFailed}
+	client := &arrayJobsClient{jobs: append(arrayTestJobs(), unloaded)}
+	v := NewJobsView(client)
+	v.jobs = arrayTestJobs()
+
+	summary, err := v.loadArraySummary("20")
+	require.NoError(t, err)
+	assert.Equal(t, 11, summary.TaskCount)
+	assert.Equal(t, []string{"22", "23"}, summary.FailedTaskIDs())
+	assert.Equal(t, []string{"21", "20_3"}, summary.TaskTargets([]int{1, 3}))
+	require.Len(t, client.listed, 1)
+	assert.Equal(t, "20", client.listed[0].ArrayJobID)
+	assert.Equal(t, []string{"bob"}, client.listed[0].Users)
+}
+
+func TestJobsViewConfirmsArrayActions(t *testing.T) {
+	v := NewJobsView(nil)
+	v.SetPages(tview.NewPages())
+	v.jobs = arrayTestJobs()
+
+	ran := false
+	action := arrayAction{"Cancel", "cancelled", func(dao.JobManager, string) error {
+		ran = true
+		return nil
+	}}
+	v.confirmArrayAction(action, "20", []string{"20"}, 10)
+
+	assert.True(t, v.pages.HasPage("array-action-confirmation"))
+	assert.False(t, ran, "nothing runs before the action is confirmed")
+}
@@ -0,0 +1,169 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/jontk/s9s/internal/dao"
+	"github.com/jontk/s9s/internal/efficiency"
+	"github.com/jontk/s9s/internal/export"
+	"github.com/rivo/tview"
+)
+
+// efficiencyColor returns the color for an efficiency percentage
+func efficiencyColor(percent float64) string {
+	switch {
+	case percent < efficiency.PoorThreshold:
+		return "red"
+	case percent < efficiency.GoodThreshold:
+		return "yellow"
+	default:
+		return "green"
+	}
+}
+
+// formatEfficiencyCell renders the efficiency column as the lowest resource
+// efficiency of the job and the resource it applies to, e.g. "25% MEM"
+func formatEfficiencyCell(job *dao.Job, now time.Time) string {
+	report, ok := efficiency.Calculate(job, now)
+	if !ok {
+		return ""
+	}
+
+	resource, score, found := "", 0.0, false
+	for _, m := range []struct {
+		name   string
+		metric efficiency.Metric
+	}{{"CPU", report.CPU}, {"MEM", report.Memory}, {"GPU", report.GPU}} {
+		if m.metric.Available && (!found || m.metric.Percent() < score) {
+			resource, score, found = m.name, m.metric.Percent(), true
+		}
+	}
+	if !found {
+		return ""
+	}
+	return fmt.Sprintf("[%s]%.0f%% %s[white]", efficiencyColor(score), score, resource)
+}
+
+// writeEfficiencyDetails writes the seff-style efficiency section of the job details
+func writeEfficiencyDetails(d *strings.Builder, job *dao.Job) {
+	report, ok := efficiency.Calculate(job, time.Now())
+	if !ok || (!report.HasUsage() && !report.Time.Available) {
+		return
+	}
+
+	d.WriteString("[teal]Efficiency[white]\n")
+	if report.CPU.Available {
+		writeDetailIndented(d, "CPU", fmt.Sprintf("[%s]%.1f%%[white] (%s of %s core-walltime, %d cores)",
+			efficiencyColor(report.CPU.Percent()), report.CPU.Percent(),
+			FormatDurationDetailed(secondsDuration(report.CPU.Used)),
+			FormatDurationDetailed(secondsDuration(report.CPU.Allocated)),
+			report.CPUs))
+	}
+	if report.Memory.Available {
+		writeDetailIndented(d, "Memory", fmt.Sprintf("[%s]%.1f%%[white] (%s peak of %s requested)",
+			efficiencyColor(report.Memory.Percent()), report.Memory.Percent(),
+			FormatMemory(int64(report.Memory.Used)), FormatMemory(int64(report.Memory.Allocated))))
+	}
+	if report.GPU.Available {
+		writeDetailIndented(d, "GPU", fmt.Sprintf("[%s]%.1f%%[white] (average utilisation of %d GPUs)",
+			efficiencyColor(report.GPU.Percent()), report.GPU.Percent(), report.GPUs))
+	}
+	if report.Time.Available {
+		writeDetailIndented(d, "Time", fmt.Sprintf("%.1f%% (%s of %s limit)",
+			report.Time.Percent(),
+			FormatDurationDetailed(report.Elapsed),
+			FormatDurationDetailed(secondsDuration(report.Time.Allocated))))
+	}
+	if !report.HasUsage() {
+		writeDetailIndented(d, "Usage", "[gray]not reported by the cluster[white]")
+	}
+	d.WriteString("\n")
+}
+
+// secondsDuration converts a number of seconds to a duration
+func secondsDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// showWasteSummary shows the unused allocation of the loaded jobs per user
+func (v *JobsView) showWasteSummary() {
+	if v.pages == nil {
+		return
+	}
+
+	v.mu.RLock()
+	jobs := make([]*dao.Job, len(v.jobs))
+	copy(jobs, v.jobs)
+	v.mu.RUnlock()
+
+	summaries := efficiency.SummarizeWaste(jobs, time.Now())
+
+	textView := tview.NewTextView().
+		SetDynamicColors(true).
+		SetText(formatWasteSummary(summaries)).
+		SetScrollable(true)
+
+	modal := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(textView, 0, 1, true).
+		AddItem(tview.NewTextView().SetDynamicColors(true).
+			SetText("[yellow]e[white] Export  [yellow]ESC[white] Close"), 1, 0, false)
+
+	modal.SetBorder(true).
+		SetTitle(" Efficiency Waste by User ").
+		SetTitleAlign(tview.AlignCenter)
+
+	centeredModal := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(modal, 0, 6, true).
+			AddItem(nil, 0, 1, false), 0, 8, true).
+		AddItem(nil, 0, 1, false)
+
+	modal.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEsc:
+			v.pages.RemovePage("waste-summary")
+			return nil
+		case event.Key() == tcell.KeyRune && (event.Rune() == 'e' || event.Rune() == 'E'):
+			showTableExportDialog(v.pages, v.app, "Efficiency Waste", func() *export.TableData {
+				return export.WasteTableData(summaries)
+			})
+			return nil
+		}
+		return event
+	})
+
+	v.pages.AddPage("waste-summary", centeredModal, true, true)
+}
+
+// formatWasteSummary renders the per-user waste table
+func formatWasteSummary(summaries []*efficiency.UserWaste) string {
+	if len(summaries) == 0 {
+		return "[gray]No loaded jobs report CPU, memory or GPU usage.[white]\n"
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("[teal]%-12s %5s %12s %10s %14s %10s %12s %10s[white]\n",
+		"User", "Jobs", "CPU h wasted", "CPU eff", "Mem GBh waste", "Mem eff", "GPU h wasted", "GPU eff"))
+	for _, w := range summaries {
+		b.WriteString(fmt.Sprintf("%-12s %5d %12.1f %s %14.1f %s %12.1f %s\n",
+			TruncateString(w.User, 12), w.Jobs,
+			w.CPUHoursWasted, formatWasteEfficiency(w.CPUHours, w.CPUEfficiency()),
+			w.MemGBHoursWasted, formatWasteEfficiency(w.MemGBHours, w.MemoryEfficiency()),
+			w.GPUHoursWasted, formatWasteEfficiency(w.GPUHours, w.GPUEfficiency())))
+	}
+	return b.String()
+}
+
+// formatWasteEfficiency renders a colored, fixed-width efficiency percentage
+func formatWasteEfficiency(total, percent float64) string {
+	if total <= 0 {
+		return fmt.Sprintf("%10s", "-")
+	}
+	return fmt.Sprintf("[%s]%9.1f%%[white]", efficiencyColor(percent), percent)
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/jontk/s9s/internal/output"
 	"github.com/jontk/s9s/internal/ssh"
 	"github.com/jontk/s9s/internal/streaming"
+	"github.com/jontk/s9s/internal/ui/styles"
 	"github.com/jontk/s9s/pkg/slurm"
 	"github.com/rivo/tview"
 )
@@ -46,6 +47,11 @@ type JobOutputView struct {
 	streamToggle  *tview.Button
 	scrollToggle  *tview.Button
 
+	// Multi-task (job array / per-rank output) support
+	mergedTasks bool
+	taskChannel <-chan streaming.StreamEvent
+	taskFilter  string
+
 	// Output reading
 	outputReader output.Reader
 }
@@ -124,7 +130,7 @@ func (v *JobOutputView) buildUI() {
 	// Create help text with streaming commands
 	helpText := tview.NewTextView()
 	helpText.SetDynamicColors(true)
-	helpText.SetText("[yellow]Keys:[white] r=Refresh t=Toggle Stream a=Auto-scroll s=Switch stdout/stderr f=Follow e=Export Esc=Close")
+	helpText.SetText("[yellow]Keys:[white] r=Refresh t=Toggle Stream a=Auto-scroll s=Switch stdout/stderr f=Follow e=Export m=Merge Tasks k=Task Filter g=First Error Esc=Close")
 	helpText.SetTextAlign(tview.AlignCenter)
 
 	// Create main content area
@@ -205,6 +211,10 @@ func (v *JobOutputView) setupEventHandlers() {
 		case tcell.KeyRune:
 			switch event.Rune() {
 			case 'r', 'R':
+				if v.mergedTasks {
+					v.reloadMergedBuffer()
+					return nil
+				}
 				v.loadOutput()
 				return nil
 			case 't', 'T':
@@ -222,12 +232,35 @@ func (v *JobOutputView) setupEventHandlers() {
 			case 'e', 'E':
 				v.exportOutput()
 				return nil
+			case 'm', 'M':
+				v.toggleMergedTasks()
+				return nil
+			case 'k', 'K':
+				v.promptTaskFilter()
+				return nil
+			case 'g', 'G':
+				v.jumpToFirstTaskError()
+				return nil
 			}
 		}
 		return event
 	})
 }
 
+// isMockClient reports whether client is, or wraps, the mock SLURM client
+func isMockClient(client dao.SlurmClient) bool {
+	for {
+		if _, ok := client.(*slurm.MockClient); ok {
+			return true
+		}
+		wrapper, ok := client.(interface{ Unwrap() dao.SlurmClient })
+		if !ok {
+			return false
+		}
+		client = wrapper.Unwrap()
+	}
+}
+
 // loadOutput loads job output from SLURM
 func (v *JobOutputView) loadOutput() {
 	v.textView.SetText("[yellow]Loading output...[white]")
@@ -239,7 +272,7 @@ func (v *JobOutputView) loadOutput() {
 		// Try to get job output from client
 		if jobMgr := v.client.Jobs(); jobMgr != nil {
 			// For mock client, generate sample output
-			if _, ok := v.client.(*slurm.MockClient); ok {
+			if isMockClient(v.client) {
 				content = v.generateMockOutput()
 			} else {
 				// For real client, try to get actual output
@@ -279,8 +312,8 @@ func (v *JobOutputView) getJobOutput() (string, error) {
 		return "", fmt.Errorf("failed to read job output: %w", err)
 	}
 
-	// Format output with metadata
-	result := content.Content
+	// Render ANSI colors and collapse carriage-return progress redraws
+	result := streaming.RenderTerminalOutput(content.Content)
 
 	// Add informational header if truncated
 	if content.Truncated {
@@ -390,6 +423,9 @@ func (v *JobOutputView) switchOutputType() {
 	if v.isStreaming {
 		v.stopStreaming()
 	}
+	if v.mergedTasks {
+		v.stopMergedTasks()
+	}
 	v.streamContent.Reset()
 
 	if v.outputType == "stdout" {
@@ -466,8 +502,8 @@ func (v *JobOutputView) showExportDialog() {
 
 // performExport performs the actual export operation
 func (v *JobOutputView) performExport(format export.ExportFormat) {
-	// Get current output content
-	content := v.textView.GetText(false)
+	// Get current output content without color tags
+	content := v.textView.GetText(true)
 	if content == "" {
 		v.showNotification("No content to export")
 		return
@@ -583,6 +619,7 @@ func (v *JobOutputView) show() {
 func (v *JobOutputView) close() {
 	v.stopAutoRefresh()
 	v.stopStreaming()
+	v.stopMergedTasks()
 	if v.pages != nil {
 		v.pages.RemovePage("job-output")
 	}
@@ -697,8 +734,10 @@ func (v *JobOutputView) handleStreamEvent(event *streaming.StreamEvent) {
 		case streaming.StreamEventNewOutput:
 			// Accumulate content in our own builder to preserve newlines,
 			// then set the full text (avoids tview's Write/GetText quirks)
+			// The partial line (e.g. a progress bar being redrawn) is shown
+			// but not accumulated until it is completed.
 			v.streamContent.WriteString(event.Content)
-			v.textView.SetText(v.streamContent.String())
+			v.textView.SetText(v.streamContent.String() + event.PartialLine)
 
 			// Auto-scroll if enabled
 			if v.autoScroll {
@@ -728,7 +767,7 @@ func (v *JobOutputView) loadBufferedOutput() {
 	}
 
 	go func() {
-		lines, err := v.streamManager.GetBuffer(v.jobID, v.outputType)
+		lines, err := v.streamManager.GetRenderedBuffer(v.jobID, v.outputType)
 		if err != nil {
 			return
 		}
@@ -782,6 +821,12 @@ func (v *JobOutputView) updateStreamingUI() {
 	if v.isStreaming {
 		titleSuffix = " [●LIVE]"
 	}
+	if v.mergedTasks {
+		titleSuffix = " [●ALL TASKS]"
+		if v.taskFilter != "" {
+			titleSuffix = fmt.Sprintf(" [●TASKS %s]", v.taskFilter)
+		}
+	}
 	v.textView.SetTitle(fmt.Sprintf(" Job %s - %s (%s)%s ", v.jobID, v.jobName, strings.ToUpper(v.outputType), titleSuffix))
 }
 
@@ -791,6 +836,14 @@ func (v *JobOutputView) getStatusText() string {
 		return "[gray]Streaming not available[white]"
 	}
 
+	if v.mergedTasks {
+		taskCount := 0
+		if sources, err := v.streamManager.GetTaskSources(v.jobID, v.outputType); err == nil {
+			taskCount = len(sources)
+		}
+		return fmt.Sprintf("[green]●[white] Merged stream of %d task(s) | Press 'k' to filter tasks, 'g' for first error", taskCount)
+	}
+
 	if v.isStreaming {
 		bufferInfo := ""
 		if v.outputBuffer != nil {
@@ -821,3 +874,186 @@ func (v *JobOutputView) getStreamStatusIndicator() string {
 
 	return "[gray]● STOPPED[white]"
 }
+
+// Multi-task Methods
+
+// toggleMergedTasks switches between the single output file and a merged
+// stream of every task of a job array or multi-node job
+func (v *JobOutputView) toggleMergedTasks() {
+	if v.streamManager == nil {
+		v.showNotification("Merged task output not available - Stream Manager not configured")
+		return
+	}
+
+	if v.mergedTasks {
+		v.stopMergedTasks()
+		v.updateStreamingUI()
+		v.loadOutput()
+		return
+	}
+
+	if v.isStreaming {
+		v.stopStreaming()
+	}
+
+	if err := v.streamManager.StartMultiTaskStream(v.jobID, v.outputType); err != nil {
+		v.showNotification(fmt.Sprintf("Failed to merge task output: %v", err))
+		return
+	}
+
+	v.taskChannel = v.streamManager.SubscribeTasks(v.jobID, v.outputType)
+	v.mergedTasks = true
+	v.taskFilter = ""
+	v.streamContent.Reset()
+	v.textView.SetText("[yellow]Waiting for task output...[white]")
+	v.updateStreamingUI()
+
+	go v.processTaskEvents(v.taskChannel)
+}
+
+// stopMergedTasks stops the merged task stream if one is running
+func (v *JobOutputView) stopMergedTasks() {
+	if !v.mergedTasks || v.streamManager == nil {
+		return
+	}
+
+	_ = v.streamManager.StopMultiTaskStream(v.jobID, v.outputType)
+	v.taskChannel = nil
+	v.mergedTasks = false
+	v.taskFilter = ""
+	v.streamContent.Reset()
+}
+
+// processTaskEvents appends merged task output as it arrives
+func (v *JobOutputView) processTaskEvents(ch <-chan streaming.StreamEvent) {
+	for event := range ch {
+		if event.EventType != streaming.StreamEventNewOutput {
+			continue
+		}
+		content := event.Content
+		v.app.QueueUpdateDraw(func() {
+			if !v.mergedTasks {
+				return
+			}
+			v.streamContent.WriteString(content)
+			v.textView.SetText(v.streamContent.String())
+			if v.autoScroll {
+				v.textView.ScrollToEnd()
+			}
+		})
+	}
+}
+
+// reloadMergedBuffer redraws the merged output from the stream buffer,
+// honoring the current task filter, and returns the displayed lines
+func (v *JobOutputView) reloadMergedBuffer() []string {
+	lines, err := v.streamManager.GetMultiTaskBuffer(v.jobID, v.outputType)
+	if err != nil {
+		v.showNotification(fmt.Sprintf("Failed to read merged output: %v", err))
+		return nil
+	}
+
+	rendered, err := v.streamManager.RenderMultiTaskLines(v.jobID, v.outputType, lines)
+	if err != nil {
+		v.showNotification(fmt.Sprintf("Failed to read merged output: %v", err))
+		return nil
+	}
+
+	v.streamContent.Reset()
+	for _, line := range rendered {
+		v.streamContent.WriteString(line)
+		v.streamContent.WriteString("\n")
+	}
+	v.textView.SetText(v.streamContent.String())
+	return lines
+}
+
+// promptTaskFilter asks which tasks to show in the merged stream
+func (v *JobOutputView) promptTaskFilter() {
+	if !v.mergedTasks {
+		v.showNotification("Task filtering is only available in merged task mode (press 'm')")
+		return
+	}
+
+	input := styles.NewStyledInputField().
+		SetLabel("Tasks (e.g. 0-3,7; empty for all): ").
+		SetFieldWidth(30).
+		SetText(v.taskFilter)
+
+	input.SetDoneFunc(func(key tcell.Key) {
+		v.pages.RemovePage("task-filter")
+		v.app.SetFocus(v.textView)
+		if key != tcell.KeyEnter {
+			return
+		}
+
+		spec := strings.TrimSpace(input.GetText())
+		taskIDs, err := streaming.ParseTaskSelection(spec)
+		if err != nil {
+			v.showNotification(err.Error())
+			return
+		}
+		if err := v.streamManager.SetTaskFilter(v.jobID, v.outputType, taskIDs); err != nil {
+			v.showNotification(err.Error())
+			return
+		}
+
+		v.taskFilter = spec
+		v.reloadMergedBuffer()
+		if v.autoScroll {
+			v.textView.ScrollToEnd()
+		}
+		v.updateStreamingUI()
+	})
+
+	input.SetBorder(true).
+		SetTitle(" Task Filter ").
+		SetTitleAlign(tview.AlignCenter)
+
+	modal := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().
+			AddItem(nil, 0, 1, false).
+			AddItem(input, 60, 0, true).
+			AddItem(nil, 0, 1, false), 3, 0, true).
+		AddItem(nil, 0, 1, false)
+
+	v.pages.AddPage("task-filter", modal, true, true)
+	v.app.SetFocus(input)
+}
+
+// jumpToFirstTaskError narrows the merged stream to the first task that
+// printed an error and scrolls to that line
+func (v *JobOutputView) jumpToFirstTaskError() {
+	if !v.mergedTasks {
+		v.showNotification("First-error navigation is only available in merged task mode (press 'm')")
+		return
+	}
+
+	taskErr, err := v.streamManager.FirstTaskError(v.jobID, v.outputType)
+	if err != nil {
+		v.showNotification(err.Error())
+		return
+	}
+	if taskErr == nil {
+		v.showNotification("No task has printed an error yet")
+		return
+	}
+
+	if err := v.streamManager.SetTaskFilter(v.jobID, v.outputType, []string{taskErr.TaskID}); err != nil {
+		v.showNotification(err.Error())
+		return
+	}
+	v.taskFilter = taskErr.TaskID
+
+	lines := v.reloadMergedBuffer()
+	v.autoScroll = false
+	for i, line := range lines {
+		if line == taskErr.Line {
+			v.textView.ScrollTo(i, 0)
+			break
+		}
+	}
+	v.updateStreamingUI()
+}
@@ -1,6 +1,7 @@
 package views
 
 import (
+	"context"
 	"encoding/base64"
 	"fmt"
 	"os"
@@ -12,6 +13,8 @@ import (
 	"github.com/gdamore/tcell/v2"
 	"github.com/jontk/s9s/internal/config"
 	"github.com/jontk/s9s/internal/dao"
+	"github.com/jontk/s9s/internal/jobtemplate"
+	"github.com/jontk/s9s/internal/sweep"
 	"github.com/jontk/s9s/internal/ui/styles"
 	"github.com/rivo/tview"
 )
@@ -22,14 +25,17 @@ type JobSubmissionWizard struct {
 	app              *tview.Application
 	pages            *tview.Pages
 	form             *tview.Form
-	templates        []*dao.JobTemplate
+	templates        []*jobtemplate.Template
+	templatesErr     error // Templates that could not be loaded
 	onSubmit         func(jobID string)
 	onCancel         func()
 	workingDir       string // Current working directory at application start
 	slurmUser        string // Resolved SLURM username for user lookups
 	submissionConfig *config.JobSubmissionConfig
-	selectedTemplate *dao.JobTemplate   // Track currently selected template for hidden fields
-	currentJob       *dao.JobSubmission // Track current job for field visibility
+	selectedTemplate *jobtemplate.Template // Track currently selected template for hidden fields
+	currentJob       *dao.JobSubmission    // Track current job for field visibility
+	groups           *sweep.GroupStore     // Where parameter sweeps are recorded as job groups
+	ctx              context.Context       // Bounds background submissions, e.g. of sweeps
 }
 
 // NewJobSubmissionWizard creates a new job submission wizard
@@ -46,8 +52,9 @@ func NewJobSubmissionWizard(client dao.SlurmClient, app *tview.Application, cfg
 		workingDir:       workingDir,
 		slurmUser:        slurmUser,
 		submissionConfig: cfg,
+		ctx:              context.Background(),
 	}
-	w.templates = w.mergeTemplates()
+	w.templates, w.templatesErr = LoadTemplateLibrary(cfg)
 	return w
 }
 
@@ -61,70 +68,6 @@ func (w *JobSubmissionWizard) Show(pages *tview.Pages, onSubmit func(jobID strin
 	w.showTemplateSelection()
 }
 
-// mergeTemplates performs 3-tier template merging with name-based override.
-// Precedence (highest to lowest):
-//  1. User-saved templates from ~/.s9s/templates/*.json
-//  2. Config YAML templates
-//  3. Built-in hardcoded templates
-//
-// Which tiers are active is controlled by the templateSources config option.
-//
-//nolint:cyclop // 3-tier template merge logic
-func (w *JobSubmissionWizard) mergeTemplates() []*dao.JobTemplate {
-	sources := config.ResolveTemplateSources(w.submissionConfig)
-	seen := make(map[string]int) // name -> index in result
-	var result []*dao.JobTemplate
-
-	// 1. Built-in templates (lowest priority)
-	if config.HasTemplateSource(sources, "builtin") {
-		for _, t := range BuiltinTemplates() {
-			seen[t.Name] = len(result)
-			result = append(result, t)
-		}
-	}
-
-	// 2. Config YAML templates
-	if config.HasTemplateSource(sources, "config") && w.submissionConfig != nil {
-		for _, ct := range w.submissionConfig.Templates {
-			vals := config.JobSubmissionFromMap(ct.Defaults)
-			js := ConfigValuesToJobSubmission(&vals)
-			t := &dao.JobTemplate{
-				Name:          ct.Name,
-				Description:   ct.Description,
-				JobSubmission: js,
-			}
-			if idx, ok := seen[ct.Name]; ok {
-				result[idx] = t
-			} else {
-				seen[ct.Name] = len(result)
-				result = append(result, t)
-			}
-		}
-	}
-
-	// 3. User-saved templates (highest priority)
-	if config.HasTemplateSource(sources, "saved") {
-		mgr := NewJobTemplateManager()
-		for _, saved := range mgr.GetTemplates() {
-			t := &dao.JobTemplate{
-				Name:        saved.Name,
-				Description: saved.Description,
-			}
-			if saved.JobSubmission != nil {
-				t.JobSubmission = *saved.JobSubmission
-			}
-			if idx, ok := seen[saved.Name]; ok {
-				result[idx] = t
-			} else {
-				seen[saved.Name] = len(result)
-				result = append(result, t)
-			}
-		}
-	}
-
-	return result
-}
-
 // showTemplateSelection shows the template selection screen
 func (w *JobSubmissionWizard) showTemplateSelection() {
 	list := tview.NewList()
@@ -134,7 +77,7 @@ func (w *JobSubmissionWizard) showTemplateSelection() {
 
 	// Add custom job option
 	list.AddItem("Custom Job", "Create a job from scratch", '0', func() {
-		w.showJobForm(nil)
+		w.showJobForm(nil, nil)
 	})
 
 	// Add merged templates
@@ -144,12 +87,16 @@ func (w *JobSubmissionWizard) showTemplateSelection() {
 		if i < 9 {
 			shortcut = rune('1' + i)
 		}
+		description := t.Description
+		if t.Version != "" {
+			description = fmt.Sprintf("%s (v%s)", description, t.Version)
+		}
 		list.AddItem(
 			t.Name,
-			t.Description,
+			description,
 			shortcut,
 			func() {
-				w.showJobForm(t)
+				w.selectTemplate(t)
 			},
 		)
 	}
@@ -177,33 +124,119 @@ func (w *JobSubmissionWizard) showTemplateSelection() {
 	// Create centered layout
 	centered := createCenteredModal(list, 50, 20)
 	w.pages.AddPage("job-wizard-templates", centered, true, true)
+
+	if w.templatesErr != nil {
+		w.showError(fmt.Sprintf("Some templates could not be loaded:\n%v", w.templatesErr))
+		w.templatesErr = nil
+	}
 }
 
-// showJobForm shows the job submission form
-//
-//nolint:cyclop // multi-step form initialization
-func (w *JobSubmissionWizard) showJobForm(template *dao.JobTemplate) {
+// selectTemplate continues with the selected template: parameterized
+// templates first ask for their parameters
+func (w *JobSubmissionWizard) selectTemplate(t *jobtemplate.Template) {
+	if t.Parameterized() {
+		w.showParameterForm(t)
+		return
+	}
+	job, err := TemplateSubmission(t, nil)
+	if err != nil {
+		w.showError(err.Error())
+		return
+	}
+	w.showJobForm(t, &job)
+}
+
+// showParameterForm asks for the parameters of a template, prefilled with
+// their defaults, and continues with the form of the rendered submission
+func (w *JobSubmissionWizard) showParameterForm(t *jobtemplate.Template) {
 	form := styles.StyleForm(tview.NewForm())
-	w.form = form
+	form.SetBorder(true).
+		SetTitle(fmt.Sprintf(" %s - Parameters ", t.Name)).
+		SetTitleAlign(tview.AlignCenter)
 
-	// 1. Start with hardcoded defaults
-	job := &dao.JobSubmission{
-		TimeLimit: "01:00:00",
-		CPUs:      1,
-		Nodes:     1,
+	values := make(map[string]string, len(t.Parameters))
+	for _, p := range t.Parameters {
+		name := p.Name
+		label := name
+		if p.Required {
+			label += " *"
+		}
+		if p.Default != nil {
+			values[name] = fmt.Sprint(p.Default)
+		}
+
+		switch p.Type {
+		case jobtemplate.TypeEnum:
+			index := w.getStringIndex(p.Options, values[name])
+			values[name] = p.Options[index]
+			form.AddDropDown(label, p.Options, index, func(option string, _ int) {
+				values[name] = option
+			})
+		case jobtemplate.TypeBool:
+			form.AddCheckbox(label, values[name] == "true", func(checked bool) {
+				values[name] = strconv.FormatBool(checked)
+			})
+		default:
+			input := tview.NewInputField().
+				SetLabel(label).
+				SetText(values[name]).
+				SetFieldWidth(40).
+				SetPlaceholder(p.Description)
+			input.SetChangedFunc(func(text string) {
+				values[name] = text
+			})
+			form.AddFormItem(input)
+		}
 	}
 
-	// 2. Overlay formDefaults from config
-	if w.submissionConfig != nil && w.submissionConfig.FormDefaults != nil {
-		vals := config.JobSubmissionFromMap(w.submissionConfig.FormDefaults)
-		cfgDefaults := ConfigValuesToJobSubmission(&vals)
-		overlayJobDefaults(job, &cfgDefaults)
+	back := func() {
+		w.pages.RemovePage("job-wizard-parameters")
+		w.showTemplateSelection()
 	}
+	form.AddButton("Next", func() {
+		set := make(map[string]string, len(values))
+		for name, value := range values {
+			if value != "" {
+				set[name] = value
+			}
+		}
+		job, err := TemplateSubmission(t, set)
+		if err != nil {
+			w.showError(err.Error())
+			return
+		}
+		w.pages.RemovePage("job-wizard-parameters")
+		w.showJobForm(t, &job)
+	})
+	form.AddButton("Back", back)
+
+	form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEsc {
+			back()
+			return nil
+		}
+		return event
+	})
+
+	centered := createCenteredModal(form, 70, 2*len(t.Parameters)+5)
+	w.pages.AddPage("job-wizard-parameters", centered, true, true)
+}
+
+// showJobForm shows the job submission form, with the values of the
+// submission of the selected template, if any
+//
+//nolint:cyclop // multi-step form initialization
+func (w *JobSubmissionWizard) showJobForm(template *jobtemplate.Template, templateJob *dao.JobSubmission) {
+	form := styles.StyleForm(tview.NewForm())
+	w.form = form
+
+	// 1-2. Start with hardcoded defaults and formDefaults from config
+	job := newFormJob(w.submissionConfig)
 
 	// 3. If template selected, overlay template defaults
 	if template != nil {
 		w.selectedTemplate = template
-		overlayJobDefaults(job, &template.JobSubmission)
+		overlayJobDefaults(job, templateJob)
 		form.SetTitle(fmt.Sprintf(" Submit Job - %s ", template.Name))
 	} else {
 		w.selectedTemplate = nil
@@ -250,6 +283,22 @@ func (w *JobSubmissionWizard) showJobForm(template *dao.JobTemplate) {
 	w.pages.RemovePage("job-wizard-templates")
 }
 
+// newFormJob returns the job the submission form starts from: hardcoded
+// defaults with the formDefaults of cfg over them
+func newFormJob(cfg *config.JobSubmissionConfig) *dao.JobSubmission {
+	job := &dao.JobSubmission{
+		TimeLimit: "01:00:00",
+		CPUs:      1,
+		Nodes:     1,
+	}
+	if cfg != nil && cfg.FormDefaults != nil {
+		vals := config.JobSubmissionFromMap(cfg.FormDefaults)
+		cfgDefaults := ConfigValuesToJobSubmission(&vals)
+		overlayJobDefaults(job, &cfgDefaults)
+	}
+	return job
+}
+
 // ConfigValuesToJobSubmission converts config.JobSubmissionValues to dao.JobSubmission
 func ConfigValuesToJobSubmission(v *config.JobSubmissionValues) dao.JobSubmission {
 	return dao.JobSubmission{
@@ -792,23 +841,17 @@ func fieldHasValue(job *dao.JobSubmission, field string) bool {
 
 // isExplicitlyHidden checks global and per-template hiddenFields lists
 func (w *JobSubmissionWizard) isExplicitlyHidden(fieldName string) bool {
-	if w.submissionConfig == nil {
-		return false
-	}
-	for _, f := range w.submissionConfig.HiddenFields {
-		if f == fieldName {
-			return true
+	if w.submissionConfig != nil {
+		for _, f := range w.submissionConfig.HiddenFields {
+			if f == fieldName {
+				return true
+			}
 		}
 	}
 	if w.selectedTemplate != nil {
-		for _, ct := range w.submissionConfig.Templates {
-			if ct.Name == w.selectedTemplate.Name {
-				for _, f := range ct.HiddenFields {
-					if f == fieldName {
-						return true
-					}
-				}
-				break
+		for _, f := range w.selectedTemplate.HiddenFields {
+			if f == fieldName {
+				return true
 			}
 		}
 	}
@@ -1590,6 +1633,10 @@ func (w *JobSubmissionWizard) addJobFormButtons(form *tview.Form, job *dao.JobSu
 		w.showJobPreview(job)
 	})
 
+	form.AddButton("Sweep", func() {
+		w.showSweepBuilder(job)
+	})
+
 	form.AddButton("Cancel", func() {
 		w.pages.RemovePage("job-wizard-form")
 		w.showTemplateSelection()
@@ -1617,6 +1664,31 @@ func (w *JobSubmissionWizard) setupJobFormHandlers(form *tview.Form, job *dao.Jo
 
 // validateAndSubmitJob validates and submits the job
 func (w *JobSubmissionWizard) validateAndSubmitJob(job *dao.JobSubmission) error {
+	if err := ValidateJobSubmission(job); err != nil {
+		return err
+	}
+
+	// Submit the job
+	jobID, err := w.client.Jobs().Submit(job)
+	if err != nil {
+		return fmt.Errorf("failed to submit job: %w", err)
+	}
+
+	// Show success message
+	w.showSuccess(fmt.Sprintf("Job successfully submitted!\n\nJob ID: %s\nJob Name: %s", jobID, job.Name))
+
+	// Close wizard and callback
+	w.pages.RemovePage("job-wizard-form")
+	if w.onSubmit != nil {
+		w.onSubmit(jobID)
+	}
+
+	return nil
+}
+
+// ValidateJobSubmission checks the fields the submission form requires
+// and the format of the time limit and memory
+func ValidateJobSubmission(job *dao.JobSubmission) error {
 	// Validate required fields
 	if job.Name == "" {
 		return fmt.Errorf("job name is required")
@@ -1637,23 +1709,7 @@ func (w *JobSubmissionWizard) validateAndSubmitJob(job *dao.JobSubmission) error
 	if job.Memory != "" && !isValidMemoryFormat(job.Memory) {
 		return fmt.Errorf("invalid memory format (use M or G suffix, e.g., 1024M or 4G)")
 	}
-
-	// Submit the job
-	jobID, err := w.client.Jobs().Submit(job)
-	if err != nil {
-		return fmt.Errorf("failed to submit job: %w", err)
-	}
-
-	// Show success message
-	w.showSuccess(fmt.Sprintf("Job successfully submitted!\n\nJob ID: %s\nJob Name: %s", jobID, job.Name))
-
-	// Close wizard and callback
-	w.pages.RemovePage("job-wizard-form")
-	if w.onSubmit != nil {
-		w.onSubmit(jobID)
-	}
-
-	return nil
+	return dao.ValidateDependencies(job.Dependencies)
 }
 
 // showJobPreview shows a preview of the job submission
@@ -1968,7 +2024,7 @@ func generateJobScript(job *dao.JobSubmission) string {
 	}
 
 	if len(job.Dependencies) > 0 {
-		script.WriteString(fmt.Sprintf("[green]#SBATCH --dependency=[white]afterok:%s\n", strings.Join(job.Dependencies, ":")))
+		script.WriteString(fmt.Sprintf("[green]#SBATCH --dependency=[white]%s\n", dao.DependencyString(job.Dependencies)))
 	}
 
 	if job.Constraints != "" {
@@ -0,0 +1,336 @@
+package views
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/jontk/s9s/internal/config"
+	"github.com/jontk/s9s/internal/dao"
+	"github.com/jontk/s9s/internal/debug"
+	"github.com/jontk/s9s/internal/fileperms"
+	"github.com/jontk/s9s/internal/sweep"
+	"github.com/jontk/s9s/internal/ui/components"
+	"github.com/jontk/s9s/internal/ui/styles"
+	"github.com/rivo/tview"
+)
+
+const (
+	// defaultSweepMaxJobs is the most individual jobs a sweep submits when
+	// sweep.maxJobs is not configured
+	defaultSweepMaxJobs = 500
+
+	// defaultSweepSubmitInterval is the pause between submissions when
+	// sweep.submitInterval is not configured
+	defaultSweepSubmitInterval = 200 * time.Millisecond
+
+	// sweepPreviewRows is how many jobs the sweep preview lists
+	sweepPreviewRows = 100
+)
+
+// SweepRequest describes a parameter sweep of the job of the submission form
+type SweepRequest struct {
+	Group      string // Name of the job group the sweep is tagged with
+	Parameters string // A grid such as "lr=0.1,0.01 seed=1..5", or a CSV file
+	Mode       string // sweep.ModeJobs or sweep.ModeArray
+	MaxRunning int    // Array tasks running at once; 0 for no limit
+}
+
+// SweepPlan is a sweep expanded into the jobs to submit
+type SweepPlan struct {
+	Request     SweepRequest
+	Sweep       *sweep.Sweep
+	Jobs        []*dao.JobSubmission // One job per point, or the job array
+	MappingFile string               // Array only: where the task parameters are written
+	Mapping     []byte               // Array only: the task parameters
+}
+
+// PlanSweep expands job by the sweep of req and validates the resulting
+// jobs. A sweep of individual jobs may have at most sweep.maxJobs jobs;
+// larger sweeps must be submitted as a job array.
+func PlanSweep(cfg *config.JobSubmissionConfig, groups *sweep.GroupStore, job *dao.JobSubmission, req SweepRequest) (*SweepPlan, error) {
+	if err := sweep.ValidateGroupName(req.Group); err != nil {
+		return nil, err
+	}
+	if groups != nil {
+		if _, exists := groups.Get(req.Group); exists {
+			return nil, fmt.Errorf("group %s already exists", req.Group)
+		}
+	}
+
+	var s *sweep.Sweep
+	var err error
+	if parameters := strings.TrimSpace(req.Parameters); strings.HasSuffix(strings.ToLower(parameters), ".csv") {
+		s, err = sweep.LoadCSV(parameters)
+	} else {
+		s, err = sweep.ParseGrid(parameters)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &SweepPlan{Request: req, Sweep: s}
+	switch req.Mode {
+	case sweep.ModeArray:
+		if job.WorkingDir == "" {
+			return nil, fmt.Errorf("a job array sweep needs a working directory for its mapping file")
+		}
+		plan.MappingFile = sweep.MappingFile(job.WorkingDir, req.Group)
+		array, mapping, err := s.Array(job, plan.MappingFile, req.MaxRunning)
+		if err != nil {
+			return nil, err
+		}
+		if err := ValidateJobSubmission(array); err != nil {
+			return nil, err
+		}
+		plan.Jobs = []*dao.JobSubmission{array}
+		plan.Mapping = mapping
+	default:
+		maxJobs, _ := sweepLimits(cfg)
+		if len(s.Points) > maxJobs {
+			return nil, fmt.Errorf("the sweep has %d jobs, more than the limit of %d; submit it as a job array", len(s.Points), maxJobs)
+		}
+		jobs, err := s.Jobs(job)
+		if err != nil {
+			return nil, err
+		}
+		for i, j := range jobs {
+			if err := ValidateJobSubmission(j); err != nil {
+				return nil, fmt.Errorf("job %d (%s): %w", i+1, s.Label(i), err)
+			}
+		}
+		plan.Jobs = jobs
+	}
+	return plan, nil
+}
+
+// sweepLimits returns the job limit and submission interval of sweeps
+func sweepLimits(cfg *config.JobSubmissionConfig) (int, time.Duration) {
+	maxJobs, interval := defaultSweepMaxJobs, defaultSweepSubmitInterval
+	if cfg == nil {
+		return maxJobs, interval
+	}
+	if cfg.Sweep.MaxJobs > 0 {
+		maxJobs = cfg.Sweep.MaxJobs
+	}
+	if d, err := time.ParseDuration(cfg.Sweep.SubmitInterval); err == nil && d >= 0 {
+		interval = d
+	}
+	return maxJobs, interval
+}
+
+// Preview describes the jobs of the plan
+func (p *SweepPlan) Preview() string {
+	var b strings.Builder
+	if p.Request.Mode == sweep.ModeArray {
+		array := p.Jobs[0]
+		fmt.Fprintf(&b, "[yellow]Job array[white] %s --array=%s, group %s\n", tview.Escape(array.Name), array.ArraySpec, p.Request.Group)
+		fmt.Fprintf(&b, "[yellow]Mapping file[white] %s\n\n", p.MappingFile)
+		b.WriteString(tview.Escape(string(p.Mapping)))
+		b.WriteString("\n[yellow]Script[white]\n")
+		b.WriteString(tview.Escape(JobScript(array)))
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "[yellow]%d jobs[white], group %s\n\n", len(p.Jobs), p.Request.Group)
+	for i, job := range p.Jobs {
+		if i == sweepPreviewRows {
+			fmt.Fprintf(&b, "... and %d more\n", len(p.Jobs)-i)
+			break
+		}
+		fmt.Fprintf(&b, "%4d  %-30s %s\n", i+1, tview.Escape(job.Name), tview.Escape(p.Sweep.Label(i)))
+	}
+	b.WriteString("\n[yellow]Script of job 1[white]\n")
+	b.WriteString(tview.Escape(JobScript(p.Jobs[0])))
+	return b.String()
+}
+
+// SetGroupStore sets the store sweeps are recorded in as job groups
+func (w *JobSubmissionWizard) SetGroupStore(groups *sweep.GroupStore) {
+	w.groups = groups
+}
+
+// SetContext sets the context that background submissions stop with
+func (w *JobSubmissionWizard) SetContext(ctx context.Context) {
+	if ctx != nil {
+		w.ctx = ctx
+	}
+}
+
+// showSweepBuilder asks how to sweep job: over which parameters, as
+// individual jobs or as a job array, and under which group name
+func (w *JobSubmissionWizard) showSweepBuilder(job *dao.JobSubmission) {
+	const page = "job-wizard-sweep"
+
+	req := SweepRequest{Group: defaultSweepGroup(job.Name), Mode: sweep.ModeJobs}
+	modes := []string{"Individual jobs", "Job array"}
+
+	form := styles.StyleForm(tview.NewForm())
+	form.SetBorder(true).
+		SetTitle(" Parameter Sweep ").
+		SetTitleAlign(tview.AlignCenter)
+
+	form.AddInputField("Group", req.Group, 40, nil, func(text string) {
+		req.Group = strings.TrimSpace(text)
+	})
+	parameters := tview.NewInputField().
+		SetLabel("Parameters").
+		SetFieldWidth(60).
+		SetPlaceholder("lr=1e-3,1e-4 seed=1..5  or  points.csv")
+	parameters.SetChangedFunc(func(text string) {
+		req.Parameters = text
+	})
+	form.AddFormItem(parameters)
+	form.AddDropDown("Submit as", modes, 0, func(_ string, index int) {
+		req.Mode = sweep.ModeJobs
+		if index == 1 {
+			req.Mode = sweep.ModeArray
+		}
+	})
+	form.AddInputField("Max running tasks", "", 8, tview.InputFieldInteger, func(text string) {
+		req.MaxRunning, _ = strconv.Atoi(text)
+	})
+
+	closeBuilder := func() {
+		w.pages.RemovePage(page)
+		w.app.SetFocus(w.form)
+	}
+	plan := func() *SweepPlan {
+		p, err := PlanSweep(w.submissionConfig, w.groups, job, req)
+		if err != nil {
+			w.showError(err.Error())
+			return nil
+		}
+		return p
+	}
+	form.AddButton("Preview", func() {
+		if p := plan(); p != nil {
+			w.showSweepPreview(p)
+		}
+	})
+	form.AddButton("Submit", func() {
+		if p := plan(); p != nil {
+			w.pages.RemovePage(page)
+			w.submitSweep(p)
+		}
+	})
+	form.AddButton("Cancel", closeBuilder)
+
+	form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEsc {
+			closeBuilder()
+			return nil
+		}
+		return event
+	})
+
+	w.pages.AddPage(page, createCenteredModal(form, 80, 13), true, true)
+}
+
+// defaultSweepGroup suggests a group name from the job name, up to the
+// first sweep parameter in it
+func defaultSweepGroup(jobName string) string {
+	jobName, _, _ = strings.Cut(jobName, "{{")
+	name := strings.Map(func(r rune) rune {
+		if r == '.' || r == '-' || r == '_' || (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') {
+			return r
+		}
+		return '-'
+	}, jobName)
+	name = strings.Trim(name, ".-_")
+	if name == "" {
+		return "sweep"
+	}
+	return name + "-sweep"
+}
+
+// showSweepPreview shows the jobs a sweep will submit
+func (w *JobSubmissionWizard) showSweepPreview(plan *SweepPlan) {
+	const page = "job-wizard-sweep-preview"
+
+	textView := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetText(plan.Preview())
+	textView.SetBorder(true).
+		SetTitle(" Sweep Preview (ESC to close) ").
+		SetTitleAlign(tview.AlignCenter)
+	textView.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEsc {
+			w.pages.RemovePage(page)
+			return nil
+		}
+		return event
+	})
+
+	w.pages.AddPage(page, createCenteredModal(textView, 90, 30), true, true)
+}
+
+// submitSweep records the group of a sweep and submits its jobs in the
+// background, showing the progress. Esc stops the submission; jobs already
+// submitted stay in the group.
+func (w *JobSubmissionWizard) submitSweep(plan *SweepPlan) {
+	group := plan.Request.Group
+	if plan.MappingFile != "" {
+		if err := os.WriteFile(plan.MappingFile, plan.Mapping, fileperms.FileDefault); err != nil {
+			w.showError(fmt.Sprintf("Failed to write the mapping file: %v", err))
+			return
+		}
+	}
+	if w.groups != nil {
+		err := w.groups.Create(sweep.Group{
+			Name:        group,
+			Mode:        plan.Request.Mode,
+			Variables:   plan.Sweep.Names,
+			MappingFile: plan.MappingFile,
+		})
+		if err != nil {
+			w.showError(err.Error())
+			return
+		}
+	}
+
+	_, interval := sweepLimits(w.submissionConfig)
+	loading := components.NewLoadingWrapper(components.NewLoadingManager(w.app, w.pages), "job_sweep")
+	total := len(plan.Jobs)
+
+	var ids []string
+	loading.WithCancellableLoadingAsync(w.ctx, fmt.Sprintf("Submitting %s: 0/%d jobs...", group, total), func(ctx context.Context) error {
+		var err error
+		ids, err = sweep.Submit(ctx, w.client.Jobs(), plan.Jobs, interval, func(done, total int, id string, err error) {
+			if err == nil && w.groups != nil {
+				if addErr := w.groups.AddJobs(group, id); addErr != nil {
+					debug.Logger.Printf("Failed to add job %s to group %s: %v", id, group, addErr)
+				}
+			}
+			loading.UpdateMessage(fmt.Sprintf("Submitting %s: %d/%d jobs...", group, done, total))
+		})
+		return err
+	}, func(err error) {
+		w.app.QueueUpdateDraw(func() {
+			switch {
+			case errors.Is(err, context.Canceled):
+				w.showError(fmt.Sprintf("Cancelled after submitting %d of %d jobs of group %s", len(ids), total, group))
+			case err != nil:
+				w.showError(fmt.Sprintf("Submitted %d of %d jobs of group %s, then failed: %v", len(ids), total, group, err))
+			default:
+				message := fmt.Sprintf("Submitted %d jobs as group %s", total, group)
+				if plan.Request.Mode == sweep.ModeArray {
+					message = fmt.Sprintf("Submitted job array %s as group %s", ids[0], group)
+				}
+				w.showSuccess(message + fmt.Sprintf("\n\nFollow them with :group %s", group))
+			}
+			if len(ids) > 0 {
+				w.pages.RemovePage("job-wizard-form")
+				if w.onSubmit != nil {
+					w.onSubmit(ids[0])
+				}
+			}
+		})
+	})
+}
@@ -0,0 +1,93 @@
+package views
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/jontk/s9s/internal/config"
+	"github.com/jontk/s9s/internal/dao"
+	"github.com/jontk/s9s/internal/sweep"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sweepJob(dir string) *dao.JobSubmission {
+	return &dao.JobSubmission{
+		Name:       "train-{{ .lr }}",
+		Partition:  "gpu",
+		TimeLimit:  "01:00:00",
+		WorkingDir: dir,
+		Script:     "#!/bin/bash\npython train.py --lr $lr\n",
+	}
+}
+
+func TestPlanSweep(t *testing.T) {
+	dir := t.TempDir()
+	groups := sweep.NewGroupStore(filepath.Join(dir, "groups.json"))
+	require.NoError(t, groups.Create(sweep.Group{Name: "taken"}))
+	cfg := &config.JobSubmissionConfig{Sweep: config.SweepConfig{MaxJobs: 3}}
+
+	plan, err := PlanSweep(cfg, groups, sweepJob(dir), SweepRequest{Group: "lr", Parameters: "lr=0.1,0.01", Mode: sweep.ModeJobs})
+	require.NoError(t, err)
+	require.Len(t, plan.Jobs, 2)
+	assert.Equal(t, "train-0.01", plan.Jobs[1].Name)
+	assert.Contains(t, plan.Preview(), "train-0.01")
+
+	plan, err = PlanSweep(cfg, groups, sweepJob(dir), SweepRequest{Group: "lr", Parameters: "lr=1..10", Mode: sweep.ModeJobs})
+	assert.EqualError(t, err, "the sweep has 10 jobs, more than the limit of 3; submit it as a job array")
+	assert.Nil(t, plan)
+
+	// An array is not limited, but only its script can use the parameters
+	job := sweepJob(dir)
+	_, err = PlanSweep(cfg, groups, job, SweepRequest{Group: "lr", Parameters: "lr=1..10", Mode: sweep.ModeArray})
+	assert.ErrorContains(t, err, "Name uses sweep parameters")
+	job.Name = "train"
+	plan, err = PlanSweep(cfg, groups, job, SweepRequest{Group: "lr", Parameters: "lr=1..10", Mode: sweep.ModeArray, MaxRunning: 2})
+	require.NoError(t, err)
+	require.Len(t, plan.Jobs, 1)
+	assert.Equal(t, "0-9%2", plan.Jobs[0].ArraySpec)
+	assert.Equal(t, filepath.Join(dir, "lr.sweep.tsv"), plan.MappingFile)
+
+	_, err = PlanSweep(cfg, groups, sweepJob(dir), SweepRequest{Group: "taken", Parameters: "lr=1", Mode: sweep.ModeJobs})
+	assert.EqualError(t, err, "group taken already exists")
+	_, err = PlanSweep(cfg, groups, sweepJob(dir), SweepRequest{Group: "lr", Parameters: filepath.Join(dir, "missing.csv"), Mode: sweep.ModeJobs})
+	assert.ErrorContains(t, err, "missing.csv")
+
+	job = sweepJob(dir)
+	job.TimeLimit = "{{ .lr }}"
+	_, err = PlanSweep(cfg, groups, job, SweepRequest{Group: "lr", Parameters: "lr=01:00:00,soon", Mode: sweep.ModeJobs})
+	assert.EqualError(t, err, "job 2 (lr=soon): invalid time format (use HH:MM:SS or D-HH:MM:SS)")
+}
+
+func TestDefaultSweepGroup(t *testing.T) {
+	assert.Equal(t, "train-sweep", defaultSweepGroup("train-{{ .lr }}"))
+	assert.Equal(t, "my-job-sweep", defaultSweepGroup("my job"))
+	assert.Equal(t, "sweep", defaultSweepGroup("{{ .model }}"))
+}
+
+func TestJobsViewGroups(t *testing.T) {
+	groups := sweep.NewGroupStore(filepath.Join(t.TempDir(), "groups.json"))
+	require.NoError(t, groups.Create(sweep.Group{Name: "lr", JobIDs: []string{"101", "102"}}))
+	require.NoError(t, groups.Create(sweep.Group{Name: "ablation", Mode: sweep.ModeArray, JobIDs: []string{"200"}}))
+
+	v := NewJobsView(nil)
+	v.SetGroupStore(groups)
+	jobs := []*dao.Job{
+		{ID: "101"},
+		{ID: "102"},
+		{ID: "200_3", ArrayJobID: "200", ArrayTaskID: "3"},
+		{ID: "300"},
+	}
+	assert.Equal(t, "lr", v.jobToMap(jobs[0])["Group"])
+	assert.Equal(t, "ablation", v.jobToMap(jobs[2])["Group"], "array tasks belong to the group of their array")
+	assert.Equal(t, "", v.jobToMap(jobs[3])["Group"])
+
+	v.stateFilter = []string{dao.JobStateRunning}
+	require.NoError(t, v.ShowGroup("lr"))
+	assert.Empty(t, v.stateFilter, "finished jobs of the group stay listed")
+	filtered := v.applyAdvancedFilter(jobs)
+	require.Len(t, filtered, 2)
+	assert.Equal(t, "102", filtered[1].ID)
+
+	assert.EqualError(t, v.ShowGroup("nope"), "group nope not found")
+}
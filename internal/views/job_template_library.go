@@ -0,0 +1,122 @@
+package views
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jontk/s9s/internal/config"
+	"github.com/jontk/s9s/internal/dao"
+	"github.com/jontk/s9s/internal/jobtemplate"
+)
+
+// LoadTemplateLibrary returns the job templates of the template sources
+// of cfg. A source replaces the templates of the same name of the sources
+// before it, so with the default sources saved templates replace config
+// templates, which replace built-in ones. Templates that cannot be read
+// are left out and reported in the returned error.
+func LoadTemplateLibrary(cfg *config.JobSubmissionConfig) ([]*jobtemplate.Template, error) {
+	seen := make(map[string]int) // name -> index in result
+	var result []*jobtemplate.Template
+	add := func(t *jobtemplate.Template) {
+		if idx, ok := seen[t.Name]; ok {
+			result[idx] = t
+		} else {
+			seen[t.Name] = len(result)
+			result = append(result, t)
+		}
+	}
+
+	var errs []error
+	for _, source := range config.ResolveTemplateSources(cfg) {
+		switch source {
+		case "builtin":
+			for _, t := range BuiltinTemplates() {
+				job := t.JobSubmission
+				add(&jobtemplate.Template{
+					JobTemplateConfig: config.JobTemplateConfig{Name: t.Name, Description: t.Description},
+					Source:            source,
+					Job:               &job,
+				})
+			}
+		case "config":
+			if cfg == nil {
+				continue
+			}
+			for _, ct := range cfg.Templates {
+				t := jobtemplate.FromConfig(ct)
+				if err := t.Check(); err != nil {
+					errs = append(errs, fmt.Errorf("template %s: %w", ct.Name, err))
+					continue
+				}
+				add(t)
+			}
+		case "saved":
+			for _, saved := range NewJobTemplateManager().GetTemplates() {
+				t := &jobtemplate.Template{
+					JobTemplateConfig: config.JobTemplateConfig{Name: saved.Name, Description: saved.Description},
+					Source:            source,
+					Job:               &dao.JobSubmission{},
+				}
+				if saved.JobSubmission != nil {
+					t.Job = saved.JobSubmission
+				}
+				add(t)
+			}
+		default:
+			templates, err := jobtemplate.LoadDir(source)
+			if err != nil {
+				errs = append(errs, err)
+			}
+			for _, t := range templates {
+				add(t)
+			}
+		}
+	}
+	return result, errors.Join(errs...)
+}
+
+// FindTemplate returns the template of the library with the given name
+func FindTemplate(templates []*jobtemplate.Template, name string) (*jobtemplate.Template, error) {
+	for _, t := range templates {
+		if t.Name == name {
+			return t, nil
+		}
+	}
+	return nil, fmt.Errorf("template %q not found", name)
+}
+
+// TemplateSubmission returns the job submission of template t with the
+// parameter values of set
+func TemplateSubmission(t *jobtemplate.Template, set map[string]string) (dao.JobSubmission, error) {
+	if t.Job != nil {
+		if _, err := t.Values(set); err != nil {
+			return dao.JobSubmission{}, err
+		}
+		return *t.Job, nil
+	}
+	defaults, err := t.Render(set)
+	if err != nil {
+		return dao.JobSubmission{}, err
+	}
+	vals := config.JobSubmissionFromMap(defaults)
+	return ConfigValuesToJobSubmission(&vals), nil
+}
+
+// TemplateJob returns the job the submission form shows for template t
+// with the parameter values of set: the template submission over the form
+// defaults of cfg
+func TemplateJob(cfg *config.JobSubmissionConfig, t *jobtemplate.Template, set map[string]string) (*dao.JobSubmission, error) {
+	templateJob, err := TemplateSubmission(t, set)
+	if err != nil {
+		return nil, err
+	}
+	job := newFormJob(cfg)
+	overlayJobDefaults(job, &templateJob)
+	return job, nil
+}
+
+// JobScript returns the batch script of job, with its options as #SBATCH
+// directives, as the submission form previews it
+func JobScript(job *dao.JobSubmission) string {
+	return generateCleanJobScript(job)
+}
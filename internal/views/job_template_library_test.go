@@ -0,0 +1,65 @@
+package views
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jontk/s9s/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadTemplateLibrary(t *testing.T) {
+	catalog := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(catalog, "gpu.yaml"), []byte(`
+name: GPU Job
+version: "2"
+parameters:
+  - name: gpus
+    type: int
+    default: 2
+defaults:
+  partition: gpu
+  gpus: "{{ .gpus }}"
+  script: "#!/bin/bash\nnvidia-smi -L  # {{ .gpus }} GPUs\n"
+`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(catalog, "bad.yaml"), []byte("name: [\n"), 0o600))
+
+	cfg := &config.JobSubmissionConfig{
+		TemplateSources: []string{"builtin", catalog, "config"},
+		Templates: []config.JobTemplateConfig{
+			{Name: "Array Job", Description: "Site array job", Defaults: map[string]any{"arraySpec": "1-10"}},
+		},
+	}
+	templates, err := LoadTemplateLibrary(cfg)
+	assert.ErrorContains(t, err, "bad.yaml")
+	require.Len(t, templates, len(BuiltinTemplates()))
+
+	// Later sources replace templates of the same name, in place
+	gpu, err := FindTemplate(templates, "GPU Job")
+	require.NoError(t, err)
+	assert.Equal(t, catalog, gpu.Source)
+	assert.Equal(t, "2", gpu.Version)
+	array, err := FindTemplate(templates, "Array Job")
+	require.NoError(t, err)
+	assert.Equal(t, "config", array.Source)
+	basic, err := FindTemplate(templates, "Basic Batch Job")
+	require.NoError(t, err)
+	assert.Equal(t, "builtin", basic.Source)
+
+	job, err := TemplateSubmission(gpu, map[string]string{"gpus": "4"})
+	require.NoError(t, err)
+	assert.Equal(t, 4, job.GPUs)
+	assert.Equal(t, "gpu", job.Partition)
+	assert.Contains(t, JobScript(&job), "nvidia-smi -L  # 4 GPUs")
+
+	_, err = TemplateSubmission(basic, map[string]string{"gpus": "4"})
+	assert.EqualError(t, err, `template Basic Batch Job has no parameter "gpus"`)
+	job, err = TemplateSubmission(array, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "1-10", job.ArraySpec)
+
+	_, err = FindTemplate(templates, "nope")
+	assert.EqualError(t, err, `template "nope" not found`)
+}
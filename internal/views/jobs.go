@@ -5,14 +5,17 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gdamore/tcell/v2"
+	"github.com/jontk/s9s/internal/annotations"
 	"github.com/jontk/s9s/internal/config"
 	"github.com/jontk/s9s/internal/dao"
 	"github.com/jontk/s9s/internal/debug"
 	"github.com/jontk/s9s/internal/export"
 	"github.com/jontk/s9s/internal/streaming"
+	"github.com/jontk/s9s/internal/sweep"
 	"github.com/jontk/s9s/internal/ui/components"
 	"github.com/jontk/s9s/internal/ui/filters"
 	"github.com/jontk/s9s/internal/ui/styles"
@@ -25,6 +28,10 @@ type JobsView struct {
 	client              dao.SlurmClient
 	table               *components.MultiSelectTable
 	jobs                []*dao.Job
+	totalJobs           int                  // Jobs matching fetchedOpts; more than len(jobs) while pages remain
+	fetchedOpts         *dao.ListJobsOptions // Server-side filter jobs were fetched with
+	loadingMore         atomic.Bool
+	samplingProgress    atomic.Bool // Output tails are being scanned for progress
 	mu                  sync.RWMutex
 	filter              string
 	stateFilter         []string
@@ -37,6 +44,8 @@ type JobsView struct {
 	app                 *tview.Application
 	pages               *tview.Pages
 	selectedJobs        map[string]bool
+	collapseArrays      bool            // One row per job array instead of per task
+	expandedArrays      map[string]bool // Arrays showing their tasks below the summary row
 	filterBar           *components.FilterBar
 	advancedFilter      *filters.Filter
 	isAdvancedMode      bool
@@ -45,11 +54,15 @@ type JobsView struct {
 	batchOpsView        *BatchOperationsView
 	multiSelectMode     bool
 	selectionStatusText *tview.TextView
+	selectionStatus     string
 	mainStatusBar       *components.StatusBar // Reference to main app status bar
 	submissionConfig    *config.JobSubmissionConfig
 	viewConfig          *config.JobsViewConfig
 	slurmUser           string
 	streamMgr           *streaming.StreamManager
+	groups              *sweep.GroupStore  // Job groups, such as parameter sweeps
+	annotations         *annotations.Store // Notes, labels and bookmarks of jobs
+	cluster             string             // Cluster annotations are kept for
 }
 
 // SetSubmissionConfig sets the job submission configuration
@@ -57,12 +70,18 @@ func (v *JobsView) SetSubmissionConfig(cfg *config.JobSubmissionConfig) {
 	v.submissionConfig = cfg
 }
 
+// SetGroupStore sets the store of job groups shown and filtered by
+func (v *JobsView) SetGroupStore(groups *sweep.GroupStore) {
+	v.groups = groups
+}
+
 // SetViewConfig sets the jobs view configuration from config file
 func (v *JobsView) SetViewConfig(cfg *config.JobsViewConfig) {
 	v.viewConfig = cfg
 	if cfg != nil && cfg.ShowOnlyActive {
 		v.stateFilter = []string{dao.JobStateRunning, dao.JobStatePending}
 	}
+	v.applyProgressConfig()
 }
 
 // SetSlurmUser sets the resolved SLURM username for the job submission wizard
@@ -76,6 +95,55 @@ func (v *JobsView) SetStreamManager(sm *streaming.StreamManager) {
 	if v.jobOutputView != nil {
 		v.jobOutputView.SetStreamManager(sm)
 	}
+	v.applyProgressConfig()
+}
+
+// applyProgressConfig configures the progress extractors of the stream manager
+func (v *JobsView) applyProgressConfig() {
+	if v.streamMgr == nil || v.viewConfig == nil {
+		return
+	}
+	cfg := v.viewConfig.Progress
+	if len(cfg.Presets) == 0 && len(cfg.Patterns) == 0 {
+		return
+	}
+
+	var extractors []streaming.ProgressExtractor
+	for _, pattern := range cfg.Patterns {
+		extractor, err := streaming.NewRegexProgressExtractor(pattern.Name, pattern.Pattern)
+		if err != nil {
+			debug.Logger.Printf("Skipping progress pattern: %v", err)
+			continue
+		}
+		extractors = append(extractors, extractor)
+	}
+
+	presets := cfg.Presets
+	if len(presets) == 0 {
+		presets = streaming.ProgressPresetNames()
+	}
+	for _, name := range presets {
+		if name == "none" {
+			break
+		}
+		extractor, err := streaming.ProgressPreset(name)
+		if err != nil {
+			debug.Logger.Printf("Skipping progress preset: %v", err)
+			continue
+		}
+		extractors = append(extractors, extractor)
+	}
+
+	v.streamMgr.Progress().SetExtractors(extractors)
+}
+
+// progressSamplingEnabled reports whether output tails of running jobs are
+// scanned for progress after each refresh
+func (v *JobsView) progressSamplingEnabled() bool {
+	if v.streamMgr == nil {
+		return false
+	}
+	return v.viewConfig == nil || v.viewConfig.Progress.SampleOutput == nil || *v.viewConfig.Progress.SampleOutput
 }
 
 // SetPages sets the pages reference for modal handling
@@ -131,10 +199,12 @@ func (v *JobsView) SetStatusBar(statusBar *components.StatusBar) {
 // NewJobsView creates a new jobs view
 func NewJobsView(client dao.SlurmClient) *JobsView {
 	v := &JobsView{
-		BaseView:     NewBaseView("jobs", "Jobs"),
-		client:       client,
-		jobs:         []*dao.Job{},
-		selectedJobs: make(map[string]bool),
+		BaseView:       NewBaseView("jobs", "Jobs"),
+		client:         client,
+		jobs:           []*dao.Job{},
+		selectedJobs:   make(map[string]bool),
+		collapseArrays: true,
+		expandedArrays: make(map[string]bool),
 	}
 
 	// Create table with job columns
@@ -143,13 +213,18 @@ func NewJobsView(client dao.SlurmClient) *JobsView {
 		components.NewColumn("Name").Width(20).Build(),
 		components.NewColumn("User").Width(10).Build(),
 		components.NewColumn("Account").Width(12).Build(),
-		components.NewColumn("State").Width(12).Sortable(true).Build(),
+		components.NewColumn("State").Width(32).Sortable(true).Build(), // Wide enough for array task counts
 		components.NewColumn("Partition").Width(10).Build(),
 		components.NewColumn("Nodes").Width(8).Align(tview.AlignRight).Build(),
 		components.NewColumn("Time").Width(10).Align(tview.AlignRight).Build(),
 		components.NewColumn("Time Limit").Width(10).Align(tview.AlignRight).Build(),
 		components.NewColumn("Priority").Width(8).Align(tview.AlignRight).Sortable(true).Build(),
 		components.NewColumn("Submit Time").Width(19).Sortable(true).Build(),
+		components.NewColumn("Progress").Width(16).Build(),
+		components.NewColumn("Efficiency").Width(16).Build(),
+		components.NewColumn("★").Width(1).Build(),
+		components.NewColumn("Labels").Width(16).Build(),
+		components.NewColumn("Note").Width(30).Build(),
 	}
 
 	// Create multi-select table
@@ -168,6 +243,7 @@ func NewJobsView(client dao.SlurmClient) *JobsView {
 	v.table.SetOnSort(v.onSort)
 	v.table.SetOnSelectionChange(v.onSelectionChange)
 	v.table.SetOnRowToggle(v.onRowToggle)
+	v.table.SetOnNeedMore(jobsLoadAhead, v.loadMoreJobs)
 
 	// Create filter input with styled colors for visibility across themes
 	v.filterInput = styles.NewStyledInputField().
@@ -184,8 +260,8 @@ func NewJobsView(client dao.SlurmClient) *JobsView {
 	// Create selection status text
 	v.selectionStatusText = tview.NewTextView().
 		SetDynamicColors(true).
-		SetTextAlign(tview.AlignRight).
-		SetText("[gray]Multi-select: Off[white]")
+		SetTextAlign(tview.AlignRight)
+	v.setSelectionStatus("[gray]Multi-select: Off[white]")
 
 	// Create info bar with filter and selection status
 	infoBar := tview.NewFlex().
@@ -230,7 +306,7 @@ func (v *JobsView) Refresh() error {
 	go func() {
 		defer v.refreshing.Store(false)
 
-		jobList, err := v.fetchJobs()
+		jobList, opts, err := v.fetchJobs()
 		if err != nil {
 			v.SetLastError(err)
 			return
@@ -238,37 +314,63 @@ func (v *JobsView) Refresh() error {
 
 		if v.app != nil {
 			v.app.QueueUpdateDraw(func() {
-				v.mu.Lock()
-				v.jobs = jobList.Jobs
-				v.mu.Unlock()
+				v.setJobs(jobList, opts)
 				v.updateTable()
 			})
 		}
+		v.sampleProgress(jobList.Jobs)
 	}()
 
 	return nil
 }
 
-// fetchJobs fetches jobs from the backend without touching UI
-func (v *JobsView) fetchJobs() (*dao.JobList, error) {
-	limit := 1000
-	if v.viewConfig != nil && v.viewConfig.MaxJobs > 0 {
-		limit = v.viewConfig.MaxJobs
+// sampleProgress scans the output of running jobs for progress in the
+// background, so refreshes do not wait on file reads, and redraws the
+// table with the new readings. A scan still running is not duplicated.
+func (v *JobsView) sampleProgress(jobs []*dao.Job) {
+	if !v.progressSamplingEnabled() || !v.samplingProgress.CompareAndSwap(false, true) {
+		return
 	}
-	opts := &dao.ListJobsOptions{
-		States: v.stateFilter,
-		Limit:  limit,
+
+	go func() {
+		defer v.samplingProgress.Store(false)
+		v.streamMgr.SampleProgress(jobs)
+		if v.app != nil {
+			v.app.QueueUpdateDraw(v.updateTable)
+		}
+	}()
+}
+
+// fetchJobs fetches jobs from the backend without touching UI. The quick
+// and advanced filters are pushed down to the backend; a refresh reloads
+// as many rows as are loaded, so scrolled-in pages are kept.
+func (v *JobsView) fetchJobs() (*dao.JobList, *dao.ListJobsOptions, error) {
+	opts, ok := v.listOptions()
+	if !ok {
+		return &dao.JobList{Jobs: []*dao.Job{}}, nil, nil
 	}
 
-	if v.userFilter != "" {
-		opts.Users = []string{v.userFilter}
+	opts.Limit = v.pageSize()
+	v.mu.RLock()
+	if sameListOptions(opts, v.fetchedOpts) {
+		opts.Limit = max(opts.Limit, len(v.jobs))
 	}
+	v.mu.RUnlock()
 
-	if v.partFilter != "" {
-		opts.Partitions = []string{v.partFilter}
+	jobList, err := v.client.Jobs().List(opts)
+	if err != nil {
+		return nil, nil, err
 	}
+	return jobList, opts, nil
+}
 
-	return v.client.Jobs().List(opts)
+// setJobs replaces the loaded jobs with a list fetched with opts
+func (v *JobsView) setJobs(jobList *dao.JobList, opts *dao.ListJobsOptions) {
+	v.mu.Lock()
+	v.jobs = jobList.Jobs
+	v.totalJobs = max(jobList.Total, len(jobList.Jobs))
+	v.fetchedOpts = opts
+	v.mu.Unlock()
 }
 
 // Stop stops the view
@@ -289,6 +391,11 @@ func (v *JobsView) Hints() []string {
 		"[yellow]x[white] Actions",
 		"[yellow]b[white] Batch Ops",
 		"[yellow]v[white] Multi-Select",
+		"[yellow]W[white] Waste",
+		"[yellow]z[white] Arrays",
+		"[yellow]F[white] Requeue Failed",
+		"[yellow]n[white] Note",
+		"[yellow]m[white] Bookmark",
 	}
 
 	if v.isAdvancedMode {
@@ -359,7 +466,12 @@ func (v *JobsView) handleJobsViewRune(event *tcell.EventKey) *tcell.EventKey {
 func (v *JobsView) jobsKeyHandlers() map[tcell.Key]func(*JobsView, *tcell.EventKey) *tcell.EventKey {
 	return map[tcell.Key]func(*JobsView, *tcell.EventKey) *tcell.EventKey{
 		tcell.KeyCtrlF: func(v *JobsView, _ *tcell.EventKey) *tcell.EventKey { v.showGlobalSearch(); return nil },
-		tcell.KeyEnter: func(v *JobsView, _ *tcell.EventKey) *tcell.EventKey { v.showJobDetails(); return nil },
+		tcell.KeyEnter: func(v *JobsView, _ *tcell.EventKey) *tcell.EventKey {
+			if !v.toggleSelectedArray() {
+				v.showJobDetails()
+			}
+			return nil
+		},
 		tcell.KeyCtrlA: func(v *JobsView, _ *tcell.EventKey) *tcell.EventKey { v.selectAllJobs(); return nil },
 	}
 }
@@ -368,10 +480,22 @@ func (v *JobsView) jobsKeyHandlers() map[tcell.Key]func(*JobsView, *tcell.EventK
 func (v *JobsView) jobsRuneHandlers() map[rune]func(*JobsView, *tcell.EventKey) *tcell.EventKey {
 	return map[rune]func(*JobsView, *tcell.EventKey) *tcell.EventKey{
 		' ': func(v *JobsView, _ *tcell.EventKey) *tcell.EventKey { v.toggleRowSelection(); return nil },
-		'c': func(v *JobsView, _ *tcell.EventKey) *tcell.EventKey { v.cancelSelectedJob(); return nil },
-		'C': func(v *JobsView, _ *tcell.EventKey) *tcell.EventKey { v.cancelSelectedJob(); return nil },
-		'H': func(v *JobsView, _ *tcell.EventKey) *tcell.EventKey { v.holdSelectedJob(); return nil },
-		'r': func(v *JobsView, _ *tcell.EventKey) *tcell.EventKey { v.releaseSelectedJob(); return nil },
+		'c': func(v *JobsView, _ *tcell.EventKey) *tcell.EventKey {
+			v.actOnSelection(arrayCancel, v.cancelSelectedJob)
+			return nil
+		},
+		'C': func(v *JobsView, _ *tcell.EventKey) *tcell.EventKey {
+			v.actOnSelection(arrayCancel, v.cancelSelectedJob)
+			return nil
+		},
+		'H': func(v *JobsView, _ *tcell.EventKey) *tcell.EventKey {
+			v.actOnSelection(arrayHold, v.holdSelectedJob)
+			return nil
+		},
+		'r': func(v *JobsView, _ *tcell.EventKey) *tcell.EventKey {
+			v.actOnSelection(arrayRelease, v.releaseSelectedJob)
+			return nil
+		},
 		'R': func(v *JobsView, _ *tcell.EventKey) *tcell.EventKey { go func() { _ = v.Refresh() }(); return nil },
 		'o': func(v *JobsView, _ *tcell.EventKey) *tcell.EventKey { v.showJobOutput(); return nil },
 		'O': func(v *JobsView, _ *tcell.EventKey) *tcell.EventKey { v.showJobOutput(); return nil },
@@ -400,7 +524,15 @@ func (v *JobsView) jobsRuneHandlers() map[rune]func(*JobsView, *tcell.EventKey)
 		'V': func(v *JobsView, _ *tcell.EventKey) *tcell.EventKey { v.toggleMultiSelectMode(); return nil },
 		'e': func(v *JobsView, _ *tcell.EventKey) *tcell.EventKey { v.showExportDialog(); return nil },
 		'E': func(v *JobsView, _ *tcell.EventKey) *tcell.EventKey { v.showExportDialog(); return nil },
+		'W': func(v *JobsView, _ *tcell.EventKey) *tcell.EventKey { v.showWasteSummary(); return nil },
 		'f': func(v *JobsView, _ *tcell.EventKey) *tcell.EventKey { v.showAdvancedFilter(); return nil },
+		'z': func(v *JobsView, _ *tcell.EventKey) *tcell.EventKey { v.toggleArrayCollapse(); return nil },
+		'Z': func(v *JobsView, _ *tcell.EventKey) *tcell.EventKey { v.toggleArrayCollapse(); return nil },
+		'F': func(v *JobsView, _ *tcell.EventKey) *tcell.EventKey { v.requeueFailedTasks(); return nil },
+		'n': func(v *JobsView, _ *tcell.EventKey) *tcell.EventKey { v.showAnnotationEditor(); return nil },
+		'N': func(v *JobsView, _ *tcell.EventKey) *tcell.EventKey { v.showAnnotationEditor(); return nil },
+		'm': func(v *JobsView, _ *tcell.EventKey) *tcell.EventKey { v.toggleSelectedBookmark(); return nil },
+		'M': func(v *JobsView, _ *tcell.EventKey) *tcell.EventKey { v.toggleSelectedBookmark(); return nil },
 		'x': func(v *JobsView, _ *tcell.EventKey) *tcell.EventKey { v.showJobActions(); return nil },
 		'X': func(v *JobsView, _ *tcell.EventKey) *tcell.EventKey { v.showJobActions(); return nil },
 	}
@@ -438,9 +570,7 @@ func (v *JobsView) onSelectionChange(selectedCount int, allSelected bool) {
 		default:
 			statusText = fmt.Sprintf("[green]Multi-select: On[white] | [yellow]%d selected[white]", selectedCount)
 		}
-		if v.selectionStatusText != nil {
-			v.selectionStatusText.SetText(statusText)
-		}
+		v.setSelectionStatus(statusText)
 	}
 
 	// Sync with legacy selectedJobs map for compatibility with existing batch operations
@@ -477,9 +607,9 @@ func (v *JobsView) toggleMultiSelectMode() {
 	v.table.SetMultiSelectMode(v.multiSelectMode)
 
 	if v.multiSelectMode {
-		v.selectionStatusText.SetText("[green]Multi-select: On[white] | [gray]0 selected[white]")
+		v.setSelectionStatus("[green]Multi-select: On[white] | [gray]0 selected[white]")
 	} else {
-		v.selectionStatusText.SetText("[gray]Multi-select: Off[white]")
+		v.setSelectionStatus("[gray]Multi-select: Off[white]")
 		v.selectedJobs = make(map[string]bool) // Clear selections when disabling
 	}
 }
@@ -506,35 +636,41 @@ func (v *JobsView) updateTable() {
 		filteredJobs = v.applyAdvancedFilter(filteredJobs)
 	}
 
-	data := make([][]string, len(filteredJobs))
-	for i, job := range filteredJobs {
-		stateColor := dao.GetJobStateColor(job.State)
-		coloredState := fmt.Sprintf("[%s]%s[white]", stateColor, job.State)
+	data := v.buildJobRows(filteredJobs, time.Now())
 
-		timeUsed := job.TimeUsed
-		if timeUsed == "" && job.StartTime != nil && job.State == dao.JobStateRunning {
-			timeUsed = FormatDurationDetailed(time.Since(*job.StartTime))
-		}
+	v.table.SetData(data)
+	v.renderSelectionStatus(v.loadStatusLocked())
+}
 
-		priority := fmt.Sprintf("%.0f", job.Priority)
-		submitTime := job.SubmitTime.Format("2006-01-02 15:04:05")
-
-		data[i] = []string{
-			job.ID,
-			job.Name,
-			job.User,
-			job.Account,
-			coloredState,
-			job.Partition,
-			fmt.Sprintf("%d", job.NodeCount),
-			timeUsed,
-			job.TimeLimit,
-			priority,
-			submitTime,
-		}
+// jobRow renders a job as a table row
+func (v *JobsView) jobRow(job *dao.Job, now time.Time) []string {
+	stateColor := dao.GetJobStateColor(job.State)
+	coloredState := fmt.Sprintf("[%s]%s[white]", stateColor, job.State)
+
+	timeUsed := job.TimeUsed
+	if timeUsed == "" && job.StartTime != nil && job.State == dao.JobStateRunning {
+		timeUsed = FormatDurationDetailed(time.Since(*job.StartTime))
 	}
 
-	v.table.SetData(data)
+	priority := fmt.Sprintf("%.0f", job.Priority)
+	submitTime := job.SubmitTime.Format("2006-01-02 15:04:05")
+
+	row := []string{
+		job.ID,
+		job.Name,
+		job.User,
+		job.Account,
+		coloredState,
+		job.Partition,
+		fmt.Sprintf("%d", job.NodeCount),
+		timeUsed,
+		job.TimeLimit,
+		priority,
+		submitTime,
+		v.formatProgressCell(job),
+		formatEfficiencyCell(job, now),
+	}
+	return append(row, annotationCells(v.jobAnnotation(job))...)
 }
 
 // onJobSelect handles job selection
@@ -952,7 +1088,9 @@ func (v *JobsView) formatJobDetails(job *dao.Job) string {
 	writeDetailField(&d, "QoS", job.QOS)
 	writeDetailField(&d, "Priority", fmt.Sprintf("%.0f", job.Priority))
 	writeDetailField(&d, "Cluster", job.Cluster)
+	writeDetailField(&d, "Group", v.jobGroup(job))
 	d.WriteString("\n")
+	v.writeAnnotationDetails(&d, job)
 
 	// Scheduling
 	d.WriteString("[teal]Scheduling[white]\n")
@@ -975,6 +1113,9 @@ func (v *JobsView) formatJobDetails(job *dao.Job) string {
 	}
 	d.WriteString("\n")
 
+	v.writeProgressDetails(&d, job)
+	writeEfficiencyDetails(&d, job)
+
 	// Resources
 	d.WriteString("[teal]Resources[white]\n")
 	writeDetailIndented(&d, "Nodes", fmt.Sprintf("%d", job.NodeCount))
@@ -1211,6 +1352,8 @@ func (v *JobsView) performRequeueJob(jobID string) {
 // showJobSubmissionForm shows job submission form using the wizard
 func (v *JobsView) showJobSubmissionForm() {
 	wizard := NewJobSubmissionWizard(v.client, v.app, v.submissionConfig, v.slurmUser)
+	wizard.SetGroupStore(v.groups)
+	wizard.SetContext(v.ctx)
 	wizard.Show(v.pages, func(_ string) {
 		go func() { _ = v.Refresh() }()
 	}, func() {
@@ -1386,7 +1529,8 @@ func (v *JobsView) showBatchOperations() {
 		allSelectedData := v.table.GetAllSelectedData()
 		for _, rowData := range allSelectedData {
 			if len(rowData) > 0 {
-				selectedJobs = append(selectedJobs, rowData[0])
+				// A collapsed array row acts on the whole array
+				selectedJobs = append(selectedJobs, strings.TrimSuffix(rowData[0], arrayRowSuffix))
 				jobData := map[string]interface{}{
 					"name":  rowData[1],
 					"state": rowData[4],
@@ -1774,6 +1918,7 @@ func (v *JobsView) closeAdvancedFilter() {
 func (v *JobsView) onAdvancedFilterChange(filter *filters.Filter) {
 	v.advancedFilter = filter
 	v.updateTable()
+	v.refreshIfPushdownChanged()
 
 	// Note: Status bar updates removed since individual view status bars are no longer used
 }
@@ -1796,8 +1941,39 @@ func (v *JobsView) applyAdvancedFilter(jobs []*dao.Job) []*dao.Job {
 	return filtered
 }
 
+// CaptureSavedView returns the advanced filter, sort and columns as a saved view
+func (v *JobsView) CaptureSavedView() filters.SavedView {
+	saved := filters.SavedView{ViewType: "jobs", Filter: filterSource(v.advancedFilter)}
+	captureTableLayout(v.table.Table, &saved)
+	return saved
+}
+
+// ApplySavedView restores the advanced filter, sort and columns of a saved view
+func (v *JobsView) ApplySavedView(saved filters.SavedView) error {
+	filter, err := parseSavedFilter(saved)
+	if err != nil {
+		return err
+	}
+	if err := applyTableLayout(v.table.Table, saved); err != nil {
+		return fmt.Errorf("view %s: %w", saved.Name, err)
+	}
+
+	if v.filterBar != nil {
+		v.filterBar.SetFilter(saved.Filter)
+		return nil
+	}
+	v.advancedFilter = filter
+	v.updateTable()
+	v.refreshIfPushdownChanged()
+	return nil
+}
+
 // jobToMap converts a job to a map for filter evaluation
 func (v *JobsView) jobToMap(job *dao.Job) map[string]interface{} {
+	annotation := v.jobAnnotation(job)
+	if annotation.Labels == nil {
+		annotation.Labels = []string{}
+	}
 	return map[string]interface{}{
 		"ID":         job.ID,
 		"Name":       job.Name,
@@ -1816,7 +1992,56 @@ func (v *JobsView) jobToMap(job *dao.Job) map[string]interface{} {
 		"EndTime":    job.EndTime,
 		"WorkingDir": job.WorkingDir,
 		"Command":    job.Command,
+		"Group":      v.jobGroup(job),
+		"Label":      annotation.Labels,
+		"Note":       annotation.Note,
+		"Bookmarked": annotation.Bookmarked,
+	}
+}
+
+// jobGroup returns the group job belongs to: its own or that of its array
+func (v *JobsView) jobGroup(job *dao.Job) string {
+	if v.groups == nil {
+		return ""
+	}
+	if group := v.groups.GroupOf(job.ID); group != "" {
+		return group
 	}
+	if job.ArrayJobID != "" {
+		return v.groups.GroupOf(job.ArrayJobID)
+	}
+	return ""
+}
+
+// ShowGroup filters the view to the jobs of the named group, in any state,
+// so that finished jobs of the group stay listed
+func (v *JobsView) ShowGroup(name string) error {
+	if v.groups == nil {
+		return fmt.Errorf("job groups are not available")
+	}
+	if _, ok := v.groups.Get(name); !ok {
+		return fmt.Errorf("group %s not found", name)
+	}
+
+	return v.showFilter("group=" + name)
+}
+
+// showFilter clears the state quick filter and applies filter as the
+// advanced filter
+func (v *JobsView) showFilter(filter string) error {
+	v.stateFilter = []string{}
+	if v.filterBar != nil {
+		v.filterBar.SetFilter(filter)
+		return nil
+	}
+	parsed, err := filters.NewFilterParser().Parse(filter)
+	if err != nil {
+		return err
+	}
+	v.advancedFilter = parsed
+	v.updateTable()
+	v.refreshIfPushdownChanged()
+	return nil
 }
 
 // showGlobalSearch shows the global search interface
@@ -1992,6 +2217,59 @@ func (v *JobsView) showExportDialog() {
 		jobs := make([]*dao.Job, len(v.jobs))
 		copy(jobs, v.jobs)
 		v.mu.RUnlock()
-		return export.JobsTableData(jobs)
+		return v.jobsExportData(jobs)
 	})
 }
+
+// jobProgress returns the progress estimate for a job, if its output reports any
+func (v *JobsView) jobProgress(job *dao.Job) (*streaming.ProgressEstimate, bool) {
+	if v.streamMgr == nil {
+		return nil, false
+	}
+	return v.streamMgr.EstimateProgress(job)
+}
+
+// formatProgressCell renders the progress column, highlighting jobs that are
+// projected to run past their time limit
+func (v *JobsView) formatProgressCell(job *dao.Job) string {
+	estimate, ok := v.jobProgress(job)
+	if !ok {
+		return ""
+	}
+
+	cell := fmt.Sprintf("%.0f%%", estimate.Percent)
+	if estimate.HasETA() {
+		cell += " ETA " + FormatTimeDuration(estimate.ETA)
+	}
+	if estimate.WillTimeOut {
+		return "[red]" + cell + " ![white]"
+	}
+	return cell
+}
+
+// writeProgressDetails writes the progress section of the job details
+func (v *JobsView) writeProgressDetails(d *strings.Builder, job *dao.Job) {
+	estimate, ok := v.jobProgress(job)
+	if !ok {
+		return
+	}
+
+	d.WriteString("[teal]Progress[white]\n")
+	writeDetailIndented(d, "Complete", fmt.Sprintf("%.1f%%", estimate.Percent))
+	if estimate.Rate > 0 {
+		writeDetailIndented(d, "Rate", fmt.Sprintf("%.2f%%/min", estimate.Rate))
+	}
+	if estimate.HasETA() {
+		writeDetailIndented(d, "ETA", fmt.Sprintf("%s (%s)",
+			FormatDurationDetailed(estimate.ETA),
+			time.Now().Add(estimate.ETA).Format("2006-01-02 15:04")))
+	}
+	if estimate.Remaining > 0 {
+		writeDetailIndented(d, "Remaining", FormatDurationDetailed(estimate.Remaining))
+	}
+	if estimate.WillTimeOut {
+		writeDetailIndented(d, "Warning", "[red]projected to exceed the time limit[white]")
+	}
+	writeDetailIndented(d, "Source", fmt.Sprintf("%s (updated %s)", estimate.Source, estimate.UpdatedAt.Format("15:04:05")))
+	d.WriteString("\n")
+}
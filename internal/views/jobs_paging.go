@@ -0,0 +1,195 @@
+package views
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/jontk/s9s/internal/dao"
+)
+
+const (
+	// defaultJobsPageSize is the number of jobs fetched per page when
+	// jobs.maxJobs is not configured
+	defaultJobsPageSize = 1000
+
+	// jobsLoadAhead is how close to the last loaded row the selection gets
+	// before the next page is fetched
+	jobsLoadAhead = 50
+)
+
+// pageSize returns the number of jobs fetched per page
+func (v *JobsView) pageSize() int {
+	if v.viewConfig != nil && v.viewConfig.MaxJobs > 0 {
+		return v.viewConfig.MaxJobs
+	}
+	return defaultJobsPageSize
+}
+
+// listOptions builds the server-side filter from the quick filters and the
+// parts of the advanced filter that pin states, users, partitions or
+// accounts to fixed values. The advanced filter is still applied to the
+// returned jobs, so only what is pushed down has to be exact. ok is false
+// when the filters contradict each other and no job can match.
+func (v *JobsView) listOptions() (opts *dao.ListJobsOptions, ok bool) {
+	opts = &dao.ListJobsOptions{}
+	var single []string
+
+	if opts.States, ok = v.pushdownValues(slices.Clone(v.stateFilter), "State"); !ok {
+		return nil, false
+	}
+	if v.userFilter != "" {
+		single = []string{v.userFilter}
+	}
+	if opts.Users, ok = v.pushdownValues(single, "User"); !ok {
+		return nil, false
+	}
+	single = nil
+	if v.partFilter != "" {
+		single = []string{v.partFilter}
+	}
+	if opts.Partitions, ok = v.pushdownValues(single, "Partition"); !ok {
+		return nil, false
+	}
+	if opts.Accounts, ok = v.pushdownValues(nil, "Account"); !ok {
+		return nil, false
+	}
+	return opts, true
+}
+
+// pushdownValues narrows values, a quick filter where empty means any, by
+// the values the advanced filter requires for field
+func (v *JobsView) pushdownValues(values []string, field string) ([]string, bool) {
+	required, constrained := v.advancedFilter.RequiredValues(field)
+	switch {
+	case !constrained:
+		return values, true
+	case len(values) == 0:
+		return required, len(required) > 0
+	}
+	values = slices.DeleteFunc(values, func(value string) bool {
+		return !slices.Contains(required, value)
+	})
+	return values, len(values) > 0
+}
+
+// sameListOptions reports whether a and b select the same jobs
+func sameListOptions(a, b *dao.ListJobsOptions) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return slices.Equal(a.States, b.States) &&
+		slices.Equal(a.Users, b.Users) &&
+		slices.Equal(a.Partitions, b.Partitions) &&
+		slices.Equal(a.Accounts, b.Accounts)
+}
+
+// loadMoreJobs fetches the next page of jobs when the selection nears the
+// last loaded row
+func (v *JobsView) loadMoreJobs() {
+	v.mu.RLock()
+	offset := len(v.jobs)
+	hasMore := offset < v.totalJobs
+	fetched := v.fetchedOpts
+	v.mu.RUnlock()
+
+	if !hasMore || v.refreshing.Load() || !v.loadingMore.CompareAndSwap(false, true) {
+		return
+	}
+
+	go func() {
+		defer v.loadingMore.Store(false)
+
+		opts, ok := v.listOptions()
+		if !ok || !sameListOptions(opts, fetched) {
+			return // A refresh for the new filters is on its way
+		}
+		opts.Limit = v.pageSize()
+		opts.Offset = offset
+
+		jobList, err := v.client.Jobs().List(opts)
+		if err != nil {
+			v.SetLastError(err)
+			return
+		}
+
+		if v.app != nil {
+			v.app.QueueUpdateDraw(func() {
+				if v.appendJobs(opts, jobList) {
+					v.updateTable()
+				}
+			})
+		}
+	}()
+}
+
+// appendJobs adds a page fetched with opts, unless the loaded jobs changed
+// in the meantime. Jobs already loaded are skipped.
+func (v *JobsView) appendJobs(opts *dao.ListJobsOptions, page *dao.JobList) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if len(v.jobs) != opts.Offset || !sameListOptions(opts, v.fetchedOpts) {
+		return false
+	}
+
+	loaded := make(map[string]bool, len(v.jobs))
+	for _, job := range v.jobs {
+		loaded[job.ID] = true
+	}
+	jobs := slices.Clip(v.jobs)
+	for _, job := range page.Jobs {
+		if !loaded[job.ID] {
+			jobs = append(jobs, job)
+		}
+	}
+	v.jobs = jobs
+	v.totalJobs = max(page.Total, len(jobs))
+	return true
+}
+
+// loadStatus describes how many of the matching jobs are loaded, or ""
+// when all of them are
+func (v *JobsView) loadStatus() string {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.loadStatusLocked()
+}
+
+// loadStatusLocked is loadStatus for callers holding v.mu
+func (v *JobsView) loadStatusLocked() string {
+	if len(v.jobs) >= v.totalJobs {
+		return ""
+	}
+	return fmt.Sprintf("[gray]Loaded %d/%d[white]", len(v.jobs), v.totalJobs)
+}
+
+// setSelectionStatus shows the multi-select status next to the load status
+func (v *JobsView) setSelectionStatus(text string) {
+	v.selectionStatus = text
+	v.renderSelectionStatus(v.loadStatus())
+}
+
+// renderSelectionStatus shows the load status and the multi-select status
+func (v *JobsView) renderSelectionStatus(load string) {
+	if v.selectionStatusText == nil {
+		return
+	}
+	text := v.selectionStatus
+	if load != "" {
+		text = load + " | " + text
+	}
+	v.selectionStatusText.SetText(text)
+}
+
+// refreshIfPushdownChanged refetches the jobs when the filters now push a
+// different server-side filter down than the loaded jobs were fetched with
+func (v *JobsView) refreshIfPushdownChanged() {
+	opts, _ := v.listOptions()
+	v.mu.RLock()
+	changed := !sameListOptions(opts, v.fetchedOpts)
+	v.mu.RUnlock()
+
+	if changed && v.client != nil {
+		v.scheduleFilterRefresh(false)
+	}
+}
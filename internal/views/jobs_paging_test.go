@@ -0,0 +1,88 @@
+package views
+
+import (
+	"testing"
+
+	"github.com/jontk/s9s/internal/config"
+	"github.com/jontk/s9s/internal/dao"
+	"github.com/jontk/s9s/internal/ui/filters"
+	"github.com/jontk/s9s/pkg/slurm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parseJobsFilter(t *testing.T, text string) *filters.Filter {
+	t.Helper()
+	filter, err := filters.NewFilterParser().Parse(text)
+	require.NoError(t, err)
+	return filter
+}
+
+func TestJobsViewListOptions(t *testing.T) {
+	v := NewJobsView(nil)
+
+	opts, ok := v.listOptions()
+	require.True(t, ok)
+	assert.Equal(t, &dao.ListJobsOptions{}, opts)
+
+	v.stateFilter = []string{dao.JobStateRunning, dao.JobStatePending}
+	v.userFilter = "alice"
+	v.advancedFilter = parseJobsFilter(t, "state=PENDING and partition in (gpu,debug) and account=physics and name~train")
+	opts, ok = v.listOptions()
+	require.True(t, ok)
+	assert.Equal(t, []string{dao.JobStatePending}, opts.States, "quick and advanced state filters intersect")
+	assert.Equal(t, []string{"alice"}, opts.Users)
+	assert.Equal(t, []string{"gpu", "debug"}, opts.Partitions)
+	assert.Equal(t, []string{"physics"}, opts.Accounts)
+	assert.Equal(t, []string{dao.JobStateRunning, dao.JobStatePending}, v.stateFilter, "the quick filter is not modified")
+
+	// Conditions that cannot be pushed down leave the options alone
+	v.advancedFilter = parseJobsFilter(t, "user=bob or priority>100")
+	opts, ok = v.listOptions()
+	require.True(t, ok)
+	assert.Equal(t, []string{"alice"}, opts.Users)
+
+	// Contradicting filters match nothing
+	v.advancedFilter = parseJobsFilter(t, "user=bob")
+	_, ok = v.listOptions()
+	assert.False(t, ok)
+}
+
+func TestJobsViewPaging(t *testing.T) {
+	client := slurm.NewFastMockClient()
+	all, err := client.Jobs().List(nil)
+	require.NoError(t, err)
+	require.Greater(t, all.Total, 3, "the mock needs a few jobs")
+
+	v := NewJobsView(client)
+	v.SetViewConfig(&config.JobsViewConfig{MaxJobs: 2})
+
+	first, opts, err := v.fetchJobs()
+	require.NoError(t, err)
+	require.Len(t, first.Jobs, 2)
+	v.setJobs(first, opts)
+	assert.Equal(t, all.Total, v.totalJobs)
+	assert.Contains(t, v.loadStatus(), "Loaded 2/")
+
+	next := &dao.ListJobsOptions{Limit: 2, Offset: 2}
+	page, err := client.Jobs().List(next)
+	require.NoError(t, err)
+	require.True(t, v.appendJobs(next, page))
+	require.Len(t, v.jobs, 4)
+	assert.NotEqual(t, v.jobs[0].ID, v.jobs[2].ID, "pages do not overlap")
+
+	// A stale page is dropped
+	assert.False(t, v.appendJobs(next, page))
+
+	// A refresh keeps the rows that were scrolled in
+	refreshed, _, err := v.fetchJobs()
+	require.NoError(t, err)
+	assert.Len(t, refreshed.Jobs, 4)
+
+	// A different filter starts from the first page again
+	v.userFilter = first.Jobs[0].User
+	filtered, filteredOpts, err := v.fetchJobs()
+	require.NoError(t, err)
+	assert.LessOrEqual(t, len(filtered.Jobs), 2)
+	assert.Equal(t, []string{v.userFilter}, filteredOpts.Users)
+}
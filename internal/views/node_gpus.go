@@ -0,0 +1,192 @@
+package views
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/jontk/s9s/internal/dao"
+	"github.com/rivo/tview"
+)
+
+// untypedGPULabel is shown for GPUs configured without a model
+const untypedGPULabel = "(untyped)"
+
+// formatNodeGPUUsage formats GPU usage text for a node row
+func (v *NodesView) formatNodeGPUUsage(node *dao.Node) string {
+	total, allocated := node.GPUCounts()
+	if total == 0 {
+		return ""
+	}
+	// SLURM reports allocation only, so allocated GPUs are drawn as used
+	gpuUsage := v.createDualUsageBar(allocated, allocated, total)
+	return fmt.Sprintf("%s %d/%d", gpuUsage, allocated, total)
+}
+
+// gpuGroupKey returns the GPU model group of a node
+func gpuGroupKey(node *dao.Node) string {
+	if total, _ := node.GPUCounts(); total == 0 {
+		return "<no GPUs>"
+	}
+	if types := node.GPUTypes(); len(types) > 0 {
+		return strings.Join(types, "+")
+	}
+	return untypedGPULabel
+}
+
+// writeGPUDetails writes the GRES section of the node details
+func (v *NodesView) writeGPUDetails(w *strings.Builder, node *dao.Node) {
+	if len(node.GRES) == 0 {
+		return
+	}
+
+	w.WriteString("\n[teal]GPU / GRES Information:[white]\n")
+	if total, allocated := node.GPUCounts(); total > 0 {
+		fmt.Fprintf(w, "[yellow]  Total GPUs:[white] %d\n", total)
+		fmt.Fprintf(w, "[yellow]  Allocated GPUs:[white] %d\n", allocated)
+		fmt.Fprintf(w, "[yellow]  Free GPUs:[white] %d\n", total-allocated)
+	}
+	for _, g := range node.GRES {
+		fmt.Fprintf(w, "[yellow]  %s:[white] %d allocated", g.String(), g.Allocated)
+		if len(g.Indices) > 0 {
+			fmt.Fprintf(w, " (IDX:%s)", formatIndexList(g.Indices))
+		}
+		w.WriteString("\n")
+	}
+}
+
+// formatIndexList formats sorted device indices as ranges, e.g. "0-1,5"
+func formatIndexList(indices []int) string {
+	var parts []string
+	for i := 0; i < len(indices); {
+		j := i
+		for j+1 < len(indices) && indices[j+1] == indices[j]+1 {
+			j++
+		}
+		if i == j {
+			parts = append(parts, strconv.Itoa(indices[i]))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d-%d", indices[i], indices[j]))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ",")
+}
+
+// ShowGPUAvailability shows where count GPUs of the given model can start
+// right now. A count of 0 or an empty model summarises every model.
+func (v *NodesView) ShowGPUAvailability(count int, gpuType string) {
+	if v.pages == nil || v.app == nil {
+		return
+	}
+
+	textView := tview.NewTextView().
+		SetDynamicColors(true).
+		SetText("[yellow]Loading GPU availability...[white]").
+		SetScrollable(true)
+
+	modal := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(textView, 0, 1, true).
+		AddItem(tview.NewTextView().SetDynamicColors(true).
+			SetText("[yellow]ESC[white] Close"), 1, 0, false)
+
+	modal.SetBorder(true).
+		SetTitle(" GPU Availability ").
+		SetTitleAlign(tview.AlignCenter)
+
+	centeredModal := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(modal, 0, 6, true).
+			AddItem(nil, 0, 1, false), 0, 8, true).
+		AddItem(nil, 0, 1, false)
+
+	modal.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEsc {
+			v.pages.RemovePage("gpu-availability")
+			return nil
+		}
+		return event
+	})
+
+	v.pages.AddPage("gpu-availability", centeredModal, true, true)
+
+	// The view's node list may be narrowed by state or partition filters,
+	// so availability is computed from all nodes
+	go func() {
+		nodeList, err := v.client.Nodes().List(&dao.ListNodesOptions{})
+		v.app.QueueUpdateDraw(func() {
+			if err != nil {
+				textView.SetText(fmt.Sprintf("[red]Failed to load nodes: %v[white]", err))
+				return
+			}
+			textView.SetText(formatGPUAvailability(dao.BuildGPUAvailability(nodeList.Nodes), count, gpuType))
+		})
+	}()
+}
+
+// formatGPUAvailability renders the per-model GPU availability and, when a
+// count is requested, the nodes that can start such a job right now
+func formatGPUAvailability(avail []*dao.GPUAvailability, count int, gpuType string) string {
+	if len(avail) == 0 {
+		return "[gray]No nodes report GPU resources.[white]\n"
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("[teal]%-12s %6s %6s %8s %6s  %s[white]\n",
+		"Model", "Total", "Alloc", "Unavail", "Free", "Largest free on one node"))
+	var matched []*dao.GPUAvailability
+	for _, a := range avail {
+		if gpuType != "" && !strings.EqualFold(a.Type, gpuType) {
+			continue
+		}
+		matched = append(matched, a)
+
+		largest := 0
+		if len(a.Nodes) > 0 {
+			largest = a.Nodes[0].Free
+		}
+		freeColor := "green"
+		if a.Free == 0 {
+			freeColor = "red"
+		}
+		b.WriteString(fmt.Sprintf("%-12s %6d %6d %8d [%s]%6d[white]  %d\n",
+			TruncateString(gpuModelLabel(a.Type), 12), a.Total, a.Allocated, a.Unavailable,
+			freeColor, a.Free, largest))
+	}
+
+	if len(matched) == 0 {
+		b.WriteString(fmt.Sprintf("\n[red]No nodes have GPUs of type %s.[white]\n", gpuType))
+		return b.String()
+	}
+	if count <= 0 {
+		return b.String()
+	}
+
+	for _, a := range matched {
+		b.WriteString(fmt.Sprintf("\n[teal]%d x %s GPUs right now:[white]\n", count, gpuModelLabel(a.Type)))
+		nodes := a.NodesWithFree(count)
+		if len(nodes) == 0 {
+			b.WriteString(fmt.Sprintf("  [yellow]No single node has %d free; %d free across %d nodes[white]\n",
+				count, a.Free, len(a.Nodes)))
+			continue
+		}
+		b.WriteString(fmt.Sprintf("  Partitions: %s\n", strings.Join(a.Partitions(count), ", ")))
+		for _, n := range nodes {
+			b.WriteString(fmt.Sprintf("  %-16s %d/%d free  %s\n",
+				n.Node, n.Free, n.Total, strings.Join(n.Partitions, ",")))
+		}
+	}
+	return b.String()
+}
+
+// gpuModelLabel returns the display name of a GPU model
+func gpuModelLabel(gpuType string) string {
+	if gpuType == "" {
+		return untypedGPULabel
+	}
+	return gpuType
+}
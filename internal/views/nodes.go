@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"slices"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -33,7 +35,7 @@ type NodesView struct {
 	stateFilter    []string
 	partFilter     string
 	filterDebounce *time.Timer
-	groupBy        string // "none", "partition", "state", "features"
+	groupBy        string // "none", "partition", "state", "features", "gpu"
 	groupExpanded  map[string]bool
 	container      *tview.Flex
 	filterInput    *tview.InputField
@@ -105,6 +107,7 @@ func NewNodesView(client dao.SlurmClient) *NodesView {
 		components.NewColumn("Partitions").Width(15).Build(),
 		components.NewColumn("CPU Usage").Width(20).Align(tview.AlignCenter).Sortable(true).Build(),
 		components.NewColumn("Memory Usage").Width(20).Align(tview.AlignCenter).Sortable(true).Build(),
+		components.NewColumn("GPUs").Width(16).Align(tview.AlignCenter).Sortable(true).Build(),
 		components.NewColumn("CPU Total").Width(10).Align(tview.AlignRight).Sortable(true).Build(),
 		components.NewColumn("Memory Total").Width(15).Align(tview.AlignRight).Sortable(true).Build(),
 		components.NewColumn("Features").Width(20).Build(),
@@ -211,6 +214,7 @@ func (v *NodesView) Hints() []string {
 		"[yellow]p[white] Partition",
 		"[yellow]a[white] All States",
 		"[yellow]g[white] Group By",
+		"[yellow]u[white] GPU Avail",
 		"[yellow]Space[white] Toggle Group",
 		"Bar: █=Used ▒=Alloc ▱=Free",
 	}
@@ -314,6 +318,8 @@ func (v *NodesView) nodesRuneHandlers() map[rune]func(*NodesView, *tcell.EventKe
 		'g': func(v *NodesView, _ *tcell.EventKey) *tcell.EventKey { v.promptGroupBy(); return nil },
 		'G': func(v *NodesView, _ *tcell.EventKey) *tcell.EventKey { v.promptGroupBy(); return nil },
 		' ': func(v *NodesView, _ *tcell.EventKey) *tcell.EventKey { v.toggleGroupExpansion(); return nil },
+		'u': func(v *NodesView, _ *tcell.EventKey) *tcell.EventKey { v.ShowGPUAvailability(0, ""); return nil },
+		'U': func(v *NodesView, _ *tcell.EventKey) *tcell.EventKey { v.ShowGPUAvailability(0, ""); return nil },
 		'e': func(v *NodesView, _ *tcell.EventKey) *tcell.EventKey { v.showExportDialog(); return nil },
 		'E': func(v *NodesView, _ *tcell.EventKey) *tcell.EventKey { v.showExportDialog(); return nil },
 	}
@@ -393,7 +399,7 @@ func (v *NodesView) updateTableGrouped() {
 		}
 
 		groupHeader := fmt.Sprintf("[yellow]%s %s (%d nodes)[white]", expandIcon, groupName, len(nodes))
-		data = append(data, []string{groupHeader, "", "", "", "", "", "", "", ""})
+		data = append(data, []string{groupHeader, "", "", "", "", "", "", "", "", ""})
 
 		// Add nodes if expanded
 		if expanded {
@@ -410,12 +416,11 @@ func (v *NodesView) updateTableGrouped() {
 
 // formatNodeRow formats a single node row
 func (v *NodesView) formatNodeRow(node *dao.Node) []string {
-	displayState := v.getNodeDisplayState(node)
-	stateColor := dao.GetNodeStateColor(displayState)
-	coloredState := fmt.Sprintf("[%s]%s[white]", stateColor, displayState)
+	coloredState := fmt.Sprintf("[%s]%s[white]", node.ParsedState().Color(), node.State)
 
 	cpuUsageText := v.formatNodeCPUUsage(node)
 	memUsageText := v.formatNodeMemoryUsage(node)
+	gpuUsageText := v.formatNodeGPUUsage(node)
 
 	partitions := truncateString(strings.Join(node.Partitions, ","), 14, 11)
 	features := truncateString(strings.Join(node.Features, ","), 19, 16)
@@ -427,6 +432,7 @@ func (v *NodesView) formatNodeRow(node *dao.Node) []string {
 		partitions,
 		cpuUsageText,
 		memUsageText,
+		gpuUsageText,
 		fmt.Sprintf("%d", node.CPUsTotal),
 		FormatMemory(node.MemoryTotal),
 		features,
@@ -434,18 +440,6 @@ func (v *NodesView) formatNodeRow(node *dao.Node) []string {
 	}
 }
 
-// getNodeDisplayState determines the display state for a node
-func (v *NodesView) getNodeDisplayState(node *dao.Node) string {
-	displayState := node.State
-	isDrainedByReason := node.Reason != "" && node.Reason != "Not responding"
-
-	if node.State == "IDLE" && isDrainedByReason {
-		displayState = "IDLE+DRAIN"
-	}
-
-	return displayState
-}
-
 // formatNodeCPUUsage formats CPU usage text for a node row
 func (v *NodesView) formatNodeCPUUsage(node *dao.Node) string {
 	cpuActualUsed := v.calculateCPUActualUsed(node)
@@ -600,7 +594,7 @@ func (v *NodesView) updateStatusBar(message string) {
 	drain := 0
 
 	for _, node := range v.nodes {
-		switch node.State {
+		switch node.ParsedState().Summary() {
 		case dao.NodeStateIdle:
 			idle++
 		case dao.NodeStateAllocated:
@@ -760,11 +754,8 @@ func (v *NodesView) drainSelectedNode() {
 		return
 	}
 
-	data := v.table.GetSelectedData()
-	state := data[1]
-
 	// Check if node can be drained
-	if strings.Contains(state, dao.NodeStateDown) {
+	if node := v.findNode(nodeName); node != nil && node.ParsedState().IsDown() {
 		// Note: Status bar update removed since individual view status bars are no longer used
 		return
 	}
@@ -862,21 +853,17 @@ func (v *NodesView) resumeSelectedNode() {
 		return
 	}
 
-	data := v.table.GetSelectedData()
-	state := data[1]
-
 	node := v.findNode(nodeName)
 	if node == nil {
 		debug.Logger.Printf("resumeSelectedNode() - node %s not found in node list", nodeName)
 		return
 	}
 
-	cleanState := v.cleanNodeState(state)
-	debug.Logger.Printf("resumeSelectedNode() - clean state: %s, reason: '%s'", cleanState, node.Reason)
+	debug.Logger.Printf("resumeSelectedNode() - state: %s, reason: '%s'", node.State, node.Reason)
 
-	if !v.isNodeDrained(cleanState, node) {
-		v.showResumeError(nodeName, cleanState)
-		debug.Logger.Printf("resumeSelectedNode() - node %s cannot be resumed, state: %s, reason: %s", nodeName, cleanState, node.Reason)
+	if !isNodeResumable(node) {
+		v.showResumeError(nodeName, node.State)
+		debug.Logger.Printf("resumeSelectedNode() - node %s cannot be resumed, state: %s, reason: %s", nodeName, node.State, node.Reason)
 		return
 	}
 
@@ -911,23 +898,10 @@ func (v *NodesView) findNode(nodeName string) *dao.Node {
 	return nil
 }
 
-// cleanNodeState removes color codes from node state string
-func (v *NodesView) cleanNodeState(state string) string {
-	colorCodes := []string{"[green]", "[white]", "[yellow]", "[red]", "[blue]", "[orange]"}
-	cleanState := state
-
-	for _, code := range colorCodes {
-		cleanState = strings.ReplaceAll(cleanState, code, "")
-	}
-
-	return cleanState
-}
-
-// isNodeDrained checks if a node is in a drained state
-func (v *NodesView) isNodeDrained(cleanState string, node *dao.Node) bool {
-	return strings.Contains(cleanState, dao.NodeStateDrain) ||
-		strings.Contains(cleanState, dao.NodeStateDraining) ||
-		(node.Reason != "" && node.Reason != "Not responding")
+// isNodeResumable checks if a node is drained, failed or down and can be resumed
+func isNodeResumable(node *dao.Node) bool {
+	state := node.ParsedState()
+	return state.IsDrain() || state.IsDown() || state.Has(dao.NodeFlagFail)
 }
 
 // showResumeError shows an error modal for resume operation
@@ -937,7 +911,7 @@ func (v *NodesView) showResumeError(nodeName, state string) {
 	}
 
 	errorModal := tview.NewModal().
-		SetText(fmt.Sprintf("Node %s is in state '%s' with no drain reason. Only drained nodes can be resumed.", nodeName, state)).
+		SetText(fmt.Sprintf("Node %s is in state '%s'. Only drained, failed or down nodes can be resumed.", nodeName, state)).
 		AddButtons([]string{"OK"}).
 		SetDoneFunc(func(_ int, _ string) {
 			v.pages.RemovePage("error")
@@ -1080,13 +1054,20 @@ func (v *NodesView) formatNodeDetails(node *dao.Node) string {
 
 	details.WriteString(fmt.Sprintf("[yellow]Node Name:[white] %s\n", node.Name))
 
-	stateColor := dao.GetNodeStateColor(node.State)
-	details.WriteString(fmt.Sprintf("[yellow]State:[white] [%s]%s[white]\n", stateColor, node.State))
+	state := node.ParsedState()
+	details.WriteString(fmt.Sprintf("[yellow]State:[white] [%s]%s[white]\n", state.Color(), state.Summary()))
+	if state.Base != "" {
+		details.WriteString(fmt.Sprintf("[yellow]Base State:[white] %s\n", state.Base))
+	}
+	if len(state.Flags) > 0 {
+		details.WriteString(fmt.Sprintf("[yellow]State Flags:[white] %s\n", strings.Join(state.Flags, ", ")))
+	}
 
 	details.WriteString(fmt.Sprintf("[yellow]Partitions:[white] %s\n", strings.Join(node.Partitions, ", ")))
 
 	v.writeCPUDetails(&details, node)
 	v.writeMemoryDetails(&details, node)
+	v.writeGPUDetails(&details, node)
 
 	if len(node.Features) > 0 {
 		details.WriteString(fmt.Sprintf("\n[yellow]Features:[white] %s\n", strings.Join(node.Features, ", ")))
@@ -1497,13 +1478,15 @@ func (v *NodesView) groupNodes() map[string][]*dao.Node {
 				groupKey = "<no partition>"
 			}
 		case "state":
-			groupKey = node.State
+			groupKey = node.ParsedState().Summary()
 		case "features":
 			if len(node.Features) > 0 {
 				groupKey = node.Features[0] // Use first feature
 			} else {
 				groupKey = "<no features>"
 			}
+		case "gpu":
+			groupKey = gpuGroupKey(node)
 		default:
 			groupKey = "All Nodes"
 		}
@@ -1514,9 +1497,12 @@ func (v *NodesView) groupNodes() map[string][]*dao.Node {
 	return groups
 }
 
+// nodeGroupByOptions lists the ways nodes can be grouped
+var nodeGroupByOptions = []string{"none", "partition", "state", "features", "gpu"}
+
 // promptGroupBy prompts for grouping method
 func (v *NodesView) promptGroupBy() {
-	options := []string{"none", "partition", "state", "features"}
+	options := nodeGroupByOptions
 	currentIndex := 0
 
 	// Find current selection
@@ -1683,11 +1669,54 @@ func (v *NodesView) applyAdvancedFilter(nodes []*dao.Node) []*dao.Node {
 	return filtered
 }
 
+// CaptureSavedView returns the advanced filter, sort, columns and grouping
+// as a saved view
+func (v *NodesView) CaptureSavedView() filters.SavedView {
+	saved := filters.SavedView{ViewType: "nodes", Filter: filterSource(v.advancedFilter)}
+	if v.groupBy != "none" {
+		saved.GroupBy = v.groupBy
+	}
+	captureTableLayout(v.table, &saved)
+	return saved
+}
+
+// ApplySavedView restores the advanced filter, sort, columns and grouping of
+// a saved view
+func (v *NodesView) ApplySavedView(saved filters.SavedView) error {
+	filter, err := parseSavedFilter(saved)
+	if err != nil {
+		return err
+	}
+	groupBy := saved.GroupBy
+	if groupBy == "" {
+		groupBy = "none"
+	}
+	if !slices.Contains(nodeGroupByOptions, groupBy) {
+		return fmt.Errorf("view %s: unknown group-by %q", saved.Name, saved.GroupBy)
+	}
+	if err := applyTableLayout(v.table, saved); err != nil {
+		return fmt.Errorf("view %s: %w", saved.Name, err)
+	}
+	v.setGroupBy(groupBy)
+
+	if v.filterBar != nil {
+		v.filterBar.SetFilter(saved.Filter)
+		return nil
+	}
+	v.advancedFilter = filter
+	v.updateTable()
+	return nil
+}
+
 // nodeToMap converts a node to a map for filter evaluation
 func (v *NodesView) nodeToMap(node *dao.Node) map[string]interface{} {
+	gpusTotal, gpusAllocated := node.GPUCounts()
+	state := node.ParsedState()
 	return map[string]interface{}{
 		"Name":            node.Name,
 		"State":           node.State,
+		"BaseState":       state.Base,
+		"StateFlags":      strings.Join(state.Flags, ","),
 		"CPUsAllocated":   node.CPUsAllocated,
 		"CPUsTotal":       node.CPUsTotal,
 		"MemoryAllocated": node.MemoryAllocated,
@@ -1695,6 +1724,9 @@ func (v *NodesView) nodeToMap(node *dao.Node) map[string]interface{} {
 		"Features":        strings.Join(node.Features, ","),
 		"Partitions":      strings.Join(node.Partitions, ","),
 		"Reason":          node.Reason,
+		"GPUs":            gpusTotal,
+		"GPUsFree":        gpusTotal - gpusAllocated,
+		"GPUType":         strings.Join(node.GPUTypes(), ","),
 	}
 }
 
@@ -1782,6 +1814,24 @@ func (v *NodesView) GetNodeNames() []string {
 	return names
 }
 
+// GetGPUTypes returns the GPU models of the cached nodes, sorted
+func (v *NodesView) GetGPUTypes() []string {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	seen := make(map[string]bool)
+	var types []string
+	for _, node := range v.nodes {
+		for _, t := range node.GPUTypes() {
+			if !seen[t] {
+				seen[t] = true
+				types = append(types, t)
+			}
+		}
+	}
+	sort.Strings(types)
+	return types
+}
+
 // showSSHTerminalManager shows the SSH terminal manager interface
 func (v *NodesView) showSSHTerminalManager(nodeName string) {
 	if v.sshTerminal == nil {
@@ -0,0 +1,205 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/jontk/s9s/internal/dao"
+	"github.com/jontk/s9s/internal/ui/styles"
+	"github.com/rivo/tview"
+)
+
+// partitionStateEffects describes what each settable partition state does
+var partitionStateEffects = map[string]string{
+	dao.PartitionStateUp:       "Accept and schedule jobs",
+	dao.PartitionStateDown:     "Accept jobs but do not start them",
+	dao.PartitionStateDrain:    "Run queued jobs but reject new ones",
+	dao.PartitionStateInactive: "Neither accept nor start jobs",
+}
+
+// selectedPartition returns the partition of the selected row, if any
+func (v *PartitionsView) selectedPartition() *dao.Partition {
+	data := v.table.GetSelectedData()
+	if len(data) == 0 {
+		return nil
+	}
+
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	for _, partition := range v.partitions {
+		if partition.Name == data[0] {
+			return partition
+		}
+	}
+	return nil
+}
+
+// showPartitionStateChooser lets an administrator pick a new state for the
+// selected partition
+func (v *PartitionsView) showPartitionStateChooser() {
+	partition := v.selectedPartition()
+	if partition == nil {
+		return
+	}
+
+	list := tview.NewList()
+	for _, state := range dao.PartitionSettableStates() {
+		label := state
+		if state == partition.State {
+			label += " (current)"
+		}
+		list.AddItem(label, partitionStateEffects[state], 0, func() {
+			v.closePartitionAdminPage()
+			if state == partition.State {
+				v.warnPartition(fmt.Sprintf("Partition %s is already %s", partition.Name, state))
+				return
+			}
+			v.confirmPartitionUpdate(
+				fmt.Sprintf("Set partition %s from %s to %s?\n\n%s.", partition.Name, partition.State, state, partitionStateEffects[state]),
+				fmt.Sprintf("Partition %s set to %s", partition.Name, state),
+				func(partitions dao.PartitionManager) error { return partitions.SetState(partition.Name, state) },
+			)
+		})
+	}
+
+	list.SetBorder(true).
+		SetTitle(fmt.Sprintf(" Set State of Partition %s ", partition.Name)).
+		SetTitleAlign(tview.AlignCenter)
+	list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEsc {
+			v.closePartitionAdminPage()
+			return nil
+		}
+		return event
+	})
+
+	v.showPartitionAdminPage(list, 2*len(dao.PartitionSettableStates())+2)
+}
+
+// promptPartitionMaxTime asks for a new run time limit for the selected
+// partition
+func (v *PartitionsView) promptPartitionMaxTime() {
+	partition := v.selectedPartition()
+	if partition == nil {
+		return
+	}
+
+	input := styles.NewStyledInputField().
+		SetLabel("Max time: ").
+		SetFieldWidth(20).
+		SetPlaceholder("minutes, D-HH:MM:SS or UNLIMITED").
+		SetText(partition.MaxTime)
+
+	input.SetDoneFunc(func(key tcell.Key) {
+		v.closePartitionAdminPage()
+		if key != tcell.KeyEnter {
+			return
+		}
+		limit := strings.TrimSpace(input.GetText())
+		if _, err := dao.MaxTimeMinutes(limit); err != nil {
+			v.warnPartition(fmt.Sprintf("Invalid max time: %v", err))
+			return
+		}
+		v.confirmPartitionUpdate(
+			fmt.Sprintf("Change the max time of partition %s from %s to %s?\n\nRunning jobs keep their current limits.", partition.Name, partition.MaxTime, limit),
+			fmt.Sprintf("Max time of partition %s set to %s", partition.Name, limit),
+			func(partitions dao.PartitionManager) error { return partitions.SetMaxTime(partition.Name, limit) },
+		)
+	})
+
+	input.SetBorder(true).
+		SetTitle(fmt.Sprintf(" Max Time of Partition %s ", partition.Name)).
+		SetTitleAlign(tview.AlignCenter)
+
+	v.showPartitionAdminPage(input, 3)
+}
+
+// confirmPartitionUpdate asks for confirmation before running update
+func (v *PartitionsView) confirmPartitionUpdate(question, done string, update func(dao.PartitionManager) error) {
+	if v.pages == nil {
+		return
+	}
+
+	modal := tview.NewModal().
+		SetText(question).
+		AddButtons([]string{"Apply", "Cancel"}).
+		SetDoneFunc(func(buttonIndex int, _ string) {
+			v.pages.RemovePage("partition-update-confirmation")
+			if v.app != nil {
+				v.app.SetFocus(v.table.Table)
+			}
+			if buttonIndex == 0 {
+				v.runPartitionUpdate(done, update)
+			}
+		})
+	modal.SetBorder(true).
+		SetTitle(" Confirm Partition Update ").
+		SetTitleAlign(tview.AlignCenter)
+
+	v.pages.AddPage("partition-update-confirmation", modal, true, true)
+}
+
+// runPartitionUpdate applies update in the background and refreshes the
+// view once it is done
+func (v *PartitionsView) runPartitionUpdate(done string, update func(dao.PartitionManager) error) {
+	go func() {
+		err := update(v.client.Partitions())
+
+		if v.app != nil {
+			v.app.QueueUpdateDraw(func() {
+				if v.mainStatusBar == nil {
+					return
+				}
+				if err != nil {
+					v.mainStatusBar.Error(fmt.Sprintf("Partition update failed: %v", err))
+					return
+				}
+				v.mainStatusBar.Success(done)
+			})
+		}
+		if err != nil {
+			return
+		}
+
+		time.Sleep(500 * time.Millisecond)
+		_ = v.Refresh()
+	}()
+}
+
+// showPartitionAdminPage shows a centered administration dialog of the
+// given height
+func (v *PartitionsView) showPartitionAdminPage(content tview.Primitive, height int) {
+	if v.pages == nil {
+		return
+	}
+	centered := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(content, height, 0, true).
+			AddItem(nil, 0, 1, false), 60, 0, true).
+		AddItem(nil, 0, 1, false)
+	v.pages.AddPage("partition-admin", centered, true, true)
+	if v.app != nil {
+		v.app.SetFocus(content)
+	}
+}
+
+// closePartitionAdminPage closes the administration dialog
+func (v *PartitionsView) closePartitionAdminPage() {
+	if v.pages != nil {
+		v.pages.RemovePage("partition-admin")
+	}
+	if v.app != nil {
+		v.app.SetFocus(v.table.Table)
+	}
+}
+
+// warnPartition shows a partition related warning
+func (v *PartitionsView) warnPartition(message string) {
+	if v.mainStatusBar != nil {
+		v.mainStatusBar.Warning(message)
+	}
+}
@@ -0,0 +1,63 @@
+package views
+
+import (
+	"testing"
+
+	"github.com/jontk/s9s/internal/dao"
+	"github.com/jontk/s9s/pkg/slurm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPartitionDetailsShowSchedulingAndAccess(t *testing.T) {
+	v := NewPartitionsView(nil)
+	details := v.formatPartitionDetails(&dao.Partition{
+		Name:           "gpu",
+		State:          dao.PartitionStateUp,
+		MaxTime:        "2-00:00:00",
+		PriorityTier:   2,
+		OverSubscribe:  "FORCE:4",
+		AllowAccounts:  []string{"ml", "physics"},
+		DenyQOS:        []string{"low"},
+		BillingWeights: "CPU=1.0,GRES/gpu=8.0",
+	})
+
+	assert.Contains(t, details, "Priority Tier:[white] 2")
+	assert.Contains(t, details, "OverSubscribe:[white] FORCE:4")
+	assert.Contains(t, details, "Max Nodes per Job:[white] UNLIMITED")
+	assert.Contains(t, details, "Allow Accounts:[white] ml, physics")
+	assert.Contains(t, details, "Deny QOS:[white] low")
+	assert.Contains(t, details, "Billing Weights:[white] CPU=1.0,GRES/gpu=8.0")
+	assert.NotContains(t, details, "Allow Groups", "empty attributes are left out")
+}
+
+func TestPartitionAdminUpdates(t *testing.T) {
+	client := slurm.NewFastMockClient()
+	v := NewPartitionsView(client)
+
+	list, err := client.Partitions().List()
+	require.NoError(t, err)
+	v.partitions = list.Partitions
+	v.updateTable()
+
+	v.table.Select(1, 0)
+	selected := v.selectedPartition()
+	require.NotNil(t, selected)
+
+	partitions := client.Partitions()
+	require.NoError(t, partitions.SetState(selected.Name, "drain"))
+	require.NoError(t, partitions.SetMaxTime(selected.Name, "12:00:00"))
+	updated, err := partitions.Get(selected.Name)
+	require.NoError(t, err)
+	assert.Equal(t, dao.PartitionStateDrain, updated.State)
+	assert.Equal(t, "12:00:00", updated.MaxTime)
+	assert.Equal(t, dao.PartitionStateUp, selected.State, "listed partitions are not modified in place")
+
+	assert.Error(t, partitions.SetState(selected.Name, "MAINT"))
+	require.NoError(t, partitions.SetMaxTime(selected.Name, "infinite"))
+	updated, err = partitions.Get(selected.Name)
+	require.NoError(t, err)
+	assert.Equal(t, "UNLIMITED", updated.MaxTime)
+	assert.Error(t, partitions.SetMaxTime(selected.Name, "soon"))
+	assert.Error(t, partitions.SetState("nosuch", dao.PartitionStateUp))
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/jontk/s9s/internal/dao"
 	"github.com/jontk/s9s/internal/debug"
 	"github.com/jontk/s9s/internal/export"
+	"github.com/jontk/s9s/internal/timeseries"
 	"github.com/jontk/s9s/internal/ui/components"
 	"github.com/jontk/s9s/internal/ui/filters"
 	"github.com/jontk/s9s/internal/ui/styles"
@@ -40,6 +41,8 @@ type PartitionsView struct {
 	advancedFilter *filters.Filter
 	isAdvancedMode bool
 	globalSearch   *GlobalSearch
+	history        *timeseries.Store
+	mainStatusBar  *components.StatusBar
 }
 
 // SetPages sets the pages reference for modal handling
@@ -51,6 +54,11 @@ func (v *PartitionsView) SetPages(pages *tview.Pages) {
 	}
 }
 
+// SetStatusBar sets the main status bar reference
+func (v *PartitionsView) SetStatusBar(statusBar *components.StatusBar) {
+	v.mainStatusBar = statusBar
+}
+
 // SetApp sets the application reference
 func (v *PartitionsView) SetApp(app *tview.Application) {
 	v.app = app
@@ -82,6 +90,7 @@ func NewPartitionsView(client dao.SlurmClient) *PartitionsView {
 		components.NewColumn("Queue Depth").Width(20).Align(tview.AlignCenter).Build(),
 		components.NewColumn("Running").Width(8).Align(tview.AlignRight).Sortable(true).Build(),
 		components.NewColumn("Pending").Width(8).Align(tview.AlignRight).Sortable(true).Build(),
+		components.NewColumn("Queue Trend").Width(12).Build(),
 		components.NewColumn("Avg Wait").Width(10).Align(tview.AlignRight).Sortable(true).Build(),
 		components.NewColumn("Max Wait").Width(10).Align(tview.AlignRight).Sortable(true).Build(),
 		components.NewColumn("Efficiency").Width(12).Align(tview.AlignCenter).Build(),
@@ -120,6 +129,13 @@ func NewPartitionsView(client dao.SlurmClient) *PartitionsView {
 	return v
 }
 
+// SetHistory sets the metrics history used for queue depth trends
+func (v *PartitionsView) SetHistory(history *timeseries.Store) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.history = history
+}
+
 // SetClient sets the SLURM client for the partitions view
 func (v *PartitionsView) SetClient(client dao.SlurmClient) {
 	v.mu.Lock()
@@ -162,7 +178,7 @@ func (v *PartitionsView) Refresh() error {
 			return
 		}
 
-		queueInfo := v.buildAllQueueInfo(jobList.Jobs, partitionList.Partitions)
+		queueInfo := dao.BuildQueueInfo(jobList.Jobs, partitionList.Partitions, time.Now())
 
 		if v.app != nil {
 			v.app.QueueUpdateDraw(func() {
@@ -179,77 +195,6 @@ func (v *PartitionsView) Refresh() error {
 	return nil
 }
 
-// buildAllQueueInfo computes queue info for all partitions from a single jobs list.
-// This replaces per-partition calculateQueueInfo and calculateAllocatedCPUs calls,
-// reducing N+1 API calls to 1.
-func (v *PartitionsView) buildAllQueueInfo(jobs []*dao.Job, partitions []*dao.Partition) map[string]*dao.QueueInfo {
-	// Build CPUs-per-node ratio map for allocated CPU estimation
-	cpusPerNode := make(map[string]float64, len(partitions))
-	for _, p := range partitions {
-		if p.TotalNodes > 0 {
-			cpusPerNode[p.Name] = float64(p.TotalCPUs) / float64(p.TotalNodes)
-		} else {
-			cpusPerNode[p.Name] = 1.0
-		}
-	}
-
-	// Initialize QueueInfo for every partition
-	infoMap := make(map[string]*dao.QueueInfo, len(partitions))
-	for _, p := range partitions {
-		infoMap[p.Name] = &dao.QueueInfo{Partition: p.Name}
-	}
-
-	// Per-partition wait time accumulators
-	type waitAcc struct {
-		total   time.Duration
-		longest time.Duration
-		count   int
-	}
-	waits := make(map[string]*waitAcc, len(partitions))
-
-	now := time.Now()
-
-	for _, job := range jobs {
-		info, ok := infoMap[job.Partition]
-		if !ok {
-			// Job belongs to a partition not in our list — skip
-			continue
-		}
-
-		info.TotalJobs++
-
-		switch job.State {
-		case dao.JobStateRunning, dao.JobStateCompleting:
-			info.RunningJobs++
-			// Accumulate allocated CPUs from running jobs
-			info.AllocatedCPUs += int(float64(job.NodeCount) * cpusPerNode[job.Partition])
-		case dao.JobStatePending:
-			info.PendingJobs++
-			waitTime := now.Sub(job.SubmitTime)
-			w := waits[job.Partition]
-			if w == nil {
-				w = &waitAcc{}
-				waits[job.Partition] = w
-			}
-			w.total += waitTime
-			w.count++
-			if waitTime > w.longest {
-				w.longest = waitTime
-			}
-		}
-	}
-
-	// Compute average/longest wait times
-	for partName, w := range waits {
-		if w.count > 0 {
-			infoMap[partName].AverageWait = w.total / time.Duration(w.count)
-			infoMap[partName].LongestWait = w.longest
-		}
-	}
-
-	return infoMap
-}
-
 // Stop stops the view
 func (v *PartitionsView) Stop() error {
 	return nil
@@ -262,6 +207,8 @@ func (v *PartitionsView) Hints() []string {
 		"[yellow]N[white] Nodes",
 		"[yellow]A[white] Analytics",
 		"[yellow]W[white] Wait Times",
+		"[yellow]u[white] Set State",
+		"[yellow]t[white] Max Time",
 	}
 
 	if v.isAdvancedMode {
@@ -349,6 +296,12 @@ func (v *PartitionsView) handleRuneCommand(r rune) bool {
 	case 'W':
 		v.showWaitTimeAnalytics()
 		return true
+	case 'u':
+		v.showPartitionStateChooser()
+		return true
+	case 't':
+		v.promptPartitionMaxTime()
+		return true
 	case 'S':
 		v.promptSortBy()
 		return true
@@ -453,6 +406,7 @@ func (v *PartitionsView) updateTable() {
 			queueDepth,
 			running,
 			pending,
+			formatPartitionQueueTrend(v.history, partition.Name, 12),
 			avgWait,
 			maxWait,
 			efficiency,
@@ -719,6 +673,31 @@ func (v *PartitionsView) formatPartitionDetails(partition *dao.Partition) string
 		details.WriteString(fmt.Sprintf("[yellow]Nodes:[white] %s\n", nodeList))
 	}
 
+	details.WriteString("\n[teal]Scheduling:[white]\n")
+	details.WriteString(fmt.Sprintf("[yellow]  Priority Tier:[white] %d\n", partition.PriorityTier))
+	details.WriteString(fmt.Sprintf("[yellow]  Priority Job Factor:[white] %d\n", partition.PriorityJobFactor))
+	writePartitionDetail(&details, "OverSubscribe", partition.OverSubscribe)
+	maxNodes := "UNLIMITED"
+	if partition.MaxNodes > 0 {
+		maxNodes = fmt.Sprintf("%d", partition.MaxNodes)
+	}
+	details.WriteString(fmt.Sprintf("[yellow]  Max Nodes per Job:[white] %s\n", maxNodes))
+
+	if len(partition.AllowAccounts)+len(partition.DenyAccounts)+len(partition.AllowGroups)+len(partition.AllowQOS)+len(partition.DenyQOS) > 0 {
+		details.WriteString("\n[teal]Access:[white]\n")
+		writePartitionDetail(&details, "Allow Accounts", strings.Join(partition.AllowAccounts, ", "))
+		writePartitionDetail(&details, "Deny Accounts", strings.Join(partition.DenyAccounts, ", "))
+		writePartitionDetail(&details, "Allow Groups", strings.Join(partition.AllowGroups, ", "))
+		writePartitionDetail(&details, "Allow QOS", strings.Join(partition.AllowQOS, ", "))
+		writePartitionDetail(&details, "Deny QOS", strings.Join(partition.DenyQOS, ", "))
+	}
+
+	if partition.TRES != "" || partition.BillingWeights != "" {
+		details.WriteString("\n[teal]TRES:[white]\n")
+		writePartitionDetail(&details, "Configured", partition.TRES)
+		writePartitionDetail(&details, "Billing Weights", partition.BillingWeights)
+	}
+
 	// Add queue information if available
 	v.mu.RLock()
 	if queueInfo, exists := v.queueInfo[partition.Name]; exists {
@@ -735,11 +714,24 @@ func (v *PartitionsView) formatPartitionDetails(partition *dao.Partition) string
 			details.WriteString(fmt.Sprintf("[yellow]  Longest Wait:[white] %s\n", FormatTimeDuration(queueInfo.LongestWait)))
 		}
 	}
+	if v.history != nil {
+		t := trendSeries{label: "Pending jobs", series: timeseries.PartitionPendingSeries(partition.Name), upIsBad: true}
+		details.WriteString("\n[teal]Queue Trend:[white]\n")
+		details.WriteString("  " + formatTrendLine(v.history, t, 30) + "\n")
+	}
 	v.mu.RUnlock()
 
 	return details.String()
 }
 
+// writePartitionDetail writes an indented detail line, skipping empty values
+func writePartitionDetail(details *strings.Builder, label, value string) {
+	if value == "" {
+		return
+	}
+	details.WriteString(fmt.Sprintf("[yellow]  %s:[white] %s\n", label, value))
+}
+
 // showPartitionJobs shows jobs for the selected partition
 func (v *PartitionsView) showPartitionJobs() {
 	data := v.table.GetSelectedData()
@@ -0,0 +1,314 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/jontk/s9s/internal/config"
+	"github.com/jontk/s9s/internal/dao"
+	"github.com/jontk/s9s/internal/jobtemplate"
+	"github.com/jontk/s9s/internal/pipeline"
+	"github.com/rivo/tview"
+)
+
+// pipelineRefreshInterval is how often the pipeline view reads the states
+// of the stage jobs
+const pipelineRefreshInterval = 5 * time.Second
+
+// PipelineResolver returns the resolver that turns the stages of a pipeline
+// into jobs: the job of the stage template with its parameter values, over
+// the form defaults of cfg. Jobs are named after the pipeline and stage,
+// and run in workingDir unless their template sets a working directory.
+func PipelineResolver(cfg *config.JobSubmissionConfig, spec *pipeline.Spec, workingDir string) pipeline.Resolver {
+	var once sync.Once
+	var templates []*jobtemplate.Template
+	var libErr error
+
+	return func(stage pipeline.Stage) (*dao.JobSubmission, error) {
+		once.Do(func() { templates, libErr = LoadTemplateLibrary(cfg) })
+		t, err := FindTemplate(templates, stage.Template)
+		if err != nil {
+			if libErr != nil {
+				return nil, fmt.Errorf("%w (some templates could not be loaded: %v)", err, libErr)
+			}
+			return nil, err
+		}
+		job, err := TemplateJob(cfg, t, stage.Set)
+		if err != nil {
+			return nil, err
+		}
+		job.Name = spec.Name + "-" + stage.Name
+		if job.WorkingDir == "" {
+			job.WorkingDir = workingDir
+		}
+		if err := ValidateJobSubmission(job); err != nil {
+			return nil, err
+		}
+		return job, nil
+	}
+}
+
+// PipelineGraph draws the stages of a run level by level, each stage in the
+// colour of the state of its job: the stages that wait for nothing, then
+// the stages that wait for those, and so on
+func PipelineGraph(run *pipeline.Run, states map[string]string) string {
+	levels := run.Spec.Levels()
+	parts := make([]string, 0, len(levels))
+	for _, level := range levels {
+		names := make([]string, len(level))
+		for i, name := range level {
+			color := "gray"
+			if state, ok := states[name]; ok {
+				color = dao.GetJobStateColor(state)
+			}
+			names[i] = fmt.Sprintf("[%s]%s[white]", color, name)
+		}
+		if len(names) == 1 {
+			parts = append(parts, names[0])
+		} else {
+			parts = append(parts, "{"+strings.Join(names, ", ")+"}")
+		}
+	}
+	return strings.Join(parts, " ─▶ ")
+}
+
+// PipelineView shows a pipeline run live: its stages as a graph and a
+// table of their jobs and states. The selected stage can be resubmitted
+// together with the stages downstream of it.
+type PipelineView struct {
+	app    *tview.Application
+	pages  *tview.Pages
+	client dao.SlurmClient
+	store  *pipeline.RunStore
+	cfg    *config.JobSubmissionConfig
+
+	run    *pipeline.Run
+	states map[string]string
+	graph  *tview.TextView
+	table  *tview.Table
+	status *tview.TextView
+	stop   chan struct{}
+}
+
+// NewPipelineView creates a pipeline view. Resubmitted stages are saved to
+// store and resolved with the templates of cfg.
+func NewPipelineView(client dao.SlurmClient, app *tview.Application, pages *tview.Pages, store *pipeline.RunStore, cfg *config.JobSubmissionConfig) *PipelineView {
+	return &PipelineView{
+		app:    app,
+		pages:  pages,
+		client: client,
+		store:  store,
+		cfg:    cfg,
+	}
+}
+
+// Show displays run in a modal and refreshes it until it is closed
+func (v *PipelineView) Show(run *pipeline.Run) {
+	v.run = run
+	v.states = make(map[string]string)
+
+	v.graph = tview.NewTextView().SetDynamicColors(true).SetWrap(true)
+	v.graph.SetBorder(true).SetTitle(" Stages ")
+
+	v.table = tview.NewTable().
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	v.table.SetBorder(true).SetTitle(" Jobs ")
+	v.table.SetInputCapture(v.handleKey)
+
+	v.status = tview.NewTextView().SetDynamicColors(true).SetTextAlign(tview.AlignCenter)
+
+	help := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignCenter).
+		SetText("[yellow]Keys:[white] r=Resubmit stage and downstream F5=Refresh Esc=Close")
+
+	content := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(v.graph, 4, 0, false).
+		AddItem(v.table, 0, 1, true).
+		AddItem(v.status, 1, 0, false).
+		AddItem(help, 1, 0, false)
+	content.SetBorder(true).
+		SetTitle(fmt.Sprintf(" Pipeline %s ", run.ID)).
+		SetTitleAlign(tview.AlignCenter)
+
+	v.render()
+	v.pages.AddPage("pipeline", createCenteredModal(content, 110, 30), true, true)
+	v.app.SetFocus(v.table)
+
+	v.stop = make(chan struct{})
+	go v.refreshLoop(v.stop)
+}
+
+// handleKey handles the keys of the stage table
+func (v *PipelineView) handleKey(event *tcell.EventKey) *tcell.EventKey {
+	switch {
+	case event.Key() == tcell.KeyEsc:
+		v.close()
+		return nil
+	case event.Key() == tcell.KeyF5:
+		go v.refresh()
+		return nil
+	case event.Rune() == 'r':
+		if stage := v.selectedStage(); stage != "" {
+			v.confirmResubmit(stage)
+		}
+		return nil
+	}
+	return event
+}
+
+// close removes the modal and stops refreshing
+func (v *PipelineView) close() {
+	if v.stop != nil {
+		close(v.stop)
+		v.stop = nil
+	}
+	v.pages.RemovePage("pipeline")
+}
+
+// refreshLoop refreshes the states until stop is closed
+func (v *PipelineView) refreshLoop(stop chan struct{}) {
+	v.refresh()
+	ticker := time.NewTicker(pipelineRefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			v.refresh()
+		}
+	}
+}
+
+// refresh reads the states of the stage jobs, off the UI goroutine
+func (v *PipelineView) refresh() {
+	v.app.QueueUpdate(func() {
+		run := v.run.Clone()
+		go func() {
+			states := run.States(v.client.Jobs())
+			v.app.QueueUpdateDraw(func() {
+				if v.run.ID != run.ID {
+					return
+				}
+				v.states = states
+				v.status.SetText(fmt.Sprintf("[gray]Updated %s", time.Now().Format("15:04:05")))
+				v.render()
+			})
+		}()
+	})
+}
+
+// render fills the graph and the table from the run and its states
+func (v *PipelineView) render() {
+	v.graph.SetText(PipelineGraph(v.run, v.states))
+
+	selected, _ := v.table.GetSelection()
+	v.table.Clear()
+	for col, header := range []string{"STAGE", "JOB", "STATE", "AFTER", "DEPENDENCY", "ARRAY", "TEMPLATE"} {
+		v.table.SetCell(0, col, tview.NewTableCell(header).
+			SetTextColor(tcell.ColorYellow).
+			SetSelectable(false))
+	}
+
+	order, _ := v.run.Spec.Order()
+	for i, name := range order {
+		stage, _ := v.run.Spec.Stage(name)
+		state := v.states[name]
+		jobID := v.run.Jobs[name]
+		switch {
+		case jobID == "":
+			jobID, state = "-", "NOT SUBMITTED"
+		case state == "":
+			state = "..."
+		}
+		dependency := "-"
+		if len(stage.After) > 0 {
+			dependency = stage.DependencyType()
+		}
+		row := i + 1
+		v.table.SetCell(row, 0, tview.NewTableCell(name))
+		v.table.SetCell(row, 1, tview.NewTableCell(jobID))
+		v.table.SetCell(row, 2, tview.NewTableCell(state).SetTextColor(tcell.GetColor(dao.GetJobStateColor(state))))
+		v.table.SetCell(row, 3, tview.NewTableCell(dashIfBlank(strings.Join(stage.After, ", "))))
+		v.table.SetCell(row, 4, tview.NewTableCell(dependency))
+		v.table.SetCell(row, 5, tview.NewTableCell(dashIfBlank(stage.Array)))
+		v.table.SetCell(row, 6, tview.NewTableCell(stage.Template))
+	}
+	if selected < 1 || selected > len(order) {
+		selected = 1
+	}
+	v.table.Select(selected, 0)
+}
+
+// selectedStage returns the name of the stage of the selected row
+func (v *PipelineView) selectedStage() string {
+	row, _ := v.table.GetSelection()
+	if row < 1 {
+		return ""
+	}
+	return v.table.GetCell(row, 0).Text
+}
+
+// confirmResubmit asks before resubmitting a stage and its downstream stages
+func (v *PipelineView) confirmResubmit(stage string) {
+	stages := v.run.Spec.Descendants(stage)
+	text := fmt.Sprintf("Resubmit stage %s?", stage)
+	if len(stages) > 1 {
+		text = fmt.Sprintf("Resubmit stage %s and the stages after it (%s)?\n\nPending jobs of these stages are cancelled first.",
+			stage, strings.Join(stages[1:], ", "))
+	}
+
+	modal := tview.NewModal().
+		SetText(text).
+		AddButtons([]string{"Resubmit", "Cancel"}).
+		SetDoneFunc(func(buttonIndex int, _ string) {
+			v.pages.RemovePage("pipeline-resubmit")
+			v.app.SetFocus(v.table)
+			if buttonIndex == 0 {
+				v.resubmit(stage)
+			}
+		})
+	v.pages.AddPage("pipeline-resubmit", modal, true, true)
+}
+
+// resubmit resubmits a stage and its downstream stages in the background
+// and saves the new jobs of the run
+func (v *PipelineView) resubmit(stage string) {
+	run := v.run.Clone()
+	resolve := PipelineResolver(v.cfg, &run.Spec, run.WorkingDir)
+	v.status.SetText(fmt.Sprintf("[yellow]Resubmitting %s...", stage))
+
+	go func() {
+		stages, err := run.Resubmit(v.client.Jobs(), resolve, stage)
+		var saveErr error
+		if v.store != nil {
+			saveErr = v.store.Save(run)
+		}
+		v.app.QueueUpdateDraw(func() {
+			v.run = run
+			switch {
+			case err != nil:
+				v.status.SetText(fmt.Sprintf("[red]Resubmitting %s failed: %v", stage, err))
+			case saveErr != nil:
+				v.status.SetText(fmt.Sprintf("[red]Resubmitted %s, but the run could not be saved: %v", strings.Join(stages, ", "), saveErr))
+			default:
+				v.status.SetText(fmt.Sprintf("[green]Resubmitted %s", strings.Join(stages, ", ")))
+			}
+			v.render()
+		})
+		v.refresh()
+	}()
+}
+
+// dashIfBlank returns "-" for an empty table cell
+func dashIfBlank(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,40 @@
+package views
+
+import (
+	"testing"
+
+	"github.com/jontk/s9s/internal/config"
+	"github.com/jontk/s9s/internal/dao"
+	"github.com/jontk/s9s/internal/pipeline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPipelineResolver(t *testing.T) {
+	spec := &pipeline.Spec{Name: "demo", Stages: []pipeline.Stage{{Name: "prep", Template: "Basic Batch Job"}}}
+	cfg := &config.JobSubmissionConfig{TemplateSources: []string{"builtin"}}
+	resolve := PipelineResolver(cfg, spec, "/data/demo")
+
+	job, err := resolve(spec.Stages[0])
+	require.NoError(t, err)
+	assert.Equal(t, "demo-prep", job.Name)
+	assert.Equal(t, "normal", job.Partition)
+	assert.Equal(t, "/data/demo", job.WorkingDir)
+
+	_, err = resolve(pipeline.Stage{Name: "train", Template: "nope"})
+	assert.EqualError(t, err, `template "nope" not found`)
+}
+
+func TestPipelineGraph(t *testing.T) {
+	run := &pipeline.Run{Spec: pipeline.Spec{Name: "demo", Stages: []pipeline.Stage{
+		{Name: "prep", Template: "t"},
+		{Name: "train", Template: "t", After: []string{"prep"}},
+		{Name: "lint", Template: "t", After: []string{"prep"}},
+		{Name: "eval", Template: "t", After: []string{"train", "lint"}},
+	}}}
+	states := map[string]string{"prep": dao.JobStateCompleted, "train": dao.JobStateRunning, "lint": dao.JobStateFailed}
+
+	assert.Equal(t,
+		"[cyan]prep[white] ─▶ {[green]train[white], [red]lint[white]} ─▶ [gray]eval[white]",
+		PipelineGraph(run, states))
+}
@@ -0,0 +1,63 @@
+package views
+
+import (
+	"fmt"
+
+	"github.com/jontk/s9s/internal/ui/components"
+	"github.com/jontk/s9s/internal/ui/filters"
+)
+
+// SavedViewTarget is implemented by views whose filter, sort and columns can
+// be saved as a named view and recalled later
+type SavedViewTarget interface {
+	View
+	CaptureSavedView() filters.SavedView
+	ApplySavedView(saved filters.SavedView) error
+}
+
+// captureTableLayout records the sort and the visible columns of table.
+// Columns are only recorded when some are hidden.
+func captureTableLayout(table *components.Table, saved *filters.SavedView) {
+	if col, ascending := table.GetCurrentSortColumn(); col >= 0 {
+		saved.SortColumn = table.ColumnName(col)
+		saved.SortDescending = !ascending
+	}
+
+	if visible := table.VisibleColumns(); len(visible) < table.ColumnCount() {
+		saved.Columns = visible
+	}
+}
+
+// applyTableLayout restores the sort and visible columns of a saved view
+func applyTableLayout(table *components.Table, saved filters.SavedView) error {
+	if err := table.SetVisibleColumns(saved.Columns); err != nil {
+		return err
+	}
+	if saved.SortColumn == "" {
+		table.SetSort(-1, true)
+		return nil
+	}
+	col := table.ColumnIndex(saved.SortColumn)
+	if col < 0 {
+		return fmt.Errorf("unknown sort column %q", saved.SortColumn)
+	}
+	table.SetSort(col, !saved.SortDescending)
+	return nil
+}
+
+// parseSavedFilter parses the advanced filter of a saved view
+func parseSavedFilter(saved filters.SavedView) (*filters.Filter, error) {
+	filter, err := filters.NewFilterParser().Parse(saved.Filter)
+	if err != nil {
+		return nil, fmt.Errorf("view %s: %w", saved.Name, err)
+	}
+	return filter, nil
+}
+
+// filterSource returns the text of an advanced filter, or "" when none is set
+func filterSource(filter *filters.Filter) string {
+	if filter == nil {
+		return ""
+	}
+	return filter.Source
+}
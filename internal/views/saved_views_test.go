@@ -0,0 +1,65 @@
+package views
+
+import (
+	"testing"
+
+	"github.com/jontk/s9s/internal/dao"
+	"github.com/jontk/s9s/internal/ui/filters"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobsViewSavedViewRoundTrip(t *testing.T) {
+	v := NewJobsView(nil)
+	v.jobs = []*dao.Job{
+		{ID: "1", Name: "train", User: "alice", State: dao.JobStatePending, Partition: "gpu", Priority: 10},
+		{ID: "2", Name: "prep", User: "bob", State: dao.JobStatePending, Partition: "cpu", Priority: 20},
+		{ID: "3", Name: "eval", User: "alice", State: dao.JobStatePending, Partition: "gpu", Priority: 30},
+	}
+
+	saved := filters.SavedView{
+		Name:           "my-gpu-pending",
+		ViewType:       "jobs",
+		Filter:         "state=PENDING and partition=gpu",
+		SortColumn:     "priority",
+		SortDescending: true,
+		Columns:        []string{"id", "user", "state", "priority"},
+	}
+	require.NoError(t, v.ApplySavedView(saved))
+
+	assert.Equal(t, []string{"ID", "User", "State", "Priority"}, v.table.VisibleColumns())
+	rows := v.table.GetFilteredData()
+	require.Len(t, rows, 2)
+	assert.Equal(t, "3", rows[0][0], "sorted by priority, descending")
+	assert.Equal(t, "1", rows[1][0])
+
+	captured := v.CaptureSavedView()
+	assert.Equal(t, "jobs", captured.ViewType)
+	assert.Equal(t, saved.Filter, captured.Filter)
+	assert.Equal(t, "Priority", captured.SortColumn)
+	assert.True(t, captured.SortDescending)
+	assert.Equal(t, []string{"ID", "User", "State", "Priority"}, captured.Columns)
+
+	// An empty view restores the defaults
+	require.NoError(t, v.ApplySavedView(filters.SavedView{Name: "all", ViewType: "jobs"}))
+	reset := v.CaptureSavedView()
+	assert.Empty(t, reset.Filter)
+	assert.Empty(t, reset.SortColumn)
+	assert.Empty(t, reset.Columns)
+	assert.Len(t, v.table.GetFilteredData(), 3)
+}
+
+func TestSavedViewApplyErrors(t *testing.T) {
+	jobs := NewJobsView(nil)
+	assert.Error(t, jobs.ApplySavedView(filters.SavedView{Name: "x", ViewType: "jobs", Columns: []string{"nope"}}))
+	assert.Error(t, jobs.ApplySavedView(filters.SavedView{Name: "x", ViewType: "jobs", SortColumn: "nope"}))
+	assert.Error(t, jobs.ApplySavedView(filters.SavedView{Name: "x", ViewType: "jobs", Filter: "state @ x"}))
+	assert.Equal(t, jobs.table.ColumnCount(), len(jobs.table.VisibleColumns()), "failed views leave the columns alone")
+
+	nodes := NewNodesView(nil)
+	assert.Error(t, nodes.ApplySavedView(filters.SavedView{Name: "x", ViewType: "nodes", GroupBy: "rack"}))
+	require.NoError(t, nodes.ApplySavedView(filters.SavedView{Name: "x", ViewType: "nodes", GroupBy: "partition", Filter: "state=IDLE"}))
+	captured := nodes.CaptureSavedView()
+	assert.Equal(t, "partition", captured.GroupBy)
+	assert.Equal(t, "state=IDLE", captured.Filter)
+}
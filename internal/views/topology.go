@@ -0,0 +1,591 @@
+package views
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/jontk/s9s/internal/config"
+	"github.com/jontk/s9s/internal/dao"
+	"github.com/jontk/s9s/internal/safety"
+	"github.com/jontk/s9s/internal/topology"
+	"github.com/jontk/s9s/internal/ui/components"
+	"github.com/jontk/s9s/internal/ui/styles"
+	"github.com/rivo/tview"
+)
+
+// topologyColorModes are the metrics the grid can be colored by, in the
+// order the c key cycles through them
+var topologyColorModes = []string{"state", "cpu", "memory", "gpu", "load"}
+
+// heatColors color utilization in 20% steps, from idle to saturated
+var heatColors = []tcell.Color{
+	tcell.NewHexColor(0x1a9850),
+	tcell.NewHexColor(0x91cf60),
+	tcell.NewHexColor(0xfee08b),
+	tcell.NewHexColor(0xfc8d59),
+	tcell.NewHexColor(0xd73027),
+}
+
+// TopologyView shows every node as a colored cell, grouped by rack or
+// switch, so a whole machine fits on one screen
+type TopologyView struct {
+	*BaseView
+	client        dao.SlurmClient
+	config        config.TopologyViewConfig
+	grid          *components.NodeGrid
+	legend        *tview.TextView
+	details       *tview.TextView
+	container     *tview.Flex
+	app           *tview.Application
+	pages         *tview.Pages
+	mainStatusBar *components.StatusBar
+
+	mu      sync.RWMutex
+	nodes   map[string]*dao.Node
+	topo    *topology.Topology
+	colorBy string
+}
+
+// NewTopologyView creates a new topology view
+func NewTopologyView(client dao.SlurmClient) *TopologyView {
+	v := &TopologyView{
+		BaseView: NewBaseView("topology", "Topology"),
+		client:   client,
+		nodes:    make(map[string]*dao.Node),
+		colorBy:  "state",
+	}
+
+	v.grid = components.NewNodeGrid()
+	v.grid.SetSelectedFunc(v.drillIntoNode)
+	v.grid.SetChangedFunc(func(string) { v.updateDetails() })
+
+	v.legend = tview.NewTextView().SetDynamicColors(true)
+	v.details = tview.NewTextView().SetDynamicColors(true)
+
+	v.container = tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(v.legend, 1, 0, false).
+		AddItem(v.grid, 0, 1, true).
+		AddItem(v.details, 1, 0, false)
+
+	return v
+}
+
+// SetApp sets the application reference
+func (v *TopologyView) SetApp(app *tview.Application) {
+	v.app = app
+}
+
+// SetPages sets the pages reference for modal handling
+func (v *TopologyView) SetPages(pages *tview.Pages) {
+	v.pages = pages
+}
+
+// SetStatusBar sets the main status bar reference
+func (v *TopologyView) SetStatusBar(statusBar *components.StatusBar) {
+	v.mainStatusBar = statusBar
+}
+
+// SetClient sets the SLURM client for the topology view
+func (v *TopologyView) SetClient(client dao.SlurmClient) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.client = client
+}
+
+// SetTopologyConfig sets where the topology comes from and the initial
+// color mode
+func (v *TopologyView) SetTopologyConfig(cfg config.TopologyViewConfig) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.config = cfg
+	if mode := strings.ToLower(cfg.ColorBy); slices.Contains(topologyColorModes, mode) {
+		v.colorBy = mode
+	}
+}
+
+// Init initializes the topology view
+func (v *TopologyView) Init(ctx context.Context) error {
+	_ = v.BaseView.Init(ctx)
+	return nil
+}
+
+// Render returns the view's main component
+func (v *TopologyView) Render() tview.Primitive {
+	return v.container
+}
+
+// Refresh reloads the nodes and places them in the topology
+func (v *TopologyView) Refresh() error {
+	if !v.refreshing.CompareAndSwap(false, true) {
+		return nil
+	}
+
+	go func() {
+		defer v.refreshing.Store(false)
+
+		v.mu.RLock()
+		client, cfg := v.client, v.config
+		v.mu.RUnlock()
+
+		nodeList, err := client.Nodes().List(&dao.ListNodesOptions{})
+		if err != nil {
+			v.SetLastError(err)
+			return
+		}
+		topo, err := topology.Load(&cfg, nodeList.Nodes)
+		if err != nil {
+			v.SetLastError(err)
+			v.warnTopology(fmt.Sprintf("Topology: %v", err))
+			return
+		}
+
+		if v.app != nil {
+			v.app.QueueUpdateDraw(func() { v.setNodes(nodeList.Nodes, topo) })
+		}
+	}()
+
+	return nil
+}
+
+// setNodes stores the nodes and their placement and redraws the grid
+func (v *TopologyView) setNodes(nodes []*dao.Node, topo *topology.Topology) {
+	byName := make(map[string]*dao.Node, len(nodes))
+	for _, node := range nodes {
+		byName[node.Name] = node
+	}
+
+	v.mu.Lock()
+	v.nodes = byName
+	v.topo = topo
+	v.mu.Unlock()
+
+	v.updateGrid()
+}
+
+// updateGrid rebuilds the grid cells from the nodes and the color mode
+func (v *TopologyView) updateGrid() {
+	v.mu.RLock()
+	groups := v.buildGroupsLocked()
+	v.mu.RUnlock()
+
+	v.grid.SetGroups(groups)
+	v.updateLegend()
+	v.updateDetails()
+}
+
+// buildGroupsLocked turns the topology groups into grid groups
+func (v *TopologyView) buildGroupsLocked() []components.GridGroup {
+	if v.topo == nil {
+		return nil
+	}
+
+	groups := make([]components.GridGroup, 0, len(v.topo.Groups))
+	for _, group := range v.topo.Groups {
+		cells := make([]components.GridCell, len(group.Nodes))
+		for i, name := range group.Nodes {
+			cells[i] = topologyCell(name, v.nodes[name], v.colorBy)
+		}
+		groups = append(groups, components.GridGroup{Label: group.Label(), Cells: cells})
+	}
+	return groups
+}
+
+// topologyCell returns the grid cell of a node. The glyph shows whether
+// the node is usable and the color shows the selected metric; nodes the
+// topology lists but SLURM does not report are shown as unknown.
+func topologyCell(name string, node *dao.Node, mode string) components.GridCell {
+	if node == nil {
+		return components.GridCell{Name: name, Glyph: '?', Color: tcell.ColorGray}
+	}
+
+	state := node.ParsedState()
+	glyph := '■'
+	switch {
+	case state.IsDown():
+		glyph = 'x'
+	case state.IsDrain():
+		glyph = '▒'
+	case state.IsPoweredDown():
+		glyph = '·'
+	}
+
+	if mode == "state" {
+		return components.GridCell{Name: name, Glyph: glyph, Color: tcell.GetColor(state.Color())}
+	}
+	return components.GridCell{Name: name, Glyph: glyph, Color: heatColor(nodeHeat(node, mode))}
+}
+
+// nodeHeat returns the utilization of a node in percent for a color mode,
+// or -1 when the node does not report it
+func nodeHeat(node *dao.Node, mode string) float64 {
+	switch mode {
+	case "cpu":
+		if node.CPUsTotal > 0 {
+			return 100 * float64(node.CPUsAllocated) / float64(node.CPUsTotal)
+		}
+	case "memory":
+		if node.MemoryTotal > 0 {
+			return 100 * float64(node.MemoryAllocated) / float64(node.MemoryTotal)
+		}
+	case "gpu":
+		if total, allocated := node.GPUCounts(); total > 0 {
+			return 100 * float64(allocated) / float64(total)
+		}
+	case "load":
+		if node.CPUsTotal > 0 && node.CPULoad >= 0 {
+			return 100 * node.CPULoad / float64(node.CPUsTotal)
+		}
+	}
+	return -1
+}
+
+// heatColor returns the color of a utilization percentage
+func heatColor(pct float64) tcell.Color {
+	if pct < 0 {
+		return tcell.ColorGray
+	}
+	step := int(pct / 20)
+	return heatColors[max(min(step, len(heatColors)-1), 0)]
+}
+
+// updateLegend shows the topology source, color mode and marks
+func (v *TopologyView) updateLegend() {
+	v.mu.RLock()
+	mode := v.colorBy
+	source, groups := "", 0
+	if v.topo != nil {
+		source, groups = v.topo.Source, len(v.topo.Groups)
+	}
+	nodes := len(v.nodes)
+	v.mu.RUnlock()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "[yellow]Source:[white] %s (%d groups, %d nodes)  [yellow]Color:[white] %s  ", source, groups, nodes, mode)
+	if mode == "state" {
+		b.WriteString("[green]■[white] idle [blue]■[white] alloc [yellow]■[white] reserved [red]■[white] down/drain [gray]■[white] off")
+	} else {
+		for i, color := range heatColors {
+			fmt.Fprintf(&b, "[#%06x]■[white]%d%% ", color.Hex(), i*20)
+		}
+		b.WriteString("[gray]■[white] n/a")
+	}
+	b.WriteString("  [white]x down ▒ drain · off ? unknown")
+	if marked := len(v.grid.Marked()); marked > 0 {
+		fmt.Fprintf(&b, "  [yellow]%d marked[white]", marked)
+	}
+	v.legend.SetText(b.String())
+}
+
+// updateDetails describes the node under the cursor
+func (v *TopologyView) updateDetails() {
+	name := v.grid.Current()
+
+	v.mu.RLock()
+	node := v.nodes[name]
+	var group *topology.Group
+	if v.topo != nil {
+		group = v.topo.GroupOf(name)
+	}
+	v.mu.RUnlock()
+
+	v.details.SetText(formatTopologyNode(name, node, group))
+}
+
+// formatTopologyNode formats the one-line summary of a node
+func formatTopologyNode(name string, node *dao.Node, group *topology.Group) string {
+	if name == "" {
+		return "[gray]No nodes[white]"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "[yellow]%s[white]", name)
+	if group != nil {
+		fmt.Fprintf(&b, " in %s", group.Label())
+	}
+	if node == nil {
+		b.WriteString("  [gray]not reported by SLURM[white]")
+		return b.String()
+	}
+
+	state := node.ParsedState()
+	fmt.Fprintf(&b, "  [%s]%s[white]  CPU %d/%d  Mem %s/%s",
+		state.Color(), node.State, node.CPUsAllocated, node.CPUsTotal,
+		FormatMemory(node.MemoryAllocated), FormatMemory(node.MemoryTotal))
+	if total, allocated := node.GPUCounts(); total > 0 {
+		fmt.Fprintf(&b, "  GPU %d/%d", allocated, total)
+	}
+	if node.CPULoad >= 0 {
+		fmt.Fprintf(&b, "  Load %.1f", node.CPULoad)
+	}
+	if node.Reason != "" {
+		fmt.Fprintf(&b, "  [red]%s[white]", node.Reason)
+	}
+	return b.String()
+}
+
+// Stop stops the view
+func (v *TopologyView) Stop() error {
+	return nil
+}
+
+// Hints returns keyboard hints
+func (v *TopologyView) Hints() []string {
+	return []string{
+		"[yellow]Enter[white] Node",
+		"[yellow]Space[white] Mark",
+		"[yellow]a[white] Mark Group",
+		"[yellow]c[white] Color",
+		"[yellow]d[white] Drain",
+		"[yellow]r[white] Resume",
+		"[yellow][ ][white] Group",
+	}
+}
+
+// OnKey handles keyboard events; grid navigation is left to the grid
+func (v *TopologyView) OnKey(event *tcell.EventKey) *tcell.EventKey {
+	if v.pages != nil && v.pages.GetPageCount() > 1 {
+		return event
+	}
+
+	switch event.Key() {
+	case tcell.KeyEsc:
+		if len(v.grid.Marked()) > 0 {
+			v.grid.ClearMarks()
+			v.updateLegend()
+			return nil
+		}
+		return event
+	case tcell.KeyRune:
+		switch event.Rune() {
+		case 'c':
+			v.cycleColorMode()
+			return nil
+		case 'a':
+			v.grid.ToggleGroupMarks()
+			v.updateLegend()
+			return nil
+		case ' ':
+			v.grid.ToggleMark()
+			v.updateLegend()
+			return nil
+		case 'd':
+			v.promptDrain()
+			return nil
+		case 'r':
+			v.confirmResume()
+			return nil
+		case 'R':
+			go func() { _ = v.Refresh() }()
+			return nil
+		}
+	}
+	return event
+}
+
+// OnFocus handles focus events
+func (v *TopologyView) OnFocus() error {
+	v.SetFocused(true)
+	if v.app != nil {
+		v.app.SetFocus(v.grid)
+	}
+	if !v.IsInitialized() {
+		v.SetInitialized(true)
+		go func() { _ = v.Refresh() }()
+	}
+	return nil
+}
+
+// OnLoseFocus handles loss of focus
+func (v *TopologyView) OnLoseFocus() error {
+	v.SetFocused(false)
+	return nil
+}
+
+// cycleColorMode switches to the next color mode
+func (v *TopologyView) cycleColorMode() {
+	v.mu.Lock()
+	i := slices.Index(topologyColorModes, v.colorBy)
+	v.colorBy = topologyColorModes[(i+1)%len(topologyColorModes)]
+	v.mu.Unlock()
+	v.updateGrid()
+}
+
+// drillIntoNode shows a node in the nodes view
+func (v *TopologyView) drillIntoNode(name string) {
+	v.SwitchToView("nodes")
+	if v.viewMgr == nil {
+		return
+	}
+	if nv, err := v.viewMgr.GetView("nodes"); err == nil {
+		if nodesView, ok := nv.(*NodesView); ok {
+			nodesView.SetFilterText(name)
+		}
+	}
+}
+
+// actionTargets returns the marked nodes, or the node under the cursor
+// when none are marked
+func (v *TopologyView) actionTargets() []string {
+	if marked := v.grid.Marked(); len(marked) > 0 {
+		return marked
+	}
+	if name := v.grid.Current(); name != "" {
+		return []string{name}
+	}
+	return nil
+}
+
+// promptDrain asks for a drain reason and then confirms draining the
+// target nodes
+func (v *TopologyView) promptDrain() {
+	targets := v.actionTargets()
+	if len(targets) == 0 || v.pages == nil {
+		return
+	}
+
+	input := styles.NewStyledInputField().
+		SetLabel("Drain reason: ").
+		SetFieldWidth(40)
+	input.SetDoneFunc(func(key tcell.Key) {
+		v.pages.RemovePage("topology-drain")
+		if key != tcell.KeyEnter {
+			v.focusGrid()
+			return
+		}
+		reason := strings.TrimSpace(input.GetText())
+		if reason == "" {
+			reason = "Manual drain"
+		}
+		// The safety policy looks up every target, so it is checked in the
+		// background with the drain itself
+		check := func() error { return safety.CheckDrain(v.client, targets) }
+		v.confirmNodeAction(fmt.Sprintf("Drain %s?\n\nReason: %s", describeNodes(targets), reason), "Drain",
+			targets, "drained", check, func(nodes dao.NodeManager, name string) error { return nodes.Drain(name, reason) })
+	})
+	input.SetBorder(true).
+		SetTitle(fmt.Sprintf(" Drain %s ", describeNodes(targets))).
+		SetTitleAlign(tview.AlignCenter)
+
+	modal := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(input, 3, 0, true).
+			AddItem(nil, 0, 1, false), 60, 0, true).
+		AddItem(nil, 0, 1, false)
+	v.pages.AddPage("topology-drain", modal, true, true)
+	if v.app != nil {
+		v.app.SetFocus(input)
+	}
+}
+
+// confirmResume confirms resuming the target nodes
+func (v *TopologyView) confirmResume() {
+	targets := v.actionTargets()
+	if len(targets) == 0 {
+		return
+	}
+	v.confirmNodeAction(fmt.Sprintf("Resume %s?", describeNodes(targets)), "Resume",
+		targets, "resumed", nil, func(nodes dao.NodeManager, name string) error { return nodes.Resume(name) })
+}
+
+// confirmNodeAction asks for confirmation before running action on targets
+func (v *TopologyView) confirmNodeAction(question, button string, targets []string, done string, check func() error, action func(dao.NodeManager, string) error) {
+	if v.pages == nil {
+		return
+	}
+	modal := tview.NewModal().
+		SetText(question).
+		AddButtons([]string{button, "Cancel"}).
+		SetDoneFunc(func(buttonIndex int, _ string) {
+			v.pages.RemovePage("topology-confirm")
+			v.focusGrid()
+			if buttonIndex == 0 {
+				v.runNodeAction(targets, done, check, action)
+			}
+		})
+	modal.SetBorder(true).
+		SetTitle(fmt.Sprintf(" Confirm %s ", button)).
+		SetTitleAlign(tview.AlignCenter)
+	v.pages.AddPage("topology-confirm", modal, true, true)
+}
+
+// runNodeAction applies action to every target in the background, reports
+// how many succeeded and refreshes the grid. If check, when given, fails,
+// nothing is applied and its error is shown.
+func (v *TopologyView) runNodeAction(targets []string, done string, check func() error, action func(dao.NodeManager, string) error) {
+	go func() {
+		if check != nil {
+			if err := check(); err != nil {
+				if v.app != nil {
+					v.app.QueueUpdateDraw(func() {
+						if v.mainStatusBar != nil {
+							v.mainStatusBar.Error(err.Error())
+						}
+					})
+				}
+				return
+			}
+		}
+
+		nodes := v.client.Nodes()
+		var failures []string
+		for _, name := range targets {
+			if err := action(nodes, name); err != nil {
+				failures = append(failures, fmt.Sprintf("%s: %v", name, err))
+			}
+		}
+
+		if v.app != nil {
+			v.app.QueueUpdateDraw(func() {
+				if len(failures) == 0 {
+					v.grid.ClearMarks()
+				}
+				if v.mainStatusBar == nil {
+					return
+				}
+				succeeded := len(targets) - len(failures)
+				if len(failures) > 0 {
+					v.mainStatusBar.Error(fmt.Sprintf("%d of %d node(s) %s; first error %s", succeeded, len(targets), done, failures[0]))
+					return
+				}
+				v.mainStatusBar.Success(fmt.Sprintf("%d node(s) %s", succeeded, done))
+			})
+		}
+
+		time.Sleep(500 * time.Millisecond)
+		_ = v.Refresh()
+	}()
+}
+
+// focusGrid returns focus to the grid
+func (v *TopologyView) focusGrid() {
+	if v.app != nil {
+		v.app.SetFocus(v.grid)
+	}
+}
+
+// warnTopology shows a topology related warning
+func (v *TopologyView) warnTopology(message string) {
+	if v.mainStatusBar != nil {
+		v.mainStatusBar.Warning(message)
+	}
+}
+
+// describeNodes names a node list briefly, e.g. "node001" or "40 nodes
+// (node001 … node040)"
+func describeNodes(names []string) string {
+	switch len(names) {
+	case 0:
+		return "no nodes"
+	case 1:
+		return names[0]
+	}
+	return fmt.Sprintf("%d nodes (%s … %s)", len(names), names[0], names[len(names)-1])
+}
@@ -0,0 +1,74 @@
+package views
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jontk/s9s/internal/timeseries"
+)
+
+// trendSparklineWindow is the span of history drawn in sparklines
+const trendSparklineWindow = time.Hour
+
+// trendSeries describes how a history series is labelled and colored
+type trendSeries struct {
+	label     string
+	series    string
+	unit      string
+	precision int
+	upIsBad   bool // Rising values are drawn red instead of green
+}
+
+// clusterTrends are the cluster-wide series shown by the dashboard and
+// health views
+var clusterTrends = []trendSeries{
+	{label: "Running jobs", series: timeseries.SeriesJobsRunning},
+	{label: "Pending jobs", series: timeseries.SeriesJobsPending, upIsBad: true},
+	{label: "Down nodes", series: timeseries.SeriesNodesDown, upIsBad: true},
+	{label: "CPU alloc", series: timeseries.SeriesCPUAllocated, unit: "%", precision: 1},
+	{label: "Memory alloc", series: timeseries.SeriesMemoryAllocated, unit: "%", precision: 1},
+}
+
+// formatTrendLine renders one series as label, sparkline of the last hour,
+// current value and the 1h and 24h change
+func formatTrendLine(history *timeseries.Store, t trendSeries, width int) string {
+	latest, ok := history.Latest(t.series)
+	if !ok {
+		return fmt.Sprintf("%-13s [gray]no data yet[white]", t.label)
+	}
+	spark := timeseries.Sparkline(history.Values(t.series, latest.Time.Add(-trendSparklineWindow)), width)
+	return fmt.Sprintf("%-13s [teal]%-*s[white] %8s  1h %s  24h %s",
+		t.label, width, spark, fmt.Sprintf("%.*f%s", t.precision, latest.Value, t.unit),
+		formatTrendDelta(history, t, time.Hour), formatTrendDelta(history, t, 24*time.Hour))
+}
+
+// formatTrendDelta renders the change of a series over d, colored by
+// whether the change is good or bad. Shorter history yields the change
+// over the history available.
+func formatTrendDelta(history *timeseries.Store, t trendSeries, d time.Duration) string {
+	delta, ok := history.Delta(t.series, d)
+	if !ok {
+		return "[gray]n/a[white]"
+	}
+	color := "white"
+	switch {
+	case delta > 0 && t.upIsBad, delta < 0 && !t.upIsBad:
+		color = "red"
+	case delta != 0:
+		color = "green"
+	}
+	return fmt.Sprintf("[%s]%s%s[white]", color, timeseries.FormatDelta(delta, t.precision), t.unit)
+}
+
+// formatPartitionQueueTrend renders the queue depth sparkline of a partition
+func formatPartitionQueueTrend(history *timeseries.Store, partition string, width int) string {
+	if history == nil {
+		return ""
+	}
+	series := timeseries.PartitionPendingSeries(partition)
+	latest, ok := history.Latest(series)
+	if !ok {
+		return ""
+	}
+	return timeseries.Sparkline(history.Values(series, latest.Time.Add(-trendSparklineWindow)), width)
+}
@@ -4,6 +4,8 @@ package slurm
 import (
 	"fmt"
 	"math/rand"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -144,6 +146,15 @@ func (m *MockClient) populatePartitions() {
 		MaxTime:     "7-00:00:00",
 		QOS:         []string{"normal", "high", "low"},
 		Nodes:       []string{"node[001-100]"},
+
+		PriorityTier:      1,
+		PriorityJobFactor: 1,
+		AllowAccounts:     []string{"ALL"},
+		AllowGroups:       []string{"ALL"},
+		AllowQOS:          []string{"normal", "high", "low"},
+		OverSubscribe:     "NO",
+		BillingWeights:    "CPU=1.0,Mem=0.25G",
+		TRES:              "cpu=3200,mem=12800G,node=100,billing=3200",
 	}
 
 	m.partitions["gpu"] = &dao.Partition{
@@ -155,6 +166,17 @@ func (m *MockClient) populatePartitions() {
 		MaxTime:     "2-00:00:00",
 		QOS:         []string{"gpu-normal", "gpu-high"},
 		Nodes:       []string{"gpu[001-020]"},
+
+		PriorityTier:      2,
+		PriorityJobFactor: 10,
+		AllowAccounts:     []string{"gpu-research", "ml-team"},
+		AllowGroups:       []string{"ALL"},
+		AllowQOS:          []string{"gpu-normal", "gpu-high"},
+		DenyQOS:           []string{"low"},
+		OverSubscribe:     "NO",
+		MaxNodes:          4,
+		BillingWeights:    "CPU=1.0,Mem=0.25G,GRES/gpu=8.0",
+		TRES:              "cpu=640,mem=5120G,node=20,billing=1920,gres/gpu=80",
 	}
 
 	m.partitions["debug"] = &dao.Partition{
@@ -166,6 +188,17 @@ func (m *MockClient) populatePartitions() {
 		MaxTime:     "1:00:00",
 		QOS:         []string{"debug"},
 		Nodes:       []string{"debug[001-010]"},
+
+		PriorityTier:      3,
+		PriorityJobFactor: 100,
+		AllowAccounts:     []string{"ALL"},
+		DenyAccounts:      []string{"guest"},
+		AllowGroups:       []string{"ALL"},
+		AllowQOS:          []string{"debug"},
+		OverSubscribe:     "FORCE:4",
+		MaxNodes:          2,
+		BillingWeights:    "CPU=0.5",
+		TRES:              "cpu=320,mem=1280G,node=10,billing=160",
 	}
 }
 
@@ -189,14 +222,19 @@ func (m *MockClient) populateComputeNodes() {
 			MemoryAllocated: m.getComputeNodeMemoryAllocated(state),
 			MemoryFree:      m.getComputeNodeMemoryFree(state),
 			Features:        []string{"avx2", "sse4.2"},
+			Topology:        fmt.Sprintf("default:core:leaf%02d", (i-1)/20+1),
 		}
 	}
 
-	// Add some down nodes
-	m.nodes["node098"].State = dao.NodeStateDown
+	// Add nodes in compound states
+	m.nodes["node089"].State = "IDLE+DRAIN"
+	m.nodes["node089"].Reason = "Maintenance"
+	m.nodes["node090"].State = "IDLE+CLOUD+POWERED_DOWN"
+	m.nodes["node096"].State = "MIXED+COMPLETING"
+	m.nodes["node098"].State = "DOWN+NOT_RESPONDING"
 	m.nodes["node098"].Reason = "Hardware failure"
-	m.nodes["node099"].State = dao.NodeStateDrain
-	m.nodes["node099"].Reason = "Maintenance"
+	m.nodes["node099"].State = "MIXED+DRAIN"
+	m.nodes["node099"].Reason = "Kernel update"
 }
 
 func (m *MockClient) getComputeNodeCPUsAllocated(state string) int {
@@ -245,9 +283,23 @@ func (m *MockClient) getComputeNodeMemoryFree(state string) int64 {
 
 func (m *MockClient) populateGPUNodes() {
 	for i := 1; i <= 20; i++ {
+		// gpu001-012 carry 8 A100s, gpu013-020 carry 4 V100s
+		gpuType, gpuCount := "a100", 8
+		if i > 12 {
+			gpuType, gpuCount = "v100", 4
+		}
+		// Every third node has half of its GPUs in use
+		gpusUsed, usedIdx := 0, "N/A"
+		state := dao.NodeStateIdle
+		if i%3 == 0 {
+			gpusUsed = gpuCount / 2
+			usedIdx = fmt.Sprintf("0-%d", gpusUsed-1)
+			state = dao.NodeStateMixed
+		}
+
 		m.nodes[fmt.Sprintf("gpu%03d", i)] = &dao.Node{
 			Name:            fmt.Sprintf("gpu%03d", i),
-			State:           dao.NodeStateIdle,
+			State:           state,
 			Partitions:      []string{"gpu"},
 			CPUsTotal:       32,
 			CPUsAllocated:   0,
@@ -255,7 +307,12 @@ func (m *MockClient) populateGPUNodes() {
 			MemoryTotal:     256 * 1024, // 256GB
 			MemoryAllocated: 0,
 			MemoryFree:      256 * 1024,
-			Features:        []string{"gpu", "cuda", "avx2"},
+			Features:        []string{"gpu", "cuda", "avx2", gpuType},
+			GRES: dao.ParseGRES(
+				fmt.Sprintf("gpu:%s:%d(S:0-1)", gpuType, gpuCount),
+				fmt.Sprintf("gpu:%s:%d(IDX:%s)", gpuType, gpusUsed, usedIdx),
+			),
+			Topology: fmt.Sprintf("default:core:gpu-leaf%02d", (i-1)/10+1),
 		}
 	}
 }
@@ -293,6 +350,7 @@ func (m *MockClient) populateJobs() {
 		}
 
 		m.setJobStateDetails(job, state)
+		m.setJobUsage(job)
 		m.jobs[job.ID] = job
 	}
 }
@@ -317,6 +375,25 @@ func (m *MockClient) setJobStateDetails(job *dao.Job, state string) {
 	}
 }
 
+// setJobUsage fills in accounting usage for jobs that have started, with
+// efficiencies spread across the whole range
+func (m *MockClient) setJobUsage(job *dao.Job) {
+	if job.StartTime == nil {
+		return
+	}
+	end := time.Now()
+	if job.EndTime != nil {
+		end = *job.EndTime
+	}
+	elapsed := end.Sub(*job.StartTime)
+
+	job.CPUs = job.NodeCount * 8
+	job.MemoryPerNode = 16384
+	job.TRESAlloc = fmt.Sprintf("cpu=%d,mem=%dG,node=%d", job.CPUs, job.NodeCount*16, job.NodeCount)
+	job.TotalCPU = time.Duration(float64(elapsed) * float64(job.CPUs) * (0.05 + rand.Float64()*0.95))
+	job.MaxRSS = int64(float64(job.MemoryPerNode*int64(job.NodeCount)) * (0.05 + rand.Float64()*0.9))
+}
+
 func (m *MockClient) populateReservations() {
 	m.reservations["maint-001"] = &dao.Reservation{
 		Name:      "maint-001",
@@ -595,6 +672,8 @@ func (m *mockJobManager) filterJobs(opts *dao.ListJobsOptions) []*dao.Job {
 			jobs = append(jobs, job)
 		}
 	}
+	// Keep a stable order so consecutive pages do not overlap
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].ID < jobs[j].ID })
 	return jobs
 }
 
@@ -614,6 +693,9 @@ func (m *mockJobManager) jobMatchesFilters(job *dao.Job, opts *dao.ListJobsOptio
 	if len(opts.Accounts) > 0 && !contains(opts.Accounts, job.Account) {
 		return false
 	}
+	if opts.ArrayJobID != "" && job.ArrayJobID != opts.ArrayJobID {
+		return false
+	}
 	return true
 }
 
@@ -621,8 +703,8 @@ func (m *mockJobManager) calculateLimitOffset(opts *dao.ListJobsOptions, jobCoun
 	start := 0
 	end := jobCount
 	if opts != nil {
-		if opts.Offset > 0 && opts.Offset < jobCount {
-			start = opts.Offset
+		if opts.Offset > 0 {
+			start = min(opts.Offset, jobCount)
 		}
 		if opts.Limit > 0 && start+opts.Limit < end {
 			end = start + opts.Limit
@@ -832,7 +914,7 @@ func (m *mockNodeManager) List(opts *dao.ListNodesOptions) (*dao.NodeList, error
 	for _, node := range m.client.nodes {
 		// Apply filters
 		if opts != nil {
-			if len(opts.States) > 0 && !contains(opts.States, node.State) {
+			if len(opts.States) > 0 && !matchesNodeState(node, opts.States) {
 				continue
 			}
 			if len(opts.Partitions) > 0 && !hasCommonElement(opts.Partitions, node.Partitions) {
@@ -870,7 +952,7 @@ func (m *mockNodeManager) Drain(name, reason string) error {
 		return fmt.Errorf("node %s not found", name)
 	}
 
-	node.State = dao.NodeStateDrain
+	node.State = node.ParsedState().WithFlag(dao.NodeFlagDrain).String()
 	node.Reason = reason
 	now := time.Now()
 	node.ReasonTime = &now
@@ -887,8 +969,12 @@ func (m *mockNodeManager) Resume(name string) error {
 		return fmt.Errorf("node %s not found", name)
 	}
 
-	if node.State == dao.NodeStateDrain || node.State == dao.NodeStateDraining {
-		node.State = dao.NodeStateIdle
+	if state := node.ParsedState(); state.IsDrain() {
+		state = state.WithoutFlag(dao.NodeFlagDrain)
+		if state.Base == "" {
+			state.Base = dao.NodeStateIdle
+		}
+		node.State = state.String()
 		node.Reason = ""
 		node.ReasonTime = nil
 	}
@@ -941,6 +1027,50 @@ func (m *mockPartitionManager) Get(name string) (*dao.Partition, error) {
 	return partition, nil
 }
 
+func (m *mockPartitionManager) SetState(name, state string) error {
+	m.client.simulateDelay()
+	state = strings.ToUpper(strings.TrimSpace(state))
+	if !dao.IsSettablePartitionState(state) {
+		return fmt.Errorf("invalid partition state %q", state)
+	}
+
+	m.client.mu.Lock()
+	defer m.client.mu.Unlock()
+
+	partition, exists := m.client.partitions[name]
+	if !exists {
+		return fmt.Errorf("partition %s not found", name)
+	}
+
+	updated := *partition
+	updated.State = state
+	m.client.partitions[name] = &updated
+	return nil
+}
+
+func (m *mockPartitionManager) SetMaxTime(name, limit string) error {
+	m.client.simulateDelay()
+	if _, err := dao.MaxTimeMinutes(limit); err != nil {
+		return fmt.Errorf("invalid max time: %w", err)
+	}
+
+	m.client.mu.Lock()
+	defer m.client.mu.Unlock()
+
+	partition, exists := m.client.partitions[name]
+	if !exists {
+		return fmt.Errorf("partition %s not found", name)
+	}
+
+	updated := *partition
+	updated.MaxTime = strings.TrimSpace(limit)
+	if dao.IsUnlimitedTimeLimit(limit) {
+		updated.MaxTime = "UNLIMITED"
+	}
+	m.client.partitions[name] = &updated
+	return nil
+}
+
 // mockReservationManager implements dao.ReservationManager
 type mockReservationManager struct {
 	client *MockClient
@@ -1016,12 +1146,12 @@ func (m *mockInfoManager) GetStats() (*dao.ClusterMetrics, error) {
 		totalMemory += node.MemoryTotal
 		usedMemory += node.MemoryAllocated
 
-		switch node.State {
-		case dao.NodeStateAllocated, dao.NodeStateMixed:
+		switch node.ParsedState().Summary() {
+		case dao.NodeStateAllocated, dao.NodeStateMixed, dao.NodeFlagCompleting:
 			activeNodes++
 		case dao.NodeStateIdle:
 			idleNodes++
-		case dao.NodeStateDown, dao.NodeStateDrain, dao.NodeStateDraining:
+		case dao.NodeStateDown, dao.NodeStateDrain, dao.NodeStateDraining, dao.NodeFlagFail:
 			downNodes++
 		}
 	}
@@ -1052,6 +1182,17 @@ func (m *mockInfoManager) GetStats() (*dao.ClusterMetrics, error) {
 
 // Helper functions
 
+// matchesNodeState reports whether the node matches any of the state filters
+func matchesNodeState(node *dao.Node, states []string) bool {
+	state := node.ParsedState()
+	for _, s := range states {
+		if state.Matches(s) {
+			return true
+		}
+	}
+	return false
+}
+
 func contains(slice []string, item string) bool {
 	for _, s := range slice {
 		if s == item {
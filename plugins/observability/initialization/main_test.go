@@ -0,0 +1,28 @@
+package initialization
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+// TestMain runs the tests in a temporary directory, as the default
+// configuration writes its debug log and secrets under ./data
+func TestMain(m *testing.M) {
+	os.Exit(runInTempDir(m))
+}
+
+func runInTempDir(m *testing.M) int {
+	dir, err := os.MkdirTemp("", "s9s-observability-test")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	if err := os.Chdir(dir); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return m.Run()
+}
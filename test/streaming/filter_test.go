@@ -328,6 +328,10 @@ func TestStreamSearcher(t *testing.T) {
 
 		highlighted := searcher.GetHighlightedLine("This is an error message", "yellow")
 		assert.Contains(t, highlighted, "[yellow]error[white]")
+
+		// Plain text around the match is escaped for display
+		highlighted = searcher.GetHighlightedLine("[rank0] error", "yellow")
+		assert.Equal(t, "[rank0[] [yellow]error[white]", highlighted)
 	})
 }
 
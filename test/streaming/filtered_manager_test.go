@@ -0,0 +1,71 @@
+package streaming_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jontk/s9s/internal/dao"
+	"github.com/jontk/s9s/internal/streaming"
+)
+
+func TestFilteredStreamManager_KeepsOutputColors(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out_920.log")
+	if err := os.WriteFile(path, nil, 0600); err != nil {
+		t.Fatalf("Failed to create output: %v", err)
+	}
+	job := &dao.Job{ID: "920", State: dao.JobStateRunning, StdOut: path}
+
+	config := streaming.DefaultSlurmConfig()
+	config.FileCheckInterval = 50 * time.Millisecond
+	fsm, err := streaming.NewFilteredStreamManager(&fakeSlurmClient{jobs: []*dao.Job{job}}, nil, nil, config, filepath.Join(dir, "filters.json"))
+	if err != nil {
+		t.Fatalf("NewFilteredStreamManager failed: %v", err)
+	}
+	defer func() { _ = fsm.Close() }()
+
+	if err := fsm.StartFilteredStream("920", "stdout"); err != nil {
+		t.Fatalf("StartFilteredStream failed: %v", err)
+	}
+	if err := fsm.SetQuickFilter("error", streaming.FilterTypeKeyword); err != nil {
+		t.Fatalf("SetQuickFilter failed: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := fsm.StreamWithContext(ctx, "920", "stdout")
+	if err != nil {
+		t.Fatalf("StreamWithContext failed: %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte("\x1b[31merror\x1b[0m: disk full\nstep 1 [ok]\n"), 0600); err != nil {
+		t.Fatalf("Failed to write output: %v", err)
+	}
+
+	const want = "[maroon:-:-]error[-:-:-]: disk full"
+	select {
+	case event := <-events:
+		if lines := streaming.EventLines(event); len(lines) != 1 || lines[0] != want {
+			t.Errorf("Expected the filtered event to keep colors, got %q", lines)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for a filtered event")
+	}
+
+	// Reloading the filtered lines keeps the colors too
+	fsm.ClearFilters()
+	lines, err := fsm.GetFilteredContent("920", "stdout", true)
+	if err != nil {
+		t.Fatalf("GetFilteredContent failed: %v", err)
+	}
+	if want := []string{want, "step 1 [ok[]"}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("Expected %q, got %q", want, lines)
+	}
+	plain, _ := fsm.GetFilteredContent("920", "stdout", false)
+	if want := []string{"error: disk full", "step 1 [ok]"}; !reflect.DeepEqual(plain, want) {
+		t.Errorf("Expected plain lines %q, got %q", want, plain)
+	}
+}
@@ -0,0 +1,217 @@
+package streaming_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jontk/s9s/internal/dao"
+	"github.com/jontk/s9s/internal/streaming"
+)
+
+// fakeSlurmClient serves a fixed set of jobs and remembers the list options
+// it was asked with
+type fakeSlurmClient struct {
+	dao.SlurmClient
+	jobs []*dao.Job
+
+	mu     sync.Mutex
+	listed []dao.ListJobsOptions
+}
+
+func (c *fakeSlurmClient) Jobs() dao.JobManager {
+	return &fakeJobManager{client: c, jobs: c.jobs}
+}
+
+type fakeJobManager struct {
+	dao.JobManager
+	client *fakeSlurmClient
+	jobs   []*dao.Job
+}
+
+func (m *fakeJobManager) List(opts *dao.ListJobsOptions) (*dao.JobList, error) {
+	if opts != nil {
+		m.client.mu.Lock()
+		m.client.listed = append(m.client.listed, *opts)
+		m.client.mu.Unlock()
+	}
+	return &dao.JobList{Jobs: m.jobs, Total: len(m.jobs)}, nil
+}
+
+func (m *fakeJobManager) Get(id string) (*dao.Job, error) {
+	for _, job := range m.jobs {
+		if job.ID == id {
+			return job, nil
+		}
+	}
+	return nil, fmt.Errorf("job %s not found", id)
+}
+
+func TestPathResolver_ResolveArrayTasks(t *testing.T) {
+	dir := t.TempDir()
+	client := &fakeSlurmClient{jobs: []*dao.Job{
+		{ID: "102", ArrayJobID: "100", ArrayTaskID: "10", StdOut: filepath.Join(dir, "out_%A_%a.log")},
+		{ID: "100", ArrayJobID: "100", ArrayTaskID: "2", StdOut: filepath.Join(dir, "out_%A_%a.log")},
+		{ID: "101", ArrayJobID: "100", ArrayTaskID: "3", StdOut: filepath.Join(dir, "out_%A_%a.log")},
+		{ID: "200", StdOut: filepath.Join(dir, "other.log")},
+	}}
+
+	resolver := streaming.NewPathResolver(client, nil)
+	sources, err := resolver.ResolveTaskOutputPaths("101", "stdout")
+	if err != nil {
+		t.Fatalf("ResolveTaskOutputPaths failed: %v", err)
+	}
+
+	var ids, paths []string
+	for _, source := range sources {
+		ids = append(ids, source.TaskID)
+		paths = append(paths, filepath.Base(source.FilePath))
+	}
+
+	if want := []string{"2", "3", "10"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("Expected task IDs %v, got %v", want, ids)
+	}
+	if want := []string{"out_100_2.log", "out_100_3.log", "out_100_10.log"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("Expected paths %v, got %v", want, paths)
+	}
+	if len(client.listed) != 1 || client.listed[0].ArrayJobID != "100" {
+		t.Errorf("Expected only the tasks of array 100 to be listed, got %+v", client.listed)
+	}
+}
+
+func TestPathResolver_ResolvePerRankAndNode(t *testing.T) {
+	client := &fakeSlurmClient{jobs: []*dao.Job{
+		{ID: "300", NodeList: "gpu[01-02]", Tasks: 4, WorkingDir: "/scratch", StdOut: "rank_%j_%t_%N.out"},
+		{ID: "301", NodeList: "cn[1,3]", WorkingDir: "/scratch", StdOut: "node_%n_%N.out"},
+	}}
+	resolver := streaming.NewPathResolver(client, nil)
+
+	ranks, err := resolver.ResolveTaskOutputPaths("300", "stdout")
+	if err != nil {
+		t.Fatalf("ResolveTaskOutputPaths failed: %v", err)
+	}
+	wantRanks := []string{
+		"/scratch/rank_300_0_gpu01.out",
+		"/scratch/rank_300_1_gpu01.out",
+		"/scratch/rank_300_2_gpu02.out",
+		"/scratch/rank_300_3_gpu02.out",
+	}
+	for i, source := range ranks {
+		if source.FilePath != wantRanks[i] {
+			t.Errorf("Rank %d: expected %s, got %s", i, wantRanks[i], source.FilePath)
+		}
+	}
+
+	nodes, err := resolver.ResolveTaskOutputPaths("301", "stdout")
+	if err != nil {
+		t.Fatalf("ResolveTaskOutputPaths failed: %v", err)
+	}
+	if len(nodes) != 2 || nodes[1].TaskID != "cn3" || nodes[1].FilePath != "/scratch/node_1_cn3.out" {
+		t.Errorf("Unexpected per-node sources: %+v", nodes)
+	}
+}
+
+func TestParseTaskSelection(t *testing.T) {
+	ids, err := streaming.ParseTaskSelection("0-2, 7,node12")
+	if err != nil {
+		t.Fatalf("ParseTaskSelection failed: %v", err)
+	}
+	if want := []string{"0", "1", "2", "7", "node12"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("Expected %v, got %v", want, ids)
+	}
+
+	if _, err := streaming.ParseTaskSelection("5-3"); err == nil {
+		t.Error("Expected error for descending range")
+	}
+}
+
+func TestStreamManager_MultiTaskStream(t *testing.T) {
+	dir := t.TempDir()
+	pattern := filepath.Join(dir, "task_%a.out")
+	client := &fakeSlurmClient{jobs: []*dao.Job{
+		{ID: "500", ArrayJobID: "500", ArrayTaskID: "0", StdOut: pattern},
+		{ID: "501", ArrayJobID: "500", ArrayTaskID: "1", StdOut: pattern},
+	}}
+
+	writeFile := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	writeFile("task_0.out", "starting\nstep 1\n")
+	writeFile("task_1.out", "starting\nERROR: disk full\n")
+
+	config := streaming.DefaultSlurmConfig()
+	config.FileCheckInterval = 50 * time.Millisecond
+	sm, err := streaming.NewStreamManager(client, nil, nil, config)
+	if err != nil {
+		t.Fatalf("NewStreamManager failed: %v", err)
+	}
+	defer func() { _ = sm.Close() }()
+
+	if err := sm.StartMultiTaskStream("500", "stdout"); err != nil {
+		t.Fatalf("StartMultiTaskStream failed: %v", err)
+	}
+	defer func() { _ = sm.StopMultiTaskStream("500", "stdout") }()
+
+	waitForLines := func(want int) []string {
+		deadline := time.Now().Add(2 * time.Second)
+		for time.Now().Before(deadline) {
+			lines, _ := sm.GetMultiTaskBuffer("500", "stdout")
+			if len(lines) >= want {
+				return lines
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		t.Fatalf("Timed out waiting for %d merged lines", want)
+		return nil
+	}
+
+	lines := waitForLines(4)
+	if !strings.HasPrefix(lines[0], "[0] ") || !strings.HasSuffix(lines[0], "starting") {
+		t.Errorf("Expected first line prefixed with task 0, got %q", lines[0])
+	}
+	rendered, err := sm.RenderMultiTaskLines("500", "stdout", lines[:1])
+	if err != nil || len(rendered) != 1 || !strings.Contains(rendered[0], "[0[][-] ") {
+		t.Errorf("Expected a colored task prefix, got %q (err %v)", rendered, err)
+	}
+
+	taskErr, err := sm.FirstTaskError("500", "stdout")
+	if err != nil || taskErr == nil {
+		t.Fatalf("Expected a task error, got %v (err %v)", taskErr, err)
+	}
+	if taskErr.TaskID != "1" {
+		t.Errorf("Expected first error from task 1, got %s", taskErr.TaskID)
+	}
+
+	if err := sm.SetTaskFilter("500", "stdout", []string{"0"}); err != nil {
+		t.Fatalf("SetTaskFilter failed: %v", err)
+	}
+	filtered, _ := sm.GetMultiTaskBuffer("500", "stdout")
+	if len(filtered) != 2 {
+		t.Errorf("Expected 2 lines for task 0, got %d: %v", len(filtered), filtered)
+	}
+
+	// Partial lines are held back until their newline arrives
+	f, err := os.OpenFile(filepath.Join(dir, "task_0.out"), os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatalf("Failed to open task file: %v", err)
+	}
+	_, _ = f.WriteString("step 2")
+	time.Sleep(200 * time.Millisecond)
+	if lines, _ := sm.GetMultiTaskBuffer("500", "stdout"); len(lines) != 2 {
+		t.Errorf("Expected partial line to be held back, got %v", lines)
+	}
+	_, _ = f.WriteString(" done\n")
+	_ = f.Close()
+
+	lines = waitForLines(3)
+	if !strings.HasSuffix(lines[2], "step 2 done") {
+		t.Errorf("Expected joined partial line, got %q", lines[2])
+	}
+}
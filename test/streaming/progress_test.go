@@ -0,0 +1,198 @@
+package streaming_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jontk/s9s/internal/dao"
+	"github.com/jontk/s9s/internal/streaming"
+)
+
+func TestProgressPresets_Extract(t *testing.T) {
+	tests := []struct {
+		preset  string
+		line    string
+		percent float64
+		ok      bool
+	}{
+		{"epoch", "Epoch 3/10 loss=0.41", 30, true},
+		{"epoch", "epoch: 5 of 20", 25, true},
+		{"step", "Iteration 250/1000", 25, true},
+		{"tqdm", " 45%|####5     | 450/1000 [00:10<00:12, 43.2it/s]", 45, true},
+		{"percent", "Progress: 12.5%", 12.5, true},
+		{"fraction", "[ 40/200] processing sample", 20, true},
+		{"fraction", "[200/40] bogus", 0, false},
+		{"percent", "used 80% of memory", 0, false},
+	}
+
+	for _, tt := range tests {
+		extractor, err := streaming.ProgressPreset(tt.preset)
+		if err != nil {
+			t.Fatalf("ProgressPreset(%s) failed: %v", tt.preset, err)
+		}
+		sample, ok := extractor.Extract(tt.line)
+		if ok != tt.ok {
+			t.Errorf("%s on %q: expected ok=%v, got %v", tt.preset, tt.line, tt.ok, ok)
+			continue
+		}
+		if ok && sample.Percent != tt.percent {
+			t.Errorf("%s on %q: expected %.1f%%, got %.1f%%", tt.preset, tt.line, tt.percent, sample.Percent)
+		}
+	}
+}
+
+func TestNewRegexProgressExtractor_RequiresGroups(t *testing.T) {
+	if _, err := streaming.NewRegexProgressExtractor("bad", `(\d+)%`); err == nil {
+		t.Error("Expected error for pattern without named groups")
+	}
+	if _, err := streaming.NewRegexProgressExtractor("bad", `(?P<percent>`); err == nil {
+		t.Error("Expected error for invalid pattern")
+	}
+
+	extractor, err := streaming.NewRegexProgressExtractor("sim", `t=(?P<current>\d+) of (?P<total>\d+)`)
+	if err != nil {
+		t.Fatalf("NewRegexProgressExtractor failed: %v", err)
+	}
+	if sample, ok := extractor.Extract("sim t=30 of 120"); !ok || sample.Percent != 25 {
+		t.Errorf("Expected 25%%, got %+v (ok %v)", sample, ok)
+	}
+}
+
+func TestProgressTracker_EstimateAndTimeout(t *testing.T) {
+	start := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	job := &dao.Job{ID: "42", State: dao.JobStateRunning, StartTime: &start, TimeLimit: "60"}
+
+	tracker := streaming.NewProgressTracker()
+	tracker.Observe("42", []string{"Epoch 1/10"}, start.Add(5*time.Minute))
+	tracker.Observe("42", []string{"Epoch 3/10", "  30%|###       |"}, start.Add(15*time.Minute))
+
+	now := start.Add(15 * time.Minute)
+	estimate, ok := tracker.Estimate("42", job, now)
+	if !ok {
+		t.Fatal("Expected an estimate")
+	}
+	if estimate.Source != "epoch" {
+		t.Errorf("Expected epoch extractor to take priority, got %s", estimate.Source)
+	}
+	// 10% -> 30% in 10 minutes is 2%/min, leaving 35 minutes for the rest
+	if estimate.Rate != 2 {
+		t.Errorf("Expected rate 2%%/min, got %.2f", estimate.Rate)
+	}
+	if estimate.ETA != 35*time.Minute {
+		t.Errorf("Expected ETA 35m, got %v", estimate.ETA)
+	}
+	if estimate.Remaining != 45*time.Minute || estimate.WillTimeOut {
+		t.Errorf("Expected 45m remaining without timeout, got %v (timeout %v)", estimate.Remaining, estimate.WillTimeOut)
+	}
+
+	job.TimeLimit = "0:30:00"
+	estimate, _ = tracker.Estimate("42", job, now)
+	if !estimate.WillTimeOut {
+		t.Error("Expected job to be flagged as timing out")
+	}
+}
+
+func TestProgressTracker_RateFallsBackToStartTime(t *testing.T) {
+	start := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	job := &dao.Job{ID: "7", StartTime: &start, TimeLimit: "1-00:00:00"}
+
+	tracker := streaming.NewProgressTracker()
+	tracker.Observe("7", []string{"Progress: 25%"}, start.Add(time.Hour))
+
+	estimate, ok := tracker.Estimate("7", job, start.Add(time.Hour))
+	if !ok {
+		t.Fatal("Expected an estimate")
+	}
+	if estimate.ETA != 3*time.Hour {
+		t.Errorf("Expected ETA 3h from single reading, got %v", estimate.ETA)
+	}
+
+	tracker.Forget("7")
+	if _, ok := tracker.Estimate("7", job, start); ok {
+		t.Error("Expected no estimate after Forget")
+	}
+}
+
+func TestStreamManager_SampleProgress(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Now().Add(-10 * time.Minute)
+	job := &dao.Job{ID: "900", State: dao.JobStateRunning, StartTime: &start, StdOut: filepath.Join(dir, "train_%j.out")}
+
+	content := "loading\nstep 10/100\r\x1b[Kstep 20/100\n"
+	if err := os.WriteFile(filepath.Join(dir, "train_900.out"), []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write output: %v", err)
+	}
+
+	sm, err := streaming.NewStreamManager(&fakeSlurmClient{jobs: []*dao.Job{job}}, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("NewStreamManager failed: %v", err)
+	}
+	defer func() { _ = sm.Close() }()
+
+	sm.SampleProgress([]*dao.Job{job, {ID: "901", State: dao.JobStatePending}})
+
+	estimate, ok := sm.EstimateProgress(job)
+	if !ok {
+		t.Fatal("Expected progress from sampled output")
+	}
+	if estimate.Percent != 20 || estimate.Source != "step" {
+		t.Errorf("Expected 20%% from step extractor, got %.1f%% from %s", estimate.Percent, estimate.Source)
+	}
+
+	// An unchanged file is not read again while its offset is remembered
+	sm.Progress().Forget(job.ID)
+	sm.SampleProgress([]*dao.Job{job})
+	if _, ok := sm.EstimateProgress(job); ok {
+		t.Error("Expected an unchanged output file to be skipped")
+	}
+
+	// Pruning drops the offset with the readings, so the file is read afresh
+	sm.PruneProgress(time.Now().Add(time.Minute))
+	sm.SampleProgress([]*dao.Job{job})
+	if _, ok := sm.EstimateProgress(job); !ok {
+		t.Error("Expected the output to be sampled again after pruning")
+	}
+}
+
+func TestStreamManager_SampleProgressKeepsRateWindow(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "train_910.out")
+	t0 := time.Date(2026, 1, 1, 11, 0, 0, 0, time.UTC)
+	start := t0.Add(-50 * time.Minute)
+	job := &dao.Job{ID: "910", State: dao.JobStateRunning, StartTime: &start, StdOut: filepath.Join(dir, "train_%j.out")}
+
+	sm, err := streaming.NewStreamManager(&fakeSlurmClient{jobs: []*dao.Job{job}}, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("NewStreamManager failed: %v", err)
+	}
+	defer func() { _ = sm.Close() }()
+
+	appendOutput := func(content string) {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+		if err != nil {
+			t.Fatalf("Failed to open output: %v", err)
+		}
+		defer func() { _ = f.Close() }()
+		if _, err := f.WriteString(content); err != nil {
+			t.Fatalf("Failed to write output: %v", err)
+		}
+	}
+
+	appendOutput("Epoch 1/10\n")
+	sm.SampleProgressAt([]*dao.Job{job}, t0)
+	appendOutput("Epoch 4/10\n")
+	sm.SampleProgressAt([]*dao.Job{job}, t0.Add(5*time.Minute))
+	appendOutput("Epoch 6/10\n")
+	sm.SampleProgressAt([]*dao.Job{job}, t0.Add(10*time.Minute))
+
+	estimate, ok := sm.Progress().Estimate(job.ID, job, t0.Add(10*time.Minute))
+	if !ok {
+		t.Fatal("Expected progress from sampled output")
+	}
+	// 10% -> 60% over the 10 sampled minutes, not 60% over the hour since start
+	if estimate.Percent != 60 || estimate.Rate != 5 {
+		t.Errorf("Expected 60%% at a windowed 5%%/min, got %.1f%% at %.2f%%/min", estimate.Percent, estimate.Rate)
+	}
+}
@@ -0,0 +1,104 @@
+package streaming_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jontk/s9s/internal/streaming"
+)
+
+func TestTerminalProcessor_SGRColors(t *testing.T) {
+	tp := streaming.NewTerminalProcessor()
+
+	lines := tp.Process("\x1b[1;31merror:\x1b[0m build failed\n")
+	want := []string{"[maroon:-:b]error:[-:-:-] build failed"}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("Expected %q, got %q", want, lines)
+	}
+
+	lines = tp.Process("\x1b[38;5;196mX\x1b[39m \x1b[48;2;0;128;255mY\x1b[m\n")
+	want = []string{"[#ff0000:-:-]X[-:-:-] [-:#0080ff:-]Y[-:-:-]"}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("Expected %q, got %q", want, lines)
+	}
+}
+
+func TestTerminalProcessor_CarriageReturnCollapse(t *testing.T) {
+	tp := streaming.NewTerminalProcessor()
+
+	lines := tp.Process("Epoch 1:  10%|#         |\rEpoch 1:  50%|#####     |")
+	if len(lines) != 0 {
+		t.Errorf("Expected no completed lines, got %q", lines)
+	}
+	if partial := tp.Partial(); partial != "Epoch 1:  50%|#####     |" {
+		t.Errorf("Unexpected partial line %q", partial)
+	}
+
+	lines = tp.Process("\rEpoch 1: 100%|##########|\n")
+	want := []string{"Epoch 1: 100%|##########|"}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("Expected %q, got %q", want, lines)
+	}
+
+	// A shorter redraw only overwrites the start of the line unless erased
+	lines = tp.Process("abcdef\rXY\n123456\r\x1b[KZ\n")
+	want = []string{"XYcdef", "Z"}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("Expected %q, got %q", want, lines)
+	}
+}
+
+func TestTerminalProcessor_ChunkBoundaries(t *testing.T) {
+	tp := streaming.NewTerminalProcessor()
+
+	// Split inside an escape sequence, a CRLF and a multi-byte rune
+	chunks := []string{"\x1b[3", "2mok\x1b", "[0m \xe2\x9c", "\x93\r", "\n"}
+	var lines []string
+	for _, chunk := range chunks {
+		lines = append(lines, tp.Process(chunk)...)
+	}
+
+	want := []string{"[green:-:-]ok[-:-:-] ✓"}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("Expected %q, got %q", want, lines)
+	}
+}
+
+func TestTerminalProcessor_StripsControlSequences(t *testing.T) {
+	output := streaming.RenderTerminalOutput("\x1b]0;title\x07\x1b[?25lhello\x1b[2J\x1b(B\x07 [INFO]\x1b[?25h\n")
+	if output != "hello [INFO[]\n" {
+		t.Errorf("Unexpected rendered output %q", output)
+	}
+}
+
+func TestTerminalProcessor_PlainLines(t *testing.T) {
+	tp := streaming.NewTerminalProcessor()
+
+	lines := tp.ProcessLines("\x1b[31m[ERROR]\x1b[0m step 3\n50%\r")
+	want := []streaming.TerminalLine{{Text: "[ERROR] step 3", Markup: "[maroon:-:-][ERROR[][-:-:-] step 3"}}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("Expected %q, got %q", want, lines)
+	}
+
+	tp.ProcessLines("\x1b[32m100%\x1b[0m")
+	if partial := tp.PartialLine(); partial.Text != "100%" || partial.Markup != "[green:-:-]100%[-:-:-]" {
+		t.Errorf("Unexpected partial line %q", partial)
+	}
+}
+
+func TestTerminalProcessor_BoundsCursorMovement(t *testing.T) {
+	tp := streaming.NewTerminalProcessor()
+
+	lines := tp.ProcessLines("\x1b[999999999Cx\n\x1b[999999999Gy\nab\x1b[-5Cc\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines, got %d", len(lines))
+	}
+	for i, line := range lines[:2] {
+		if len(line.Text) > 16*1024+1 {
+			t.Errorf("Line %d padded to %d cells", i, len(line.Text))
+		}
+	}
+	if lines[2].Text != "ab c" {
+		t.Errorf("Expected a negative move to count as one, got %q", lines[2].Text)
+	}
+}